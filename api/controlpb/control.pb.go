@@ -0,0 +1,1370 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.28.3
+// source: control.proto
+
+package controlpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListTradersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTradersRequest) Reset() {
+	*x = ListTradersRequest{}
+	mi := &file_control_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTradersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTradersRequest) ProtoMessage() {}
+
+func (x *ListTradersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTradersRequest.ProtoReflect.Descriptor instead.
+func (*ListTradersRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{0}
+}
+
+type TraderInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	TraderName    string                 `protobuf:"bytes,2,opt,name=trader_name,json=traderName,proto3" json:"trader_name,omitempty"`
+	AiModel       string                 `protobuf:"bytes,3,opt,name=ai_model,json=aiModel,proto3" json:"ai_model,omitempty"`
+	IsRunning     bool                   `protobuf:"varint,4,opt,name=is_running,json=isRunning,proto3" json:"is_running,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TraderInfo) Reset() {
+	*x = TraderInfo{}
+	mi := &file_control_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraderInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraderInfo) ProtoMessage() {}
+
+func (x *TraderInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraderInfo.ProtoReflect.Descriptor instead.
+func (*TraderInfo) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TraderInfo) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *TraderInfo) GetTraderName() string {
+	if x != nil {
+		return x.TraderName
+	}
+	return ""
+}
+
+func (x *TraderInfo) GetAiModel() string {
+	if x != nil {
+		return x.AiModel
+	}
+	return ""
+}
+
+func (x *TraderInfo) GetIsRunning() bool {
+	if x != nil {
+		return x.IsRunning
+	}
+	return false
+}
+
+type ListTradersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Traders       []*TraderInfo          `protobuf:"bytes,1,rep,name=traders,proto3" json:"traders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTradersResponse) Reset() {
+	*x = ListTradersResponse{}
+	mi := &file_control_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTradersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTradersResponse) ProtoMessage() {}
+
+func (x *ListTradersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTradersResponse.ProtoReflect.Descriptor instead.
+func (*ListTradersResponse) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListTradersResponse) GetTraders() []*TraderInfo {
+	if x != nil {
+		return x.Traders
+	}
+	return nil
+}
+
+type TraderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TraderRequest) Reset() {
+	*x = TraderRequest{}
+	mi := &file_control_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraderRequest) ProtoMessage() {}
+
+func (x *TraderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraderRequest.ProtoReflect.Descriptor instead.
+func (*TraderRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TraderRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+type TraderStatus struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	TraderId           string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	TraderName         string                 `protobuf:"bytes,2,opt,name=trader_name,json=traderName,proto3" json:"trader_name,omitempty"`
+	AiModel            string                 `protobuf:"bytes,3,opt,name=ai_model,json=aiModel,proto3" json:"ai_model,omitempty"`
+	IsRunning          bool                   `protobuf:"varint,4,opt,name=is_running,json=isRunning,proto3" json:"is_running,omitempty"`
+	ObserveMode        bool                   `protobuf:"varint,5,opt,name=observe_mode,json=observeMode,proto3" json:"observe_mode,omitempty"`
+	CallCount          int64                  `protobuf:"varint,6,opt,name=call_count,json=callCount,proto3" json:"call_count,omitempty"`
+	RuntimeMinutes     int64                  `protobuf:"varint,7,opt,name=runtime_minutes,json=runtimeMinutes,proto3" json:"runtime_minutes,omitempty"`
+	PausedUntilUnix    int64                  `protobuf:"varint,8,opt,name=paused_until_unix,json=pausedUntilUnix,proto3" json:"paused_until_unix,omitempty"`
+	MaxDailyLoss       float64                `protobuf:"fixed64,9,opt,name=max_daily_loss,json=maxDailyLoss,proto3" json:"max_daily_loss,omitempty"`
+	MaxDrawdown        float64                `protobuf:"fixed64,10,opt,name=max_drawdown,json=maxDrawdown,proto3" json:"max_drawdown,omitempty"`
+	MaxRiskPerTradeUsd float64                `protobuf:"fixed64,11,opt,name=max_risk_per_trade_usd,json=maxRiskPerTradeUsd,proto3" json:"max_risk_per_trade_usd,omitempty"`
+	StopTradingMinutes int64                  `protobuf:"varint,12,opt,name=stop_trading_minutes,json=stopTradingMinutes,proto3" json:"stop_trading_minutes,omitempty"`
+	StrategyProfile    string                 `protobuf:"bytes,13,opt,name=strategy_profile,json=strategyProfile,proto3" json:"strategy_profile,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TraderStatus) Reset() {
+	*x = TraderStatus{}
+	mi := &file_control_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraderStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraderStatus) ProtoMessage() {}
+
+func (x *TraderStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraderStatus.ProtoReflect.Descriptor instead.
+func (*TraderStatus) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TraderStatus) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *TraderStatus) GetTraderName() string {
+	if x != nil {
+		return x.TraderName
+	}
+	return ""
+}
+
+func (x *TraderStatus) GetAiModel() string {
+	if x != nil {
+		return x.AiModel
+	}
+	return ""
+}
+
+func (x *TraderStatus) GetIsRunning() bool {
+	if x != nil {
+		return x.IsRunning
+	}
+	return false
+}
+
+func (x *TraderStatus) GetObserveMode() bool {
+	if x != nil {
+		return x.ObserveMode
+	}
+	return false
+}
+
+func (x *TraderStatus) GetCallCount() int64 {
+	if x != nil {
+		return x.CallCount
+	}
+	return 0
+}
+
+func (x *TraderStatus) GetRuntimeMinutes() int64 {
+	if x != nil {
+		return x.RuntimeMinutes
+	}
+	return 0
+}
+
+func (x *TraderStatus) GetPausedUntilUnix() int64 {
+	if x != nil {
+		return x.PausedUntilUnix
+	}
+	return 0
+}
+
+func (x *TraderStatus) GetMaxDailyLoss() float64 {
+	if x != nil {
+		return x.MaxDailyLoss
+	}
+	return 0
+}
+
+func (x *TraderStatus) GetMaxDrawdown() float64 {
+	if x != nil {
+		return x.MaxDrawdown
+	}
+	return 0
+}
+
+func (x *TraderStatus) GetMaxRiskPerTradeUsd() float64 {
+	if x != nil {
+		return x.MaxRiskPerTradeUsd
+	}
+	return 0
+}
+
+func (x *TraderStatus) GetStopTradingMinutes() int64 {
+	if x != nil {
+		return x.StopTradingMinutes
+	}
+	return 0
+}
+
+func (x *TraderStatus) GetStrategyProfile() string {
+	if x != nil {
+		return x.StrategyProfile
+	}
+	return ""
+}
+
+type PauseRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TraderId        string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	DurationMinutes int64                  `protobuf:"varint,2,opt,name=duration_minutes,json=durationMinutes,proto3" json:"duration_minutes,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PauseRequest) Reset() {
+	*x = PauseRequest{}
+	mi := &file_control_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseRequest) ProtoMessage() {}
+
+func (x *PauseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseRequest.ProtoReflect.Descriptor instead.
+func (*PauseRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PauseRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *PauseRequest) GetDurationMinutes() int64 {
+	if x != nil {
+		return x.DurationMinutes
+	}
+	return 0
+}
+
+type OverrideRiskRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	TraderId           string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	MaxDailyLoss       *float64               `protobuf:"fixed64,2,opt,name=max_daily_loss,json=maxDailyLoss,proto3,oneof" json:"max_daily_loss,omitempty"`
+	MaxDrawdown        *float64               `protobuf:"fixed64,3,opt,name=max_drawdown,json=maxDrawdown,proto3,oneof" json:"max_drawdown,omitempty"`
+	MaxRiskPerTradeUsd *float64               `protobuf:"fixed64,4,opt,name=max_risk_per_trade_usd,json=maxRiskPerTradeUsd,proto3,oneof" json:"max_risk_per_trade_usd,omitempty"`
+	StopTradingMinutes *int64                 `protobuf:"varint,5,opt,name=stop_trading_minutes,json=stopTradingMinutes,proto3,oneof" json:"stop_trading_minutes,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *OverrideRiskRequest) Reset() {
+	*x = OverrideRiskRequest{}
+	mi := &file_control_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OverrideRiskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OverrideRiskRequest) ProtoMessage() {}
+
+func (x *OverrideRiskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OverrideRiskRequest.ProtoReflect.Descriptor instead.
+func (*OverrideRiskRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *OverrideRiskRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *OverrideRiskRequest) GetMaxDailyLoss() float64 {
+	if x != nil && x.MaxDailyLoss != nil {
+		return *x.MaxDailyLoss
+	}
+	return 0
+}
+
+func (x *OverrideRiskRequest) GetMaxDrawdown() float64 {
+	if x != nil && x.MaxDrawdown != nil {
+		return *x.MaxDrawdown
+	}
+	return 0
+}
+
+func (x *OverrideRiskRequest) GetMaxRiskPerTradeUsd() float64 {
+	if x != nil && x.MaxRiskPerTradeUsd != nil {
+		return *x.MaxRiskPerTradeUsd
+	}
+	return 0
+}
+
+func (x *OverrideRiskRequest) GetStopTradingMinutes() int64 {
+	if x != nil && x.StopTradingMinutes != nil {
+		return *x.StopTradingMinutes
+	}
+	return 0
+}
+
+type SwitchProfileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Profile       string                 `protobuf:"bytes,2,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SwitchProfileRequest) Reset() {
+	*x = SwitchProfileRequest{}
+	mi := &file_control_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SwitchProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwitchProfileRequest) ProtoMessage() {}
+
+func (x *SwitchProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwitchProfileRequest.ProtoReflect.Descriptor instead.
+func (*SwitchProfileRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SwitchProfileRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *SwitchProfileRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+type SetWatchlistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Symbols       []string               `protobuf:"bytes,2,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetWatchlistRequest) Reset() {
+	*x = SetWatchlistRequest{}
+	mi := &file_control_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetWatchlistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetWatchlistRequest) ProtoMessage() {}
+
+func (x *SetWatchlistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetWatchlistRequest.ProtoReflect.Descriptor instead.
+func (*SetWatchlistRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SetWatchlistRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *SetWatchlistRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+type PositionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side          string                 `protobuf:"bytes,3,opt,name=side,proto3" json:"side,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PositionRequest) Reset() {
+	*x = PositionRequest{}
+	mi := &file_control_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PositionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PositionRequest) ProtoMessage() {}
+
+func (x *PositionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PositionRequest.ProtoReflect.Descriptor instead.
+func (*PositionRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PositionRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *PositionRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *PositionRequest) GetSide() string {
+	if x != nil {
+		return x.Side
+	}
+	return ""
+}
+
+type AnnotatePositionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side          string                 `protobuf:"bytes,3,opt,name=side,proto3" json:"side,omitempty"`
+	Manual        *bool                  `protobuf:"varint,4,opt,name=manual,proto3,oneof" json:"manual,omitempty"`
+	Note          *string                `protobuf:"bytes,5,opt,name=note,proto3,oneof" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnnotatePositionRequest) Reset() {
+	*x = AnnotatePositionRequest{}
+	mi := &file_control_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnnotatePositionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnnotatePositionRequest) ProtoMessage() {}
+
+func (x *AnnotatePositionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnnotatePositionRequest.ProtoReflect.Descriptor instead.
+func (*AnnotatePositionRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AnnotatePositionRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *AnnotatePositionRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *AnnotatePositionRequest) GetSide() string {
+	if x != nil {
+		return x.Side
+	}
+	return ""
+}
+
+func (x *AnnotatePositionRequest) GetManual() bool {
+	if x != nil && x.Manual != nil {
+		return *x.Manual
+	}
+	return false
+}
+
+func (x *AnnotatePositionRequest) GetNote() string {
+	if x != nil && x.Note != nil {
+		return *x.Note
+	}
+	return ""
+}
+
+type AdjustPositionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side          string                 `protobuf:"bytes,3,opt,name=side,proto3" json:"side,omitempty"`
+	StopLoss      *float64               `protobuf:"fixed64,4,opt,name=stop_loss,json=stopLoss,proto3,oneof" json:"stop_loss,omitempty"`
+	TakeProfit    *float64               `protobuf:"fixed64,5,opt,name=take_profit,json=takeProfit,proto3,oneof" json:"take_profit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustPositionRequest) Reset() {
+	*x = AdjustPositionRequest{}
+	mi := &file_control_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustPositionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustPositionRequest) ProtoMessage() {}
+
+func (x *AdjustPositionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustPositionRequest.ProtoReflect.Descriptor instead.
+func (*AdjustPositionRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AdjustPositionRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *AdjustPositionRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *AdjustPositionRequest) GetSide() string {
+	if x != nil {
+		return x.Side
+	}
+	return ""
+}
+
+func (x *AdjustPositionRequest) GetStopLoss() float64 {
+	if x != nil && x.StopLoss != nil {
+		return *x.StopLoss
+	}
+	return 0
+}
+
+func (x *AdjustPositionRequest) GetTakeProfit() float64 {
+	if x != nil && x.TakeProfit != nil {
+		return *x.TakeProfit
+	}
+	return 0
+}
+
+type ControlResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlResponse) Reset() {
+	*x = ControlResponse{}
+	mi := &file_control_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlResponse) ProtoMessage() {}
+
+func (x *ControlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlResponse.ProtoReflect.Descriptor instead.
+func (*ControlResponse) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ControlResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *ControlResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type StreamDecisionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamDecisionsRequest) Reset() {
+	*x = StreamDecisionsRequest{}
+	mi := &file_control_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamDecisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamDecisionsRequest) ProtoMessage() {}
+
+func (x *StreamDecisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamDecisionsRequest.ProtoReflect.Descriptor instead.
+func (*StreamDecisionsRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StreamDecisionsRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+type DecisionAction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Quantity      float64                `protobuf:"fixed64,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Leverage      int32                  `protobuf:"varint,4,opt,name=leverage,proto3" json:"leverage,omitempty"`
+	Price         float64                `protobuf:"fixed64,5,opt,name=price,proto3" json:"price,omitempty"`
+	StopLoss      float64                `protobuf:"fixed64,6,opt,name=stop_loss,json=stopLoss,proto3" json:"stop_loss,omitempty"`
+	TakeProfit    float64                `protobuf:"fixed64,7,opt,name=take_profit,json=takeProfit,proto3" json:"take_profit,omitempty"`
+	Success       bool                   `protobuf:"varint,8,opt,name=success,proto3" json:"success,omitempty"`
+	Hypothetical  bool                   `protobuf:"varint,9,opt,name=hypothetical,proto3" json:"hypothetical,omitempty"`
+	Error         string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	Reasoning     string                 `protobuf:"bytes,11,opt,name=reasoning,proto3" json:"reasoning,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DecisionAction) Reset() {
+	*x = DecisionAction{}
+	mi := &file_control_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecisionAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionAction) ProtoMessage() {}
+
+func (x *DecisionAction) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionAction.ProtoReflect.Descriptor instead.
+func (*DecisionAction) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DecisionAction) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *DecisionAction) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *DecisionAction) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *DecisionAction) GetLeverage() int32 {
+	if x != nil {
+		return x.Leverage
+	}
+	return 0
+}
+
+func (x *DecisionAction) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *DecisionAction) GetStopLoss() float64 {
+	if x != nil {
+		return x.StopLoss
+	}
+	return 0
+}
+
+func (x *DecisionAction) GetTakeProfit() float64 {
+	if x != nil {
+		return x.TakeProfit
+	}
+	return 0
+}
+
+func (x *DecisionAction) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DecisionAction) GetHypothetical() bool {
+	if x != nil {
+		return x.Hypothetical
+	}
+	return false
+}
+
+func (x *DecisionAction) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DecisionAction) GetReasoning() string {
+	if x != nil {
+		return x.Reasoning
+	}
+	return ""
+}
+
+type DecisionEvent struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	TraderId         string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	CycleNumber      int64                  `protobuf:"varint,2,opt,name=cycle_number,json=cycleNumber,proto3" json:"cycle_number,omitempty"`
+	TimestampUnix    int64                  `protobuf:"varint,3,opt,name=timestamp_unix,json=timestampUnix,proto3" json:"timestamp_unix,omitempty"`
+	Success          bool                   `protobuf:"varint,4,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorMessage     string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	ObserveMode      bool                   `protobuf:"varint,6,opt,name=observe_mode,json=observeMode,proto3" json:"observe_mode,omitempty"`
+	TotalEquity      float64                `protobuf:"fixed64,7,opt,name=total_equity,json=totalEquity,proto3" json:"total_equity,omitempty"`
+	AvailableBalance float64                `protobuf:"fixed64,8,opt,name=available_balance,json=availableBalance,proto3" json:"available_balance,omitempty"`
+	PositionCount    int32                  `protobuf:"varint,9,opt,name=position_count,json=positionCount,proto3" json:"position_count,omitempty"`
+	Decisions        []*DecisionAction      `protobuf:"bytes,10,rep,name=decisions,proto3" json:"decisions,omitempty"`
+	ExecutionLog     []string               `protobuf:"bytes,11,rep,name=execution_log,json=executionLog,proto3" json:"execution_log,omitempty"`
+	RecordJson       string                 `protobuf:"bytes,12,opt,name=record_json,json=recordJson,proto3" json:"record_json,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DecisionEvent) Reset() {
+	*x = DecisionEvent{}
+	mi := &file_control_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecisionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionEvent) ProtoMessage() {}
+
+func (x *DecisionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionEvent.ProtoReflect.Descriptor instead.
+func (*DecisionEvent) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DecisionEvent) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *DecisionEvent) GetCycleNumber() int64 {
+	if x != nil {
+		return x.CycleNumber
+	}
+	return 0
+}
+
+func (x *DecisionEvent) GetTimestampUnix() int64 {
+	if x != nil {
+		return x.TimestampUnix
+	}
+	return 0
+}
+
+func (x *DecisionEvent) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DecisionEvent) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *DecisionEvent) GetObserveMode() bool {
+	if x != nil {
+		return x.ObserveMode
+	}
+	return false
+}
+
+func (x *DecisionEvent) GetTotalEquity() float64 {
+	if x != nil {
+		return x.TotalEquity
+	}
+	return 0
+}
+
+func (x *DecisionEvent) GetAvailableBalance() float64 {
+	if x != nil {
+		return x.AvailableBalance
+	}
+	return 0
+}
+
+func (x *DecisionEvent) GetPositionCount() int32 {
+	if x != nil {
+		return x.PositionCount
+	}
+	return 0
+}
+
+func (x *DecisionEvent) GetDecisions() []*DecisionAction {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
+func (x *DecisionEvent) GetExecutionLog() []string {
+	if x != nil {
+		return x.ExecutionLog
+	}
+	return nil
+}
+
+func (x *DecisionEvent) GetRecordJson() string {
+	if x != nil {
+		return x.RecordJson
+	}
+	return ""
+}
+
+var File_control_proto protoreflect.FileDescriptor
+
+const file_control_proto_rawDesc = "" +
+	"\n" +
+	"\rcontrol.proto\x12\x0fnofx.control.v1\"\x14\n" +
+	"\x12ListTradersRequest\"\x84\x01\n" +
+	"\n" +
+	"TraderInfo\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x1f\n" +
+	"\vtrader_name\x18\x02 \x01(\tR\n" +
+	"traderName\x12\x19\n" +
+	"\bai_model\x18\x03 \x01(\tR\aaiModel\x12\x1d\n" +
+	"\n" +
+	"is_running\x18\x04 \x01(\bR\tisRunning\"L\n" +
+	"\x13ListTradersResponse\x125\n" +
+	"\atraders\x18\x01 \x03(\v2\x1b.nofx.control.v1.TraderInfoR\atraders\",\n" +
+	"\rTraderRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\"\xf7\x03\n" +
+	"\fTraderStatus\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x1f\n" +
+	"\vtrader_name\x18\x02 \x01(\tR\n" +
+	"traderName\x12\x19\n" +
+	"\bai_model\x18\x03 \x01(\tR\aaiModel\x12\x1d\n" +
+	"\n" +
+	"is_running\x18\x04 \x01(\bR\tisRunning\x12!\n" +
+	"\fobserve_mode\x18\x05 \x01(\bR\vobserveMode\x12\x1d\n" +
+	"\n" +
+	"call_count\x18\x06 \x01(\x03R\tcallCount\x12'\n" +
+	"\x0fruntime_minutes\x18\a \x01(\x03R\x0eruntimeMinutes\x12*\n" +
+	"\x11paused_until_unix\x18\b \x01(\x03R\x0fpausedUntilUnix\x12$\n" +
+	"\x0emax_daily_loss\x18\t \x01(\x01R\fmaxDailyLoss\x12!\n" +
+	"\fmax_drawdown\x18\n" +
+	" \x01(\x01R\vmaxDrawdown\x122\n" +
+	"\x16max_risk_per_trade_usd\x18\v \x01(\x01R\x12maxRiskPerTradeUsd\x120\n" +
+	"\x14stop_trading_minutes\x18\f \x01(\x03R\x12stopTradingMinutes\x12)\n" +
+	"\x10strategy_profile\x18\r \x01(\tR\x0fstrategyProfile\"V\n" +
+	"\fPauseRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12)\n" +
+	"\x10duration_minutes\x18\x02 \x01(\x03R\x0fdurationMinutes\"\xcd\x02\n" +
+	"\x13OverrideRiskRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12)\n" +
+	"\x0emax_daily_loss\x18\x02 \x01(\x01H\x00R\fmaxDailyLoss\x88\x01\x01\x12&\n" +
+	"\fmax_drawdown\x18\x03 \x01(\x01H\x01R\vmaxDrawdown\x88\x01\x01\x127\n" +
+	"\x16max_risk_per_trade_usd\x18\x04 \x01(\x01H\x02R\x12maxRiskPerTradeUsd\x88\x01\x01\x125\n" +
+	"\x14stop_trading_minutes\x18\x05 \x01(\x03H\x03R\x12stopTradingMinutes\x88\x01\x01B\x11\n" +
+	"\x0f_max_daily_lossB\x0f\n" +
+	"\r_max_drawdownB\x19\n" +
+	"\x17_max_risk_per_trade_usdB\x17\n" +
+	"\x15_stop_trading_minutes\"M\n" +
+	"\x14SwitchProfileRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x18\n" +
+	"\aprofile\x18\x02 \x01(\tR\aprofile\"L\n" +
+	"\x13SetWatchlistRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x18\n" +
+	"\asymbols\x18\x02 \x03(\tR\asymbols\"Z\n" +
+	"\x0fPositionRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04side\x18\x03 \x01(\tR\x04side\"\xac\x01\n" +
+	"\x17AnnotatePositionRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04side\x18\x03 \x01(\tR\x04side\x12\x1b\n" +
+	"\x06manual\x18\x04 \x01(\bH\x00R\x06manual\x88\x01\x01\x12\x17\n" +
+	"\x04note\x18\x05 \x01(\tH\x01R\x04note\x88\x01\x01B\t\n" +
+	"\a_manualB\a\n" +
+	"\x05_note\"\xc6\x01\n" +
+	"\x15AdjustPositionRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04side\x18\x03 \x01(\tR\x04side\x12 \n" +
+	"\tstop_loss\x18\x04 \x01(\x01H\x00R\bstopLoss\x88\x01\x01\x12$\n" +
+	"\vtake_profit\x18\x05 \x01(\x01H\x01R\n" +
+	"takeProfit\x88\x01\x01B\f\n" +
+	"\n" +
+	"_stop_lossB\x0e\n" +
+	"\f_take_profit\";\n" +
+	"\x0fControlResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"5\n" +
+	"\x16StreamDecisionsRequest\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\"\xbe\x02\n" +
+	"\x0eDecisionAction\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x01R\bquantity\x12\x1a\n" +
+	"\bleverage\x18\x04 \x01(\x05R\bleverage\x12\x14\n" +
+	"\x05price\x18\x05 \x01(\x01R\x05price\x12\x1b\n" +
+	"\tstop_loss\x18\x06 \x01(\x01R\bstopLoss\x12\x1f\n" +
+	"\vtake_profit\x18\a \x01(\x01R\n" +
+	"takeProfit\x12\x18\n" +
+	"\asuccess\x18\b \x01(\bR\asuccess\x12\"\n" +
+	"\fhypothetical\x18\t \x01(\bR\fhypothetical\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\x12\x1c\n" +
+	"\treasoning\x18\v \x01(\tR\treasoning\"\xd4\x03\n" +
+	"\rDecisionEvent\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12!\n" +
+	"\fcycle_number\x18\x02 \x01(\x03R\vcycleNumber\x12%\n" +
+	"\x0etimestamp_unix\x18\x03 \x01(\x03R\rtimestampUnix\x12\x18\n" +
+	"\asuccess\x18\x04 \x01(\bR\asuccess\x12#\n" +
+	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\x12!\n" +
+	"\fobserve_mode\x18\x06 \x01(\bR\vobserveMode\x12!\n" +
+	"\ftotal_equity\x18\a \x01(\x01R\vtotalEquity\x12+\n" +
+	"\x11available_balance\x18\b \x01(\x01R\x10availableBalance\x12%\n" +
+	"\x0eposition_count\x18\t \x01(\x05R\rpositionCount\x12=\n" +
+	"\tdecisions\x18\n" +
+	" \x03(\v2\x1f.nofx.control.v1.DecisionActionR\tdecisions\x12#\n" +
+	"\rexecution_log\x18\v \x03(\tR\fexecutionLog\x12\x1f\n" +
+	"\vrecord_json\x18\f \x01(\tR\n" +
+	"recordJson2\xd9\b\n" +
+	"\rTraderControl\x12X\n" +
+	"\vListTraders\x12#.nofx.control.v1.ListTradersRequest\x1a$.nofx.control.v1.ListTradersResponse\x12J\n" +
+	"\tGetStatus\x12\x1e.nofx.control.v1.TraderRequest\x1a\x1d.nofx.control.v1.TraderStatus\x12I\n" +
+	"\x05Start\x12\x1e.nofx.control.v1.TraderRequest\x1a .nofx.control.v1.ControlResponse\x12H\n" +
+	"\x04Stop\x12\x1e.nofx.control.v1.TraderRequest\x1a .nofx.control.v1.ControlResponse\x12H\n" +
+	"\x05Pause\x12\x1d.nofx.control.v1.PauseRequest\x1a .nofx.control.v1.ControlResponse\x12J\n" +
+	"\x06Resume\x12\x1e.nofx.control.v1.TraderRequest\x1a .nofx.control.v1.ControlResponse\x12V\n" +
+	"\fOverrideRisk\x12$.nofx.control.v1.OverrideRiskRequest\x1a .nofx.control.v1.ControlResponse\x12X\n" +
+	"\rSwitchProfile\x12%.nofx.control.v1.SwitchProfileRequest\x1a .nofx.control.v1.ControlResponse\x12V\n" +
+	"\fSetWatchlist\x12$.nofx.control.v1.SetWatchlistRequest\x1a .nofx.control.v1.ControlResponse\x12^\n" +
+	"\x10AnnotatePosition\x12(.nofx.control.v1.AnnotatePositionRequest\x1a .nofx.control.v1.ControlResponse\x12Z\n" +
+	"\x0eAdjustPosition\x12&.nofx.control.v1.AdjustPositionRequest\x1a .nofx.control.v1.ControlResponse\x12S\n" +
+	"\rClosePosition\x12 .nofx.control.v1.PositionRequest\x1a .nofx.control.v1.ControlResponse\x12\\\n" +
+	"\x0fStreamDecisions\x12'.nofx.control.v1.StreamDecisionsRequest\x1a\x1e.nofx.control.v1.DecisionEvent0\x01B\x14Z\x12nofx/api/controlpbb\x06proto3"
+
+var (
+	file_control_proto_rawDescOnce sync.Once
+	file_control_proto_rawDescData []byte
+)
+
+func file_control_proto_rawDescGZIP() []byte {
+	file_control_proto_rawDescOnce.Do(func() {
+		file_control_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_control_proto_rawDesc), len(file_control_proto_rawDesc)))
+	})
+	return file_control_proto_rawDescData
+}
+
+var file_control_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_control_proto_goTypes = []any{
+	(*ListTradersRequest)(nil),      // 0: nofx.control.v1.ListTradersRequest
+	(*TraderInfo)(nil),              // 1: nofx.control.v1.TraderInfo
+	(*ListTradersResponse)(nil),     // 2: nofx.control.v1.ListTradersResponse
+	(*TraderRequest)(nil),           // 3: nofx.control.v1.TraderRequest
+	(*TraderStatus)(nil),            // 4: nofx.control.v1.TraderStatus
+	(*PauseRequest)(nil),            // 5: nofx.control.v1.PauseRequest
+	(*OverrideRiskRequest)(nil),     // 6: nofx.control.v1.OverrideRiskRequest
+	(*SwitchProfileRequest)(nil),    // 7: nofx.control.v1.SwitchProfileRequest
+	(*SetWatchlistRequest)(nil),     // 8: nofx.control.v1.SetWatchlistRequest
+	(*PositionRequest)(nil),         // 9: nofx.control.v1.PositionRequest
+	(*AnnotatePositionRequest)(nil), // 10: nofx.control.v1.AnnotatePositionRequest
+	(*AdjustPositionRequest)(nil),   // 11: nofx.control.v1.AdjustPositionRequest
+	(*ControlResponse)(nil),         // 12: nofx.control.v1.ControlResponse
+	(*StreamDecisionsRequest)(nil),  // 13: nofx.control.v1.StreamDecisionsRequest
+	(*DecisionAction)(nil),          // 14: nofx.control.v1.DecisionAction
+	(*DecisionEvent)(nil),           // 15: nofx.control.v1.DecisionEvent
+}
+var file_control_proto_depIdxs = []int32{
+	1,  // 0: nofx.control.v1.ListTradersResponse.traders:type_name -> nofx.control.v1.TraderInfo
+	14, // 1: nofx.control.v1.DecisionEvent.decisions:type_name -> nofx.control.v1.DecisionAction
+	0,  // 2: nofx.control.v1.TraderControl.ListTraders:input_type -> nofx.control.v1.ListTradersRequest
+	3,  // 3: nofx.control.v1.TraderControl.GetStatus:input_type -> nofx.control.v1.TraderRequest
+	3,  // 4: nofx.control.v1.TraderControl.Start:input_type -> nofx.control.v1.TraderRequest
+	3,  // 5: nofx.control.v1.TraderControl.Stop:input_type -> nofx.control.v1.TraderRequest
+	5,  // 6: nofx.control.v1.TraderControl.Pause:input_type -> nofx.control.v1.PauseRequest
+	3,  // 7: nofx.control.v1.TraderControl.Resume:input_type -> nofx.control.v1.TraderRequest
+	6,  // 8: nofx.control.v1.TraderControl.OverrideRisk:input_type -> nofx.control.v1.OverrideRiskRequest
+	7,  // 9: nofx.control.v1.TraderControl.SwitchProfile:input_type -> nofx.control.v1.SwitchProfileRequest
+	8,  // 10: nofx.control.v1.TraderControl.SetWatchlist:input_type -> nofx.control.v1.SetWatchlistRequest
+	10, // 11: nofx.control.v1.TraderControl.AnnotatePosition:input_type -> nofx.control.v1.AnnotatePositionRequest
+	11, // 12: nofx.control.v1.TraderControl.AdjustPosition:input_type -> nofx.control.v1.AdjustPositionRequest
+	9,  // 13: nofx.control.v1.TraderControl.ClosePosition:input_type -> nofx.control.v1.PositionRequest
+	13, // 14: nofx.control.v1.TraderControl.StreamDecisions:input_type -> nofx.control.v1.StreamDecisionsRequest
+	2,  // 15: nofx.control.v1.TraderControl.ListTraders:output_type -> nofx.control.v1.ListTradersResponse
+	4,  // 16: nofx.control.v1.TraderControl.GetStatus:output_type -> nofx.control.v1.TraderStatus
+	12, // 17: nofx.control.v1.TraderControl.Start:output_type -> nofx.control.v1.ControlResponse
+	12, // 18: nofx.control.v1.TraderControl.Stop:output_type -> nofx.control.v1.ControlResponse
+	12, // 19: nofx.control.v1.TraderControl.Pause:output_type -> nofx.control.v1.ControlResponse
+	12, // 20: nofx.control.v1.TraderControl.Resume:output_type -> nofx.control.v1.ControlResponse
+	12, // 21: nofx.control.v1.TraderControl.OverrideRisk:output_type -> nofx.control.v1.ControlResponse
+	12, // 22: nofx.control.v1.TraderControl.SwitchProfile:output_type -> nofx.control.v1.ControlResponse
+	12, // 23: nofx.control.v1.TraderControl.SetWatchlist:output_type -> nofx.control.v1.ControlResponse
+	12, // 24: nofx.control.v1.TraderControl.AnnotatePosition:output_type -> nofx.control.v1.ControlResponse
+	12, // 25: nofx.control.v1.TraderControl.AdjustPosition:output_type -> nofx.control.v1.ControlResponse
+	12, // 26: nofx.control.v1.TraderControl.ClosePosition:output_type -> nofx.control.v1.ControlResponse
+	15, // 27: nofx.control.v1.TraderControl.StreamDecisions:output_type -> nofx.control.v1.DecisionEvent
+	15, // [15:28] is the sub-list for method output_type
+	2,  // [2:15] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_control_proto_init() }
+func file_control_proto_init() {
+	if File_control_proto != nil {
+		return
+	}
+	file_control_proto_msgTypes[6].OneofWrappers = []any{}
+	file_control_proto_msgTypes[10].OneofWrappers = []any{}
+	file_control_proto_msgTypes[11].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_control_proto_rawDesc), len(file_control_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_control_proto_goTypes,
+		DependencyIndexes: file_control_proto_depIdxs,
+		MessageInfos:      file_control_proto_msgTypes,
+	}.Build()
+	File_control_proto = out.File
+	file_control_proto_goTypes = nil
+	file_control_proto_depIdxs = nil
+}
@@ -0,0 +1,581 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: control.proto
+
+package controlpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TraderControl_ListTraders_FullMethodName      = "/nofx.control.v1.TraderControl/ListTraders"
+	TraderControl_GetStatus_FullMethodName        = "/nofx.control.v1.TraderControl/GetStatus"
+	TraderControl_Start_FullMethodName            = "/nofx.control.v1.TraderControl/Start"
+	TraderControl_Stop_FullMethodName             = "/nofx.control.v1.TraderControl/Stop"
+	TraderControl_Pause_FullMethodName            = "/nofx.control.v1.TraderControl/Pause"
+	TraderControl_Resume_FullMethodName           = "/nofx.control.v1.TraderControl/Resume"
+	TraderControl_OverrideRisk_FullMethodName     = "/nofx.control.v1.TraderControl/OverrideRisk"
+	TraderControl_SwitchProfile_FullMethodName    = "/nofx.control.v1.TraderControl/SwitchProfile"
+	TraderControl_SetWatchlist_FullMethodName     = "/nofx.control.v1.TraderControl/SetWatchlist"
+	TraderControl_AnnotatePosition_FullMethodName = "/nofx.control.v1.TraderControl/AnnotatePosition"
+	TraderControl_AdjustPosition_FullMethodName   = "/nofx.control.v1.TraderControl/AdjustPosition"
+	TraderControl_ClosePosition_FullMethodName    = "/nofx.control.v1.TraderControl/ClosePosition"
+	TraderControl_StreamDecisions_FullMethodName  = "/nofx.control.v1.TraderControl/StreamDecisions"
+)
+
+// TraderControlClient is the client API for TraderControl service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TraderControlClient interface {
+	ListTraders(ctx context.Context, in *ListTradersRequest, opts ...grpc.CallOption) (*ListTradersResponse, error)
+	GetStatus(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*TraderStatus, error)
+	Start(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*ControlResponse, error)
+	Stop(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*ControlResponse, error)
+	Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*ControlResponse, error)
+	Resume(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*ControlResponse, error)
+	OverrideRisk(ctx context.Context, in *OverrideRiskRequest, opts ...grpc.CallOption) (*ControlResponse, error)
+	SwitchProfile(ctx context.Context, in *SwitchProfileRequest, opts ...grpc.CallOption) (*ControlResponse, error)
+	SetWatchlist(ctx context.Context, in *SetWatchlistRequest, opts ...grpc.CallOption) (*ControlResponse, error)
+	AnnotatePosition(ctx context.Context, in *AnnotatePositionRequest, opts ...grpc.CallOption) (*ControlResponse, error)
+	AdjustPosition(ctx context.Context, in *AdjustPositionRequest, opts ...grpc.CallOption) (*ControlResponse, error)
+	ClosePosition(ctx context.Context, in *PositionRequest, opts ...grpc.CallOption) (*ControlResponse, error)
+	StreamDecisions(ctx context.Context, in *StreamDecisionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DecisionEvent], error)
+}
+
+type traderControlClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTraderControlClient(cc grpc.ClientConnInterface) TraderControlClient {
+	return &traderControlClient{cc}
+}
+
+func (c *traderControlClient) ListTraders(ctx context.Context, in *ListTradersRequest, opts ...grpc.CallOption) (*ListTradersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTradersResponse)
+	err := c.cc.Invoke(ctx, TraderControl_ListTraders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) GetStatus(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*TraderStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TraderStatus)
+	err := c.cc.Invoke(ctx, TraderControl_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) Start(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*ControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlResponse)
+	err := c.cc.Invoke(ctx, TraderControl_Start_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) Stop(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*ControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlResponse)
+	err := c.cc.Invoke(ctx, TraderControl_Stop_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*ControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlResponse)
+	err := c.cc.Invoke(ctx, TraderControl_Pause_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) Resume(ctx context.Context, in *TraderRequest, opts ...grpc.CallOption) (*ControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlResponse)
+	err := c.cc.Invoke(ctx, TraderControl_Resume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) OverrideRisk(ctx context.Context, in *OverrideRiskRequest, opts ...grpc.CallOption) (*ControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlResponse)
+	err := c.cc.Invoke(ctx, TraderControl_OverrideRisk_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) SwitchProfile(ctx context.Context, in *SwitchProfileRequest, opts ...grpc.CallOption) (*ControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlResponse)
+	err := c.cc.Invoke(ctx, TraderControl_SwitchProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) SetWatchlist(ctx context.Context, in *SetWatchlistRequest, opts ...grpc.CallOption) (*ControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlResponse)
+	err := c.cc.Invoke(ctx, TraderControl_SetWatchlist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) AnnotatePosition(ctx context.Context, in *AnnotatePositionRequest, opts ...grpc.CallOption) (*ControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlResponse)
+	err := c.cc.Invoke(ctx, TraderControl_AnnotatePosition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) AdjustPosition(ctx context.Context, in *AdjustPositionRequest, opts ...grpc.CallOption) (*ControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlResponse)
+	err := c.cc.Invoke(ctx, TraderControl_AdjustPosition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) ClosePosition(ctx context.Context, in *PositionRequest, opts ...grpc.CallOption) (*ControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlResponse)
+	err := c.cc.Invoke(ctx, TraderControl_ClosePosition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *traderControlClient) StreamDecisions(ctx context.Context, in *StreamDecisionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DecisionEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TraderControl_ServiceDesc.Streams[0], TraderControl_StreamDecisions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamDecisionsRequest, DecisionEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TraderControl_StreamDecisionsClient = grpc.ServerStreamingClient[DecisionEvent]
+
+// TraderControlServer is the server API for TraderControl service.
+// All implementations must embed UnimplementedTraderControlServer
+// for forward compatibility.
+type TraderControlServer interface {
+	ListTraders(context.Context, *ListTradersRequest) (*ListTradersResponse, error)
+	GetStatus(context.Context, *TraderRequest) (*TraderStatus, error)
+	Start(context.Context, *TraderRequest) (*ControlResponse, error)
+	Stop(context.Context, *TraderRequest) (*ControlResponse, error)
+	Pause(context.Context, *PauseRequest) (*ControlResponse, error)
+	Resume(context.Context, *TraderRequest) (*ControlResponse, error)
+	OverrideRisk(context.Context, *OverrideRiskRequest) (*ControlResponse, error)
+	SwitchProfile(context.Context, *SwitchProfileRequest) (*ControlResponse, error)
+	SetWatchlist(context.Context, *SetWatchlistRequest) (*ControlResponse, error)
+	AnnotatePosition(context.Context, *AnnotatePositionRequest) (*ControlResponse, error)
+	AdjustPosition(context.Context, *AdjustPositionRequest) (*ControlResponse, error)
+	ClosePosition(context.Context, *PositionRequest) (*ControlResponse, error)
+	StreamDecisions(*StreamDecisionsRequest, grpc.ServerStreamingServer[DecisionEvent]) error
+	mustEmbedUnimplementedTraderControlServer()
+}
+
+// UnimplementedTraderControlServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTraderControlServer struct{}
+
+func (UnimplementedTraderControlServer) ListTraders(context.Context, *ListTradersRequest) (*ListTradersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTraders not implemented")
+}
+func (UnimplementedTraderControlServer) GetStatus(context.Context, *TraderRequest) (*TraderStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedTraderControlServer) Start(context.Context, *TraderRequest) (*ControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Start not implemented")
+}
+func (UnimplementedTraderControlServer) Stop(context.Context, *TraderRequest) (*ControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
+}
+func (UnimplementedTraderControlServer) Pause(context.Context, *PauseRequest) (*ControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedTraderControlServer) Resume(context.Context, *TraderRequest) (*ControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedTraderControlServer) OverrideRisk(context.Context, *OverrideRiskRequest) (*ControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OverrideRisk not implemented")
+}
+func (UnimplementedTraderControlServer) SwitchProfile(context.Context, *SwitchProfileRequest) (*ControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SwitchProfile not implemented")
+}
+func (UnimplementedTraderControlServer) SetWatchlist(context.Context, *SetWatchlistRequest) (*ControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetWatchlist not implemented")
+}
+func (UnimplementedTraderControlServer) AnnotatePosition(context.Context, *AnnotatePositionRequest) (*ControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnnotatePosition not implemented")
+}
+func (UnimplementedTraderControlServer) AdjustPosition(context.Context, *AdjustPositionRequest) (*ControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdjustPosition not implemented")
+}
+func (UnimplementedTraderControlServer) ClosePosition(context.Context, *PositionRequest) (*ControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClosePosition not implemented")
+}
+func (UnimplementedTraderControlServer) StreamDecisions(*StreamDecisionsRequest, grpc.ServerStreamingServer[DecisionEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamDecisions not implemented")
+}
+func (UnimplementedTraderControlServer) mustEmbedUnimplementedTraderControlServer() {}
+func (UnimplementedTraderControlServer) testEmbeddedByValue()                       {}
+
+// UnsafeTraderControlServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TraderControlServer will
+// result in compilation errors.
+type UnsafeTraderControlServer interface {
+	mustEmbedUnimplementedTraderControlServer()
+}
+
+func RegisterTraderControlServer(s grpc.ServiceRegistrar, srv TraderControlServer) {
+	// If the following call pancis, it indicates UnimplementedTraderControlServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TraderControl_ServiceDesc, srv)
+}
+
+func _TraderControl_ListTraders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTradersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).ListTraders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_ListTraders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).ListTraders(ctx, req.(*ListTradersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).GetStatus(ctx, req.(*TraderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).Start(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_Start_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).Start(ctx, req.(*TraderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_Stop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).Stop(ctx, req.(*TraderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).Pause(ctx, req.(*PauseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).Resume(ctx, req.(*TraderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_OverrideRisk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OverrideRiskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).OverrideRisk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_OverrideRisk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).OverrideRisk(ctx, req.(*OverrideRiskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_SwitchProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwitchProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).SwitchProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_SwitchProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).SwitchProfile(ctx, req.(*SwitchProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_SetWatchlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetWatchlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).SetWatchlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_SetWatchlist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).SetWatchlist(ctx, req.(*SetWatchlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_AnnotatePosition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnnotatePositionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).AnnotatePosition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_AnnotatePosition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).AnnotatePosition(ctx, req.(*AnnotatePositionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_AdjustPosition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjustPositionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).AdjustPosition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_AdjustPosition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).AdjustPosition(ctx, req.(*AdjustPositionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_ClosePosition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PositionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraderControlServer).ClosePosition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TraderControl_ClosePosition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraderControlServer).ClosePosition(ctx, req.(*PositionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TraderControl_StreamDecisions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamDecisionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TraderControlServer).StreamDecisions(m, &grpc.GenericServerStream[StreamDecisionsRequest, DecisionEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TraderControl_StreamDecisionsServer = grpc.ServerStreamingServer[DecisionEvent]
+
+// TraderControl_ServiceDesc is the grpc.ServiceDesc for TraderControl service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TraderControl_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nofx.control.v1.TraderControl",
+	HandlerType: (*TraderControlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListTraders",
+			Handler:    _TraderControl_ListTraders_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _TraderControl_GetStatus_Handler,
+		},
+		{
+			MethodName: "Start",
+			Handler:    _TraderControl_Start_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _TraderControl_Stop_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _TraderControl_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _TraderControl_Resume_Handler,
+		},
+		{
+			MethodName: "OverrideRisk",
+			Handler:    _TraderControl_OverrideRisk_Handler,
+		},
+		{
+			MethodName: "SwitchProfile",
+			Handler:    _TraderControl_SwitchProfile_Handler,
+		},
+		{
+			MethodName: "SetWatchlist",
+			Handler:    _TraderControl_SetWatchlist_Handler,
+		},
+		{
+			MethodName: "AnnotatePosition",
+			Handler:    _TraderControl_AnnotatePosition_Handler,
+		},
+		{
+			MethodName: "AdjustPosition",
+			Handler:    _TraderControl_AdjustPosition_Handler,
+		},
+		{
+			MethodName: "ClosePosition",
+			Handler:    _TraderControl_ClosePosition_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamDecisions",
+			Handler:       _TraderControl_StreamDecisions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "control.proto",
+}
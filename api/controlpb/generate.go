@@ -0,0 +1,4 @@
+// Package controlpb gRPC控制接口的协议定义（由 control.proto 生成）
+package controlpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative control.proto
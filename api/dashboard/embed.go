@@ -0,0 +1,21 @@
+// Package dashboard 内置Web仪表盘（静态页面通过go:embed打包进二进制，单文件部署）
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// FileSystem 返回仪表盘静态资源（以static目录为根）
+func FileSystem() http.FileSystem {
+	sub, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		// static目录在编译期嵌入，不可能不存在
+		panic(err)
+	}
+	return http.FS(sub)
+}
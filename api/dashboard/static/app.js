@@ -0,0 +1,238 @@
+// NOFX 内置仪表盘：只读展示，所有数据来自 /api/*
+(function () {
+  'use strict';
+
+  const REFRESH_MS = 15000;
+  let traderId = '';
+
+  const $ = (id) => document.getElementById(id);
+
+  async function fetchJSON(path) {
+    const sep = path.includes('?') ? '&' : '?';
+    const url = traderId ? `${path}${sep}trader_id=${encodeURIComponent(traderId)}` : path;
+    const resp = await fetch(url);
+    if (!resp.ok) {
+      throw new Error(`${path}: HTTP ${resp.status}`);
+    }
+    return resp.json();
+  }
+
+  function fmt(n, digits = 2) {
+    if (n === undefined || n === null || isNaN(n)) return '-';
+    return Number(n).toFixed(digits);
+  }
+
+  function signed(n, digits = 2) {
+    if (n === undefined || n === null || isNaN(n)) return '-';
+    return (n >= 0 ? '+' : '') + Number(n).toFixed(digits);
+  }
+
+  function pnlClass(n) {
+    return n >= 0 ? 'up' : 'down';
+  }
+
+  function escapeHTML(s) {
+    return String(s || '').replace(/[&<>"']/g, (c) => ({
+      '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;',
+    }[c]));
+  }
+
+  // 简单折线图（不依赖第三方库，保持单文件部署）
+  function drawLine(canvas, values, opts = {}) {
+    const ctx = canvas.getContext('2d');
+    const dpr = window.devicePixelRatio || 1;
+    const width = canvas.clientWidth;
+    const height = canvas.clientHeight || Number(canvas.getAttribute('height'));
+    canvas.width = width * dpr;
+    canvas.height = height * dpr;
+    ctx.scale(dpr, dpr);
+    ctx.clearRect(0, 0, width, height);
+
+    if (values.length < 2) {
+      ctx.fillStyle = '#848e9c';
+      ctx.fillText('暂无数据', 10, 20);
+      return;
+    }
+
+    const pad = { left: 60, right: 10, top: 10, bottom: 20 };
+    let min = opts.min !== undefined ? opts.min : Math.min(...values);
+    let max = opts.max !== undefined ? opts.max : Math.max(...values);
+    if (max === min) {
+      max += 1;
+      min -= 1;
+    }
+    const x = (i) => pad.left + (i / (values.length - 1)) * (width - pad.left - pad.right);
+    const y = (v) => pad.top + (1 - (v - min) / (max - min)) * (height - pad.top - pad.bottom);
+
+    // 坐标轴刻度
+    ctx.strokeStyle = '#2b3139';
+    ctx.fillStyle = '#848e9c';
+    ctx.font = '11px sans-serif';
+    for (let i = 0; i <= 4; i++) {
+      const v = min + ((max - min) * i) / 4;
+      const yy = y(v);
+      ctx.beginPath();
+      ctx.moveTo(pad.left, yy);
+      ctx.lineTo(width - pad.right, yy);
+      ctx.stroke();
+      ctx.fillText(opts.format ? opts.format(v) : fmt(v), 4, yy + 4);
+    }
+
+    // 基准线（如初始余额、50%准确率）
+    if (opts.baseline !== undefined && opts.baseline > min && opts.baseline < max) {
+      ctx.strokeStyle = '#848e9c';
+      ctx.setLineDash([4, 4]);
+      ctx.beginPath();
+      ctx.moveTo(pad.left, y(opts.baseline));
+      ctx.lineTo(width - pad.right, y(opts.baseline));
+      ctx.stroke();
+      ctx.setLineDash([]);
+    }
+
+    ctx.strokeStyle = opts.color || '#f0b90b';
+    ctx.lineWidth = 1.5;
+    ctx.beginPath();
+    values.forEach((v, i) => {
+      if (i === 0) ctx.moveTo(x(i), y(v));
+      else ctx.lineTo(x(i), y(v));
+    });
+    ctx.stroke();
+  }
+
+  async function loadTraders() {
+    const traders = await fetchJSON('/api/traders');
+    const select = $('trader-select');
+    select.innerHTML = traders
+      .map((t) => `<option value="${escapeHTML(t.trader_id)}">${escapeHTML(t.trader_name)} (${escapeHTML(t.ai_model)})</option>`)
+      .join('');
+    if (traders.length > 0) {
+      traderId = traders[0].trader_id;
+    }
+    select.addEventListener('change', () => {
+      traderId = select.value;
+      refresh();
+    });
+  }
+
+  function renderStatus(status) {
+    const badge = $('status-badge');
+    if (status.observe_mode) {
+      badge.textContent = '观察模式';
+      badge.className = 'badge observe';
+    } else if (status.is_running) {
+      badge.textContent = `运行中 · 第${status.call_count}周期`;
+      badge.className = 'badge running';
+    } else {
+      badge.textContent = '已停止';
+      badge.className = 'badge';
+    }
+
+    const c = status.constraints || {};
+    const rows = [
+      ['今日开仓', `${c.daily_trades ?? '-'} / ${c.max_daily_trades ?? '-'}（${c.daily_reset_in ?? '-'}后重置）`],
+      ['本小时开仓', `${c.hourly_trades ?? '-'} / ${c.max_hourly_trades ?? '-'}（${c.hourly_reset_in ?? '-'}后重置）`],
+      ['冷却中币种', c.cooldown_symbols ?? '-'],
+      ['暂停交易至', status.stop_until && !status.stop_until.startsWith('0001') ? new Date(status.stop_until).toLocaleString() : '-'],
+    ];
+    $('constraints').innerHTML = rows
+      .map(([k, v]) => `<dt>${k}</dt><dd>${escapeHTML(v)}</dd>`)
+      .join('');
+  }
+
+  function renderAccount(account) {
+    $('equity').textContent = `${fmt(account.total_equity)} USDT`;
+    $('available').textContent = `${fmt(account.available_balance)} USDT`;
+    const pnl = $('total-pnl');
+    pnl.textContent = `${signed(account.total_pnl)} (${signed(account.total_pnl_pct)}%)`;
+    pnl.className = `value ${pnlClass(account.total_pnl)}`;
+    $('margin-used').textContent = `${fmt(account.margin_used_pct)}%`;
+  }
+
+  function renderPositions(positions) {
+    const body = $('positions-body');
+    if (!positions || positions.length === 0) {
+      body.innerHTML = '<tr><td colspan="10" class="muted">无持仓</td></tr>';
+      return;
+    }
+    body.innerHTML = positions.map((p) => `
+      <tr>
+        <td>${escapeHTML(p.symbol)}</td>
+        <td class="${p.side === 'long' ? 'up' : 'down'}">${p.side === 'long' ? '多' : '空'}</td>
+        <td>${fmt(p.quantity, 4)}</td>
+        <td>${p.leverage}x</td>
+        <td>${fmt(p.entry_price, 4)}</td>
+        <td>${fmt(p.mark_price, 4)}</td>
+        <td>${p.stop_loss ? fmt(p.stop_loss, 4) : '-'}</td>
+        <td>${p.take_profit ? fmt(p.take_profit, 4) : '-'}</td>
+        <td>${fmt(p.liquidation_price, 4)}</td>
+        <td class="${pnlClass(p.unrealized_pnl)}">${signed(p.unrealized_pnl)} (${signed(p.unrealized_pnl_pct)}%)</td>
+      </tr>`).join('');
+  }
+
+  function renderEquity(history, initialBalance) {
+    const values = (history || []).map((p) => p.total_equity);
+    drawLine($('equity-chart'), values, { baseline: initialBalance });
+  }
+
+  function renderDecisions(records) {
+    $('decisions').innerHTML = (records || []).map((r) => {
+      const actions = (r.decisions || []).map((a) => {
+        const cls = a.success ? '' : 'down';
+        const tag = a.hypothetical ? '（假设）' : '';
+        return `<span class="${cls}">${escapeHTML(a.action)} ${escapeHTML(a.symbol)}${tag}</span>`;
+      }).join('');
+      return `
+        <div class="decision">
+          <div class="meta">#${r.cycle_number} · ${new Date(r.timestamp).toLocaleString()}${r.success ? '' : ' · ❌ ' + escapeHTML(r.error_message)}</div>
+          <div class="actions">${actions || '<span class="muted">无操作</span>'}</div>
+          <pre>${escapeHTML(r.cot_trace)}</pre>
+        </div>`;
+    }).join('') || '<div class="muted">暂无决策</div>';
+  }
+
+  function renderPredictions(data) {
+    const perf = data.performance || {};
+    const evaluated = (data.recent || []).filter((r) => r.evaluated).reverse(); // 从旧到新
+    $('prediction-summary').textContent =
+      `已评估 ${evaluated.length} 条 · 方向胜率 ${fmt(perf.overall_win_rate * 100, 1)}% · 平均准确度 ${fmt(perf.avg_accuracy * 100, 1)}%`;
+
+    // 滚动胜率（窗口10条）
+    const windowSize = 10;
+    const rolling = [];
+    evaluated.forEach((_, i) => {
+      const slice = evaluated.slice(Math.max(0, i - windowSize + 1), i + 1);
+      const correct = slice.filter((r) => r.is_correct).length;
+      rolling.push((correct / slice.length) * 100);
+    });
+    drawLine($('prediction-chart'), rolling, {
+      min: 0,
+      max: 100,
+      baseline: 50,
+      color: '#0ecb81',
+      format: (v) => `${v.toFixed(0)}%`,
+    });
+  }
+
+  async function refresh() {
+    const tasks = [
+      fetchJSON('/api/status').then((status) => {
+        renderStatus(status);
+        return fetchJSON('/api/equity-history').then((h) => renderEquity(h, status.initial_balance));
+      }),
+      fetchJSON('/api/account').then(renderAccount),
+      fetchJSON('/api/positions').then(renderPositions),
+      fetchJSON('/api/decisions/latest').then(renderDecisions),
+      fetchJSON('/api/predictions').then(renderPredictions),
+    ];
+    const results = await Promise.allSettled(tasks);
+    results
+      .filter((r) => r.status === 'rejected')
+      .forEach((r) => console.warn('仪表盘刷新失败:', r.reason));
+    $('updated-at').textContent = `更新于 ${new Date().toLocaleTimeString()}`;
+  }
+
+  loadTraders()
+    .then(refresh)
+    .catch((err) => console.error(err));
+  setInterval(refresh, REFRESH_MS);
+})();
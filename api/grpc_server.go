@@ -0,0 +1,370 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"nofx/api/controlpb"
+	"nofx/logger"
+	"nofx/manager"
+	"nofx/trader"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCServer gRPC控制接口服务器（启动/停止/暂停、状态查询、风控覆盖、决策事件推送）
+type GRPCServer struct {
+	service   *traderControlService
+	port      int
+	authToken string
+}
+
+// traderControlService TraderControl服务实现
+type traderControlService struct {
+	controlpb.UnimplementedTraderControlServer
+
+	traderManager *manager.TraderManager
+}
+
+// NewGRPCServer 创建gRPC控制服务器（authToken为空时不校验）
+func NewGRPCServer(traderManager *manager.TraderManager, port int, authToken string) *GRPCServer {
+	return &GRPCServer{
+		service:   &traderControlService{traderManager: traderManager},
+		port:      port,
+		authToken: authToken,
+	}
+}
+
+// Start 启动gRPC服务器（阻塞）
+func (s *GRPCServer) Start() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return fmt.Errorf("监听端口失败: %w", err)
+	}
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryAuth),
+		grpc.StreamInterceptor(s.streamAuth),
+	)
+	controlpb.RegisterTraderControlServer(server, s.service)
+
+	log.Printf("🎛️  gRPC控制接口启动在 :%d", s.port)
+	if s.authToken == "" {
+		log.Printf("⚠️  gRPC控制接口未设置grpc_auth_token，任何能访问该端口的客户端都可以控制trader")
+	}
+	return server.Serve(lis)
+}
+
+// authorize 校验 authorization: Bearer <token>
+func (s *GRPCServer) authorize(ctx context.Context) error {
+	if s.authToken == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if v == "Bearer "+s.authToken {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "认证失败")
+}
+
+func (s *GRPCServer) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *GRPCServer) streamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorize(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// getTrader 按ID获取trader（不存在时返回NotFound）
+func (s *traderControlService) getTrader(id string) (*trader.AutoTrader, error) {
+	at, err := s.traderManager.GetTrader(id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return at, nil
+}
+
+// ListTraders 列出所有trader
+func (s *traderControlService) ListTraders(ctx context.Context, req *controlpb.ListTradersRequest) (*controlpb.ListTradersResponse, error) {
+	resp := &controlpb.ListTradersResponse{}
+	for _, at := range s.traderManager.GetAllTraders() {
+		resp.Traders = append(resp.Traders, &controlpb.TraderInfo{
+			TraderId:   at.GetID(),
+			TraderName: at.GetName(),
+			AiModel:    at.GetAIModel(),
+			IsRunning:  at.IsRunning(),
+		})
+	}
+	return resp, nil
+}
+
+// GetStatus 查询trader运行状态
+func (s *traderControlService) GetStatus(ctx context.Context, req *controlpb.TraderRequest) (*controlpb.TraderStatus, error) {
+	at, err := s.getTrader(req.GetTraderId())
+	if err != nil {
+		return nil, err
+	}
+
+	st := at.GetStatus()
+	cfg := at.GetConfig()
+	resp := &controlpb.TraderStatus{
+		TraderId:           at.GetID(),
+		TraderName:         at.GetName(),
+		AiModel:            at.GetAIModel(),
+		IsRunning:          at.IsRunning(),
+		ObserveMode:        cfg.ObserveMode,
+		MaxDailyLoss:       cfg.MaxDailyLoss,
+		MaxDrawdown:        cfg.MaxDrawdown,
+		MaxRiskPerTradeUsd: cfg.MaxRiskPerTradeUSD,
+		StopTradingMinutes: int64(cfg.StopTradingTime / time.Minute),
+		StrategyProfile:    cfg.StrategyProfile,
+	}
+	if v, ok := st["call_count"].(int); ok {
+		resp.CallCount = int64(v)
+	}
+	if v, ok := st["runtime_minutes"].(int); ok {
+		resp.RuntimeMinutes = int64(v)
+	}
+	if paused, until := at.PauseState(); paused && !until.IsZero() {
+		resp.PausedUntilUnix = until.Unix()
+	} else if paused {
+		resp.PausedUntilUnix = -1 // 无限期暂停
+	}
+	return resp, nil
+}
+
+// Start 启动trader
+func (s *traderControlService) Start(ctx context.Context, req *controlpb.TraderRequest) (*controlpb.ControlResponse, error) {
+	if err := s.traderManager.StartTrader(req.GetTraderId()); err != nil {
+		return &controlpb.ControlResponse{Ok: false, Message: err.Error()}, nil
+	}
+	log.Printf("🎛️  [gRPC] 启动trader %s", req.GetTraderId())
+	return &controlpb.ControlResponse{Ok: true, Message: "已启动"}, nil
+}
+
+// Stop 停止trader
+func (s *traderControlService) Stop(ctx context.Context, req *controlpb.TraderRequest) (*controlpb.ControlResponse, error) {
+	if err := s.traderManager.StopTrader(req.GetTraderId()); err != nil {
+		return &controlpb.ControlResponse{Ok: false, Message: err.Error()}, nil
+	}
+	log.Printf("🎛️  [gRPC] 停止trader %s", req.GetTraderId())
+	return &controlpb.ControlResponse{Ok: true, Message: "已停止"}, nil
+}
+
+// Pause 暂停决策周期
+func (s *traderControlService) Pause(ctx context.Context, req *controlpb.PauseRequest) (*controlpb.ControlResponse, error) {
+	at, err := s.getTrader(req.GetTraderId())
+	if err != nil {
+		return nil, err
+	}
+	at.Pause(time.Duration(req.GetDurationMinutes()) * time.Minute)
+	return &controlpb.ControlResponse{Ok: true, Message: "已暂停"}, nil
+}
+
+// Resume 解除暂停（同时确认并解除提现/划转锁）
+func (s *traderControlService) Resume(ctx context.Context, req *controlpb.TraderRequest) (*controlpb.ControlResponse, error) {
+	at, err := s.getTrader(req.GetTraderId())
+	if err != nil {
+		return nil, err
+	}
+	at.Resume()
+	if at.AcknowledgeWithdrawalLock() {
+		log.Printf("🔓 [gRPC] trader %s 已确认外部提现/划转", req.GetTraderId())
+		return &controlpb.ControlResponse{Ok: true, Message: "已恢复，提现/划转锁已解除"}, nil
+	}
+	return &controlpb.ControlResponse{Ok: true, Message: "已恢复"}, nil
+}
+
+// OverrideRisk 覆盖风控参数（下一个决策周期生效）
+func (s *traderControlService) OverrideRisk(ctx context.Context, req *controlpb.OverrideRiskRequest) (*controlpb.ControlResponse, error) {
+	at, err := s.getTrader(req.GetTraderId())
+	if err != nil {
+		return nil, err
+	}
+
+	override := trader.RiskOverride{
+		MaxDailyLoss:       req.MaxDailyLoss,
+		MaxDrawdown:        req.MaxDrawdown,
+		MaxRiskPerTradeUSD: req.MaxRiskPerTradeUsd,
+	}
+	if req.StopTradingMinutes != nil {
+		d := time.Duration(*req.StopTradingMinutes) * time.Minute
+		override.StopTradingTime = &d
+	}
+	if err := at.OverrideRisk(override); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Printf("🎛️  [gRPC] 收到trader %s 的风控参数覆盖，将在下一个决策周期生效", req.GetTraderId())
+	return &controlpb.ControlResponse{Ok: true, Message: "将在下一个决策周期生效"}, nil
+}
+
+// SwitchProfile 切换策略档案（下一个决策周期生效）
+func (s *traderControlService) SwitchProfile(ctx context.Context, req *controlpb.SwitchProfileRequest) (*controlpb.ControlResponse, error) {
+	at, err := s.getTrader(req.GetTraderId())
+	if err != nil {
+		return nil, err
+	}
+	if err := at.SwitchStrategyProfile(req.GetProfile()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Printf("🎛️  [gRPC] trader %s 将切换到策略档案 %s", req.GetTraderId(), req.GetProfile())
+	return &controlpb.ControlResponse{Ok: true, Message: "将在下一个决策周期生效"}, nil
+}
+
+// SetWatchlist 设置用户关注币种（下一个决策周期生效）
+func (s *traderControlService) SetWatchlist(ctx context.Context, req *controlpb.SetWatchlistRequest) (*controlpb.ControlResponse, error) {
+	at, err := s.getTrader(req.GetTraderId())
+	if err != nil {
+		return nil, err
+	}
+	if err := at.SetWatchlist(req.GetSymbols()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Printf("⭐ [gRPC] trader %s 关注列表: %v", req.GetTraderId(), at.GetWatchlist())
+	return &controlpb.ControlResponse{Ok: true, Message: "将在下一个决策周期生效"}, nil
+}
+
+// AnnotatePosition 持仓备注 / 人工管理标记（下一个决策周期生效）
+func (s *traderControlService) AnnotatePosition(ctx context.Context, req *controlpb.AnnotatePositionRequest) (*controlpb.ControlResponse, error) {
+	at, err := s.getTrader(req.GetTraderId())
+	if err != nil {
+		return nil, err
+	}
+	if err := at.AnnotatePosition(req.GetSymbol(), req.GetSide(), req.Manual, req.Note); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Printf("🙋 [gRPC] trader %s 持仓标记: %s %s", req.GetTraderId(), req.GetSymbol(), req.GetSide())
+	return &controlpb.ControlResponse{Ok: true, Message: "将在下一个决策周期生效"}, nil
+}
+
+// AdjustPosition 调整持仓止损止盈（下一个决策周期执行）
+func (s *traderControlService) AdjustPosition(ctx context.Context, req *controlpb.AdjustPositionRequest) (*controlpb.ControlResponse, error) {
+	at, err := s.getTrader(req.GetTraderId())
+	if err != nil {
+		return nil, err
+	}
+	if err := at.AdjustPosition(req.GetSymbol(), req.GetSide(), req.GetStopLoss(), req.GetTakeProfit()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Printf("🙋 [gRPC] trader %s 调整持仓: %s %s 止损=%.4f 止盈=%.4f",
+		req.GetTraderId(), req.GetSymbol(), req.GetSide(), req.GetStopLoss(), req.GetTakeProfit())
+	return &controlpb.ControlResponse{Ok: true, Message: "将在下一个决策周期执行"}, nil
+}
+
+// ClosePosition 请求平仓（下一个决策周期执行）
+func (s *traderControlService) ClosePosition(ctx context.Context, req *controlpb.PositionRequest) (*controlpb.ControlResponse, error) {
+	at, err := s.getTrader(req.GetTraderId())
+	if err != nil {
+		return nil, err
+	}
+	if err := at.RequestClosePosition(req.GetSymbol(), req.GetSide()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Printf("🙋 [gRPC] trader %s 请求平仓: %s %s", req.GetTraderId(), req.GetSymbol(), req.GetSide())
+	return &controlpb.ControlResponse{Ok: true, Message: "将在下一个决策周期执行"}, nil
+}
+
+// tradeRecord 带trader ID的决策记录（多个trader汇聚到同一个推送流）
+type tradeRecord struct {
+	traderID string
+	record   *logger.DecisionRecord
+}
+
+// StreamDecisions 实时推送决策事件（trader_id为空时推送所有trader）
+func (s *traderControlService) StreamDecisions(req *controlpb.StreamDecisionsRequest, stream controlpb.TraderControl_StreamDecisionsServer) error {
+	var traders []*trader.AutoTrader
+	if req.GetTraderId() != "" {
+		at, err := s.getTrader(req.GetTraderId())
+		if err != nil {
+			return err
+		}
+		traders = append(traders, at)
+	} else {
+		for _, at := range s.traderManager.GetAllTraders() {
+			traders = append(traders, at)
+		}
+	}
+
+	ctx := stream.Context()
+	events := make(chan tradeRecord, 16)
+	for _, at := range traders {
+		ch, cancel := at.GetDecisionLogger().Subscribe(16)
+		defer cancel()
+
+		go func(traderID string, ch <-chan *logger.DecisionRecord) {
+			for record := range ch {
+				select {
+				case events <- tradeRecord{traderID: traderID, record: record}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(at.GetID(), ch)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			if err := stream.Send(decisionEvent(ev.traderID, ev.record)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// decisionEvent 决策记录 → gRPC事件
+func decisionEvent(traderID string, record *logger.DecisionRecord) *controlpb.DecisionEvent {
+	event := &controlpb.DecisionEvent{
+		TraderId:         traderID,
+		CycleNumber:      int64(record.CycleNumber),
+		TimestampUnix:    record.Timestamp.Unix(),
+		Success:          record.Success,
+		ErrorMessage:     record.ErrorMessage,
+		ObserveMode:      record.ObserveMode,
+		TotalEquity:      record.AccountState.TotalBalance,
+		AvailableBalance: record.AccountState.AvailableBalance,
+		PositionCount:    int32(record.AccountState.PositionCount),
+		ExecutionLog:     record.ExecutionLog,
+	}
+	for _, d := range record.Decisions {
+		event.Decisions = append(event.Decisions, &controlpb.DecisionAction{
+			Action:       d.Action,
+			Symbol:       d.Symbol,
+			Quantity:     d.Quantity,
+			Leverage:     int32(d.Leverage),
+			Price:        d.Price,
+			StopLoss:     d.StopLoss,
+			TakeProfit:   d.TakeProfit,
+			Success:      d.Success,
+			Hypothetical: d.Hypothetical,
+			Error:        d.Error,
+			Reasoning:    d.Reasoning,
+		})
+	}
+	if data, err := json.Marshal(record); err == nil {
+		event.RecordJson = string(data)
+	}
+	return event
+}
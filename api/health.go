@@ -0,0 +1,158 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"nofx/market"
+	"nofx/trader"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	healthCacheTTL      = 30 * time.Second // 探针频繁调用时复用结果，避免每次都请求交易所/LLM
+	clockSkewDegraded   = time.Second      // 超过1秒提示
+	clockSkewUnhealthy  = 5 * time.Second  // 超过币安默认recvWindow（5秒），签名请求会失败
+	diskFreeDegradedMB  = 500
+	diskFreeUnhealthyMB = 100
+)
+
+// healthReport 健康检查汇总
+type healthReport struct {
+	Status    string                          `json:"status"`
+	Time      string                          `json:"time"`
+	Checks    []trader.HealthCheck            `json:"checks"`
+	Traders   map[string][]trader.HealthCheck `json:"traders"`
+	CheckedAt time.Time                       `json:"-"`
+}
+
+type healthCache struct {
+	mu     sync.Mutex
+	report *healthReport
+}
+
+// handleHealth 健康检查
+// ok/degraded 返回200，unhealthy 返回503；?strict=1 时degraded也返回503
+func (s *Server) handleHealth(c *gin.Context) {
+	report := s.healthReport(c.Query("refresh") == "1")
+
+	code := http.StatusOK
+	if report.Status == trader.HealthUnhealthy || (c.Query("strict") == "1" && report.Status == trader.HealthDegraded) {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, report)
+}
+
+// healthReport 获取健康检查结果（带缓存）
+func (s *Server) healthReport(refresh bool) *healthReport {
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+
+	if !refresh && s.health.report != nil && time.Since(s.health.report.CheckedAt) < healthCacheTTL {
+		return s.health.report
+	}
+
+	report := &healthReport{
+		Status:  trader.HealthOK,
+		Checks:  []trader.HealthCheck{checkClockSkew(), checkDiskSpace("."), checkLiquidationStream()},
+		Traders: make(map[string][]trader.HealthCheck),
+	}
+
+	// 各trader并发自检
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, at := range s.traderManager.GetAllTraders() {
+		wg.Add(1)
+		go func(at *trader.AutoTrader) {
+			defer wg.Done()
+			checks := at.HealthChecks()
+			mu.Lock()
+			report.Traders[at.GetID()] = checks
+			mu.Unlock()
+		}(at)
+	}
+	wg.Wait()
+
+	worst := func(checks []trader.HealthCheck) {
+		for _, check := range checks {
+			if trader.HealthSeverity(check.Status) > trader.HealthSeverity(report.Status) {
+				report.Status = check.Status
+			}
+		}
+	}
+	worst(report.Checks)
+	for _, checks := range report.Traders {
+		worst(checks)
+	}
+
+	report.CheckedAt = time.Now()
+	report.Time = report.CheckedAt.Format(time.RFC3339)
+	s.health.report = report
+	return report
+}
+
+// checkClockSkew 本地时钟与交易所服务器时间的偏差
+func checkClockSkew() trader.HealthCheck {
+	check := trader.HealthCheck{Name: "clock_skew", Status: trader.HealthOK}
+
+	skew, err := market.ServerTimeSkew()
+	if err != nil {
+		check.Status = trader.HealthDegraded
+		check.Message = fmt.Sprintf("获取服务器时间失败: %v", err)
+		return check
+	}
+
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	check.Message = fmt.Sprintf("本地时钟偏差%+dms", skew.Milliseconds())
+	switch {
+	case abs > clockSkewUnhealthy:
+		check.Status = trader.HealthUnhealthy
+	case abs > clockSkewDegraded:
+		check.Status = trader.HealthDegraded
+	}
+	return check
+}
+
+// checkDiskSpace 日志所在磁盘的剩余空间
+func checkDiskSpace(path string) trader.HealthCheck {
+	check := trader.HealthCheck{Name: "disk_space", Status: trader.HealthOK}
+
+	free, total, err := diskUsage(path)
+	if err != nil {
+		check.Status = trader.HealthDegraded
+		check.Message = fmt.Sprintf("获取磁盘空间失败: %v", err)
+		return check
+	}
+
+	freeMB := free / 1024 / 1024
+	check.Message = fmt.Sprintf("剩余%dMB / %dMB", freeMB, total/1024/1024)
+	switch {
+	case freeMB < diskFreeUnhealthyMB:
+		check.Status = trader.HealthUnhealthy
+	case freeMB < diskFreeDegradedMB:
+		check.Status = trader.HealthDegraded
+	}
+	return check
+}
+
+// checkLiquidationStream 强平订单流WebSocket连接（未启用时为ok）
+func checkLiquidationStream() trader.HealthCheck {
+	check := trader.HealthCheck{Name: "liquidation_ws", Status: trader.HealthOK}
+
+	name, enabled, connected := market.LiquidationStreamStatus()
+	switch {
+	case !enabled:
+		check.Message = "未启用"
+	case !connected:
+		check.Status = trader.HealthDegraded
+		check.Message = fmt.Sprintf("%s未连接（重连中）", name)
+	default:
+		check.Message = name
+	}
+	return check
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package api
+
+import "syscall"
+
+// diskUsage 路径所在文件系统的可用空间与总空间（字节）
+func diskUsage(path string) (free, total uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, err
+	}
+	return st.Bavail * uint64(st.Bsize), st.Blocks * uint64(st.Bsize), nil
+}
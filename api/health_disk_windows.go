@@ -0,0 +1,10 @@
+//go:build windows
+
+package api
+
+import "errors"
+
+// diskUsage Windows下暂不支持
+func diskUsage(path string) (free, total uint64, err error) {
+	return 0, 0, errors.New("当前平台不支持磁盘空间检查")
+}
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"nofx/api/dashboard"
+	"nofx/decision/tracker"
+	"nofx/logger"
 	"nofx/manager"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,10 +22,12 @@ type Server struct {
 	router        *gin.Engine
 	traderManager *manager.TraderManager
 	port          int
+	health        healthCache // 🆕 健康检查结果缓存
+	webhookSecret string      // 📥 交易想法webhook密钥（空=不启用）
 }
 
-// NewServer 创建API服务器
-func NewServer(traderManager *manager.TraderManager, port int) *Server {
+// NewServer 创建API服务器（webhookSecret为空时不注册交易想法webhook）
+func NewServer(traderManager *manager.TraderManager, port int, webhookSecret string) *Server {
 	// 设置为Release模式（减少日志输出）
 	gin.SetMode(gin.ReleaseMode)
 
@@ -34,6 +40,7 @@ func NewServer(traderManager *manager.TraderManager, port int) *Server {
 		router:        router,
 		traderManager: traderManager,
 		port:          port,
+		webhookSecret: webhookSecret,
 	}
 
 	// 设置路由
@@ -63,6 +70,15 @@ func (s *Server) setupRoutes() {
 	// 健康检查
 	s.router.Any("/health", s.handleHealth)
 
+	// 📈 内置仪表盘（静态页面，数据来自下面的API）
+	s.router.StaticFS("/dashboard", dashboard.FileSystem())
+	s.router.GET("/", func(c *gin.Context) {
+		c.Redirect(http.StatusFound, "/dashboard/")
+	})
+
+	// 📡 实时事件推送（WebSocket）
+	s.router.GET("/ws", s.handleWebSocket)
+
 	// API路由组
 	api := s.router.Group("/api")
 	{
@@ -71,6 +87,7 @@ func (s *Server) setupRoutes() {
 
 		// Trader列表
 		api.GET("/traders", s.handleTraderList)
+		api.GET("/accounts", s.handleAccounts) // 🏦 按交易所账户/子账户汇总净值
 
 		// 指定trader的数据（使用query参数 ?trader_id=xxx）
 		api.GET("/status", s.handleStatus)
@@ -78,23 +95,29 @@ func (s *Server) setupRoutes() {
 		api.GET("/positions", s.handlePositions)
 		api.GET("/decisions", s.handleDecisions)
 		api.GET("/decisions/latest", s.handleLatestDecisions)
+		api.GET("/decisions/snapshot", s.handleDecisionSnapshot) // 📸 执行决策时的市场快照
+		api.GET("/close-fills", s.handleCloseFills)              // 🎯 止损/止盈/强平平仓审计
 		api.GET("/statistics", s.handleStatistics)
 		api.GET("/equity-history", s.handleEquityHistory)
+		api.GET("/equity-curve", s.handleEquityCurve) // 📈 持久化净值曲线与回撤/夏普
 		api.GET("/performance", s.handlePerformance)
-		api.GET("/memory", s.handleMemory) // 🧠 AI记忆系统
+		api.GET("/strategy-report", s.handleStrategyReport)     // 🏷️ 按策略来源拆分绩效
+		api.GET("/decision-outcomes", s.handleDecisionOutcomes) // 📈 已平仓交易按最终R值排名
+		api.GET("/shadow-report", s.handleShadowReport)         // 🪞 主引擎与影子引擎对比
+		api.GET("/memory", s.handleMemory)                      // 🧠 AI记忆系统
+		api.GET("/watchlist", s.handleWatchlist)                // ⭐ 用户关注币种
+		api.GET("/predictions", s.handlePredictions)            // 🎯 预测准确率
+		api.GET("/gate-report", s.handleGateReport)             // 🚧 开仓规则价值
 
 		// 📋 日志查看接口（用于远程诊断）
 		api.GET("/logs", s.handleLogs)
 		api.GET("/logs/errors", s.handleErrorLogs)
-	}
-}
 
-// handleHealth 健康检查
-func (s *Server) handleHealth(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
-		"time":   c.Request.Context().Value("time"),
-	})
+		// 📥 外部交易想法（TradingView警报、自定义扫描器），需要webhook_secret
+		if s.webhookSecret != "" {
+			api.POST("/webhook/ideas", s.handleTradeIdea)
+		}
+	}
 }
 
 // getTraderFromQuery 从query参数获取trader
@@ -123,6 +146,15 @@ func (s *Server) handleCompetition(c *gin.Context) {
 	c.JSON(http.StatusOK, comparison)
 }
 
+// handleAccounts 🏦 按账户汇总净值（同一账户下的多个trader只统计一次）
+func (s *Server) handleAccounts(c *gin.Context) {
+	accounts, totalEquity := s.traderManager.GetAccountSummaries()
+	c.JSON(http.StatusOK, gin.H{
+		"accounts":     accounts,
+		"total_equity": totalEquity,
+	})
+}
+
 // handleTraderList trader列表
 func (s *Server) handleTraderList(c *gin.Context) {
 	traders := s.traderManager.GetAllTraders()
@@ -237,6 +269,10 @@ func (s *Server) handleDecisions(c *gin.Context) {
 		})
 		return
 	}
+	// 📈 附上每个周期开仓的最终盈亏
+	if err := trader.GetDecisionLogger().AttachOutcomes(records); err != nil {
+		log.Printf("⚠️  决策盈亏归因失败: %v", err)
+	}
 
 	c.JSON(http.StatusOK, records)
 }
@@ -262,6 +298,9 @@ func (s *Server) handleLatestDecisions(c *gin.Context) {
 		})
 		return
 	}
+	if err := trader.GetDecisionLogger().AttachOutcomes(records); err != nil {
+		log.Printf("⚠️  决策盈亏归因失败: %v", err)
+	}
 
 	// 反转数组，让最新的在前面（用于列表显示）
 	// GetLatestRecords返回的是从旧到新（用于图表），这里需要从新到旧
@@ -272,6 +311,64 @@ func (s *Server) handleLatestDecisions(c *gin.Context) {
 	c.JSON(http.StatusOK, records)
 }
 
+// handleDecisionSnapshot 📸 指定周期执行决策时的市场快照（完整market.Data及扩展数据）
+func (s *Server) handleDecisionSnapshot(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cycle, err := strconv.Atoi(c.Query("cycle"))
+	if err != nil || cycle <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cycle参数必须是正整数"})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshot, err := trader.GetDecisionLogger().LoadMarketSnapshot(cycle)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// handleCloseFills 🎯 交易所侧平仓审计记录（平仓原因、触发价、成交价、滑点）
+func (s *Server) handleCloseFills(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	records, err := trader.GetDecisionLogger().GetCloseFills(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
 // handleStatistics 统计信息
 func (s *Server) handleStatistics(c *gin.Context) {
 	_, traderID, err := s.getTraderFromQuery(c)
@@ -383,6 +480,122 @@ func (s *Server) handleEquityHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, history)
 }
 
+// handleEquityCurve 📈 持久化净值曲线（默认最近7天）与风险指标
+func (s *Server) handleEquityCurve(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	days := 7
+	if d, err := strconv.Atoi(c.Query("days")); err == nil && d > 0 {
+		days = d
+	}
+	samples, stats := trader.GetEquityCurve(time.Now().AddDate(0, 0, -days))
+	c.JSON(http.StatusOK, gin.H{"stats": stats, "samples": samples})
+}
+
+// handleStrategyReport 🏷️ 按策略来源（ai/limit/basis/manual）拆分的成交绩效（默认最近30天）
+func (s *Server) handleStrategyReport(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	days := 30
+	if d, err := strconv.Atoi(c.Query("days")); err == nil && d > 0 {
+		days = d
+	}
+	report, err := trader.GetStrategyReport(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("获取策略绩效失败: %v", err),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"days": days, "strategies": report})
+}
+
+// handleDecisionOutcomes 📈 已平仓交易按最终R值排名（附开仓决策理由，用于比较AI推理模式的实际结果）
+// ?order=worst 最差的在前，?limit=N 返回条数（默认50）
+func (s *Server) handleDecisionOutcomes(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	decisionLogger := trader.GetDecisionLogger()
+	outcomes, err := decisionLogger.GetDecisionOutcomes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("获取决策盈亏归因失败: %v", err),
+		})
+		return
+	}
+	records, err := decisionLogger.GetLatestRecords(10000)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("获取决策日志失败: %v", err),
+		})
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	best := c.Query("order") != "worst"
+	ranked := logger.RankOutcomes(outcomes, records, best)
+	total := len(ranked)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	c.JSON(http.StatusOK, gin.H{"total": total, "outcomes": ranked})
+}
+
+// handleShadowReport 🪞 主引擎与影子引擎的模拟成绩对比
+func (s *Server) handleShadowReport(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	report := trader.GetShadowReport()
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未启用影子引擎（配置shadow_engine）"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 // handlePerformance AI历史表现分析（用于展示AI学习和反思）
 func (s *Server) handlePerformance(c *gin.Context) {
 	_, traderID, err := s.getTraderFromQuery(c)
@@ -397,9 +610,12 @@ func (s *Server) handlePerformance(c *gin.Context) {
 		return
 	}
 
-	// 分析最近100个周期的交易表现（避免长期持仓的交易记录丢失）
-	// 假设每3分钟一个周期，100个周期 = 5小时，足够覆盖大部分交易
-	performance, err := trader.GetDecisionLogger().AnalyzePerformance(100)
+	// 默认使用trader配置的统计窗口（performance_window_cycles），可用 ?cycles=N 覆盖
+	cycles := trader.PerformanceWindow()
+	if n, err := strconv.Atoi(c.Query("cycles")); err == nil && n > 0 && n <= 10000 {
+		cycles = n
+	}
+	performance, err := trader.GetDecisionLogger().AnalyzePerformance(cycles)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": fmt.Sprintf("分析历史表现失败: %v", err),
@@ -410,6 +626,40 @@ func (s *Server) handlePerformance(c *gin.Context) {
 	c.JSON(http.StatusOK, performance)
 }
 
+// handlePredictions 🎯 AI预测准确率（整体胜率 + 最近预测记录，用于仪表盘图表）
+func (s *Server) handlePredictions(c *gin.Context) {
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+
+	// 预测记录由决策引擎写入 ./prediction_logs（所有trader共用）
+	predTracker := tracker.NewPredictionTracker("./prediction_logs")
+	c.JSON(http.StatusOK, gin.H{
+		"performance": predTracker.GetPerformance(c.Query("symbol")),
+		"recent":      predTracker.GetRecentPredictions(limit),
+	})
+}
+
+// handleGateReport 🚧 各开仓规则拒绝的预测在事后的模拟表现（?since=72h 只统计最近一段时间）
+// 模拟在预测评估时完成（prediction_stats 命令），这里只汇总已评估的记录
+func (s *Server) handleGateReport(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("since格式错误（如72h）: %v", err)})
+			return
+		}
+		since = time.Now().Add(-d)
+	}
+
+	predTracker := tracker.NewPredictionTracker("./prediction_logs")
+	c.JSON(http.StatusOK, predTracker.GateValueReport(since))
+}
+
 // Start 启动服务器
 func (s *Server) Start() error {
 	addr := fmt.Sprintf(":%d", s.port)
@@ -417,23 +667,53 @@ func (s *Server) Start() error {
 	log.Printf("📊 API文档:")
 	log.Printf("  • GET  /api/competition      - 竞赛总览（对比所有trader）")
 	log.Printf("  • GET  /api/traders          - Trader列表")
+	log.Printf("  • GET  /api/accounts         - 按账户/子账户汇总净值")
 	log.Printf("  • GET  /api/status?trader_id=xxx     - 指定trader的系统状态")
 	log.Printf("  • GET  /api/account?trader_id=xxx    - 指定trader的账户信息")
 	log.Printf("  • GET  /api/positions?trader_id=xxx  - 指定trader的持仓列表")
 	log.Printf("  • GET  /api/decisions?trader_id=xxx  - 指定trader的决策日志")
 	log.Printf("  • GET  /api/decisions/latest?trader_id=xxx - 指定trader的最新决策")
+	log.Printf("  • GET  /api/decisions/snapshot?trader_id=xxx&cycle=N - 指定周期执行决策时的市场快照")
+	log.Printf("  • GET  /api/close-fills?trader_id=xxx&limit=N - 止损/止盈/强平平仓审计（触发价与成交价）")
 	log.Printf("  • GET  /api/statistics?trader_id=xxx - 指定trader的统计信息")
 	log.Printf("  • GET  /api/equity-history?trader_id=xxx - 指定trader的收益率历史数据")
-	log.Printf("  • GET  /api/performance?trader_id=xxx - 指定trader的AI学习表现分析")
+	log.Printf("  • GET  /api/equity-curve?trader_id=xxx&days=N - 净值曲线与日/周收益、最大回撤、夏普比率")
+	log.Printf("  • GET  /api/performance?trader_id=xxx&cycles=N - 指定trader的AI学习表现分析（夏普/索提诺/期望/R倍数/连胜连亏）")
+	log.Printf("  • GET  /api/strategy-report?trader_id=xxx&days=N - 按策略来源（ai/limit/basis/manual）拆分的成交绩效")
+	log.Printf("  • GET  /api/decision-outcomes?trader_id=xxx&order=best|worst - 已平仓交易按最终R值排名（附开仓理由）")
+	log.Printf("  • GET  /api/shadow-report?trader_id=xxx - 主引擎与影子引擎的决策一致率和模拟盈亏对比")
 	log.Printf("  • GET  /api/memory?trader_id=xxx - 指定trader的AI记忆系统")
+	log.Printf("  • GET  /api/watchlist?trader_id=xxx - 指定trader的用户关注币种")
+	log.Printf("  • GET  /api/predictions?limit=N - AI预测准确率")
+	log.Printf("  • GET  /api/gate-report?since=72h - 开仓规则价值（被拒绝开仓的模拟结果）")
 	log.Printf("  • GET  /api/logs?lines=N&filter=keyword - 系统日志（远程诊断）")
 	log.Printf("  • GET  /api/logs/errors?lines=N - 错误日志（远程诊断）")
+	if s.webhookSecret != "" {
+		log.Printf("  • POST /api/webhook/ideas?trader_id=xxx - 外部交易想法（下一周期交给AI确认，需要webhook_secret）")
+	}
 	log.Printf("  • GET  /health               - 健康检查")
+	log.Printf("📈 仪表盘: http://localhost%s/dashboard/", addr)
 	log.Println()
 
 	return s.router.Run(addr)
 }
 
+// handleWatchlist ⭐ 获取用户关注币种（修改走gRPC SetWatchlist）
+func (s *Server) handleWatchlist(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"symbols": trader.GetWatchlist()})
+}
+
 // handleMemory 🧠 获取AI记忆系统数据
 func (s *Server) handleMemory(c *gin.Context) {
 	_, traderID, err := s.getTraderFromQuery(c)
@@ -574,4 +854,3 @@ func readLastLines(filename string, maxLines int, filter string) ([]string, erro
 
 	return lines, nil
 }
-
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"nofx/trader"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tradeIdeaRequest 📥 webhook请求体（TradingView警报无法设置请求头，密钥可放在secret字段）
+type tradeIdeaRequest struct {
+	trader.TradeIdea
+	Secret   string `json:"secret,omitempty"`
+	TraderID string `json:"trader_id,omitempty"` // 空=投递给所有trader
+}
+
+// webhookAuthorized 校验 X-Webhook-Secret 请求头或请求体中的secret
+func (s *Server) webhookAuthorized(c *gin.Context, bodySecret string) bool {
+	secret := c.GetHeader("X-Webhook-Secret")
+	if secret == "" {
+		secret = bodySecret
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(s.webhookSecret)) == 1
+}
+
+// handleTradeIdea 📥 接收外部交易想法（下一个决策周期作为候选信号交给AI确认或否决，不直接下单）
+func (s *Server) handleTradeIdea(c *gin.Context) {
+	var req tradeIdeaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求体解析失败: " + err.Error()})
+		return
+	}
+	if !s.webhookAuthorized(c, req.Secret) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "认证失败"})
+		return
+	}
+
+	traderID := req.TraderID
+	if traderID == "" {
+		traderID = c.Query("trader_id")
+	}
+	var ids []string
+	if traderID != "" {
+		ids = []string{traderID}
+	} else {
+		ids = s.traderManager.GetTraderIDs()
+		sort.Strings(ids)
+	}
+
+	results := make(map[string]interface{}, len(ids))
+	accepted := 0
+	for _, id := range ids {
+		t, err := s.traderManager.GetTrader(id)
+		if err != nil {
+			results[id] = gin.H{"error": err.Error()}
+			continue
+		}
+		idea, err := t.SubmitTradeIdea(req.TradeIdea)
+		if err != nil {
+			results[id] = gin.H{"error": err.Error()}
+			continue
+		}
+		accepted++
+		results[id] = idea
+	}
+
+	status := http.StatusAccepted
+	if accepted == 0 {
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, gin.H{"accepted": accepted, "traders": results})
+}
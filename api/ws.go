@@ -0,0 +1,115 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"nofx/events"
+	"nofx/trader"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	wsPingInterval = 30 * time.Second
+	wsPongTimeout  = 2 * wsPingInterval
+	wsSendBuffer   = 64 // 每个连接的待发送事件缓冲
+)
+
+// wsUpgrader 与HTTP API一致允许跨域
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsMessage WebSocket推送消息
+type wsMessage struct {
+	Type     events.Type  `json:"type"`
+	TraderID string       `json:"trader_id"`
+	Data     events.Event `json:"data"`
+}
+
+// handleWebSocket 📡 实时推送交易器事件，替代轮询
+// ?trader_id=xxx 只推送指定trader（默认全部），?types=cycle_started,decision_executed 只推送指定事件类型
+func (s *Server) handleWebSocket(c *gin.Context) {
+	traders := make(map[string]*trader.AutoTrader)
+	if id := c.Query("trader_id"); id != "" {
+		at, err := s.traderManager.GetTrader(id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		traders[id] = at
+	} else {
+		traders = s.traderManager.GetAllTraders()
+	}
+
+	var types []events.Type
+	if raw := c.Query("types"); raw != "" {
+		for _, name := range strings.Split(raw, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				types = append(types, events.Type(name))
+			}
+		}
+	}
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("⚠️  WebSocket握手失败: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	msgs := make(chan wsMessage, wsSendBuffer)
+	done := make(chan struct{})
+	for id, at := range traders {
+		traderID := id
+		unsubscribe := at.Events().Subscribe("ws:"+c.ClientIP(), func(e events.Event) {
+			select {
+			case msgs <- wsMessage{Type: e.EventType(), TraderID: traderID, Data: e}:
+			case <-done:
+			}
+		}, types...)
+		defer unsubscribe()
+	}
+	defer close(done) // 先于取消订阅执行，释放阻塞中的订阅者
+
+	// 读循环：处理pong和客户端关闭（客户端不需要发送消息）
+	closed := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	log.Printf("📡 WebSocket客户端已连接: %s（%d个trader）", c.ClientIP(), len(traders))
+	defer log.Printf("📡 WebSocket客户端已断开: %s", c.ClientIP())
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case msg := <-msgs:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
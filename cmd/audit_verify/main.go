@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"nofx/logger"
+	"os"
+)
+
+// 🔗 决策记录审计哈希链校验工具（trader启用audit_chain后写入的记录，含月度归档）
+//
+//	go run ./cmd/audit_verify -dir decision_logs/<trader_id>
+//	go run ./cmd/audit_verify -dir decision_logs/<trader_id> -head <外部保存的链头哈希>
+//
+// 发现记录被修改、删除、插入或哈希被去掉时以退出码1结束（可用于cron巡检）
+func main() {
+	dir := flag.String("dir", "", "决策日志目录（decision_logs/<trader_id>）")
+	head := flag.String("head", "", "外部保存的链头哈希（可选，用于发现末尾记录被删除）")
+	asJSON := flag.Bool("json", false, "以JSON输出校验结果")
+	flag.Parse()
+
+	if *dir == "" {
+		log.Fatal("用法: audit_verify -dir decision_logs/<trader_id> [-head <hash>] [-json]")
+	}
+
+	report, err := logger.VerifyAuditChain(*dir, *head)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if *asJSON {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("📋 决策记录 %d 条：链上 %d 条，启用哈希链之前 %d 条\n", report.Records, report.Chained, report.Unchained)
+		if report.Chained > 0 {
+			fmt.Printf("🔗 链范围: 周期 %d → %d\n", report.FirstCycle, report.HeadCycle)
+			fmt.Printf("🔗 链头哈希: %s\n", report.HeadHash)
+		}
+		if report.Truncated {
+			fmt.Printf("ℹ️  链起点之前的记录已被清理，从周期 %d 开始校验\n", report.FirstCycle)
+		}
+		for _, p := range report.Problems {
+			if p.File != "" {
+				fmt.Printf("❌ %s（周期 %d）: %s\n", p.File, p.Cycle, p.Issue)
+			} else {
+				fmt.Printf("❌ %s\n", p.Issue)
+			}
+		}
+	}
+
+	if !report.OK() {
+		if !*asJSON {
+			fmt.Printf("⚠️  校验失败：发现 %d 个问题\n", len(report.Problems))
+		}
+		os.Exit(1)
+	}
+	if !*asJSON {
+		fmt.Println("✅ 哈希链完整")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"nofx/market"
+	"strings"
+	"time"
+)
+
+// 📚 研究数据集下载工具：按币种下载历史资金费率、持仓量、现货/永续基差到本地CSV（回测和离线分析notebook共用）
+//
+//	go run ./cmd/dataset -symbols BTCUSDT,ETHUSDT -days 180
+//
+// 已有文件只追加最后一行之后的数据，重复运行即增量更新（适合cron）
+func main() {
+	symbols := flag.String("symbols", "BTCUSDT,ETHUSDT", "币种列表（逗号分隔）")
+	dir := flag.String("dir", "data/dataset", "数据集目录")
+	days := flag.Int("days", 90, "首次下载回溯天数（持仓量最多30天）")
+	oiPeriod := flag.String("oi-period", "1h", "持仓量采样周期（5m/15m/30m/1h/2h/4h/6h/12h/1d）")
+	basisInterval := flag.String("basis-interval", "1h", "基差K线周期")
+	flag.Parse()
+
+	if *days <= 0 {
+		log.Fatal("days必须大于0")
+	}
+	initialStart := time.Now().AddDate(0, 0, -*days).UnixMilli()
+
+	failed := 0
+	for _, symbol := range strings.Split(*symbols, ",") {
+		symbol = market.Normalize(strings.TrimSpace(symbol))
+		if symbol == "" {
+			continue
+		}
+		log.Printf("📥 %s", symbol)
+
+		path := market.DatasetPath(*dir, symbol, "funding")
+		if err := update(path, initialStart, func(start int64) (int, error) {
+			records, fetchErr := market.FetchFundingHistory(symbol, start)
+			added, err := market.AppendFunding(path, records) // 分页中途失败时保留已取到的部分
+			if fetchErr != nil {
+				return added, fetchErr
+			}
+			return added, err
+		}); err != nil {
+			log.Printf("  ❌ 资金费率: %v", err)
+			failed++
+		}
+
+		oiPath := market.DatasetPath(*dir, symbol, "open_interest_"+*oiPeriod)
+		if err := update(oiPath, initialStart, func(start int64) (int, error) {
+			records, fetchErr := market.FetchOpenInterestHistory(symbol, *oiPeriod, start)
+			added, err := market.AppendOpenInterest(oiPath, records) // 分页中途失败时保留已取到的部分
+			if fetchErr != nil {
+				return added, fetchErr
+			}
+			return added, err
+		}); err != nil {
+			log.Printf("  ❌ 持仓量: %v", err)
+			failed++
+		}
+
+		basisPath := market.DatasetPath(*dir, symbol, "basis_"+*basisInterval)
+		if err := update(basisPath, initialStart, func(start int64) (int, error) {
+			records, fetchErr := market.FetchBasisHistory(symbol, *basisInterval, start)
+			added, err := market.AppendBasis(basisPath, records) // 分页中途失败时保留已取到的部分
+			if fetchErr != nil {
+				return added, fetchErr
+			}
+			return added, err
+		}); err != nil {
+			log.Printf("  ❌ 基差: %v", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		log.Fatalf("⚠️  %d个数据集更新失败（已写入的数据保留，重新运行会从断点继续）", failed)
+	}
+	log.Printf("✅ 数据集已更新: %s", *dir)
+}
+
+// update 从文件最后一行之后（新文件从initialStart）开始下载并追加
+func update(path string, initialStart int64, fetch func(start int64) (int, error)) error {
+	last, err := market.LastDatasetTime(path)
+	if err != nil {
+		return err
+	}
+	start := initialStart
+	if last > 0 {
+		start = last + 1
+	}
+	added, err := fetch(start)
+	log.Printf("  ✓ %s: 新增%d行", path, added)
+	return err
+}
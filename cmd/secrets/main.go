@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"nofx/secrets"
+	"os"
+	"sort"
+	"strings"
+)
+
+// 🔐 加密密钥文件管理工具（口令来自环境变量NOFX_SECRETS_PASSPHRASE）
+//
+//	go run ./cmd/secrets set <name>   从标准输入读取密钥值（避免出现在shell历史中）
+//	go run ./cmd/secrets list         列出密钥名称（不显示值）
+//	go run ./cmd/secrets rm <name>    删除密钥
+//
+// 文件路径默认secrets.enc，可用环境变量NOFX_SECRETS_FILE覆盖
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("用法: secrets set <name> | list | rm <name>")
+	}
+
+	path := os.Getenv("NOFX_SECRETS_FILE")
+	if path == "" {
+		path = secrets.DefaultFile
+	}
+	passphrase := os.Getenv(secrets.PassphraseEnv)
+	if passphrase == "" {
+		log.Fatalf("请设置环境变量: %s", secrets.PassphraseEnv)
+	}
+
+	values, err := secrets.ReadFile(path, passphrase)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	switch os.Args[1] {
+	case "set":
+		name := argName()
+		fmt.Fprintf(os.Stderr, "输入 %s 的值并回车: ", name)
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		value := strings.TrimSpace(line)
+		if value == "" {
+			log.Fatalf("❌ 未读取到密钥值: %v", err)
+		}
+		values[name] = value
+		save(path, passphrase, values)
+		fmt.Printf("✅ 已保存 %s（配置中使用 \"secret:%s\"）\n", name, name)
+	case "list":
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println(name)
+		}
+	case "rm":
+		name := argName()
+		if _, ok := values[name]; !ok {
+			log.Fatalf("❌ 密钥 %s 不存在", name)
+		}
+		delete(values, name)
+		save(path, passphrase, values)
+		fmt.Printf("✅ 已删除 %s\n", name)
+	default:
+		log.Fatalf("未知命令: %s", os.Args[1])
+	}
+}
+
+func argName() string {
+	if len(os.Args) < 3 || os.Args[2] == "" {
+		log.Fatal("请指定密钥名称")
+	}
+	return os.Args[2]
+}
+
+func save(path, passphrase string, values map[string]string) {
+	if err := secrets.WriteFile(path, passphrase, values); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -16,12 +17,13 @@ type TraderConfig struct {
 	QwenModel string `json:"qwen_model,omitempty"` // 具体Qwen模型（如qwen-plus/qwen-max）
 
 	// 交易平台选择（二选一）
-	Exchange string `json:"exchange"` // "binance" or "hyperliquid"
+	Exchange string `json:"exchange"` // "binance", "binance_coinm", "hyperliquid", "aster", "bybit" or "mock"
 
 	// 币安配置
 	BinanceAPIKey    string `json:"binance_api_key,omitempty"`
 	BinanceSecretKey string `json:"binance_secret_key,omitempty"`
 	BinanceTestnet   bool   `json:"binance_testnet,omitempty"` // 是否使用币安测试网
+	SubAccount       string `json:"sub_account,omitempty"`     // 🏦 使用binance_sub_accounts中的子账户（"auto"=分配到交易员最少的子账户），此时无需配置API密钥
 
 	// Hyperliquid配置
 	HyperliquidPrivateKey string `json:"hyperliquid_private_key,omitempty"`
@@ -33,6 +35,11 @@ type TraderConfig struct {
 	AsterSigner     string `json:"aster_signer,omitempty"`      // Aster API钱包地址
 	AsterPrivateKey string `json:"aster_private_key,omitempty"` // Aster API钱包私钥
 
+	// Bybit配置（v5统一账户）
+	BybitAPIKey    string `json:"bybit_api_key,omitempty"`
+	BybitSecretKey string `json:"bybit_secret_key,omitempty"`
+	BybitTestnet   bool   `json:"bybit_testnet,omitempty"`
+
 	// AI配置
 	QwenKey     string `json:"qwen_key,omitempty"`
 	DeepSeekKey string `json:"deepseek_key,omitempty"`
@@ -45,6 +52,330 @@ type TraderConfig struct {
 	InitialBalance      float64 `json:"initial_balance"`
 	ScanIntervalMinutes int     `json:"scan_interval_minutes"`
 	KlineInterval       string  `json:"kline_interval,omitempty"` // K线周期，如 "5m", "15m", "30m"，默认 "5m"
+
+	// 🕰️ 分用途K线周期（空=与kline_interval相同）：长期背景（EMA/ATR止损、量化体制）和入场时机检查（RSI/EMA回调）
+	LongTermInterval    string `json:"long_term_interval,omitempty"`
+	EntryTimingInterval string `json:"entry_timing_interval,omitempty"`
+
+	// 🆕 盈亏对账间隔（分钟），0=默认60分钟，-1=禁用（仅币安支持收益流水）
+	PnLReconcileMinutes int `json:"pnl_reconcile_minutes,omitempty"`
+
+	// 🆕 启动对账时无法匹配开仓记录的持仓如何处理："adopt"（默认，接管）/"close"（平仓）/"ignore"（忽略）
+	UnknownPositionPolicy string `json:"unknown_position_policy,omitempty"`
+
+	// 🕵️ 账户异常检测：相邻周期钱包余额下降超过该比例(%)且没有平仓可以解释时告警，0=默认2%，<0=不检测余额
+	AnomalyBalanceDropPct float64 `json:"anomaly_balance_drop_pct,omitempty"`
+
+	// 🔒 提现/划转锁：相邻周期没有交易时可用余额下降超过该比例(%)则暂停开新仓，直到gRPC Resume或设置withdrawal_lock_ack后重启，0=默认5%，<0=不启用
+	WithdrawalLockPct float64 `json:"withdrawal_lock_pct,omitempty"`
+	WithdrawalLockAck bool    `json:"withdrawal_lock_ack,omitempty"`
+
+	// 🛡️ 单笔最大风险（USDT）：仓位×止损距离超过该值时缩小仓位，0=不限制
+	MaxRiskPerTradeUSD float64 `json:"max_risk_per_trade_usd,omitempty"`
+
+	// 🏦 账户级别：按当前净值选择（min_equity不超过净值的最高一档），累计亏损禁止开仓阈值、单笔风险占净值比例、记忆保本区间随账户规模调整
+	AccountTiers []AccountTierConfig `json:"account_tiers,omitempty"`
+
+	// 🎯 按止损距离推导杠杆：杠杆 = stop_margin_loss_pct / 止损距离%，止损触发时亏损保证金的该比例（如25），保证金不变、仓位随杠杆缩放，0=不启用
+	StopMarginLossPct float64 `json:"stop_margin_loss_pct,omitempty"`
+
+	// ⏱️ 价格过期保护：AI预测所用的价格快照到下单之间，价格变动超过止损距离的该比例时放弃开仓，0=默认0.5，<0=不检查
+	StalePriceStopFraction float64 `json:"stale_price_stop_fraction,omitempty"`
+
+	// 📏 止损止盈最小距离：按交易所价格精度格式化后距当前价至少min_stop_ticks个价格步进（0=默认2）且不小于min_stop_distance_pct%，
+	// 不足时stop_distance_action="adjust"（默认）推远到最小距离，"reject"拒绝开仓
+	MinStopTicks       int     `json:"min_stop_ticks,omitempty"`
+	MinStopDistancePct float64 `json:"min_stop_distance_pct,omitempty"`
+	StopDistanceAction string  `json:"stop_distance_action,omitempty"`
+
+	// ⌛ 最长持仓时间（小时），超时且未明显盈利时由程序直接平仓
+	// 按市场体制区分：趋势（ADX≥25）默认24小时，震荡默认8小时；0=默认值，-1=不限制
+	MaxHoldHoursTrend float64 `json:"max_hold_hours_trend,omitempty"`
+	MaxHoldHoursRange float64 `json:"max_hold_hours_range,omitempty"`
+
+	// ⌛ AI单次调用超时（秒），0=默认240秒
+	AITimeoutSeconds int `json:"ai_timeout_seconds,omitempty"`
+	// ⌛ 每个决策周期的AI时间预算（秒），超出后中止剩余预测、持仓默认持有；0=扫描间隔的80%，-1=不限制
+	CycleBudgetSeconds int `json:"cycle_budget_seconds,omitempty"`
+
+	// 🗳️ 多模型集成预测：配置2-3个模型并行预测，"majority"（默认，多数投票）或 "weighted"（按历史准确率加权）
+	EnsembleModels []EnsembleModelConfig `json:"ensemble_models,omitempty"`
+	EnsembleMode   string                `json:"ensemble_mode,omitempty"`
+
+	// 🚨 山寨币异动信号注入决策（仅Binance）：近期高置信的量价/OI异动、现货期货价差信号作为额外候选币种交给AI
+	AltcoinSignals bool `json:"altcoin_signals,omitempty"`
+
+	// 🪙 候选币种的计价（保证金）币种偏好（仅Binance支持USDC）：如["USDC","USDT"]表示优先交易USDC永续，无USDC合约时用USDT，空=只用USDT
+	QuoteAssets []string `json:"quote_assets,omitempty"`
+
+	// 📐 现货期货基差策略（仅Binance）：独立于AI的保证金预算，策略币种不交给AI
+	Basis *BasisConfig `json:"basis,omitempty"`
+
+	// 🎚️ 保证金模式与杠杆策略："isolated"（默认）或 "cross"；symbol_policies 按币种覆盖模式和杠杆上限
+	MarginMode     string                        `json:"margin_mode,omitempty"`
+	SymbolPolicies map[string]SymbolPolicyConfig `json:"symbol_policies,omitempty"`
+
+	// 📐 组合仓位分配："risk_parity"（按止损距离等风险分配保证金）或 "equal_weight"（平均分配），默认逐个计算
+	PositionAllocation      string `json:"position_allocation,omitempty"`
+	MaxNewPositionsPerCycle int    `json:"max_new_positions_per_cycle,omitempty"` // 单周期最多新开仓数量（默认1）
+
+	// 🎟️ 冷却期豁免：AI标记override_cooldown时每日最多豁免次数（0=不允许）及最低信心度（默认85）
+	CooldownOverridesPerDay       int `json:"cooldown_overrides_per_day,omitempty"`
+	CooldownOverrideMinConfidence int `json:"cooldown_override_min_confidence,omitempty"`
+
+	// 🎚️ 按类别/币种覆盖硬约束：键为 "majors"、"alts" 或币种（如 "DOGEUSDT"），币种覆盖优先于类别，未设置的字段继承默认值
+	// major_symbols 为主流币列表（默认 BTCUSDT、ETHUSDT）
+	ConstraintOverrides map[string]ConstraintOverrideConfig `json:"constraint_overrides,omitempty"`
+	MajorSymbols        []string                            `json:"major_symbols,omitempty"`
+
+	// 🗜️ 决策日志归档：N天前的记录按月压缩归档（0=不归档），归档保留天数（0=永久保留）
+	DecisionLogArchiveDays   int `json:"decision_log_archive_days,omitempty"`
+	DecisionLogRetentionDays int `json:"decision_log_retention_days,omitempty"`
+
+	// 🔗 决策记录审计哈希链：每条记录写入上一条的哈希，可用 cmd/audit_verify 校验历史是否被改动
+	AuditChain bool `json:"audit_chain,omitempty"`
+
+	// 🛡️ 持仓上限：总持仓数（默认3）、多/空仓币种数（默认各1）、总名义敞口净值倍数（0=不限制）
+	MaxPositions        int     `json:"max_positions,omitempty"`
+	MaxLongPositions    int     `json:"max_long_positions,omitempty"`
+	MaxShortPositions   int     `json:"max_short_positions,omitempty"`
+	MaxNotionalMultiple float64 `json:"max_notional_multiple,omitempty"`
+
+	// 🎛️ 策略档案：scalper/swing/conservative，打包扫描周期、K线周期、开仓阈值、止损倍数、仓位分配和持仓时长（显式配置优先）
+	StrategyProfile string `json:"strategy_profile,omitempty"`
+
+	// 🐕 交易所失联看门狗：连续失联多少分钟判定为中断（0=默认3，<0=禁用）；恢复后是否平掉没有止损单的持仓
+	ConnectivityLossMinutes       int  `json:"connectivity_loss_minutes,omitempty"`
+	FlattenUnprotectedOnReconnect bool `json:"flatten_unprotected_on_reconnect,omitempty"`
+
+	// 🛡️ 止损单巡检：每N秒检查持仓的止损单，缺失时按记录补设（0=默认60，<0=禁用）；连续补设失败N次后告警（0=默认3）
+	ProtectiveMonitorSeconds    int `json:"protective_monitor_seconds,omitempty"`
+	ProtectiveMonitorAlertAfter int `json:"protective_monitor_alert_after,omitempty"`
+
+	// 🏷️ 撤单范围：默认只撤本系统下的挂单（客户端订单ID前缀 nofx_），true时撤掉该币种全部挂单（包括手动下的单）
+	CancelAllOrders bool `json:"cancel_all_orders,omitempty"`
+
+	// ⏳ TWAP拆单：名义价值≥twap_min_notional或占盘口±0.5%深度超过twap_max_book_pct%时，分twap_slices片在twap_duration_seconds内执行
+	TWAPMinNotional     float64 `json:"twap_min_notional,omitempty"`
+	TWAPMaxBookPct      float64 `json:"twap_max_book_pct,omitempty"`
+	TWAPSlices          int     `json:"twap_slices,omitempty"`
+	TWAPDurationSeconds int     `json:"twap_duration_seconds,omitempty"`
+
+	// 🧪 故障注入（仅mock模拟交易）：交易所调用按概率返回瞬时错误、随机延迟、开仓部分成交，用于压力测试
+	ChaosErrorRate       float64 `json:"chaos_error_rate,omitempty"`
+	ChaosMaxLatencyMs    int     `json:"chaos_max_latency_ms,omitempty"`
+	ChaosPartialFillRate float64 `json:"chaos_partial_fill_rate,omitempty"`
+	ChaosSeed            int64   `json:"chaos_seed,omitempty"`
+
+	// 🪜 分批止盈策略：决策没有指定take_profit_ladder时，按止损距离的R倍数逐档只减仓止盈（最多3档），剩余仓位以决策止盈为最终目标
+	TakeProfitLadder []TakeProfitRungConfig `json:"take_profit_ladder,omitempty"`
+
+	// ⭐ 用户关注币种：不论排名每个周期都加入候选池（如 ["SOLUSDT", "WIF"]）
+	Watchlist []string `json:"watchlist,omitempty"`
+
+	// 📐 绩效统计窗口：夏普/索提诺/期望收益/R倍数/连胜连亏按最近N个周期计算（0=默认100）
+	PerformanceWindowCycles int `json:"performance_window_cycles,omitempty"`
+
+	// ⏰ 开仓时机保护：资金费结算前funding_guard_minutes分钟内，费率绝对值≥funding_guard_rate_pct%（默认0.05）且对开仓方向不利时不开仓；
+	// event_calendar_file 中的宏观事件（CPI/FOMC）前后event_guard_minutes分钟（默认30）内不开仓
+	FundingGuardMinutes int     `json:"funding_guard_minutes,omitempty"`
+	FundingGuardRatePct float64 `json:"funding_guard_rate_pct,omitempty"`
+	EventCalendarFile   string  `json:"event_calendar_file,omitempty"`
+	EventGuardMinutes   int     `json:"event_guard_minutes,omitempty"`
+
+	// ⏱️ 自适应扫描间隔：波动放大(ATR3/ATR14≥scan_atr_spike_ratio，默认1.5)或持仓距止损/止盈≤scan_near_level_pct%（默认0.5）时缩短到min_scan_interval_minutes，
+	// 无持仓且BTC震荡时延长到max_scan_interval_minutes（都不配置=固定scan_interval_minutes）
+	MinScanIntervalMinutes int     `json:"min_scan_interval_minutes,omitempty"`
+	MaxScanIntervalMinutes int     `json:"max_scan_interval_minutes,omitempty"`
+	ScanATRSpikeRatio      float64 `json:"scan_atr_spike_ratio,omitempty"`
+	ScanNearLevelPct       float64 `json:"scan_near_level_pct,omitempty"`
+
+	// 🪞 决策引擎：multi_agent（默认）/monolithic；shadow_engine设置后每周期用另一个引擎对同一上下文决策，只记录和模拟不执行
+	DecisionEngine string `json:"decision_engine,omitempty"`
+	ShadowEngine   string `json:"shadow_engine,omitempty"`
+
+	// 💸 持仓评估专用模型：持有/平仓判断使用更便宜/更快的模型，独立超时和token上限（仅multi_agent引擎）
+	PositionModel *PositionModelConfig `json:"position_model,omitempty"`
+
+	// 🔁 备用AI模型：主模型连续超时/5xx（或响应过慢）时从本周期剩余调用开始切换到备用模型，failback_minutes后自动尝试切回
+	FallbackModel *FallbackModelConfig `json:"fallback_model,omitempty"`
+
+	// 🧯 组合止损：净值较历史高水位回撤≥portfolio_stop_pct时暂停交易（可选全部平仓），冷却期后以当时净值重置高水位
+	PortfolioStopPct            float64 `json:"portfolio_stop_pct,omitempty"`
+	PortfolioStopFlatten        bool    `json:"portfolio_stop_flatten,omitempty"`
+	PortfolioStopCooloffMinutes int     `json:"portfolio_stop_cooloff_minutes,omitempty"` // 0=默认1440分钟
+
+	// 🌙 定时空仓窗口：窗口内平掉全部持仓并拒绝开仓（如周五22:00到周日22:00 UTC的周末避险），开始前发出通知
+	FlatWindows []FlatWindowConfig `json:"flat_windows,omitempty"`
+
+	// 🚦 过度交易保护：最近overtrading_window_trades笔已平仓交易期望收益为负时，开仓概率阈值提高overtrading_probability_step（默认0.05）、
+	// 决策信心度需≥overtrading_min_confidence（默认80）、冷却期乘以overtrading_cooldown_multiplier（默认2），期望转正后恢复
+	OvertradingWindowTrades       int     `json:"overtrading_window_trades,omitempty"`
+	OvertradingProbabilityStep    float64 `json:"overtrading_probability_step,omitempty"`
+	OvertradingMinConfidence      int     `json:"overtrading_min_confidence,omitempty"`
+	OvertradingCooldownMultiplier float64 `json:"overtrading_cooldown_multiplier,omitempty"`
+
+	// 🔀 决策一致性检查：上一周期信心度≥contradiction_min_confidence（默认75）的开仓方向，本周期以同等信心反向开仓且价格变动
+	// 小于contradiction_max_move_pct%（默认1，<0=不检查）时记为自相矛盾，信心度需≥contradiction_required_confidence（默认90）
+	ContradictionMinConfidence      int     `json:"contradiction_min_confidence,omitempty"`
+	ContradictionMaxMovePct         float64 `json:"contradiction_max_move_pct,omitempty"`
+	ContradictionRequiredConfidence int     `json:"contradiction_required_confidence,omitempty"`
+
+	// 🧐 大仓位自我审查：仓位名义价值≥self_critique_min_notional（USDT）或杠杆≥self_critique_min_leverage时，下单前把决策和风险计算
+	// 交给AI再审一遍，给出阻断性问题则不开仓；self_critique_block_on_error=true时审查调用失败也不开仓（默认放行）
+	SelfCritiqueMinNotional  float64 `json:"self_critique_min_notional,omitempty"`
+	SelfCritiqueMinLeverage  int     `json:"self_critique_min_leverage,omitempty"`
+	SelfCritiqueBlockOnError bool    `json:"self_critique_block_on_error,omitempty"`
+
+	// 🎯 按AI信心度调整仓位：信心度≤50用min倍、100用max倍，按该信心度档位的历史胜率校准（都为0=不调整）
+	ConfidenceSizingMin float64 `json:"confidence_sizing_min,omitempty"`
+	ConfidenceSizingMax float64 `json:"confidence_sizing_max,omitempty"`
+
+	// 📡 行情数据源：binance（默认）或 hyperliquid（K线、资金费率、持仓量与交易场所一致）
+	MarketDataSource string `json:"market_data_source,omitempty"`
+
+	// 💧 候选币种流动性过滤：oi（持仓价值，默认）/ volume（24h成交额）/ none；阈值USD（0=默认：OI 15M，成交额20M）
+	LiquidityFilter string  `json:"liquidity_filter,omitempty"`
+	LiquidityMinUSD float64 `json:"liquidity_min_usd,omitempty"`
+
+	// 🚦 决策策略规则：AI决策执行前检查，可否决（如"永不做空BTC"）或限制杠杆/仓位（如"周末山寨币杠杆≤3x"）
+	DecisionPolicies []DecisionPolicyConfig `json:"decision_policies,omitempty"`
+
+	// ⏱️ 按币种类别的AI预测频率：主流币(BTC/ETH)/山寨币每N个周期预测一次（0/1=每个周期），持仓币种始终管理
+	MajorScanEvery int `json:"major_scan_every,omitempty"`
+	AltScanEvery   int `json:"alt_scan_every,omitempty"`
+
+	// 🏅 AI预测前按动量、成交额、OI变化、偏离EMA20本地打分，每周期只把前N个候选币种交给AI（0=不限制）
+	MaxPredictionsPerCycle int `json:"max_predictions_per_cycle,omitempty"`
+}
+
+// allowedStrategyProfiles 内置策略档案（与 trader.StrategyProfileNames 保持一致）
+var allowedStrategyProfiles = map[string]bool{
+	"scalper":      true,
+	"swing":        true,
+	"conservative": true,
+}
+
+// allowedDecisionEngines 决策引擎（与 decision.EngineMultiAgent/EngineMonolithic 保持一致）
+var allowedDecisionEngines = map[string]bool{
+	"multi_agent": true,
+	"monolithic":  true,
+}
+
+// FlatWindowConfig 定时空仓窗口（每周重复）
+type FlatWindowConfig struct {
+	Name          string `json:"name"`
+	Start         string `json:"start"`                    // 开始时间，如 "fri 22:00"
+	End           string `json:"end"`                      // 结束时间，如 "sun 22:00"
+	Timezone      string `json:"timezone,omitempty"`       // IANA时区（默认UTC）
+	NoticeMinutes int    `json:"notice_minutes,omitempty"` // 开始前多少分钟发出通知（0=默认30，<0=不通知）
+}
+
+// validWeekTime 检查 "fri 22:00" 形式的每周时间
+func validWeekTime(s string) bool {
+	fields := strings.Fields(s)
+	if len(fields) != 2 || !allowedPolicyWeekdays[strings.ToLower(fields[0])] {
+		return false
+	}
+	_, err := time.Parse("15:04", fields[1])
+	return err == nil
+}
+
+// DecisionPolicyConfig 决策策略规则（所有条件同时满足时生效，条件为空表示不限制）
+type DecisionPolicyConfig struct {
+	Name           string   `json:"name"`
+	Symbols        []string `json:"symbols,omitempty"`          // 适用币种
+	Scope          string   `json:"scope,omitempty"`            // major=仅BTC/ETH，alt=仅山寨币
+	Actions        []string `json:"actions,omitempty"`          // 适用动作（默认全部开仓动作）
+	Weekdays       []string `json:"weekdays,omitempty"`         // 适用星期（UTC，mon..sun）
+	Veto           bool     `json:"veto,omitempty"`             // 命中即否决
+	MaxLeverage    int      `json:"max_leverage,omitempty"`     // 杠杆上限
+	MaxPositionUSD float64  `json:"max_position_usd,omitempty"` // 仓位上限（USDT）
+}
+
+// allowedPolicyWeekdays 决策策略允许的星期缩写
+var allowedPolicyWeekdays = map[string]bool{
+	"mon": true, "tue": true, "wed": true, "thu": true, "fri": true, "sat": true, "sun": true,
+}
+
+// allowedPolicyActions 决策策略可匹配的动作
+var allowedPolicyActions = map[string]bool{
+	"open_long": true, "open_short": true, "close_long": true, "close_short": true,
+}
+
+// SymbolPolicyConfig 单个币种的保证金/杠杆策略
+type SymbolPolicyConfig struct {
+	MarginMode  string `json:"margin_mode,omitempty"`  // "isolated" 或 "cross"（空=继承 margin_mode）
+	MaxLeverage int    `json:"max_leverage,omitempty"` // 杠杆上限（0=不额外限制）
+}
+
+// ConstraintOverrideConfig 按类别/币种覆盖的硬约束（0=继承）
+type ConstraintOverrideConfig struct {
+	CooldownMinutes   int `json:"cooldown_minutes,omitempty"`    // 同币种冷却期（默认20分钟）
+	MinHoldingMinutes int `json:"min_holding_minutes,omitempty"` // 最短持仓时间（默认15分钟）
+	MaxHourlyTrades   int `json:"max_hourly_trades,omitempty"`   // 该币种每小时最多开仓次数
+	MaxDailyTrades    int `json:"max_daily_trades,omitempty"`    // 该币种每24小时最多开仓次数
+}
+
+// TakeProfitRungConfig 分批止盈的一档
+type TakeProfitRungConfig struct {
+	R   float64 `json:"r"`   // 止盈距离 = 止损距离 × R
+	Pct float64 `json:"pct"` // 平掉开仓数量的百分比
+}
+
+// BasisConfig 现货期货基差策略配置
+type BasisConfig struct {
+	Mode            string   `json:"mode"`                       // "directional"（方向性，默认）或 "delta_neutral"（买现货+空合约）
+	Symbols         []string `json:"symbols"`                    // 策略交易的币种（不再交给AI）
+	EntryPct        float64  `json:"entry_pct"`                  // 开仓基差阈值（%）
+	ExitPct         float64  `json:"exit_pct"`                   // 平仓基差阈值（%）
+	PositionUSDT    float64  `json:"position_usdt"`              // 单笔保证金
+	BudgetUSDT      float64  `json:"budget_usdt"`                // 策略总保证金预算
+	MaxLossUSDT     float64  `json:"max_loss_usdt,omitempty"`    // 策略累计亏损上限（0=不限制）
+	Leverage        int      `json:"leverage,omitempty"`         // 合约杠杆（默认2）
+	StopLossPct     float64  `json:"stop_loss_pct,omitempty"`    // 方向性持仓止损（价格反向变动%）
+	MaxHoldHours    float64  `json:"max_hold_hours,omitempty"`   // 最长持仓小时数（0=不限制）
+	IntervalSeconds int      `json:"interval_seconds,omitempty"` // 检查间隔（默认60秒）
+}
+
+// EnsembleModelConfig 集成预测的模型配置
+type EnsembleModelConfig struct {
+	Name     string `json:"name,omitempty"` // 模型名称（用于准确率统计，默认取provider）
+	Provider string `json:"provider"`       // "deepseek", "qwen" 或 "custom"
+	APIKey   string `json:"api_key"`
+	APIURL   string `json:"api_url,omitempty"` // custom时必填
+	Model    string `json:"model,omitempty"`   // 模型名（custom时必填）
+}
+
+// PositionModelConfig 持仓评估专用模型配置
+type PositionModelConfig struct {
+	Provider       string `json:"provider,omitempty"` // "deepseek", "qwen", "custom"；为空时沿用主模型的provider和密钥
+	APIKey         string `json:"api_key,omitempty"`
+	APIURL         string `json:"api_url,omitempty"` // custom时必填
+	Model          string `json:"model,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // 单次请求超时（0=沿用ai_timeout_seconds）
+	MaxTokens      int    `json:"max_tokens,omitempty"`      // 单次回复最大token数（0=默认2000）
+}
+
+// AccountTierConfig 账户级别配置
+type AccountTierConfig struct {
+	Name               string  `json:"name"`
+	MinEquity          float64 `json:"min_equity"`                       // 净值≥该值时适用（USDT）
+	MaxLossPct         float64 `json:"max_loss_pct,omitempty"`           // 累计亏损达到该比例禁止开仓（0=默认20），谨慎档位按75%/50%/25%缩放
+	MaxRiskPerTradePct float64 `json:"max_risk_per_trade_pct,omitempty"` // 单笔风险上限占净值%（与max_risk_per_trade_usd取较小者，0=不按比例限制）
+	BreakEvenPct       float64 `json:"break_even_pct,omitempty"`         // 记忆中收益率在±该范围内记为保本（0=默认0.1）
+}
+
+// FallbackModelConfig 备用AI模型配置
+type FallbackModelConfig struct {
+	Provider        string `json:"provider"` // "deepseek", "qwen" 或 "custom"
+	APIKey          string `json:"api_key"`
+	APIURL          string `json:"api_url,omitempty"`          // custom时必填
+	Model           string `json:"model,omitempty"`            // 模型名（custom时必填）
+	TimeoutSeconds  int    `json:"timeout_seconds,omitempty"`  // 单次请求超时（0=沿用ai_timeout_seconds）
+	MaxFailures     int    `json:"max_failures,omitempty"`     // 主模型连续失败多少次后切换（0=默认2）
+	SlowSeconds     int    `json:"slow_seconds,omitempty"`     // 主模型响应慢于该秒数时记为一次失败（0=不按延迟判断）
+	FailbackMinutes int    `json:"failback_minutes,omitempty"` // 切换后多久重新尝试主模型（0=默认10）
 }
 
 // LeverageConfig 杠杆配置
@@ -59,13 +390,47 @@ type Config struct {
 	UseDefaultCoins    bool           `json:"use_default_coins"` // 是否使用默认主流币种列表
 	DefaultCoins       []string       `json:"default_coins"`     // 默认主流币种池
 	CoinPoolAPIURL     string         `json:"coin_pool_api_url"`
+	CoinPoolProviders  []string       `json:"coin_pool_providers,omitempty"` // 🆕 币种池数据源链，如 ["remote","local","default"]
 	OITopAPIURL        string         `json:"oi_top_api_url"`
 	APIServerPort      int            `json:"api_server_port"`
 	MaxDailyLoss       float64        `json:"max_daily_loss"`
 	MaxDrawdown        float64        `json:"max_drawdown"`
 	StopTradingMinutes int            `json:"stop_trading_minutes"`
-	Leverage           LeverageConfig `json:"leverage"`        // 杠杆配置
-	UseLimitOrders     bool           `json:"use_limit_orders"` // 是否使用限价单模式（默认false=市价单）
+	Leverage           LeverageConfig `json:"leverage"`                       // 杠杆配置
+	UseLimitOrders     bool           `json:"use_limit_orders"`               // 是否使用限价单模式（默认false=市价单）
+	ObserveMode        bool           `json:"observe_mode"`                   // 👁️ 观察模式：完整运行决策流程但不下单（用于新账户预热记忆/校准）
+	LiquidationStream  bool           `json:"liquidation_stream"`             // 🆕 订阅币安强平订单流生成清算热力图（否则用订单簿估算）
+	KlineStream        bool           `json:"kline_stream,omitempty"`         // 🆕 订阅币安K线/标记价格推送，本地维护K线计算指标（REST仅用于冷启动）
+	CandleCacheDir     string         `json:"candle_cache_dir,omitempty"`     // 💾 K线磁盘缓存目录（重启后只补齐缺失K线，空=不缓存）
+	SocialSentimentURL string         `json:"social_sentiment_url,omitempty"` // 🆕 社交情绪API（{symbol}替换为币种，如BTC），可选
+	GRPCPort           int            `json:"grpc_port,omitempty"`            // 🎛️ gRPC控制接口端口（0=不启用）
+	GRPCAuthToken      string         `json:"grpc_auth_token,omitempty"`      // 🎛️ gRPC认证token（客户端需携带 authorization: Bearer <token>）
+	WebhookSecret      string         `json:"webhook_secret,omitempty"`       // 📥 交易想法webhook密钥（空=不启用 POST /api/webhook/ideas）
+
+	// 🌐 日志和通知语言："zh"（默认）或 "en"；决策记录中的结构化字段不翻译
+	Language string `json:"language,omitempty"`
+
+	// 📝 外部提示词模板目录（<name>.tmpl覆盖内置模板，修改后下次决策自动生效；空=使用内置模板）
+	PromptDir string `json:"prompt_dir,omitempty"`
+
+	// 🔐 密钥提供者（密钥字段写成 "secret:<name>" 或 "env:<VAR>" 时使用）
+	Secrets *SecretsConfig `json:"secrets,omitempty"`
+
+	// 仍以明文写在配置文件中的密钥字段（启动时提示，不输出值）
+	PlaintextSecrets []string `json:"-"`
+
+	// 🏦 币安子账户：每个子账户独立API密钥、保证金互相隔离；主账户API密钥仅用于资金划转（可选）
+	BinanceSubAccounts     []BinanceSubAccountConfig `json:"binance_sub_accounts,omitempty"`
+	BinanceMasterAPIKey    string                    `json:"binance_master_api_key,omitempty"`
+	BinanceMasterSecretKey string                    `json:"binance_master_secret_key,omitempty"`
+}
+
+// BinanceSubAccountConfig 币安子账户配置
+type BinanceSubAccountConfig struct {
+	Name      string `json:"name"`            // 子账户名称（trader的sub_account引用）
+	Email     string `json:"email,omitempty"` // 子账户邮箱（主账户划转时使用）
+	APIKey    string `json:"api_key"`
+	SecretKey string `json:"secret_key"`
 }
 
 // LoadConfig 从文件加载配置
@@ -80,8 +445,15 @@ func LoadConfig(filename string) (*Config, error) {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
-	// 设置默认值：如果use_default_coins未设置（为false）且没有配置coin_pool_api_url，则默认使用默认币种列表
-	if !config.UseDefaultCoins && config.CoinPoolAPIURL == "" {
+	// 🔐 解析密钥引用，所有密钥登记脱敏
+	plaintext, err := config.resolveSecrets()
+	if err != nil {
+		return nil, err
+	}
+	config.PlaintextSecrets = plaintext
+
+	// 设置默认值：如果use_default_coins未设置（为false）且没有配置coin_pool_api_url和数据源链，则默认使用默认币种列表
+	if !config.UseDefaultCoins && config.CoinPoolAPIURL == "" && len(config.CoinPoolProviders) == 0 {
 		config.UseDefaultCoins = true
 	}
 
@@ -113,6 +485,21 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("至少需要配置一个trader")
 	}
 
+	// 验证币安子账户
+	subAccounts := make(map[string]bool)
+	for i, sub := range c.BinanceSubAccounts {
+		if sub.Name == "" || sub.Name == "auto" {
+			return fmt.Errorf("binance_sub_accounts[%d]: name不能为空或auto", i)
+		}
+		if subAccounts[sub.Name] {
+			return fmt.Errorf("binance_sub_accounts[%d]: name '%s' 重复", i, sub.Name)
+		}
+		if sub.APIKey == "" || sub.SecretKey == "" {
+			return fmt.Errorf("binance_sub_accounts[%d]: 必须配置api_key和secret_key", i)
+		}
+		subAccounts[sub.Name] = true
+	}
+
 	traderIDs := make(map[string]bool)
 	for i := range c.Traders {
 		if c.Traders[i].ID == "" {
@@ -134,14 +521,24 @@ func (c *Config) Validate() error {
 		if c.Traders[i].Exchange == "" {
 			c.Traders[i].Exchange = "binance" // 默认使用币安
 		}
-		if c.Traders[i].Exchange != "binance" && c.Traders[i].Exchange != "hyperliquid" && c.Traders[i].Exchange != "aster" && c.Traders[i].Exchange != "mock" {
-			return fmt.Errorf("trader[%d]: exchange必须是 'binance', 'hyperliquid', 'aster' 或 'mock'", i)
+		if c.Traders[i].Exchange != "binance" && c.Traders[i].Exchange != "binance_coinm" && c.Traders[i].Exchange != "hyperliquid" && c.Traders[i].Exchange != "aster" && c.Traders[i].Exchange != "bybit" && c.Traders[i].Exchange != "mock" {
+			return fmt.Errorf("trader[%d]: exchange必须是 'binance', 'binance_coinm', 'hyperliquid', 'aster', 'bybit' 或 'mock'", i)
 		}
 
 		// 根据平台验证对应的密钥
-		if c.Traders[i].Exchange == "binance" {
+		if sub := c.Traders[i].SubAccount; sub != "" {
+			if c.Traders[i].Exchange != "binance" {
+				return fmt.Errorf("trader[%d]: sub_account仅支持币安", i)
+			}
+			if sub == "auto" && len(c.BinanceSubAccounts) == 0 {
+				return fmt.Errorf("trader[%d]: sub_account为auto时必须配置binance_sub_accounts", i)
+			}
+			if sub != "auto" && !subAccounts[sub] {
+				return fmt.Errorf("trader[%d]: 子账户 '%s' 不存在于binance_sub_accounts", i, sub)
+			}
+		} else if c.Traders[i].Exchange == "binance" || c.Traders[i].Exchange == "binance_coinm" {
 			if c.Traders[i].BinanceAPIKey == "" || c.Traders[i].BinanceSecretKey == "" {
-				return fmt.Errorf("trader[%d]: 使用币安时必须配置binance_api_key和binance_secret_key", i)
+				return fmt.Errorf("trader[%d]: 使用币安时必须配置binance_api_key和binance_secret_key（或sub_account）", i)
 			}
 		} else if c.Traders[i].Exchange == "hyperliquid" {
 			if c.Traders[i].HyperliquidPrivateKey == "" {
@@ -151,6 +548,10 @@ func (c *Config) Validate() error {
 			if c.Traders[i].AsterUser == "" || c.Traders[i].AsterSigner == "" || c.Traders[i].AsterPrivateKey == "" {
 				return fmt.Errorf("trader[%d]: 使用Aster时必须配置aster_user, aster_signer和aster_private_key", i)
 			}
+		} else if c.Traders[i].Exchange == "bybit" {
+			if c.Traders[i].BybitAPIKey == "" || c.Traders[i].BybitSecretKey == "" {
+				return fmt.Errorf("trader[%d]: 使用Bybit时必须配置bybit_api_key和bybit_secret_key", i)
+			}
 		}
 
 		if c.Traders[i].AIModel == "qwen" {
@@ -186,21 +587,460 @@ func (c *Config) Validate() error {
 		if c.Traders[i].InitialBalance <= 0 {
 			return fmt.Errorf("trader[%d]: initial_balance必须大于0", i)
 		}
-		if c.Traders[i].ScanIntervalMinutes <= 0 {
+		// 🎛️ 策略档案：未显式配置的扫描周期、K线周期等由档案提供
+		profile := c.Traders[i].StrategyProfile
+		if profile != "" && !allowedStrategyProfiles[profile] {
+			return fmt.Errorf("trader[%d]: strategy_profile必须是 'scalper', 'swing' 或 'conservative'", i)
+		}
+
+		if c.Traders[i].ScanIntervalMinutes <= 0 && profile == "" {
 			c.Traders[i].ScanIntervalMinutes = 3 // 默认3分钟
 		}
 
 		// 验证和设置K线周期默认值
-		if c.Traders[i].KlineInterval == "" {
+		if c.Traders[i].KlineInterval == "" && profile == "" {
 			c.Traders[i].KlineInterval = "5m" // 默认5分钟
 		}
 		allowedIntervals := map[string]bool{
 			"1m": true, "3m": true, "5m": true, "15m": true,
 			"30m": true, "1h": true, "2h": true, "4h": true,
 		}
-		if !allowedIntervals[c.Traders[i].KlineInterval] {
+		if c.Traders[i].KlineInterval != "" && !allowedIntervals[c.Traders[i].KlineInterval] {
 			return fmt.Errorf("trader[%d]: kline_interval必须是 '1m', '3m', '5m', '15m', '30m', '1h', '2h' 或 '4h'", i)
 		}
+		if c.Traders[i].LongTermInterval != "" && !allowedIntervals[c.Traders[i].LongTermInterval] {
+			return fmt.Errorf("trader[%d]: long_term_interval必须是 '1m', '3m', '5m', '15m', '30m', '1h', '2h' 或 '4h'", i)
+		}
+		if c.Traders[i].EntryTimingInterval != "" && !allowedIntervals[c.Traders[i].EntryTimingInterval] {
+			return fmt.Errorf("trader[%d]: entry_timing_interval必须是 '1m', '3m', '5m', '15m', '30m', '1h', '2h' 或 '4h'", i)
+		}
+
+		if c.Traders[i].MaxRiskPerTradeUSD < 0 {
+			return fmt.Errorf("trader[%d]: max_risk_per_trade_usd不能为负数", i)
+		}
+		if c.Traders[i].StopMarginLossPct < 0 || c.Traders[i].StopMarginLossPct > 100 {
+			return fmt.Errorf("trader[%d]: stop_margin_loss_pct必须在0-100之间", i)
+		}
+		if c.Traders[i].StalePriceStopFraction > 5 {
+			return fmt.Errorf("trader[%d]: stale_price_stop_fraction不能超过5（止损距离的倍数）", i)
+		}
+		if c.Traders[i].MinStopTicks < 0 || c.Traders[i].MinStopDistancePct < 0 {
+			return fmt.Errorf("trader[%d]: min_stop_ticks/min_stop_distance_pct不能为负数", i)
+		}
+		if a := c.Traders[i].StopDistanceAction; a != "" && a != "adjust" && a != "reject" {
+			return fmt.Errorf("trader[%d]: stop_distance_action必须是 'adjust' 或 'reject'", i)
+		}
+		if c.Traders[i].AITimeoutSeconds < 0 {
+			return fmt.Errorf("trader[%d]: ai_timeout_seconds不能为负数", i)
+		}
+		if c.Traders[i].CycleBudgetSeconds < -1 {
+			return fmt.Errorf("trader[%d]: cycle_budget_seconds必须≥0（-1表示不限制）", i)
+		}
+
+		if c.Traders[i].AltcoinSignals && c.Traders[i].Exchange != "binance" {
+			return fmt.Errorf("trader[%d]: altcoin_signals仅支持binance交易所", i)
+		}
+		for _, q := range c.Traders[i].QuoteAssets {
+			if q != "USDT" && q != "USDC" {
+				return fmt.Errorf("trader[%d]: quote_assets只能包含USDT或USDC: %q", i, q)
+			}
+			if q != "USDT" && c.Traders[i].Exchange != "binance" {
+				return fmt.Errorf("trader[%d]: quote_assets的USDC仅支持binance交易所", i)
+			}
+		}
+
+		// 验证基差策略配置
+		if b := c.Traders[i].Basis; b != nil {
+			if c.Traders[i].Exchange != "binance" {
+				return fmt.Errorf("trader[%d]: basis仅支持binance交易所", i)
+			}
+			if b.Mode != "" && b.Mode != "directional" && b.Mode != "delta_neutral" {
+				return fmt.Errorf("trader[%d]: basis.mode必须是 'directional' 或 'delta_neutral'", i)
+			}
+			if len(b.Symbols) == 0 {
+				return fmt.Errorf("trader[%d]: basis.symbols不能为空", i)
+			}
+			if b.EntryPct <= 0 || b.ExitPct < 0 || b.ExitPct >= b.EntryPct {
+				return fmt.Errorf("trader[%d]: basis需要 entry_pct > exit_pct ≥ 0", i)
+			}
+			if b.PositionUSDT <= 0 || b.BudgetUSDT < b.PositionUSDT {
+				return fmt.Errorf("trader[%d]: basis需要 budget_usdt ≥ position_usdt > 0", i)
+			}
+			if b.MaxLossUSDT < 0 || b.Leverage < 0 || b.StopLossPct < 0 || b.MaxHoldHours < 0 || b.IntervalSeconds < 0 {
+				return fmt.Errorf("trader[%d]: basis的数值参数不能为负数", i)
+			}
+		}
+
+		// 验证保证金模式与杠杆策略
+		validMarginMode := func(mode string) bool {
+			return mode == "" || mode == "isolated" || mode == "cross"
+		}
+		if !validMarginMode(c.Traders[i].MarginMode) {
+			return fmt.Errorf("trader[%d]: margin_mode必须是 'isolated' 或 'cross'", i)
+		}
+		for symbol, p := range c.Traders[i].SymbolPolicies {
+			if !validMarginMode(p.MarginMode) {
+				return fmt.Errorf("trader[%d]: symbol_policies[%s].margin_mode必须是 'isolated' 或 'cross'", i, symbol)
+			}
+			if p.MaxLeverage < 0 {
+				return fmt.Errorf("trader[%d]: symbol_policies[%s].max_leverage不能为负数", i, symbol)
+			}
+			if p.MarginMode != "" && c.Traders[i].Exchange == "aster" {
+				return fmt.Errorf("trader[%d]: aster不支持按币种设置保证金模式（沿用账户设置）", i)
+			}
+		}
+		if c.Traders[i].MarginMode != "" && c.Traders[i].Exchange == "aster" {
+			return fmt.Errorf("trader[%d]: aster不支持设置margin_mode（沿用账户设置）", i)
+		}
+
+		// 验证组合仓位分配
+		switch c.Traders[i].PositionAllocation {
+		case "", "risk_parity", "equal_weight":
+		default:
+			return fmt.Errorf("trader[%d]: position_allocation必须是 'risk_parity' 或 'equal_weight'", i)
+		}
+		if c.Traders[i].MaxNewPositionsPerCycle < 0 {
+			return fmt.Errorf("trader[%d]: max_new_positions_per_cycle不能为负数", i)
+		}
+
+		// 验证冷却期豁免
+		if c.Traders[i].CooldownOverridesPerDay < 0 {
+			return fmt.Errorf("trader[%d]: cooldown_overrides_per_day不能为负数", i)
+		}
+		if mc := c.Traders[i].CooldownOverrideMinConfidence; mc < 0 || mc > 100 {
+			return fmt.Errorf("trader[%d]: cooldown_override_min_confidence必须在0-100之间", i)
+		}
+		for key, o := range c.Traders[i].ConstraintOverrides {
+			if o.CooldownMinutes < 0 || o.MinHoldingMinutes < 0 || o.MaxHourlyTrades < 0 || o.MaxDailyTrades < 0 {
+				return fmt.Errorf("trader[%d]: constraint_overrides[%s]不能为负数", i, key)
+			}
+		}
+
+		// 验证持仓上限
+		tc := &c.Traders[i]
+		if tc.MaxPositions < 0 || tc.MaxLongPositions < 0 || tc.MaxShortPositions < 0 || tc.MaxNotionalMultiple < 0 {
+			return fmt.Errorf("trader[%d]: max_positions/max_long_positions/max_short_positions/max_notional_multiple不能为负数", i)
+		}
+		if tc.MaxPositions > 0 && (tc.MaxLongPositions > tc.MaxPositions || tc.MaxShortPositions > tc.MaxPositions) {
+			return fmt.Errorf("trader[%d]: max_long_positions/max_short_positions不能大于max_positions(%d)", i, tc.MaxPositions)
+		}
+
+		// 验证TWAP拆单
+		if tc.TWAPMinNotional < 0 || tc.TWAPMaxBookPct < 0 || tc.TWAPSlices < 0 || tc.TWAPDurationSeconds < 0 {
+			return fmt.Errorf("trader[%d]: twap_min_notional/twap_max_book_pct/twap_slices/twap_duration_seconds不能为负数", i)
+		}
+		if tc.TWAPSlices == 1 {
+			return fmt.Errorf("trader[%d]: twap_slices至少为2（0=默认5）", i)
+		}
+
+		// 验证故障注入
+		if tc.ChaosErrorRate < 0 || tc.ChaosErrorRate > 1 || tc.ChaosPartialFillRate < 0 || tc.ChaosPartialFillRate > 1 {
+			return fmt.Errorf("trader[%d]: chaos_error_rate/chaos_partial_fill_rate必须在0-1之间", i)
+		}
+		if tc.ChaosMaxLatencyMs < 0 {
+			return fmt.Errorf("trader[%d]: chaos_max_latency_ms不能为负数", i)
+		}
+		if (tc.ChaosErrorRate > 0 || tc.ChaosPartialFillRate > 0 || tc.ChaosMaxLatencyMs > 0) && tc.Exchange != "mock" {
+			return fmt.Errorf("trader[%d]: chaos_*故障注入仅支持mock模拟交易", i)
+		}
+
+		// 验证分批止盈策略
+		if len(tc.TakeProfitLadder) > 3 {
+			return fmt.Errorf("trader[%d]: take_profit_ladder最多3档", i)
+		}
+		ladderPct, lastR := 0.0, 0.0
+		for j, rung := range tc.TakeProfitLadder {
+			if rung.R <= lastR {
+				return fmt.Errorf("trader[%d]: take_profit_ladder[%d].r必须大于0且逐档递增", i, j)
+			}
+			if rung.Pct <= 0 || rung.Pct > 100 {
+				return fmt.Errorf("trader[%d]: take_profit_ladder[%d].pct必须在0-100之间", i, j)
+			}
+			ladderPct += rung.Pct
+			lastR = rung.R
+		}
+		if ladderPct > 100 {
+			return fmt.Errorf("trader[%d]: take_profit_ladder各档pct合计不能超过100", i)
+		}
+
+		// 验证关注列表
+		for _, symbol := range tc.Watchlist {
+			if strings.TrimSpace(symbol) == "" {
+				return fmt.Errorf("trader[%d]: watchlist不能包含空币种", i)
+			}
+		}
+
+		// 验证绩效统计窗口
+		if tc.PerformanceWindowCycles < 0 {
+			return fmt.Errorf("trader[%d]: performance_window_cycles不能为负数", i)
+		}
+
+		// 验证开仓时机保护
+		if tc.FundingGuardMinutes < 0 || tc.FundingGuardRatePct < 0 || tc.EventGuardMinutes < 0 {
+			return fmt.Errorf("trader[%d]: funding_guard_minutes/funding_guard_rate_pct/event_guard_minutes不能为负数", i)
+		}
+		if tc.FundingGuardMinutes > 480 {
+			return fmt.Errorf("trader[%d]: funding_guard_minutes不能超过480（资金费每8小时结算）", i)
+		}
+
+		// 验证自适应扫描间隔
+		if tc.MinScanIntervalMinutes < 0 || tc.MaxScanIntervalMinutes < 0 || tc.ScanATRSpikeRatio < 0 || tc.ScanNearLevelPct < 0 {
+			return fmt.Errorf("trader[%d]: min_scan_interval_minutes/max_scan_interval_minutes/scan_atr_spike_ratio/scan_near_level_pct不能为负数", i)
+		}
+		if tc.MinScanIntervalMinutes > 0 && tc.MaxScanIntervalMinutes > 0 && tc.MinScanIntervalMinutes > tc.MaxScanIntervalMinutes {
+			return fmt.Errorf("trader[%d]: min_scan_interval_minutes不能大于max_scan_interval_minutes", i)
+		}
+		if tc.ScanATRSpikeRatio > 0 && tc.ScanATRSpikeRatio <= 1 {
+			return fmt.Errorf("trader[%d]: scan_atr_spike_ratio必须大于1", i)
+		}
+
+		// 验证决策引擎
+		if tc.DecisionEngine != "" && !allowedDecisionEngines[tc.DecisionEngine] {
+			return fmt.Errorf("trader[%d]: decision_engine必须是multi_agent或monolithic", i)
+		}
+		if tc.ShadowEngine != "" {
+			if !allowedDecisionEngines[tc.ShadowEngine] {
+				return fmt.Errorf("trader[%d]: shadow_engine必须是multi_agent或monolithic", i)
+			}
+			primary := tc.DecisionEngine
+			if primary == "" {
+				primary = "multi_agent"
+			}
+			if tc.ShadowEngine == primary {
+				return fmt.Errorf("trader[%d]: shadow_engine不能与decision_engine相同", i)
+			}
+		}
+
+		// 验证持仓评估模型
+		if pm := tc.PositionModel; pm != nil {
+			switch pm.Provider {
+			case "":
+				if pm.Model == "" {
+					return fmt.Errorf("trader[%d]: position_model未配置provider时必须配置model", i)
+				}
+			case "deepseek", "qwen":
+				if pm.APIKey == "" {
+					return fmt.Errorf("trader[%d]: position_model未配置api_key", i)
+				}
+			case "custom":
+				if pm.APIKey == "" || pm.APIURL == "" || pm.Model == "" {
+					return fmt.Errorf("trader[%d]: position_model使用custom时必须配置api_key、api_url和model", i)
+				}
+			default:
+				return fmt.Errorf("trader[%d]: position_model.provider必须是 'deepseek', 'qwen' 或 'custom'", i)
+			}
+			if pm.TimeoutSeconds < 0 || pm.MaxTokens < 0 {
+				return fmt.Errorf("trader[%d]: position_model的timeout_seconds和max_tokens不能为负数", i)
+			}
+		}
+
+		// 验证账户级别
+		tierNames := make(map[string]bool, len(tc.AccountTiers))
+		for j, tier := range tc.AccountTiers {
+			if tier.Name == "" || tierNames[tier.Name] {
+				return fmt.Errorf("trader[%d]: account_tiers[%d]的name不能为空或重复", i, j)
+			}
+			tierNames[tier.Name] = true
+			if tier.MinEquity < 0 || tier.BreakEvenPct < 0 {
+				return fmt.Errorf("trader[%d]: account_tiers[%d]的min_equity和break_even_pct不能为负数", i, j)
+			}
+			if tier.MaxLossPct < 0 || tier.MaxLossPct >= 100 || tier.MaxRiskPerTradePct < 0 || tier.MaxRiskPerTradePct >= 100 {
+				return fmt.Errorf("trader[%d]: account_tiers[%d]的max_loss_pct和max_risk_per_trade_pct必须在0-100之间", i, j)
+			}
+		}
+
+		// 验证备用AI模型
+		if fm := tc.FallbackModel; fm != nil {
+			switch fm.Provider {
+			case "deepseek", "qwen":
+				if fm.APIKey == "" {
+					return fmt.Errorf("trader[%d]: fallback_model未配置api_key", i)
+				}
+			case "custom":
+				if fm.APIKey == "" || fm.APIURL == "" || fm.Model == "" {
+					return fmt.Errorf("trader[%d]: fallback_model使用custom时必须配置api_key、api_url和model", i)
+				}
+			default:
+				return fmt.Errorf("trader[%d]: fallback_model.provider必须是 'deepseek', 'qwen' 或 'custom'", i)
+			}
+			if fm.TimeoutSeconds < 0 || fm.MaxFailures < 0 || fm.SlowSeconds < 0 || fm.FailbackMinutes < 0 {
+				return fmt.Errorf("trader[%d]: fallback_model的timeout_seconds、max_failures、slow_seconds和failback_minutes不能为负数", i)
+			}
+		}
+
+		// 验证组合止损
+		if tc.PortfolioStopPct < 0 || tc.PortfolioStopPct >= 100 {
+			return fmt.Errorf("trader[%d]: portfolio_stop_pct必须在0-100之间", i)
+		}
+		if tc.PortfolioStopCooloffMinutes < 0 {
+			return fmt.Errorf("trader[%d]: portfolio_stop_cooloff_minutes不能为负数", i)
+		}
+		if tc.PortfolioStopPct == 0 && (tc.PortfolioStopFlatten || tc.PortfolioStopCooloffMinutes > 0) {
+			return fmt.Errorf("trader[%d]: portfolio_stop_flatten/portfolio_stop_cooloff_minutes需要同时配置portfolio_stop_pct", i)
+		}
+
+		// 验证定时空仓窗口
+		for j, w := range tc.FlatWindows {
+			if w.Name == "" {
+				return fmt.Errorf("trader[%d]: flat_windows[%d]缺少name", i, j)
+			}
+			if !validWeekTime(w.Start) || !validWeekTime(w.End) {
+				return fmt.Errorf("trader[%d]: flat_windows[%d](%s)的start/end格式应为 \"fri 22:00\"", i, j, w.Name)
+			}
+			if strings.EqualFold(strings.Join(strings.Fields(w.Start), " "), strings.Join(strings.Fields(w.End), " ")) {
+				return fmt.Errorf("trader[%d]: flat_windows[%d](%s)的start和end不能相同", i, j, w.Name)
+			}
+			if w.Timezone != "" {
+				if _, err := time.LoadLocation(w.Timezone); err != nil {
+					return fmt.Errorf("trader[%d]: flat_windows[%d](%s)的timezone无效: %v", i, j, w.Name, err)
+				}
+			}
+		}
+
+		// 验证过度交易保护
+		if tc.OvertradingWindowTrades < 0 || tc.OvertradingWindowTrades == 1 {
+			return fmt.Errorf("trader[%d]: overtrading_window_trades至少为2（0=不启用）", i)
+		}
+		if tc.OvertradingProbabilityStep < 0 || tc.OvertradingProbabilityStep > 0.3 {
+			return fmt.Errorf("trader[%d]: overtrading_probability_step必须在0-0.3之间", i)
+		}
+		if tc.OvertradingMinConfidence < 0 || tc.OvertradingMinConfidence > 100 {
+			return fmt.Errorf("trader[%d]: overtrading_min_confidence必须在0-100之间", i)
+		}
+		if tc.OvertradingCooldownMultiplier != 0 && tc.OvertradingCooldownMultiplier < 1 {
+			return fmt.Errorf("trader[%d]: overtrading_cooldown_multiplier不能小于1", i)
+		}
+
+		// 验证决策一致性检查
+		if tc.ContradictionMinConfidence < 0 || tc.ContradictionMinConfidence > 100 ||
+			tc.ContradictionRequiredConfidence < 0 || tc.ContradictionRequiredConfidence > 100 {
+			return fmt.Errorf("trader[%d]: contradiction_min_confidence/contradiction_required_confidence必须在0-100之间", i)
+		}
+
+		// 验证大仓位自我审查
+		if tc.SelfCritiqueMinNotional < 0 || tc.SelfCritiqueMinLeverage < 0 {
+			return fmt.Errorf("trader[%d]: self_critique_min_notional/self_critique_min_leverage不能为负数", i)
+		}
+		if tc.SelfCritiqueBlockOnError && tc.SelfCritiqueMinNotional == 0 && tc.SelfCritiqueMinLeverage == 0 {
+			return fmt.Errorf("trader[%d]: self_critique_block_on_error需要同时配置self_critique_min_notional或self_critique_min_leverage", i)
+		}
+
+		// 验证信心度仓位调整
+		if tc.ConfidenceSizingMin != 0 || tc.ConfidenceSizingMax != 0 {
+			if tc.ConfidenceSizingMin <= 0 || tc.ConfidenceSizingMax < tc.ConfidenceSizingMin || tc.ConfidenceSizingMax > 3 {
+				return fmt.Errorf("trader[%d]: confidence_sizing_min/max需满足 0 < min ≤ max ≤ 3", i)
+			}
+		}
+
+		// 验证行情数据源
+		if tc.MarketDataSource != "" && tc.MarketDataSource != "binance" && tc.MarketDataSource != "hyperliquid" {
+			return fmt.Errorf("trader[%d]: market_data_source必须是binance或hyperliquid", i)
+		}
+
+		// 验证流动性过滤
+		switch tc.LiquidityFilter {
+		case "", "oi", "volume", "none":
+		default:
+			return fmt.Errorf("trader[%d]: liquidity_filter必须是oi、volume或none", i)
+		}
+		if tc.LiquidityMinUSD < 0 {
+			return fmt.Errorf("trader[%d]: liquidity_min_usd不能为负数", i)
+		}
+
+		// 验证决策策略
+		for j, rule := range tc.DecisionPolicies {
+			if rule.Name == "" {
+				return fmt.Errorf("trader[%d]: decision_policies[%d]缺少name", i, j)
+			}
+			if !rule.Veto && rule.MaxLeverage <= 0 && rule.MaxPositionUSD <= 0 {
+				return fmt.Errorf("trader[%d]: decision_policies[%d](%s)需要设置veto、max_leverage或max_position_usd", i, j, rule.Name)
+			}
+			if rule.MaxLeverage < 0 || rule.MaxPositionUSD < 0 {
+				return fmt.Errorf("trader[%d]: decision_policies[%d](%s)的max_leverage/max_position_usd不能为负数", i, j, rule.Name)
+			}
+			if rule.Scope != "" && rule.Scope != "major" && rule.Scope != "alt" {
+				return fmt.Errorf("trader[%d]: decision_policies[%d](%s)的scope必须是major或alt", i, j, rule.Name)
+			}
+			for _, day := range rule.Weekdays {
+				if !allowedPolicyWeekdays[strings.ToLower(day)] {
+					return fmt.Errorf("trader[%d]: decision_policies[%d](%s)的weekdays无效: %s（应为mon..sun）", i, j, rule.Name, day)
+				}
+			}
+			for _, action := range rule.Actions {
+				if !allowedPolicyActions[action] {
+					return fmt.Errorf("trader[%d]: decision_policies[%d](%s)的actions无效: %s", i, j, rule.Name, action)
+				}
+			}
+		}
+
+		// 验证预测频率
+		if tc.MajorScanEvery < 0 || tc.AltScanEvery < 0 {
+			return fmt.Errorf("trader[%d]: major_scan_every/alt_scan_every不能为负数", i)
+		}
+		if tc.MaxPredictionsPerCycle < 0 {
+			return fmt.Errorf("trader[%d]: max_predictions_per_cycle不能为负数", i)
+		}
+
+		// 验证决策日志归档
+		if c.Traders[i].DecisionLogArchiveDays < 0 || c.Traders[i].DecisionLogRetentionDays < 0 {
+			return fmt.Errorf("trader[%d]: decision_log_archive_days和decision_log_retention_days不能为负数", i)
+		}
+		if r := c.Traders[i].DecisionLogRetentionDays; r > 0 && r < c.Traders[i].DecisionLogArchiveDays {
+			return fmt.Errorf("trader[%d]: decision_log_retention_days(%d)不能小于decision_log_archive_days(%d)",
+				i, r, c.Traders[i].DecisionLogArchiveDays)
+		}
+
+		// 验证集成预测配置
+		if n := len(c.Traders[i].EnsembleModels); n == 1 || n > 3 {
+			return fmt.Errorf("trader[%d]: ensemble_models需要配置2-3个模型", i)
+		}
+		for j, m := range c.Traders[i].EnsembleModels {
+			switch m.Provider {
+			case "deepseek", "qwen":
+			case "custom":
+				if m.APIURL == "" || m.Model == "" {
+					return fmt.Errorf("trader[%d]: ensemble_models[%d]使用custom时必须配置api_url和model", i, j)
+				}
+			default:
+				return fmt.Errorf("trader[%d]: ensemble_models[%d].provider必须是 'deepseek', 'qwen' 或 'custom'", i, j)
+			}
+			if m.APIKey == "" {
+				return fmt.Errorf("trader[%d]: ensemble_models[%d]未配置api_key", i, j)
+			}
+		}
+		switch c.Traders[i].EnsembleMode {
+		case "", "majority", "weighted":
+		default:
+			return fmt.Errorf("trader[%d]: ensemble_mode必须是 'majority' 或 'weighted'", i)
+		}
+
+		// 验证未知持仓处理策略
+		switch c.Traders[i].UnknownPositionPolicy {
+		case "", "adopt", "close", "ignore":
+		default:
+			return fmt.Errorf("trader[%d]: unknown_position_policy必须是 'adopt', 'close' 或 'ignore'", i)
+		}
+
+		// 失联看门狗被禁用时，恢复后平仓选项不会生效
+		if c.Traders[i].FlattenUnprotectedOnReconnect && c.Traders[i].ConnectivityLossMinutes < 0 {
+			return fmt.Errorf("trader[%d]: flatten_unprotected_on_reconnect需要启用失联看门狗（connectivity_loss_minutes不能为负数）", i)
+		}
+		if c.Traders[i].ProtectiveMonitorSeconds > 0 && c.Traders[i].ProtectiveMonitorSeconds < 10 {
+			return fmt.Errorf("trader[%d]: protective_monitor_seconds不能小于10（挂单查询较重）", i)
+		}
+		if c.Traders[i].ProtectiveMonitorAlertAfter < 0 {
+			return fmt.Errorf("trader[%d]: protective_monitor_alert_after不能为负数", i)
+		}
+	}
+
+	// 验证币种池数据源链
+	for _, name := range c.CoinPoolProviders {
+		if name != "remote" && name != "local" && name != "default" {
+			return fmt.Errorf("coin_pool_providers只支持 'remote', 'local' 或 'default'，当前: '%s'", name)
+		}
+		if name == "remote" && c.CoinPoolAPIURL == "" {
+			return fmt.Errorf("coin_pool_providers包含 'remote' 时必须配置coin_pool_api_url")
+		}
 	}
 
 	if c.APIServerPort <= 0 {
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"nofx/secrets"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SecretsConfig 🔐 密钥提供者配置：密钥字段可写成 "secret:<name>"（按提供者链查找）或 "env:<VAR>"
+type SecretsConfig struct {
+	Providers      []string `json:"providers,omitempty"`       // 查找顺序，可选 keyring/file/env（默认全部，加密文件不存在时跳过）
+	File           string   `json:"file,omitempty"`            // 加密密钥文件（默认secrets.enc，口令来自环境变量NOFX_SECRETS_PASSPHRASE）
+	KeyringService string   `json:"keyring_service,omitempty"` // 系统钥匙串服务名（默认nofx）
+}
+
+// secretFields 配置中所有密钥字段（含子账户、集成模型和持仓评估模型）
+func (c *Config) secretFields() map[string]*string {
+	fields := map[string]*string{
+		"binance_master_api_key":    &c.BinanceMasterAPIKey,
+		"binance_master_secret_key": &c.BinanceMasterSecretKey,
+		"grpc_auth_token":           &c.GRPCAuthToken,
+		"webhook_secret":            &c.WebhookSecret,
+	}
+	for i := range c.BinanceSubAccounts {
+		sub := &c.BinanceSubAccounts[i]
+		fields[fmt.Sprintf("binance_sub_accounts[%d].api_key", i)] = &sub.APIKey
+		fields[fmt.Sprintf("binance_sub_accounts[%d].secret_key", i)] = &sub.SecretKey
+	}
+	for i := range c.Traders {
+		t := &c.Traders[i]
+		prefix := fmt.Sprintf("trader[%d].", i)
+		fields[prefix+"binance_api_key"] = &t.BinanceAPIKey
+		fields[prefix+"binance_secret_key"] = &t.BinanceSecretKey
+		fields[prefix+"hyperliquid_private_key"] = &t.HyperliquidPrivateKey
+		fields[prefix+"aster_private_key"] = &t.AsterPrivateKey
+		fields[prefix+"qwen_key"] = &t.QwenKey
+		fields[prefix+"deepseek_key"] = &t.DeepSeekKey
+		fields[prefix+"custom_api_key"] = &t.CustomAPIKey
+		for j := range t.EnsembleModels {
+			fields[fmt.Sprintf("%sensemble_models[%d].api_key", prefix, j)] = &t.EnsembleModels[j].APIKey
+		}
+		if t.PositionModel != nil {
+			fields[prefix+"position_model.api_key"] = &t.PositionModel.APIKey
+		}
+		if t.FallbackModel != nil {
+			fields[prefix+"fallback_model.api_key"] = &t.FallbackModel.APIKey
+		}
+	}
+	return fields
+}
+
+// resolveSecrets 解析密钥引用并登记脱敏，返回仍以明文写在配置中的密钥字段
+func (c *Config) resolveSecrets() (plaintext []string, err error) {
+	fields := c.secretFields()
+
+	var chain secrets.Chain
+	needChain := false
+	for _, field := range fields {
+		if strings.HasPrefix(*field, secrets.SecretPrefix) {
+			needChain = true
+			break
+		}
+	}
+	if needChain {
+		opts := secrets.Options{Passphrase: os.Getenv(secrets.PassphraseEnv)}
+		if c.Secrets != nil {
+			opts.Providers = c.Secrets.Providers
+			opts.File = c.Secrets.File
+			opts.KeyringService = c.Secrets.KeyringService
+		}
+		if chain, err = secrets.NewChain(opts); err != nil {
+			return nil, fmt.Errorf("初始化密钥提供者失败: %w", err)
+		}
+	}
+
+	for name, field := range fields {
+		if *field == "" {
+			continue
+		}
+		if !secrets.IsReference(*field) {
+			plaintext = append(plaintext, name)
+		}
+		value, err := secrets.Resolve(chain, *field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		*field = value
+	}
+	sort.Strings(plaintext)
+	return plaintext, nil
+}
@@ -0,0 +1,41 @@
+package agents
+
+import "math"
+
+// DefaultMaxAccountLossPct 默认累计亏损达到20%时禁止开新仓
+const DefaultMaxAccountLossPct = 20.0
+
+// AccountRiskGate 账户累计亏损风控：亏损达到MaxLossPct时禁止开仓，
+// 达到其75%/50%/25%时逐级提高开仓概率门槛（默认即20%/15%/10%/5%）
+type AccountRiskGate struct {
+	Tier       string  // 当前账户级别名称（空=未配置账户级别）
+	MaxLossPct float64 // 禁止开仓的累计亏损比例（0=默认20）
+}
+
+// MaxLoss 禁止开仓的累计亏损比例
+func (g AccountRiskGate) MaxLoss() float64 {
+	if g.MaxLossPct > 0 {
+		return g.MaxLossPct
+	}
+	return DefaultMaxAccountLossPct
+}
+
+// Step 第n档（1-3）谨慎阈值对应的累计亏损比例（3档=75%，2档=50%，1档=25%）
+func (g AccountRiskGate) Step(n int) float64 {
+	return g.MaxLoss() * float64(n) / 4
+}
+
+// MinProbability 按账户累计盈亏（%）计算开仓所需最低概率（>1表示禁止开仓）及风控状态
+func (g AccountRiskGate) MinProbability(pnlPct, base float64) (float64, string) {
+	switch {
+	case pnlPct < -g.MaxLoss():
+		return 1.01, "🛑 严格禁止"
+	case pnlPct < -g.Step(3):
+		return math.Max(base, 0.75), "⚠️ 谨慎交易"
+	case pnlPct < -g.Step(2):
+		return math.Max(base, 0.70), "💡 适度谨慎"
+	case pnlPct < -g.Step(1):
+		return math.Max(base, 0.68), "✅ 正常偏谨慎"
+	}
+	return base, "✅ 正常"
+}
@@ -0,0 +1,24 @@
+package agents
+
+import "nofx/decision/types"
+
+// newAttribution 根据预测结果创建因子归因记录
+func newAttribution(stage string, prediction *types.Prediction, rawProbability float64) *types.FactorAttribution {
+	return &types.FactorAttribution{
+		Symbol:         prediction.Symbol,
+		Stage:          stage,
+		Direction:      prediction.Direction,
+		Probability:    prediction.Probability,
+		RawProbability: rawProbability,
+		Confidence:     prediction.Confidence,
+		RiskLevel:      prediction.RiskLevel,
+	}
+}
+
+// errDetail 检查未通过时的说明（通过时为空）
+func errDetail(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
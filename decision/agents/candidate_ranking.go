@@ -0,0 +1,86 @@
+package agents
+
+import (
+	"math"
+	"nofx/market"
+	"sort"
+)
+
+// CandidateRanking 🏅 AI预测前的本地候选币种初筛：按动量、成交额、OI变化、偏离EMA20打分，只把前TopK个交给AI
+// 已持仓币种不参与（持仓管理每个周期都执行），用户关注币种和带异动信号的山寨币不受限制、也不占名额
+type CandidateRanking struct {
+	TopK int // 每周期最多交给AI预测的候选币种数（0=不限制）
+}
+
+// Enabled 是否启用初筛
+func (r CandidateRanking) Enabled() bool {
+	return r.TopK > 0
+}
+
+// candidateScore 候选币种的本地评分（各因子为候选集内的百分位排名，0-1）
+type candidateScore struct {
+	Symbol   string
+	Score    float64
+	Momentum float64 // |1h涨跌幅| + |4h涨跌幅|/2
+	Volume   float64 // 24h成交额（USDT）
+	OIDelta  float64 // |4h OI变化%|
+	EMADist  float64 // |价格偏离EMA20%|
+}
+
+// rankCandidates 对需要AI预测的候选币种打分，返回未进入前TopK、本周期跳过的币种及评分
+func (r CandidateRanking) rankCandidates(coins []CandidateCoin, dataMap map[string]*market.Data) map[string]candidateScore {
+	if !r.Enabled() {
+		return nil
+	}
+
+	var scores []candidateScore
+	for _, coin := range coins {
+		if coin.UserPriority() || coin.Idea != "" || (coin.Signal != "" && !isMajorSymbol(coin.Symbol)) {
+			continue // ⭐📥🚨 始终分析
+		}
+		data, ok := dataMap[coin.Symbol]
+		if !ok || data == nil {
+			continue
+		}
+		s := candidateScore{
+			Symbol:   coin.Symbol,
+			Momentum: math.Abs(data.PriceChange1h) + math.Abs(data.PriceChange4h)/2,
+			Volume:   data.Volume24h,
+			OIDelta:  math.Abs(data.OIChange4h),
+		}
+		if data.CurrentEMA20 > 0 {
+			s.EMADist = math.Abs(data.CurrentPrice-data.CurrentEMA20) / data.CurrentEMA20 * 100
+		}
+		scores = append(scores, s)
+	}
+	if len(scores) <= r.TopK {
+		return nil
+	}
+
+	// 各因子量纲不同，按候选集内的百分位排名归一化后等权平均
+	factors := []func(s *candidateScore) float64{
+		func(s *candidateScore) float64 { return s.Momentum },
+		func(s *candidateScore) float64 { return s.Volume },
+		func(s *candidateScore) float64 { return s.OIDelta },
+		func(s *candidateScore) float64 { return s.EMADist },
+	}
+	order := make([]int, len(scores))
+	for _, factor := range factors {
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return factor(&scores[order[a]]) < factor(&scores[order[b]])
+		})
+		for rank, idx := range order {
+			scores[idx].Score += float64(rank) / float64(len(scores)-1) / float64(len(factors))
+		}
+	}
+
+	sort.SliceStable(scores, func(a, b int) bool { return scores[a].Score > scores[b].Score })
+	skipped := make(map[string]candidateScore, len(scores)-r.TopK)
+	for _, s := range scores[r.TopK:] {
+		skipped[s.Symbol] = s
+	}
+	return skipped
+}
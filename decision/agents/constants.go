@@ -1,5 +1,7 @@
 package agents
 
+import "nofx/decision/riskcalc"
+
 // 全局常量 - 统一所有Agent使用的阈值与风控参数
 const (
 	// === 市场体制阈值 ===
@@ -8,15 +10,15 @@ const (
 	ATRPctMid     = 4.0 // <4.0% = 中波动, >=4.0% = 高波动
 
 	// === 止损止盈倍数范围 ===
-	MinStopMultiple = 4.5  // 最小止损倍数（xATR）- 从2.0放宽到4.5，给市场波动留出空间
-	MaxStopMultiple = 25.0 // 最大止损倍数（xATR） - 放宽以支持低波动市场
-	MinTPMultiple   = 9.0  // 最小止盈倍数（xATR）- 从3.0调整到9.0，保持2:1风险回报比
-	MaxTPMultiple   = 30.0 // 最大止盈倍数（xATR） - 同步放宽
+	MinStopMultiple = riskcalc.DefaultMinStopATR // 最小止损倍数（xATR）- 从2.0放宽到4.5，给市场波动留出空间
+	MaxStopMultiple = riskcalc.DefaultMaxStopATR // 最大止损倍数（xATR） - 放宽以支持低波动市场
+	MinTPMultiple   = riskcalc.DefaultMinTPATR   // 最小止盈倍数（xATR）- 从3.0调整到9.0，保持2:1风险回报比
+	MaxTPMultiple   = riskcalc.DefaultMaxTPATR   // 最大止盈倍数（xATR） - 同步放宽
 
 	// === 风险回报比要求 ===
-	MinRiskReward     = 2.0  // 最低R/R比要求
-	RRFloatTolerance  = 0.05 // R/R比允许的浮点误差（5%）
-	RRStrictTolerance = 0.01 // 强平调整前的严格误差（1%）
+	MinRiskReward     = riskcalc.DefaultMinRiskReward // 最低R/R比要求
+	RRFloatTolerance  = riskcalc.DefaultRRTolerance   // R/R比允许的浮点误差（5%）
+	RRStrictTolerance = 0.01                          // 强平调整前的严格误差（1%）
 
 	// === 成交量信号阈值 ===
 	VolumeExpandThreshold   = 20.0  // 成交量放大阈值（%）
@@ -28,8 +30,8 @@ const (
 	FundingRateShortThreshold = 0.01 // 做空资金费率阈值（%）
 
 	// === 强平价安全边距 ===
-	LiquidationSafetyRatio = 0.3  // 止损价距强平价的安全缓冲（30%）
-	LiquidationMarginRate  = 0.95 // 强平保证金率（近似）
+	LiquidationSafetyRatio = riskcalc.DefaultLiquidationSafetyRatio // 止损价距强平价的安全缓冲（30%）
+	LiquidationMarginRate  = riskcalc.DefaultLiquidationMarginRate  // 强平保证金率（近似）
 
 	// === 仓位sizing风险预算 ===
 	RiskBudgetPerTrade = 0.032 // 每笔交易的风险预算（1%净值）
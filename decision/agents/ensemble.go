@@ -0,0 +1,198 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"nofx/decision/tracker"
+	"nofx/decision/types"
+	"nofx/mcp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 集成投票方式
+const (
+	EnsembleMajority = "majority" // 多数方向投票，概率取同向模型平均
+	EnsembleWeighted = "weighted" // 按各模型历史方向准确率加权
+)
+
+// neutralModelWeight 样本不足时模型的默认权重
+const neutralModelWeight = 0.5
+
+// EnsembleMember 集成预测中的单个模型
+type EnsembleMember struct {
+	Name   string
+	Client *mcp.Client
+}
+
+// Ensemble 多模型集成预测配置
+type Ensemble struct {
+	Members []EnsembleMember
+	Mode    string             // "majority"（默认）或 "weighted"
+	Weights map[string]float64 // 各模型投票权重（weighted模式，由预测跟踪器的历史准确率计算）
+}
+
+// enabled 至少2个模型时才启用集成
+func (e *Ensemble) enabled() bool {
+	return e != nil && len(e.Members) >= 2
+}
+
+// weight 模型的投票权重
+func (e *Ensemble) weight(model string) float64 {
+	if e.Mode != EnsembleWeighted {
+		return 1
+	}
+	if w, ok := e.Weights[model]; ok && w > 0 {
+		return w
+	}
+	return neutralModelWeight
+}
+
+// SetEnsemble 设置多模型集成预测（nil或少于2个模型时使用单模型）
+func (agent *PredictionAgent) SetEnsemble(ensemble *Ensemble) {
+	agent.ensemble = ensemble
+}
+
+// memberPrediction 单个模型的预测结果
+type memberPrediction struct {
+	model      string
+	prediction *types.Prediction
+	err        error
+}
+
+// predictEnsemble 并行调用所有模型，按投票合并为一个预测
+func (agent *PredictionAgent) predictEnsemble(callCtx context.Context, ctx *PredictionContext, maxRetries int) (*types.Prediction, error) {
+	results := make([]memberPrediction, len(agent.ensemble.Members))
+
+	var wg sync.WaitGroup
+	for i, member := range agent.ensemble.Members {
+		wg.Add(1)
+		go func(i int, member EnsembleMember) {
+			defer wg.Done()
+			prediction, err := agent.predictWithRetry(callCtx, member.Client, ctx, maxRetries)
+			results[i] = memberPrediction{model: member.Name, prediction: prediction, err: err}
+		}(i, member)
+	}
+	wg.Wait()
+
+	var succeeded []memberPrediction
+	var errs []string
+	for _, r := range results {
+		if r.err != nil {
+			log.Printf("⚠️  [集成] 模型%s预测失败: %v", r.model, r.err)
+			errs = append(errs, fmt.Sprintf("%s: %v", r.model, r.err))
+			continue
+		}
+		r.prediction.Model = r.model
+		succeeded = append(succeeded, r)
+	}
+
+	if len(succeeded) == 0 {
+		return nil, fmt.Errorf("所有模型预测失败: %s", strings.Join(errs, "; "))
+	}
+	if callCtx.Err() != nil && len(succeeded) < len(results) {
+		log.Printf("⌛ [集成] 时间预算耗尽，仅使用%d/%d个模型的结果", len(succeeded), len(results))
+	}
+
+	return agent.ensemble.combine(succeeded), nil
+}
+
+// combine 合并各模型预测
+// 方向：按（加权）票数取多数，并列时视为neutral；数值：同向模型按权重平均
+func (e *Ensemble) combine(members []memberPrediction) *types.Prediction {
+	votes := make([]types.ModelVote, 0, len(members))
+	directionWeight := make(map[string]float64)
+	for _, m := range members {
+		w := e.weight(m.model)
+		votes = append(votes, types.ModelVote{
+			Model:       m.model,
+			Direction:   m.prediction.Direction,
+			Probability: m.prediction.Probability,
+			Weight:      w,
+		})
+		directionWeight[m.prediction.Direction] += w
+	}
+
+	// 选出票数最多的方向（并列时无共识）
+	direction := ""
+	best := 0.0
+	tie := false
+	for dir, w := range directionWeight {
+		switch {
+		case w > best:
+			direction, best, tie = dir, w, false
+		case w == best:
+			tie = true
+		}
+	}
+
+	var agreeing []memberPrediction
+	if !tie {
+		for _, m := range members {
+			if m.prediction.Direction == direction {
+				agreeing = append(agreeing, m)
+			}
+		}
+	}
+
+	if len(agreeing) == 0 {
+		// 模型之间没有共识：输出低概率neutral，后续方向检查会跳过开仓
+		base := *members[0].prediction
+		base.Direction = "neutral"
+		base.Probability = 0.5
+		base.Confidence = "low"
+		base.Reasoning = "多模型预测方向分歧，无共识 | " + describeVotes(votes)
+		base.Model = "ensemble"
+		base.Votes = votes
+		return &base
+	}
+
+	// 同向模型中概率最高的作为基准（保留时间框架、关键因素等文字字段）
+	sort.Slice(agreeing, func(i, j int) bool {
+		return agreeing[i].prediction.Probability > agreeing[j].prediction.Probability
+	})
+	combined := *agreeing[0].prediction
+
+	var totalWeight, prob, move, bestCase, worstCase float64
+	for _, m := range agreeing {
+		w := e.weight(m.model)
+		totalWeight += w
+		prob += m.prediction.Probability * w
+		move += m.prediction.ExpectedMove * w
+		bestCase += m.prediction.BestCase * w
+		worstCase += m.prediction.WorstCase * w
+	}
+	combined.Probability = prob / totalWeight
+	combined.ExpectedMove = move / totalWeight
+	combined.BestCase = bestCase / totalWeight
+	combined.WorstCase = worstCase / totalWeight
+	combined.Reasoning = fmt.Sprintf("集成%d/%d同向 | %s | %s",
+		len(agreeing), len(members), describeVotes(votes), combined.Reasoning)
+	combined.Model = "ensemble"
+	combined.Votes = votes
+
+	return &combined
+}
+
+// describeVotes 投票摘要，如 "deepseek:up 72% | qwen:down 60%"
+func describeVotes(votes []types.ModelVote) string {
+	parts := make([]string, 0, len(votes))
+	for _, v := range votes {
+		parts = append(parts, fmt.Sprintf("%s:%s %.0f%%", v.Model, v.Direction, v.Probability*100))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// withWeights 按预测跟踪器的各模型历史方向准确率生成权重（样本不足的模型使用默认权重）
+func (e *Ensemble) withWeights(accuracy map[string]*tracker.ModelAccuracy) *Ensemble {
+	weighted := *e
+	weighted.Weights = make(map[string]float64, len(e.Members))
+	for _, m := range e.Members {
+		if acc, ok := accuracy[m.Name]; ok && acc.Reliable {
+			weighted.Weights[m.Name] = acc.WinRate
+		}
+	}
+	return &weighted
+}
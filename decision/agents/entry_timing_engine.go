@@ -19,7 +19,7 @@ type EntryTimingEngine struct {
 // NewEntryTimingEngine 创建入场时机引擎
 func NewEntryTimingEngine() *EntryTimingEngine {
 	return &EntryTimingEngine{
-		ADXMinimum:        25.0,  // ADX>25强趋势
+		ADXMinimum:        25.0,   // ADX>25强趋势
 		FundingRateLimit:  0.0001, // 0.01%资金费率上限
 		RSIOverBought:     70.0,   // RSI>70超买
 		RSIOverSold:       30.0,   // RSI<30超卖
@@ -29,13 +29,13 @@ func NewEntryTimingEngine() *EntryTimingEngine {
 
 // EntryDecision 入场决策
 type EntryDecision struct {
-	Strategy      string  // "immediate" 或 "wait_pullback" 或 "reject"
-	LimitPrice    float64 // 限价单价格（wait_pullback时）
-	CurrentPrice  float64 // 当前价格
-	PullbackPct   float64 // 期望回调百分比
-	ExpiryHours   int     // 有效期（小时）
-	Reasoning     string  // 决策推理
-	KeyLevels     []float64 // 关键价位（EMA20, EMA50等）
+	Strategy     string    // "immediate" 或 "wait_pullback" 或 "reject"
+	LimitPrice   float64   // 限价单价格（wait_pullback时）
+	CurrentPrice float64   // 当前价格
+	PullbackPct  float64   // 期望回调百分比
+	ExpiryHours  int       // 有效期（小时）
+	Reasoning    string    // 决策推理
+	KeyLevels    []float64 // 关键价位（EMA20, EMA50等）
 }
 
 // Decide 决策入场时机
@@ -43,6 +43,8 @@ func (e *EntryTimingEngine) Decide(
 	prediction *types.Prediction,
 	marketData *market.Data,
 ) (*EntryDecision, error) {
+	// 🕰️ 配置了独立的入场时机周期时，RSI和EMA/ATR使用该周期的值
+	marketData = marketData.ForEntryTiming()
 
 	// 🚫 第1步：趋势过滤（硬性拒绝）
 	if err := e.validateTrend(prediction.Direction, marketData); err != nil {
@@ -85,7 +87,7 @@ func (e *EntryTimingEngine) Decide(
 			CurrentPrice: marketData.CurrentPrice,
 			PullbackPct:  pullbackPct,
 			ExpiryHours:  expiry,
-			Reasoning: e.buildWaitReasoning(prediction.Direction, marketData, targetPrice),
+			Reasoning:    e.buildWaitReasoning(prediction.Direction, marketData, targetPrice),
 			KeyLevels: []float64{
 				marketData.LongerTermContext.EMA20,
 				marketData.LongerTermContext.EMA50,
@@ -0,0 +1,32 @@
+package agents
+
+// 默认开仓概率阈值（AI在有信号冲突时最高给0.65）
+const DefaultMinProbability = 0.65
+
+// EntryTuning 开仓筛选与止损参数（由策略档案设置，零值=使用默认常量）
+type EntryTuning struct {
+	MinProbability     float64 // 基础概率阈值（0=默认65%），账户亏损时仍会在此基础上提高
+	HighConfidenceOnly bool    // 只接受high/very_high置信度（默认允许medium）
+	StopATRMultiple    float64 // 最小止损ATR倍数（0=MinStopMultiple），最小止盈同步按MinRiskReward倍调整
+}
+
+func (t EntryTuning) minProbability() float64 {
+	if t.MinProbability > 0 {
+		return t.MinProbability
+	}
+	return DefaultMinProbability
+}
+
+func (t EntryTuning) stopMultiple() float64 {
+	if t.StopATRMultiple > 0 {
+		return t.StopATRMultiple
+	}
+	return MinStopMultiple
+}
+
+func (t EntryTuning) tpMultiple() float64 {
+	if t.StopATRMultiple > 0 {
+		return t.StopATRMultiple * MinRiskReward
+	}
+	return MinTPMultiple
+}
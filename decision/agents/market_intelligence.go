@@ -1,8 +1,11 @@
 package agents
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"nofx/decision/prompts"
+	"nofx/errs"
 	"nofx/market"
 	"nofx/mcp"
 )
@@ -47,7 +50,7 @@ type ExtendedDataMap struct {
 }
 
 // Collect 收集市场情报
-func (agent *MarketIntelligenceAgent) Collect(btcData *market.Data, symbols []string, marketDataMap map[string]*market.Data) (*MarketIntelligence, error) {
+func (agent *MarketIntelligenceAgent) Collect(callCtx context.Context, btcData *market.Data, symbols []string, marketDataMap map[string]*market.Data) (*MarketIntelligence, error) {
 	// 1. 分析BTC大盘背景
 	btcContext := agent.analyzeBTCContext(btcData)
 
@@ -72,7 +75,7 @@ func (agent *MarketIntelligenceAgent) Collect(btcData *market.Data, symbols []st
 	}
 
 	// 3. 调用AI进行综合分析
-	intelligence, err := agent.analyzeMarket(btcContext, extendedDataMap, btcData, marketDataMap)
+	intelligence, err := agent.analyzeMarket(callCtx, btcContext, extendedDataMap, btcData, marketDataMap)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +135,7 @@ func (agent *MarketIntelligenceAgent) analyzeBTCContext(btcData *market.Data) *B
 
 // analyzeMarket 调用AI进行市场综合分析
 func (agent *MarketIntelligenceAgent) analyzeMarket(
+	callCtx context.Context,
 	btcContext *BTCContext,
 	extendedData *ExtendedDataMap,
 	btcData *market.Data,
@@ -139,7 +143,7 @@ func (agent *MarketIntelligenceAgent) analyzeMarket(
 ) (*MarketIntelligence, error) {
 	systemPrompt, userPrompt := agent.buildIntelligencePrompt(btcContext, extendedData, btcData, marketDataMap)
 
-	response, err := agent.mcpClient.CallWithMessages(systemPrompt, userPrompt)
+	response, err := agent.mcpClient.CallWithContext(callCtx, systemPrompt, userPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("AI调用失败: %w", err)
 	}
@@ -148,11 +152,11 @@ func (agent *MarketIntelligenceAgent) analyzeMarket(
 	intelligence := &MarketIntelligence{}
 	jsonData := extractJSON(response)
 	if jsonData == "" {
-		return nil, fmt.Errorf("无法从响应中提取JSON")
+		return nil, errs.New(errs.AIParseError, "无法从响应中提取JSON")
 	}
 
 	if err := json.Unmarshal([]byte(jsonData), intelligence); err != nil {
-		return nil, fmt.Errorf("JSON解析失败: %w", err)
+		return nil, errs.Wrap(errs.AIParseError, 0, fmt.Errorf("JSON解析失败: %w", err))
 	}
 
 	return intelligence, nil
@@ -165,11 +169,16 @@ func (agent *MarketIntelligenceAgent) buildIntelligencePrompt(
 	btcData *market.Data,
 	marketDataMap map[string]*market.Data,
 ) (systemPrompt string, userPrompt string) {
-	systemPrompt = `Role: summarise global crypto context. Output JSON only:
-{"market_phase":"","key_risks":[],"key_opportunities":[],"summary":""}
-Rules: choose market_phase ∈ {accumulation,markup,distribution,markdown}. key_risks/key_opportunities 各给3条以内、≤80字符的中文短句。summary ≤3句，概括走势、情绪与风险。不要包含多余文本或 markdown。`
+	systemPrompt = prompts.Render(prompts.MarketIntelligenceSystem, nil)
 
-	userPrompt = "数据来源: Binance 5m 聚合 + 4h 指标.\n"
+	intraday, longTerm := "", ""
+	if btcData.IntradaySeries != nil {
+		intraday = btcData.IntradaySeries.Interval
+	}
+	if btcData.LongerTermContext != nil {
+		longTerm = btcData.LongerTermContext.Interval
+	}
+	userPrompt = fmt.Sprintf("数据来源: Binance %s 聚合 + %s 指标.\n", intraday, longTerm)
 
 	// 🆕 检测短期急跌/急涨
 	shortTermAlert := ""
@@ -205,7 +214,8 @@ Rules: choose market_phase ∈ {accumulation,markup,distribution,markdown}. key_
 		if btcData.LongerTermContext.AverageVolume > 0 {
 			volDelta = (btcData.LongerTermContext.CurrentVolume/btcData.LongerTermContext.AverageVolume - 1) * 100
 		}
-		userPrompt += fmt.Sprintf("BTC 4h: EMA20=%.2f | EMA50=%.2f | EMA200=%.2f | MACD=%.2f | RSI7=%.2f | Vol=%.0f/%.0f (%+.1f%%)\n",
+		userPrompt += fmt.Sprintf("BTC %s: EMA20=%.2f | EMA50=%.2f | EMA200=%.2f | MACD=%.2f | RSI7=%.2f | Vol=%.0f/%.0f (%+.1f%%)\n",
+			longTerm,
 			btcData.LongerTermContext.EMA20,
 			btcData.LongerTermContext.EMA50,
 			btcData.LongerTermContext.EMA200,
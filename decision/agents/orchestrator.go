@@ -1,7 +1,9 @@
 package agents
 
 import (
+	"context"
 	"encoding/json"
+	"nofx/decision/types"
 	"nofx/market"
 	"nofx/mcp"
 	"time"
@@ -21,6 +23,28 @@ type Context struct {
 	AltcoinLeverage int
 	MemoryPrompt    string // 🧠 AI记忆提示（Sprint 1）
 	UseLimitOrders  bool   // 是否使用限价单模式
+
+	Deadline time.Time // ⌛ 本周期AI决策截止时间（零值=不限制）
+	Ensemble *Ensemble // 🗳️ 多模型集成预测（nil=单模型）
+
+	PositionClient *mcp.Client // 💸 持仓评估专用模型（nil=与开仓预测共用，不参与集成投票）
+
+	Allocation      string // 📐 组合仓位分配模式（AllocationSequential/AllocationRiskParity/AllocationEqualWeight）
+	MaxNewPositions int    // 📐 单周期最多新开仓数量（0=默认1个）
+
+	PositionLimits PositionLimits   // 🛡️ 持仓数量与名义敞口上限
+	RiskGate       AccountRiskGate  // 🏦 按账户级别的累计亏损风控阈值
+	Tuning         EntryTuning      // 🎛️ 策略档案的开仓阈值与止损倍数
+	Cadence        ScanCadence      // ⏱️ 按币种类别降低AI预测频率
+	Ranking        CandidateRanking // 🏅 AI预测前的本地候选币种初筛
+}
+
+// budgetContext 按周期截止时间创建AI调用上下文
+func (c *Context) budgetContext() (context.Context, context.CancelFunc) {
+	if c.Deadline.IsZero() {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), c.Deadline)
 }
 
 // AccountInfo 账户信息
@@ -50,10 +74,25 @@ type PositionInfoInput struct {
 	OpenTime         time.Time // 🆕 开仓时间（用于判断持仓时长）
 }
 
+// SourceUser 用户置顶关注的候选币种来源
+const SourceUser = "user"
+
 // CandidateCoin 候选币种
 type CandidateCoin struct {
 	Symbol  string
 	Sources []string
+	Signal  string // 🚨 山寨币异动信号摘要
+	Idea    string // 📥 外部交易想法摘要（webhook）
+}
+
+// UserPriority 是否用户置顶关注的币种
+func (c CandidateCoin) UserPriority() bool {
+	for _, s := range c.Sources {
+		if s == SourceUser {
+			return true
+		}
+	}
+	return false
 }
 
 // Decision AI的交易决策
@@ -80,6 +119,11 @@ type FullDecision struct {
 	CoTTrace   string
 	Decisions  []Decision
 	Timestamp  time.Time
+
+	Attributions []types.FactorAttribution // 🧾 每个币种的决策因子归因
+	TimedOut     bool                      // ⌛ 是否因超出周期时间预算而中止了部分预测
+
+	ExtendedData map[string]*market.ExtendedData // 📸 本周期预测使用的扩展数据（按币种）
 }
 
 // DecisionOrchestrator 决策协调器
@@ -89,6 +133,7 @@ type DecisionOrchestrator struct {
 	predictionAgent   *PredictionAgent         // 预测Agent
 	btcEthLeverage    int
 	altcoinLeverage   int
+	tuning            EntryTuning // 🎛️ 本次决策使用的开仓参数（来自Context.Tuning）
 }
 
 // NewDecisionOrchestrator 创建决策协调器
@@ -102,6 +147,14 @@ func NewDecisionOrchestrator(mcpClient *mcp.Client, btcEthLeverage, altcoinLever
 	}
 }
 
+// PerformanceSummary 绩效摘要（logger.PerformanceAnalysis 实现，避免agents依赖logger）
+func PerformanceSummary(perf interface{}) string {
+	if s, ok := perf.(interface{ Summary() string }); ok {
+		return s.Summary()
+	}
+	return ""
+}
+
 // getSharpeFromPerformance 从Performance接口中提取夏普比率
 func getSharpeFromPerformance(perf interface{}) (float64, bool) {
 	if perf == nil {
@@ -136,4 +189,3 @@ func (o *DecisionOrchestrator) GetFullDecision(ctx *Context) (*FullDecision, err
 	// 使用预测驱动模式（新架构）
 	return o.GetFullDecisionPredictive(ctx)
 }
-
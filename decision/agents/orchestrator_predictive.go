@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"nofx/decision/riskcalc"
 	"nofx/decision/tracker"
 	"nofx/decision/types"
 	"nofx/market"
@@ -15,6 +16,12 @@ import (
 func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDecision, error) {
 	var cotBuilder strings.Builder
 	decisions := []Decision{}
+	attributions := []*types.FactorAttribution{} // 🧾 每个币种的决策因子归因
+
+	// ⌛ 周期时间预算：到期后中止剩余AI预测，持仓默认持有、候选币种不再分析
+	callCtx, cancel := ctx.budgetContext()
+	defer cancel()
+	timedOut := false
 
 	cotBuilder.WriteString("=== AI Prediction-Driven Decision System ===\n\n")
 
@@ -26,15 +33,24 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 
 	// 🚨 新增：提取夏普比率进行自适应风控
 	sharpeRatio, hasSharpe := getSharpeFromPerformance(ctx.Performance)
-	minProbability := 0.65   // 默认概率阈值65%（修正：AI在有冲突时最高给0.65）
-	allowMediumConf := true  // 默认允许medium置信度（修正：AI在有冲突时给medium是合理的）
+	perfSummary := PerformanceSummary(ctx.Performance) // 📐 索提诺/期望/R倍数/连胜连亏
+	o.tuning = ctx.Tuning                              // 🎛️ 策略档案的开仓阈值与止损倍数
+	minProbability := o.tuning.minProbability()        // 默认概率阈值65%（修正：AI在有冲突时最高给0.65）
+	allowMediumConf := !o.tuning.HighConfidenceOnly    // 默认允许medium置信度（修正：AI在有冲突时给medium是合理的）
+	confDesc := "允许medium置信度"
+	if !allowMediumConf {
+		confDesc = "仅high置信度"
+	}
 
 	// ⚠️  临时禁用夏普限制（用户要求）- 让系统可以正常开仓测试
 	if !hasSharpe {
-		cotBuilder.WriteString("## 📊 绩效记忆\n\n无历史绩效，使用默认阈值 (概率≥65%, 允许medium置信度)\n\n")
+		cotBuilder.WriteString(fmt.Sprintf("## 📊 绩效记忆\n\n无历史绩效，使用默认阈值 (概率≥%.0f%%, %s)\n\n", minProbability*100, confDesc))
 	} else {
 		// 显示夏普但不限制
-		cotBuilder.WriteString(fmt.Sprintf("## 📊 绩效记忆\n\n夏普=%.2f → ✅ **测试模式** (暂不限制，概率≥65%%, 允许medium)\n\n", sharpeRatio))
+		cotBuilder.WriteString(fmt.Sprintf("## 📊 绩效记忆\n\n夏普=%.2f → ✅ **测试模式** (暂不限制，概率≥%.0f%%, %s)\n\n", sharpeRatio, minProbability*100, confDesc))
+	}
+	if perfSummary != "" {
+		cotBuilder.WriteString(fmt.Sprintf("绩效: %s\n\n", perfSummary))
 	}
 
 	/* 🔒 原夏普限制（已临时禁用）
@@ -78,7 +94,7 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 		}
 	}
 
-	intelligence, err := o.intelligenceAgent.Collect(btcData, symbols, ctx.MarketDataMap)
+	intelligence, err := o.intelligenceAgent.Collect(callCtx, btcData, symbols, ctx.MarketDataMap)
 	if err != nil {
 		log.Printf("⚠️  市场情报收集失败: %v", err)
 		intelligence = &MarketIntelligence{
@@ -112,9 +128,28 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 	predTracker := tracker.NewPredictionTracker("./prediction_logs")
 	extendedDataCache := make(map[string]*market.ExtendedData)
 
+	// 🗳️ 多模型集成预测（weighted模式按各模型历史方向准确率加权）
+	if ctx.Ensemble.enabled() {
+		o.predictionAgent.SetEnsemble(ctx.Ensemble.withWeights(predTracker.GetModelAccuracy()))
+		cotBuilder.WriteString(fmt.Sprintf("**集成预测**: %d个模型 (%s投票)\n", len(ctx.Ensemble.Members), ctx.Ensemble.Mode))
+		if summary := predTracker.GetModelAccuracySummary(); summary != "" {
+			cotBuilder.WriteString(fmt.Sprintf("**模型历史准确率**: %s\n", summary))
+		}
+		cotBuilder.WriteString("\n")
+	}
+
 	// STEP 2: 持仓管理（基于预测）
 	cotBuilder.WriteString("## STEP 2: 持仓管理（基于AI预测）\n\n")
 
+	// 💸 持有/平仓判断可使用更便宜的独立模型（独立超时和token上限）
+	positionAgent := o.predictionAgent
+	if ctx.PositionClient != nil {
+		positionAgent = NewPredictionAgent(ctx.PositionClient)
+		if len(ctx.Positions) > 0 {
+			cotBuilder.WriteString(fmt.Sprintf("**持仓评估模型**: %s\n\n", ctx.PositionClient.Model))
+		}
+	}
+
 	if len(ctx.Positions) > 0 {
 		for _, pos := range ctx.Positions {
 			marketData, hasData := ctx.MarketDataMap[pos.Symbol]
@@ -129,6 +164,20 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 				extendedDataCache[pos.Symbol] = extendedData
 			}
 
+			// ⌛ 时间预算已耗尽：跳过预测，默认持有
+			if callCtx.Err() != nil {
+				timedOut = true
+				decisions = append(decisions, budgetHoldDecision(pos.Symbol))
+				attributions = append(attributions, &types.FactorAttribution{
+					Symbol:  pos.Symbol,
+					Stage:   "position",
+					Verdict: "hold",
+					Reason:  "周期时间预算耗尽，跳过预测",
+				})
+				cotBuilder.WriteString(fmt.Sprintf("**%s**: ⌛ 时间预算耗尽，默认持有\n\n", pos.Symbol))
+				continue
+			}
+
 			historicalPerf := predTracker.GetPerformance(pos.Symbol)
 			recentFeedback := predTracker.GetRecentFeedback(pos.Symbol, 8)
 
@@ -138,15 +187,27 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 				ExtendedData:   extendedData,
 				HistoricalPerf: historicalPerf,
 				SharpeRatio:    sharpeRatio,
+				PerfSummary:    perfSummary,
 				Account:        &ctx.Account,
 				Positions:      ctx.Positions,
 				RecentFeedback: recentFeedback,
 				TraderMemory:   ctx.MemoryPrompt, // 🧠 注入实际交易记忆
+				RiskGate:       ctx.RiskGate,
 			}
 
-			prediction, err := o.predictionAgent.PredictWithRetry(predCtx, 3)
+			prediction, err := positionAgent.PredictWithRetry(callCtx, predCtx, 3)
 			if err != nil {
 				log.Printf("⚠️  预测%s失败: %v", pos.Symbol, err)
+				attributions = append(attributions, &types.FactorAttribution{
+					Symbol:  pos.Symbol,
+					Stage:   "position",
+					Verdict: "hold",
+					Reason:  fmt.Sprintf("预测失败: %v", err),
+				})
+				if callCtx.Err() != nil {
+					timedOut = true
+					decisions = append(decisions, budgetHoldDecision(pos.Symbol))
+				}
 				continue
 			}
 
@@ -172,6 +233,16 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 			// 基于预测决定是否平仓
 			shouldClose, closeReason := o.shouldClosePositionWithReason(pos, prediction)
 
+			attr := newAttribution("position", prediction, originalProb)
+			attr.AccountPnLPct = ctx.Account.TotalPnLPct
+			attr.AddCheck("close_rules", !shouldClose, closeReason)
+			attr.Verdict = "hold"
+			if shouldClose {
+				attr.Verdict = "close"
+				attr.Reason = closeReason
+			}
+			attributions = append(attributions, attr)
+
 			if shouldClose {
 				action := "close_long"
 				if pos.Side == "short" {
@@ -204,7 +275,7 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 	cotBuilder.WriteString("## STEP 3: AI预测分析（寻找新机会）\n\n")
 
 	// 计算可用开仓名额
-	maxPositions := 3
+	maxPositions := ctx.PositionLimits.Total()
 	currentPositions := len(ctx.Positions)
 	availableSlots := maxPositions - currentPositions
 
@@ -226,15 +297,55 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 		validPredictions := []struct {
 			symbol     string
 			prediction *types.Prediction
+			attr       *types.FactorAttribution
 		}{}
 
+		// 🏅 本地初筛：只把评分前TopK的候选币种交给AI（已持仓、未到预测周期的不参与排名）
+		var dueCoins []CandidateCoin
 		for _, coin := range ctx.CandidateCoins {
+			if !positionSymbols[coin.Symbol] && ctx.Cadence.due(coin, ctx.CallCount) {
+				dueCoins = append(dueCoins, coin)
+			}
+		}
+		rankedOut := ctx.Ranking.rankCandidates(dueCoins, ctx.MarketDataMap)
+		if len(rankedOut) > 0 {
+			log.Printf("🏅 本地初筛: %d个候选币种中%d个未进入前%d，跳过AI预测", len(dueCoins), len(rankedOut), ctx.Ranking.TopK)
+		}
+
+		for i, coin := range ctx.CandidateCoins {
+			// ⌛ 时间预算已耗尽：不再分析剩余候选币种
+			if callCtx.Err() != nil {
+				timedOut = true
+				cotBuilder.WriteString(fmt.Sprintf("⌛ 周期时间预算耗尽，跳过剩余%d个候选币种\n\n", len(ctx.CandidateCoins)-i))
+				break
+			}
+
 			// 跳过已持仓的币种
 			if positionSymbols[coin.Symbol] {
 				cotBuilder.WriteString(fmt.Sprintf("**%s**: 已持仓，跳过分析\n\n", coin.Symbol))
 				continue
 			}
 
+			// ⏱️ 本周期不属于该类别的预测周期
+			if !ctx.Cadence.due(coin, ctx.CallCount) {
+				cotBuilder.WriteString(fmt.Sprintf("**%s**: 未到预测周期，跳过分析\n\n", coin.Symbol))
+				continue
+			}
+
+			// 🏅 本地评分未进入前TopK
+			if score, ok := rankedOut[coin.Symbol]; ok {
+				reason := fmt.Sprintf("本地初筛评分%.2f未进入前%d（动量%.2f%% | 成交额%.1fM | OI 4h变化%.2f%% | 偏离EMA20 %.2f%%）",
+					score.Score, ctx.Ranking.TopK, score.Momentum, score.Volume/1_000_000, score.OIDelta, score.EMADist)
+				cotBuilder.WriteString(fmt.Sprintf("**%s**: %s，跳过分析\n\n", coin.Symbol, reason))
+				attributions = append(attributions, &types.FactorAttribution{
+					Symbol:  coin.Symbol,
+					Stage:   "entry",
+					Verdict: "skip",
+					Reason:  reason,
+				})
+				continue
+			}
+
 			marketData, hasData := ctx.MarketDataMap[coin.Symbol]
 			if !hasData {
 				cotBuilder.WriteString(fmt.Sprintf("**%s**: 缺少市场数据，跳过分析\n\n", coin.Symbol))
@@ -256,15 +367,26 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 				ExtendedData:   extendedData,
 				HistoricalPerf: historicalPerf,
 				SharpeRatio:    sharpeRatio,
+				PerfSummary:    perfSummary,
 				Account:        &ctx.Account,
 				Positions:      ctx.Positions,
 				RecentFeedback: recentFeedback,
 				TraderMemory:   ctx.MemoryPrompt, // 🧠 注入实际交易记忆
+				AltcoinSignal:  coin.Signal,
+				TradeIdea:      coin.Idea,
+				UserPriority:   coin.UserPriority(),
+				RiskGate:       ctx.RiskGate,
 			}
 
-			prediction, err := o.predictionAgent.PredictWithRetry(predCtx, 3)
+			prediction, err := o.predictionAgent.PredictWithRetry(callCtx, predCtx, 3)
 			if err != nil {
 				log.Printf("⚠️  预测%s失败: %v", coin.Symbol, err)
+				attributions = append(attributions, &types.FactorAttribution{
+					Symbol:  coin.Symbol,
+					Stage:   "entry",
+					Verdict: "skip",
+					Reason:  fmt.Sprintf("预测失败: %v", err),
+				})
 				continue
 			}
 
@@ -290,21 +412,10 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 			// 🛡️ 强制风控检查：账户累计亏损限制
 			accountTotalPnLPct := ctx.Account.TotalPnLPct
 			var accountRiskViolation string
-			var requiredMinProb float64 = minProbability
-
-			if accountTotalPnLPct < -20 {
-				// 亏损 > 20%：严格禁止新开仓
-				accountRiskViolation = fmt.Sprintf("账户累计亏损%.2f%% > 20%%，严格禁止新开仓", accountTotalPnLPct)
-				requiredMinProb = 1.01 // 设置一个不可能达到的阈值，强制拒绝
-			} else if accountTotalPnLPct < -15 {
-				// 亏损 15-20%：谨慎交易，降低阈值给AI更多机会
-				requiredMinProb = math.Max(requiredMinProb, 0.75)
-			} else if accountTotalPnLPct < -10 {
-				// 亏损 10-15%：适度谨慎
-				requiredMinProb = math.Max(requiredMinProb, 0.70)
-			} else if accountTotalPnLPct < -5 {
-				// 亏损 5-10%：正常偏谨慎
-				requiredMinProb = math.Max(requiredMinProb, 0.68)
+			// 🏦 按账户级别缩放：亏损达到上限禁止开仓，之前逐级提高概率门槛
+			requiredMinProb, _ := ctx.RiskGate.MinProbability(accountTotalPnLPct, minProbability)
+			if requiredMinProb > 1.0 {
+				accountRiskViolation = fmt.Sprintf("账户累计亏损%.2f%% > %.0f%%，严格禁止新开仓", accountTotalPnLPct, ctx.RiskGate.MaxLoss())
 			}
 
 			// 判断是否值得开仓
@@ -314,11 +425,22 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 				(allowMediumConf && prediction.Confidence == "medium")
 
 			// 🆕 跟踪拒绝原因（用于记录所有预测）
-			var rejectReason string
+			var rejectReason, rejectRule string
+
+			// 🧾 初筛因子归因
+			attr := newAttribution("entry", prediction, originalProb)
+			attr.MinProbability = requiredMinProb
+			attr.AccountPnLPct = accountTotalPnLPct
+			attr.AddCheck("account_risk", accountRiskViolation == "", accountRiskViolation)
+			attr.AddCheck("direction", prediction.Direction != "neutral", prediction.Direction)
+			attr.AddCheck("probability", prediction.Probability >= requiredMinProb,
+				fmt.Sprintf("%.0f%% / 阈值%.0f%%", prediction.Probability*100, requiredMinProb*100))
+			attr.AddCheck("confidence", meetsConfidence, prediction.Confidence)
+			attributions = append(attributions, attr)
 
 			if accountRiskViolation != "" {
 				// 账户风控不通过，强制拒绝
-				rejectReason = accountRiskViolation
+				rejectReason, rejectRule = accountRiskViolation, "account_risk"
 				cotBuilder.WriteString(fmt.Sprintf("  × %s\n\n", accountRiskViolation))
 			} else if prediction.Probability >= requiredMinProb && meetsConfidence && prediction.Direction != "neutral" {
 				cotBuilder.WriteString(fmt.Sprintf("  ✓ 满足开仓条件（概率%.0f%% >= %.0f%% 且 置信度%s）\n",
@@ -333,14 +455,16 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 				validPredictions = append(validPredictions, struct {
 					symbol     string
 					prediction *types.Prediction
-				}{coin.Symbol, prediction})
+					attr       *types.FactorAttribution
+				}{coin.Symbol, prediction, attr})
 			} else {
 				// 详细说明不满足的原因
 				if prediction.Direction == "neutral" {
-					rejectReason = "方向neutral，不开仓"
+					rejectReason, rejectRule = "方向neutral，不开仓", "direction"
 					cotBuilder.WriteString(fmt.Sprintf("  × 方向neutral，不开仓\n\n"))
 				} else if prediction.Probability < requiredMinProb {
-					if accountTotalPnLPct < -5 {
+					rejectRule = "probability"
+					if accountTotalPnLPct < -ctx.RiskGate.Step(1) {
 						rejectReason = fmt.Sprintf("概率%.0f%% < 风控要求%.0f%% (账户亏损%.2f%%)",
 							prediction.Probability*100, requiredMinProb*100, accountTotalPnLPct)
 						cotBuilder.WriteString(fmt.Sprintf("  × %s\n\n", rejectReason))
@@ -350,6 +474,7 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 						cotBuilder.WriteString(fmt.Sprintf("  × %s\n\n", rejectReason))
 					}
 				} else if !meetsConfidence {
+					rejectRule = "confidence"
 					if allowMediumConf {
 						rejectReason = fmt.Sprintf("置信度%s不满足要求 (需要high或medium)", prediction.Confidence)
 					} else {
@@ -362,7 +487,9 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 			// 🆕 记录所有预测（初筛阶段被拒绝的）
 			// 如果有拒绝原因，立即记录；通过初筛的会在后续流程中记录
 			if rejectReason != "" {
-				if err := predTracker.RecordAll(prediction, marketData.CurrentPrice, false, rejectReason); err != nil {
+				attr.Skip(rejectReason)
+				if err := predTracker.RecordEntry(prediction, marketData.CurrentPrice, false, rejectReason,
+					o.entryPlan(rejectRule, prediction, marketData, 0, 0)); err != nil {
 					log.Printf("⚠️  记录预测失败: %v", err)
 				}
 			}
@@ -375,18 +502,50 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 			opened := 0
 			remainingBalance := ctx.Account.AvailableBalance
 
-			// 🔧 每次决策周期最多开1个新仓位（保守策略，确保质量>数量）
+			// 🛡️ 同方向持仓与总名义敞口（随本周期开仓累加）
+			sideSymbols := make(map[string][]string)
+			currentNotional := 0.0
+			for _, pos := range ctx.Positions {
+				sideSymbols[pos.Side] = append(sideSymbols[pos.Side], pos.Symbol)
+				currentNotional += math.Abs(pos.Quantity) * pos.MarkPrice
+			}
+
+			// 🔧 每次决策周期最多开1个新仓位（保守策略，确保质量>数量），可配置放宽
 			maxNewPositionsPerCycle := 1
+			if ctx.MaxNewPositions > 0 {
+				maxNewPositionsPerCycle = ctx.MaxNewPositions
+			}
+
+			// 📐 组合分配：对本周期可能开仓的候选联合分配保证金预算，避免先处理的币种占满保证金
+			var marginBudgets []float64
+			if ctx.Allocation != AllocationSequential {
+				n := min(len(validPredictions), maxNewPositionsPerCycle, availableSlots)
+				entries := make([]allocationEntry, 0, n)
+				for _, vp := range validPredictions[:n] {
+					entries = append(entries, allocationEntry{
+						symbol:  vp.symbol,
+						riskPct: entryRiskPct(vp.prediction, ctx.MarketDataMap[vp.symbol], o.tuning.stopMultiple()),
+					})
+				}
+				marginBudgets = allocateMarginBudgets(ctx.Allocation, entries, remainingBalance)
+				cotBuilder.WriteString(fmt.Sprintf("**组合分配** (%s, 可用保证金%.2f):\n", ctx.Allocation, remainingBalance))
+				for i, e := range entries {
+					cotBuilder.WriteString(fmt.Sprintf("  %s: 止损距离%.2f%% → 保证金上限%.2f\n", e.symbol, e.riskPct, marginBudgets[i]))
+				}
+				cotBuilder.WriteString("\n")
+			}
 
-			for _, vp := range validPredictions {
+			for idx, vp := range validPredictions {
 				if opened >= maxNewPositionsPerCycle {
 					cotBuilder.WriteString(fmt.Sprintf("⚠️  已达到单次决策开仓限制（%d个），剩余%d个候选机会将在下次决策时评估\n",
 						maxNewPositionsPerCycle, len(validPredictions)-opened))
 					// 🆕 记录因开仓限制而未执行的预测
 					for i := opened; i < len(validPredictions); i++ {
 						remainingVP := validPredictions[i]
+						remainingVP.attr.Skip(fmt.Sprintf("开仓限制（本周期最多%d个）", maxNewPositionsPerCycle))
 						if md, ok := ctx.MarketDataMap[remainingVP.symbol]; ok {
-							if recErr := predTracker.RecordAll(remainingVP.prediction, md.CurrentPrice, false, fmt.Sprintf("开仓限制（本周期最多%d个）", maxNewPositionsPerCycle)); recErr != nil {
+							if recErr := predTracker.RecordEntry(remainingVP.prediction, md.CurrentPrice, false, fmt.Sprintf("开仓限制（本周期最多%d个）", maxNewPositionsPerCycle),
+								o.entryPlan("max_new_positions", remainingVP.prediction, md, 0, 0)); recErr != nil {
 								log.Printf("⚠️  记录预测失败: %v", recErr)
 							}
 						}
@@ -400,8 +559,10 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 					// 🆕 记录因持仓上限而未执行的预测
 					for i := opened; i < len(validPredictions); i++ {
 						remainingVP := validPredictions[i]
+						remainingVP.attr.Skip("总持仓已满")
 						if md, ok := ctx.MarketDataMap[remainingVP.symbol]; ok {
-							if recErr := predTracker.RecordAll(remainingVP.prediction, md.CurrentPrice, false, "总持仓已满"); recErr != nil {
+							if recErr := predTracker.RecordEntry(remainingVP.prediction, md.CurrentPrice, false, "总持仓已满",
+								o.entryPlan("position_slots", remainingVP.prediction, md, 0, 0)); recErr != nil {
 								log.Printf("⚠️  记录预测失败: %v", recErr)
 							}
 						}
@@ -411,13 +572,22 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 
 				marketData := ctx.MarketDataMap[vp.symbol]
 
+				// 已分配预算的候选不超过其预算；其余候选（前面有候选被拒绝时）使用剩余资金
+				marginBudget := remainingBalance
+				if idx < len(marginBudgets) && marginBudgets[idx] < marginBudget {
+					marginBudget = marginBudgets[idx]
+				}
+
 				positionSize, leverage, stopLoss, takeProfit, err := o.calculatePositionFromPrediction(
-					vp.prediction, marketData, ctx.Account.TotalEquity, remainingBalance)
+					vp.prediction, marketData, ctx.Account.TotalEquity, marginBudget, vp.attr)
 
+				vp.attr.AddCheck("risk_calc", err == nil, errDetail(err))
 				if err != nil {
+					vp.attr.Skip(fmt.Sprintf("风险计算失败: %v", err))
 					cotBuilder.WriteString(fmt.Sprintf("**%s**: 风险计算失败 - %v\n\n", vp.symbol, err))
 					// 🆕 记录被拒绝的预测（风险计算失败）
-					if recErr := predTracker.RecordAll(vp.prediction, ctx.MarketDataMap[vp.symbol].CurrentPrice, false, fmt.Sprintf("风险计算失败: %v", err)); recErr != nil {
+					if recErr := predTracker.RecordEntry(vp.prediction, marketData.CurrentPrice, false, fmt.Sprintf("风险计算失败: %v", err),
+						o.entryPlan("risk_calc", vp.prediction, marketData, 0, 0)); recErr != nil {
 						log.Printf("⚠️  记录预测失败: %v", recErr)
 					}
 					continue
@@ -426,10 +596,13 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 				validationErr := o.validateRiskParameters(
 					vp.symbol, vp.prediction.Direction, marketData,
 					stopLoss, takeProfit, leverage)
+				vp.attr.AddCheck("risk_validation", validationErr == nil, errDetail(validationErr))
 				if validationErr != nil {
+					vp.attr.Skip(fmt.Sprintf("风控验证失败: %v", validationErr))
 					cotBuilder.WriteString(fmt.Sprintf("**%s**: 风控验证失败 - %v\n\n", vp.symbol, validationErr))
 					// 🆕 记录被拒绝的预测（风控验证失败）
-					if recErr := predTracker.RecordAll(vp.prediction, marketData.CurrentPrice, false, fmt.Sprintf("风控验证失败: %v", validationErr)); recErr != nil {
+					if recErr := predTracker.RecordEntry(vp.prediction, marketData.CurrentPrice, false, fmt.Sprintf("风控验证失败: %v", validationErr),
+						o.entryPlan("risk_validation", vp.prediction, marketData, stopLoss, takeProfit)); recErr != nil {
 						log.Printf("⚠️  记录预测失败: %v", recErr)
 					}
 					continue
@@ -438,37 +611,62 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 				// 🆕 入场时机验证（防止追涨杀跌）
 				entryEngine := NewEntryTimingEngine()
 				entryDecision, timingErr := entryEngine.Decide(vp.prediction, marketData)
+				vp.attr.AddCheck("entry_timing", timingErr == nil, errDetail(timingErr))
 				if timingErr != nil {
+					vp.attr.EntryTiming = "reject"
+					vp.attr.Skip(fmt.Sprintf("入场时机不佳: %v", timingErr))
 					cotBuilder.WriteString(fmt.Sprintf("**%s**: 入场时机不佳 - %v\n\n", vp.symbol, timingErr))
 					log.Printf("⏸️  [%s] 入场时机不佳: %v", vp.symbol, timingErr)
 					// 🆕 记录被拒绝的预测（入场时机不佳）
-					if recErr := predTracker.RecordAll(vp.prediction, marketData.CurrentPrice, false, fmt.Sprintf("入场时机不佳: %v", timingErr)); recErr != nil {
+					if recErr := predTracker.RecordEntry(vp.prediction, marketData.CurrentPrice, false, fmt.Sprintf("入场时机不佳: %v", timingErr),
+						o.entryPlan("entry_timing", vp.prediction, marketData, stopLoss, takeProfit)); recErr != nil {
 						log.Printf("⚠️  记录预测失败: %v", recErr)
 					}
 					continue
 				}
+				vp.attr.EntryTiming = entryDecision.Strategy
 
-			// 🆕 Portfolio级别风控验证
-			portfolioRM := NewPortfolioRiskManager()
-			newSide := "long"
-			if vp.prediction.Direction == "down" {
-				newSide = "short"
-			}
-			// 估算新仓位风险
-			riskPercent := math.Abs(vp.prediction.WorstCase)
-			estimatedRisk := positionSize * (riskPercent / 100.0)
-
-			if portfolioErr := portfolioRM.ValidateNewPosition(
-				ctx.Positions, vp.symbol, newSide, estimatedRisk, ctx.Account.TotalEquity,
-			); portfolioErr != nil {
-				cotBuilder.WriteString(fmt.Sprintf("**%s**: Portfolio风控拒绝 - %v\n\n", vp.symbol, portfolioErr))
-				log.Printf("🛡️  [%s] Portfolio风控拒绝: %v", vp.symbol, portfolioErr)
-				// 🆕 记录被拒绝的预测（Portfolio风控拒绝）
-				if recErr := predTracker.RecordAll(vp.prediction, marketData.CurrentPrice, false, fmt.Sprintf("Portfolio风控拒绝: %v", portfolioErr)); recErr != nil {
-					log.Printf("⚠️  记录预测失败: %v", recErr)
+				// 🆕 Portfolio级别风控验证
+				portfolioRM := NewPortfolioRiskManager()
+				newSide := "long"
+				if vp.prediction.Direction == "down" {
+					newSide = "short"
+				}
+				// 估算新仓位风险
+				riskPercent := math.Abs(vp.prediction.WorstCase)
+				estimatedRisk := positionSize * (riskPercent / 100.0)
+
+				portfolioErr := portfolioRM.ValidateNewPosition(
+					ctx.Positions, vp.symbol, newSide, estimatedRisk, ctx.Account.TotalEquity,
+				)
+				vp.attr.AddCheck("portfolio_risk", portfolioErr == nil, errDetail(portfolioErr))
+				if portfolioErr != nil {
+					vp.attr.Skip(fmt.Sprintf("Portfolio风控拒绝: %v", portfolioErr))
+					cotBuilder.WriteString(fmt.Sprintf("**%s**: Portfolio风控拒绝 - %v\n\n", vp.symbol, portfolioErr))
+					log.Printf("🛡️  [%s] Portfolio风控拒绝: %v", vp.symbol, portfolioErr)
+					// 🆕 记录被拒绝的预测（Portfolio风控拒绝）
+					if recErr := predTracker.RecordEntry(vp.prediction, marketData.CurrentPrice, false, fmt.Sprintf("Portfolio风控拒绝: %v", portfolioErr),
+						o.entryPlan("portfolio_risk", vp.prediction, marketData, stopLoss, takeProfit)); recErr != nil {
+						log.Printf("⚠️  记录预测失败: %v", recErr)
+					}
+					continue
+				}
+
+				// 🛡️ 持仓上限：同方向币种数 + 总名义敞口
+				limitErr := ctx.PositionLimits.CheckSide(vp.symbol, newSide, sideSymbols[newSide])
+				if limitErr == nil {
+					limitErr = ctx.PositionLimits.CheckNotional(currentNotional, positionSize, ctx.Account.TotalEquity)
+				}
+				vp.attr.AddCheck("position_limits", limitErr == nil, errDetail(limitErr))
+				if limitErr != nil {
+					vp.attr.Skip(fmt.Sprintf("持仓上限: %v", limitErr))
+					cotBuilder.WriteString(fmt.Sprintf("**%s**: 持仓上限 - %v\n\n", vp.symbol, limitErr))
+					if recErr := predTracker.RecordEntry(vp.prediction, marketData.CurrentPrice, false, fmt.Sprintf("持仓上限: %v", limitErr),
+						o.entryPlan("position_limits", vp.prediction, marketData, stopLoss, takeProfit)); recErr != nil {
+						log.Printf("⚠️  记录预测失败: %v", recErr)
+					}
+					continue
 				}
-				continue
-			}
 
 				// 🆕 限价单支持：根据配置和入场时机决定是否使用限价单
 				isLimitOrder := false
@@ -518,11 +716,15 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 				}
 
 				requiredMargin := positionSize / float64(leverage)
+				vp.attr.AddCheck("margin", requiredMargin <= remainingBalance,
+					fmt.Sprintf("需要%.2f, 剩余%.2f", requiredMargin, remainingBalance))
 				if requiredMargin > remainingBalance {
+					vp.attr.Skip(fmt.Sprintf("剩余资金不足（需要%.2f, 剩余%.2f）", requiredMargin, remainingBalance))
 					cotBuilder.WriteString(fmt.Sprintf("**%s**: 剩余资金不足（需要%.2f, 剩余%.2f）\n\n",
 						vp.symbol, requiredMargin, remainingBalance))
 					// 🆕 记录被拒绝的预测（资金不足）
-					if recErr := predTracker.RecordAll(vp.prediction, marketData.CurrentPrice, false, fmt.Sprintf("剩余资金不足（需要%.2f, 剩余%.2f）", requiredMargin, remainingBalance)); recErr != nil {
+					if recErr := predTracker.RecordEntry(vp.prediction, marketData.CurrentPrice, false, fmt.Sprintf("剩余资金不足（需要%.2f, 剩余%.2f）", requiredMargin, remainingBalance),
+						o.entryPlan("margin", vp.prediction, marketData, stopLoss, takeProfit)); recErr != nil {
 						log.Printf("⚠️  记录预测失败: %v", recErr)
 					}
 					continue
@@ -552,6 +754,12 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 
 				riskPercent = math.Abs(vp.prediction.WorstCase)
 
+				vp.attr.Verdict = "open"
+				vp.attr.PositionSizeUSD = positionSize
+				vp.attr.Leverage = leverage
+				vp.attr.StopLoss = stopLoss
+				vp.attr.TakeProfit = takeProfit
+
 				decisions = append(decisions, Decision{
 					Symbol:          vp.symbol,
 					Action:          action,
@@ -572,12 +780,15 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 				})
 
 				// 🆕 记录已执行的预测
-				if err := predTracker.RecordAll(vp.prediction, marketData.CurrentPrice, true, ""); err != nil {
+				if err := predTracker.RecordEntry(vp.prediction, marketData.CurrentPrice, true, "",
+					tracker.EntryPlan{StopLoss: stopLoss, TakeProfit: takeProfit}); err != nil {
 					log.Printf("⚠️  记录预测失败: %v", err)
 				}
 
 				remainingBalance -= requiredMargin
 				opened++
+				sideSymbols[newSide] = append(sideSymbols[newSide], vp.symbol)
+				currentNotional += positionSize
 			}
 		}
 	}
@@ -591,10 +802,31 @@ func (o *DecisionOrchestrator) GetFullDecisionPredictive(ctx *Context) (*FullDec
 		})
 	}
 
-	return &FullDecision{
+	if timedOut {
+		log.Printf("⌛ 本周期AI决策超出时间预算，已中止剩余预测")
+		cotBuilder.WriteString("\n⌛ 本周期AI决策超出时间预算，剩余预测已中止（持仓默认持有）\n")
+	}
+
+	fullDecision := &FullDecision{
 		CoTTrace:  cotBuilder.String(),
 		Decisions: decisions,
-	}, nil
+		TimedOut:  timedOut,
+
+		ExtendedData: extendedDataCache,
+	}
+	for _, attr := range attributions {
+		fullDecision.Attributions = append(fullDecision.Attributions, *attr)
+	}
+	return fullDecision, nil
+}
+
+// budgetHoldDecision 时间预算耗尽时持仓的回退决策（默认持有）
+func budgetHoldDecision(symbol string) Decision {
+	return Decision{
+		Symbol:    symbol,
+		Action:    "hold",
+		Reasoning: "⌛ 本周期AI时间预算耗尽，未完成预测，默认持有",
+	}
 }
 
 // shouldClosePosition 基于AI预测判断是否应该平仓（保留向后兼容）
@@ -632,314 +864,83 @@ func (o *DecisionOrchestrator) shouldClosePositionWithReason(pos PositionInfoInp
 		return true, fmt.Sprintf("止损: 亏损%.2f%% > 20%%", pos.UnrealizedPnLPct)
 	}
 
-	// 3. 如果持仓时间过长（超过24小时）且未盈利 → 平仓
-	if holdDuration > 24*time.Hour && pos.UnrealizedPnLPct < 5.0 {
-		log.Printf("  → 触发条件3: 持仓过久(%.1f小时, 盈利%.2f%%)", holdDuration.Hours(), pos.UnrealizedPnLPct)
-		return true, fmt.Sprintf("持仓过久: %.0f小时 > 24小时且盈利%.2f%% < 5%%", holdDuration.Hours(), pos.UnrealizedPnLPct)
-	}
+	// ⌛ 持仓过久的到期平仓已移至交易器（trader/position_expiry.go），每个周期确定性执行，不依赖预测成功
 
 	log.Printf("  → 不平仓")
 	return false, ""
 }
 
-// calculatePositionFromPrediction 基于AI预测计算仓位参数
+// entryPlan 记录开仓被拒绝的规则和止损止盈（未进入仓位计算时按预测估算假设值，用于事后模拟闸门价值）
+func (o *DecisionOrchestrator) entryPlan(rule string, prediction *types.Prediction, marketData *market.Data, stopLoss, takeProfit float64) tracker.EntryPlan {
+	side := riskcalc.Side(prediction.Direction)
+	if (stopLoss <= 0 || takeProfit <= 0) && side != "" && marketData != nil && marketData.LongerTermContext != nil {
+		stopLoss, takeProfit = riskcalc.ProtectionPrices(side, marketData.CurrentPrice, marketData.LongerTermContext.ATR14,
+			prediction.BestCase, prediction.WorstCase, o.tuning.stopMultiple(), riskcalc.DefaultLimits())
+	}
+	return tracker.EntryPlan{Rule: rule, StopLoss: stopLoss, TakeProfit: takeProfit}
+}
+
+// calculatePositionFromPrediction 基于AI预测计算仓位参数（计算在riskcalc中，这里负责修正预测、记录日志和归因）
 func (o *DecisionOrchestrator) calculatePositionFromPrediction(
 	prediction *types.Prediction,
 	marketData *market.Data,
 	totalEquity float64,
 	availableBalance float64,
+	attr *types.FactorAttribution,
 ) (positionSize float64, leverage int, stopLoss float64, takeProfit float64, err error) {
-
-	// 🔧 修复AI预测值的符号错误和逻辑错误
-	// 做空时：best_case应该<0且绝对值大（价格跌得多=盈利多），worst_case应该>0（价格涨=亏损）
-	// 做多时：best_case应该>0（价格涨=盈利），worst_case应该<0（价格跌=亏损）
-	if prediction.Direction == "down" {
-		// 做空：三种错误情况
-		if prediction.BestCase > 0 {
-			// 情况1：best_case是正数，说明AI认为价格上涨是"最好情况" → 完全搞反
-			log.Printf("🔧 %s 做空预测修正（类型1）：best_case %.2f%% → %.2f%%, worst_case %.2f%% → %.2f%%",
-				prediction.Symbol, prediction.BestCase, -math.Abs(prediction.WorstCase),
-				prediction.WorstCase, math.Abs(prediction.BestCase))
-			prediction.BestCase, prediction.WorstCase = -math.Abs(prediction.WorstCase), math.Abs(prediction.BestCase)
-		} else if prediction.BestCase < 0 && prediction.WorstCase < 0 {
-			// 情况2：两个都是负数，AI理解为"价格跌幅"，但把小跌幅当成最好 → 逻辑反了
-			// 对做空：跌得多才是最好的，所以应该交换
-			if math.Abs(prediction.BestCase) < math.Abs(prediction.WorstCase) {
-				// best_case的绝对值小于worst_case，说明AI认为"跌得少=好"，这是错的
-				log.Printf("🔧 %s 做空预测修正（类型2）：交换best/worst并调整符号",
-					prediction.Symbol)
-				log.Printf("   修正前: best=%.2f%%, worst=%.2f%%", prediction.BestCase, prediction.WorstCase)
-				// 交换并修正：跌得多的变成best_case（保持负号），跌得少的变成worst_case（改正号表示止损）
-				prediction.BestCase, prediction.WorstCase = prediction.WorstCase, -prediction.BestCase
-				log.Printf("   修正后: best=%.2f%%, worst=%.2f%%", prediction.BestCase, prediction.WorstCase)
-			} else {
-				// best_case绝对值已经大于worst_case，只需要修正worst_case的符号
-				log.Printf("🔧 %s 做空worst_case符号修正：%.2f%% → %.2f%%",
-					prediction.Symbol, prediction.WorstCase, -prediction.WorstCase)
-				prediction.WorstCase = -prediction.WorstCase
-			}
-		} else if prediction.WorstCase < 0 {
-			// 情况3：best_case正确（负数），worst_case错误（也是负数）
-			log.Printf("🔧 %s 做空worst_case符号修正：%.2f%% → %.2f%%",
-				prediction.Symbol, prediction.WorstCase, -prediction.WorstCase)
-			prediction.WorstCase = -prediction.WorstCase
-		}
-	} else if prediction.Direction == "up" {
-		// 做多：检查AI是否理解错误
-		if prediction.BestCase < 0 {
-			// best_case是负数，说明AI认为价格下跌是"最好情况"，这对做多是错的
-			log.Printf("🔧 %s 做多预测修正：best_case %.2f%% → %.2f%%, worst_case %.2f%% → %.2f%%",
-				prediction.Symbol, prediction.BestCase, math.Abs(prediction.WorstCase),
-				prediction.WorstCase, -math.Abs(prediction.BestCase))
-			prediction.BestCase, prediction.WorstCase = math.Abs(prediction.WorstCase), -math.Abs(prediction.BestCase)
-		} else if prediction.WorstCase > 0 {
-			// best_case已经是正数（正确），但worst_case也是正数（错误）
-			// worst_case应该是负数（价格下跌=止损）
-			log.Printf("🔧 %s 做多worst_case修正：%.2f%% → %.2f%%",
-				prediction.Symbol, prediction.WorstCase, -prediction.WorstCase)
-			prediction.WorstCase = -prediction.WorstCase
-		}
-	}
-
-	// 基于概率和风险计算仓位（简化的凯利公式）
-	// f* = (p*b - q) / b
-	// p = 胜率, q = 败率, b = 盈亏比
-
-	winRate := prediction.Probability
-	loseRate := 1 - prediction.Probability
-
-	// 🔧 修复：根据ATR%动态确保best_case和worst_case有合理值
-	// 在低波动市场中，AI可能给出极小的值，需要根据ATR调整
-	atrPct := (marketData.LongerTermContext.ATR14 / marketData.CurrentPrice) * 100
-
-	// 动态计算最小case值：至少为4.5倍ATR（与MinStopMultiple对齐）
-	minCaseValue := math.Max(0.5, atrPct*MinStopMultiple)
-
-	if math.Abs(prediction.BestCase) < minCaseValue {
-		log.Printf("⚠️  %s best_case=%.2f%%过小（ATR%%=%.2f%%），调整为%.2f%%",
-			prediction.Symbol, prediction.BestCase, atrPct, minCaseValue)
-		if prediction.BestCase >= 0 {
-			prediction.BestCase = minCaseValue
-		} else {
-			prediction.BestCase = -minCaseValue
-		}
-	}
-
-	if math.Abs(prediction.WorstCase) < minCaseValue {
-		log.Printf("⚠️  %s worst_case=%.2f%%过小（ATR%%=%.2f%%），调整为%.2f%%",
-			prediction.Symbol, prediction.WorstCase, atrPct, minCaseValue)
-		if prediction.WorstCase >= 0 {
-			prediction.WorstCase = minCaseValue
-		} else {
-			prediction.WorstCase = -minCaseValue
-		}
+	if marketData == nil || marketData.LongerTermContext == nil {
+		return 0, 0, 0, 0, fmt.Errorf("市场数据不完整")
 	}
 
-	// 🔧 新增：确保调整后的R/R比 ≥ 2.0
-	// 这是风控的硬性要求，即使在低波动市场也必须满足
-	minRR := 2.0
-
-	// 🔧 修复：做空和做多的R/R计算都应该是 盈利/亏损
+	// 做多以外的方向都按做空处理（与下单一致）
+	side := "long"
 	if prediction.Direction == "down" {
-		// 做空：|best_case| / |worst_case| ≥ 2.0（盈利/亏损）
-		currentRR := math.Abs(prediction.BestCase) / math.Abs(prediction.WorstCase)
-		if currentRR < minRR {
-			// R/R不足，调整best_case（增大止盈目标）以满足要求
-			requiredBestCase := math.Abs(prediction.WorstCase) * minRR
-			// best_case应该是负数（价格下跌）
-			prediction.BestCase = -requiredBestCase
-			log.Printf("🔧 %s 做空R/R调整: best_case %.2f%% → %.2f%% (确保R/R≥%.1f)",
-				prediction.Symbol, math.Abs(prediction.BestCase), requiredBestCase, minRR)
-		}
-	} else {
-		// 做多：best_case / |worst_case| ≥ 2.0（盈利/亏损）
-		currentRR := math.Abs(prediction.BestCase) / math.Abs(prediction.WorstCase)
-		if currentRR < minRR {
-			// R/R不足，调整best_case（增大止盈目标）以满足要求
-			requiredBestCase := math.Abs(prediction.WorstCase) * minRR
-			// best_case应该是正数（价格上涨）
-			prediction.BestCase = requiredBestCase
-			log.Printf("🔧 %s 做多R/R调整: best_case %.2f%% → %.2f%% (确保R/R≥%.1f)",
-				prediction.Symbol, math.Abs(prediction.BestCase), requiredBestCase, minRR)
-		}
+		side = "short"
 	}
 
-	// 🔧 关键修复：根据方向正确计算盈亏比
-	// AI预测的 best_case/worst_case 是价格变化百分比
-	// 需要转换为持仓盈亏比
-	var payoffRatio float64
-
-	if prediction.Direction == "down" {
-		// 做空时：
-		// - best_case应该是负数（价格下跌，盈利）
-		// - worst_case应该是正数或小负数（价格上涨或小跌，亏损）
-		// 但AI可能返回都是负数的情况，需要兼容处理
-
-		// 取绝对值确保计算正确
-		absBest := math.Abs(prediction.BestCase)
-		absWorst := math.Abs(prediction.WorstCase)
-
-		if absBest < 1e-6 {
-			return 0, 0, 0, 0, fmt.Errorf("做空时best_case(%.2f)过小，无法计算盈亏比", prediction.BestCase)
-		}
-
-		// 做空的盈亏比 = 盈利幅度 / 亏损幅度
-		// 如果都是负数，说明AI理解为都是跌幅，取较大的跌幅作为盈利
-		if prediction.BestCase < 0 && prediction.WorstCase < 0 {
-			// 都是负数：取绝对值较大的作为盈利（价格跌得更多）
-			if absWorst > absBest {
-				payoffRatio = absWorst / absBest // worst_case跌得更多，是盈利
-			} else {
-				payoffRatio = absBest / absWorst // best_case跌得更多，是盈利
-			}
-		} else {
-			// 正常情况：best_case负（盈利），worst_case正（亏损）
-			payoffRatio = absBest / absWorst
-		}
-
-	} else {
-		// 做多时：价格上涨是盈利（best_case），价格下跌是亏损（worst_case）
-		// 盈亏比 = best_case / |worst_case|
-		absWorst := math.Abs(prediction.WorstCase)
-		if absWorst < 1e-6 {
-			return 0, 0, 0, 0, fmt.Errorf("做多时worst_case(%.2f)过小，无法计算盈亏比", prediction.WorstCase)
-		}
-		payoffRatio = prediction.BestCase / absWorst
-	}
-
-	if payoffRatio <= 0 {
-		return 0, 0, 0, 0, fmt.Errorf("无效的盈亏比: %.2f", payoffRatio)
-	}
-
-	// 凯利比例
-	kellyFraction := (winRate*payoffRatio - loseRate) / payoffRatio
-
-	if kellyFraction <= 0 {
-		return 0, 0, 0, 0, fmt.Errorf("凯利比例为负，不应开仓")
+	// 🔧 修复AI预测值的符号错误：做多 best>0/worst<0，做空 best<0/worst>0
+	best, worst, note := riskcalc.NormalizeCases(side, prediction.BestCase, prediction.WorstCase)
+	if note != "" {
+		log.Printf("🔧 %s %s", prediction.Symbol, note)
 	}
+	prediction.BestCase, prediction.WorstCase = best, worst
 
-	// 🔧 使用 1/4 凯利 - 保守策略，降低爆仓风险
-	// 全凯利在加密货币市场风险过高（胜率不稳定、黑天鹅事件）
-	// 1/4 凯利可以在保持正期望的同时大幅降低回撤
-	conservativeKelly := kellyFraction * 0.25
-
-	// 计算仓位大小（名义价值）
-	positionSize = totalEquity * conservativeKelly
-
-	// 硬约束：单币最多60%总资金
-	maxPositionSize := totalEquity * 0.6
-	if positionSize > maxPositionSize {
-		positionSize = maxPositionSize
-	}
-
-	// 🔧 计算杠杆（需要先计算杠杆，才能检查保证金）
-	isBTCETH := (prediction.Symbol == "BTCUSDT" || prediction.Symbol == "ETHUSDT")
 	baseLeverage := o.altcoinLeverage
-	if isBTCETH {
+	if prediction.Symbol == "BTCUSDT" || prediction.Symbol == "ETHUSDT" {
 		baseLeverage = o.btcEthLeverage
 	}
 
-	// 根据风险级别调整杠杆
-	switch prediction.RiskLevel {
-	case "low":
-		leverage = baseLeverage // 使用基础杠杆
-	case "medium":
-		leverage = int(float64(baseLeverage) * 0.8) // 降低20%
-	case "high":
-		leverage = int(float64(baseLeverage) * 0.6) // 降低40%
-	default:
-		leverage = int(float64(baseLeverage) * 0.8)
-	}
-
-	if leverage < 1 {
-		leverage = 1
-	}
-
-	// 最小仓位保护（Binance要求名义价值≥100 USDT）
-	if positionSize < 100 {
-		// 强制使用100 USDT最小名义价值
-		log.Printf("⚠️  [%s] 凯利仓位%.2f USDT过小，使用最小仓位100 USDT", prediction.Symbol, positionSize)
-		positionSize = 100
-	}
-
-	// 🔧 检查保证金是否足够（名义价值/杠杆 = 保证金）
-	requiredMargin := positionSize / float64(leverage)
-	if requiredMargin > availableBalance*0.9 {
-		// 保证金不足，降低仓位
-		oldPositionSize := positionSize
-		positionSize = availableBalance * 0.9 * float64(leverage)
-
-		// ✅ 关键修复：确保降低后的仓位不低于 100 USDT
-		if positionSize < 100 {
-			positionSize = 100
-			// 重新检查 100 USDT 仓位所需的保证金
-			requiredMargin = 100.0 / float64(leverage)
-			if requiredMargin > availableBalance {
-				return 0, 0, 0, 0, fmt.Errorf("账户资金不足: 可用%.2f USDT, %dx杠杆下最小仓位100 USDT需保证金%.2f USDT",
-					availableBalance, leverage, requiredMargin)
-			}
-			log.Printf("⚠️  [%s] 保证金不足，降低仓位至最小值: %.2f → 100 USDT (保证金%.2f → %.2f)",
-				prediction.Symbol, oldPositionSize,
-				oldPositionSize/float64(leverage), requiredMargin)
-		} else {
-			log.Printf("⚠️  [%s] 保证金不足，降低仓位: %.2f → %.2f USDT (保证金%.2f → %.2f)",
-				prediction.Symbol, oldPositionSize, positionSize,
-				oldPositionSize/float64(leverage), positionSize/float64(leverage))
+	sizing, err := riskcalc.SizeFromPrediction(riskcalc.SizingInput{
+		Side:             side,
+		Probability:      prediction.Probability,
+		BestCase:         prediction.BestCase,
+		WorstCase:        prediction.WorstCase,
+		RiskLevel:        prediction.RiskLevel,
+		Price:            marketData.CurrentPrice,
+		ATR:              marketData.LongerTermContext.ATR14,
+		TotalEquity:      totalEquity,
+		AvailableBalance: availableBalance,
+		BaseLeverage:     baseLeverage,
+		StopATR:          o.tuning.stopMultiple(),
+	}, riskcalc.DefaultLimits())
+	if sizing != nil {
+		for _, n := range sizing.Notes {
+			log.Printf("⚠️  [%s] %s", prediction.Symbol, n)
 		}
+		// 后续的组合风控和入场时机使用调整后的预测值
+		prediction.BestCase, prediction.WorstCase = sizing.BestCase, sizing.WorstCase
+		attr.ATRPct = sizing.ATRPct
+		attr.BestCase = sizing.BestCase
+		attr.WorstCase = sizing.WorstCase
+		attr.RiskReward = sizing.PayoffRatio
+		attr.KellyFraction = sizing.KellyFraction
+		attr.AppliedKelly = sizing.AppliedKelly
 	}
-
-	// 计算止损止盈（基于AI预测的最好/最坏情况）
-	currentPrice := marketData.CurrentPrice
-
-	if prediction.Direction == "up" {
-		// 做多
-		stopLoss = currentPrice * (1 + prediction.WorstCase/100.0)  // 最坏情况（价格下跌=止损）
-		takeProfit = currentPrice * (1 + prediction.BestCase/100.0) // 最好情况（价格上涨=止盈）
-	} else {
-		// 做空
-		// 🔧 修复后的值：best_case是负数（价格下跌=盈利=止盈），worst_case是正数（价格上涨=亏损=止损）
-		stopLoss = currentPrice * (1 + prediction.WorstCase/100.0)   // worst_case正数=价格上涨=止损
-		takeProfit = currentPrice * (1 + prediction.BestCase/100.0)  // best_case负数=价格下跌=止盈
-	}
-
-	// 验证止损在强平价范围内
-	marginRate := LiquidationMarginRate / float64(leverage)
-	var liquidationPrice float64
-
-	if prediction.Direction == "up" {
-		liquidationPrice = currentPrice * (1 - marginRate)
-		if stopLoss <= liquidationPrice {
-			// 止损价太低，调整杠杆
-			leverage = int(float64(leverage) * 0.7)
-			if leverage < 1 {
-				leverage = 1
-			}
-			// 重新计算强平价
-			marginRate = LiquidationMarginRate / float64(leverage)
-			liquidationPrice = currentPrice * (1 - marginRate)
-		}
-	} else {
-		liquidationPrice = currentPrice * (1 + marginRate)
-		if stopLoss >= liquidationPrice {
-			// 止损价太高，调整杠杆
-			leverage = int(float64(leverage) * 0.7)
-			if leverage < 1 {
-				leverage = 1
-			}
-			// 重新计算强平价
-			marginRate = LiquidationMarginRate / float64(leverage)
-			liquidationPrice = currentPrice * (1 + marginRate)
-		}
-	}
-
-	// 🔧 最终保证金检查（杠杆可能在止损验证时被调整）
-	finalMargin := positionSize / float64(leverage)
-	if finalMargin > availableBalance*0.9 {
-		return 0, 0, 0, 0, fmt.Errorf("调整杠杆后保证金不足: 需要%.2f USDT, 可用%.2f USDT (杠杆%dx)",
-			finalMargin, availableBalance, leverage)
+	if err != nil {
+		return 0, 0, 0, 0, err
 	}
 
-	return positionSize, leverage, stopLoss, takeProfit, nil
+	return sizing.PositionSize, sizing.Leverage, sizing.StopLoss, sizing.TakeProfit, nil
 }
 
 // validateRiskParameters 验证风控参数（预测模式的风控防线）
@@ -956,110 +957,28 @@ func (o *DecisionOrchestrator) validateRiskParameters(
 		return fmt.Errorf("市场数据不完整")
 	}
 
-	currentPrice := marketData.CurrentPrice
-	atr := marketData.LongerTermContext.ATR14
-	atrPct := (atr / currentPrice) * 100
-
-	// 1️⃣ 计算止损止盈的ATR倍数
-	var stopDistancePct, tpDistancePct float64
-	var stopMultiple, tpMultiple float64
-
-	// 预先声明验证所需的变量（避免goto跳过声明）
-	stopMin := MinStopMultiple * (1.0 - RRFloatTolerance) // 4.5 * 0.95 = 4.275
-	stopMax := MaxStopMultiple * (1.0 + RRFloatTolerance) // 25.0 * 1.05 = 26.25
-	tpMin := MinTPMultiple * (1.0 - RRFloatTolerance)     // 9.0 * 0.95 = 8.55
-	tpMax := MaxTPMultiple * (1.0 + RRFloatTolerance)     // 30.0 * 1.05 = 31.5
-
-	// 🔧 修复：direction参数是"up"/"down"，而不是"long"/"short"
-	if direction == "up" || direction == "long" {
-		stopDistancePct = (currentPrice - stopLoss) / currentPrice * 100
-		tpDistancePct = (takeProfit - currentPrice) / currentPrice * 100
-		stopMultiple = (currentPrice - stopLoss) / atr
-		tpMultiple = (takeProfit - currentPrice) / atr
-	} else {
-		stopDistancePct = (stopLoss - currentPrice) / currentPrice * 100
-		tpDistancePct = (currentPrice - takeProfit) / currentPrice * 100
-		stopMultiple = (stopLoss - currentPrice) / atr
-		tpMultiple = (currentPrice - takeProfit) / atr
-	}
-
-	// 🔧 低波动豁免机制：当ATR极低时（<0.5%），豁免倍数检查，只验证绝对值
-	// 原因：低波动市场(ATR=0.13%)时，5%止损会是38.5倍ATR，超过25倍上限导致无法开仓
-	//       但5%止损在低波动市场是合理的，应该允许
-	// 🔧 v2: 提高阈值从0.3%到0.5%，因为0.31%也是低波动市场
-	if atrPct < 0.5 {
-		// 低波动市场：放宽绝对值范围（允许更小的止损距离）
-		// 止损：0.8-10.0%（降低最小值从1.0%到0.8%，允许BTC当前0.95%止损通过）
-		// 止盈：1.6-20.0%（对应R/R≥2.0的要求）
-		if stopDistancePct < 0.8 || stopDistancePct > 10.0 {
-			return fmt.Errorf("低波动市场止损%.2f%%超出合理范围[0.8-10.0]%%（ATR仅%.2f%%，豁免倍数检查）",
-				stopDistancePct, atrPct)
-		}
-		if tpDistancePct < 1.6 || tpDistancePct > 20.0 {
-			return fmt.Errorf("低波动市场止盈%.2f%%超出合理范围[1.6-20.0]%%（ATR仅%.2f%%，豁免倍数检查）",
-				tpDistancePct, atrPct)
-		}
-		log.Printf("✅ [低波动豁免] ATR=%.2f%% < 0.5%%, 豁免倍数检查，止损%.2f%% 止盈%.2f%% 在绝对值合理范围内",
-			atrPct, stopDistancePct, tpDistancePct)
-		// 继续检查R/R比，跳过倍数检查
-		goto checkRiskReward
-	}
-
-	// 🚨 检查止损是否在ATR合理范围内 [4.5-25.0倍]（带浮点容差）
-	if stopMultiple < stopMin || stopMultiple > stopMax {
-		return fmt.Errorf("止损倍数%.2fx超出合理范围[%.1f-%.1f]ATR（止损%.2f%%, ATR%%=%.2f%%）",
-			stopMultiple, MinStopMultiple, MaxStopMultiple, stopDistancePct, atrPct)
-	}
-
-	// 🚨 检查止盈是否在ATR合理范围内 [9.0-30.0倍]（带浮点容差）
-	if tpMultiple < tpMin || tpMultiple > tpMax {
-		return fmt.Errorf("止盈倍数%.2fx超出合理范围[%.1f-%.1f]ATR（止盈%.2f%%, ATR%%=%.2f%%）",
-			tpMultiple, MinTPMultiple, MaxTPMultiple, tpDistancePct, atrPct)
+	// direction是"up"/"down"，统一为long/short后再校验（强平价安全距离按方向区分）
+	side := riskcalc.Side(direction)
+	if side == "" {
+		side = "short"
 	}
-
-checkRiskReward:
-	// 2️⃣ 计算R/R比（使用与riskAgent相同的逻辑）
-	riskReward := tpDistancePct / stopDistancePct
-
-	// 🚨 硬约束：R/R必须≥2.0（与传统模式一致）
-	minRR := MinRiskReward * (1.0 - RRFloatTolerance) // 2.0 * 0.95 = 1.90
-	if riskReward < minRR {
-		return fmt.Errorf("风险回报比%.2f:1 < %.1f:1要求（止损%.1fx, 止盈%.1fx, 差值%.2f）",
-			riskReward, MinRiskReward, stopMultiple, tpMultiple, MinRiskReward-riskReward)
+	check, err := riskcalc.CheckProtection(riskcalc.OpenOrder{
+		Side:       side,
+		Price:      marketData.CurrentPrice,
+		StopLoss:   stopLoss,
+		TakeProfit: takeProfit,
+		Leverage:   leverage,
+	}, marketData.LongerTermContext.ATR14, o.tuning.stopMultiple(), o.tuning.tpMultiple(), riskcalc.DefaultLimits())
+	if err != nil {
+		return err
 	}
 
-	// 3️⃣ 检查强平价安全距离（使用与riskAgent相同的标准）
-	marginRate := LiquidationMarginRate / float64(leverage)
-	var liquidationPrice float64
-	var safeStopLoss float64
-
-	if direction == "long" {
-		liquidationPrice = currentPrice * (1.0 - marginRate)
-		// 止损必须高于强平价 + 安全缓冲
-		safeStopLoss = liquidationPrice + (currentPrice-liquidationPrice)*LiquidationSafetyRatio
-
-		if stopLoss < safeStopLoss {
-			distanceToLiq := (stopLoss - liquidationPrice) / currentPrice * 100
-			safeDistance := (safeStopLoss - liquidationPrice) / currentPrice * 100
-			return fmt.Errorf("止损%.4f离强平价%.4f过近（实际%.2f%% < 安全要求%.2f%%）",
-				stopLoss, liquidationPrice, distanceToLiq, safeDistance)
-		}
-	} else { // short
-		liquidationPrice = currentPrice * (1.0 + marginRate)
-		// 止损必须低于强平价 - 安全缓冲
-		safeStopLoss = liquidationPrice - (liquidationPrice-currentPrice)*LiquidationSafetyRatio
-
-		if stopLoss > safeStopLoss {
-			distanceToLiq := (liquidationPrice - stopLoss) / currentPrice * 100
-			safeDistance := (liquidationPrice - safeStopLoss) / currentPrice * 100
-			return fmt.Errorf("止损%.4f离强平价%.4f过近（实际%.2f%% < 安全要求%.2f%%）",
-				stopLoss, liquidationPrice, distanceToLiq, safeDistance)
-		}
+	if check.LowVolExempt {
+		log.Printf("✅ [低波动豁免] ATR=%.2f%% < 0.5%%, 豁免倍数检查，止损%.2f%% 止盈%.2f%% 在绝对值合理范围内",
+			check.ATRPct, check.StopPct, check.TPPct)
 	}
-
-	// ✅ 所有检查通过
 	log.Printf("✅ [%s] 风控验证通过: 止损%.1fx ATR | 止盈%.1fx ATR | R/R=%.2f:1 | 强平价安全距离OK",
-		symbol, stopMultiple, tpMultiple, riskReward)
+		symbol, check.StopATR, check.TPATR, check.RiskReward)
 
 	return nil
 }
@@ -1279,5 +1198,3 @@ func calculateDynamicLimitPrice(
 
 	return limitPrice, pullbackPct
 }
-
-
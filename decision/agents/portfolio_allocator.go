@@ -0,0 +1,55 @@
+package agents
+
+import (
+	"math"
+	"nofx/decision/types"
+	"nofx/market"
+)
+
+// 组合仓位分配模式
+const (
+	AllocationSequential  = ""             // 逐个计算（默认）：先处理的币种可能占满保证金
+	AllocationRiskParity  = "risk_parity"  // 风险平价：保证金预算按止损距离的倒数分配，各仓位风险相等
+	AllocationEqualWeight = "equal_weight" // 等权：保证金预算平均分配
+)
+
+// allocationEntry 参与联合分配的开仓候选
+type allocationEntry struct {
+	symbol  string
+	riskPct float64 // 估算止损距离（%）
+}
+
+// entryRiskPct 估算开仓的止损距离：与 calculatePositionFromPrediction 一致，worst_case 不低于 max(0.5%, ATR% × 最小止损倍数)
+func entryRiskPct(prediction *types.Prediction, marketData *market.Data, stopMultiple float64) float64 {
+	riskPct := math.Abs(prediction.WorstCase)
+	if marketData != nil && marketData.CurrentPrice > 0 && marketData.LongerTermContext != nil {
+		atrPct := marketData.LongerTermContext.ATR14 / marketData.CurrentPrice * 100
+		riskPct = math.Max(riskPct, atrPct*stopMultiple)
+	}
+	return math.Max(riskPct, 0.5)
+}
+
+// allocateMarginBudgets 在开仓前联合分配可用保证金，返回每个候选的保证金上限（与entries顺序一致）
+// 风险平价：权重 ∝ 1/止损距离，使各仓位在止损时的亏损大致相等；等权：平均分配
+func allocateMarginBudgets(mode string, entries []allocationEntry, availableMargin float64) []float64 {
+	budgets := make([]float64, len(entries))
+	if len(entries) == 0 || availableMargin <= 0 {
+		return budgets
+	}
+
+	weights := make([]float64, len(entries))
+	totalWeight := 0.0
+	for i, e := range entries {
+		w := 1.0
+		if mode == AllocationRiskParity && e.riskPct > 0 {
+			w = 1 / e.riskPct
+		}
+		weights[i] = w
+		totalWeight += w
+	}
+
+	for i := range entries {
+		budgets[i] = availableMargin * weights[i] / totalWeight
+	}
+	return budgets
+}
@@ -0,0 +1,67 @@
+package agents
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 持仓上限默认值
+const (
+	DefaultMaxPositions    = 3 // 最多同时持有3个币种
+	DefaultMaxSidePosition = 1 // 同方向只持有一个币种
+)
+
+// PositionLimits 持仓数量与敞口上限（编排层筛选和执行层下单前共用）
+type PositionLimits struct {
+	MaxPositions        int     // 最大持仓数量（0=默认3）
+	MaxLongPositions    int     // 多仓币种数上限（0=默认1）
+	MaxShortPositions   int     // 空仓币种数上限（0=默认1）
+	MaxNotionalMultiple float64 // 总名义敞口上限（净值倍数，0=不限制）
+}
+
+// Total 最大持仓数量
+func (l PositionLimits) Total() int {
+	if l.MaxPositions > 0 {
+		return l.MaxPositions
+	}
+	return DefaultMaxPositions
+}
+
+// Side 单方向持仓数量上限
+func (l PositionLimits) Side(side string) int {
+	limit := l.MaxLongPositions
+	if side == "short" {
+		limit = l.MaxShortPositions
+	}
+	if limit > 0 {
+		return limit
+	}
+	return DefaultMaxSidePosition
+}
+
+// CheckSide 检查同方向持仓数量（existing 为同方向的其他币种）
+func (l PositionLimits) CheckSide(symbol, side string, existing []string) error {
+	limit := l.Side(side)
+	if len(existing) < limit {
+		return nil
+	}
+	directionName := "多仓"
+	if side == "short" {
+		directionName = "空仓"
+	}
+	return fmt.Errorf("❌ 同方向持仓已达上限（%d/%d）：已有%s%s，拒绝开%s%s。如需换仓，请先平掉其中一个",
+		len(existing), limit, strings.Join(existing, "、"), directionName, symbol, directionName)
+}
+
+// CheckNotional 检查开仓后总名义敞口是否超过净值倍数上限
+func (l PositionLimits) CheckNotional(currentNotional, newNotional, equity float64) error {
+	if l.MaxNotionalMultiple <= 0 || equity <= 0 {
+		return nil
+	}
+	maxNotional := equity * l.MaxNotionalMultiple
+	if currentNotional+newNotional > maxNotional {
+		return fmt.Errorf("总名义敞口超限：现有%.2f + 新仓位%.2f > %.1f倍净值（%.2f）",
+			currentNotional, newNotional, l.MaxNotionalMultiple, maxNotional)
+	}
+	return nil
+}
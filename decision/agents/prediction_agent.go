@@ -1,11 +1,15 @@
 package agents
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"math"
+	"nofx/decision/prompts"
+	"nofx/decision/schema"
 	"nofx/decision/types"
+	"nofx/errs"
 	"nofx/market"
 	"nofx/mcp"
 	"strings"
@@ -16,6 +20,7 @@ import (
 // 负责基于市场情报预测未来价格走势
 type PredictionAgent struct {
 	mcpClient *mcp.Client
+	ensemble  *Ensemble // 🗳️ 多模型集成预测（nil=单模型）
 }
 
 // NewPredictionAgent 创建预测Agent
@@ -32,21 +37,31 @@ type PredictionContext struct {
 	ExtendedData   *market.ExtendedData         // 🆕 扩展市场数据（情绪/清算/OI变化）
 	HistoricalPerf *types.HistoricalPerformance // 历史预测表现
 	SharpeRatio    float64                      // 系统近期夏普（用于概率校准）
+	PerfSummary    string                       // 📐 近期绩效摘要（索提诺/期望/R倍数/连胜连亏）
 	Account        *AccountInfo                 // 账户上下文
 	Positions      []PositionInfoInput          // 当前持仓列表
 	RecentFeedback string                       // tracker生成的近期反馈
 	TraderMemory   string                       // 🧠 交易员记忆（实际交易经验）
+	AltcoinSignal  string                       // 🚨 山寨币异动信号摘要（候选来自异动扫描时）
+	TradeIdea      string                       // 📥 外部交易想法摘要（候选来自webhook时）
+	UserPriority   bool                         // ⭐ 用户置顶关注的币种
+	RiskGate       AccountRiskGate              // 🏦 按账户级别的累计亏损风控阈值
 }
 
 // Predict 预测币种未来走势
-func (agent *PredictionAgent) Predict(ctx *PredictionContext) (*types.Prediction, error) {
+func (agent *PredictionAgent) Predict(callCtx context.Context, ctx *PredictionContext) (*types.Prediction, error) {
+	return agent.predictWith(callCtx, agent.mcpClient, ctx)
+}
+
+// predictWith 使用指定模型预测
+func (agent *PredictionAgent) predictWith(callCtx context.Context, client *mcp.Client, ctx *PredictionContext) (*types.Prediction, error) {
 	if err := agent.validateMarketData(ctx); err != nil {
 		return nil, fmt.Errorf("数据验证失败: %w", err)
 	}
 
 	systemPrompt, userPrompt := agent.buildPredictionPrompt(ctx)
 
-	response, err := agent.mcpClient.CallWithMessages(systemPrompt, userPrompt)
+	response, err := client.CallWithContext(callCtx, systemPrompt, userPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("AI调用失败: %w", err)
 	}
@@ -58,13 +73,22 @@ func (agent *PredictionAgent) Predict(ctx *PredictionContext) (*types.Prediction
 		// 打印原始响应以调试DeepSeek R1
 		log.Printf("⚠️  无法提取JSON，原始响应前800字符:\n%s", truncateString(response, 800))
 		log.Printf("⚠️  原始响应长度: %d字符", len(response))
-		return nil, fmt.Errorf("无法从响应中提取JSON")
+		return nil, errs.New(errs.AIParseError, "无法从响应中提取JSON")
 	}
 
 	log.Printf("🔍 AI原始预测JSON: %s", jsonData)
 
+	// 🔧 按JSON Schema校验结构，失败时把校验错误发回模型修复一次
+	if verr := schema.Prediction.Validate(jsonData); verr != nil {
+		repaired, err := schema.Repair(callCtx, client, schema.Prediction, jsonData, verr, extractJSON)
+		if err != nil {
+			return nil, fmt.Errorf("预测JSON校验失败: %w", err)
+		}
+		jsonData = repaired
+	}
+
 	if err := json.Unmarshal([]byte(jsonData), prediction); err != nil {
-		return nil, fmt.Errorf("JSON解析失败: %w\nJSON: %s", err, jsonData)
+		return nil, errs.Wrap(errs.AIParseError, 0, fmt.Errorf("JSON解析失败: %w\nJSON: %s", err, jsonData))
 	}
 
 	normalizePrediction(prediction)
@@ -84,19 +108,30 @@ func (agent *PredictionAgent) Predict(ctx *PredictionContext) (*types.Prediction
 	return prediction, nil
 }
 
-// PredictWithRetry 对AI预测增加重试机制，提高稳定性
-func (agent *PredictionAgent) PredictWithRetry(ctx *PredictionContext, maxRetries int) (*types.Prediction, error) {
+// PredictWithRetry 对AI预测增加重试机制，提高稳定性（callCtx到期时不再重试）
+func (agent *PredictionAgent) PredictWithRetry(callCtx context.Context, ctx *PredictionContext, maxRetries int) (*types.Prediction, error) {
+	if agent.ensemble.enabled() {
+		return agent.predictEnsemble(callCtx, ctx, maxRetries)
+	}
+	return agent.predictWithRetry(callCtx, agent.mcpClient, ctx, maxRetries)
+}
+
+// predictWithRetry 使用指定模型预测（带重试）
+func (agent *PredictionAgent) predictWithRetry(callCtx context.Context, client *mcp.Client, ctx *PredictionContext, maxRetries int) (*types.Prediction, error) {
 	if maxRetries <= 0 {
 		maxRetries = 1
 	}
 	var lastErr error
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		prediction, err := agent.Predict(ctx)
+		prediction, err := agent.predictWith(callCtx, client, ctx)
 		if err == nil {
 			return prediction, nil
 		}
 		lastErr = err
 		log.Printf("⚠️  AI预测失败(第%d次尝试/%d): %v", attempt, maxRetries, err)
+		if callCtx.Err() != nil {
+			return nil, fmt.Errorf("AI预测已中止: %w", callCtx.Err())
+		}
 		if attempt < maxRetries {
 			time.Sleep(time.Duration(attempt) * time.Second)
 		}
@@ -163,137 +198,8 @@ func (agent *PredictionAgent) buildPredictionPrompt(ctx *PredictionContext) (sys
 	// 🆕 动态生成"最近错误教训"（基于实际表现）
 	mistakesSection := agent.buildMistakesSection(ctx)
 
-	systemPrompt = `你是一名专业的加密货币量化预测员，专为 BTC/ETH 预测短期走势（1h/4h/24h）。必须综合考虑【账户风险+持仓情况+技术指标】做出决策，并严格输出 JSON。
-
-🌟 **心态指引**：
-- 这是小资金测试账户，用于优化策略和积累经验
-- 不要因历史亏损而过度悲观或恐惧，每次决策都是独立的
-- 专注当前市场信号和机会，而非过度纠结过往失误
-- 满足风控阈值且信号明确时，应果断行动而非观望
-
-=====================
-【0. 🎯 综合决策框架（核心优先级）】
-
-⚠️ **决策优先级**（从高到低）：
-1. 账户风险控制（累计盈亏、保证金占用）
-2. 持仓状态分析（盈亏、持仓时长、方向）
-3. 技术指标验证（趋势、动量、超买超卖）
-4. 市场情绪参考（资金费率、OI变化、情绪指数）
-
-✅ **必须遵守的决策逻辑**：
-- 🎯 **风控阈值**：系统会在输入数据中明确告诉你"当前风控阈值"，你**必须严格遵守**，不得擅自修改或使用其他数值
-- 🛑 账户风险红线：当系统告知禁止开仓时，必须输出neutral（prob=0.50-0.55）
-- 🔒 持仓已满(3/3) → 新机会概率必须 > 0.80 才考虑替换
-- 🛑 保证金占用 > 60% → 严禁新开仓，倾向neutral
-- ⚠️ 保证金占用 > 40% → 降低预期收益(expected_move ≤ 2%)
-- ✅ 持仓有大幅盈利(>5%) → 考虑建议部分止盈（在reasoning中提示）
-- ⚠️ 单个持仓亏损 > 5% → 考虑止损（在reasoning中提示）
-
-📊 **持仓方向冲突处理**：
-- 已有多单且预测down → 如盈利>3%建议平仓，否则neutral观望
-- 已有空单且预测up → 如盈利>3%建议平仓，否则neutral观望
-- 持仓时长<4小时且盈亏不极端 → 倾向neutral继续持有
-
-=====================
-【1. 最近错误教训（自动注入）】
-` + mistakesSection + `
-
-=====================
-【2. 技术分析原则（次要逻辑）】
-- 技术指标权重：EMA/MACD/RSI/ADX = 50%（降低权重）
-- 账户风险权重：持仓盈亏/保证金/风险等级 = 30%（新增）
-- 情绪/资金费率/社交等占 20%
-- 2~3 个关键指标一致 + 账户风险可控 → 输出 up/down（0.65–0.75）
-- 信号轻微冲突或账户有风险 → 选neutral或降低概率到0.50-0.60
-- 严格避免追涨/杀跌（BTC/ETH 专用规则见下方）
-
-=====================
-【3. 硬禁止规则（BTC/ETH 专用，触发即 neutral & prob=0.50）】
-
-【做多禁止】
-- RSI7 > 75 或 RSI14 > 75              # 过度超买 → 禁止追涨（与Entry Engine统一）
-- 1h涨幅 > 4% 或 价格 > EMA20 + 3%     # 大阳线 + 偏离均线（BTC/ETH实际波动调整）
-- atr% > 3.5 且 1h涨幅 > 3%             # 高波动+大单边拉升（降低阈值）
-- -DI > +DI * 1.5                        # 空头力量明显占优（≥50%）
-- ADX>25 且 p<EMA50 且 -DI>+DI           # 强下跌趋势中禁止抄底
-
-【做空禁止】
-- RSI7 < 35 或 RSI14 < 35              # 接近超卖 → 禁止杀跌（与Entry Engine统一）
-- 1h跌幅 < -3% 且 价格 < EMA20 - 2%    # 大阴线 + 跌破均线（BTC/ETH实际波动调整）
-- atr% > 3.5 且 1h跌幅 < -3%            # 高波动+大单边下跌（降低阈值）
-- +DI > -DI * 1.5                        # 多头力量明显占优（≥50%）
-- ADX>25 且 p>EMA50 且 +DI>-DI           # 强上涨趋势中禁止抄底做空
-
-=====================
-【4. 警告信号（限幅处理，适配 BTC/ETH）】
-触发任意一条 → probability ≤ 0.65，expected_move ≤ ±2%：
-【做多警告】
-- RSI7 > 70 或 RSI14 > 68
-- 1h涨幅 > 2%                            # 降低阈值以匹配实际波动
-- p > EMA20 + 1.5%                       # 降低阈值以匹配实际波动
-
-【做空警告】
-- RSI7 < 35 或 RSI14 < 35
-- 1h跌幅 < -2%                           # 降低阈值以匹配实际波动
-- p < EMA20 - 1.5%                       # 降低阈值以匹配实际波动
-
-同时触发 ≥2 条 → 倾向 neutral 或 probability=0.58~0.62
-
-=====================
-【5. 趋势结构（核心趋势判断）】
-- 上升趋势：p>EMA20>EMA50 且 MACD>0 → UP（0.65~0.75）
-- 下跌趋势：p<EMA20<EMA50 且 MACD<0 → DOWN（0.65~0.75）
-- 横盘：ADX<20 → neutral 或偏向最强方（prob<0.62）
-
-MACD：
-- m>ms 且上升 → 金叉 → 看涨信号
-- m<ms 且下降 → 死叉 → 看跌信号
-
-ADX：
-- ADX<20 → 震荡（不可信趋势）
-- ADX>25 + 金叉 → 高质量趋势信号
-- ADX下降 → 趋势疲软 → expected_move 应缩小
-
-=====================
-【6. 历史经验（交易记忆必须使用）】
-推理必须包含：
-- 当前账户风险状态（盈亏、保证金、持仓数量）
-- 持仓情况对新决策的影响（方向冲突、盈亏状态）
-- 当前市场是否类似过去盈利模式（提高概率）
-- 是否接近过往亏损模式（降低概率）
-- 如出现强烈相似 → 调整 probability ±0.03
-
-⚠️ **推理格式要求**：
-第1句：说明账户风险状态（如：账户浮亏-3.2%，风险偏高）
-第2-3句：技术分析（趋势、指标、信号）
-第4句：综合账户+技术的最终判断
-
-🚫 **推理文本禁止事项**：
-- 禁止在reasoning中写"需概率≥XX%"这样的具体数字
-- 如需提到风控，使用"需满足风控阈值"或"风控要求较高"等通用说法
-- 系统会自动验证概率是否满足阈值，你无需在reasoning中重复
-
-=====================
-【7. 概率 / 置信度规则】
-- probability 范围：0.50–1.00
-- neutral: 0.50–0.58
-- up/down ≥ 0.58
-- expected_move：±10% 以内
-- confidence：high / medium / low
-- timeframe：1h / 4h / 24h
-
-若模型逻辑冲突 → 以"硬禁止"优先级最高，其次"趋势结构"，再次"警告信号"。
-
-=====================
-【8. 严格 JSON 输出（必须符合结构）】
-仅输出以下 JSON，不要解释，不要多余文本：
-{"symbol":"SYMBOL","direction":"up|down|neutral","probability":0.65,"expected_move":2.5,"timeframe":"1h|4h|24h","confidence":"high|medium|low","reasoning":"中文推理<150字","key_factors":["因素1","因素2","因素3"],"risk_level":"high|medium|low","worst_case":-1.5,"best_case":3.5}
-
-数据字段说明:
-- p:价格 | 1h/4h/24h:涨跌幅% | r7/r14:RSI指标
-- m:MACD值 | ms:MACD信号线 | e20/e50:EMA均线 | atr%:波动率百分比
-- adx:趋势强度 | +di/-di:多空力量 | vol24h:24h成交额(百万USDT)
-- f:资金费率 | oiΔ4h/24h:持仓量变化% | fgi:恐慌贪婪指数 | social:社交情绪`
+	// 📝 系统提示词模板见 decision/prompts/prediction_system.tmpl（可通过 prompt_dir 覆盖）
+	systemPrompt = prompts.Render(prompts.PredictionSystem, prompts.PredictionData{Mistakes: mistakesSection})
 
 	return systemPrompt, agent.buildUserPrompt(ctx)
 }
@@ -328,7 +234,7 @@ func (agent *PredictionAgent) buildUserPrompt(ctx *PredictionContext) string {
 		compactData["p"] = md.CurrentPrice
 		compactData["1h"] = md.PriceChange1h
 		compactData["4h"] = md.PriceChange4h
-		compactData["r7"] = md.CurrentRSI7   // 改名区分
+		compactData["r7"] = md.CurrentRSI7 // 改名区分
 		compactData["m"] = md.CurrentMACD
 		compactData["f"] = md.FundingRate
 
@@ -345,9 +251,9 @@ func (agent *PredictionAgent) buildUserPrompt(ctx *PredictionContext) string {
 		}
 
 		// === 方案A维度（+40 tokens）===
-		compactData["24h"] = md.PriceChange24h  // 🆕 24h涨跌幅
-		compactData["r14"] = md.CurrentRSI14    // 🆕 RSI14
-		compactData["ms"] = md.MACDSignal       // 🆕 MACD Signal线
+		compactData["24h"] = md.PriceChange24h // 🆕 24h涨跌幅
+		compactData["r14"] = md.CurrentRSI14   // 🆕 RSI14
+		compactData["ms"] = md.MACDSignal      // 🆕 MACD Signal线
 		if md.Volume24h > 0 {
 			compactData["vol24h"] = md.Volume24h / 1e6 // 🆕 24h成交额(M USDT)
 		}
@@ -365,16 +271,45 @@ func (agent *PredictionAgent) buildUserPrompt(ctx *PredictionContext) string {
 			ltc := md.LongerTermContext
 			compactData["atr14"] = ltc.ATR14 // 🆕 ATR14绝对值（止损距离参考）
 
-			// 🆕 OI变化率（从ExtendedData获取）
+			// 🆕 OI变化率（market.Data来自openInterestHist，缺失时回退ExtendedData）
+			oi4h, oi24h := md.OIChange4h, md.OIChange24h
 			if ctx.ExtendedData != nil && ctx.ExtendedData.Derivatives != nil {
 				d := ctx.ExtendedData.Derivatives
-				if d.OIChange4h != 0 {
-					compactData["oiΔ4h"] = d.OIChange4h
+				if oi4h == 0 {
+					oi4h = d.OIChange4h
 				}
-				if d.OIChange24h != 0 {
-					compactData["oiΔ24h"] = d.OIChange24h
+				if oi24h == 0 {
+					oi24h = d.OIChange24h
 				}
 			}
+			if md.OIChange1h != 0 {
+				compactData["oiΔ1h"] = md.OIChange1h
+			}
+			if oi4h != 0 {
+				compactData["oiΔ4h"] = oi4h
+			}
+			if oi24h != 0 {
+				compactData["oiΔ24h"] = oi24h
+			}
+			if md.OIPriceSignal != "" {
+				compactData["oi_px"] = md.OIPriceSignal // 🆕 4h价格与OI关系
+			}
+		}
+
+		// 🆕 多空持仓结构（拥挤度）
+		if pos := md.Positioning; pos != nil {
+			if pos.GlobalLongShortRatio > 0 {
+				compactData["ls"] = pos.GlobalLongShortRatio
+			}
+			if pos.TopLongShortRatio > 0 {
+				compactData["top_ls"] = pos.TopLongShortRatio
+			}
+			if pos.TakerBuySellRatio > 0 {
+				compactData["taker"] = pos.TakerBuySellRatio
+			}
+			if pos.Crowding != market.Balanced {
+				compactData["crowd"] = pos.Crowding
+			}
 		}
 
 		// === 方案C维度（+50 tokens）===
@@ -462,6 +397,9 @@ func (agent *PredictionAgent) buildUserPrompt(ctx *PredictionContext) string {
 			sb.WriteString(fmt.Sprintf("夏普比率: %.2f", ctx.SharpeRatio))
 		}
 		sb.WriteString("\n")
+		if ctx.PerfSummary != "" {
+			sb.WriteString(fmt.Sprintf("近期绩效: %s\n", ctx.PerfSummary))
+		}
 
 		// 4️⃣ 持仓详情（如果有）
 		if len(ctx.Positions) > 0 {
@@ -514,24 +452,9 @@ func (agent *PredictionAgent) buildUserPrompt(ctx *PredictionContext) string {
 
 		// 5️⃣ 账户风控提示（基于账户总体盈亏）- 🔧 修复：移到if-else外部，确保无论是否有持仓都显示
 		// 🎯 首先，明确显示当前所需的最低概率阈值
-		var requiredMinProb float64
-		var riskStatus string
-		if accountTotalPnLPct < -20 {
-			requiredMinProb = 1.01 // 禁止开仓
-			riskStatus = "🛑 严格禁止"
-		} else if accountTotalPnLPct < -15 {
-			requiredMinProb = 0.75 // 降低阈值，给AI更多机会
-			riskStatus = "⚠️ 谨慎交易"
-		} else if accountTotalPnLPct < -10 {
-			requiredMinProb = 0.70
-			riskStatus = "💡 适度谨慎"
-		} else if accountTotalPnLPct < -5 {
-			requiredMinProb = 0.68
-			riskStatus = "✅ 正常偏谨慎"
-		} else {
-			requiredMinProb = 0.65
-			riskStatus = "✅ 正常"
-		}
+		// 🏦 阈值按账户级别缩放（默认亏损5%/10%/15%/20%逐级收紧）
+		gate := ctx.RiskGate
+		requiredMinProb, riskStatus := gate.MinProbability(accountTotalPnLPct, 0.65)
 
 		// 🐛 调试日志：输出实际的亏损百分比和计算出的阈值
 		log.Printf("🔍 [风控阈值调试] 币种:%s 账户累计亏损:%.2f%% 计算阈值:%.0f%% 状态:%s",
@@ -549,7 +472,11 @@ func (agent *PredictionAgent) buildUserPrompt(ctx *PredictionContext) string {
 
 			// 🌟 添加积极提示
 			sb.WriteString("💡 **重要提醒**：\n")
-			sb.WriteString("- 这是**小资金测试账户**，目的是优化策略和积累经验\n")
+			if gate.Tier != "" {
+				sb.WriteString(fmt.Sprintf("- 当前账户级别：**%s**（净值 %.0f USDT），风控阈值已按该级别设置\n", gate.Tier, ctx.Account.TotalEquity))
+			} else {
+				sb.WriteString("- 这是**小资金测试账户**，目的是优化策略和积累经验\n")
+			}
 			sb.WriteString("- 不要因历史亏损而过度悲观，每次决策都是独立的新机会\n")
 			sb.WriteString("- 关注**当前技术信号**和市场机会，而非过度纠结历史表现\n")
 			sb.WriteString("- 符合概率阈值且技术信号明确时，应该**果断行动**而非观望\n")
@@ -559,18 +486,18 @@ func (agent *PredictionAgent) buildUserPrompt(ctx *PredictionContext) string {
 
 		// 🔧 根据账户总体盈亏给出强制约束（不是持仓浮动盈亏）
 		// 💡 使用前面计算的动态阈值，避免与实际风控不一致
-		if accountTotalPnLPct < -20 {
-			sb.WriteString("- 🛑 账户累计亏损 > 20%，**严格禁止**新开仓，必须输出neutral（概率0.50-0.55）\n")
+		if accountTotalPnLPct < -gate.MaxLoss() {
+			sb.WriteString(fmt.Sprintf("- 🛑 账户累计亏损 > %.0f%%，**严格禁止**新开仓，必须输出neutral（概率0.50-0.55）\n", gate.MaxLoss()))
 			sb.WriteString("- 立即减仓或止损，保护剩余资金\n")
-		} else if accountTotalPnLPct < -15 {
-			sb.WriteString(fmt.Sprintf("- ⚠️ 账户累计亏损15-20%%，新开仓概率必须 ≥ %.0f%%\n", requiredMinProb*100))
+		} else if accountTotalPnLPct < -gate.Step(3) {
+			sb.WriteString(fmt.Sprintf("- ⚠️ 账户累计亏损%.0f-%.0f%%，新开仓概率必须 ≥ %.0f%%\n", gate.Step(3), gate.MaxLoss(), requiredMinProb*100))
 			sb.WriteString("- 优先考虑与现有持仓风险对冲的方向\n")
 			sb.WriteString("- 检查亏损持仓是否需要止损\n")
-		} else if accountTotalPnLPct < -10 {
-			sb.WriteString(fmt.Sprintf("- 💡 账户累计亏损10-15%%，新开仓概率必须 ≥ %.0f%%\n", requiredMinProb*100))
+		} else if accountTotalPnLPct < -gate.Step(2) {
+			sb.WriteString(fmt.Sprintf("- 💡 账户累计亏损%.0f-%.0f%%，新开仓概率必须 ≥ %.0f%%\n", gate.Step(2), gate.Step(3), requiredMinProb*100))
 			sb.WriteString("- 检查亏损持仓是否需要调整或止损\n")
-		} else if accountTotalPnLPct < -5 {
-			sb.WriteString(fmt.Sprintf("- ✅ 账户累计亏损5-10%%，新开仓概率建议 ≥ %.0f%%\n", requiredMinProb*100))
+		} else if accountTotalPnLPct < -gate.Step(1) {
+			sb.WriteString(fmt.Sprintf("- ✅ 账户累计亏损%.0f-%.0f%%，新开仓概率建议 ≥ %.0f%%\n", gate.Step(1), gate.Step(2), requiredMinProb*100))
 		} else if accountTotalPnLPct > 10 {
 			sb.WriteString("- ✅ 账户盈利 > 10%，可考虑部分止盈锁定利润\n")
 			sb.WriteString("- 检查盈利持仓是否达到移动止损条件\n")
@@ -586,7 +513,6 @@ func (agent *PredictionAgent) buildUserPrompt(ctx *PredictionContext) string {
 		sb.WriteString("\n")
 	}
 
-
 	if ctx != nil && ctx.HistoricalPerf != nil && ctx.HistoricalPerf.OverallWinRate > 0 {
 		perf := ctx.HistoricalPerf
 		sb.WriteString(fmt.Sprintf("\n# 历史表现\n胜率:%.0f%% 准确率:%.0f%%",
@@ -615,6 +541,27 @@ func (agent *PredictionAgent) buildUserPrompt(ctx *PredictionContext) string {
 		log.Printf("⚠️  [DEBUG] TraderMemory为空！ctx=%v, TraderMemory长度=%d", ctx != nil, len(ctx.TraderMemory))
 	}
 
+	// 🚨 山寨币异动扫描信号（量价/OI异动、现货领先期货）
+	if ctx != nil && ctx.AltcoinSignal != "" {
+		sb.WriteString("\n# 🚨 异动信号\n")
+		sb.WriteString(ctx.AltcoinSignal)
+		sb.WriteString("\n⚠️ 异动信号只是线索: 需结合K线与资金流确认，已大幅拉升的不要追高\n")
+	}
+
+	// 📥 外部系统推送的交易想法（TradingView警报、自定义扫描器）
+	if ctx != nil && ctx.TradeIdea != "" {
+		sb.WriteString("\n# 📥 外部交易想法\n")
+		sb.WriteString(ctx.TradeIdea)
+		sb.WriteString("\n⚠️ 外部想法未经验证: 只有K线、趋势与资金流独立确认同一方向时才采纳，否则给出neutral或相反判断；建议的止损止盈仅供参考\n")
+	}
+
+	// ⭐ 用户关注币种
+	if ctx != nil && ctx.UserPriority {
+		sb.WriteString("\n# ⭐ 用户优先关注\n")
+		sb.WriteString("该币种由用户置顶关注（不论排名每个周期都会分析），请给出完整细致的分析。\n")
+		sb.WriteString("⚠️ 用户关注不代表看多或看空: 信号不充分时照常给出neutral，不要为迎合用户而降低标准\n")
+	}
+
 	sb.WriteString("\n# 开始预测\n")
 	return sb.String()
 }
@@ -862,14 +809,14 @@ func (agent *PredictionAgent) selectTimeframe(md *market.Data) string {
 
 	// 🔧 调整阈值，增加1h和24h的使用
 	switch {
-	case atrPct > 4.0:  // 原来是3.0，提高阈值
-		return "1h"     // 极高波动用1h（快速反应）
-	case atrPct > 2.0:  // 新增中等波动区间
-		return "4h"     // 中高波动用4h
-	case atrPct < 0.8:  // 原来是1.0，降低阈值
-		return "24h"    // 极低波动用24h（等待变盘）
+	case atrPct > 4.0: // 原来是3.0，提高阈值
+		return "1h" // 极高波动用1h（快速反应）
+	case atrPct > 2.0: // 新增中等波动区间
+		return "4h" // 中高波动用4h
+	case atrPct < 0.8: // 原来是1.0，降低阈值
+		return "24h" // 极低波动用24h（等待变盘）
 	default:
-		return "4h"     // 默认4h
+		return "4h" // 默认4h
 	}
 }
 
@@ -918,7 +865,7 @@ func (agent *PredictionAgent) validatePredictionEnhanced(pred *types.Prediction,
 	return nil
 }
 
-// truncateString 截断字符串到指定长度  
+// truncateString 截断字符串到指定长度
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -0,0 +1,38 @@
+package agents
+
+// ScanCadence 按币种类别控制AI预测频率（N=每N个周期预测一次，0/1=每个周期）
+// 已持仓币种不受影响（持仓管理每个周期都执行），带异动信号的山寨币和用户关注币种也不跳过
+type ScanCadence struct {
+	MajorEvery int // 主流币（BTC/ETH）
+	AltEvery   int // 山寨币
+}
+
+// isMajorSymbol 是否主流币（BTC/ETH）
+func isMajorSymbol(symbol string) bool {
+	return symbol == "BTCUSDT" || symbol == "ETHUSDT"
+}
+
+// Enabled 是否配置了降频
+func (c ScanCadence) Enabled() bool {
+	return c.MajorEvery > 1 || c.AltEvery > 1
+}
+
+// due 候选币种在第cycle个周期是否需要AI预测
+func (c ScanCadence) due(coin CandidateCoin, cycle int) bool {
+	every := c.AltEvery
+	if isMajorSymbol(coin.Symbol) {
+		every = c.MajorEvery
+	} else if coin.Signal != "" {
+		return true // 🚨 异动信号有时效性，不等待
+	}
+	if coin.UserPriority() {
+		return true // ⭐ 用户关注币种每个周期都分析
+	}
+	if coin.Idea != "" {
+		return true // 📥 外部交易想法只注入一个周期
+	}
+	if every <= 1 {
+		return true
+	}
+	return cycle%every == 0
+}
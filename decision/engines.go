@@ -0,0 +1,48 @@
+package decision
+
+import (
+	"fmt"
+	"nofx/mcp"
+)
+
+// 决策引擎
+const (
+	EngineMultiAgent = "multi_agent" // 预测驱动的Multi-Agent引擎（默认）
+	EngineMonolithic = "monolithic"  // 旧版单一prompt引擎
+)
+
+// ValidEngine 是否为支持的决策引擎名称（空=默认引擎）
+func ValidEngine(engine string) bool {
+	switch engine {
+	case "", EngineMultiAgent, EngineMonolithic:
+		return true
+	}
+	return false
+}
+
+// GetFullDecisionWithEngine 获取市场数据后用指定引擎决策（空=Multi-Agent）
+func GetFullDecisionWithEngine(engine string, ctx *Context, mcpClient *mcp.Client) (*FullDecision, error) {
+	if err := FetchMarketData(ctx); err != nil {
+		return nil, err
+	}
+	return DecideWithEngine(engine, ctx, mcpClient)
+}
+
+// FetchMarketData 为持仓和候选币种并发获取市场数据（调用方可与其他准备工作并发，再用DecideWithEngine决策）
+func FetchMarketData(ctx *Context) error {
+	if err := fetchMarketDataForContext(ctx); err != nil {
+		return fmt.Errorf("获取市场数据失败: %w", err)
+	}
+	return nil
+}
+
+// DecideWithEngine 复用ctx中已获取的市场数据，用指定引擎决策（影子对比时保证两个引擎看到相同的数据）
+func DecideWithEngine(engine string, ctx *Context, mcpClient *mcp.Client) (*FullDecision, error) {
+	switch engine {
+	case "", EngineMultiAgent:
+		return decideMultiAgent(ctx, mcpClient)
+	case EngineMonolithic:
+		return decideMonolithic(ctx, mcpClient)
+	}
+	return nil, fmt.Errorf("不支持的决策引擎: %s", engine)
+}
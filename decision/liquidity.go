@@ -0,0 +1,80 @@
+package decision
+
+import (
+	"fmt"
+	"nofx/decision/types"
+	"nofx/market"
+)
+
+// 流动性过滤方式
+const (
+	LiquidityFilterOI     = "oi"     // 持仓价值 = 持仓量 × 当前价格（默认）
+	LiquidityFilterVolume = "volume" // 24小时成交额
+	LiquidityFilterNone   = "none"   // 不过滤
+)
+
+// 默认阈值（USD）
+const (
+	DefaultLiquidityMinOIUSD     = 15_000_000
+	DefaultLiquidityMinVolumeUSD = 20_000_000
+)
+
+// LiquidityFilter 💧 候选币种流动性过滤（现有持仓不受影响，需要决策是否平仓）
+type LiquidityFilter struct {
+	Mode   string  // oi/volume/none（空=oi）
+	MinUSD float64 // 阈值（0=按方式使用默认值）
+}
+
+func (f LiquidityFilter) mode() string {
+	if f.Mode == "" {
+		return LiquidityFilterOI
+	}
+	return f.Mode
+}
+
+func (f LiquidityFilter) minUSD() float64 {
+	if f.MinUSD > 0 {
+		return f.MinUSD
+	}
+	if f.mode() == LiquidityFilterVolume {
+		return DefaultLiquidityMinVolumeUSD
+	}
+	return DefaultLiquidityMinOIUSD
+}
+
+// Check 检查币种流动性，不满足阈值时返回过滤结果（数据缺失时不过滤）
+func (f LiquidityFilter) Check(symbol string, data *market.Data) *types.FilteredSymbol {
+	if data == nil || data.CurrentPrice <= 0 {
+		return nil
+	}
+
+	var value float64
+	switch f.mode() {
+	case LiquidityFilterOI:
+		if data.OpenInterest == nil || data.OpenInterest.Latest <= 0 {
+			return nil
+		}
+		value = data.OpenInterest.Latest * data.CurrentPrice
+	case LiquidityFilterVolume:
+		if data.Volume24h <= 0 {
+			return nil // K线不足24小时，无法判断
+		}
+		value = data.Volume24h
+	default:
+		return nil
+	}
+
+	if minUSD := f.minUSD(); value < minUSD {
+		return &types.FilteredSymbol{Symbol: symbol, Filter: f.mode(), ValueUSD: value, MinUSD: minUSD}
+	}
+	return nil
+}
+
+// describeFiltered 过滤原因（日志用）
+func describeFiltered(fs *types.FilteredSymbol) string {
+	label := "持仓价值"
+	if fs.Filter == LiquidityFilterVolume {
+		label = "24h成交额"
+	}
+	return fmt.Sprintf("%s过低(%.2fM USD < %.2fM)", label, fs.ValueUSD/1_000_000, fs.MinUSD/1_000_000)
+}
@@ -0,0 +1,199 @@
+// Package prompts AI系统提示词模板（text/template）
+// 默认模板打包进二进制；SetDir 指定目录后优先使用目录中的同名 .tmpl 文件（修改后下次渲染自动生效，无需重新编译），
+// Version 返回当前生效模板内容的版本号，写入每条决策记录，用于把交易结果与提示词变更对应起来
+package prompts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// 模板名称（对应 <name>.tmpl）
+const (
+	PredictionSystem         = "prediction_system"          // 预测Agent系统提示词（变量: .Mistakes）
+	MarketIntelligenceSystem = "market_intelligence_system" // 市场情报Agent系统提示词
+	MonolithicSystem         = "monolithic_system"          // 单一prompt引擎系统提示词（变量见 MonolithicData）
+	SelfCritiqueSystem       = "self_critique_system"       // 大仓位开仓前自我审查的系统提示词
+)
+
+// MonolithicData 单一prompt引擎系统提示词的模板变量
+type MonolithicData struct {
+	Equity              float64
+	BTCETHLeverage      int
+	AltcoinLeverage     int
+	AltLeverageMidVol   int // 山寨币中波动杠杆（基础杠杆×0.8）
+	MaxPositions        int
+	MaxLong             int
+	MaxShort            int
+	MaxNotionalMultiple float64 // 0=不限制
+	AltMinUSD           float64
+	AltMaxUSD           float64
+	MajorMinUSD         float64
+	MajorMaxUSD         float64
+}
+
+// PredictionData 预测Agent系统提示词的模板变量
+type PredictionData struct {
+	Mistakes string // 最近错误教训（自动生成）
+}
+
+//go:embed *.tmpl
+var builtinFS embed.FS
+
+// override 目录中的模板（按文件修改时间重新加载，解析失败时tmpl为nil，文件修改前不再重试）
+type override struct {
+	modTime time.Time
+	text    string
+	tmpl    *template.Template
+}
+
+var (
+	mu        sync.Mutex
+	dir       string
+	builtin   = loadBuiltin()
+	overrides = make(map[string]*override)
+)
+
+func loadBuiltin() map[string]*template.Template {
+	entries, err := builtinFS.ReadDir(".")
+	if err != nil {
+		panic(fmt.Sprintf("读取内置提示词模板失败: %v", err))
+	}
+	templates := make(map[string]*template.Template)
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".tmpl")
+		data, err := builtinFS.ReadFile(e.Name())
+		if err != nil {
+			panic(fmt.Sprintf("读取内置提示词模板%s失败: %v", name, err))
+		}
+		templates[name] = template.Must(template.New(name).Parse(string(data)))
+	}
+	return templates
+}
+
+// SetDir 设置外部模板目录（目录中没有的模板继续使用内置版本）
+func SetDir(d string) {
+	mu.Lock()
+	defer mu.Unlock()
+	dir = d
+	overrides = make(map[string]*override)
+}
+
+// Names 所有模板名称
+func Names() []string {
+	names := make([]string, 0, len(builtin))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render 渲染模板（外部模板解析或渲染失败时回退到内置版本，不影响交易）
+func Render(name string, data interface{}) string {
+	mu.Lock()
+	o := loadOverride(name)
+	mu.Unlock()
+
+	var buf bytes.Buffer
+	if o != nil {
+		err := o.tmpl.Execute(&buf, data)
+		if err == nil {
+			return buf.String()
+		}
+		log.Printf("⚠️  提示词模板%s渲染失败，使用内置版本: %v", name, err)
+		buf.Reset()
+	}
+
+	t, ok := builtin[name]
+	if !ok {
+		log.Printf("⚠️  未知的提示词模板: %s", name)
+		return ""
+	}
+	if err := t.Execute(&buf, data); err != nil {
+		log.Printf("⚠️  内置提示词模板%s渲染失败: %v", name, err)
+	}
+	return buf.String()
+}
+
+// Version 当前生效模板的版本号：<标签>-<内容哈希前8位>
+// 标签取模板目录中VERSION文件的内容，没有时为builtin（全部内置）或custom（有外部模板）
+func Version() string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	h := sha256.New()
+	custom := false
+	for _, name := range Names() {
+		text := ""
+		if o := loadOverride(name); o != nil {
+			text = o.text
+			custom = true
+		} else {
+			data, _ := builtinFS.ReadFile(name + ".tmpl")
+			text = string(data)
+		}
+		h.Write([]byte(name + "\x00" + text + "\x00"))
+	}
+
+	label := "builtin"
+	if custom {
+		label = "custom"
+	}
+	if dir != "" {
+		if data, err := os.ReadFile(filepath.Join(dir, "VERSION")); err == nil && strings.TrimSpace(string(data)) != "" {
+			label = strings.TrimSpace(string(data))
+		}
+	}
+	return label + "-" + hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// loadOverride 加载目录中的模板（文件不存在或解析失败时返回nil，调用方持有锁）
+func loadOverride(name string) *override {
+	if dir == "" {
+		return nil
+	}
+	path := filepath.Join(dir, name+".tmpl")
+	info, err := os.Stat(path)
+	if err != nil {
+		delete(overrides, name)
+		return nil
+	}
+	if o, ok := overrides[name]; ok && o.modTime.Equal(info.ModTime()) {
+		if o.tmpl == nil {
+			return nil
+		}
+		return o
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("⚠️  读取提示词模板%s失败，使用内置版本: %v", path, err)
+		return nil
+	}
+	_, reloaded := overrides[name]
+	tmpl, err := template.New(name).Parse(string(data))
+	if err != nil {
+		log.Printf("⚠️  解析提示词模板%s失败，使用内置版本: %v", path, err)
+		overrides[name] = &override{modTime: info.ModTime()}
+		return nil
+	}
+	overrides[name] = &override{modTime: info.ModTime(), text: string(data), tmpl: tmpl}
+	if reloaded {
+		log.Printf("📝 提示词模板已重新加载: %s", path)
+	} else {
+		log.Printf("📝 使用外部提示词模板: %s", path)
+	}
+	return overrides[name]
+}
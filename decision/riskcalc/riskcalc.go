@@ -0,0 +1,481 @@
+// Package riskcalc 风控计算（纯函数，无日志/无全局状态）
+// 开仓校验、止损止盈ATR倍数校验、强平价安全距离与凯利仓位计算都集中在这里，
+// 阈值通过 Limits 注入，调用方负责记录日志和归因
+package riskcalc
+
+import (
+	"fmt"
+	"math"
+)
+
+// 默认风控阈值（decision/agents/constants.go 引用这些值）
+const (
+	DefaultMinRiskReward          = 2.0  // 最低R/R比
+	DefaultRRTolerance            = 0.05 // R/R比允许的浮点误差（5%）
+	DefaultLiquidationMarginRate  = 0.95 // 强平保证金率（近似）
+	DefaultLiquidationSafetyRatio = 0.3  // 止损价距强平价的安全缓冲（30%）
+	DefaultMinStopATR             = 4.5  // 最小止损倍数（xATR）
+	DefaultMaxStopATR             = 25.0 // 最大止损倍数（xATR）
+	DefaultMinTPATR               = 9.0  // 最小止盈倍数（xATR）
+	DefaultMaxTPATR               = 30.0 // 最大止盈倍数（xATR）
+)
+
+// Limits 风控阈值
+type Limits struct {
+	MinRiskReward          float64
+	RRTolerance            float64
+	LiquidationMarginRate  float64
+	LiquidationSafetyRatio float64
+	MinStopATR             float64
+	MaxStopATR             float64
+	MinTPATR               float64
+	MaxTPATR               float64
+
+	// 低波动豁免：ATR%低于此值时不检查ATR倍数，改为检查止损/止盈的绝对百分比范围
+	LowVolATRPct float64
+	LowVolMinSL  float64
+	LowVolMaxSL  float64
+	LowVolMinTP  float64
+	LowVolMaxTP  float64
+
+	// 凯利仓位
+	KellyScale       float64 // 实际使用的凯利比例（1/4凯利）
+	MaxPositionRatio float64 // 单币名义价值上限（占总净值）
+	MinNotional      float64 // 交易所最小名义价值（USDT）
+	MarginUsage      float64 // 可用保证金使用上限
+	LeverageCut      float64 // 止损越过强平价时的杠杆缩减系数
+}
+
+// DefaultLimits 默认风控阈值
+func DefaultLimits() Limits {
+	return Limits{
+		MinRiskReward:          DefaultMinRiskReward,
+		RRTolerance:            DefaultRRTolerance,
+		LiquidationMarginRate:  DefaultLiquidationMarginRate,
+		LiquidationSafetyRatio: DefaultLiquidationSafetyRatio,
+		MinStopATR:             DefaultMinStopATR,
+		MaxStopATR:             DefaultMaxStopATR,
+		MinTPATR:               DefaultMinTPATR,
+		MaxTPATR:               DefaultMaxTPATR,
+
+		LowVolATRPct: 0.5,
+		LowVolMinSL:  0.8,
+		LowVolMaxSL:  10.0,
+		LowVolMinTP:  1.6,
+		LowVolMaxTP:  20.0,
+
+		KellyScale:       0.25,
+		MaxPositionRatio: 0.6,
+		MinNotional:      100,
+		MarginUsage:      0.9,
+		LeverageCut:      0.7,
+	}
+}
+
+// minRR 带容差的最低R/R比
+func (l Limits) minRR() float64 {
+	return l.MinRiskReward * (1.0 - l.RRTolerance)
+}
+
+// Side 统一方向：up/long → long，down/short → short（其他返回空）
+func Side(direction string) string {
+	switch direction {
+	case "up", "long", "open_long":
+		return "long"
+	case "down", "short", "open_short":
+		return "short"
+	}
+	return ""
+}
+
+// LiquidationPrice 估算强平价（逐仓近似：入场价 × (1 ∓ 保证金率/杠杆)）
+func LiquidationPrice(side string, entry float64, leverage int, marginRate float64) float64 {
+	if leverage < 1 {
+		leverage = 1
+	}
+	rate := marginRate / float64(leverage)
+	if side == "long" {
+		return entry * (1 - rate)
+	}
+	return entry * (1 + rate)
+}
+
+// StopBeyondLiquidation 止损是否在强平价之外（会先被强平，止损失效）
+func StopBeyondLiquidation(side string, stopLoss, liquidation float64) bool {
+	if side == "long" {
+		return stopLoss <= liquidation
+	}
+	return stopLoss >= liquidation
+}
+
+// RiskReward 以price为入场价的风险%、收益%和R/R比（风险≤0时R/R为0）
+func RiskReward(side string, price, stopLoss, takeProfit float64) (riskPct, rewardPct, ratio float64) {
+	if side == "long" {
+		riskPct = (price - stopLoss) / price * 100
+		rewardPct = (takeProfit - price) / price * 100
+	} else {
+		riskPct = (stopLoss - price) / price * 100
+		rewardPct = (price - takeProfit) / price * 100
+	}
+	if riskPct > 0 {
+		ratio = rewardPct / riskPct
+	}
+	return riskPct, rewardPct, ratio
+}
+
+// OpenOrder 待校验的开仓参数
+type OpenOrder struct {
+	Side       string // long/short
+	Price      float64
+	StopLoss   float64
+	TakeProfit float64
+	Leverage   int
+}
+
+// CheckOpen 开仓硬约束：止损止盈方向、R/R比、止损不越过强平价
+func CheckOpen(o OpenOrder, l Limits) error {
+	if o.StopLoss <= 0 || o.TakeProfit <= 0 {
+		return fmt.Errorf("止损和止盈必须大于0")
+	}
+	if o.Side == "long" && o.StopLoss >= o.TakeProfit {
+		return fmt.Errorf("做多时止损价必须小于止盈价")
+	}
+	if o.Side == "short" && o.StopLoss <= o.TakeProfit {
+		return fmt.Errorf("做空时止损价必须大于止盈价")
+	}
+	if o.Price <= 0 {
+		return fmt.Errorf("当前市价无效: %.4f", o.Price)
+	}
+
+	riskPct, rewardPct, ratio := RiskReward(o.Side, o.Price, o.StopLoss, o.TakeProfit)
+	if ratio < l.minRR() {
+		return fmt.Errorf("风险回报比过低(%.2f:1)，必须≥%.1f:1 [当前价:%.4f 风险:%.2f%% 收益:%.2f%%] [止损:%.2f 止盈:%.2f]",
+			ratio, l.MinRiskReward, o.Price, riskPct, rewardPct, o.StopLoss, o.TakeProfit)
+	}
+
+	liq := LiquidationPrice(o.Side, o.Price, o.Leverage, l.LiquidationMarginRate)
+	if StopBeyondLiquidation(o.Side, o.StopLoss, liq) {
+		if o.Side == "long" {
+			return fmt.Errorf("🚨 致命错误：做多止损价(%.4f)低于或等于估算的强平价(%.4f)，止损将失效，仓位会被强制平仓导致100%%保证金损失！[当前价:%.4f 杠杆:%dx]",
+				o.StopLoss, liq, o.Price, o.Leverage)
+		}
+		return fmt.Errorf("🚨 致命错误：做空止损价(%.4f)高于或等于估算的强平价(%.4f)，止损将失效，仓位会被强制平仓导致100%%保证金损失！[当前价:%.4f 杠杆:%dx]",
+			o.StopLoss, liq, o.Price, o.Leverage)
+	}
+	return nil
+}
+
+// ProtectionCheck 止损止盈ATR倍数校验的计算结果
+type ProtectionCheck struct {
+	ATRPct        float64
+	StopPct       float64 // 止损距离（%）
+	TPPct         float64 // 止盈距离（%）
+	StopATR       float64 // 止损ATR倍数
+	TPATR         float64 // 止盈ATR倍数
+	RiskReward    float64
+	LowVolExempt  bool    // 低波动豁免了倍数检查
+	Liquidation   float64 // 估算强平价
+	SafeStopLimit float64 // 止损允许的最远价格（强平价留安全缓冲）
+}
+
+// CheckProtection 止损止盈校验：ATR倍数范围（低波动时改查绝对百分比）、R/R比、止损距强平价的安全缓冲
+// minStopATR/minTPATR 为0时使用Limits中的值（策略档案可调整）
+func CheckProtection(o OpenOrder, atr, minStopATR, minTPATR float64, l Limits) (ProtectionCheck, error) {
+	var c ProtectionCheck
+	if o.Price <= 0 || atr <= 0 {
+		return c, fmt.Errorf("市场数据不完整")
+	}
+	if minStopATR <= 0 {
+		minStopATR = l.MinStopATR
+	}
+	if minTPATR <= 0 {
+		minTPATR = l.MinTPATR
+	}
+
+	c.ATRPct = atr / o.Price * 100
+	if o.Side == "long" {
+		c.StopPct = (o.Price - o.StopLoss) / o.Price * 100
+		c.TPPct = (o.TakeProfit - o.Price) / o.Price * 100
+		c.StopATR = (o.Price - o.StopLoss) / atr
+		c.TPATR = (o.TakeProfit - o.Price) / atr
+	} else {
+		c.StopPct = (o.StopLoss - o.Price) / o.Price * 100
+		c.TPPct = (o.Price - o.TakeProfit) / o.Price * 100
+		c.StopATR = (o.StopLoss - o.Price) / atr
+		c.TPATR = (o.Price - o.TakeProfit) / atr
+	}
+
+	if c.ATRPct < l.LowVolATRPct {
+		// 低波动市场：倍数会被放大（ATR=0.13%时5%止损=38倍ATR），只检查绝对距离
+		c.LowVolExempt = true
+		if c.StopPct < l.LowVolMinSL || c.StopPct > l.LowVolMaxSL {
+			return c, fmt.Errorf("低波动市场止损%.2f%%超出合理范围[%.1f-%.1f]%%（ATR仅%.2f%%，豁免倍数检查）",
+				c.StopPct, l.LowVolMinSL, l.LowVolMaxSL, c.ATRPct)
+		}
+		if c.TPPct < l.LowVolMinTP || c.TPPct > l.LowVolMaxTP {
+			return c, fmt.Errorf("低波动市场止盈%.2f%%超出合理范围[%.1f-%.1f]%%（ATR仅%.2f%%，豁免倍数检查）",
+				c.TPPct, l.LowVolMinTP, l.LowVolMaxTP, c.ATRPct)
+		}
+	} else {
+		tol := l.RRTolerance
+		if c.StopATR < minStopATR*(1-tol) || c.StopATR > l.MaxStopATR*(1+tol) {
+			return c, fmt.Errorf("止损倍数%.2fx超出合理范围[%.1f-%.1f]ATR（止损%.2f%%, ATR%%=%.2f%%）",
+				c.StopATR, minStopATR, l.MaxStopATR, c.StopPct, c.ATRPct)
+		}
+		if c.TPATR < minTPATR*(1-tol) || c.TPATR > l.MaxTPATR*(1+tol) {
+			return c, fmt.Errorf("止盈倍数%.2fx超出合理范围[%.1f-%.1f]ATR（止盈%.2f%%, ATR%%=%.2f%%）",
+				c.TPATR, minTPATR, l.MaxTPATR, c.TPPct, c.ATRPct)
+		}
+	}
+
+	c.RiskReward = c.TPPct / c.StopPct
+	if c.StopPct <= 0 || c.RiskReward < l.minRR() {
+		return c, fmt.Errorf("风险回报比%.2f:1 < %.1f:1要求（止损%.1fx, 止盈%.1fx, 差值%.2f）",
+			c.RiskReward, l.MinRiskReward, c.StopATR, c.TPATR, l.MinRiskReward-c.RiskReward)
+	}
+
+	// 止损必须在强平价内侧，且与强平价之间保留 (入场价-强平价)×安全比例 的缓冲
+	c.Liquidation = LiquidationPrice(o.Side, o.Price, o.Leverage, l.LiquidationMarginRate)
+	if o.Side == "long" {
+		c.SafeStopLimit = c.Liquidation + (o.Price-c.Liquidation)*l.LiquidationSafetyRatio
+		if o.StopLoss < c.SafeStopLimit {
+			return c, fmt.Errorf("止损%.4f离强平价%.4f过近（实际%.2f%% < 安全要求%.2f%%）",
+				o.StopLoss, c.Liquidation, (o.StopLoss-c.Liquidation)/o.Price*100, (c.SafeStopLimit-c.Liquidation)/o.Price*100)
+		}
+	} else {
+		c.SafeStopLimit = c.Liquidation - (c.Liquidation-o.Price)*l.LiquidationSafetyRatio
+		if o.StopLoss > c.SafeStopLimit {
+			return c, fmt.Errorf("止损%.4f离强平价%.4f过近（实际%.2f%% < 安全要求%.2f%%）",
+				o.StopLoss, c.Liquidation, (c.Liquidation-o.StopLoss)/o.Price*100, (c.Liquidation-c.SafeStopLimit)/o.Price*100)
+		}
+	}
+	return c, nil
+}
+
+// NormalizeCases 修正AI预测中best_case/worst_case的符号（价格变化%）
+// 修正后做多 best>0、worst<0，做空 best<0、worst>0；返回修正说明（无修正时为空）
+func NormalizeCases(side string, best, worst float64) (float64, float64, string) {
+	if side == "short" {
+		switch {
+		case best > 0:
+			// 把价格上涨当成最好情况 → 完全搞反
+			nb, nw := -math.Abs(worst), math.Abs(best)
+			return nb, nw, fmt.Sprintf("做空预测修正（类型1）：best_case %.2f%% → %.2f%%, worst_case %.2f%% → %.2f%%", best, nb, worst, nw)
+		case best < 0 && worst < 0:
+			if math.Abs(best) < math.Abs(worst) {
+				// 两个都是跌幅且"跌得少=好" → 交换，跌得多的是止盈，跌得少的改为正号作为止损
+				return worst, -best, fmt.Sprintf("做空预测修正（类型2）：best/worst %.2f%%/%.2f%% → %.2f%%/%.2f%%", best, worst, worst, -best)
+			}
+			return best, -worst, fmt.Sprintf("做空worst_case符号修正：%.2f%% → %.2f%%", worst, -worst)
+		case worst < 0:
+			return best, -worst, fmt.Sprintf("做空worst_case符号修正：%.2f%% → %.2f%%", worst, -worst)
+		}
+		return best, worst, ""
+	}
+
+	switch {
+	case best < 0:
+		nb, nw := math.Abs(worst), -math.Abs(best)
+		return nb, nw, fmt.Sprintf("做多预测修正：best_case %.2f%% → %.2f%%, worst_case %.2f%% → %.2f%%", best, nb, worst, nw)
+	case worst > 0:
+		return best, -worst, fmt.Sprintf("做多worst_case修正：%.2f%% → %.2f%%", worst, -worst)
+	}
+	return best, worst, ""
+}
+
+// SizingInput 凯利仓位计算输入
+type SizingInput struct {
+	Side             string  // long/short
+	Probability      float64 // 胜率
+	BestCase         float64 // 最好情况价格变化%（已经过NormalizeCases）
+	WorstCase        float64 // 最坏情况价格变化%
+	RiskLevel        string  // low/medium/high
+	Price            float64
+	ATR              float64
+	TotalEquity      float64
+	AvailableBalance float64 // 本仓位可用的保证金预算
+	BaseLeverage     int
+	StopATR          float64 // 最小止损ATR倍数（0=Limits.MinStopATR）
+}
+
+// Sizing 凯利仓位计算结果
+type Sizing struct {
+	PositionSize  float64 // 名义价值（USDT）
+	Leverage      int
+	StopLoss      float64
+	TakeProfit    float64
+	BestCase      float64 // 调整后的最好情况%
+	WorstCase     float64 // 调整后的最坏情况%
+	ATRPct        float64
+	PayoffRatio   float64
+	KellyFraction float64
+	AppliedKelly  float64
+	Notes         []string // 调整说明（调用方记录日志）
+}
+
+// SizeFromPrediction 按预测计算仓位、杠杆和止损止盈
+// 1) best/worst不小于ATR×止损倍数  2) 保证R/R≥最低要求  3) 1/4凯利仓位（上限、最小名义价值、保证金预算）
+// 4) 按风险等级降杠杆  5) 止损越过强平价时再降一次杠杆
+func SizeFromPrediction(in SizingInput, l Limits) (*Sizing, error) {
+	if in.Price <= 0 || in.ATR <= 0 {
+		return nil, fmt.Errorf("市场数据不完整")
+	}
+	s := &Sizing{BestCase: in.BestCase, WorstCase: in.WorstCase}
+	s.ATRPct = in.ATR / in.Price * 100
+
+	stopATR := in.StopATR
+	if stopATR <= 0 {
+		stopATR = l.MinStopATR
+	}
+	minCase := math.Max(0.5, s.ATRPct*stopATR)
+	if math.Abs(s.BestCase) < minCase {
+		s.Notes = append(s.Notes, fmt.Sprintf("best_case=%.2f%%过小（ATR%%=%.2f%%），调整为%.2f%%", s.BestCase, s.ATRPct, minCase))
+		s.BestCase = math.Copysign(minCase, signOrPositive(s.BestCase))
+	}
+	if math.Abs(s.WorstCase) < minCase {
+		s.Notes = append(s.Notes, fmt.Sprintf("worst_case=%.2f%%过小（ATR%%=%.2f%%），调整为%.2f%%", s.WorstCase, s.ATRPct, minCase))
+		s.WorstCase = math.Copysign(minCase, signOrPositive(s.WorstCase))
+	}
+
+	// R/R不足时放大止盈目标
+	if math.Abs(s.BestCase)/math.Abs(s.WorstCase) < l.MinRiskReward {
+		required := math.Abs(s.WorstCase) * l.MinRiskReward
+		if in.Side == "short" {
+			required = -required
+		}
+		s.Notes = append(s.Notes, fmt.Sprintf("R/R调整: best_case %.2f%% → %.2f%% (确保R/R≥%.1f)", s.BestCase, required, l.MinRiskReward))
+		s.BestCase = required
+	}
+
+	payoff, err := PayoffRatio(in.Side, s.BestCase, s.WorstCase)
+	if err != nil {
+		return nil, err
+	}
+	s.PayoffRatio = payoff
+
+	s.KellyFraction = Kelly(in.Probability, payoff)
+	if s.KellyFraction <= 0 {
+		return s, fmt.Errorf("凯利比例为负，不应开仓")
+	}
+	s.AppliedKelly = s.KellyFraction * l.KellyScale
+
+	s.PositionSize = math.Min(in.TotalEquity*s.AppliedKelly, in.TotalEquity*l.MaxPositionRatio)
+	s.Leverage = RiskLeverage(in.BaseLeverage, in.RiskLevel)
+
+	if s.PositionSize < l.MinNotional {
+		s.Notes = append(s.Notes, fmt.Sprintf("凯利仓位%.2f USDT过小，使用最小仓位%.0f USDT", s.PositionSize, l.MinNotional))
+		s.PositionSize = l.MinNotional
+	}
+
+	// 保证金预算不足时降低仓位（不低于最小名义价值）
+	if s.PositionSize/float64(s.Leverage) > in.AvailableBalance*l.MarginUsage {
+		old := s.PositionSize
+		s.PositionSize = in.AvailableBalance * l.MarginUsage * float64(s.Leverage)
+		if s.PositionSize < l.MinNotional {
+			s.PositionSize = l.MinNotional
+			margin := l.MinNotional / float64(s.Leverage)
+			if margin > in.AvailableBalance {
+				return s, fmt.Errorf("账户资金不足: 可用%.2f USDT, %dx杠杆下最小仓位%.0f USDT需保证金%.2f USDT",
+					in.AvailableBalance, s.Leverage, l.MinNotional, margin)
+			}
+		}
+		s.Notes = append(s.Notes, fmt.Sprintf("保证金不足，降低仓位: %.2f → %.2f USDT (保证金%.2f → %.2f)",
+			old, s.PositionSize, old/float64(s.Leverage), s.PositionSize/float64(s.Leverage)))
+	}
+
+	s.StopLoss = in.Price * (1 + s.WorstCase/100)
+	s.TakeProfit = in.Price * (1 + s.BestCase/100)
+
+	if liq := LiquidationPrice(in.Side, in.Price, s.Leverage, l.LiquidationMarginRate); StopBeyondLiquidation(in.Side, s.StopLoss, liq) {
+		old := s.Leverage
+		s.Leverage = int(float64(s.Leverage) * l.LeverageCut)
+		if s.Leverage < 1 {
+			s.Leverage = 1
+		}
+		s.Notes = append(s.Notes, fmt.Sprintf("止损%.4f越过强平价%.4f，杠杆 %dx → %dx", s.StopLoss, liq, old, s.Leverage))
+	}
+
+	if margin := s.PositionSize / float64(s.Leverage); margin > in.AvailableBalance*l.MarginUsage {
+		return s, fmt.Errorf("调整杠杆后保证金不足: 需要%.2f USDT, 可用%.2f USDT (杠杆%dx)",
+			margin, in.AvailableBalance, s.Leverage)
+	}
+	return s, nil
+}
+
+// ProtectionPrices 只按预测计算止损止盈价（与SizeFromPrediction第1-2步一致：ATR下限 + 最低R/R）
+// 用于未进入仓位计算就被拒绝的开仓，估算假设的止损止盈
+func ProtectionPrices(side string, price, atr, best, worst, stopATR float64, l Limits) (stopLoss, takeProfit float64) {
+	best, worst, _ = NormalizeCases(side, best, worst)
+	if stopATR <= 0 {
+		stopATR = l.MinStopATR
+	}
+	minCase := 0.5
+	if price > 0 && atr > 0 {
+		minCase = math.Max(minCase, atr/price*100*stopATR)
+	}
+	if math.Abs(best) < minCase {
+		best = math.Copysign(minCase, signOrPositive(best))
+	}
+	if math.Abs(worst) < minCase {
+		worst = math.Copysign(minCase, signOrPositive(worst))
+	}
+	if math.Abs(best)/math.Abs(worst) < l.MinRiskReward {
+		best = math.Copysign(math.Abs(worst)*l.MinRiskReward, signOrPositive(best))
+	}
+	return price * (1 + worst/100), price * (1 + best/100)
+}
+
+// PayoffRatio 盈亏比（盈利幅度/亏损幅度，做空两个值都为负时取跌幅大的作为盈利）
+func PayoffRatio(side string, best, worst float64) (float64, error) {
+	absBest, absWorst := math.Abs(best), math.Abs(worst)
+	var ratio float64
+	if side == "short" {
+		if absBest < 1e-6 {
+			return 0, fmt.Errorf("做空时best_case(%.2f)过小，无法计算盈亏比", best)
+		}
+		ratio = absBest / absWorst
+		if best < 0 && worst < 0 && absWorst > absBest {
+			ratio = absWorst / absBest
+		}
+	} else {
+		if absWorst < 1e-6 {
+			return 0, fmt.Errorf("做多时worst_case(%.2f)过小，无法计算盈亏比", worst)
+		}
+		ratio = best / absWorst
+	}
+	if ratio <= 0 || math.IsInf(ratio, 0) || math.IsNaN(ratio) {
+		return 0, fmt.Errorf("无效的盈亏比: %.2f", ratio)
+	}
+	return ratio, nil
+}
+
+// Kelly 凯利比例 f* = (p×b - q) / b
+func Kelly(winRate, payoff float64) float64 {
+	if payoff <= 0 {
+		return 0
+	}
+	return (winRate*payoff - (1 - winRate)) / payoff
+}
+
+// RiskLeverage 按风险等级调整杠杆：low=100%，medium/未知=80%，high=60%（至少1倍）
+func RiskLeverage(base int, riskLevel string) int {
+	factor := 0.8
+	switch riskLevel {
+	case "low":
+		factor = 1.0
+	case "high":
+		factor = 0.6
+	}
+	leverage := int(float64(base) * factor)
+	if leverage < 1 {
+		leverage = 1
+	}
+	return leverage
+}
+
+func signOrPositive(v float64) float64 {
+	if v >= 0 {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,227 @@
+package riskcalc
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestLiquidationPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		side     string
+		entry    float64
+		leverage int
+		rate     float64
+		want     float64
+	}{
+		{"做多10倍", "long", 100, 10, 0.95, 90.5},
+		{"做空10倍", "short", 100, 10, 0.95, 109.5},
+		{"做多1倍", "long", 100, 1, 0.95, 5},
+		{"做空1倍", "short", 100, 1, 0.95, 195},
+		{"杠杆0按1倍", "long", 100, 0, 0.95, 5},
+		{"负杠杆按1倍", "short", 100, -3, 0.95, 195},
+		{"做多125倍", "long", 50000, 125, 0.95, 50000 * (1 - 0.95/125)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LiquidationPrice(tt.side, tt.entry, tt.leverage, tt.rate); !approx(got, tt.want) {
+				t.Errorf("LiquidationPrice(%s, %v, %d) = %v, want %v", tt.side, tt.entry, tt.leverage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopBeyondLiquidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		side     string
+		stopLoss float64
+		liq      float64
+		want     bool
+	}{
+		{"做多止损在强平价上方", "long", 95, 90, false},
+		{"做多止损等于强平价", "long", 90, 90, true},
+		{"做多止损在强平价下方", "long", 85, 90, true},
+		{"做空止损在强平价下方", "short", 105, 110, false},
+		{"做空止损等于强平价", "short", 110, 110, true},
+		{"做空止损在强平价上方", "short", 115, 110, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StopBeyondLiquidation(tt.side, tt.stopLoss, tt.liq); got != tt.want {
+				t.Errorf("StopBeyondLiquidation(%s, %v, %v) = %v, want %v", tt.side, tt.stopLoss, tt.liq, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRiskReward(t *testing.T) {
+	tests := []struct {
+		name                     string
+		side                     string
+		price, stop, tp          float64
+		wantRisk, wantReward, rr float64
+	}{
+		{"做多1:2", "long", 100, 95, 110, 5, 10, 2},
+		{"做空1:3", "short", 100, 102, 94, 2, 6, 3},
+		{"做多止损在入场价上方", "long", 100, 101, 110, -1, 10, 0},
+		{"做空止损等于入场价", "short", 100, 100, 90, 0, 10, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk, reward, rr := RiskReward(tt.side, tt.price, tt.stop, tt.tp)
+			if !approx(risk, tt.wantRisk) || !approx(reward, tt.wantReward) || !approx(rr, tt.rr) {
+				t.Errorf("RiskReward = (%v, %v, %v), want (%v, %v, %v)", risk, reward, rr, tt.wantRisk, tt.wantReward, tt.rr)
+			}
+		})
+	}
+}
+
+func TestCheckOpen(t *testing.T) {
+	l := DefaultLimits()
+	tests := []struct {
+		name    string
+		order   OpenOrder
+		wantErr string // 空=应通过
+	}{
+		{"做多通过", OpenOrder{Side: "long", Price: 100, StopLoss: 95, TakeProfit: 110, Leverage: 5}, ""},
+		{"做空通过", OpenOrder{Side: "short", Price: 100, StopLoss: 105, TakeProfit: 90, Leverage: 5}, ""},
+		{"R/R在容差内通过", OpenOrder{Side: "long", Price: 100, StopLoss: 95, TakeProfit: 109.5, Leverage: 5}, ""},
+		{"止损为0", OpenOrder{Side: "long", Price: 100, StopLoss: 0, TakeProfit: 110, Leverage: 5}, "必须大于0"},
+		{"做多止损高于止盈", OpenOrder{Side: "long", Price: 100, StopLoss: 110, TakeProfit: 105, Leverage: 5}, "做多时止损价必须小于止盈价"},
+		{"做空止损低于止盈", OpenOrder{Side: "short", Price: 100, StopLoss: 90, TakeProfit: 95, Leverage: 5}, "做空时止损价必须大于止盈价"},
+		{"市价无效", OpenOrder{Side: "long", Price: 0, StopLoss: 95, TakeProfit: 110, Leverage: 5}, "当前市价无效"},
+		{"R/R过低", OpenOrder{Side: "long", Price: 100, StopLoss: 95, TakeProfit: 105, Leverage: 5}, "风险回报比过低"},
+		{"做多止损越过强平价", OpenOrder{Side: "long", Price: 100, StopLoss: 85, TakeProfit: 140, Leverage: 10}, "做多止损价"},
+		{"做空止损越过强平价", OpenOrder{Side: "short", Price: 100, StopLoss: 115, TakeProfit: 60, Leverage: 10}, "做空止损价"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckOpen(tt.order, l)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CheckOpen() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("CheckOpen() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckProtection(t *testing.T) {
+	l := DefaultLimits()
+	tests := []struct {
+		name       string
+		order      OpenOrder
+		atr        float64
+		minStopATR float64
+		minTPATR   float64
+		wantErr    string
+		wantLowVol bool
+	}{
+		// ATR=1%：止损5x、止盈10x
+		{"做多通过", OpenOrder{Side: "long", Price: 100, StopLoss: 95, TakeProfit: 110, Leverage: 5}, 1, 0, 0, "", false},
+		{"做空通过", OpenOrder{Side: "short", Price: 100, StopLoss: 105, TakeProfit: 90, Leverage: 5}, 1, 0, 0, "", false},
+		{"市场数据不完整", OpenOrder{Side: "long", Price: 100, StopLoss: 95, TakeProfit: 110, Leverage: 5}, 0, 0, 0, "市场数据不完整", false},
+		{"止损倍数过小", OpenOrder{Side: "long", Price: 100, StopLoss: 97, TakeProfit: 110, Leverage: 5}, 1, 0, 0, "止损倍数", false},
+		{"策略档案放宽止损倍数", OpenOrder{Side: "long", Price: 100, StopLoss: 97, TakeProfit: 110, Leverage: 5}, 1, 3, 0, "", false},
+		{"止盈倍数过大", OpenOrder{Side: "short", Price: 100, StopLoss: 105, TakeProfit: 60, Leverage: 2}, 1, 0, 0, "止盈倍数", false},
+		{"R/R不足", OpenOrder{Side: "long", Price: 100, StopLoss: 80, TakeProfit: 120, Leverage: 2}, 1, 0, 0, "风险回报比", false},
+		// 10倍做多强平价90.5，安全边界93.35
+		{"做多止损离强平价过近", OpenOrder{Side: "long", Price: 100, StopLoss: 93, TakeProfit: 115, Leverage: 10}, 1, 0, 0, "离强平价", false},
+		{"做空止损离强平价过近", OpenOrder{Side: "short", Price: 100, StopLoss: 107, TakeProfit: 85, Leverage: 10}, 1, 0, 0, "离强平价", false},
+		// ATR=0.2%：低波动豁免，只检查绝对百分比
+		{"低波动豁免通过", OpenOrder{Side: "long", Price: 100, StopLoss: 99, TakeProfit: 102, Leverage: 5}, 0.2, 0, 0, "", true},
+		{"低波动止损过近", OpenOrder{Side: "long", Price: 100, StopLoss: 99.5, TakeProfit: 102, Leverage: 5}, 0.2, 0, 0, "低波动市场止损", true},
+		{"低波动止盈过远", OpenOrder{Side: "short", Price: 100, StopLoss: 102, TakeProfit: 75, Leverage: 5}, 0.2, 0, 0, "低波动市场止盈", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := CheckProtection(tt.order, tt.atr, tt.minStopATR, tt.minTPATR, l)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CheckProtection() = %v, want nil", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("CheckProtection() = %v, want error containing %q", err, tt.wantErr)
+			}
+			if tt.atr > 0 && c.LowVolExempt != tt.wantLowVol {
+				t.Errorf("LowVolExempt = %v, want %v", c.LowVolExempt, tt.wantLowVol)
+			}
+		})
+	}
+}
+
+// 安全缓冲对多空两个方向都生效（此前做多方向因方向字符串不一致被跳过）
+func TestCheckProtectionSafetyBufferBothSides(t *testing.T) {
+	l := DefaultLimits()
+	for _, side := range []string{"long", "short"} {
+		o := OpenOrder{Side: side, Price: 100, Leverage: 10}
+		liq := LiquidationPrice(side, o.Price, o.Leverage, l.LiquidationMarginRate)
+		// 止损放在强平价内侧、但在安全缓冲之内
+		o.StopLoss = liq + (o.Price-liq)*l.LiquidationSafetyRatio/2
+		o.TakeProfit = o.Price + (o.Price-o.StopLoss)*2.5
+		_, err := CheckProtection(o, math.Abs(o.Price-o.StopLoss)/6, 0, 0, l)
+		if err == nil || !strings.Contains(err.Error(), "离强平价") {
+			t.Errorf("%s: CheckProtection() = %v, want safety buffer error", side, err)
+		}
+	}
+}
+
+// 性质：任意杠杆(1-125)和方向下，通过校验的止损都在入场价与强平价之间
+func TestStopInsideLiquidationProperty(t *testing.T) {
+	l := DefaultLimits()
+	const price = 100.0
+	for leverage := 1; leverage <= 125; leverage++ {
+		for _, side := range []string{"long", "short"} {
+			sign := 1.0
+			if side == "short" {
+				sign = -1
+			}
+			liq := LiquidationPrice(side, price, leverage, l.LiquidationMarginRate)
+			if sign*(price-liq) <= 0 {
+				t.Fatalf("%s %dx: 强平价%.4f不在亏损方向", side, leverage, liq)
+			}
+
+			// 止损距离从0.05%扫到99%
+			for stopPct := 0.05; stopPct < 99; stopPct *= 1.15 {
+				o := OpenOrder{
+					Side:       side,
+					Price:      price,
+					StopLoss:   price * (1 - sign*stopPct/100),
+					TakeProfit: price * (1 + sign*stopPct*2.5/100),
+					Leverage:   leverage,
+				}
+				if o.TakeProfit <= 0 {
+					continue
+				}
+				inside := sign*(o.StopLoss-liq) > 0 && sign*(price-o.StopLoss) > 0
+
+				if err := CheckOpen(o, l); err == nil && !inside {
+					t.Errorf("CheckOpen %s %dx: 止损%.4f不在强平价%.4f内侧却通过校验", side, leverage, o.StopLoss, liq)
+				}
+
+				c, err := CheckProtection(o, price*stopPct/100/6, 0, 0, l)
+				if err == nil {
+					if !inside {
+						t.Errorf("CheckProtection %s %dx: 止损%.4f不在强平价%.4f内侧却通过校验", side, leverage, o.StopLoss, liq)
+					}
+					if StopBeyondLiquidation(side, o.StopLoss, c.Liquidation) {
+						t.Errorf("CheckProtection %s %dx: StopBeyondLiquidation为真却通过校验", side, leverage)
+					}
+				}
+				if c.Liquidation > 0 && !(sign*(c.SafeStopLimit-c.Liquidation) > 0 && sign*(price-c.SafeStopLimit) > 0) {
+					t.Errorf("%s %dx: 安全边界%.4f不在强平价%.4f与入场价之间", side, leverage, c.SafeStopLimit, c.Liquidation)
+				}
+			}
+		}
+	}
+}
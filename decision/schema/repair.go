@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"nofx/errs"
+	"nofx/mcp"
+	"strings"
+)
+
+const repairSystemPrompt = `你是JSON修复器。用户会给出一段不符合JSON Schema的JSON以及校验错误。
+请只修复结构、类型和缺失字段问题，尽量保留原有的数值和文字含义，不要重新分析行情。
+只输出修复后的JSON本身，不要输出解释或markdown代码块。`
+
+// Repair 把校验错误发回模型修复一次，返回通过校验的JSON文本
+// extract 用于从模型回复中提取JSON（不同输出格式的提取方式不同）
+func Repair(callCtx context.Context, client *mcp.Client, s *Schema, broken string, cause error, extract func(string) string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("## JSON Schema\n")
+	sb.WriteString(compact(s.Raw()))
+	sb.WriteString("\n\n## 原始JSON\n")
+	sb.WriteString(broken)
+	sb.WriteString("\n\n## 校验错误\n")
+	sb.WriteString(cause.Error())
+	sb.WriteString("\n\n请输出修复后的JSON。")
+
+	log.Printf("🔧 AI输出不符合%s，发起一次修复请求: %v", s.Name(), cause)
+
+	response, err := client.CallWithContext(callCtx, repairSystemPrompt, sb.String())
+	if err != nil {
+		return "", fmt.Errorf("修复请求失败: %w", err)
+	}
+
+	repaired := extract(response)
+	if repaired == "" {
+		return "", errs.New(errs.AIParseError, "修复响应中未找到JSON")
+	}
+	if err := s.Validate(repaired); err != nil {
+		return "", errs.Wrap(errs.AIParseError, 0, fmt.Errorf("修复后仍%w", err))
+	}
+
+	log.Printf("✅ AI输出已修复并通过%s校验", s.Name())
+	return repaired, nil
+}
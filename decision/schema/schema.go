@@ -0,0 +1,120 @@
+// Package schema AI输出的JSON Schema定义与校验
+// 解析AI响应后先按Schema校验结构，失败时可通过 Repair 把校验错误发回模型修复一次
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+//go:embed prediction.schema.json
+var predictionSchema string
+
+//go:embed decisions.schema.json
+var decisionsSchema string
+
+var (
+	// Prediction 单币种预测（PredictionAgent输出）
+	Prediction = mustCompile("prediction.schema.json", predictionSchema)
+	// Decisions 交易决策列表（单一prompt决策输出）
+	Decisions = mustCompile("decisions.schema.json", decisionsSchema)
+)
+
+// Schema 已编译的JSON Schema
+type Schema struct {
+	name     string
+	raw      string
+	compiled *jsonschema.Schema
+}
+
+func mustCompile(name, raw string) *Schema {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(raw))
+	if err != nil {
+		panic(fmt.Sprintf("解析schema %s失败: %v", name, err))
+	}
+
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(name, doc); err != nil {
+		panic(fmt.Sprintf("加载schema %s失败: %v", name, err))
+	}
+	compiled, err := c.Compile(name)
+	if err != nil {
+		panic(fmt.Sprintf("编译schema %s失败: %v", name, err))
+	}
+	return &Schema{name: name, raw: raw, compiled: compiled}
+}
+
+// Name schema名称
+func (s *Schema) Name() string {
+	return s.name
+}
+
+// Raw schema原文（用于修复提示）
+func (s *Schema) Raw() string {
+	return s.raw
+}
+
+// ValidationError Schema校验失败（Problems 为逐条错误，便于发回模型修复）
+type ValidationError struct {
+	Schema   string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("不符合%s: %s", e.Schema, strings.Join(e.Problems, "; "))
+}
+
+// Validate 校验JSON文本（语法错误同样返回 *ValidationError）
+func (s *Schema) Validate(jsonText string) error {
+	inst, err := jsonschema.UnmarshalJSON(strings.NewReader(jsonText))
+	if err != nil {
+		return &ValidationError{Schema: s.name, Problems: []string{fmt.Sprintf("JSON语法错误: %v", err)}}
+	}
+
+	err = s.compiled.Validate(inst)
+	if err == nil {
+		return nil
+	}
+
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return &ValidationError{Schema: s.name, Problems: []string{err.Error()}}
+	}
+	return &ValidationError{Schema: s.name, Problems: problems(verr)}
+}
+
+// problems 把校验错误展开为 "位置: 原因" 列表
+func problems(verr *jsonschema.ValidationError) []string {
+	var result []string
+	for _, unit := range verr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		loc := unit.InstanceLocation
+		if loc == "" {
+			loc = "/"
+		}
+		result = append(result, fmt.Sprintf("%s: %s", loc, unit.Error.String()))
+	}
+	if len(result) == 0 {
+		result = append(result, verr.Error())
+	}
+	return result
+}
+
+// compact 压缩JSON（修复提示中减少token）
+func compact(raw string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return raw
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return raw
+	}
+	return string(data)
+}
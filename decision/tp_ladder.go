@@ -0,0 +1,34 @@
+package decision
+
+import "fmt"
+
+// ValidateTakeProfitLadder 校验分批止盈：最多3档、比例合计不超过100%、价格在盈利方向且逐档远离入场价
+// takeProfit>0时各档不能越过最终止盈价（越过的档位永远不会成交）
+func ValidateTakeProfitLadder(side string, price, takeProfit float64, ladder []TakeProfitLevel) error {
+	if len(ladder) == 0 {
+		return nil
+	}
+	if len(ladder) > MaxTakeProfitLevels {
+		return fmt.Errorf("take_profit_ladder最多%d档，实际%d档", MaxTakeProfitLevels, len(ladder))
+	}
+
+	total := 0.0
+	prev := price
+	for i, level := range ladder {
+		if level.Pct <= 0 || level.Pct > 100 {
+			return fmt.Errorf("take_profit_ladder第%d档比例必须在0-100之间: %.2f", i+1, level.Pct)
+		}
+		total += level.Pct
+		if (side == "long" && level.Price <= prev) || (side == "short" && (level.Price >= prev || level.Price <= 0)) {
+			return fmt.Errorf("take_profit_ladder第%d档价格%.4f必须在盈利方向且比上一档更远（%s, 入场%.4f）", i+1, level.Price, side, price)
+		}
+		if takeProfit > 0 && ((side == "long" && level.Price > takeProfit) || (side == "short" && level.Price < takeProfit)) {
+			return fmt.Errorf("take_profit_ladder第%d档价格%.4f越过了最终止盈%.4f", i+1, level.Price, takeProfit)
+		}
+		prev = level.Price
+	}
+	if total > 100+1e-9 {
+		return fmt.Errorf("take_profit_ladder比例合计%.2f%%超过100%%", total)
+	}
+	return nil
+}
@@ -62,6 +62,7 @@ func main() {
 	filtered := filterBySince(records, cutoff)
 	stats := computeStats(filtered, *symbol)
 	printSummary(stats, *symbol, *topN, cutoff)
+	printGateReport(pt.GateValueReport(cutoff))
 }
 
 func loadRecords(dir string) ([]tracker.PredictionRecord, error) {
@@ -235,6 +236,31 @@ func printTopSymbols(records []tracker.PredictionRecord, topN int) {
 	}
 }
 
+// printGateReport 🚧 各开仓规则拒绝的预测如果开仓会怎样（全部交易对）
+func printGateReport(report *tracker.GateReport) {
+	if len(report.Rules) == 0 {
+		return
+	}
+
+	fmt.Println("\n开仓规则价值（被拒绝的预测按假设止损止盈模拟，收益为未加杠杆的价格变化%）:")
+	printGate := func(g tracker.GateValue) {
+		fmt.Printf("  %-18s | 记录 %4d | 已模拟 %4d | 止盈/止损/到期 %d/%d/%d | 胜率 %6.2f%% | 平均 %+6.2f%% | 合计 %+8.2f%%",
+			g.Rule, g.Count, g.Simulated, g.TakeProfits, g.StopLosses, g.Timeouts, g.WinRate*100, g.AvgPnLPct, g.TotalPnLPct)
+	}
+	printGate(report.Executed)
+	fmt.Println("  ← 基准（实际开仓）")
+	for _, g := range report.Rules {
+		printGate(g)
+		verdict := "✅ 有效"
+		if g.Simulated == 0 {
+			verdict = "⏳ 待评估"
+		} else if g.Value < 0 {
+			verdict = "⚠️ 挡掉了盈利机会"
+		}
+		fmt.Printf("  → 价值 %+8.2f%% %s\n", g.Value, verdict)
+	}
+}
+
 func parseSince(raw string) (time.Time, error) {
 	if raw == "" {
 		return time.Time{}, nil
@@ -0,0 +1,157 @@
+package tracker
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"nofx/market"
+)
+
+// 模拟交易结果
+const (
+	SimTakeProfit = "take_profit"
+	SimStopLoss   = "stop_loss"
+	SimTimeout    = "timeout" // 到预测目标时间都没有触发，按最后收盘价平仓
+)
+
+// simulateEntry 🚧 按评估区间的K线模拟开仓结果（同一根K线同时触及止损和止盈时按止损计，偏保守）
+func simulateEntry(record *PredictionRecord, klines []market.Kline) {
+	if record.StopLoss <= 0 || record.TakeProfit <= 0 || record.EntryPrice <= 0 || len(klines) == 0 {
+		return
+	}
+	if record.Prediction == nil || (record.Prediction.Direction != "up" && record.Prediction.Direction != "down") {
+		return
+	}
+	long := record.Prediction.Direction == "up"
+
+	exit := klines[len(klines)-1].Close
+	record.SimOutcome = SimTimeout
+	record.SimExitTime = time.UnixMilli(klines[len(klines)-1].CloseTime)
+	for _, k := range klines {
+		hitStop := (long && k.Low <= record.StopLoss) || (!long && k.High >= record.StopLoss)
+		hitTarget := (long && k.High >= record.TakeProfit) || (!long && k.Low <= record.TakeProfit)
+		if hitStop {
+			exit, record.SimOutcome = record.StopLoss, SimStopLoss
+		} else if hitTarget {
+			exit, record.SimOutcome = record.TakeProfit, SimTakeProfit
+		} else {
+			continue
+		}
+		record.SimExitTime = time.UnixMilli(k.CloseTime)
+		break
+	}
+
+	record.SimPnLPct = (exit - record.EntryPrice) / record.EntryPrice * 100
+	if !long {
+		record.SimPnLPct = -record.SimPnLPct
+	}
+}
+
+// GateValue 单条开仓规则的价值统计
+type GateValue struct {
+	Rule        string  `json:"rule"`
+	Count       int     `json:"count"`     // 被该规则拒绝的预测数（基准为实际开仓数）
+	Simulated   int     `json:"simulated"` // 已完成模拟的数量
+	TakeProfits int     `json:"take_profits"`
+	StopLosses  int     `json:"stop_losses"`
+	Timeouts    int     `json:"timeouts"`
+	WinRate     float64 `json:"win_rate"`      // 模拟收益>0的比例
+	AvgPnLPct   float64 `json:"avg_pnl_pct"`   // 平均模拟收益（价格变化%，未加杠杆）
+	TotalPnLPct float64 `json:"total_pnl_pct"` // 模拟收益合计
+	// Value 规则价值 = -合计模拟收益：为正说明被拒绝的开仓整体会亏钱，规则在起作用；为负说明规则挡掉了盈利机会（基准不计算）
+	Value float64 `json:"value"`
+}
+
+// GateReport 开仓闸门价值报告
+type GateReport struct {
+	Since    time.Time   `json:"since,omitempty"`
+	Executed GateValue   `json:"executed"` // 基准：实际开仓的预测按同样方式模拟
+	Rules    []GateValue `json:"rules"`    // 按规则价值从高到低
+}
+
+// GateValueReport 统计每条开仓规则拒绝的预测在事后的模拟表现（只统计since之后的记录，零值=全部）
+func (pt *PredictionTracker) GateValueReport(since time.Time) *GateReport {
+	report := &GateReport{Since: since, Executed: GateValue{Rule: "executed"}}
+	byRule := make(map[string]*GateValue)
+
+	for _, record := range pt.loadRecords() {
+		if record.Timestamp.Before(since) {
+			continue
+		}
+		var gv *GateValue
+		switch {
+		case record.Executed:
+			gv = &report.Executed
+		case record.RejectRule != "":
+			if gv = byRule[record.RejectRule]; gv == nil {
+				gv = &GateValue{Rule: record.RejectRule}
+				byRule[record.RejectRule] = gv
+			}
+		default:
+			continue // 旧记录没有规则信息
+		}
+
+		gv.Count++
+		if record.SimOutcome == "" {
+			continue
+		}
+		gv.Simulated++
+		gv.TotalPnLPct += record.SimPnLPct
+		if record.SimPnLPct > 0 {
+			gv.WinRate++
+		}
+		switch record.SimOutcome {
+		case SimTakeProfit:
+			gv.TakeProfits++
+		case SimStopLoss:
+			gv.StopLosses++
+		default:
+			gv.Timeouts++
+		}
+	}
+
+	finish := func(gv *GateValue) {
+		if gv.Simulated > 0 {
+			gv.WinRate /= float64(gv.Simulated)
+			gv.AvgPnLPct = gv.TotalPnLPct / float64(gv.Simulated)
+		}
+	}
+	finish(&report.Executed)
+	for _, gv := range byRule {
+		finish(gv)
+		gv.Value = 0 - gv.TotalPnLPct
+		report.Rules = append(report.Rules, *gv)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool {
+		return report.Rules[i].Value > report.Rules[j].Value
+	})
+	return report
+}
+
+// loadRecords 读取全部预测记录
+func (pt *PredictionTracker) loadRecords() []PredictionRecord {
+	entries, err := os.ReadDir(pt.dataDir)
+	if err != nil {
+		return nil
+	}
+
+	var records []PredictionRecord
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(pt.dataDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var record PredictionRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
@@ -0,0 +1,91 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// minModelSamples 模型准确率参与加权所需的最少样本数
+const minModelSamples = 10
+
+// ModelAccuracy 单个模型的历史预测表现（来自集成预测中的投票记录）
+type ModelAccuracy struct {
+	Model    string  `json:"model"`
+	Samples  int     `json:"samples"`
+	Correct  int     `json:"correct"`
+	WinRate  float64 `json:"win_rate"`
+	Reliable bool    `json:"reliable"` // 样本数是否足够（≥10）
+}
+
+// GetModelAccuracy 按模型统计方向准确率
+// 每条已评估的集成预测记录中，各模型的投票方向与实际涨跌分别比对
+func (pt *PredictionTracker) GetModelAccuracy() map[string]*ModelAccuracy {
+	result := make(map[string]*ModelAccuracy)
+
+	files, err := ioutil.ReadDir(pt.dataDir)
+	if err != nil {
+		return result
+	}
+
+	for _, file := range files {
+		if filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(pt.dataDir, file.Name()))
+		if err != nil {
+			continue
+		}
+
+		var record PredictionRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			continue
+		}
+		if !record.Evaluated || record.Prediction == nil {
+			continue
+		}
+
+		for _, vote := range record.Prediction.Votes {
+			acc, ok := result[vote.Model]
+			if !ok {
+				acc = &ModelAccuracy{Model: vote.Model}
+				result[vote.Model] = acc
+			}
+			acc.Samples++
+			if directionCorrect(vote.Direction, record.ActualMove) {
+				acc.Correct++
+			}
+		}
+	}
+
+	for _, acc := range result {
+		acc.WinRate = float64(acc.Correct) / float64(acc.Samples)
+		acc.Reliable = acc.Samples >= minModelSamples
+	}
+	return result
+}
+
+// GetModelAccuracySummary 模型准确率摘要（用于思维链展示）
+func (pt *PredictionTracker) GetModelAccuracySummary() string {
+	accuracy := pt.GetModelAccuracy()
+	if len(accuracy) == 0 {
+		return ""
+	}
+
+	models := make([]string, 0, len(accuracy))
+	for model := range accuracy {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+
+	parts := make([]string, 0, len(models))
+	for _, model := range models {
+		acc := accuracy[model]
+		parts = append(parts, fmt.Sprintf("%s %.0f%% (%d/%d)", model, acc.WinRate*100, acc.Correct, acc.Samples))
+	}
+	return strings.Join(parts, " | ")
+}
@@ -62,6 +62,21 @@ type PredictionRecord struct {
 	// 🆕 记录所有预测（包括被拒绝的）
 	Executed     bool   `json:"executed"`      // 是否实际开仓
 	RejectReason string `json:"reject_reason"` // 拒绝原因（如果未执行）
+
+	// 🚧 开仓闸门模拟：被拒绝时为假设的止损止盈，评估时按K线模拟先触发哪一个
+	RejectRule  string    `json:"reject_rule,omitempty"` // 拒绝规则（probability/risk_validation/entry_timing等）
+	StopLoss    float64   `json:"stop_loss,omitempty"`
+	TakeProfit  float64   `json:"take_profit,omitempty"`
+	SimOutcome  string    `json:"sim_outcome,omitempty"` // take_profit/stop_loss/timeout
+	SimPnLPct   float64   `json:"sim_pnl_pct,omitempty"` // 模拟收益（价格变化%，未加杠杆）
+	SimExitTime time.Time `json:"sim_exit_time,omitempty"`
+}
+
+// EntryPlan 开仓计划（被拒绝的规则 + 止损止盈，用于事后模拟闸门价值）
+type EntryPlan struct {
+	Rule       string
+	StopLoss   float64
+	TakeProfit float64
 }
 
 // Record 记录一次预测（已执行的开仓）
@@ -96,6 +111,11 @@ func (pt *PredictionTracker) Record(prediction *types.Prediction, currentPrice f
 // RecordAll 记录所有预测（包括被拒绝的）
 // 用于全面评估AI预测准确率
 func (pt *PredictionTracker) RecordAll(prediction *types.Prediction, currentPrice float64, executed bool, rejectReason string) error {
+	return pt.RecordEntry(prediction, currentPrice, executed, rejectReason, EntryPlan{})
+}
+
+// RecordEntry 记录预测及其开仓计划（止损止盈有效时评估阶段会模拟交易结果）
+func (pt *PredictionTracker) RecordEntry(prediction *types.Prediction, currentPrice float64, executed bool, rejectReason string, plan EntryPlan) error {
 	// 生成唯一ID（使用纳秒避免同一秒多个预测冲突）
 	id := fmt.Sprintf("%s_%d_%d", prediction.Symbol, time.Now().Unix(), time.Now().Nanosecond())
 
@@ -112,6 +132,9 @@ func (pt *PredictionTracker) RecordAll(prediction *types.Prediction, currentPric
 		Evaluated:    false,
 		Executed:     executed,
 		RejectReason: rejectReason,
+		RejectRule:   plan.Rule,
+		StopLoss:     plan.StopLoss,
+		TakeProfit:   plan.TakeProfit,
 	}
 
 	// 保存到文件
@@ -184,6 +207,7 @@ func (pt *PredictionTracker) EvaluatePending() error {
 
 		// 评估预测
 		pt.evaluateRecord(&record, actualData)
+		simulateEntry(&record, actualData.Klines)
 
 		// 保存更新后的记录
 		updatedData, _ := json.MarshalIndent(record, "", "  ")
@@ -198,6 +222,7 @@ type ActualPriceData struct {
 	FinalPrice float64
 	HighPrice  float64
 	LowPrice   float64
+	Klines     []market.Kline // 评估区间的K线（回退到实时价时为空）
 }
 
 // getActualPriceData 获取实际价格数据
@@ -235,6 +260,7 @@ func (pt *PredictionTracker) getActualPriceData(symbol string, startTime, endTim
 		FinalPrice: final,
 		HighPrice:  high,
 		LowPrice:   low,
+		Klines:     klines,
 	}, nil
 }
 
@@ -247,15 +273,7 @@ func (pt *PredictionTracker) evaluateRecord(record *PredictionRecord, actualData
 
 	// 判断方向是否正确
 	pred := record.Prediction
-	if pred.Direction == "up" && record.ActualMove > 0 {
-		record.IsCorrect = true
-	} else if pred.Direction == "down" && record.ActualMove < 0 {
-		record.IsCorrect = true
-	} else if pred.Direction == "neutral" && math.Abs(record.ActualMove) < 1.0 {
-		record.IsCorrect = true
-	} else {
-		record.IsCorrect = false
-	}
+	record.IsCorrect = directionCorrect(pred.Direction, record.ActualMove)
 
 	// 计算准确度（预测幅度 vs 实际幅度）
 	if pred.ExpectedMove != 0 {
@@ -269,6 +287,19 @@ func (pt *PredictionTracker) evaluateRecord(record *PredictionRecord, actualData
 	record.EvaluatedTime = time.Now()
 }
 
+// directionCorrect 预测方向与实际涨跌是否一致（neutral要求波动<1%）
+func directionCorrect(direction string, actualMove float64) bool {
+	switch direction {
+	case "up":
+		return actualMove > 0
+	case "down":
+		return actualMove < 0
+	case "neutral":
+		return math.Abs(actualMove) < 1.0
+	}
+	return false
+}
+
 // GetPerformance 获取历史预测表现
 func (pt *PredictionTracker) GetPerformance(symbol string) *types.HistoricalPerformance {
 	files, err := ioutil.ReadDir(pt.dataDir)
@@ -680,12 +711,12 @@ func parseFloat(val interface{}) (float64, error) {
 
 // CalibrationData 校准数据
 type CalibrationData struct {
-	Symbol            string  // 币种
-	SampleSize        int     // 样本数量
-	CalibrationFactor float64 // 校准因子（实际准确率/预测置信度）
+	Symbol             string  // 币种
+	SampleSize         int     // 样本数量
+	CalibrationFactor  float64 // 校准因子（实际准确率/预测置信度）
 	OverconfidenceBias float64 // 过度自信偏差
-	DirectionAccuracy float64 // 方向准确率
-	MagnitudeAccuracy float64 // 幅度准确率
+	DirectionAccuracy  float64 // 方向准确率
+	MagnitudeAccuracy  float64 // 幅度准确率
 }
 
 // GetCalibrationFactor 获取预测校准因子
@@ -790,12 +821,12 @@ func (pt *PredictionTracker) GetCalibrationFactor(symbol string) *CalibrationDat
 	overconfidenceBias := float64(overconfidentCount) / float64(len(records))
 
 	return &CalibrationData{
-		Symbol:            symbol,
-		SampleSize:        len(records),
-		CalibrationFactor: calibrationFactor,
+		Symbol:             symbol,
+		SampleSize:         len(records),
+		CalibrationFactor:  calibrationFactor,
 		OverconfidenceBias: overconfidenceBias,
-		DirectionAccuracy: actualAccuracy,
-		MagnitudeAccuracy: 1.0 - avgMagnitudeError,
+		DirectionAccuracy:  actualAccuracy,
+		MagnitudeAccuracy:  1.0 - avgMagnitudeError,
 	}
 }
 
@@ -0,0 +1,56 @@
+package types
+
+// FactorAttribution 单个币种的决策因子归因（为什么开仓/跳过/持有/平仓）
+// 随决策记录一起保存，用于复盘时直接查看每一步风控和计算结果，而不必从日志和思维链里拼凑
+type FactorAttribution struct {
+	Symbol  string `json:"symbol"`
+	Stage   string `json:"stage"`            // "position"（持仓管理）/ "entry"（寻找新机会）
+	Verdict string `json:"verdict"`          // "open", "skip", "hold", "close"
+	Reason  string `json:"reason,omitempty"` // 最终结论的原因（拒绝原因/平仓原因）
+
+	// 预测
+	Direction      string  `json:"direction,omitempty"`
+	Probability    float64 `json:"probability,omitempty"`     // 校准后概率
+	RawProbability float64 `json:"raw_probability,omitempty"` // AI原始概率（校准前）
+	Confidence     string  `json:"confidence,omitempty"`
+	RiskLevel      string  `json:"risk_level,omitempty"`
+	BestCase       float64 `json:"best_case,omitempty"`  // 风险计算调整后的最好情况(%)
+	WorstCase      float64 `json:"worst_case,omitempty"` // 风险计算调整后的最坏情况(%)
+
+	// 阈值
+	MinProbability float64 `json:"min_probability,omitempty"` // 实际应用的概率阈值（含账户亏损加严）
+	AccountPnLPct  float64 `json:"account_pnl_pct"`           // 账户累计盈亏%（决定概率阈值加严档位）
+
+	// 风险计算
+	ATRPct          float64 `json:"atr_pct,omitempty"`
+	RiskReward      float64 `json:"risk_reward,omitempty"`    // 盈亏比
+	KellyFraction   float64 `json:"kelly_fraction,omitempty"` // 全凯利比例
+	AppliedKelly    float64 `json:"applied_kelly,omitempty"`  // 实际使用的凯利比例（1/4凯利）
+	PositionSizeUSD float64 `json:"position_size_usd,omitempty"`
+	Leverage        int     `json:"leverage,omitempty"`
+	StopLoss        float64 `json:"stop_loss,omitempty"`
+	TakeProfit      float64 `json:"take_profit,omitempty"`
+
+	// 入场时机
+	EntryTiming string `json:"entry_timing,omitempty"` // 入场时机结论（如 immediate / wait_pullback / rejected）
+
+	Checks []RiskCheck `json:"checks,omitempty"` // 按执行顺序的各项检查
+}
+
+// RiskCheck 单项检查结果
+type RiskCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// AddCheck 追加一项检查结果
+func (f *FactorAttribution) AddCheck(name string, passed bool, detail string) {
+	f.Checks = append(f.Checks, RiskCheck{Name: name, Passed: passed, Detail: detail})
+}
+
+// Skip 标记为跳过并记录原因
+func (f *FactorAttribution) Skip(reason string) {
+	f.Verdict = "skip"
+	f.Reason = reason
+}
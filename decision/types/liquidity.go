@@ -0,0 +1,9 @@
+package types
+
+// FilteredSymbol 因流动性不足被过滤、本周期未交给AI分析的候选币种
+type FilteredSymbol struct {
+	Symbol   string  `json:"symbol"`
+	Filter   string  `json:"filter"`    // "oi"（持仓价值）/ "volume"（24h成交额）
+	ValueUSD float64 `json:"value_usd"` // 实际值（USD）
+	MinUSD   float64 `json:"min_usd"`   // 阈值（USD）
+}
@@ -3,22 +3,34 @@ package types
 // Prediction AI的预测结果
 type Prediction struct {
 	Symbol       string   `json:"symbol"`
-	Direction    string   `json:"direction"`      // "up", "down", "neutral"
-	Probability  float64  `json:"probability"`    // 0-1的概率
-	ExpectedMove float64  `json:"expected_move"`  // 预期涨跌幅(%)
-	Timeframe    string   `json:"timeframe"`      // "1h", "4h", "24h"
-	Confidence   string   `json:"confidence"`     // "very_high", "high", "medium", "low"
-	Reasoning    string   `json:"reasoning"`      // 预测依据
-	KeyFactors   []string `json:"key_factors"`    // 关键因素
-	RiskLevel    string   `json:"risk_level"`     // "low", "medium", "high"
-	WorstCase    float64  `json:"worst_case"`     // 最坏情况跌幅(%)
-	BestCase     float64  `json:"best_case"`      // 最好情况涨幅(%)
+	Direction    string   `json:"direction"`     // "up", "down", "neutral"
+	Probability  float64  `json:"probability"`   // 0-1的概率
+	ExpectedMove float64  `json:"expected_move"` // 预期涨跌幅(%)
+	Timeframe    string   `json:"timeframe"`     // "1h", "4h", "24h"
+	Confidence   string   `json:"confidence"`    // "very_high", "high", "medium", "low"
+	Reasoning    string   `json:"reasoning"`     // 预测依据
+	KeyFactors   []string `json:"key_factors"`   // 关键因素
+	RiskLevel    string   `json:"risk_level"`    // "low", "medium", "high"
+	WorstCase    float64  `json:"worst_case"`    // 最坏情况跌幅(%)
+	BestCase     float64  `json:"best_case"`     // 最好情况涨幅(%)
+
+	// 🗳️ 多模型集成预测
+	Model string      `json:"model,omitempty"` // 产生该预测的模型（集成预测为 "ensemble"）
+	Votes []ModelVote `json:"votes,omitempty"` // 各模型的投票（用于按模型统计准确率）
+}
+
+// ModelVote 集成预测中单个模型的投票
+type ModelVote struct {
+	Model       string  `json:"model"`
+	Direction   string  `json:"direction"`
+	Probability float64 `json:"probability"`
+	Weight      float64 `json:"weight"` // 投票权重（多数投票时为1，加权模式为历史方向准确率）
 }
 
 // HistoricalPerformance 历史预测表现
 type HistoricalPerformance struct {
 	OverallWinRate float64 `json:"overall_win_rate"`
-	SymbolWinRate  float64 `json:"symbol_win_rate"`  // 该币种的胜率
-	AvgAccuracy    float64 `json:"avg_accuracy"`     // 平均准确度
-	CommonMistakes string  `json:"common_mistakes"`  // 常见错误
+	SymbolWinRate  float64 `json:"symbol_win_rate"` // 该币种的胜率
+	AvgAccuracy    float64 `json:"avg_accuracy"`    // 平均准确度
+	CommonMistakes string  `json:"common_mistakes"` // 常见错误
 }
@@ -0,0 +1,168 @@
+// Package errs 交易所与AI调用错误的统一分类
+// 各交易所适配器把错误码映射为 Kind，重试/告警/跳过逻辑按 Kind 判断，不再匹配错误信息子串
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+)
+
+// Kind 错误类别（实现 error 接口，可直接用于 errors.Is(err, errs.RateLimited)）
+type Kind int
+
+const (
+	Unknown            Kind = iota
+	RateLimited             // 限流/IP封禁（-1003、HTTP 429/418）
+	InsufficientMargin      // 保证金不足（-2019）
+	InvalidSymbol           // 交易对不存在或不可交易（-1121、-4140）
+	PrecisionError          // 数量/价格精度或最小下单量不符合规则（-1111、-1013、-4164）
+	Transient               // 网络抖动、超时、交易所内部错误（-1001、-1007、HTTP 5xx）
+	ClockSkew               // 时间戳超出recvWindow（-1021）
+	Maintenance             // 交易所维护
+	NoChange                // 目标状态已生效（如杠杆/保证金模式无需修改）
+	OrderNotFound           // 订单不存在（-2011、-2013）
+	AIParseError            // AI响应无法解析为决策
+	AITimeout               // AI请求超时
+)
+
+var kindNames = map[Kind]string{
+	Unknown:            "unknown",
+	RateLimited:        "rate_limited",
+	InsufficientMargin: "insufficient_margin",
+	InvalidSymbol:      "invalid_symbol",
+	PrecisionError:     "precision_error",
+	Transient:          "transient",
+	ClockSkew:          "clock_skew",
+	Maintenance:        "maintenance",
+	NoChange:           "no_change",
+	OrderNotFound:      "order_not_found",
+	AIParseError:       "ai_parse_error",
+	AITimeout:          "ai_timeout",
+}
+
+// String 类别名称（写入决策日志和状态API）
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
+func (k Kind) Error() string { return k.String() }
+
+// Retryable 该类别是否为瞬时错误（稍后重试可能成功）
+func (k Kind) Retryable() bool {
+	switch k {
+	case RateLimited, Transient, ClockSkew, AITimeout:
+		return true
+	}
+	return false
+}
+
+// Error 带类别和交易所错误码的错误（Error() 保持原始错误信息不变）
+type Error struct {
+	Kind Kind
+	Code int64 // 交易所错误码（0=无）
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is 使 errors.Is(err, kind) 按类别匹配
+func (e *Error) Is(target error) bool {
+	k, ok := target.(Kind)
+	return ok && k == e.Kind
+}
+
+// Wrap 给错误标注类别（err为nil或kind为Unknown时原样返回）
+func Wrap(kind Kind, code int64, err error) error {
+	if err == nil || kind == Unknown {
+		return err
+	}
+	return &Error{Kind: kind, Code: code, Err: err}
+}
+
+// New 创建带类别的错误
+func New(kind Kind, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// KindOf 错误链中最外层的类别（未分类的网络错误按 Transient 处理）
+func KindOf(err error) Kind {
+	if err == nil {
+		return Unknown
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Network(err)
+}
+
+// CodeOf 错误链中的交易所错误码（无则返回0）
+func CodeOf(err error) int64 {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return 0
+}
+
+// Is 错误是否属于指定类别之一
+func Is(err error, kinds ...Kind) bool {
+	kind := KindOf(err)
+	for _, k := range kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Retryable 错误是否值得重试
+func Retryable(err error) bool {
+	return KindOf(err).Retryable()
+}
+
+// Network 识别传输层错误（超时、连接重置/拒绝、DNS失败、连接提前关闭），其他返回 Unknown
+func Network(err error) Kind {
+	if err == nil {
+		return Unknown
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Transient
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Transient
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return Transient
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return Transient
+	}
+	return Unknown
+}
+
+// HTTPStatus 按HTTP状态码分类（交易所未返回错误码时使用）
+func HTTPStatus(status int) Kind {
+	switch {
+	case status == 429 || status == 418:
+		return RateLimited
+	case status == 503:
+		return Maintenance
+	case status >= 500:
+		return Transient
+	}
+	return Unknown
+}
@@ -0,0 +1,253 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Type 事件类型
+type Type string
+
+const (
+	PositionOpened   Type = "position_opened"   // 开仓成功
+	PositionClosed   Type = "position_closed"   // 主动平仓成功
+	StopTriggered    Type = "stop_triggered"    // 止损/止盈/强平在交易所侧触发（持仓消失）
+	DecisionRejected Type = "decision_rejected" // 决策执行被拒绝（风控拦截、下单失败）
+	RiskPaused       Type = "risk_paused"       // 触发风控暂停交易
+	AccountAnomaly   Type = "account_anomaly"   // 账户快照异常（外部持仓、杠杆被修改、止损缺失、余额异常减少）
+	CycleStarted     Type = "cycle_started"     // 决策周期开始
+	PredictionDone   Type = "prediction_done"   // 单个币种AI预测完成（含最终结论）
+	DecisionExecuted Type = "decision_executed" // 决策执行成功（含观察模式假设执行）
+	StopMoved        Type = "stop_moved"        // 移动止损更新
+	FlatWindow       Type = "flat_window"       // 定时空仓窗口即将开始/开始/结束
+)
+
+// Event 交易器内部事件（JSON字段用于WebSocket推送）
+type Event interface {
+	EventType() Type
+}
+
+// PositionOpenedEvent 开仓成功
+type PositionOpenedEvent struct {
+	TraderID   string    `json:"trader_id"`
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"` // long/short
+	Quantity   float64   `json:"quantity"`
+	Price      float64   `json:"price"`
+	Leverage   int       `json:"leverage"`
+	StopLoss   float64   `json:"stop_loss"`
+	TakeProfit float64   `json:"take_profit"`
+	Time       time.Time `json:"time"`
+}
+
+// PositionClosedEvent 主动平仓成功
+type PositionClosedEvent struct {
+	TraderID    string    `json:"trader_id"`
+	Symbol      string    `json:"symbol"`
+	Side        string    `json:"side"`
+	Price       float64   `json:"price"`
+	RealizedPnL float64   `json:"realized_pnl"`
+	Reason      string    `json:"reason"`
+	Time        time.Time `json:"time"`
+}
+
+// StopTriggeredEvent 持仓在未经平仓决策的情况下消失（止损/止盈/强平）
+type StopTriggeredEvent struct {
+	TraderID    string    `json:"trader_id"`
+	Cycle       int       `json:"cycle"`
+	Symbol      string    `json:"symbol"`
+	Side        string    `json:"side"`
+	Trigger     string    `json:"trigger"`   // "stop_loss" / "take_profit" / "liquidation" / "manual"
+	Confirmed   bool      `json:"confirmed"` // true=来自交易所订单历史，false=按最后一次盈亏推断
+	EntryPrice  float64   `json:"entry_price"`
+	LastPrice   float64   `json:"last_price"` // 持仓消失前最后一次观察到的标记价格
+	ReturnPct   float64   `json:"return_pct"` // 收益率（%，已确认成交时按成交价计算，否则为最后一次观察值）
+	Leverage    int       `json:"leverage"`
+	PositionPct float64   `json:"position_pct"` // 保证金占净值比例（%）
+	HoldMinutes int       `json:"hold_minutes"`
+	Time        time.Time `json:"time"`
+
+	TriggerPrice float64 `json:"trigger_price,omitempty"` // 条件单触发价（未确认或非条件单为0）
+	FillPrice    float64 `json:"fill_price,omitempty"`    // 实际成交均价（未确认为0）
+	SlippagePct  float64 `json:"slippage_pct,omitempty"`  // 相对触发价的不利滑点（%）
+}
+
+// DecisionRejectedEvent 决策执行失败
+type DecisionRejectedEvent struct {
+	TraderID string    `json:"trader_id"`
+	Symbol   string    `json:"symbol"`
+	Action   string    `json:"action"`
+	Reason   string    `json:"reason"`
+	Time     time.Time `json:"time"`
+}
+
+// RiskPausedEvent 风控暂停交易
+type RiskPausedEvent struct {
+	TraderID string    `json:"trader_id"`
+	Reason   string    `json:"reason"`
+	Until    time.Time `json:"until"`
+	Time     time.Time `json:"time"`
+}
+
+// AccountAnomalyEvent 相邻周期账户快照对比发现的异常
+type AccountAnomalyEvent struct {
+	TraderID string    `json:"trader_id"`
+	Kind     string    `json:"kind"`             // "external_position" / "leverage_changed" / "missing_stop" / "balance_drop"
+	Symbol   string    `json:"symbol,omitempty"` // 账户级异常为空
+	Side     string    `json:"side,omitempty"`
+	Detail   string    `json:"detail"`
+	Time     time.Time `json:"time"`
+}
+
+// CycleStartedEvent 决策周期开始
+type CycleStartedEvent struct {
+	TraderID string    `json:"trader_id"`
+	Cycle    int       `json:"cycle"`
+	Time     time.Time `json:"time"`
+}
+
+// PredictionDoneEvent 单个币种的AI预测及最终结论
+type PredictionDoneEvent struct {
+	TraderID    string    `json:"trader_id"`
+	Cycle       int       `json:"cycle"`
+	Symbol      string    `json:"symbol"`
+	Stage       string    `json:"stage"`   // "entry" / "position"
+	Verdict     string    `json:"verdict"` // "open" / "skip" / "hold" / "close"
+	Direction   string    `json:"direction,omitempty"`
+	Probability float64   `json:"probability,omitempty"`
+	Confidence  string    `json:"confidence,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// DecisionExecutedEvent 决策执行成功
+type DecisionExecutedEvent struct {
+	TraderID     string    `json:"trader_id"`
+	Cycle        int       `json:"cycle"`
+	Symbol       string    `json:"symbol"`
+	Action       string    `json:"action"`
+	Quantity     float64   `json:"quantity,omitempty"`
+	Price        float64   `json:"price,omitempty"`
+	OrderID      int64     `json:"order_id,omitempty"`
+	Hypothetical bool      `json:"hypothetical,omitempty"` // 观察模式假设执行
+	Time         time.Time `json:"time"`
+}
+
+// StopMovedEvent 移动止损更新（OldStop=0表示首次设置）
+type StopMovedEvent struct {
+	TraderID string    `json:"trader_id"`
+	Symbol   string    `json:"symbol"`
+	Side     string    `json:"side"`
+	OldStop  float64   `json:"old_stop"`
+	NewStop  float64   `json:"new_stop"`
+	Time     time.Time `json:"time"`
+}
+
+// FlatWindowEvent 定时空仓窗口（upcoming=开始前通知，started=开始平仓并停止开仓，ended=恢复交易）
+type FlatWindowEvent struct {
+	TraderID string    `json:"trader_id"`
+	Name     string    `json:"name"`
+	Phase    string    `json:"phase"` // "upcoming" / "started" / "ended"
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Time     time.Time `json:"time"`
+}
+
+func (PositionOpenedEvent) EventType() Type   { return PositionOpened }
+func (PositionClosedEvent) EventType() Type   { return PositionClosed }
+func (StopTriggeredEvent) EventType() Type    { return StopTriggered }
+func (DecisionRejectedEvent) EventType() Type { return DecisionRejected }
+func (RiskPausedEvent) EventType() Type       { return RiskPaused }
+func (AccountAnomalyEvent) EventType() Type   { return AccountAnomaly }
+func (CycleStartedEvent) EventType() Type     { return CycleStarted }
+func (PredictionDoneEvent) EventType() Type   { return PredictionDone }
+func (DecisionExecutedEvent) EventType() Type { return DecisionExecuted }
+func (StopMovedEvent) EventType() Type        { return StopMoved }
+func (FlatWindowEvent) EventType() Type       { return FlatWindow }
+
+const subscriberBuffer = 64 // 每个订阅者的事件缓冲（满了丢弃，不阻塞交易流程）
+
+// subscriber 订阅者（独立goroutine按顺序处理事件）
+type subscriber struct {
+	name    string
+	types   map[Type]bool // 空=订阅全部
+	ch      chan Event
+	dropped atomic.Int64
+}
+
+// Bus 进程内事件总线：发布不阻塞，订阅者各自异步处理
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*subscriber]struct{}
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*subscriber]struct{})}
+}
+
+// Subscribe 订阅事件（不指定类型=全部事件），返回取消订阅函数
+// handler 在订阅者自己的goroutine中按发布顺序调用，panic会被恢复并记录
+func (b *Bus) Subscribe(name string, handler func(Event), types ...Type) (unsubscribe func()) {
+	sub := &subscriber{
+		name:  name,
+		types: make(map[Type]bool, len(types)),
+		ch:    make(chan Event, subscriberBuffer),
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		for e := range sub.ch {
+			dispatch(sub.name, handler, e)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, sub)
+			close(sub.ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Publish 发布事件（nil总线安全；订阅者缓冲满时丢弃并计数）
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subs {
+		if len(sub.types) > 0 && !sub.types[e.EventType()] {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			dropped := sub.dropped.Add(1)
+			log.Printf("⚠️  事件订阅者[%s]处理过慢，丢弃%s事件（累计丢弃%d）", sub.name, e.EventType(), dropped)
+		}
+	}
+}
+
+// dispatch 调用订阅者处理函数（隔离panic）
+func dispatch(name string, handler func(Event), e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("❌ 事件订阅者[%s]处理%s事件panic: %v", name, e.EventType(), r)
+		}
+	}()
+	handler(e)
+}
@@ -0,0 +1,59 @@
+package i18n
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+// 支持的语言
+const (
+	ZH = "zh"
+	EN = "en"
+)
+
+var current atomic.Value // string
+
+func init() {
+	current.Store(ZH)
+}
+
+// SetLanguage 设置日志和通知文案的语言（空=中文）
+func SetLanguage(lang string) error {
+	switch lang {
+	case "":
+		lang = ZH
+	case ZH, EN:
+	default:
+		return fmt.Errorf("不支持的语言: %s（可选zh/en）", lang)
+	}
+	current.Store(lang)
+	return nil
+}
+
+// Language 当前语言
+func Language() string {
+	return current.Load().(string)
+}
+
+// T 按当前语言格式化文案（当前语言缺少该条目时回退中文，未登记的key原样输出）
+// 各语言的同一条目必须使用相同顺序和类型的格式化参数，结构化字段（币种、动作、异常类型等）不翻译
+func T(key string, args ...interface{}) string {
+	entry, ok := messages[key]
+	if !ok {
+		return key
+	}
+	format, ok := entry[Language()]
+	if !ok {
+		format = entry[ZH]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Logf 按当前语言输出日志
+func Logf(key string, args ...interface{}) {
+	log.Print(T(key, args...))
+}
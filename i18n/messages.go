@@ -0,0 +1,223 @@
+package i18n
+
+// messages 用户可见的日志和通知文案（key → 语言 → 格式串）
+// 新增条目时中文必填，英文缺失时回退中文
+var messages = map[string]map[string]string{
+	// ==================== 启动/退出 ====================
+	"main.config_loaded": {
+		ZH: "✓ 配置加载成功，共%d个trader参赛",
+		EN: "✓ Config loaded, %d trader(s) configured",
+	},
+	"main.trader_skipped": {
+		ZH: "⏭️  [%d/%d] 跳过未启用的 %s",
+		EN: "⏭️  [%d/%d] Skipping disabled trader %s",
+	},
+	"main.trader_init": {
+		ZH: "📦 [%d/%d] 初始化 %s (%s模型)...",
+		EN: "📦 [%d/%d] Initializing %s (%s model)...",
+	},
+	"main.participants": {
+		ZH: "🏁 竞赛参赛者:",
+		EN: "🏁 Participants:",
+	},
+	"main.participant": {
+		ZH: "  • %s (%s) - 初始资金: %.0f USDT",
+		EN: "  • %s (%s) - initial balance: %.0f USDT",
+	},
+	"main.risk_warning": {
+		ZH: "⚠️  风险提示: AI自动交易有风险，建议小额资金测试！",
+		EN: "⚠️  Risk warning: automated AI trading is risky, test with small funds first!",
+	},
+	"main.press_ctrl_c": {
+		ZH: "按 Ctrl+C 停止运行",
+		EN: "Press Ctrl+C to stop",
+	},
+	"main.shutting_down": {
+		ZH: "📛 收到退出信号，正在停止所有trader...",
+		EN: "📛 Shutdown signal received, stopping all traders...",
+	},
+	"main.goodbye": {
+		ZH: "👋 感谢使用AI交易竞赛系统！",
+		EN: "👋 Thanks for using the AI trading competition system!",
+	},
+
+	// ==================== 交易器生命周期 ====================
+	"trader.started": {
+		ZH: "🚀 AI驱动自动交易系统启动",
+		EN: "🚀 AI-driven auto trading started",
+	},
+	"trader.waiting_first_cycle": {
+		ZH: "⏰ 等待第一个决策周期（%v后）...",
+		EN: "⏰ Waiting for the first decision cycle (in %v)...",
+	},
+	"trader.interval_adjusted": {
+		ZH: "⏰ 扫描周期调整为 %v",
+		EN: "⏰ Scan interval adjusted to %v",
+	},
+	"trader.stopped": {
+		ZH: "⏹ 自动交易系统停止",
+		EN: "⏹ Auto trading stopped",
+	},
+
+	// ==================== 决策周期 ====================
+	"cycle.header": {
+		ZH: "⏰ %s - AI决策周期 #%d",
+		EN: "⏰ %s - AI decision cycle #%d",
+	},
+	"cycle.paused_manual": {
+		ZH: "⏸  [%s] 已手动暂停，跳过本周期",
+		EN: "⏸  [%s] Paused manually, skipping this cycle",
+	},
+	"cycle.exchange_unreachable": {
+		ZH: "🔌 [%s] 交易所失联中，跳过本周期",
+		EN: "🔌 [%s] Exchange unreachable, skipping this cycle",
+	},
+	"cycle.risk_paused": {
+		ZH: "⏸ 风险控制：暂停交易中，剩余 %.0f 分钟",
+		EN: "⏸ Risk control: trading paused, %.0f minutes remaining",
+	},
+	"cycle.daily_reset": {
+		ZH: "📅 日盈亏已重置",
+		EN: "📅 Daily PnL reset",
+	},
+	"cycle.account": {
+		ZH: "📊 账户净值: %.2f USDT | 可用: %.2f USDT | 持仓: %d",
+		EN: "📊 Equity: %.2f USDT | Available: %.2f USDT | Positions: %d",
+	},
+	"cycle.requesting_ai": {
+		ZH: "🤖 正在请求AI分析并决策...",
+		EN: "🤖 Requesting AI analysis and decisions...",
+	},
+	"cycle.cot": {
+		ZH: "💭 AI思维链分析:",
+		EN: "💭 AI chain of thought:",
+	},
+	"cycle.cot_on_error": {
+		ZH: "💭 AI思维链分析（错误情况）:",
+		EN: "💭 AI chain of thought (on error):",
+	},
+	"cycle.decision_list": {
+		ZH: "📋 AI决策列表 (%d 个):",
+		EN: "📋 AI decisions (%d):",
+	},
+	"cycle.decision_params": {
+		ZH: "      杠杆: %dx | 仓位: %.2f USDT | 止损: %.4f | 止盈: %.4f",
+		EN: "      Leverage: %dx | Size: %.2f USDT | Stop loss: %.4f | Take profit: %.4f",
+	},
+	"cycle.execution_order": {
+		ZH: "🔄 执行顺序（已优化）: 先平仓→后开仓",
+		EN: "🔄 Execution order: closes first, then opens",
+	},
+
+	// ==================== 风控 ====================
+	"risk.monitor": {
+		ZH: "📊 风险监控: 日盈亏%.2f%% (限制%.0f%%) | 回撤%.2f%% (限制%.0f%%) | 周收益%.2f%% | 最大回撤%.2f%% | 夏普%.2f",
+		EN: "📊 Risk monitor: daily PnL %.2f%% (limit %.0f%%) | drawdown %.2f%% (limit %.0f%%) | weekly return %.2f%% | max drawdown %.2f%% | Sharpe %.2f",
+	},
+	"risk.daily_loss_triggered": {
+		ZH: "🛑 风险控制触发: 日亏损%.2f%% 超过限制%.0f%%, 暂停交易%.0f分钟",
+		EN: "🛑 Risk control triggered: daily loss %.2f%% exceeds limit %.0f%%, pausing trading for %.0f minutes",
+	},
+	"risk.daily_loss_paused": {
+		ZH: "日亏损%.2f%% 超限，暂停交易",
+		EN: "Daily loss %.2f%% over limit, trading paused",
+	},
+	"risk.drawdown_triggered": {
+		ZH: "🛑 风险控制触发: 回撤%.2f%% 超过限制%.0f%%, 暂停交易%.0f分钟",
+		EN: "🛑 Risk control triggered: drawdown %.2f%% exceeds limit %.0f%%, pausing trading for %.0f minutes",
+	},
+	"risk.drawdown_paused": {
+		ZH: "回撤%.2f%% 超限，暂停交易",
+		EN: "Drawdown %.2f%% over limit, trading paused",
+	},
+
+	// ==================== 执行 ====================
+	"exec.open_long": {
+		ZH: "  📈 开多仓: %s",
+		EN: "  📈 Opening long: %s",
+	},
+	"exec.open_short": {
+		ZH: "  📉 开空仓: %s",
+		EN: "  📉 Opening short: %s",
+	},
+	"exec.close_long": {
+		ZH: "  🔄 平多仓: %s",
+		EN: "  🔄 Closing long: %s",
+	},
+	"exec.close_short": {
+		ZH: "  🔄 平空仓: %s",
+		EN: "  🔄 Closing short: %s",
+	},
+	"exec.opened": {
+		ZH: "  ✓ 开仓成功，订单ID: %v, 数量: %.4f",
+		EN: "  ✓ Position opened, order ID: %v, quantity: %.4f",
+	},
+	"exec.closed": {
+		ZH: "  ✓ 平仓成功",
+		EN: "  ✓ Position closed",
+	},
+	"exec.failed": {
+		ZH: "❌ 执行决策失败 (%s %s): %v",
+		EN: "❌ Decision execution failed (%s %s): %v",
+	},
+	"exec.log_failed": {
+		ZH: "❌ %s %s 失败: %v",
+		EN: "❌ %s %s failed: %v",
+	},
+	"exec.log_hypothetical": {
+		ZH: "👁️ %s %s 假设执行（观察模式）",
+		EN: "👁️ %s %s hypothetical execution (observe mode)",
+	},
+	"exec.log_success": {
+		ZH: "✓ %s %s 成功",
+		EN: "✓ %s %s succeeded",
+	},
+	"exec.retry_queued": {
+		ZH: "  ⏳ %s %s 瞬时错误，稍后重试: %v",
+		EN: "  ⏳ %s %s transient error, will retry: %v",
+	},
+	"exec.retry_batch": {
+		ZH: "🔁 %d个开仓决策因瞬时错误失败，进入重试队列",
+		EN: "🔁 %d open decision(s) failed with transient errors, queued for retry",
+	},
+	"exec.retry_attempt": {
+		ZH: "  🔁 第%d次重试 %s %s（上次错误: %v）",
+		EN: "  🔁 Retry #%d %s %s (last error: %v)",
+	},
+	"exec.retry_succeeded": {
+		ZH: "🔁 %s %s 第%d次重试成功",
+		EN: "🔁 %s %s succeeded on retry #%d",
+	},
+
+	// ==================== 持仓变化 ====================
+	"position.closed_by_bot": {
+		ZH: "📤 持仓已主动平仓: %s %s | 入场价 %.4f | 上次价格 %.4f | 未实现盈亏 %.2f%%",
+		EN: "📤 Position closed by bot: %s %s | entry %.4f | last price %.4f | unrealized PnL %.2f%%",
+	},
+	"position.vanished": {
+		ZH: "🚨 检测到持仓消失，可能为止损/强平触发: %s %s | 入场价 %.4f | 上次价格 %.4f | 未实现盈亏 %.2f%%",
+		EN: "🚨 Position disappeared, likely stop/liquidation triggered: %s %s | entry %.4f | last price %.4f | unrealized PnL %.2f%%",
+	},
+
+	// ==================== 账户异常 ====================
+	"anomaly.entry": {
+		ZH: "🕵️ 账户异常[%s] %s %s: %s",
+		EN: "🕵️ Account anomaly [%s] %s %s: %s",
+	},
+	"anomaly.external_position": {
+		ZH: "出现非本系统开仓的持仓（数量%.4f, %dx），标记为外部管理",
+		EN: "position not opened by the bot appeared (qty %.4f, %dx), marked as externally managed",
+	},
+	"anomaly.leverage_changed": {
+		ZH: "杠杆被外部修改 %dx → %dx，标记为外部管理",
+		EN: "leverage changed externally %dx → %dx, marked as externally managed",
+	},
+	"anomaly.missing_stop": {
+		ZH: "交易所上没有止损单（记录止损%.4f）",
+		EN: "no stop-loss order on the exchange (recorded stop %.4f)",
+	},
+	"anomaly.balance_drop": {
+		ZH: "钱包余额%.2f → %.2f USDT（-%.2f%%），期间没有平仓可以解释（划转/提现/未知扣费？）",
+		EN: "wallet balance %.2f → %.2f USDT (-%.2f%%) with no closes to explain it (transfer/withdrawal/unknown fee?)",
+	},
+}
@@ -0,0 +1,286 @@
+package logger
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveDirName 归档子目录（decision_logs/<id>/archive/decision_YYYYMM.tar.gz）
+const archiveDirName = "archive"
+
+// recordFileTime 从文件名解析记录时间：decision_YYYYMMDD_HHMMSS_cycleN.json
+func recordFileTime(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, "decision_") || !strings.HasSuffix(name, ".json") {
+		return time.Time{}, false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(name, "decision_"), "_", 3)
+	if len(parts) < 2 {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("20060102150405", parts[0]+parts[1], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func (l *DecisionLogger) archiveDir() string {
+	return filepath.Join(l.logDir, archiveDirName)
+}
+
+func (l *DecisionLogger) archivePath(month string) string {
+	return filepath.Join(l.archiveDir(), fmt.Sprintf("decision_%s.tar.gz", month))
+}
+
+// ArchiveOldRecords 把N天前的决策记录按月压缩归档（decision_YYYYMM.tar.gz），返回归档条数
+// 最新一条记录始终保留在日志目录中，保证重启后能恢复周期编号
+func (l *DecisionLogger) ArchiveOldRecords(days int) (int, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	entries, err := os.ReadDir(l.logDir)
+	if err != nil {
+		return 0, fmt.Errorf("读取日志目录失败: %w", err)
+	}
+
+	latest := ""
+	var latestTime time.Time
+	byMonth := make(map[string][]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		t, ok := recordFileTime(entry.Name())
+		if !ok {
+			continue
+		}
+		if t.After(latestTime) {
+			latestTime, latest = t, entry.Name()
+		}
+		if t.Before(cutoff) {
+			month := t.Format("200601")
+			byMonth[month] = append(byMonth[month], entry.Name())
+		}
+	}
+
+	if err := os.MkdirAll(l.archiveDir(), 0755); err != nil {
+		return 0, fmt.Errorf("创建归档目录失败: %w", err)
+	}
+
+	archived := 0
+	for month, names := range byMonth {
+		names = removeName(names, latest)
+		if len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+		if err := l.appendToArchive(month, names); err != nil {
+			return archived, fmt.Errorf("归档%s失败: %w", month, err)
+		}
+		// 归档写入成功后再删除原文件
+		for _, name := range names {
+			if err := os.Remove(filepath.Join(l.logDir, name)); err != nil {
+				fmt.Printf("⚠ 删除已归档记录失败 %s: %v\n", name, err)
+			}
+		}
+		archived += len(names)
+	}
+
+	if archived > 0 {
+		fmt.Printf("🗜️ 已归档 %d 条决策记录（%d天前）\n", archived, days)
+	}
+	return archived, nil
+}
+
+func removeName(names []string, target string) []string {
+	for i, name := range names {
+		if name == target {
+			return append(names[:i], names[i+1:]...)
+		}
+	}
+	return names
+}
+
+// appendToArchive 追加记录到月度归档：先复制已有归档内容再写入新文件，写临时文件后原子替换
+func (l *DecisionLogger) appendToArchive(month string, names []string) error {
+	path := l.archivePath(month)
+	tmpPath := path + ".tmp"
+
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	gw := gzip.NewWriter(out)
+	tw := tar.NewWriter(gw)
+
+	existing := make(map[string]bool)
+	if err := forEachArchived(path, func(hdr *tar.Header, r io.Reader) error {
+		existing[hdr.Name] = true
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := io.Copy(tw, r)
+		return err
+	}); err != nil && !os.IsNotExist(err) {
+		out.Close()
+		return fmt.Errorf("读取已有归档失败: %w", err)
+	}
+
+	for _, name := range names {
+		if existing[name] {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(l.logDir, name))
+		if err != nil {
+			out.Close()
+			return err
+		}
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+		if t, ok := recordFileTime(name); ok {
+			hdr.ModTime = t
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			out.Close()
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			out.Close()
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// forEachArchived 遍历归档中的每个文件
+func forEachArchived(path string, fn func(hdr *tar.Header, r io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(hdr, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// PurgeArchives 删除超过保留期的月度归档（整月都早于保留期才删除），返回删除的归档数
+func (l *DecisionLogger) PurgeArchives(retentionDays int) (int, error) {
+	if retentionDays <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+
+	removed := 0
+	for _, month := range l.archivedMonths() {
+		start, err := time.ParseInLocation("200601", month, time.Local)
+		if err != nil || !start.AddDate(0, 1, 0).Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(l.archivePath(month)); err != nil {
+			fmt.Printf("⚠ 删除过期归档失败 %s: %v\n", month, err)
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		fmt.Printf("🗑️ 已删除 %d 个过期归档（保留%d天）\n", removed, retentionDays)
+	}
+	return removed, nil
+}
+
+// archivedMonths 已归档月份（从新到旧）
+func (l *DecisionLogger) archivedMonths() []string {
+	matches, err := filepath.Glob(filepath.Join(l.archiveDir(), "decision_*.tar.gz"))
+	if err != nil {
+		return nil
+	}
+	months := make([]string, 0, len(matches))
+	for _, m := range matches {
+		month := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), "decision_"), ".tar.gz")
+		months = append(months, month)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(months)))
+	return months
+}
+
+// readArchivedRecords 读取月度归档中的记录（prefix 非空时只读取文件名匹配的记录）
+func (l *DecisionLogger) readArchivedRecords(month, prefix string) ([]*DecisionRecord, error) {
+	var records []*DecisionRecord
+	err := forEachArchived(l.archivePath(month), func(hdr *tar.Header, r io.Reader) error {
+		if prefix != "" && !strings.HasPrefix(hdr.Name, prefix) {
+			return nil
+		}
+		var record DecisionRecord
+		if err := json.NewDecoder(r).Decode(&record); err != nil {
+			return nil
+		}
+		records = append(records, &record)
+		return nil
+	})
+	return records, err
+}
+
+// loadArchivedRecords 从归档补足最近的记录（按周期号从新到旧，最多need条，仅读取需要的月份）
+func (l *DecisionLogger) loadArchivedRecords(need int) []*DecisionRecord {
+	var result []*DecisionRecord
+	for _, month := range l.archivedMonths() {
+		if len(result) >= need {
+			break
+		}
+		records, err := l.readArchivedRecords(month, "")
+		if err != nil {
+			fmt.Printf("⚠ 读取归档失败 %s: %v\n", month, err)
+			continue
+		}
+		sort.Slice(records, func(i, j int) bool {
+			return records[i].CycleNumber > records[j].CycleNumber
+		})
+		if remaining := need - len(result); len(records) > remaining {
+			records = records[:remaining]
+		}
+		result = append(result, records...)
+	}
+	return result
+}
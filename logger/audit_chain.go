@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"nofx/secrets"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// 🔗 决策记录审计哈希链：每条记录写入上一条记录的哈希（prev_hash）和自身哈希（hash），
+// 事后修改、删除、插入任何一条记录都会使校验失败（删除最新记录需要与外部保存的链头比对）
+// 哈希 = sha256(不含hash字段的记录JSON，与写入文件时的缩进格式相同)，因此DecisionRecord新增字段必须带omitempty
+
+// recordHash 计算记录哈希（Hash字段置空后序列化）
+func recordHash(record *DecisionRecord) (string, error) {
+	clone := *record
+	clone.Hash = ""
+	clone.Outcome = nil // 读取时附加的字段，不写入文件
+	data, err := json.MarshalIndent(&clone, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("序列化决策记录失败: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sealRecord 把记录接到链头之后并计算哈希（按脱敏后写入文件的内容计算，与校验时读取的内容一致）
+func (l *DecisionLogger) sealRecord(record *DecisionRecord) error {
+	record.PrevHash = l.chainHead
+	record.Hash = ""
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化决策记录失败: %w", err)
+	}
+	var stored DecisionRecord
+	if err := json.Unmarshal(secrets.RedactBytes(data), &stored); err != nil {
+		return fmt.Errorf("解析决策记录失败: %w", err)
+	}
+	hash, err := recordHash(&stored)
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+	return nil
+}
+
+// archivedChainHead 月度归档中最近一条带哈希的记录（从最新的月份往前找）
+func (l *DecisionLogger) archivedChainHead() *DecisionRecord {
+	for _, month := range l.archivedMonths() {
+		records, err := l.readArchivedRecords(month, "")
+		if err != nil {
+			fmt.Printf("⚠ 读取归档%s失败: %v\n", month, err)
+			continue
+		}
+		var head *DecisionRecord
+		for _, record := range records {
+			if record.Hash != "" && (head == nil || record.Timestamp.After(head.Timestamp)) {
+				head = record
+			}
+		}
+		if head != nil {
+			return head
+		}
+	}
+	return nil
+}
+
+// EnableAuditChain 启用审计哈希链（之后写入的记录接在最近一条带哈希的记录之后）
+func (l *DecisionLogger) EnableAuditChain() {
+	l.chainMu.Lock()
+	defer l.chainMu.Unlock()
+	l.auditChain = true
+	if l.chainHead != "" {
+		fmt.Printf("🔗 审计哈希链已启用，接续链头 %s（周期 %d）\n", l.chainHead[:12], l.chainHeadCycle)
+	} else {
+		fmt.Printf("🔗 审计哈希链已启用，从下一条记录开始\n")
+	}
+}
+
+// AuditChainHead 当前链头哈希和周期（可定期保存到外部，用于发现末尾记录被删除）
+func (l *DecisionLogger) AuditChainHead() (hash string, cycle int, enabled bool) {
+	l.chainMu.Lock()
+	defer l.chainMu.Unlock()
+	return l.chainHead, l.chainHeadCycle, l.auditChain
+}
+
+// AuditProblem 校验发现的问题
+type AuditProblem struct {
+	File  string `json:"file"`
+	Cycle int    `json:"cycle"`
+	Issue string `json:"issue"`
+}
+
+// AuditReport 哈希链校验结果
+type AuditReport struct {
+	Records    int            `json:"records"`     // 决策记录总数（含归档）
+	Chained    int            `json:"chained"`     // 带哈希的记录数
+	Unchained  int            `json:"unchained"`   // 启用哈希链之前的记录数（不在校验范围内）
+	Truncated  bool           `json:"truncated"`   // 链起点之前的记录已被清理（无法核对起点的prev_hash）
+	FirstCycle int            `json:"first_cycle"` // 链上第一条记录的周期
+	HeadCycle  int            `json:"head_cycle"`  // 链头周期
+	HeadHash   string         `json:"head_hash"`   // 链头哈希
+	Problems   []AuditProblem `json:"problems,omitempty"`
+}
+
+// OK 是否未发现问题
+func (r *AuditReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// auditEntry 校验用的记录及其来源文件
+type auditEntry struct {
+	file   string
+	record DecisionRecord
+}
+
+// VerifyAuditChain 校验日志目录（含月度归档）中决策记录的哈希链
+// expectedHead 非空时额外核对链头（与外部保存的链头比对，发现末尾记录被删除）
+func VerifyAuditChain(logDir, expectedHead string) (*AuditReport, error) {
+	entries, corrupt, err := readAuditEntries(logDir)
+	if err != nil {
+		return nil, err
+	}
+	// 按写入时间排序（LogDecision写入时设置Timestamp，周期号作为并列时的次序）
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].record, entries[j].record
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return a.CycleNumber < b.CycleNumber
+	})
+
+	report := &AuditReport{Records: len(entries) + len(corrupt), Problems: corrupt}
+	problem := func(e auditEntry, format string, args ...interface{}) {
+		report.Problems = append(report.Problems, AuditProblem{File: e.file, Cycle: e.record.CycleNumber, Issue: fmt.Sprintf(format, args...)})
+	}
+
+	prev := ""
+	for _, e := range entries {
+		if e.record.Hash == "" {
+			if report.Chained == 0 {
+				report.Unchained++
+			} else {
+				problem(e, "链开始后出现不带哈希的记录（哈希被删除或期间关闭了audit_chain）")
+			}
+			continue
+		}
+
+		hash, err := recordHash(&e.record)
+		if err != nil {
+			return nil, err
+		}
+		if hash != e.record.Hash {
+			problem(e, "内容与哈希不符（记录被修改）")
+		}
+		if report.Chained == 0 {
+			report.FirstCycle = e.record.CycleNumber
+			// 链起点之前的记录已不存在（清理/归档过期删除），从此处开始校验
+			report.Truncated = e.record.PrevHash != ""
+		} else if e.record.PrevHash != prev {
+			problem(e, "prev_hash与上一条记录不符（前面的记录被删除、插入或修改）")
+		}
+		prev = e.record.Hash
+		report.Chained++
+		report.HeadCycle = e.record.CycleNumber
+		report.HeadHash = e.record.Hash
+	}
+
+	if expectedHead != "" && report.HeadHash != expectedHead {
+		found := false
+		for _, e := range entries {
+			if e.record.Hash == expectedHead {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report.Problems = append(report.Problems, AuditProblem{Issue: fmt.Sprintf("未找到外部保存的链头 %s（记录被删除或修改）", expectedHead)})
+		}
+	}
+	return report, nil
+}
+
+// readAuditEntries 读取日志目录和月度归档中的全部决策记录（无法解析的文件作为问题返回）
+func readAuditEntries(logDir string) (entries []auditEntry, corrupt []AuditProblem, err error) {
+	files, err := os.ReadDir(logDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取日志目录失败: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if _, ok := recordFileTime(file.Name()); !ok {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(logDir, file.Name()))
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取%s失败: %w", file.Name(), err)
+		}
+		var record DecisionRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			corrupt = append(corrupt, AuditProblem{File: file.Name(), Issue: fmt.Sprintf("无法解析（记录被破坏）: %v", err)})
+			continue
+		}
+		entries = append(entries, auditEntry{file: file.Name(), record: record})
+	}
+
+	l := &DecisionLogger{logDir: logDir}
+	for _, month := range l.archivedMonths() {
+		err := forEachArchived(l.archivePath(month), func(hdr *tar.Header, r io.Reader) error {
+			if _, ok := recordFileTime(hdr.Name); !ok {
+				return nil
+			}
+			name := filepath.Join(filepath.Base(l.archivePath(month)), hdr.Name)
+			var record DecisionRecord
+			if err := json.NewDecoder(r).Decode(&record); err != nil {
+				corrupt = append(corrupt, AuditProblem{File: name, Issue: fmt.Sprintf("无法解析（记录被破坏）: %v", err)})
+				return nil
+			}
+			entries = append(entries, auditEntry{file: name, record: record})
+			return nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取归档%s失败: %w", month, err)
+		}
+	}
+	return entries, corrupt, nil
+}
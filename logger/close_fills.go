@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CloseFillRecord 持仓在交易所侧消失时的平仓审计记录（止损/止盈/强平/手动平仓）
+type CloseFillRecord struct {
+	Time         time.Time `json:"time"` // 触发时间（条件单触发后立即市价成交，取成交时间）
+	Symbol       string    `json:"symbol"`
+	Side         string    `json:"side"`
+	Reason       string    `json:"reason"` // stop_loss / take_profit / liquidation / manual / unknown
+	Source       string    `json:"source"` // exchange=来自订单历史，inferred=查询失败时按最后盈亏推断
+	OrderID      int64     `json:"order_id,omitempty"`
+	OrderType    string    `json:"order_type,omitempty"`
+	TriggerPrice float64   `json:"trigger_price,omitempty"` // 条件单触发价（市价/强平为0）
+	FillPrice    float64   `json:"fill_price,omitempty"`    // 实际成交均价
+	Quantity     float64   `json:"quantity,omitempty"`
+	SlippagePct  float64   `json:"slippage_pct"` // 相对触发价的不利滑点（%，正数=成交比触发价差）
+	EntryPrice   float64   `json:"entry_price"`
+	LastPrice    float64   `json:"last_price"` // 持仓消失前最后一次观察到的标记价格
+}
+
+func (l *DecisionLogger) closeFillPath() string {
+	return filepath.Join(l.logDir, "audit", "close_fills.jsonl")
+}
+
+// LogCloseFill 追加一条平仓审计记录（JSONL，保存在子目录中，避免被当作决策记录读取）
+func (l *DecisionLogger) LogCloseFill(record *CloseFillRecord) error {
+	l.closeFillMu.Lock()
+	defer l.closeFillMu.Unlock()
+
+	path := l.closeFillPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建审计目录失败: %w", err)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("序列化平仓审计记录失败: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开平仓审计文件失败: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入平仓审计记录失败: %w", err)
+	}
+	return nil
+}
+
+// GetCloseFills 获取最近N条平仓审计记录（按时间倒序）
+func (l *DecisionLogger) GetCloseFills(limit int) ([]*CloseFillRecord, error) {
+	l.closeFillMu.Lock()
+	defer l.closeFillMu.Unlock()
+
+	f, err := os.Open(l.closeFillPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("打开平仓审计文件失败: %w", err)
+	}
+	defer f.Close()
+
+	var records []*CloseFillRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record CloseFillRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		records = append(records, &record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取平仓审计文件失败: %w", err)
+	}
+
+	// 倒序（最新在前）
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"sort"
+	"time"
+)
+
+// DecisionOutcome 📈 一次开仓的最终结果（平仓盈亏按成交台账归因到开仓决策）
+type DecisionOutcome struct {
+	DecisionID string    `json:"decision_id"`
+	Cycle      int       `json:"cycle"`
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	Strategy   string    `json:"strategy"`
+	OpenTime   time.Time `json:"open_time"`
+	CloseTime  time.Time `json:"close_time,omitempty"` // 最后一次平仓时间
+	Quantity   float64   `json:"quantity"`
+	PnL        float64   `json:"pnl"`            // 已实现盈亏（含分批平仓）
+	Risk       float64   `json:"risk,omitempty"` // 开仓时按止损计算的风险（USDT）
+	R          float64   `json:"r,omitempty"`    // PnL / Risk（无止损记录时为0）
+	Closed     bool      `json:"closed"`         // 是否已全部平仓
+	Reasoning  string    `json:"reasoning,omitempty"`
+
+	remaining float64
+}
+
+// CycleOutcome 一个决策周期开出的仓位的最终盈亏
+type CycleOutcome struct {
+	Trades []*DecisionOutcome `json:"trades"`
+	PnL    float64            `json:"pnl"`
+	R      float64            `json:"r"`    // 各笔R值之和
+	Open   int                `json:"open"` // 尚未全部平仓的笔数（结果未定）
+}
+
+// GetDecisionOutcomes 按成交台账把平仓盈亏归因到开仓决策（同币种同方向的平仓记到最近一次开仓）
+// 读取全部台账：开仓可能早于查询区间
+func (l *DecisionLogger) GetDecisionOutcomes() ([]*DecisionOutcome, error) {
+	entries, err := l.GetLedgerEntries(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	var outcomes []*DecisionOutcome
+	open := make(map[string]*DecisionOutcome)
+	for _, e := range entries {
+		key := e.Symbol + "_" + e.Side
+		if e.Action == "open" {
+			if e.Cycle <= 0 {
+				delete(open, key) // 非决策开仓（基差策略等），其后的平仓不归因
+				continue
+			}
+			t := &DecisionOutcome{
+				DecisionID: e.DecisionID,
+				Cycle:      e.Cycle,
+				Symbol:     e.Symbol,
+				Side:       e.Side,
+				Strategy:   e.Strategy,
+				OpenTime:   e.Time,
+				Quantity:   e.Quantity,
+				Risk:       e.Risk,
+				remaining:  e.Quantity,
+			}
+			open[key] = t
+			outcomes = append(outcomes, t)
+			continue
+		}
+
+		t, ok := open[key]
+		if !ok {
+			continue
+		}
+		t.PnL += e.PnL
+		t.CloseTime = e.Time
+		if t.Risk > 0 {
+			t.R = t.PnL / t.Risk
+		}
+		t.remaining -= e.Quantity
+		// 全部平仓（数量未知或剩余不足1%视为已平完）
+		if e.Quantity <= 0 || t.remaining <= t.Quantity*0.01 {
+			t.Closed = true
+			delete(open, key)
+		}
+	}
+	return outcomes, nil
+}
+
+// AttachOutcomes 给决策记录附上本周期开仓的最终盈亏，并用开仓决策的理由补全交易结果
+func (l *DecisionLogger) AttachOutcomes(records []*DecisionRecord) error {
+	outcomes, err := l.GetDecisionOutcomes()
+	if err != nil {
+		return err
+	}
+	byCycle := GroupOutcomesByCycle(outcomes)
+	for _, record := range records {
+		outcome, ok := byCycle[record.CycleNumber]
+		if !ok {
+			continue
+		}
+		fillReasoning(outcome.Trades, record)
+		record.Outcome = outcome
+	}
+	return nil
+}
+
+// GroupOutcomesByCycle 按开仓周期汇总交易结果
+func GroupOutcomesByCycle(outcomes []*DecisionOutcome) map[int]*CycleOutcome {
+	byCycle := make(map[int]*CycleOutcome)
+	for _, t := range outcomes {
+		c, ok := byCycle[t.Cycle]
+		if !ok {
+			c = &CycleOutcome{}
+			byCycle[t.Cycle] = c
+		}
+		c.Trades = append(c.Trades, t)
+		c.PnL += t.PnL
+		c.R += t.R
+		if !t.Closed {
+			c.Open++
+		}
+	}
+	return byCycle
+}
+
+// RankOutcomes 已平仓的交易按最终R值排序（R相同或无止损记录时按盈亏），best=true时最好的在前
+func RankOutcomes(outcomes []*DecisionOutcome, records []*DecisionRecord, best bool) []*DecisionOutcome {
+	byCycle := make(map[int]*DecisionRecord, len(records))
+	for _, record := range records {
+		byCycle[record.CycleNumber] = record
+	}
+
+	var closed []*DecisionOutcome
+	for _, t := range outcomes {
+		if !t.Closed {
+			continue
+		}
+		if record, ok := byCycle[t.Cycle]; ok {
+			fillReasoning([]*DecisionOutcome{t}, record)
+		}
+		closed = append(closed, t)
+	}
+	sort.SliceStable(closed, func(i, j int) bool {
+		a, b := closed[i], closed[j]
+		if a.R != b.R {
+			return (a.R > b.R) == best
+		}
+		return (a.PnL > b.PnL) == best
+	})
+	return closed
+}
+
+// fillReasoning 从开仓周期的决策记录中找到对应决策的理由
+func fillReasoning(trades []*DecisionOutcome, record *DecisionRecord) {
+	for _, t := range trades {
+		if t.Reasoning != "" {
+			continue
+		}
+		for _, d := range record.Decisions {
+			if d.DecisionID != "" && d.DecisionID == t.DecisionID {
+				t.Reasoning = d.Reasoning
+				break
+			}
+		}
+	}
+}
@@ -5,26 +5,64 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
+	"nofx/decision/types"
+	"nofx/secrets"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
 // DecisionRecord 决策记录
 type DecisionRecord struct {
-	Timestamp      time.Time          `json:"timestamp"`       // 决策时间
-	CycleNumber    int                `json:"cycle_number"`    // 周期编号
-	InputPrompt    string             `json:"input_prompt"`    // 发送给AI的输入prompt
-	CoTTrace       string             `json:"cot_trace"`       // AI思维链（输出）
-	DecisionJSON   string             `json:"decision_json"`   // 决策JSON
-	AccountState   AccountSnapshot    `json:"account_state"`   // 账户状态快照
-	Positions      []PositionSnapshot `json:"positions"`       // 持仓快照
-	CandidateCoins []string           `json:"candidate_coins"` // 候选币种列表
-	Decisions      []DecisionAction   `json:"decisions"`       // 执行的决策
-	ExecutionLog   []string           `json:"execution_log"`   // 执行日志
-	Success        bool               `json:"success"`         // 是否成功
-	ErrorMessage   string             `json:"error_message"`   // 错误信息（如果有）
+	Timestamp      time.Time          `json:"timestamp"`              // 决策时间
+	CycleNumber    int                `json:"cycle_number"`           // 周期编号
+	InputPrompt    string             `json:"input_prompt"`           // 发送给AI的输入prompt
+	CoTTrace       string             `json:"cot_trace"`              // AI思维链（输出）
+	DecisionJSON   string             `json:"decision_json"`          // 决策JSON
+	AccountState   AccountSnapshot    `json:"account_state"`          // 账户状态快照
+	Positions      []PositionSnapshot `json:"positions"`              // 持仓快照
+	CandidateCoins []string           `json:"candidate_coins"`        // 候选币种列表
+	Decisions      []DecisionAction   `json:"decisions"`              // 执行的决策
+	ExecutionLog   []string           `json:"execution_log"`          // 执行日志
+	Success        bool               `json:"success"`                // 是否成功
+	ErrorMessage   string             `json:"error_message"`          // 错误信息（如果有）
+	ObserveMode    bool               `json:"observe_mode,omitempty"` // 👁️ 观察模式周期（决策未实际执行）
+
+	// 🕵️ 与上一周期账户快照对比发现的异常（外部持仓、杠杆被修改、止损缺失、余额异常减少）
+	AccountAnomalies []AccountAnomaly `json:"account_anomalies,omitempty"`
+
+	// 🧾 决策因子归因：每个币种开仓/跳过/持有/平仓的原因及各项风控检查、RR、ATR%、凯利比例等
+	Attributions []types.FactorAttribution `json:"attributions,omitempty"`
+
+	// 💧 因流动性不足（持仓价值/24h成交额低于阈值）被过滤、未交给AI分析的候选币种
+	FilteredSymbols []types.FilteredSymbol `json:"filtered_symbols,omitempty"`
+
+	// ⌛ 本周期AI决策超出时间预算，剩余预测被中止（持仓默认持有）
+	AITimedOut bool `json:"ai_timed_out,omitempty"`
+
+	// 🛟 本周期AI决策失败，执行了确定性兜底策略（只管理已有持仓，不开新仓）
+	AIFallback bool `json:"ai_fallback,omitempty"`
+
+	// 🏷️ 周期失败的错误类别（ai_timeout/ai_parse_error/rate_limited等）
+	ErrorKind string `json:"error_kind,omitempty"`
+
+	// 📸 执行决策时的市场快照文件（snapshots/目录下，可按周期号读取）
+	SnapshotFile string `json:"snapshot_file,omitempty"`
+
+	// 📝 本周期生效的提示词模板版本（关联决策结果与提示词变更）
+	PromptVersion string `json:"prompt_version,omitempty"`
+
+	// ⏱️ 各阶段耗时（毫秒）：balance/positions/candidates/market_data/performance/ai/execution等，total为周期总耗时
+	StageTimings map[string]int64 `json:"stage_timings_ms,omitempty"`
+
+	// 📈 本周期开出的仓位的最终盈亏（读取时按成交台账归因，不写入决策文件）
+	Outcome *CycleOutcome `json:"outcome,omitempty"`
+
+	// 🔗 审计哈希链（启用audit_chain时）：上一条记录的哈希和本记录的哈希
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
 }
 
 // AccountSnapshot 账户状态快照
@@ -48,6 +86,14 @@ type PositionSnapshot struct {
 	LiquidationPrice float64 `json:"liquidation_price"`
 }
 
+// AccountAnomaly 账户异常
+type AccountAnomaly struct {
+	Kind   string `json:"kind"` // external_position/leverage_changed/missing_stop/balance_drop
+	Symbol string `json:"symbol,omitempty"`
+	Side   string `json:"side,omitempty"`
+	Detail string `json:"detail"`
+}
+
 // DecisionAction 决策动作
 type DecisionAction struct {
 	Action    string    `json:"action"`    // open_long, open_short, close_long, close_short
@@ -60,12 +106,88 @@ type DecisionAction struct {
 	Success   bool      `json:"success"`   // 是否成功
 	Error     string    `json:"error"`     // 错误信息
 	Reasoning string    `json:"reasoning"` // ✅ NEW: 平仓原因
+
+	ClientOrderID string `json:"client_order_id,omitempty"` // 🔁 客户端订单ID（幂等键）
+
+	// 🆕 费用明细（用于计算净盈亏）
+	Commission float64 `json:"commission,omitempty"`  // 本次成交手续费（USDT，正数=支出）
+	FundingFee float64 `json:"funding_fee,omitempty"` // 持仓期间资金费（仅平仓记录，正数=收取，负数=支付）
+
+	// 👁️ 观察模式：假设执行（未下单）
+	Hypothetical bool `json:"hypothetical,omitempty"`
+
+	// 开仓设置的止损止盈（观察模式下为本应设置的值）
+	StopLoss   float64 `json:"stop_loss,omitempty"`
+	TakeProfit float64 `json:"take_profit,omitempty"`
+
+	// 🎟️ 本次开仓使用了冷却期豁免
+	CooldownOverride bool `json:"cooldown_override,omitempty"`
+
+	// 🚦 决策策略的否决/修改说明
+	PolicyNotes []string `json:"policy_notes,omitempty"`
+
+	// 🏷️ 策略来源（ai/limit/basis/manual，平仓为开仓时的策略），同时编码在客户端订单ID中
+	Strategy    string  `json:"strategy,omitempty"`
+	RealizedPnL float64 `json:"realized_pnl,omitempty"` // 平仓已实现盈亏（交易所返回）
+
+	// 🎯 开仓时AI信心度（0-100）与按信心度调整仓位的倍数（1=未调整）
+	Confidence     int     `json:"confidence,omitempty"`
+	SizeMultiplier float64 `json:"size_multiplier,omitempty"`
+
+	// 🔁 执行次数（瞬时错误重试时>1）
+	Attempts int `json:"attempts,omitempty"`
+
+	// 🏷️ 执行失败的错误类别（rate_limited/insufficient_margin/invalid_symbol/precision_error等）
+	ErrorKind string `json:"error_kind,omitempty"`
+
+	// 📈 决策ID（周期:币种:动作），开仓后的平仓盈亏按此归因
+	DecisionID string `json:"decision_id,omitempty"`
+
+	// 🧐 大仓位开仓前的AI自我审查（第二次调用）
+	Critique *SelfCritique `json:"critique,omitempty"`
+
+	// ⏱️ 预测快照到下单前检查的延迟，快照价格，以及期间价格变动（止损距离的倍数）
+	DecisionLatencyMs int64   `json:"decision_latency_ms,omitempty"`
+	SnapshotPrice     float64 `json:"snapshot_price,omitempty"`
+	PriceMoveR        float64 `json:"price_move_r,omitempty"`
+}
+
+// SelfCritique 自我审查记录：提交的决策与风险计算、审查模型的回复和结论
+type SelfCritique struct {
+	Trigger    string   `json:"trigger"`              // 触发原因（名义价值/杠杆超过阈值）
+	Proposal   string   `json:"proposal"`             // 提交审查的决策与风险计算
+	Response   string   `json:"response"`             // 审查模型原始回复
+	Verdict    string   `json:"verdict"`              // approve/block/error
+	Objections []string `json:"objections,omitempty"` // 审查提出的问题
+	Summary    string   `json:"summary,omitempty"`
+	Blocked    bool     `json:"blocked"` // 是否因审查拒绝开仓
+	DurationMs int64    `json:"duration_ms"`
 }
 
 // DecisionLogger 决策日志记录器
 type DecisionLogger struct {
 	logDir      string
 	cycleNumber int
+
+	// 🆕 交易所对账修正（懒加载）
+	corrections     *pnlCorrectionStore
+	correctionsOnce sync.Once
+
+	// 🆕 决策记录订阅者（实时推送）
+	subMu       sync.Mutex
+	subscribers map[chan *DecisionRecord]struct{}
+
+	// 🎯 平仓审计记录（止损/止盈/强平的触发价与成交价）
+	closeFillMu sync.Mutex
+
+	// 🏷️ 按策略标签的成交台账
+	ledgerMu sync.Mutex
+
+	// 🔗 审计哈希链（链头为最近一条带哈希的记录）
+	chainMu        sync.Mutex
+	auditChain     bool
+	chainHead      string
+	chainHeadCycle int
 }
 
 // NewDecisionLogger 创建决策日志记录器
@@ -81,6 +203,7 @@ func NewDecisionLogger(logDir string) *DecisionLogger {
 
 	// 🔧 修复：从现有日志文件中读取最大的周期编号，避免重启后周期号重复
 	maxCycleNumber := 0
+	var chainHead *DecisionRecord // 🔗 最近一条带哈希的记录
 	files, err := ioutil.ReadDir(logDir)
 	if err == nil {
 		for _, file := range files {
@@ -103,6 +226,9 @@ func NewDecisionLogger(logDir string) *DecisionLogger {
 			if record.CycleNumber > maxCycleNumber {
 				maxCycleNumber = record.CycleNumber
 			}
+			if record.Hash != "" && (chainHead == nil || record.Timestamp.After(chainHead.Timestamp)) {
+				chainHead = &record
+			}
 		}
 	}
 
@@ -112,10 +238,23 @@ func NewDecisionLogger(logDir string) *DecisionLogger {
 		fmt.Printf("📊 无历史日志，周期编号从 1 开始\n")
 	}
 
-	return &DecisionLogger{
+	l := &DecisionLogger{
 		logDir:      logDir,
 		cycleNumber: maxCycleNumber, // 从历史最大值继续计数
 	}
+	if chainHead == nil {
+		// 🔗 实时记录已全部归档（停机超过归档天数）时从最近的归档接续链头
+		chainHead = l.archivedChainHead()
+		if chainHead != nil && chainHead.CycleNumber > l.cycleNumber {
+			l.cycleNumber = chainHead.CycleNumber
+			fmt.Printf("📊 从归档恢复周期编号，继续从周期 %d 开始\n", l.cycleNumber+1)
+		}
+	}
+	if chainHead != nil {
+		l.chainHead = chainHead.Hash
+		l.chainHeadCycle = chainHead.CycleNumber
+	}
+	return l
 }
 
 // LogDecision 记录决策
@@ -140,18 +279,36 @@ func (l *DecisionLogger) LogDecision(record *DecisionRecord) error {
 
 	filepath := filepath.Join(l.logDir, filename)
 
+	// 🔐 自由文本字段脱敏（API错误信息、AI输出中可能带出密钥）
+	redactRecord(record)
+
+	// 🔗 审计哈希链：接在链头之后，写入成功后更新链头
+	l.chainMu.Lock()
+	defer l.chainMu.Unlock()
+	if l.auditChain {
+		if err := l.sealRecord(record); err != nil {
+			return err
+		}
+	}
+
 	// 序列化为JSON（带缩进，方便阅读）
 	data, err := json.MarshalIndent(record, "", "  ")
 	if err != nil {
 		return fmt.Errorf("序列化决策记录失败: %w", err)
 	}
+	data = secrets.RedactBytes(data)
 
 	// 写入文件
 	if err := ioutil.WriteFile(filepath, data, 0644); err != nil {
 		return fmt.Errorf("写入决策记录失败: %w", err)
 	}
+	if l.auditChain {
+		l.chainHead = record.Hash
+		l.chainHeadCycle = record.CycleNumber
+	}
 
 	fmt.Printf("📝 决策记录已保存: %s\n", filename)
+	l.publish(record)
 	return nil
 }
 
@@ -184,6 +341,11 @@ func (l *DecisionLogger) GetLatestRecords(n int) ([]*DecisionRecord, error) {
 		allRecords = append(allRecords, &record)
 	}
 
+	// 🗜️ 日志目录不足N条时从归档补足（较早的记录已按月压缩归档）
+	if len(allRecords) < n {
+		allRecords = append(allRecords, l.loadArchivedRecords(n-len(allRecords))...)
+	}
+
 	// 按cycle_number降序排序（最新的周期在前）
 	for i := 0; i < len(allRecords); i++ {
 		for j := i + 1; j < len(allRecords); j++ {
@@ -234,6 +396,14 @@ func (l *DecisionLogger) GetRecordByDate(date time.Time) ([]*DecisionRecord, err
 		records = append(records, &record)
 	}
 
+	// 🗜️ 当日记录已归档时从月度归档读取
+	if len(records) == 0 {
+		archived, err := l.readArchivedRecords(date.Format("200601"), fmt.Sprintf("decision_%s_", dateStr))
+		if err == nil {
+			records = archived
+		}
+	}
+
 	return records, nil
 }
 
@@ -336,13 +506,22 @@ type TradeOutcome struct {
 	ClosePrice    float64   `json:"close_price"`    // 平仓价
 	PositionValue float64   `json:"position_value"` // 仓位价值（quantity × openPrice）
 	MarginUsed    float64   `json:"margin_used"`    // 保证金使用（positionValue / leverage）
-	PnL           float64   `json:"pn_l"`           // 盈亏（USDT）
+	GrossPnL      float64   `json:"gross_pn_l"`     // 🆕 毛盈亏（仅价格差，USDT）
+	Fees          float64   `json:"fees"`           // 🆕 开仓+平仓手续费（USDT）
+	FundingFee    float64   `json:"funding_fee"`    // 🆕 持仓期间资金费（USDT）
+	PnL           float64   `json:"pn_l"`           // 净盈亏（USDT，已扣除手续费、计入资金费）
 	PnLPct        float64   `json:"pn_l_pct"`       // 盈亏百分比（相对保证金）
 	Duration      string    `json:"duration"`       // 持仓时长
 	OpenTime      time.Time `json:"open_time"`      // 开仓时间
 	CloseTime     time.Time `json:"close_time"`     // 平仓时间
 	WasStopLoss   bool      `json:"was_stop_loss"`  // 是否止损
 	CloseReason   string    `json:"close_reason"`   // ✅ NEW: 平仓原因
+	Reconciled    bool      `json:"reconciled"`     // 🆕 盈亏已按交易所流水修正
+
+	// 📐 R倍数 = 净盈亏 / 开仓风险（止损距离×数量），开仓未设止损时为0
+	RMultiple float64 `json:"r_multiple,omitempty"`
+
+	Confidence int `json:"confidence,omitempty"` // 🎯 开仓时AI信心度（0=未记录）
 }
 
 // PerformanceAnalysis 交易表现分析
@@ -359,6 +538,20 @@ type PerformanceAnalysis struct {
 	SymbolStats   map[string]*SymbolPerformance `json:"symbol_stats"`   // 各币种表现
 	BestSymbol    string                        `json:"best_symbol"`    // 表现最好的币种
 	WorstSymbol   string                        `json:"worst_symbol"`   // 表现最差的币种
+
+	// 📐 风险调整指标
+	SortinoRatio   float64 `json:"sortino_ratio"`   // 索提诺比率（只计下行波动）
+	Expectancy     float64 `json:"expectancy"`      // 每笔期望收益（USDT）
+	AvgWinR        float64 `json:"avg_win_r"`       // 盈利交易平均R倍数
+	AvgLossR       float64 `json:"avg_loss_r"`      // 亏损交易平均R倍数（负数）
+	ExpectancyR    float64 `json:"expectancy_r"`    // 每笔期望R倍数（仅统计有止损的交易）
+	MaxWinStreak   int     `json:"max_win_streak"`  // 最长连胜
+	MaxLossStreak  int     `json:"max_loss_streak"` // 最长连亏
+	CurrentStreak  int     `json:"current_streak"`  // 当前连胜(+)/连亏(-)
+	LookbackCycles int     `json:"lookback_cycles"` // 统计窗口（周期数）
+
+	// 🎯 按开仓信心度分档（每10分一档，key为档位下限）的胜率，用于校验AI信心度是否可靠
+	ConfidenceStats map[int]*ConfidenceBucketStats `json:"confidence_stats,omitempty"`
 }
 
 // SymbolPerformance 币种表现统计
@@ -381,14 +574,16 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 
 	if len(records) == 0 {
 		return &PerformanceAnalysis{
-			RecentTrades: []TradeOutcome{},
-			SymbolStats:  make(map[string]*SymbolPerformance),
+			RecentTrades:   []TradeOutcome{},
+			SymbolStats:    make(map[string]*SymbolPerformance),
+			LookbackCycles: lookbackCycles,
 		}, nil
 	}
 
 	analysis := &PerformanceAnalysis{
-		RecentTrades: []TradeOutcome{},
-		SymbolStats:  make(map[string]*SymbolPerformance),
+		RecentTrades:   []TradeOutcome{},
+		SymbolStats:    make(map[string]*SymbolPerformance),
+		LookbackCycles: lookbackCycles,
 	}
 
 	// 追踪持仓状态：symbol_side -> {side, openPrice, openTime, quantity, leverage}
@@ -401,7 +596,8 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 		// 先从扩大的窗口中收集所有开仓记录
 		for _, record := range allRecords {
 			for _, action := range record.Decisions {
-				if !action.Success {
+				// 观察模式的假设执行不计入真实交易表现
+				if !action.Success || action.Hypothetical {
 					continue
 				}
 
@@ -418,11 +614,14 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 				case "open_long", "open_short":
 					// 记录开仓
 					openPositions[posKey] = map[string]interface{}{
-						"side":      side,
-						"openPrice": action.Price,
-						"openTime":  action.Timestamp,
-						"quantity":  action.Quantity,
-						"leverage":  action.Leverage,
+						"side":       side,
+						"openPrice":  action.Price,
+						"openTime":   action.Timestamp,
+						"quantity":   action.Quantity,
+						"leverage":   action.Leverage,
+						"commission": action.Commission,
+						"stopLoss":   action.StopLoss,
+						"confidence": action.Confidence,
 					}
 				case "close_long", "close_short":
 					// 移除已平仓记录
@@ -435,7 +634,7 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 	// 遍历分析窗口内的记录，生成交易结果
 	for _, record := range records {
 		for _, action := range record.Decisions {
-			if !action.Success {
+			if !action.Success || action.Hypothetical {
 				continue
 			}
 
@@ -452,11 +651,14 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 			case "open_long", "open_short":
 				// 更新开仓记录（可能已经在预填充时记录过了）
 				openPositions[posKey] = map[string]interface{}{
-					"side":      side,
-					"openPrice": action.Price,
-					"openTime":  action.Timestamp,
-					"quantity":  action.Quantity,
-					"leverage":  action.Leverage,
+					"side":       side,
+					"openPrice":  action.Price,
+					"openTime":   action.Timestamp,
+					"quantity":   action.Quantity,
+					"leverage":   action.Leverage,
+					"commission": action.Commission,
+					"stopLoss":   action.StopLoss,
+					"confidence": action.Confidence,
 				}
 
 			case "close_long", "close_short":
@@ -471,11 +673,24 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 					// 计算实际盈亏（USDT）
 					// 合约交易 PnL 计算：quantity × 价格差
 					// 注意：杠杆不影响绝对盈亏，只影响保证金需求
-					var pnl float64
+					var grossPnL float64
 					if side == "long" {
-						pnl = quantity * (action.Price - openPrice)
+						grossPnL = quantity * (action.Price - openPrice)
 					} else {
-						pnl = quantity * (openPrice - action.Price)
+						grossPnL = quantity * (openPrice - action.Price)
+					}
+
+					// 🆕 净盈亏 = 毛盈亏 - 开仓手续费 - 平仓手续费 + 资金费
+					// 旧日志没有费用字段时为0，退化为毛盈亏
+					openCommission, _ := openPos["commission"].(float64)
+					fees := openCommission + action.Commission
+					pnl := grossPnL - fees + action.FundingFee
+
+					// 🆕 交易所对账修正：以交易所收益流水为准
+					reconciled := false
+					if c, ok := l.GetPnLCorrection(symbol, side, action.Timestamp); ok {
+						pnl = c.ExchangePnL
+						reconciled = true
 					}
 
 					// 计算盈亏百分比（相对保证金）
@@ -486,6 +701,14 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 						pnlPct = (pnl / marginUsed) * 100
 					}
 
+					// 📐 R倍数 = 净盈亏 / 开仓时的止损风险
+					rMultiple := 0.0
+					if stopLoss, _ := openPos["stopLoss"].(float64); stopLoss > 0 {
+						if risk := math.Abs(openPrice-stopLoss) * quantity; risk > 0 {
+							rMultiple = pnl / risk
+						}
+					}
+
 					// 记录交易结果
 					outcome := TradeOutcome{
 						Symbol:        symbol,
@@ -496,13 +719,19 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 						ClosePrice:    action.Price,
 						PositionValue: positionValue,
 						MarginUsed:    marginUsed,
+						GrossPnL:      grossPnL,
+						Fees:          fees,
+						FundingFee:    action.FundingFee,
 						PnL:           pnl,
 						PnLPct:        pnlPct,
+						Reconciled:    reconciled,
 						Duration:      action.Timestamp.Sub(openTime).String(),
 						OpenTime:      openTime,
 						CloseTime:     action.Timestamp,
 						CloseReason:   action.Reasoning, // ✅ NEW: 添加平仓原因
+						RMultiple:     rMultiple,
 					}
+					outcome.Confidence, _ = openPos["confidence"].(int)
 
 					analysis.RecentTrades = append(analysis.RecentTrades, outcome)
 					analysis.TotalTrades++
@@ -564,6 +793,10 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 		}
 	}
 
+	// 📐 期望收益、R倍数和连胜/连亏（按平仓时间顺序）
+	analysis.computeTradeStats()
+	analysis.computeConfidenceStats()
+
 	// 计算各币种胜率和平均盈亏
 	bestPnL := -999999.0
 	worstPnL := 999999.0
@@ -599,6 +832,7 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 
 	// 计算夏普比率（需要至少2个数据点）
 	analysis.SharpeRatio = l.calculateSharpeRatio(records)
+	analysis.SortinoRatio = calculateSortinoRatio(records)
 
 	return analysis, nil
 }
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"nofx/market"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// snapshotDirName 市场快照子目录（decision_logs/<id>/snapshots/snapshot_YYYYMMDD_HHMMSS_cycleN.json.gz）
+const snapshotDirName = "snapshots"
+
+// MarketSnapshot 执行决策时AI看到的完整市场数据（用于复盘和回测数据管道校验）
+type MarketSnapshot struct {
+	CycleNumber  int                             `json:"cycle_number"`
+	Timestamp    time.Time                       `json:"timestamp"`
+	Symbols      []string                        `json:"symbols"` // 本周期执行了决策的币种
+	MarketData   map[string]*market.Data         `json:"market_data"`
+	ExtendedData map[string]*market.ExtendedData `json:"extended_data,omitempty"`
+}
+
+func (l *DecisionLogger) snapshotDir() string {
+	return filepath.Join(l.logDir, snapshotDirName)
+}
+
+// SaveMarketSnapshot 保存市场快照（gzip压缩的JSON），返回文件名
+func (l *DecisionLogger) SaveMarketSnapshot(snapshot *MarketSnapshot) (string, error) {
+	if err := os.MkdirAll(l.snapshotDir(), 0755); err != nil {
+		return "", fmt.Errorf("创建快照目录失败: %w", err)
+	}
+
+	filename := fmt.Sprintf("snapshot_%s_cycle%d.json.gz",
+		snapshot.Timestamp.Format("20060102_150405"), snapshot.CycleNumber)
+	path := filepath.Join(l.snapshotDir(), filename)
+	tmpPath := path + ".tmp"
+
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return "", fmt.Errorf("创建快照文件失败: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	gw := gzip.NewWriter(out)
+	if err := json.NewEncoder(gw).Encode(snapshot); err != nil {
+		out.Close()
+		return "", fmt.Errorf("序列化市场快照失败: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		out.Close()
+		return "", fmt.Errorf("写入市场快照失败: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return "", fmt.Errorf("写入市场快照失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return "", fmt.Errorf("保存市场快照失败: %w", err)
+	}
+	return filename, nil
+}
+
+// LoadMarketSnapshot 按周期号读取市场快照
+func (l *DecisionLogger) LoadMarketSnapshot(cycle int) (*MarketSnapshot, error) {
+	matches, err := filepath.Glob(filepath.Join(l.snapshotDir(), fmt.Sprintf("snapshot_*_cycle%d.json.gz", cycle)))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("周期 %d 没有市场快照", cycle)
+	}
+
+	f, err := os.Open(matches[len(matches)-1])
+	if err != nil {
+		return nil, fmt.Errorf("打开市场快照失败: %w", err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("解压市场快照失败: %w", err)
+	}
+	defer gr.Close()
+
+	var snapshot MarketSnapshot
+	if err := json.NewDecoder(gr).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("解析市场快照失败: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// PurgeSnapshots 删除N天前的市场快照，返回删除数量
+func (l *DecisionLogger) PurgeSnapshots(days int) (int, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	entries, err := os.ReadDir(l.snapshotDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("读取快照目录失败: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		// snapshot_YYYYMMDD_HHMMSS_cycleN.json.gz 与决策记录文件名格式一致
+		name := strings.TrimSuffix(strings.Replace(entry.Name(), "snapshot_", "decision_", 1), ".gz")
+		t, ok := recordFileTime(name)
+		if !ok || !t.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(l.snapshotDir(), entry.Name())); err != nil {
+			fmt.Printf("⚠ 删除过期快照失败 %s: %v\n", entry.Name(), err)
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		fmt.Printf("🗑️ 已删除 %d 个过期市场快照（保留%d天）\n", removed, days)
+	}
+	return removed, nil
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// computeTradeStats 计算期望收益、平均R倍数和连胜/连亏（RecentTrades需按平仓时间正序）
+func (a *PerformanceAnalysis) computeTradeStats() {
+	if len(a.RecentTrades) == 0 {
+		return
+	}
+
+	totalPnL := 0.0
+	winR, lossR, totalR := 0.0, 0.0, 0.0
+	winRCount, lossRCount, rCount := 0, 0, 0
+	streak := 0
+	for _, t := range a.RecentTrades {
+		totalPnL += t.PnL
+
+		if t.RMultiple != 0 {
+			totalR += t.RMultiple
+			rCount++
+			if t.RMultiple > 0 {
+				winR += t.RMultiple
+				winRCount++
+			} else {
+				lossR += t.RMultiple
+				lossRCount++
+			}
+		}
+
+		// 持平交易中断连胜/连亏
+		switch {
+		case t.PnL > 0 && streak > 0:
+			streak++
+		case t.PnL > 0:
+			streak = 1
+		case t.PnL < 0 && streak < 0:
+			streak--
+		case t.PnL < 0:
+			streak = -1
+		default:
+			streak = 0
+		}
+		if streak > a.MaxWinStreak {
+			a.MaxWinStreak = streak
+		}
+		if -streak > a.MaxLossStreak {
+			a.MaxLossStreak = -streak
+		}
+	}
+	a.CurrentStreak = streak
+	a.Expectancy = totalPnL / float64(len(a.RecentTrades))
+
+	if winRCount > 0 {
+		a.AvgWinR = winR / float64(winRCount)
+	}
+	if lossRCount > 0 {
+		a.AvgLossR = lossR / float64(lossRCount)
+	}
+	if rCount > 0 {
+		a.ExpectancyR = totalR / float64(rCount)
+	}
+}
+
+// calculateSortinoRatio 索提诺比率：周期收益均值 / 下行偏差（与夏普一样不年化，无风险利率按0）
+func calculateSortinoRatio(records []*DecisionRecord) float64 {
+	var returns []float64
+	prev := 0.0
+	for _, record := range records {
+		equity := record.AccountState.TotalBalance
+		if equity <= 0 {
+			continue
+		}
+		if prev > 0 {
+			returns = append(returns, (equity-prev)/prev)
+		}
+		prev = equity
+	}
+	if len(returns) == 0 {
+		return 0
+	}
+
+	mean := 0.0
+	downside := 0.0
+	for _, r := range returns {
+		mean += r
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	mean /= float64(len(returns))
+	downsideDev := math.Sqrt(downside / float64(len(returns)))
+
+	if downsideDev == 0 {
+		if mean > 0 {
+			return 999.0 // 没有下行波动的正收益
+		}
+		return 0
+	}
+	return mean / downsideDev
+}
+
+// Summary 绩效摘要（一行，用于AI提示词）
+func (a *PerformanceAnalysis) Summary() string {
+	if a == nil || a.TotalTrades == 0 {
+		return ""
+	}
+
+	parts := []string{
+		fmt.Sprintf("近%d笔胜率%.0f%%", a.TotalTrades, a.WinRate),
+		fmt.Sprintf("盈亏比%.2f", a.ProfitFactor),
+		fmt.Sprintf("期望%+.2f USDT/笔", a.Expectancy),
+		fmt.Sprintf("夏普%.2f", a.SharpeRatio),
+		fmt.Sprintf("索提诺%.2f", a.SortinoRatio),
+	}
+	if a.AvgWinR != 0 || a.AvgLossR != 0 {
+		parts = append(parts, fmt.Sprintf("平均R 盈%+.2f/亏%+.2f（期望%+.2fR）", a.AvgWinR, a.AvgLossR, a.ExpectancyR))
+	}
+	parts = append(parts, fmt.Sprintf("最长连胜%d/连亏%d", a.MaxWinStreak, a.MaxLossStreak))
+	switch {
+	case a.CurrentStreak > 1:
+		parts = append(parts, fmt.Sprintf("当前%d连胜", a.CurrentStreak))
+	case a.CurrentStreak < -1:
+		parts = append(parts, fmt.Sprintf("当前%d连亏", -a.CurrentStreak))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// ConfidenceBucketStats 🎯 某个信心度档位的历史交易表现
+type ConfidenceBucketStats struct {
+	Bucket        int     `json:"bucket"` // 档位下限（如70表示70-79）
+	TotalTrades   int     `json:"total_trades"`
+	WinningTrades int     `json:"winning_trades"`
+	WinRate       float64 `json:"win_rate"` // %
+}
+
+// ConfidenceBucket 信心度所在档位（每10分一档，90-100合为一档）
+func ConfidenceBucket(confidence int) int {
+	if confidence >= 100 {
+		return 90
+	}
+	if confidence < 0 {
+		return 0
+	}
+	return confidence / 10 * 10
+}
+
+// computeConfidenceStats 按开仓信心度分档统计胜率（未记录信心度的交易不计入）
+func (a *PerformanceAnalysis) computeConfidenceStats() {
+	for _, t := range a.RecentTrades {
+		if t.Confidence <= 0 {
+			continue
+		}
+		if a.ConfidenceStats == nil {
+			a.ConfidenceStats = make(map[int]*ConfidenceBucketStats)
+		}
+		bucket := ConfidenceBucket(t.Confidence)
+		stats, ok := a.ConfidenceStats[bucket]
+		if !ok {
+			stats = &ConfidenceBucketStats{Bucket: bucket}
+			a.ConfidenceStats[bucket] = stats
+		}
+		stats.TotalTrades++
+		if t.PnL > 0 {
+			stats.WinningTrades++
+		}
+	}
+	for _, stats := range a.ConfidenceStats {
+		stats.WinRate = float64(stats.WinningTrades) / float64(stats.TotalTrades) * 100
+	}
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PnLCorrection 单笔交易的盈亏修正（以交易所收益流水为准）
+type PnLCorrection struct {
+	Symbol      string    `json:"symbol"`
+	Side        string    `json:"side"`
+	CloseTime   time.Time `json:"close_time"`
+	RecordedPnL float64   `json:"recorded_pnl"` // 机器人内部账本记录的净盈亏
+	ExchangePnL float64   `json:"exchange_pnl"` // 交易所流水计算的净盈亏（已实现+手续费+资金费）
+	CorrectedAt time.Time `json:"corrected_at"`
+}
+
+// pnlCorrectionStore 盈亏修正存储（保存在日志目录的子目录中，避免被当作决策记录读取）
+type pnlCorrectionStore struct {
+	mu          sync.RWMutex
+	path        string
+	corrections map[string]PnLCorrection
+}
+
+// TradeKey 交易唯一键（symbol_side_平仓时间秒）
+func TradeKey(symbol, side string, closeTime time.Time) string {
+	return fmt.Sprintf("%s_%s_%d", symbol, side, closeTime.Unix())
+}
+
+// correctionStore 懒加载修正存储
+func (l *DecisionLogger) correctionStore() *pnlCorrectionStore {
+	l.correctionsOnce.Do(func() {
+		l.corrections = &pnlCorrectionStore{
+			path:        filepath.Join(l.logDir, "reconcile", "pnl_corrections.json"),
+			corrections: make(map[string]PnLCorrection),
+		}
+		data, err := os.ReadFile(l.corrections.path)
+		if err == nil {
+			if err := json.Unmarshal(data, &l.corrections.corrections); err != nil {
+				fmt.Printf("⚠ 解析盈亏修正文件失败: %v\n", err)
+			}
+		}
+	})
+	return l.corrections
+}
+
+// SetPnLCorrections 批量写入盈亏修正并持久化
+func (l *DecisionLogger) SetPnLCorrections(corrections []PnLCorrection) error {
+	if len(corrections) == 0 {
+		return nil
+	}
+
+	store := l.correctionStore()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, c := range corrections {
+		store.corrections[TradeKey(c.Symbol, c.Side, c.CloseTime)] = c
+	}
+
+	if err := os.MkdirAll(filepath.Dir(store.path), 0755); err != nil {
+		return fmt.Errorf("创建对账目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(store.corrections, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化盈亏修正失败: %w", err)
+	}
+	tmpFile := store.path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("写入盈亏修正失败: %w", err)
+	}
+	return os.Rename(tmpFile, store.path)
+}
+
+// GetPnLCorrection 获取某笔交易的修正后盈亏
+func (l *DecisionLogger) GetPnLCorrection(symbol, side string, closeTime time.Time) (PnLCorrection, bool) {
+	store := l.correctionStore()
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	c, ok := store.corrections[TradeKey(symbol, side, closeTime)]
+	return c, ok
+}
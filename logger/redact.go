@@ -0,0 +1,18 @@
+package logger
+
+import "nofx/secrets"
+
+// redactRecord 🔐 决策记录中的自由文本字段脱敏（写盘和推送给订阅者之前）
+func redactRecord(record *DecisionRecord) {
+	record.InputPrompt = secrets.Redact(record.InputPrompt)
+	record.CoTTrace = secrets.Redact(record.CoTTrace)
+	record.DecisionJSON = secrets.Redact(record.DecisionJSON)
+	record.ErrorMessage = secrets.Redact(record.ErrorMessage)
+	for i := range record.ExecutionLog {
+		record.ExecutionLog[i] = secrets.Redact(record.ExecutionLog[i])
+	}
+	for i := range record.Decisions {
+		record.Decisions[i].Error = secrets.Redact(record.Decisions[i].Error)
+		record.Decisions[i].Reasoning = secrets.Redact(record.Decisions[i].Reasoning)
+	}
+}
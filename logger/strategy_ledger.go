@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// LedgerEntry 🏷️ 成交台账：每笔开平仓带策略标签（ai/limit/basis/manual），用于按策略拆分绩效
+type LedgerEntry struct {
+	Time          time.Time `json:"time"`
+	Strategy      string    `json:"strategy"`
+	Symbol        string    `json:"symbol"`
+	Side          string    `json:"side"`   // long/short
+	Action        string    `json:"action"` // open/close
+	Quantity      float64   `json:"quantity,omitempty"`
+	Price         float64   `json:"price,omitempty"`
+	PnL           float64   `json:"pnl,omitempty"` // 平仓已实现盈亏（USDT）
+	Fees          float64   `json:"fees,omitempty"`
+	ClientOrderID string    `json:"client_order_id,omitempty"`
+	Note          string    `json:"note,omitempty"` // 平仓原因等
+
+	// 📈 开仓归因：开仓所在周期、决策ID及按止损计算的风险（平仓盈亏据此归因到开仓周期并换算为R）
+	Cycle      int     `json:"cycle,omitempty"`
+	DecisionID string  `json:"decision_id,omitempty"`
+	Risk       float64 `json:"risk,omitempty"` // 开仓价到止损的亏损金额（USDT）
+}
+
+// StrategyPerformance 单个策略来源的绩效
+type StrategyPerformance struct {
+	Strategy      string  `json:"strategy"`
+	Opens         int     `json:"opens"`
+	Closes        int     `json:"closes"`
+	WinningTrades int     `json:"winning_trades"`
+	LosingTrades  int     `json:"losing_trades"`
+	WinRate       float64 `json:"win_rate"`
+	TotalPnL      float64 `json:"total_pn_l"`
+	AvgPnL        float64 `json:"avg_pn_l"`
+	Fees          float64 `json:"fees"`
+}
+
+func (l *DecisionLogger) ledgerPath() string {
+	return filepath.Join(l.logDir, "ledger", "trades.jsonl")
+}
+
+// LogLedgerEntry 追加一条成交台账记录（JSONL，保存在子目录中）
+func (l *DecisionLogger) LogLedgerEntry(entry LedgerEntry) error {
+	l.ledgerMu.Lock()
+	defer l.ledgerMu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	path := l.ledgerPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建台账目录失败: %w", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化台账记录失败: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开台账文件失败: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入台账记录失败: %w", err)
+	}
+	return nil
+}
+
+// GetLedgerEntries 读取since之后的台账记录（按时间正序）
+func (l *DecisionLogger) GetLedgerEntries(since time.Time) ([]LedgerEntry, error) {
+	l.ledgerMu.Lock()
+	defer l.ledgerMu.Unlock()
+
+	f, err := os.Open(l.ledgerPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("打开台账文件失败: %w", err)
+	}
+	defer f.Close()
+
+	var entries []LedgerEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry LedgerEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.Time.Before(since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取台账文件失败: %w", err)
+	}
+	return entries, nil
+}
+
+// GetStrategyReport 按策略来源汇总since之后的绩效（按总盈亏倒序）
+func (l *DecisionLogger) GetStrategyReport(since time.Time) ([]*StrategyPerformance, error) {
+	entries, err := l.GetLedgerEntries(since)
+	if err != nil {
+		return nil, err
+	}
+
+	byStrategy := make(map[string]*StrategyPerformance)
+	for _, e := range entries {
+		stats, ok := byStrategy[e.Strategy]
+		if !ok {
+			stats = &StrategyPerformance{Strategy: e.Strategy}
+			byStrategy[e.Strategy] = stats
+		}
+		stats.Fees += e.Fees
+		if e.Action == "open" {
+			stats.Opens++
+			continue
+		}
+		stats.Closes++
+		stats.TotalPnL += e.PnL
+		if e.PnL > 0 {
+			stats.WinningTrades++
+		} else if e.PnL < 0 {
+			stats.LosingTrades++
+		}
+	}
+
+	report := make([]*StrategyPerformance, 0, len(byStrategy))
+	for _, stats := range byStrategy {
+		if stats.Closes > 0 {
+			stats.WinRate = float64(stats.WinningTrades) / float64(stats.Closes) * 100
+			stats.AvgPnL = stats.TotalPnL / float64(stats.Closes)
+		}
+		report = append(report, stats)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].TotalPnL > report[j].TotalPnL })
+	return report, nil
+}
@@ -0,0 +1,38 @@
+package logger
+
+// Subscribe 订阅新保存的决策记录（用于实时推送）
+// 订阅者处理过慢时（缓冲区已满）该条记录会被丢弃，不会阻塞交易主循环
+// 返回的函数用于取消订阅，取消后channel会被关闭
+func (l *DecisionLogger) Subscribe(buffer int) (<-chan *DecisionRecord, func()) {
+	ch := make(chan *DecisionRecord, buffer)
+
+	l.subMu.Lock()
+	if l.subscribers == nil {
+		l.subscribers = make(map[chan *DecisionRecord]struct{})
+	}
+	l.subscribers[ch] = struct{}{}
+	l.subMu.Unlock()
+
+	cancel := func() {
+		l.subMu.Lock()
+		defer l.subMu.Unlock()
+		if _, ok := l.subscribers[ch]; ok {
+			delete(l.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
+
+// publish 推送决策记录给所有订阅者
+func (l *DecisionLogger) publish(record *DecisionRecord) {
+	l.subMu.Lock()
+	defer l.subMu.Unlock()
+
+	for ch := range l.subscribers {
+		select {
+		case ch <- record:
+		default:
+		}
+	}
+}
@@ -6,11 +6,16 @@ import (
 	"log"
 	"nofx/api"
 	"nofx/config"
+	"nofx/decision/prompts"
+	"nofx/i18n"
 	"nofx/manager"
+	"nofx/market"
 	"nofx/pool"
+	"nofx/secrets"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"syscall"
 )
@@ -40,9 +45,22 @@ func main() {
 		log.Fatalf("❌ 加载配置失败: %v", err)
 	}
 
-	log.Printf("✓ 配置加载成功，共%d个trader参赛", len(cfg.Traders))
+	// 🌐 日志和通知语言
+	if err := i18n.SetLanguage(cfg.Language); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	i18n.Logf("main.config_loaded", len(cfg.Traders))
 	fmt.Println()
 
+	// 🔐 日志脱敏：已登记的密钥写入日志前替换为***，并检查明文密钥和已有日志中的泄漏
+	log.SetOutput(secrets.NewRedactWriter(log.Writer()))
+	if secrets.Tracked() > 0 {
+		log.Printf("✓ 已登记%d个密钥用于日志/决策记录脱敏", secrets.Tracked())
+	}
+	for _, warning := range secrets.CheckStartup(configFile, cfg.PlaintextSecrets, secretScanPaths()) {
+		log.Printf("⚠️  %s", warning)
+	}
+
 	// 设置默认主流币种列表
 	pool.SetDefaultCoins(cfg.DefaultCoins)
 
@@ -62,20 +80,66 @@ func main() {
 		log.Printf("✓ 已配置OI Top API")
 	}
 
+	// 🆕 设置币种池数据源链（远程API失败时回退到本地排名/默认币种）
+	if len(cfg.CoinPoolProviders) > 0 {
+		if err := pool.SetCoinPoolProviders(cfg.CoinPoolProviders); err != nil {
+			log.Fatalf("❌ 币种池数据源配置错误: %v", err)
+		}
+	}
+
+	// 🆕 清算热力图：订阅币安强平订单流，按价格分桶聚合清算密集区
+	if cfg.LiquidationStream {
+		liqMonitor := market.NewLiquidationStreamMonitor()
+		liqMonitor.Start()
+		market.SetLiquidationProvider(liqMonitor)
+		log.Printf("✓ 已启用强平订单流清算热力图")
+	}
+
+	// 🆕 K线推送：本地维护滚动K线，避免每个周期逐个币种请求REST
+	if cfg.KlineStream {
+		klineStream := market.NewKlineStream()
+		klineStream.Start()
+		market.SetKlineStream(klineStream)
+		log.Printf("✓ 已启用K线推送")
+	}
+
+	// 💾 K线磁盘缓存：重启后从磁盘加载历史K线，只向API请求缺失部分
+	if cfg.CandleCacheDir != "" {
+		candleCache, err := market.NewCandleCache(cfg.CandleCacheDir)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		market.SetCandleCache(candleCache)
+		log.Printf("✓ 已启用K线磁盘缓存: %s", cfg.CandleCacheDir)
+	}
+
+	// 📝 外部提示词模板（修改模板文件无需重新编译，决策记录中带prompt_version）
+	if cfg.PromptDir != "" {
+		prompts.SetDir(cfg.PromptDir)
+		log.Printf("✓ 已配置外部提示词模板目录: %s（版本 %s）", cfg.PromptDir, prompts.Version())
+	}
+
+	// 🆕 社交情绪数据源（可选，未配置时只使用恐慌贪婪指数）
+	if cfg.SocialSentimentURL != "" {
+		market.SetSocialSentimentProvider(market.NewHTTPSocialFeedProvider(cfg.SocialSentimentURL))
+		log.Printf("✓ 已配置社交情绪数据源")
+	}
+
 	// 创建TraderManager
 	traderManager := manager.NewTraderManager()
+	traderManager.SetBinanceSubAccounts(cfg.BinanceSubAccounts, cfg.BinanceMasterAPIKey, cfg.BinanceMasterSecretKey) // 🏦 子账户路由
 
 	// 添加所有启用的trader
 	enabledCount := 0
 	for i, traderCfg := range cfg.Traders {
 		// 跳过未启用的trader
 		if !traderCfg.Enabled {
-			log.Printf("⏭️  [%d/%d] 跳过未启用的 %s", i+1, len(cfg.Traders), traderCfg.Name)
+			i18n.Logf("main.trader_skipped", i+1, len(cfg.Traders), traderCfg.Name)
 			continue
 		}
 
 		enabledCount++
-		log.Printf("📦 [%d/%d] 初始化 %s (%s模型)...",
+		i18n.Logf("main.trader_init",
 			i+1, len(cfg.Traders), traderCfg.Name, strings.ToUpper(traderCfg.AIModel))
 
 		err := traderManager.AddTrader(
@@ -84,8 +148,9 @@ func main() {
 			cfg.MaxDailyLoss,
 			cfg.MaxDrawdown,
 			cfg.StopTradingMinutes,
-			cfg.Leverage,    // 传递杠杆配置
+			cfg.Leverage,       // 传递杠杆配置
 			cfg.UseLimitOrders, // 🆕 传递限价单模式配置
+			cfg.ObserveMode,    // 👁️ 传递观察模式配置
 		)
 		if err != nil {
 			log.Fatalf("❌ 初始化trader失败: %v", err)
@@ -98,14 +163,14 @@ func main() {
 	}
 
 	fmt.Println()
-	fmt.Println("🏁 竞赛参赛者:")
+	fmt.Println(i18n.T("main.participants"))
 	for _, traderCfg := range cfg.Traders {
 		// 只显示启用的trader
 		if !traderCfg.Enabled {
 			continue
 		}
-		fmt.Printf("  • %s (%s) - 初始资金: %.0f USDT\n",
-			traderCfg.Name, strings.ToUpper(traderCfg.AIModel), traderCfg.InitialBalance)
+		fmt.Println(i18n.T("main.participant",
+			traderCfg.Name, strings.ToUpper(traderCfg.AIModel), traderCfg.InitialBalance))
 	}
 
 	fmt.Println()
@@ -126,20 +191,36 @@ func main() {
 		fmt.Println("📋 交易模式: 市价单（即时成交）")
 		fmt.Println()
 	}
-	fmt.Println("⚠️  风险提示: AI自动交易有风险，建议小额资金测试！")
+	if cfg.ObserveMode {
+		fmt.Println("👁️  观察模式: 已启用")
+		fmt.Println("  • 完整运行行情、预测、决策和风控检查")
+		fmt.Println("  • 不会实际下单，决策记录为假设执行（含止损止盈）")
+		fmt.Println()
+	}
+	fmt.Println(i18n.T("main.risk_warning"))
 	fmt.Println()
-	fmt.Println("按 Ctrl+C 停止运行")
+	fmt.Println(i18n.T("main.press_ctrl_c"))
 	fmt.Println(strings.Repeat("=", 60))
 	fmt.Println()
 
 	// 创建并启动API服务器
-	apiServer := api.NewServer(traderManager, cfg.APIServerPort)
+	apiServer := api.NewServer(traderManager, cfg.APIServerPort, cfg.WebhookSecret)
 	go func() {
 		if err := apiServer.Start(); err != nil {
 			log.Printf("❌ API服务器错误: %v", err)
 		}
 	}()
 
+	// 🎛️ gRPC控制接口（可选）
+	if cfg.GRPCPort > 0 {
+		grpcServer := api.NewGRPCServer(traderManager, cfg.GRPCPort, cfg.GRPCAuthToken)
+		go func() {
+			if err := grpcServer.Start(); err != nil {
+				log.Printf("❌ gRPC服务器错误: %v", err)
+			}
+		}()
+	}
+
 	// 设置优雅退出
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -151,11 +232,11 @@ func main() {
 	<-sigChan
 	fmt.Println()
 	fmt.Println()
-	log.Println("📛 收到退出信号，正在停止所有trader...")
+	i18n.Logf("main.shutting_down")
 	traderManager.StopAll()
 
 	fmt.Println()
-	fmt.Println("👋 感谢使用AI交易竞赛系统！")
+	fmt.Println(i18n.T("main.goodbye"))
 }
 
 func initLogger() (*os.File, error) {
@@ -177,3 +258,14 @@ func initLogger() (*os.File, error) {
 
 	return file, nil
 }
+
+// secretScanPaths 启动时检查密钥泄漏的文件：主日志和每个trader最近的决策记录
+func secretScanPaths() []string {
+	paths := []string{filepath.Join("logs", "nofx.log")}
+	files, _ := filepath.Glob(filepath.Join("decision_logs", "*", "decision_*.json"))
+	sort.Strings(files)
+	if len(files) > 50 {
+		files = files[len(files)-50:]
+	}
+	return append(paths, files...)
+}
@@ -0,0 +1,208 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/config"
+	"nofx/trader"
+	"sort"
+	"strings"
+)
+
+// AccountSummary 🏦 单个交易所账户的净值汇总（同一账户下的trader共享保证金，只统计一次）
+type AccountSummary struct {
+	Account       string   `json:"account"`     // 账户标识（子账户为 sub:<name>）
+	SubAccount    string   `json:"sub_account"` // 子账户名称（非子账户为空）
+	TraderIDs     []string `json:"trader_ids"`
+	TotalEquity   float64  `json:"total_equity"`
+	PositionCount int      `json:"position_count"`
+	MarginUsedPct float64  `json:"margin_used_pct"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// SetBinanceSubAccounts 配置币安子账户（需在AddTrader之前调用）；主账户密钥为空时不支持划转
+func (tm *TraderManager) SetBinanceSubAccounts(subs []config.BinanceSubAccountConfig, masterAPIKey, masterSecretKey string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.subAccounts = make(map[string]config.BinanceSubAccountConfig, len(subs))
+	tm.subAccountOrder = tm.subAccountOrder[:0]
+	for _, sub := range subs {
+		tm.subAccounts[sub.Name] = sub
+		tm.subAccountOrder = append(tm.subAccountOrder, sub.Name)
+	}
+	if masterAPIKey != "" && masterSecretKey != "" {
+		tm.master = trader.NewBinanceMasterAccount(masterAPIKey, masterSecretKey)
+	}
+	if len(subs) > 0 {
+		log.Printf("🏦 已配置%d个币安子账户", len(subs))
+	}
+}
+
+// resolveAccount 确定trader使用的账户：子账户填充API密钥（auto=分配到trader最少的子账户），返回账户标识
+// 调用方需持有 tm.mu
+func (tm *TraderManager) resolveAccount(cfg *config.TraderConfig) (string, error) {
+	if cfg.SubAccount != "" {
+		name := cfg.SubAccount
+		if name == "auto" {
+			name = tm.leastLoadedSubAccount()
+			if name == "" {
+				return "", fmt.Errorf("没有可分配的币安子账户")
+			}
+		}
+		sub, ok := tm.subAccounts[name]
+		if !ok {
+			return "", fmt.Errorf("币安子账户 '%s' 不存在", name)
+		}
+		cfg.SubAccount = name
+		cfg.BinanceAPIKey = sub.APIKey
+		cfg.BinanceSecretKey = sub.SecretKey
+		log.Printf("🏦 Trader '%s' 使用币安子账户: %s", cfg.Name, name)
+		return "sub:" + name, nil
+	}
+
+	// 非子账户：相同凭证视为同一账户
+	credential := cfg.BinanceAPIKey
+	switch cfg.Exchange {
+	case "hyperliquid":
+		credential = cfg.HyperliquidWalletAddr
+	case "aster":
+		credential = cfg.AsterUser
+	case "bybit":
+		credential = cfg.BybitAPIKey
+	case "mock":
+		credential = ""
+	}
+	if credential == "" {
+		return cfg.Exchange + ":" + cfg.ID, nil
+	}
+
+	// 子账户密钥直接写在trader配置里时，同样归到该子账户
+	if cfg.Exchange == "binance" {
+		for _, name := range tm.subAccountOrder {
+			if tm.subAccounts[name].APIKey == credential {
+				cfg.SubAccount = name
+				return "sub:" + name, nil
+			}
+		}
+	}
+
+	key := cfg.Exchange + ":" + credential
+	if account, ok := tm.accountByCredential[key]; ok {
+		return account, nil
+	}
+	account := cfg.Exchange + ":" + cfg.ID
+	tm.accountByCredential[key] = account
+	return account, nil
+}
+
+// leastLoadedSubAccount trader数量最少的子账户（数量相同时按配置顺序）
+func (tm *TraderManager) leastLoadedSubAccount() string {
+	load := make(map[string]int)
+	for _, account := range tm.accounts {
+		load[account]++
+	}
+
+	best := ""
+	bestLoad := 0
+	for _, name := range tm.subAccountOrder {
+		n := load["sub:"+name]
+		if best == "" || n < bestLoad {
+			best, bestLoad = name, n
+		}
+	}
+	return best
+}
+
+// warnSharedAccount 多个trader共用同一账户时提示保证金未隔离（调用方需持有 tm.mu）
+func (tm *TraderManager) warnSharedAccount(traderID, account string) {
+	for id, other := range tm.accounts {
+		if id != traderID && other == account {
+			log.Printf("⚠️  Trader '%s' 与 '%s' 共用同一账户(%s)，保证金未隔离", traderID, id, account)
+			return
+		}
+	}
+}
+
+// GetAccountSummaries 按账户汇总净值，返回各账户汇总与全部账户总净值
+func (tm *TraderManager) GetAccountSummaries() ([]AccountSummary, float64) {
+	tm.mu.RLock()
+	byAccount := make(map[string][]string)
+	for id, account := range tm.accounts {
+		byAccount[account] = append(byAccount[account], id)
+	}
+	traders := make(map[string]*trader.AutoTrader, len(tm.traders))
+	for id, t := range tm.traders {
+		traders[id] = t
+	}
+	tm.mu.RUnlock()
+
+	summaries := make([]AccountSummary, 0, len(byAccount))
+	totalEquity := 0.0
+	for account, ids := range byAccount {
+		sort.Strings(ids)
+		summary := AccountSummary{Account: account, TraderIDs: ids}
+		if strings.HasPrefix(account, "sub:") {
+			summary.SubAccount = strings.TrimPrefix(account, "sub:")
+		}
+
+		// 同一账户下任意一个trader的账户信息即为整个账户的数据
+		var lastErr error
+		for _, id := range ids {
+			info, err := traders[id].GetAccountInfo()
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			summary.TotalEquity, _ = info["total_equity"].(float64)
+			summary.PositionCount, _ = info["position_count"].(int)
+			summary.MarginUsedPct, _ = info["margin_used_pct"].(float64)
+			lastErr = nil
+			break
+		}
+		if lastErr != nil {
+			summary.Error = lastErr.Error()
+		}
+
+		totalEquity += summary.TotalEquity
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Account < summaries[j].Account })
+	return summaries, totalEquity
+}
+
+// TransferToSubAccount 从主账户合约钱包向子账户划转USDT
+func (tm *TraderManager) TransferToSubAccount(name string, amount float64) error {
+	master, sub, err := tm.transferTarget(name)
+	if err != nil {
+		return err
+	}
+	return master.TransferToSubAccount(sub.Email, amount)
+}
+
+// TransferFromSubAccount 从子账户合约钱包划转USDT回主账户
+func (tm *TraderManager) TransferFromSubAccount(name string, amount float64) error {
+	master, sub, err := tm.transferTarget(name)
+	if err != nil {
+		return err
+	}
+	return master.TransferFromSubAccount(sub.Email, amount)
+}
+
+func (tm *TraderManager) transferTarget(name string) (*trader.BinanceMasterAccount, config.BinanceSubAccountConfig, error) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if tm.master == nil {
+		return nil, config.BinanceSubAccountConfig{}, fmt.Errorf("未配置binance_master_api_key，无法划转")
+	}
+	sub, ok := tm.subAccounts[name]
+	if !ok {
+		return nil, config.BinanceSubAccountConfig{}, fmt.Errorf("币安子账户 '%s' 不存在", name)
+	}
+	if sub.Email == "" {
+		return nil, config.BinanceSubAccountConfig{}, fmt.Errorf("子账户 '%s' 未配置email，无法划转", name)
+	}
+	return tm.master, sub, nil
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"log"
 	"nofx/config"
+	"nofx/decision"
+	"nofx/decision/agents"
 	"nofx/memory"
 	"nofx/trader"
+	"strings"
 	"sync"
 	"time"
 )
@@ -14,17 +17,27 @@ import (
 type TraderManager struct {
 	traders map[string]*trader.AutoTrader // key: trader ID
 	mu      sync.RWMutex
+
+	// 🏦 账户归属：trader ID -> 账户标识（同一账户的trader共享保证金）
+	accounts            map[string]string
+	accountByCredential map[string]string
+	subAccounts         map[string]config.BinanceSubAccountConfig
+	subAccountOrder     []string
+	master              *trader.BinanceMasterAccount // 主账户（仅用于子账户划转，可为nil）
 }
 
 // NewTraderManager 创建trader管理器
 func NewTraderManager() *TraderManager {
 	return &TraderManager{
-		traders: make(map[string]*trader.AutoTrader),
+		traders:             make(map[string]*trader.AutoTrader),
+		accounts:            make(map[string]string),
+		accountByCredential: make(map[string]string),
+		subAccounts:         make(map[string]config.BinanceSubAccountConfig),
 	}
 }
 
 // AddTrader 添加一个trader
-func (tm *TraderManager) AddTrader(cfg config.TraderConfig, coinPoolURL string, maxDailyLoss, maxDrawdown float64, stopTradingMinutes int, leverage config.LeverageConfig, useLimitOrders bool) error {
+func (tm *TraderManager) AddTrader(cfg config.TraderConfig, coinPoolURL string, maxDailyLoss, maxDrawdown float64, stopTradingMinutes int, leverage config.LeverageConfig, useLimitOrders bool, observeMode bool) error {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
@@ -32,38 +45,161 @@ func (tm *TraderManager) AddTrader(cfg config.TraderConfig, coinPoolURL string,
 		return fmt.Errorf("trader ID '%s' 已存在", cfg.ID)
 	}
 
+	// 🏦 确定账户（子账户时填充API密钥）
+	account, err := tm.resolveAccount(&cfg)
+	if err != nil {
+		return fmt.Errorf("trader '%s': %w", cfg.ID, err)
+	}
+
 	// 构建AutoTraderConfig
 	traderConfig := trader.AutoTraderConfig{
-		ID:                    cfg.ID,
-		Name:                  cfg.Name,
-		AIModel:               cfg.AIModel,
-		QwenModel:             cfg.QwenModel,
-		Exchange:              cfg.Exchange,
-		BinanceAPIKey:         cfg.BinanceAPIKey,
-		BinanceSecretKey:      cfg.BinanceSecretKey,
-		BinanceTestnet:        cfg.BinanceTestnet,
-		HyperliquidPrivateKey: cfg.HyperliquidPrivateKey,
-		HyperliquidWalletAddr: cfg.HyperliquidWalletAddr,
-		HyperliquidTestnet:    cfg.HyperliquidTestnet,
-		AsterUser:             cfg.AsterUser,
-		AsterSigner:           cfg.AsterSigner,
-		AsterPrivateKey:       cfg.AsterPrivateKey,
-		CoinPoolAPIURL:        coinPoolURL,
-		UseQwen:               cfg.AIModel == "qwen",
-		DeepSeekKey:           cfg.DeepSeekKey,
-		QwenKey:               cfg.QwenKey,
-		CustomAPIURL:          cfg.CustomAPIURL,
-		CustomAPIKey:          cfg.CustomAPIKey,
-		CustomModelName:       cfg.CustomModelName,
-		ScanInterval:          cfg.GetScanInterval(),
-		KlineInterval:         cfg.KlineInterval, // K线周期配置
-		InitialBalance:        cfg.InitialBalance,
-		BTCETHLeverage:        leverage.BTCETHLeverage,  // 使用配置的杠杆倍数
-		AltcoinLeverage:       leverage.AltcoinLeverage, // 使用配置的杠杆倍数
-		MaxDailyLoss:          maxDailyLoss,
-		MaxDrawdown:           maxDrawdown,
-		StopTradingTime:       time.Duration(stopTradingMinutes) * time.Minute,
-		UseLimitOrders:        useLimitOrders, // 🆕 限价单模式开关
+		ID:                            cfg.ID,
+		Name:                          cfg.Name,
+		AIModel:                       cfg.AIModel,
+		QwenModel:                     cfg.QwenModel,
+		Exchange:                      cfg.Exchange,
+		BinanceAPIKey:                 cfg.BinanceAPIKey,
+		BinanceSecretKey:              cfg.BinanceSecretKey,
+		BinanceTestnet:                cfg.BinanceTestnet,
+		HyperliquidPrivateKey:         cfg.HyperliquidPrivateKey,
+		HyperliquidWalletAddr:         cfg.HyperliquidWalletAddr,
+		HyperliquidTestnet:            cfg.HyperliquidTestnet,
+		AsterUser:                     cfg.AsterUser,
+		AsterSigner:                   cfg.AsterSigner,
+		AsterPrivateKey:               cfg.AsterPrivateKey,
+		BybitAPIKey:                   cfg.BybitAPIKey,
+		BybitSecretKey:                cfg.BybitSecretKey,
+		BybitTestnet:                  cfg.BybitTestnet,
+		CoinPoolAPIURL:                coinPoolURL,
+		UseQwen:                       cfg.AIModel == "qwen",
+		DeepSeekKey:                   cfg.DeepSeekKey,
+		QwenKey:                       cfg.QwenKey,
+		CustomAPIURL:                  cfg.CustomAPIURL,
+		CustomAPIKey:                  cfg.CustomAPIKey,
+		CustomModelName:               cfg.CustomModelName,
+		ScanInterval:                  cfg.GetScanInterval(),
+		KlineInterval:                 cfg.KlineInterval, // K线周期配置
+		LongTermInterval:              cfg.LongTermInterval,
+		EntryTimingInterval:           cfg.EntryTimingInterval,
+		InitialBalance:                cfg.InitialBalance,
+		BTCETHLeverage:                leverage.BTCETHLeverage,  // 使用配置的杠杆倍数
+		AltcoinLeverage:               leverage.AltcoinLeverage, // 使用配置的杠杆倍数
+		MaxDailyLoss:                  maxDailyLoss,
+		MaxDrawdown:                   maxDrawdown,
+		StopTradingTime:               time.Duration(stopTradingMinutes) * time.Minute,
+		UseLimitOrders:                useLimitOrders, // 🆕 限价单模式开关
+		PnLReconcileInterval:          time.Duration(cfg.PnLReconcileMinutes) * time.Minute,
+		ObserveMode:                   observeMode, // 👁️ 观察模式开关
+		UnknownPositionPolicy:         cfg.UnknownPositionPolicy,
+		AnomalyBalanceDropPct:         cfg.AnomalyBalanceDropPct,
+		WithdrawalLockPct:             cfg.WithdrawalLockPct,
+		WithdrawalLockAck:             cfg.WithdrawalLockAck,
+		MaxRiskPerTradeUSD:            cfg.MaxRiskPerTradeUSD,
+		AccountTiers:                  accountTiers(cfg.AccountTiers),
+		StopMarginLossPct:             cfg.StopMarginLossPct,
+		StalePriceStopFraction:        cfg.StalePriceStopFraction,
+		MinStopTicks:                  cfg.MinStopTicks,
+		MinStopDistancePct:            cfg.MinStopDistancePct,
+		StopDistanceAction:            cfg.StopDistanceAction,
+		MaxHoldTrend:                  time.Duration(cfg.MaxHoldHoursTrend * float64(time.Hour)),
+		MaxHoldRange:                  time.Duration(cfg.MaxHoldHoursRange * float64(time.Hour)),
+		AITimeout:                     time.Duration(cfg.AITimeoutSeconds) * time.Second,
+		CycleBudget:                   time.Duration(cfg.CycleBudgetSeconds) * time.Second,
+		EnsembleModels:                ensembleModels(cfg.EnsembleModels),
+		EnsembleMode:                  cfg.EnsembleMode,
+		AltcoinSignals:                cfg.AltcoinSignals,
+		QuoteAssets:                   cfg.QuoteAssets,
+		Basis:                         basisConfig(cfg.Basis),
+		LeveragePolicy:                leveragePolicy(cfg),
+		PositionAllocation:            cfg.PositionAllocation,
+		MaxNewPositionsPerCycle:       cfg.MaxNewPositionsPerCycle,
+		CooldownOverridesPerDay:       cfg.CooldownOverridesPerDay,
+		CooldownOverrideMinConfidence: cfg.CooldownOverrideMinConfidence,
+		ConstraintOverrides:           constraintOverrides(cfg.ConstraintOverrides),
+		MajorSymbols:                  cfg.MajorSymbols,
+		DecisionLogArchiveDays:        cfg.DecisionLogArchiveDays,
+		DecisionLogRetentionDays:      cfg.DecisionLogRetentionDays,
+		AuditChain:                    cfg.AuditChain,
+		PositionLimits: agents.PositionLimits{
+			MaxPositions:        cfg.MaxPositions,
+			MaxLongPositions:    cfg.MaxLongPositions,
+			MaxShortPositions:   cfg.MaxShortPositions,
+			MaxNotionalMultiple: cfg.MaxNotionalMultiple,
+		},
+		StrategyProfile:               cfg.StrategyProfile,
+		ConnectivityLossTimeout:       time.Duration(cfg.ConnectivityLossMinutes) * time.Minute,
+		FlattenUnprotectedOnReconnect: cfg.FlattenUnprotectedOnReconnect,
+		ProtectiveMonitorInterval:     time.Duration(cfg.ProtectiveMonitorSeconds) * time.Second,
+		ProtectiveMonitorAlertAfter:   cfg.ProtectiveMonitorAlertAfter,
+		CancelAllOrders:               cfg.CancelAllOrders,
+		Watchlist:                     cfg.Watchlist,
+		DecisionPolicies:              decisionPolicies(cfg.DecisionPolicies),
+		PerformanceWindow:             cfg.PerformanceWindowCycles,
+		AdaptiveScan: trader.AdaptiveScanConfig{
+			MinInterval:   time.Duration(cfg.MinScanIntervalMinutes) * time.Minute,
+			MaxInterval:   time.Duration(cfg.MaxScanIntervalMinutes) * time.Minute,
+			ATRSpikeRatio: cfg.ScanATRSpikeRatio,
+			NearLevelPct:  cfg.ScanNearLevelPct,
+		},
+		EntryTiming: trader.EntryTimingConfig{
+			FundingWindow:     time.Duration(cfg.FundingGuardMinutes) * time.Minute,
+			FundingExtremePct: cfg.FundingGuardRatePct,
+			EventCalendarFile: cfg.EventCalendarFile,
+			EventWindow:       time.Duration(cfg.EventGuardMinutes) * time.Minute,
+		},
+		DecisionEngine: cfg.DecisionEngine,
+		ShadowEngine:   cfg.ShadowEngine,
+		PositionModel:  positionModel(cfg.PositionModel),
+		FallbackModel:  fallbackModel(cfg.FallbackModel),
+		PortfolioStop: trader.PortfolioStopConfig{
+			DrawdownPct: cfg.PortfolioStopPct,
+			Flatten:     cfg.PortfolioStopFlatten,
+			Cooloff:     time.Duration(cfg.PortfolioStopCooloffMinutes) * time.Minute,
+		},
+		FlatWindows: flatWindows(cfg.FlatWindows),
+		OvertradingGuard: trader.OvertradingGuardConfig{
+			WindowTrades:       cfg.OvertradingWindowTrades,
+			ProbabilityStep:    cfg.OvertradingProbabilityStep,
+			MinConfidence:      cfg.OvertradingMinConfidence,
+			CooldownMultiplier: cfg.OvertradingCooldownMultiplier,
+		},
+		Contradiction: trader.ContradictionConfig{
+			MinConfidence:      cfg.ContradictionMinConfidence,
+			MaxMovePct:         cfg.ContradictionMaxMovePct,
+			RequiredConfidence: cfg.ContradictionRequiredConfidence,
+		},
+		SelfCritique: trader.SelfCritiqueConfig{
+			MinNotionalUSD: cfg.SelfCritiqueMinNotional,
+			MinLeverage:    cfg.SelfCritiqueMinLeverage,
+			BlockOnError:   cfg.SelfCritiqueBlockOnError,
+		},
+		ConfidenceSizing: trader.ConfidenceSizingConfig{
+			Min: cfg.ConfidenceSizingMin,
+			Max: cfg.ConfidenceSizingMax,
+		},
+		MarketDataSource: cfg.MarketDataSource,
+		Liquidity: decision.LiquidityFilter{
+			Mode:   cfg.LiquidityFilter,
+			MinUSD: cfg.LiquidityMinUSD,
+		},
+		TWAP: trader.TWAPConfig{
+			MinNotional: cfg.TWAPMinNotional,
+			MaxBookPct:  cfg.TWAPMaxBookPct,
+			Slices:      cfg.TWAPSlices,
+			Duration:    time.Duration(cfg.TWAPDurationSeconds) * time.Second,
+		},
+		Chaos: trader.ChaosConfig{
+			ErrorRate:       cfg.ChaosErrorRate,
+			MaxLatency:      time.Duration(cfg.ChaosMaxLatencyMs) * time.Millisecond,
+			PartialFillRate: cfg.ChaosPartialFillRate,
+			Seed:            cfg.ChaosSeed,
+		},
+		TakeProfitLadder: takeProfitRungs(cfg.TakeProfitLadder),
+		Cadence: agents.ScanCadence{
+			MajorEvery: cfg.MajorScanEvery,
+			AltEvery:   cfg.AltScanEvery,
+		},
+		Ranking: agents.CandidateRanking{TopK: cfg.MaxPredictionsPerCycle},
 	}
 
 	// 创建trader实例
@@ -73,10 +209,177 @@ func (tm *TraderManager) AddTrader(cfg config.TraderConfig, coinPoolURL string,
 	}
 
 	tm.traders[cfg.ID] = at
+	tm.accounts[cfg.ID] = account
+	tm.warnSharedAccount(cfg.ID, account)
 	log.Printf("✓ Trader '%s' (%s) 已添加", cfg.Name, cfg.AIModel)
 	return nil
 }
 
+// takeProfitRungs 转换分批止盈策略配置
+func takeProfitRungs(rungs []config.TakeProfitRungConfig) []trader.TakeProfitRung {
+	result := make([]trader.TakeProfitRung, 0, len(rungs))
+	for _, r := range rungs {
+		result = append(result, trader.TakeProfitRung{R: r.R, Pct: r.Pct})
+	}
+	return result
+}
+
+// ensembleModels 转换集成预测模型配置
+func ensembleModels(models []config.EnsembleModelConfig) []trader.EnsembleModelConfig {
+	result := make([]trader.EnsembleModelConfig, 0, len(models))
+	for _, m := range models {
+		result = append(result, trader.EnsembleModelConfig{
+			Name:     m.Name,
+			Provider: m.Provider,
+			APIKey:   m.APIKey,
+			APIURL:   m.APIURL,
+			Model:    m.Model,
+		})
+	}
+	return result
+}
+
+// positionModel 转换持仓评估模型配置
+func positionModel(m *config.PositionModelConfig) *trader.PositionModelConfig {
+	if m == nil {
+		return nil
+	}
+	return &trader.PositionModelConfig{
+		Provider:  m.Provider,
+		APIKey:    m.APIKey,
+		APIURL:    m.APIURL,
+		Model:     m.Model,
+		Timeout:   time.Duration(m.TimeoutSeconds) * time.Second,
+		MaxTokens: m.MaxTokens,
+	}
+}
+
+// accountTiers 转换账户级别配置
+func accountTiers(tiers []config.AccountTierConfig) []trader.AccountTierConfig {
+	result := make([]trader.AccountTierConfig, 0, len(tiers))
+	for _, t := range tiers {
+		result = append(result, trader.AccountTierConfig{
+			Name:               t.Name,
+			MinEquity:          t.MinEquity,
+			MaxLossPct:         t.MaxLossPct,
+			MaxRiskPerTradePct: t.MaxRiskPerTradePct,
+			BreakEvenPct:       t.BreakEvenPct,
+		})
+	}
+	return result
+}
+
+// fallbackModel 转换备用AI模型配置
+func fallbackModel(m *config.FallbackModelConfig) *trader.FallbackModelConfig {
+	if m == nil {
+		return nil
+	}
+	return &trader.FallbackModelConfig{
+		Provider:      m.Provider,
+		APIKey:        m.APIKey,
+		APIURL:        m.APIURL,
+		Model:         m.Model,
+		Timeout:       time.Duration(m.TimeoutSeconds) * time.Second,
+		MaxFailures:   m.MaxFailures,
+		SlowThreshold: time.Duration(m.SlowSeconds) * time.Second,
+		FailbackAfter: time.Duration(m.FailbackMinutes) * time.Minute,
+	}
+}
+
+// basisConfig 转换基差策略配置
+func basisConfig(b *config.BasisConfig) *trader.BasisConfig {
+	if b == nil {
+		return nil
+	}
+	return &trader.BasisConfig{
+		Mode:         b.Mode,
+		Symbols:      b.Symbols,
+		EntryPct:     b.EntryPct,
+		ExitPct:      b.ExitPct,
+		PositionUSDT: b.PositionUSDT,
+		BudgetUSDT:   b.BudgetUSDT,
+		MaxLossUSDT:  b.MaxLossUSDT,
+		Leverage:     b.Leverage,
+		StopLossPct:  b.StopLossPct,
+		MaxHold:      time.Duration(b.MaxHoldHours * float64(time.Hour)),
+		Interval:     time.Duration(b.IntervalSeconds) * time.Second,
+	}
+}
+
+// decisionPolicies 转换决策策略规则
+func decisionPolicies(rules []config.DecisionPolicyConfig) []trader.PolicyRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	result := make([]trader.PolicyRule, 0, len(rules))
+	for _, r := range rules {
+		result = append(result, trader.PolicyRule{
+			Name:           r.Name,
+			Symbols:        r.Symbols,
+			Scope:          r.Scope,
+			Actions:        r.Actions,
+			Weekdays:       r.Weekdays,
+			Veto:           r.Veto,
+			MaxLeverage:    r.MaxLeverage,
+			MaxPositionUSD: r.MaxPositionUSD,
+		})
+	}
+	return result
+}
+
+// flatWindows 转换定时空仓窗口
+func flatWindows(windows []config.FlatWindowConfig) []trader.FlatWindowConfig {
+	if len(windows) == 0 {
+		return nil
+	}
+	result := make([]trader.FlatWindowConfig, 0, len(windows))
+	for _, w := range windows {
+		result = append(result, trader.FlatWindowConfig{
+			Name:     w.Name,
+			Start:    w.Start,
+			End:      w.End,
+			Timezone: w.Timezone,
+			Notice:   time.Duration(w.NoticeMinutes) * time.Minute,
+		})
+	}
+	return result
+}
+
+// constraintOverrides 转换按类别/币种的硬约束覆盖
+func constraintOverrides(cfg map[string]config.ConstraintOverrideConfig) map[string]trader.SymbolConstraints {
+	if len(cfg) == 0 {
+		return nil
+	}
+	result := make(map[string]trader.SymbolConstraints, len(cfg))
+	for key, o := range cfg {
+		result[key] = trader.SymbolConstraints{
+			CooldownMinutes:   o.CooldownMinutes,
+			MinHoldingMinutes: o.MinHoldingMinutes,
+			MaxHourlyTrades:   o.MaxHourlyTrades,
+			MaxDailyTrades:    o.MaxDailyTrades,
+		}
+	}
+	return result
+}
+
+// leveragePolicy 转换保证金模式与杠杆策略（未配置时返回nil，保持逐仓默认行为）
+func leveragePolicy(cfg config.TraderConfig) *trader.LeveragePolicy {
+	if cfg.MarginMode == "" && len(cfg.SymbolPolicies) == 0 {
+		return nil
+	}
+	policy := &trader.LeveragePolicy{
+		Default: trader.SymbolPolicy{MarginMode: cfg.MarginMode},
+		Symbols: make(map[string]trader.SymbolPolicy, len(cfg.SymbolPolicies)),
+	}
+	for symbol, p := range cfg.SymbolPolicies {
+		policy.Symbols[strings.ToUpper(symbol)] = trader.SymbolPolicy{
+			MarginMode:  p.MarginMode,
+			MaxLeverage: p.MaxLeverage,
+		}
+	}
+	return policy
+}
+
 // GetTrader 获取指定ID的trader
 func (tm *TraderManager) GetTrader(id string) (*trader.AutoTrader, error) {
 	tm.mu.RLock()
@@ -140,6 +443,38 @@ func (tm *TraderManager) StopAll() {
 	}
 }
 
+// StartTrader 启动指定trader（已在运行或上一次主循环尚未退出时返回错误）
+func (tm *TraderManager) StartTrader(id string) error {
+	at, err := tm.GetTrader(id)
+	if err != nil {
+		return err
+	}
+	if at.IsRunning() || at.LoopActive() {
+		return fmt.Errorf("trader '%s' 已在运行", id)
+	}
+
+	go func() {
+		log.Printf("▶️  启动 %s...", at.GetName())
+		if err := at.Run(); err != nil {
+			log.Printf("❌ %s 运行错误: %v", at.GetName(), err)
+		}
+	}()
+	return nil
+}
+
+// StopTrader 停止指定trader
+func (tm *TraderManager) StopTrader(id string) error {
+	at, err := tm.GetTrader(id)
+	if err != nil {
+		return err
+	}
+	if !at.IsRunning() {
+		return fmt.Errorf("trader '%s' 未在运行", id)
+	}
+	at.Stop()
+	return nil
+}
+
 // GetComparisonData 获取对比数据
 func (tm *TraderManager) GetComparisonData() (map[string]interface{}, error) {
 	tm.mu.RLock()
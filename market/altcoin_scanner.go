@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"log"
 	"math"
+	"nofx/ratelimit"
 	"sort"
 	"strconv"
 	"strings"
@@ -18,80 +19,80 @@ import (
 
 // AnomalySignal 异动信号
 type AnomalySignal struct {
-	Symbol      string    `json:"symbol"`
-	Timestamp   time.Time `json:"timestamp"`
-	Direction   string    `json:"direction"` // "up" (拉盘) or "down" (砸盘)
-	Confidence  int       `json:"confidence"` // 1-5星
-	CurrentPrice float64  `json:"current_price"`
+	Symbol       string    `json:"symbol"`
+	Timestamp    time.Time `json:"timestamp"`
+	Direction    string    `json:"direction"`  // "up" (拉盘) or "down" (砸盘)
+	Confidence   int       `json:"confidence"` // 1-5星
+	CurrentPrice float64   `json:"current_price"`
 
 	// 🆕 信号等级 (三级体系)
-	SignalTier   string `json:"signal_tier"`   // "early" (观察) / "mid" (建议建仓) / "late" (慎重追高)
-	TierLabel    string `json:"tier_label"`    // "🔍观察信号" / "✅建议建仓" / "⚠️慎重追高"
+	SignalTier string `json:"signal_tier"` // "early" (观察) / "mid" (建议建仓) / "late" (慎重追高)
+	TierLabel  string `json:"tier_label"`  // "🔍观察信号" / "✅建议建仓" / "⚠️慎重追高"
 
 	// 异动指标
-	OIChange1h        float64 `json:"oi_change_1h"`        // OI 1小时变化%
-	PriceChange15m    float64 `json:"price_change_15m"`    // 价格15分钟变化%
-	VolumeChange1h    float64 `json:"volume_change_1h"`    // 成交量1小时变化%
-	FundingRate       float64 `json:"funding_rate"`        // 资金费率
-	LargeOrderRatio   float64 `json:"large_order_ratio"`   // 大单占比%
+	OIChange1h      float64 `json:"oi_change_1h"`      // OI 1小时变化%
+	PriceChange15m  float64 `json:"price_change_15m"`  // 价格15分钟变化%
+	VolumeChange1h  float64 `json:"volume_change_1h"`  // 成交量1小时变化%
+	FundingRate     float64 `json:"funding_rate"`      // 资金费率
+	LargeOrderRatio float64 `json:"large_order_ratio"` // 大单占比%
 
 	// 流动性验证
-	OIValueUSD        float64 `json:"oi_value_usd"`        // OI价值(USD)
-	Volume24h         float64 `json:"volume_24h"`          // 24h成交量(USD)
-	OrderBookDepth    float64 `json:"orderbook_depth"`     // 订单簿深度(USD)
+	OIValueUSD     float64 `json:"oi_value_usd"`    // OI价值(USD)
+	Volume24h      float64 `json:"volume_24h"`      // 24h成交量(USD)
+	OrderBookDepth float64 `json:"orderbook_depth"` // 订单簿深度(USD)
 
 	// 触发的指标
-	TriggeredSignals  []string `json:"triggered_signals"`
+	TriggeredSignals []string `json:"triggered_signals"`
 
 	// AI预测（如果启用）
-	AIPrediction      *AIPrediction `json:"ai_prediction,omitempty"`
+	AIPrediction *AIPrediction `json:"ai_prediction,omitempty"`
 
 	// 建议操作
-	SuggestedAction   string  `json:"suggested_action"`    // "open_long" or "open_short"
-	SuggestedSize     float64 `json:"suggested_size"`      // 建议仓位(USDT)
-	SuggestedLeverage int     `json:"suggested_leverage"`  // 建议杠杆
-	SuggestedStopLoss float64 `json:"suggested_stop_loss"` // 建议止损价
+	SuggestedAction     string  `json:"suggested_action"`      // "open_long" or "open_short"
+	SuggestedSize       float64 `json:"suggested_size"`        // 建议仓位(USDT)
+	SuggestedLeverage   int     `json:"suggested_leverage"`    // 建议杠杆
+	SuggestedStopLoss   float64 `json:"suggested_stop_loss"`   // 建议止损价
 	SuggestedTakeProfit float64 `json:"suggested_take_profit"` // 建议止盈价
-	RiskRewardRatio   float64 `json:"risk_reward_ratio"`   // 风险收益比
+	RiskRewardRatio     float64 `json:"risk_reward_ratio"`     // 风险收益比
 }
 
 // AIPrediction AI预测结果
 type AIPrediction struct {
-	Direction      string  `json:"direction"`       // "up", "down", "neutral"
-	Probability    float64 `json:"probability"`     // 概率
-	ExpectedMove   float64 `json:"expected_move"`   // 预期幅度%
-	Confidence     string  `json:"confidence"`      // "low", "medium", "high"
-	Reasoning      string  `json:"reasoning"`       // 推理
+	Direction    string  `json:"direction"`     // "up", "down", "neutral"
+	Probability  float64 `json:"probability"`   // 概率
+	ExpectedMove float64 `json:"expected_move"` // 预期幅度%
+	Confidence   string  `json:"confidence"`    // "low", "medium", "high"
+	Reasoning    string  `json:"reasoning"`     // 推理
 }
 
 // AltcoinScanner 山寨币异动扫描器
 type AltcoinScanner struct {
-	client        *futures.Client
-	excludeList   []string // 排除的币种（主流币）
-	scanInterval  time.Duration
+	client       *futures.Client
+	excludeList  []string // 排除的币种（主流币）
+	scanInterval time.Duration
 
 	// 🆕 中期阈值（建议建仓 - 更宽松）
-	midOIChangeThreshold      float64 // OI变化阈值 (中期: 25%)
-	midPriceChangeThreshold   float64 // 价格变化阈值 (中期: 5%)
-	midVolumeChangeThreshold  float64 // 成交量变化阈值 (中期: 150%)
-	midFundingRateThreshold   float64 // 资金费率阈值 (中期: 0.20%)
+	midOIChangeThreshold     float64 // OI变化阈值 (中期: 25%)
+	midPriceChangeThreshold  float64 // 价格变化阈值 (中期: 5%)
+	midVolumeChangeThreshold float64 // 成交量变化阈值 (中期: 150%)
+	midFundingRateThreshold  float64 // 资金费率阈值 (中期: 0.20%)
 
 	// 晚期阈值（慎重追高 - 严格）
-	lateOIChangeThreshold      float64 // OI变化阈值 (晚期: 50%)
-	latePriceChangeThreshold   float64 // 价格变化阈值 (晚期: 10%)
-	lateVolumeChangeThreshold  float64 // 成交量变化阈值 (晚期: 300%)
-	lateFundingRateThreshold   float64 // 资金费率阈值 (晚期: 0.30%)
-	largeOrderThreshold        float64 // 大单占比阈值 (默认40%)
+	lateOIChangeThreshold     float64 // OI变化阈值 (晚期: 50%)
+	latePriceChangeThreshold  float64 // 价格变化阈值 (晚期: 10%)
+	lateVolumeChangeThreshold float64 // 成交量变化阈值 (晚期: 300%)
+	lateFundingRateThreshold  float64 // 资金费率阈值 (晚期: 0.30%)
+	largeOrderThreshold       float64 // 大单占比阈值 (默认40%)
 
 	// 流动性阈值
-	minOIValue     float64 // 最小OI价值 (默认15M USD)
-	minVolume24h   float64 // 最小24h成交量 (默认50M USD)
-	minDepth       float64 // 最小订单簿深度 (默认1M USD)
-
-	mu                sync.RWMutex
-	lastScanTime      time.Time
-	scanCount         int
-	signalCount       int
+	minOIValue   float64 // 最小OI价值 (默认15M USD)
+	minVolume24h float64 // 最小24h成交量 (默认50M USD)
+	minDepth     float64 // 最小订单簿深度 (默认1M USD)
+
+	mu                 sync.RWMutex
+	lastScanTime       time.Time
+	scanCount          int
+	signalCount        int
 	lastScannedSymbols int // 上次扫描的币种数量
 }
 
@@ -242,6 +243,11 @@ func (s *AltcoinScanner) ScanTop50(top50Symbols []string) ([]*AnomalySignal, err
 
 // scanSymbol 扫描单个币种
 func (s *AltcoinScanner) scanSymbol(symbol string) (*AnomalySignal, error) {
+	// 🆕 扫描属于非关键请求，限流预算不足或冷却中时直接跳过（保留权重给交易请求）
+	if !ratelimit.For(ratelimit.Binance).AllowNonCritical() {
+		return nil, fmt.Errorf("限流预算不足，跳过 %s", symbol)
+	}
+
 	// 1. 获取当前价格
 	currentPrice, err := s.getCurrentPrice(symbol)
 	if err != nil {
@@ -358,12 +364,12 @@ func (s *AltcoinScanner) scanSymbol(symbol string) (*AnomalySignal, error) {
 	}
 
 	// === 加权综合评分 ===
-	weightedScore := (oiScore * 0.25) +       // OI 25%
-		(volumeScore * 0.20) +                 // 成交量 20%
-		(fundingScore * 0.20) +                // 资金费率 20%
-		(priceScore * 0.15) +                  // 价格 15%
-		(depthScore * 0.10) +                  // 深度 10%
-		(liquidityScore * 0.10)                // 流动性 10%
+	weightedScore := (oiScore * 0.25) + // OI 25%
+		(volumeScore * 0.20) + // 成交量 20%
+		(fundingScore * 0.20) + // 资金费率 20%
+		(priceScore * 0.15) + // 价格 15%
+		(depthScore * 0.10) + // 深度 10%
+		(liquidityScore * 0.10) // 流动性 10%
 
 	// 转换为星级（1-5星）
 	confidence := int(math.Round(weightedScore))
@@ -429,11 +435,11 @@ func (s *AltcoinScanner) scanSymbol(symbol string) (*AnomalySignal, error) {
 	var stopLoss, takeProfit float64
 
 	if direction == "up" {
-		stopLoss = currentPrice - (atrEstimate * 3)     // -9% 止损
-		takeProfit = currentPrice + (atrEstimate * 6)   // +18% 止盈
+		stopLoss = currentPrice - (atrEstimate * 3)   // -9% 止损
+		takeProfit = currentPrice + (atrEstimate * 6) // +18% 止盈
 	} else {
-		stopLoss = currentPrice + (atrEstimate * 3)     // +9% 止损
-		takeProfit = currentPrice - (atrEstimate * 6)   // -18% 止盈
+		stopLoss = currentPrice + (atrEstimate * 3)   // +9% 止损
+		takeProfit = currentPrice - (atrEstimate * 6) // -18% 止盈
 	}
 
 	// 风险收益比
@@ -468,7 +474,7 @@ func (s *AltcoinScanner) scanSymbol(symbol string) (*AnomalySignal, error) {
 
 // getAllUSDTSymbols 获取所有USDT合约
 func (s *AltcoinScanner) getAllUSDTSymbols() ([]string, error) {
-	exchangeInfo, err := s.client.NewExchangeInfoService().Do(context.Background())
+	exchangeInfo, err := s.client.NewExchangeInfoService().Do(ratelimit.NonCritical(context.Background()))
 	if err != nil {
 		return nil, err
 	}
@@ -486,7 +492,7 @@ func (s *AltcoinScanner) getAllUSDTSymbols() ([]string, error) {
 // getTop50ByVolume 获取24h成交量Top50的USDT合约（排除主流币）
 func (s *AltcoinScanner) getTop50ByVolume() ([]string, error) {
 	// 获取所有合约的24h ticker数据（一次API调用）
-	tickers, err := s.client.NewListPriceChangeStatsService().Do(context.Background())
+	tickers, err := s.client.NewListPriceChangeStatsService().Do(ratelimit.NonCritical(context.Background()))
 	if err != nil {
 		return nil, err
 	}
@@ -547,7 +553,7 @@ func (s *AltcoinScanner) isExcluded(symbol string) bool {
 
 // getCurrentPrice 获取当前价格
 func (s *AltcoinScanner) getCurrentPrice(symbol string) (float64, error) {
-	prices, err := s.client.NewListPricesService().Symbol(symbol).Do(context.Background())
+	prices, err := s.client.NewListPricesService().Symbol(symbol).Do(ratelimit.NonCritical(context.Background()))
 	if err != nil {
 		return 0, err
 	}
@@ -602,7 +608,7 @@ func (s *AltcoinScanner) checkPriceChange(symbol string) (float64, error) {
 		Symbol(symbol).
 		Interval("15m").
 		Limit(2). // 获取最近2根K线
-		Do(context.Background())
+		Do(ratelimit.NonCritical(context.Background()))
 
 	if err != nil || len(klines) < 2 {
 		return 0, err
@@ -624,7 +630,7 @@ func (s *AltcoinScanner) checkPriceChange(symbol string) (float64, error) {
 // checkVolumeChange 检查成交量1小时变化
 func (s *AltcoinScanner) checkVolumeChange(symbol string) (changePercent, volume24h float64, err error) {
 	// 获取24h ticker
-	ticker, err := s.client.NewListPriceChangeStatsService().Symbol(symbol).Do(context.Background())
+	ticker, err := s.client.NewListPriceChangeStatsService().Symbol(symbol).Do(ratelimit.NonCritical(context.Background()))
 	if err != nil || len(ticker) == 0 {
 		return 0, 0, err
 	}
@@ -642,7 +648,7 @@ func (s *AltcoinScanner) getFundingRate(symbol string) (float64, error) {
 	rates, err := s.client.NewFundingRateService().
 		Symbol(symbol).
 		Limit(1).
-		Do(context.Background())
+		Do(ratelimit.NonCritical(context.Background()))
 
 	if err != nil || len(rates) == 0 {
 		return 0, err
@@ -657,7 +663,7 @@ func (s *AltcoinScanner) getOrderBookDepth(symbol string, currentPrice float64)
 	depth, err := s.client.NewDepthService().
 		Symbol(symbol).
 		Limit(100).
-		Do(context.Background())
+		Do(ratelimit.NonCritical(context.Background()))
 
 	if err != nil {
 		return 0, err
@@ -697,6 +703,7 @@ func (s *AltcoinScanner) GetStatistics() map[string]interface{} {
 		"last_scan":     s.lastScanTime.Format("2006-01-02 15:04:05"),
 	}
 }
+
 // GetLastScannedCount 获取上次扫描的币种数量
 func (s *AltcoinScanner) GetLastScannedCount() int {
 	s.mu.RLock()
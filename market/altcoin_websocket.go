@@ -66,31 +66,31 @@ type WSTickerMessage struct {
 
 // DarkHorseSignal 黑马信号（突然冲榜的币种）
 type DarkHorseSignal struct {
-	Symbol           string    `json:"symbol"`
-	Timestamp        time.Time `json:"timestamp"`
-	CurrentRank      int       `json:"current_rank"`       // 当前排名
-	PreviousRank     int       `json:"previous_rank"`      // 之前排名（0表示不在Top50）
-	RankJump         int       `json:"rank_jump"`          // 排名跃升
-	Volume24h        float64   `json:"volume_24h"`         // 24h成交量
-	VolumeIncreasePct float64  `json:"volume_increase_pct"` // 成交量增幅%
-	PriceChangePct   float64   `json:"price_change_pct"`   // 24h价格变化%
-	Confidence       int       `json:"confidence"`         // 1-3星
-	SignalType       string    `json:"signal_type"`        // "early"
-	Reasoning        string    `json:"reasoning"`          // 信号原因
+	Symbol            string    `json:"symbol"`
+	Timestamp         time.Time `json:"timestamp"`
+	CurrentRank       int       `json:"current_rank"`        // 当前排名
+	PreviousRank      int       `json:"previous_rank"`       // 之前排名（0表示不在Top50）
+	RankJump          int       `json:"rank_jump"`           // 排名跃升
+	Volume24h         float64   `json:"volume_24h"`          // 24h成交量
+	VolumeIncreasePct float64   `json:"volume_increase_pct"` // 成交量增幅%
+	PriceChangePct    float64   `json:"price_change_pct"`    // 24h价格变化%
+	Confidence        int       `json:"confidence"`          // 1-3星
+	SignalType        string    `json:"signal_type"`         // "early"
+	Reasoning         string    `json:"reasoning"`           // 信号原因
 }
 
 // AltcoinWSMonitor 山寨币WebSocket监控器
 type AltcoinWSMonitor struct {
-	wsURL              string
-	conn               *websocket.Conn
-	tickers            map[string]*TickerData // symbol -> ticker
-	top50Symbols       []string                // Top50币种列表
-	previousTop50      map[string]int          // 上一次Top50 (symbol -> rank)
-	excludeList        []string                // 排除的主流币
-	mu                 sync.RWMutex
-	isRunning          bool
-	reconnectChan      chan struct{}
-	darkHorseCallback  func(*DarkHorseSignal)  // 黑马信号回调
+	wsURL             string
+	conn              *websocket.Conn
+	tickers           map[string]*TickerData // symbol -> ticker
+	top50Symbols      []string               // Top50币种列表
+	previousTop50     map[string]int         // 上一次Top50 (symbol -> rank)
+	excludeList       []string               // 排除的主流币
+	mu                sync.RWMutex
+	isRunning         bool
+	reconnectChan     chan struct{}
+	darkHorseCallback func(*DarkHorseSignal) // 黑马信号回调
 }
 
 // NewAltcoinWSMonitor 创建WebSocket监控器
@@ -159,7 +159,9 @@ func (m *AltcoinWSMonitor) connect() error {
 		return fmt.Errorf("拨号失败: %w", err)
 	}
 
+	m.mu.Lock()
 	m.conn = conn
+	m.mu.Unlock()
 	log.Println("✅ WebSocket连接成功: wss://fstream.binance.com")
 
 	return nil
@@ -170,7 +172,9 @@ func (m *AltcoinWSMonitor) receiveMessages() {
 	defer func() {
 		if m.conn != nil {
 			m.conn.Close()
+			m.mu.Lock()
 			m.conn = nil
+			m.mu.Unlock()
 		}
 	}()
 
@@ -308,7 +312,7 @@ func (m *AltcoinWSMonitor) calculateTop50() {
 			// 情况1: 新进入Top50（之前不在榜单）
 			if !existed {
 				m.detectDarkHorse(symbol, currentRank, 0, candidates[currentRank-1].volume)
-			} else if previousRank - currentRank >= 10 {
+			} else if previousRank-currentRank >= 10 {
 				// 情况2: 排名大幅跃升（上升10名以上）
 				m.detectDarkHorse(symbol, currentRank, previousRank, candidates[currentRank-1].volume)
 			}
@@ -356,17 +360,17 @@ func (m *AltcoinWSMonitor) detectDarkHorse(symbol string, currentRank, previousR
 
 	// 构建信号
 	signal := &DarkHorseSignal{
-		Symbol:           symbol,
-		Timestamp:        time.Now(),
-		CurrentRank:      currentRank,
-		PreviousRank:     previousRank,
-		RankJump:         rankJump,
-		Volume24h:        volume24h,
+		Symbol:            symbol,
+		Timestamp:         time.Now(),
+		CurrentRank:       currentRank,
+		PreviousRank:      previousRank,
+		RankJump:          rankJump,
+		Volume24h:         volume24h,
 		VolumeIncreasePct: 0, // 暂时无法计算历史对比
-		PriceChangePct:   priceChangePct,
-		Confidence:       confidence,
-		SignalType:       "early",
-		Reasoning:        m.buildDarkHorseReasoning(currentRank, previousRank, rankJump, volume24h),
+		PriceChangePct:    priceChangePct,
+		Confidence:        confidence,
+		SignalType:        "early",
+		Reasoning:         m.buildDarkHorseReasoning(currentRank, previousRank, rankJump, volume24h),
 	}
 
 	// 输出日志
@@ -394,6 +398,13 @@ func (m *AltcoinWSMonitor) buildDarkHorseReasoning(currentRank, previousRank, ra
 		previousRank, currentRank, rankJump, volume24h/1_000_000)
 }
 
+// Connected WebSocket当前是否已连接
+func (m *AltcoinWSMonitor) Connected() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.isRunning && m.conn != nil
+}
+
 // GetTop50Symbols 获取当前Top50列表
 func (m *AltcoinWSMonitor) GetTop50Symbols() []string {
 	m.mu.RLock()
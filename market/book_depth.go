@@ -0,0 +1,60 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// BookDepthUSD 盘口一侧在中间价±bandPct%范围内的挂单名义价值（USD）
+// side="buy" 统计会被买单吃掉的卖盘，side="sell" 统计买盘
+func BookDepthUSD(symbol, side string, bandPct float64) (float64, error) {
+	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/depth?symbol=%s&limit=100", symbol)
+	resp, err := httpGetWithRateLimit(url)
+	if err != nil {
+		return 0, fmt.Errorf("获取订单簿失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != 200 {
+		return 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var book OrderBookData
+	if err := json.Unmarshal(body, &book); err != nil {
+		return 0, fmt.Errorf("订单簿JSON解析失败: %w", err)
+	}
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return 0, fmt.Errorf("订单簿数据为空")
+	}
+
+	bestBid, _ := strconv.ParseFloat(book.Bids[0][0], 64)
+	bestAsk, _ := strconv.ParseFloat(book.Asks[0][0], 64)
+	mid := (bestBid + bestAsk) / 2
+
+	levels := book.Asks
+	inBand := func(price float64) bool { return price <= mid*(1+bandPct/100) }
+	if side == "sell" {
+		levels = book.Bids
+		inBand = func(price float64) bool { return price >= mid*(1-bandPct/100) }
+	}
+
+	depth := 0.0
+	for _, level := range levels {
+		if len(level) < 2 {
+			continue
+		}
+		price, _ := strconv.ParseFloat(level[0], 64)
+		qty, _ := strconv.ParseFloat(level[1], 64)
+		if !inBand(price) {
+			break
+		}
+		depth += price * qty
+	}
+	return depth, nil
+}
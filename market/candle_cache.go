@@ -0,0 +1,237 @@
+package market
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxCachedCandles 每个币种/周期在磁盘上保留的K线数量（5分钟K线约17天）
+const maxCachedCandles = 5000
+
+// CandleCache K线磁盘缓存：每个币种/周期一个CSV文件（<dir>/<SYMBOL>_<interval>.csv）
+// 启动后首次请求从磁盘加载，只向API补齐缺失的最新K线；只保存已收盘的K线
+type CandleCache struct {
+	dir string
+
+	mu      sync.Mutex
+	candles map[string][]Kline // symbol_interval -> 已收盘K线（按时间升序）
+}
+
+var (
+	candleCacheMu sync.RWMutex
+	candleCache   *CandleCache
+)
+
+// NewCandleCache 创建K线磁盘缓存
+func NewCandleCache(dir string) (*CandleCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建K线缓存目录失败: %w", err)
+	}
+	return &CandleCache{dir: dir, candles: make(map[string][]Kline)}, nil
+}
+
+// SetCandleCache 设置K线磁盘缓存（nil表示不缓存）
+func SetCandleCache(c *CandleCache) {
+	candleCacheMu.Lock()
+	defer candleCacheMu.Unlock()
+	candleCache = c
+}
+
+func currentCandleCache() *CandleCache {
+	candleCacheMu.RLock()
+	defer candleCacheMu.RUnlock()
+	return candleCache
+}
+
+func (c *CandleCache) path(symbol, interval string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s_%s.csv", symbol, interval))
+}
+
+// Klines 获取最近limit根K线：缓存覆盖的部分直接使用，只向API请求缓存之后的K线
+// fetch 为API请求（参数为需要的K线根数，返回按时间升序的K线，最后一根可能未收盘）
+func (c *CandleCache) Klines(symbol, interval string, limit int, fetch func(limit int) ([]Kline, error)) ([]Kline, error) {
+	key := symbol + "_" + interval
+	intervalMs := int64(getIntervalMinutes(interval)) * 60 * 1000
+
+	c.mu.Lock()
+	cached, ok := c.candles[key]
+	if !ok {
+		cached = c.loadFile(symbol, interval)
+		c.candles[key] = cached
+	}
+	c.mu.Unlock()
+
+	// 需要补齐的K线数：缓存最后一根之后的全部K线（+1根当前未收盘K线）
+	need := limit
+	if len(cached) > 0 {
+		last := cached[len(cached)-1].OpenTime
+		missing := int((time.Now().UnixMilli()-last)/intervalMs) + 1
+		if missing < limit {
+			need = missing
+		}
+	}
+	if need < 2 {
+		need = 2
+	}
+
+	fetched, err := fetch(need)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := mergeKlines(cached, fetched, intervalMs)
+
+	// 只缓存已收盘K线（最后一根可能未收盘）
+	closed := merged
+	if len(closed) > 0 && closed[len(closed)-1].CloseTime > time.Now().UnixMilli() {
+		closed = closed[:len(closed)-1]
+	}
+	if len(closed) > maxCachedCandles {
+		closed = closed[len(closed)-maxCachedCandles:]
+	}
+
+	c.mu.Lock()
+	changed := len(closed) != len(cached) ||
+		(len(closed) > 0 && closed[len(closed)-1].OpenTime != cached[len(cached)-1].OpenTime)
+	c.candles[key] = closed
+	c.mu.Unlock()
+
+	if changed {
+		if err := c.saveFile(symbol, interval, closed); err != nil {
+			log.Printf("⚠️  保存K线缓存失败 %s: %v", key, err)
+		}
+	}
+
+	if len(merged) > limit {
+		merged = merged[len(merged)-limit:]
+	}
+	return merged, nil
+}
+
+// mergeKlines 合并缓存与新获取的K线（相同开盘时间以新数据为准）；两者之间有缺口时丢弃缓存
+func mergeKlines(cached, fetched []Kline, intervalMs int64) []Kline {
+	if len(fetched) == 0 {
+		return append([]Kline(nil), cached...)
+	}
+	first := fetched[0].OpenTime
+
+	keep := 0
+	for keep < len(cached) && cached[keep].OpenTime < first {
+		keep++
+	}
+	if keep > 0 && cached[keep-1].OpenTime+intervalMs != first {
+		return append([]Kline(nil), fetched...) // 有缺口，缓存不可用
+	}
+
+	merged := make([]Kline, 0, keep+len(fetched))
+	merged = append(merged, cached[:keep]...)
+	return append(merged, fetched...)
+}
+
+// LoadCachedKlines 读取磁盘缓存中的已收盘K线（供回测等离线分析使用，不请求API）
+func LoadCachedKlines(symbol, interval string) ([]Kline, error) {
+	c := currentCandleCache()
+	if c == nil {
+		return nil, fmt.Errorf("未启用K线缓存")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := symbol + "_" + interval
+	cached, ok := c.candles[key]
+	if !ok {
+		cached = c.loadFile(symbol, interval)
+		c.candles[key] = cached
+	}
+	return append([]Kline(nil), cached...), nil
+}
+
+// loadFile 从CSV加载K线（文件不存在或损坏时返回空，由API重新获取）
+func (c *CandleCache) loadFile(symbol, interval string) []Kline {
+	f, err := os.Open(c.path(symbol, interval))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		log.Printf("⚠️  K线缓存损坏 %s_%s，重新获取: %v", symbol, interval, err)
+		return nil
+	}
+
+	klines := make([]Kline, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 7 {
+			continue
+		}
+		openTime, err := strconv.ParseInt(row[0], 10, 64)
+		if err != nil {
+			continue // 表头或无效行
+		}
+		closeTime, _ := strconv.ParseInt(row[6], 10, 64)
+		k := Kline{OpenTime: openTime, CloseTime: closeTime}
+		k.Open, _ = strconv.ParseFloat(row[1], 64)
+		k.High, _ = strconv.ParseFloat(row[2], 64)
+		k.Low, _ = strconv.ParseFloat(row[3], 64)
+		k.Close, _ = strconv.ParseFloat(row[4], 64)
+		k.Volume, _ = strconv.ParseFloat(row[5], 64)
+		klines = append(klines, k)
+	}
+	if len(klines) > 0 {
+		log.Printf("💾 从磁盘加载K线缓存 %s %s: %d根", symbol, interval, len(klines))
+	}
+	return klines
+}
+
+// saveFile 写入CSV（临时文件+重命名，避免写入中途崩溃损坏缓存）
+func (c *CandleCache) saveFile(symbol, interval string, klines []Kline) error {
+	path := c.path(symbol, interval)
+	tmpPath := path + ".tmp"
+
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"open_time", "open", "high", "low", "close", "volume", "close_time"})
+	for _, k := range klines {
+		w.Write([]string{
+			strconv.FormatInt(k.OpenTime, 10),
+			strconv.FormatFloat(k.Open, 'f', -1, 64),
+			strconv.FormatFloat(k.High, 'f', -1, 64),
+			strconv.FormatFloat(k.Low, 'f', -1, 64),
+			strconv.FormatFloat(k.Close, 'f', -1, 64),
+			strconv.FormatFloat(k.Volume, 'f', -1, 64),
+			strconv.FormatInt(k.CloseTime, 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// fetchKlines 通过API获取K线，启用磁盘缓存时只补齐缓存之后的部分
+func fetchKlines(symbol, interval string, limit int) ([]Kline, error) {
+	c := currentCandleCache()
+	if c == nil {
+		return getKlines(symbol, interval, limit)
+	}
+	return c.Klines(symbol, interval, limit, func(n int) ([]Kline, error) {
+		return getKlines(symbol, interval, n)
+	})
+}
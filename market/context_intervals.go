@@ -0,0 +1,155 @@
+package market
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// 🕰️ 分用途K线周期：日内序列使用 SetDefaultInterval 的周期，长期背景和入场时机检查可以各自使用独立周期
+// 未配置时与日内周期相同（保持原有行为：长期背景的ATR14用于止损计算，切换周期会显著改变止损距离）
+
+// minContextKlines 独立周期至少获取的K线数量（保证EMA200就绪）
+const minContextKlines = 250
+
+var (
+	contextIntervalMu   sync.RWMutex
+	longTermInterval    string // 长期背景周期（空=与日内周期相同）
+	entryTimingInterval string // 入场时机检查周期（空=与日内周期相同）
+
+	contextKlinesMu sync.Mutex
+	contextKlines   = make(map[string]contextKlineEntry) // source|symbol|interval → 已收盘K线
+)
+
+// contextKlineEntry 独立周期的已收盘K线缓存（当前K线收盘前不会变化）
+type contextKlineEntry struct {
+	confirmed  []Kline
+	validUntil time.Time
+}
+
+// SetContextIntervals 设置长期背景和入场时机检查的K线周期（空字符串表示与日内周期相同）
+func SetContextIntervals(longTerm, entryTiming string) {
+	contextIntervalMu.Lock()
+	longTermInterval = longTerm
+	entryTimingInterval = entryTiming
+	contextIntervalMu.Unlock()
+
+	if longTerm != "" || entryTiming != "" {
+		log.Printf("📊 [Market Data] 长期背景周期: %s, 入场时机周期: %s（空=与日内周期相同）", longTerm, entryTiming)
+	}
+}
+
+// contextIntervals 当前生效的长期背景/入场时机周期（未配置时返回日内周期）
+func contextIntervals(intraday string) (longTerm, entryTiming string) {
+	contextIntervalMu.RLock()
+	defer contextIntervalMu.RUnlock()
+	longTerm, entryTiming = longTermInterval, entryTimingInterval
+	if longTerm == "" {
+		longTerm = intraday
+	}
+	if entryTiming == "" {
+		entryTiming = intraday
+	}
+	return longTerm, entryTiming
+}
+
+// KlineIntervals 各用途当前生效的K线周期（状态API）
+func KlineIntervals() map[string]string {
+	longTerm, entryTiming := contextIntervals(defaultInterval)
+	return map[string]string{
+		"intraday":     defaultInterval,
+		"long_term":    longTerm,
+		"entry_timing": entryTiming,
+	}
+}
+
+// contextKlineLimit 独立周期的K线数量（覆盖约25小时，且不少于 minContextKlines）
+func contextKlineLimit(interval string) int {
+	if limit := calculateKlineLimit(interval); limit > minContextKlines {
+		return limit
+	}
+	return minContextKlines
+}
+
+// contextIndicators 按独立周期获取已收盘K线并推进该周期的指标引擎
+// 已收盘K线在当前K线收盘前不会变化，缓存到收盘时间，4h周期不会每个决策周期都重新拉取
+func contextIndicators(src Source, symbol, interval string) (*indicatorEngine, []Kline, error) {
+	key := sourceKey(src, symbol) + "|" + interval
+
+	contextKlinesMu.Lock()
+	entry, ok := contextKlines[key]
+	contextKlinesMu.Unlock()
+
+	if !ok || time.Now().After(entry.validUntil) {
+		klines, err := src.Klines(symbol, interval, contextKlineLimit(interval))
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取%s K线失败: %w", interval, err)
+		}
+		if len(klines) < 2 {
+			return nil, nil, fmt.Errorf("%s K线数据不足", interval)
+		}
+		forming := klines[len(klines)-1]
+		entry = contextKlineEntry{
+			confirmed:  klines[:len(klines)-1], // 排除未收盘K线（避免前视偏差）
+			validUntil: time.UnixMilli(forming.CloseTime),
+		}
+		contextKlinesMu.Lock()
+		contextKlines[key] = entry
+		contextKlinesMu.Unlock()
+	}
+
+	return advanceIndicators(sourceKey(src, symbol), interval, entry.confirmed), entry.confirmed, nil
+}
+
+// EntryTimingData 入场时机周期的指标（仅在入场时机周期与日内周期不同时计算）
+type EntryTimingData struct {
+	Interval string
+	RSI7     float64
+	RSI14    float64
+	Context  *LongerTermData // EMA20/EMA50/ATR14 等
+}
+
+// ForEntryTiming 入场时机检查使用的数据视图：配置了独立周期时，RSI和EMA/ATR替换为该周期的值
+func (d *Data) ForEntryTiming() *Data {
+	if d == nil || d.EntryTiming == nil {
+		return d
+	}
+	view := *d
+	view.CurrentRSI7 = d.EntryTiming.RSI7
+	view.CurrentRSI14 = d.EntryTiming.RSI14
+	view.LongerTermContext = d.EntryTiming.Context
+	return &view
+}
+
+// attachContextIntervals 按配置的长期背景/入场时机周期补充数据（失败时保留日内周期的数据）
+func attachContextIntervals(src Source, data *Data, intraday string) {
+	longTerm, entryTiming := contextIntervals(intraday)
+
+	if longTerm != intraday {
+		if engine, confirmed, err := contextIndicators(src, data.Symbol, longTerm); err != nil {
+			log.Printf("⚠️  %s 长期背景(%s)获取失败，使用%s数据: %v", data.Symbol, longTerm, intraday, err)
+		} else {
+			data.LongerTermContext = engine.longerTermData(confirmed)
+			data.LongerTermContext.Interval = longTerm
+		}
+	}
+
+	if entryTiming == intraday {
+		return
+	}
+	// 与长期背景周期相同时复用缓存的K线和指标引擎
+	engine, confirmed, err := contextIndicators(src, data.Symbol, entryTiming)
+	if err != nil {
+		log.Printf("⚠️  %s 入场时机(%s)数据获取失败，使用%s数据: %v", data.Symbol, entryTiming, intraday, err)
+		return
+	}
+	timingContext := engine.longerTermData(confirmed)
+	timingContext.Interval = entryTiming
+	data.EntryTiming = &EntryTimingData{
+		Interval: entryTiming,
+		RSI7:     engine.rsi7.value(),
+		RSI14:    engine.rsi14.value(),
+		Context:  timingContext,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"math"
 	"net/http"
+	"nofx/ratelimit"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,7 +16,8 @@ import (
 
 // httpClient 带超时的HTTP客户端（10秒超时，避免阻塞）
 var httpClient = &http.Client{
-	Timeout: 10 * time.Second,
+	Timeout:   10 * time.Second,
+	Transport: ratelimit.NewTransport(nil), // 🆕 按交易所统一限流预算
 }
 
 type marketCacheEntry struct {
@@ -32,8 +34,8 @@ var (
 	minBinanceInterval = 150 * time.Millisecond
 
 	// 🎛️ K线周期配置（可通过 SetDefaultInterval 动态设置）
-	defaultInterval = "5m"  // 默认5分钟K线
-	defaultLimit    = 300   // 默认获取300根K线
+	defaultInterval = "5m" // 默认5分钟K线
+	defaultLimit    = 300  // 默认获取300根K线
 )
 
 // SetDefaultInterval 设置全局K线周期（在trader启动时调用）
@@ -43,6 +45,9 @@ func SetDefaultInterval(interval string) {
 
 	defaultInterval = interval
 	defaultLimit = limit
+	if stream := currentKlineStream(); stream != nil {
+		stream.setInterval(interval, limit)
+	}
 	log.Printf("📊 [Market Data] K线周期设置为 %s (获取 %d 根K线)", interval, limit)
 }
 
@@ -145,9 +150,16 @@ type Data struct {
 	CurrentMinusDI    float64 // 🆕 -DI方向指标
 	Volume24h         float64 // 🆕 24小时成交额(USDT)
 	OpenInterest      *OIData
+	OIChange1h        float64          // 🆕 1小时OI变化百分比（openInterestHist）
+	OIChange4h        float64          // 🆕 4小时OI变化百分比
+	OIChange24h       float64          // 🆕 24小时OI变化百分比
+	OIPriceSignal     string           // 🆕 4小时价格与OI关系：long_build/short_covering/short_build/long_unwinding（空=无明显信号）
+	OIDivergence      bool             // 🆕 价格与OI反向变动（short_covering/long_unwinding）
+	Positioning       *PositioningData // 🆕 多空持仓结构（获取失败时为nil）
 	FundingRate       float64
 	IntradaySeries    *IntradayData
 	LongerTermContext *LongerTermData
+	EntryTiming       *EntryTimingData // 🕰️ 入场时机周期的指标（未单独配置周期时为nil，见 ForEntryTiming）
 
 	// 🎯 支撑位/阻力位（用于限价单定价）
 	NearestSupport    float64   // 最近支撑位（距当前价最近的下方价格水平）
@@ -155,18 +167,19 @@ type Data struct {
 	SupportLevels     []float64 // 多个支撑位（按距离当前价从近到远排序）
 	ResistanceLevels  []float64 // 多个阻力位（按距离当前价从近到远排序）
 
-	Timestamp         int64 // 最新K线收盘时间（Unix秒）
+	Timestamp int64 // 最新K线收盘时间（Unix秒）
 }
 
 // OIData Open Interest数据
 type OIData struct {
 	Latest float64
 	// ⚠️ 移除了 Average 字段：之前使用 oi * 0.999 伪造数据，误导AI分析
-	// 如需真实平均OI，应调用 openInterestHist API 计算
+	// OI历史变化见 Data.OIChange1h/4h/24h（来自 openInterestHist）
 }
 
-// IntradayData 日内数据(3分钟间隔)
+// IntradayData 日内数据（周期见 Interval，由 SetDefaultInterval 设置）
 type IntradayData struct {
+	Interval    string
 	MidPrices   []float64
 	EMA20Values []float64
 	MACDValues  []float64
@@ -174,8 +187,9 @@ type IntradayData struct {
 	RSI14Values []float64
 }
 
-// LongerTermData 长期数据(4小时时间框架)
+// LongerTermData 长期数据（周期见 Interval，默认与日内周期相同，可通过 SetContextIntervals 单独设置）
 type LongerTermData struct {
+	Interval      string
 	EMA20         float64
 	EMA50         float64
 	EMA200        float64 // ✅ 添加EMA200用于趋势判断
@@ -198,32 +212,41 @@ type Kline struct {
 	CloseTime int64
 }
 
-// Get 获取指定代币的市场数据
+// Get 获取指定代币的市场数据（币安数据源）
 func Get(symbol string) (*Data, error) {
+	return GetFrom(nil, symbol)
+}
+
+// GetFrom 从指定数据源获取市场数据（src为nil时使用币安），缓存按数据源隔离
+func GetFrom(src Source, symbol string) (*Data, error) {
+	if src == nil {
+		src = binanceSource{}
+	}
 	// 标准化symbol
 	symbol = Normalize(symbol)
+	key := sourceKey(src, symbol)
 
-	if cached := getMarketCache(symbol); cached != nil {
+	if cached := getMarketCache(key); cached != nil {
 		return cached, nil
 	}
 
-	data, err := computeMarketData(symbol)
+	data, err := computeMarketData(src, symbol)
 	if err != nil {
-		if stale := getMarketCacheWithoutTTL(symbol); stale != nil {
-			log.Printf("⚠️  使用缓存市场数据 %s: 获取最新行情失败: %v", symbol, err)
+		if stale := getMarketCacheWithoutTTL(key); stale != nil {
+			log.Printf("⚠️  使用缓存市场数据 %s: 获取最新行情失败: %v", key, err)
 			return stale, nil
 		}
 		return nil, err
 	}
 
-	setMarketCache(symbol, data)
+	setMarketCache(key, data)
 	return data, nil
 }
 
-func computeMarketData(symbol string) (*Data, error) {
+func computeMarketData(src Source, symbol string) (*Data, error) {
 	// 🔧 使用动态K线周期配置（通过 SetDefaultInterval 设置）
 	// 获取K线数据 (足够多以计算EMA200)
-	klines, err := getKlines(symbol, defaultInterval, defaultLimit)
+	klines, err := src.Klines(symbol, defaultInterval, defaultLimit)
 	if err != nil {
 		return nil, fmt.Errorf("获取%s K线失败: %v", defaultInterval, err)
 	}
@@ -233,15 +256,17 @@ func computeMarketData(symbol string) (*Data, error) {
 	if len(klines) < 2 {
 		return nil, fmt.Errorf("K线数据不足")
 	}
-	confirmedKlines := klines[:len(klines)-1] // 只使用已收盘的K线
+	confirmedKlines := klines[:len(klines)-1]   // 只使用已收盘的K线
 	currentPrice := klines[len(klines)-1].Close // 实时价格（用于显示）
 
 	// 计算当前指标 (全部基于已收盘的K线，避免未来信息泄露)
-	currentEMA20 := calculateEMA(confirmedKlines, 20)
-	currentMACD := calculateMACD(confirmedKlines)
-	macdSignal := calculateMACDSignal(confirmedKlines) // 🆕 MACD信号线
-	currentRSI7 := calculateRSI(confirmedKlines, 7)
-	currentRSI14 := calculateRSI(confirmedKlines, 14) // 🆕 RSI14
+	// 🧮 增量指标引擎：只推进上次之后新收盘的K线
+	indicators := advanceIndicators(sourceKey(src, symbol), defaultInterval, confirmedKlines)
+	currentEMA20 := emaValue(indicators.ema20)
+	currentMACD := indicators.macd.value()
+	macdSignal := indicators.macd.signalValue() // 🆕 MACD信号线
+	currentRSI7 := indicators.rsi7.value()
+	currentRSI14 := indicators.rsi14.value()                                       // 🆕 RSI14
 	currentADX, currentPlusDI, currentMinusDI := calculateADX(confirmedKlines, 14) // 🆕 ADX趋势强度
 
 	// 🎯 根据K线周期动态计算索引
@@ -259,26 +284,42 @@ func computeMarketData(symbol string) (*Data, error) {
 	// 🆕 计算24小时成交额（基于已收盘K线）
 	volume24h := calculate24hVolume(confirmedKlines, 1440, intervalMinutes)
 
-	// 获取OI数据
-	oiData, err := getOpenInterestData(symbol)
+	// 获取OI数据（来自当前数据源）
+	oiData := &OIData{Latest: 0}
+	if oi, err := src.OpenInterest(symbol); err == nil {
+		oiData.Latest = oi
+	} // OI失败不影响整体,使用默认值
+
+	// 🆕 OI历史变化（失败时保持为0，不影响整体）
+	// OI历史只有币安提供：其他数据源的持仓量口径不同，变化率只用币安自身的最新值计算
+	latestForChanges := oiData.Latest
+	if src.Name() != SourceBinance {
+		latestForChanges = 0
+	}
+	oiChanges, err := getOIChanges(symbol, latestForChanges)
 	if err != nil {
-		// OI失败不影响整体,使用默认值
-		oiData = &OIData{Latest: 0}
+		oiChanges = &OIChanges{}
 	}
+	oiPriceSignal := ClassifyOIPrice(priceChange4h, oiChanges.Change4h)
+
+	// 🆕 多空持仓结构（5分钟缓存，失败不影响整体）
+	positioning, _ := GetPositioningData(symbol)
 
 	// 获取Funding Rate
-	fundingRate, _ := getFundingRate(symbol)
+	fundingRate, _ := src.FundingRate(symbol)
 
 	// 🔧 修复：日内系列和长期数据都使用已确认K线（避免前视偏差）
-	intradayData := calculateIntradaySeries(confirmedKlines)
-	longerTermData := calculateLongerTermData(confirmedKlines)
+	intradayData := indicators.intradaySeries()
+	intradayData.Interval = defaultInterval
+	longerTermData := indicators.longerTermData(confirmedKlines)
+	longerTermData.Interval = defaultInterval
 
 	// 🎯 计算支撑位/阻力位（用于限价单定价）
 	nearestSupport, nearestResistance, supportLevels, resistanceLevels := calculateSupportResistance(confirmedKlines, currentPrice)
 
 	result := &Data{
 		Symbol:            symbol,
-		CurrentPrice:      currentPrice, // 实时价格（前端显示用）
+		CurrentPrice:      currentPrice,   // 实时价格（前端显示用）
 		PriceChange15m:    priceChange15m, // 🆕
 		PriceChange30m:    priceChange30m, // 🆕
 		PriceChange1h:     priceChange1h,
@@ -286,14 +327,20 @@ func computeMarketData(symbol string) (*Data, error) {
 		PriceChange24h:    priceChange24h, // 🆕
 		CurrentEMA20:      currentEMA20,
 		CurrentMACD:       currentMACD,
-		MACDSignal:        macdSignal,       // 🆕
+		MACDSignal:        macdSignal, // 🆕
 		CurrentRSI7:       currentRSI7,
-		CurrentRSI14:      currentRSI14,     // 🆕
-		CurrentADX:        currentADX,       // 🆕 ADX趋势强度
-		CurrentPlusDI:     currentPlusDI,    // 🆕 +DI方向指标
-		CurrentMinusDI:    currentMinusDI,   // 🆕 -DI方向指标
-		Volume24h:         volume24h,        // 🆕
+		CurrentRSI14:      currentRSI14,   // 🆕
+		CurrentADX:        currentADX,     // 🆕 ADX趋势强度
+		CurrentPlusDI:     currentPlusDI,  // 🆕 +DI方向指标
+		CurrentMinusDI:    currentMinusDI, // 🆕 -DI方向指标
+		Volume24h:         volume24h,      // 🆕
 		OpenInterest:      oiData,
+		OIChange1h:        oiChanges.Change1h,  // 🆕
+		OIChange4h:        oiChanges.Change4h,  // 🆕
+		OIChange24h:       oiChanges.Change24h, // 🆕
+		OIPriceSignal:     oiPriceSignal,       // 🆕
+		OIDivergence:      isOIDivergence(oiPriceSignal),
+		Positioning:       positioning, // 🆕
 		FundingRate:       fundingRate,
 		IntradaySeries:    intradayData,
 		LongerTermContext: longerTermData,
@@ -304,9 +351,12 @@ func computeMarketData(symbol string) (*Data, error) {
 		SupportLevels:     supportLevels,
 		ResistanceLevels:  resistanceLevels,
 
-		Timestamp:         confirmedKlines[len(confirmedKlines)-1].CloseTime / 1000, // 使用最后一根已确认K线的时间
+		Timestamp: confirmedKlines[len(confirmedKlines)-1].CloseTime / 1000, // 使用最后一根已确认K线的时间
 	}
 
+	// 🕰️ 长期背景/入场时机使用独立周期时单独获取（失败时保留日内周期的数据）
+	attachContextIntervals(src, result, defaultInterval)
+
 	return result, nil
 }
 
@@ -362,282 +412,6 @@ func getKlines(symbol, interval string, limit int) ([]Kline, error) {
 	return klines, nil
 }
 
-// calculateEMA 计算EMA
-func calculateEMA(klines []Kline, period int) float64 {
-	if len(klines) < period {
-		return 0
-	}
-
-	// 计算SMA作为初始EMA
-	sum := 0.0
-	for i := 0; i < period; i++ {
-		sum += klines[i].Close
-	}
-	ema := sum / float64(period)
-
-	// 计算EMA
-	multiplier := 2.0 / float64(period+1)
-	for i := period; i < len(klines); i++ {
-		ema = (klines[i].Close-ema)*multiplier + ema
-	}
-
-	return ema
-}
-
-// calculateMACD 计算MACD
-func calculateMACD(klines []Kline) float64 {
-	if len(klines) < 26 {
-		return 0
-	}
-
-	// 计算12期和26期EMA
-	ema12 := calculateEMA(klines, 12)
-	ema26 := calculateEMA(klines, 26)
-
-	// MACD = EMA12 - EMA26
-	return ema12 - ema26
-}
-
-// calculateMACDSignal 计算MACD信号线（MACD的9期EMA）
-func calculateMACDSignal(klines []Kline) float64 {
-	if len(klines) < 35 { // 需要至少26个点计算MACD，再加9个点计算Signal
-		return 0
-	}
-
-	// 计算完整的MACD序列
-	macdSeries := calculateMACDSeries(klines)
-	if len(macdSeries) == 0 {
-		return 0
-	}
-
-	// 从MACD序列中提取有效值（非零值）
-	validMACD := []float64{}
-	for _, v := range macdSeries {
-		if v != 0 {
-			validMACD = append(validMACD, v)
-		}
-	}
-
-	if len(validMACD) < 9 {
-		return 0
-	}
-
-	// 计算MACD的9期EMA作为Signal线
-	sum := 0.0
-	for i := 0; i < 9; i++ {
-		sum += validMACD[i]
-	}
-	signal := sum / 9.0
-
-	multiplier := 2.0 / 10.0 // 9期EMA的multiplier = 2/(9+1)
-	for i := 9; i < len(validMACD); i++ {
-		signal = (validMACD[i]-signal)*multiplier + signal
-	}
-
-	return signal
-}
-
-// calculateRSI 计算RSI
-func calculateRSI(klines []Kline, period int) float64 {
-	if len(klines) <= period {
-		return 0
-	}
-
-	gains := 0.0
-	losses := 0.0
-
-	// 计算初始平均涨跌幅
-	for i := 1; i <= period; i++ {
-		change := klines[i].Close - klines[i-1].Close
-		if change > 0 {
-			gains += change
-		} else {
-			losses += -change
-		}
-	}
-
-	avgGain := gains / float64(period)
-	avgLoss := losses / float64(period)
-
-	// 使用Wilder平滑方法计算后续RSI
-	for i := period + 1; i < len(klines); i++ {
-		change := klines[i].Close - klines[i-1].Close
-		if change > 0 {
-			avgGain = (avgGain*float64(period-1) + change) / float64(period)
-			avgLoss = (avgLoss * float64(period-1)) / float64(period)
-		} else {
-			avgGain = (avgGain * float64(period-1)) / float64(period)
-			avgLoss = (avgLoss*float64(period-1) + (-change)) / float64(period)
-		}
-	}
-
-	if avgLoss == 0 {
-		return 100
-	}
-
-	rs := avgGain / avgLoss
-	rsi := 100 - (100 / (1 + rs))
-
-	return rsi
-}
-
-// calculateEMASeries 计算EMA序列（O(n)复杂度，返回完整序列）
-func calculateEMASeries(klines []Kline, period int) []float64 {
-	if len(klines) < period {
-		return []float64{}
-	}
-
-	result := make([]float64, len(klines))
-
-	// 计算SMA作为初始EMA
-	sum := 0.0
-	for i := 0; i < period; i++ {
-		sum += klines[i].Close
-	}
-	ema := sum / float64(period)
-	result[period-1] = ema
-
-	// 计算EMA序列
-	multiplier := 2.0 / float64(period+1)
-	for i := period; i < len(klines); i++ {
-		ema = (klines[i].Close-ema)*multiplier + ema
-		result[i] = ema
-	}
-
-	return result
-}
-
-// calculateMACDSeries 计算MACD序列（O(n)复杂度，返回完整序列）
-func calculateMACDSeries(klines []Kline) []float64 {
-	if len(klines) < 26 {
-		return []float64{}
-	}
-
-	// 计算EMA12序列
-	ema12Series := make([]float64, len(klines))
-	sum12 := 0.0
-	for i := 0; i < 12; i++ {
-		sum12 += klines[i].Close
-	}
-	ema12 := sum12 / 12.0
-	ema12Series[11] = ema12
-	multiplier12 := 2.0 / 13.0
-	for i := 12; i < len(klines); i++ {
-		ema12 = (klines[i].Close-ema12)*multiplier12 + ema12
-		ema12Series[i] = ema12
-	}
-
-	// 计算EMA26序列
-	ema26Series := make([]float64, len(klines))
-	sum26 := 0.0
-	for i := 0; i < 26; i++ {
-		sum26 += klines[i].Close
-	}
-	ema26 := sum26 / 26.0
-	ema26Series[25] = ema26
-	multiplier26 := 2.0 / 27.0
-	for i := 26; i < len(klines); i++ {
-		ema26 = (klines[i].Close-ema26)*multiplier26 + ema26
-		ema26Series[i] = ema26
-	}
-
-	// 计算MACD序列 = EMA12 - EMA26
-	result := make([]float64, len(klines))
-	for i := 25; i < len(klines); i++ {
-		result[i] = ema12Series[i] - ema26Series[i]
-	}
-
-	return result
-}
-
-// calculateRSISeries 计算RSI序列（O(n)复杂度，返回完整序列）
-func calculateRSISeries(klines []Kline, period int) []float64 {
-	if len(klines) <= period {
-		return []float64{}
-	}
-
-	result := make([]float64, len(klines))
-
-	gains := 0.0
-	losses := 0.0
-
-	// 计算初始平均涨跌幅
-	for i := 1; i <= period; i++ {
-		change := klines[i].Close - klines[i-1].Close
-		if change > 0 {
-			gains += change
-		} else {
-			losses += -change
-		}
-	}
-
-	avgGain := gains / float64(period)
-	avgLoss := losses / float64(period)
-
-	// 计算第一个RSI值
-	if avgLoss == 0 {
-		result[period] = 100
-	} else {
-		rs := avgGain / avgLoss
-		result[period] = 100 - (100 / (1 + rs))
-	}
-
-	// 使用Wilder平滑方法计算后续RSI序列
-	for i := period + 1; i < len(klines); i++ {
-		change := klines[i].Close - klines[i-1].Close
-		if change > 0 {
-			avgGain = (avgGain*float64(period-1) + change) / float64(period)
-			avgLoss = (avgLoss * float64(period-1)) / float64(period)
-		} else {
-			avgGain = (avgGain * float64(period-1)) / float64(period)
-			avgLoss = (avgLoss*float64(period-1) + (-change)) / float64(period)
-		}
-
-		if avgLoss == 0 {
-			result[i] = 100
-		} else {
-			rs := avgGain / avgLoss
-			result[i] = 100 - (100 / (1 + rs))
-		}
-	}
-
-	return result
-}
-
-// calculateATR 计算ATR
-func calculateATR(klines []Kline, period int) float64 {
-	if len(klines) <= period {
-		return 0
-	}
-
-	trs := make([]float64, len(klines))
-	for i := 1; i < len(klines); i++ {
-		high := klines[i].High
-		low := klines[i].Low
-		prevClose := klines[i-1].Close
-
-		tr1 := high - low
-		tr2 := math.Abs(high - prevClose)
-		tr3 := math.Abs(low - prevClose)
-
-		trs[i] = math.Max(tr1, math.Max(tr2, tr3))
-	}
-
-	// 计算初始ATR
-	sum := 0.0
-	for i := 1; i <= period; i++ {
-		sum += trs[i]
-	}
-	atr := sum / float64(period)
-
-	// Wilder平滑
-	for i := period + 1; i < len(klines); i++ {
-		atr = (atr*float64(period-1) + trs[i]) / float64(period)
-	}
-
-	return atr
-}
-
 // calculateADX 计算ADX (Average Directional Index) 趋势强度指标
 // 返回：adx, +DI, -DI
 func calculateADX(klines []Kline, period int) (adx, plusDI, minusDI float64) {
@@ -742,141 +516,6 @@ func wilderSmooth(values []float64, period int) float64 {
 	return smoothed
 }
 
-// calculateIntradaySeries 计算日内系列数据
-func calculateIntradaySeries(klines []Kline) *IntradayData {
-	data := &IntradayData{
-		MidPrices:   make([]float64, 0, 10),
-		EMA20Values: make([]float64, 0, 10),
-		MACDValues:  make([]float64, 0, 10),
-		RSI7Values:  make([]float64, 0, 10),
-		RSI14Values: make([]float64, 0, 10),
-	}
-
-	// ✅ 优化：预先计算完整序列的指标，然后只取最后10个点
-	// 避免在循环中重复计算（O(n²) → O(n)）
-	totalLen := len(klines)
-	if totalLen == 0 {
-		return data
-	}
-
-	// 预计算完整序列的指标（只计算一次）
-	var fullEMA20 []float64
-	var fullMACD []float64
-	var fullRSI7 []float64
-	var fullRSI14 []float64
-
-	// 计算EMA20序列（需要至少20个点）
-	if totalLen >= 20 {
-		fullEMA20 = calculateEMASeries(klines, 20)
-	}
-
-	// 计算MACD序列（需要至少26个点）
-	if totalLen >= 26 {
-		fullMACD = calculateMACDSeries(klines)
-	}
-
-	// 计算RSI序列
-	if totalLen >= 8 {
-		fullRSI7 = calculateRSISeries(klines, 7)
-	}
-	if totalLen >= 15 {
-		fullRSI14 = calculateRSISeries(klines, 14)
-	}
-
-	// 获取最近10个数据点
-	start := totalLen - 10
-	if start < 0 {
-		start = 0
-	}
-
-	for i := start; i < totalLen; i++ {
-		data.MidPrices = append(data.MidPrices, klines[i].Close)
-
-		// 从预计算的序列中取对应的值
-		if i < len(fullEMA20) {
-			data.EMA20Values = append(data.EMA20Values, fullEMA20[i])
-		}
-		if i < len(fullMACD) {
-			data.MACDValues = append(data.MACDValues, fullMACD[i])
-		}
-		if i < len(fullRSI7) {
-			data.RSI7Values = append(data.RSI7Values, fullRSI7[i])
-		}
-		if i < len(fullRSI14) {
-			data.RSI14Values = append(data.RSI14Values, fullRSI14[i])
-		}
-	}
-
-	return data
-}
-
-// calculateLongerTermData 计算长期数据
-func calculateLongerTermData(klines []Kline) *LongerTermData {
-	data := &LongerTermData{
-		MACDValues:  make([]float64, 0, 10),
-		RSI14Values: make([]float64, 0, 10),
-	}
-
-	// 计算EMA
-	data.EMA20 = calculateEMA(klines, 20)
-	data.EMA50 = calculateEMA(klines, 50)
-	data.EMA200 = calculateEMA(klines, 200) // ✅ 计算EMA200
-
-	// 计算ATR
-	data.ATR3 = calculateATR(klines, 3)
-	data.ATR14 = calculateATR(klines, 14)
-
-	// 计算成交量
-	if len(klines) > 0 {
-		data.CurrentVolume = klines[len(klines)-1].Volume
-		// 计算平均成交量
-		sum := 0.0
-		for _, k := range klines {
-			sum += k.Volume
-		}
-		data.AverageVolume = sum / float64(len(klines))
-	}
-
-	// ✅ 优化：预先计算完整序列的指标，然后只取最后10个点
-	// 避免在循环中重复计算（O(n²) → O(n)）
-	totalLen := len(klines)
-	if totalLen == 0 {
-		return data
-	}
-
-	// 预计算完整序列的指标（只计算一次）
-	var fullMACD []float64
-	var fullRSI14 []float64
-
-	// 计算MACD序列（需要至少26个点）
-	if totalLen >= 26 {
-		fullMACD = calculateMACDSeries(klines)
-	}
-
-	// 计算RSI14序列（需要至少15个点）
-	if totalLen >= 15 {
-		fullRSI14 = calculateRSISeries(klines, 14)
-	}
-
-	// 获取最近10个数据点
-	start := totalLen - 10
-	if start < 0 {
-		start = 0
-	}
-
-	for i := start; i < totalLen; i++ {
-		// 从预计算的序列中取对应的值
-		if i < len(fullMACD) && fullMACD[i] != 0 {
-			data.MACDValues = append(data.MACDValues, fullMACD[i])
-		}
-		if i < len(fullRSI14) && fullRSI14[i] != 0 {
-			data.RSI14Values = append(data.RSI14Values, fullRSI14[i])
-		}
-	}
-
-	return data
-}
-
 // getOpenInterestData 获取OI数据
 func getOpenInterestData(symbol string) (*OIData, error) {
 	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/openInterest?symbol=%s", symbol)
@@ -973,10 +612,27 @@ func Format(data *Data) string {
 			data.OpenInterest.Latest))
 	}
 
+	if data.OIChange1h != 0 || data.OIChange4h != 0 || data.OIChange24h != 0 {
+		sb.WriteString(fmt.Sprintf("Open Interest change: 1h %+.2f%% | 4h %+.2f%% | 24h %+.2f%%\n\n",
+			data.OIChange1h, data.OIChange4h, data.OIChange24h))
+	}
+	if data.OIPriceSignal != "" {
+		divergence := ""
+		if data.OIDivergence {
+			divergence = " (price/OI divergence)"
+		}
+		sb.WriteString(fmt.Sprintf("Price vs OI (4h): %s%s\n\n", data.OIPriceSignal, divergence))
+	}
+	if data.Positioning != nil {
+		if s := FormatPositioning(data.Positioning); s != "" {
+			sb.WriteString(fmt.Sprintf("Positioning (5m): %s\n\n", s))
+		}
+	}
+
 	sb.WriteString(fmt.Sprintf("Funding Rate: %.2e\n\n", data.FundingRate))
 
 	if data.IntradaySeries != nil {
-		sb.WriteString("Intraday series (3‑minute intervals, oldest → latest):\n\n")
+		sb.WriteString(fmt.Sprintf("Intraday series (%s intervals, oldest → latest):\n\n", data.IntradaySeries.Interval))
 
 		if len(data.IntradaySeries.MidPrices) > 0 {
 			sb.WriteString(fmt.Sprintf("Mid prices: %s\n\n", formatFloatSlice(data.IntradaySeries.MidPrices)))
@@ -1000,7 +656,7 @@ func Format(data *Data) string {
 	}
 
 	if data.LongerTermContext != nil {
-		sb.WriteString("Longer‑term context (4‑hour timeframe):\n\n")
+		sb.WriteString(fmt.Sprintf("Longer‑term context (%s timeframe):\n\n", data.LongerTermContext.Interval))
 
 		sb.WriteString(fmt.Sprintf("20‑Period EMA: %.3f vs. 50‑Period EMA: %.3f vs. 200‑Period EMA: %.3f\n\n",
 			data.LongerTermContext.EMA20, data.LongerTermContext.EMA50, data.LongerTermContext.EMA200)) // ✅ 添加EMA200输出
@@ -1032,11 +688,53 @@ func formatFloatSlice(values []float64) string {
 	return "[" + strings.Join(strValues, ", ") + "]"
 }
 
-// Normalize 标准化symbol,确保是USDT交易对
+// QuoteAssets 支持的U本位合约计价（保证金）币种
+var QuoteAssets = []string{"USDT", "USDC"}
+
+// IsQuoteAsset 是否为支持的计价币种（USDT/USDC，按1:1计入USD）
+func IsQuoteAsset(asset string) bool {
+	for _, q := range QuoteAssets {
+		if asset == q {
+			return true
+		}
+	}
+	return false
+}
+
+// HasQuoteAsset 是否为USDT/USDC计价的交易对
+func HasQuoteAsset(symbol string) bool {
+	return BaseAsset(symbol) != strings.ToUpper(symbol)
+}
+
+// QuoteAsset 交易对的计价币种（BTCUSDC -> USDC，无法识别时按USDT）
+func QuoteAsset(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	for _, q := range QuoteAssets {
+		if strings.HasSuffix(symbol, q) && len(symbol) > len(q) {
+			return q
+		}
+	}
+	return "USDT"
+}
+
+// BaseAsset 交易对的基础币种（BTCUSDT/BTCUSDC -> BTC）
+func BaseAsset(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	for _, q := range QuoteAssets {
+		if strings.HasSuffix(symbol, q) && len(symbol) > len(q) {
+			return strings.TrimSuffix(symbol, q)
+		}
+	}
+	return symbol
+}
+
+// Normalize 标准化symbol：已是USDT/USDC交易对时保持不变，否则补全为USDT交易对
 func Normalize(symbol string) string {
 	symbol = strings.ToUpper(symbol)
-	if strings.HasSuffix(symbol, "USDT") {
-		return symbol
+	for _, q := range QuoteAssets {
+		if strings.HasSuffix(symbol, q) && len(symbol) > len(q) {
+			return symbol
+		}
 	}
 	return symbol + "USDT"
 }
@@ -1304,4 +1002,3 @@ func extractSortedLevels(levels []PriceLevel, currentPrice float64, isSupport bo
 
 	return result
 }
-
@@ -0,0 +1,349 @@
+package market
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// 📚 研究数据集：按币种把历史资金费率、持仓量、现货/永续基差保存为CSV（回测和离线分析共用），支持增量更新
+// 目录结构：<dir>/<SYMBOL>/funding.csv、open_interest_<period>.csv、basis_<interval>.csv，每个文件按时间升序
+
+// FundingRecord 一次资金费结算
+type FundingRecord struct {
+	Time      int64   // 结算时间（毫秒）
+	Rate      float64 // 资金费率（小数，0.0001=0.01%）
+	MarkPrice float64 // 结算时标记价格（早期数据可能为0）
+}
+
+// OpenInterestRecord 持仓量采样
+type OpenInterestRecord struct {
+	Time              int64
+	OpenInterest      float64 // 币数量
+	OpenInterestValue float64 // USDT价值
+}
+
+// BasisRecord 同一根K线的现货与永续收盘价
+type BasisRecord struct {
+	Time      int64 // K线开盘时间（毫秒）
+	SpotClose float64
+	PerpClose float64
+	BasisPct  float64 // (永续-现货)/现货×100
+}
+
+const (
+	fundingPageLimit = 1000
+	oiPageLimit      = 500
+	klinePageLimit   = 1000
+	// OIHistoryMaxAge 币安openInterestHist只保留最近30天
+	OIHistoryMaxAge = 30 * 24 * time.Hour
+)
+
+var (
+	fundingHeader = []string{"time", "funding_rate", "mark_price"}
+	oiHeader      = []string{"time", "open_interest", "open_interest_value"}
+	basisHeader   = []string{"time", "spot_close", "perp_close", "basis_pct"}
+)
+
+// DatasetPath 数据集文件路径（name如 funding、open_interest_1h、basis_1h）
+func DatasetPath(dir, symbol, name string) string {
+	return filepath.Join(dir, symbol, name+".csv")
+}
+
+// getJSON GET请求并解析JSON（带币安请求频率限制）
+func getJSON(rawURL string, out interface{}) error {
+	resp, err := httpGetWithRateLimit(rawURL)
+	if err != nil {
+		return fmt.Errorf("HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("JSON解析失败: %w", err)
+	}
+	return nil
+}
+
+// FetchFundingHistory 分页获取startTime（毫秒）之后的资金费率历史
+func FetchFundingHistory(symbol string, startTime int64) ([]FundingRecord, error) {
+	var records []FundingRecord
+	for {
+		q := url.Values{}
+		q.Set("symbol", symbol)
+		q.Set("startTime", strconv.FormatInt(startTime, 10))
+		q.Set("limit", strconv.Itoa(fundingPageLimit))
+
+		var page []struct {
+			FundingTime int64  `json:"fundingTime"`
+			FundingRate string `json:"fundingRate"`
+			MarkPrice   string `json:"markPrice"`
+		}
+		if err := getJSON("https://fapi.binance.com/fapi/v1/fundingRate?"+q.Encode(), &page); err != nil {
+			return records, err
+		}
+		for _, p := range page {
+			rate, _ := strconv.ParseFloat(p.FundingRate, 64)
+			mark, _ := strconv.ParseFloat(p.MarkPrice, 64)
+			records = append(records, FundingRecord{Time: p.FundingTime, Rate: rate, MarkPrice: mark})
+		}
+		if len(page) < fundingPageLimit {
+			return records, nil
+		}
+		startTime = page[len(page)-1].FundingTime + 1
+	}
+}
+
+// FetchOpenInterestHistory 分页获取startTime之后的持仓量历史（period: 5m/15m/30m/1h/2h/4h/6h/12h/1d，最多30天）
+func FetchOpenInterestHistory(symbol, period string, startTime int64) ([]OpenInterestRecord, error) {
+	if oldest := time.Now().Add(-OIHistoryMaxAge).Add(time.Hour).UnixMilli(); startTime < oldest {
+		startTime = oldest // 更早的数据交易所不提供，请求会报错
+	}
+
+	var records []OpenInterestRecord
+	for {
+		q := url.Values{}
+		q.Set("symbol", symbol)
+		q.Set("period", period)
+		q.Set("startTime", strconv.FormatInt(startTime, 10))
+		q.Set("limit", strconv.Itoa(oiPageLimit))
+
+		var page []OIHistoryPoint
+		if err := getJSON("https://fapi.binance.com/futures/data/openInterestHist?"+q.Encode(), &page); err != nil {
+			return records, err
+		}
+		for _, p := range page {
+			records = append(records, OpenInterestRecord{Time: p.Timestamp, OpenInterest: p.OpenInterest, OpenInterestValue: p.OpenInterestValue})
+		}
+		if len(page) < oiPageLimit {
+			return records, nil
+		}
+		startTime = page[len(page)-1].Timestamp + 1
+	}
+}
+
+// fetchClosedKlines 分页获取startTime之后已收盘的K线收盘价（openTime -> close）
+func fetchClosedKlines(baseURL, symbol, interval string, startTime int64) (map[int64]float64, error) {
+	closes := make(map[int64]float64)
+	now := time.Now().UnixMilli()
+	for {
+		q := url.Values{}
+		q.Set("symbol", symbol)
+		q.Set("interval", interval)
+		q.Set("startTime", strconv.FormatInt(startTime, 10))
+		q.Set("limit", strconv.Itoa(klinePageLimit))
+
+		var page [][]interface{}
+		if err := getJSON(baseURL+"?"+q.Encode(), &page); err != nil {
+			return closes, err
+		}
+		for _, item := range page {
+			if len(item) < 7 {
+				continue
+			}
+			openTime := int64(item[0].(float64))
+			closeTime := int64(item[6].(float64))
+			if closeTime >= now {
+				continue // 未收盘的K线不入库，下次增量更新再取
+			}
+			closes[openTime], _ = parseFloat(item[4])
+		}
+		if len(page) < klinePageLimit {
+			return closes, nil
+		}
+		startTime = int64(page[len(page)-1][0].(float64)) + 1
+	}
+}
+
+// FetchBasisHistory 获取startTime之后现货与永续K线收盘价的基差（只保留两边都有的已收盘K线）
+func FetchBasisHistory(symbol, interval string, startTime int64) ([]BasisRecord, error) {
+	perp, err := fetchClosedKlines("https://fapi.binance.com/fapi/v1/klines", symbol, interval, startTime)
+	if err != nil {
+		return nil, fmt.Errorf("获取永续K线失败: %w", err)
+	}
+	spot, err := fetchClosedKlines("https://api.binance.com/api/v3/klines", symbol, interval, startTime)
+	if err != nil {
+		return nil, fmt.Errorf("获取现货K线失败: %w", err)
+	}
+
+	var records []BasisRecord
+	for t, perpClose := range perp {
+		spotClose, ok := spot[t]
+		if !ok || spotClose <= 0 {
+			continue
+		}
+		records = append(records, BasisRecord{
+			Time: t, SpotClose: spotClose, PerpClose: perpClose,
+			BasisPct: (perpClose - spotClose) / spotClose * 100,
+		})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Time < records[j].Time })
+	return records, nil
+}
+
+// LastDatasetTime 数据集文件最后一行的时间（毫秒，文件不存在或为空返回0）
+func LastDatasetTime(path string) (int64, error) {
+	rows, err := readDatasetRows(path)
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+	return strconv.ParseInt(rows[len(rows)-1][0], 10, 64)
+}
+
+// readDatasetRows 读取CSV数据行（不含表头，文件不存在返回nil）
+func readDatasetRows(path string) ([][]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("读取%s失败: %w", path, err)
+	}
+	if len(rows) > 0 {
+		rows = rows[1:]
+	}
+	return rows, nil
+}
+
+// appendDatasetRows 追加数据行（新文件先写表头），只追加时间晚于文件最后一行的记录
+func appendDatasetRows(path string, header []string, rows [][]string) (int, error) {
+	last, err := LastDatasetTime(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	_, statErr := os.Stat(path)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if os.IsNotExist(statErr) {
+		w.Write(header)
+	}
+	written := 0
+	for _, row := range rows {
+		if t, _ := strconv.ParseInt(row[0], 10, 64); t <= last {
+			continue
+		}
+		w.Write(row)
+		written++
+	}
+	w.Flush()
+	return written, w.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// AppendFunding 增量写入资金费率，返回新增行数
+func AppendFunding(path string, records []FundingRecord) (int, error) {
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, []string{strconv.FormatInt(r.Time, 10), formatFloat(r.Rate), formatFloat(r.MarkPrice)})
+	}
+	return appendDatasetRows(path, fundingHeader, rows)
+}
+
+// AppendOpenInterest 增量写入持仓量，返回新增行数
+func AppendOpenInterest(path string, records []OpenInterestRecord) (int, error) {
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, []string{strconv.FormatInt(r.Time, 10), formatFloat(r.OpenInterest), formatFloat(r.OpenInterestValue)})
+	}
+	return appendDatasetRows(path, oiHeader, rows)
+}
+
+// AppendBasis 增量写入基差，返回新增行数
+func AppendBasis(path string, records []BasisRecord) (int, error) {
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, []string{strconv.FormatInt(r.Time, 10), formatFloat(r.SpotClose), formatFloat(r.PerpClose), formatFloat(r.BasisPct)})
+	}
+	return appendDatasetRows(path, basisHeader, rows)
+}
+
+// parseDatasetRow 解析一行：时间 + n个数值
+func parseDatasetRow(row []string, n int) (int64, []float64, bool) {
+	if len(row) < n+1 {
+		return 0, nil, false
+	}
+	t, err := strconv.ParseInt(row[0], 10, 64)
+	if err != nil {
+		return 0, nil, false
+	}
+	values := make([]float64, n)
+	for i := 0; i < n; i++ {
+		values[i], _ = strconv.ParseFloat(row[i+1], 64)
+	}
+	return t, values, true
+}
+
+// LoadFunding 读取资金费率数据集（回测用）
+func LoadFunding(dir, symbol string) ([]FundingRecord, error) {
+	rows, err := readDatasetRows(DatasetPath(dir, symbol, "funding"))
+	if err != nil {
+		return nil, err
+	}
+	records := make([]FundingRecord, 0, len(rows))
+	for _, row := range rows {
+		if t, v, ok := parseDatasetRow(row, 2); ok {
+			records = append(records, FundingRecord{Time: t, Rate: v[0], MarkPrice: v[1]})
+		}
+	}
+	return records, nil
+}
+
+// LoadOpenInterest 读取持仓量数据集
+func LoadOpenInterest(dir, symbol, period string) ([]OpenInterestRecord, error) {
+	rows, err := readDatasetRows(DatasetPath(dir, symbol, "open_interest_"+period))
+	if err != nil {
+		return nil, err
+	}
+	records := make([]OpenInterestRecord, 0, len(rows))
+	for _, row := range rows {
+		if t, v, ok := parseDatasetRow(row, 2); ok {
+			records = append(records, OpenInterestRecord{Time: t, OpenInterest: v[0], OpenInterestValue: v[1]})
+		}
+	}
+	return records, nil
+}
+
+// LoadBasis 读取基差数据集
+func LoadBasis(dir, symbol, interval string) ([]BasisRecord, error) {
+	rows, err := readDatasetRows(DatasetPath(dir, symbol, "basis_"+interval))
+	if err != nil {
+		return nil, err
+	}
+	records := make([]BasisRecord, 0, len(rows))
+	for _, row := range rows {
+		if t, v, ok := parseDatasetRow(row, 3); ok {
+			records = append(records, BasisRecord{Time: t, SpotClose: v[0], PerpClose: v[1], BasisPct: v[2]})
+		}
+	}
+	return records, nil
+}
@@ -15,6 +15,7 @@ type ExtendedData struct {
 	Liquidation *LiquidationData
 	OnchainFlow *OnchainFlow
 	Sentiment   *SentimentData
+	Positioning *PositioningData // 🆕 多空账户比/大户持仓比/主动买卖比
 }
 
 // DerivativesData 衍生品数据
@@ -71,6 +72,7 @@ func GetExtendedData(symbol string) (*ExtendedData, error) {
 	liquidationChan := make(chan *LiquidationData, 1)
 	onchainChan := make(chan *OnchainFlow, 1)
 	sentimentChan := make(chan *SentimentData, 1)
+	positioningChan := make(chan *PositioningData, 1)
 
 	// 并发获取
 	go func() {
@@ -83,11 +85,11 @@ func GetExtendedData(symbol string) (*ExtendedData, error) {
 	}()
 
 	go func() {
-		// 使用订单簿深度 + 数学估算来预测清算密集区
-		if l, err := estimateLiquidationZones(symbol); err == nil {
+		// 🆕 优先使用强平订单流热力图，数据不足时回退到订单簿估算
+		if l, err := getLiquidationData(symbol); err == nil {
 			liquidationChan <- l
 		} else {
-			log.Printf("⚠️  估算清算区域失败: %v", err)
+			log.Printf("⚠️  获取清算区域失败: %v", err)
 			liquidationChan <- nil
 		}
 	}()
@@ -110,11 +112,21 @@ func GetExtendedData(symbol string) (*ExtendedData, error) {
 		}
 	}()
 
+	go func() {
+		if p, err := GetPositioningData(symbol); err == nil {
+			positioningChan <- p
+		} else {
+			log.Printf("⚠️  获取多空持仓数据失败: %v", err)
+			positioningChan <- nil
+		}
+	}()
+
 	// 收集结果
 	data.Derivatives = <-derivativesChan
 	data.Liquidation = <-liquidationChan
 	data.OnchainFlow = <-onchainChan
 	data.Sentiment = <-sentimentChan
+	data.Positioning = <-positioningChan
 
 	return data, nil
 }
@@ -122,7 +134,7 @@ func GetExtendedData(symbol string) (*ExtendedData, error) {
 // getDerivativesData 获取衍生品数据
 func getDerivativesData(symbol string) (*DerivativesData, error) {
 	data := &DerivativesData{
-		OptionMaxPain:    0,      // 待实现（需要Deribit API）
+		OptionMaxPain:    0, // 待实现（需要Deribit API）
 		OIChange4h:       0,
 		OIChange24h:      0,
 		FundingRateTrend: "stable",
@@ -136,32 +148,14 @@ func getDerivativesData(symbol string) (*DerivativesData, error) {
 		return data, nil // 返回默认值，不影响整体
 	}
 
-	// 获取OI历史数据（5分钟间隔，获取300个点 = 25小时历史）
-	oiHistory, err := getOIHistory(symbol, "5m", 300)
+	// 获取OI历史变化（与 market.Data 使用同一套计算）
+	oiChanges, err := getOIChanges(symbol, currentOI.Latest)
 	if err != nil {
 		log.Printf("⚠️  获取OI历史失败: %v", err)
 		return data, nil
 	}
-
-	if len(oiHistory) == 0 {
-		return data, nil
-	}
-
-	// 计算4小时变化率（48个5分钟周期）
-	if len(oiHistory) >= 49 {
-		oi4hAgo := oiHistory[len(oiHistory)-49].OpenInterest
-		if oi4hAgo > 0 {
-			data.OIChange4h = ((currentOI.Latest - oi4hAgo) / oi4hAgo) * 100
-		}
-	}
-
-	// 计算24小时变化率（288个5分钟周期）
-	if len(oiHistory) >= 289 {
-		oi24hAgo := oiHistory[len(oiHistory)-289].OpenInterest
-		if oi24hAgo > 0 {
-			data.OIChange24h = ((currentOI.Latest - oi24hAgo) / oi24hAgo) * 100
-		}
-	}
+	data.OIChange4h = oiChanges.Change4h
+	data.OIChange24h = oiChanges.Change24h
 
 	// 获取资金费率趋势
 	fundingTrend, currentFunding, err := getFundingRateTrend(symbol)
@@ -203,74 +197,6 @@ func getOnchainData(symbol string) (*OnchainFlow, error) {
 	}, nil
 }
 
-// getSentimentData 获取情绪数据
-func getSentimentData(symbol string) (*SentimentData, error) {
-	// 获取恐慌贪婪指数（Alternative.me API - 免费）
-	url := "https://api.alternative.me/fng/?limit=1"
-
-	resp, err := httpGetWithRateLimit(url)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP请求失败: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
-	}
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	// 解析JSON响应
-	var result struct {
-		Name string `json:"name"`
-		Data []struct {
-			Value               string `json:"value"`
-			ValueClassification string `json:"value_classification"`
-			Timestamp           string `json:"timestamp"`
-			TimeUntilUpdate     string `json:"time_until_update"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("JSON解析失败: %w", err)
-	}
-
-	if len(result.Data) == 0 {
-		return nil, fmt.Errorf("无数据")
-	}
-
-	// 解析恐慌贪婪指数值
-	fngValue := 50 // 默认中性
-	if value, err := strconv.Atoi(result.Data[0].Value); err == nil {
-		fngValue = value
-	}
-
-	// 根据分类判断社交情绪
-	classification := result.Data[0].ValueClassification
-	socialSentiment := "neutral"
-	switch classification {
-	case "Extreme Fear":
-		socialSentiment = "bearish"
-	case "Fear":
-		socialSentiment = "bearish"
-	case "Greed":
-		socialSentiment = "bullish"
-	case "Extreme Greed":
-		socialSentiment = "bullish"
-	}
-
-	return &SentimentData{
-		FearGreedIndex:  fngValue,
-		SocialVolume:    0, // 暂无数据源
-		SocialSentiment: socialSentiment,
-		NewsImpact:      "neutral", // 暂无数据源
-	}, nil
-}
-
 // FormatExtended 格式化扩展数据为可读字符串
 func FormatExtended(data *ExtendedData) string {
 	if data == nil {
@@ -321,6 +247,9 @@ func FormatExtended(data *ExtendedData) string {
 		if l.LiqTrend != "balanced" {
 			parts = append(parts, "trend="+l.LiqTrend)
 		}
+		if l.RecentLiqVol > 0 {
+			parts = append(parts, fmt.Sprintf("vol1h≈%.1fM", l.RecentLiqVol/1e6))
+		}
 		if len(parts) > 0 {
 			sections = append(sections, "liq["+joinParts(parts)+"]")
 		}
@@ -362,6 +291,13 @@ func FormatExtended(data *ExtendedData) string {
 		}
 	}
 
+	// 多空持仓结构
+	if data.Positioning != nil {
+		if s := FormatPositioning(data.Positioning); s != "" {
+			sections = append(sections, "pos["+s+"]")
+		}
+	}
+
 	return joinParts(sections)
 }
 
@@ -382,8 +318,8 @@ func joinParts(parts []string) string {
 
 // OIHistoryPoint OI历史数据点
 type OIHistoryPoint struct {
-	Timestamp     int64   `json:"timestamp"`
-	OpenInterest  float64 `json:"sumOpenInterest,string"`
+	Timestamp         int64   `json:"timestamp"`
+	OpenInterest      float64 `json:"sumOpenInterest,string"`
 	OpenInterestValue float64 `json:"sumOpenInterestValue,string"`
 }
 
@@ -418,9 +354,9 @@ func getOIHistory(symbol, interval string, limit int) ([]OIHistoryPoint, error)
 
 // FundingRatePoint 资金费率历史数据点
 type FundingRatePoint struct {
-	Symbol       string `json:"symbol"`
-	FundingRate  string `json:"fundingRate"`
-	FundingTime  int64  `json:"fundingTime"`
+	Symbol      string `json:"symbol"`
+	FundingRate string `json:"fundingRate"`
+	FundingTime int64  `json:"fundingTime"`
 }
 
 // getFundingRateTrend 获取资金费率趋势
@@ -465,7 +401,7 @@ func getFundingRateTrend(symbol string) (trend string, current float64, err erro
 	recent /= 3
 
 	if len(rates) >= 6 {
-		for i := len(rates) - 6; i < len(rates) - 3; i++ {
+		for i := len(rates) - 6; i < len(rates)-3; i++ {
 			rate, _ := strconv.ParseFloat(rates[i].FundingRate, 64)
 			previous += rate
 		}
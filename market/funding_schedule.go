@@ -0,0 +1,53 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// FundingSchedule 当前资金费率与下次结算时间
+type FundingSchedule struct {
+	Rate        float64
+	NextFunding time.Time
+}
+
+// GetFundingSchedule 获取资金费率和下次结算时间（premiumIndex）
+func GetFundingSchedule(symbol string) (*FundingSchedule, error) {
+	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/premiumIndex?symbol=%s", symbol)
+	resp, err := httpGetWithRateLimit(url)
+	if err != nil {
+		return nil, fmt.Errorf("获取资金费率失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("资金费率JSON解析失败: %w", err)
+	}
+	if result.NextFundingTime == 0 {
+		return nil, fmt.Errorf("%s 没有资金费结算时间", symbol)
+	}
+
+	rate, _ := strconv.ParseFloat(result.LastFundingRate, 64)
+	// 优先使用标记价格推送的最新资金费率
+	if stream := currentKlineStream(); stream != nil {
+		if r, ok := stream.FundingRate(symbol); ok {
+			rate = r
+		}
+	}
+	return &FundingSchedule{Rate: rate, NextFunding: time.UnixMilli(result.NextFundingTime)}, nil
+}
@@ -0,0 +1,481 @@
+package market
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// HyperliquidSource 🟢 Hyperliquid行情数据源
+// K线和资产上下文（资金费率、持仓量、标记价格）由WebSocket推送维护，冷启动和推送不可用时走 /info REST
+type HyperliquidSource struct {
+	wsURL   string
+	infoURL string
+
+	mu         sync.RWMutex
+	candles    map[string][]Kline // coin|interval -> 滚动K线（最后一根为未收盘K线）
+	limits     map[string]int
+	updatedAt  map[string]time.Time
+	ctxs       map[string]hlAssetCtx // coin -> 资产上下文
+	ctxFetched time.Time             // 上次通过REST刷新资产上下文的时间
+	subscribed map[string]map[string]string
+	conn       *websocket.Conn
+	isRunning  bool
+
+	writeMu sync.Mutex // gorilla/websocket 不允许并发写
+}
+
+// hlAssetCtx 资产上下文（资金费率为Hyperliquid原始的1小时费率）
+type hlAssetCtx struct {
+	funding      float64
+	openInterest float64
+	markPrice    float64
+	updatedAt    time.Time
+}
+
+type hlRawCtx struct {
+	Funding      string `json:"funding"`
+	OpenInterest string `json:"openInterest"`
+	MarkPx       string `json:"markPx"`
+}
+
+func (c hlRawCtx) parse() hlAssetCtx {
+	funding, _ := strconv.ParseFloat(c.Funding, 64)
+	oi, _ := strconv.ParseFloat(c.OpenInterest, 64)
+	mark, _ := strconv.ParseFloat(c.MarkPx, 64)
+	return hlAssetCtx{funding: funding, openInterest: oi, markPrice: mark, updatedAt: time.Now()}
+}
+
+type hlCandle struct {
+	OpenTime  int64  `json:"t"`
+	CloseTime int64  `json:"T"` // 必须显式声明：encoding/json大小写不敏感，否则"T"会覆盖"t"
+	Coin      string `json:"s"`
+	Interval  string `json:"i"`
+	Open      string `json:"o"`
+	Close     string `json:"c"`
+	High      string `json:"h"`
+	Low       string `json:"l"`
+	Volume    string `json:"v"`
+}
+
+// kline 转换为币安格式K线（CloseTime按币安口径为下一根开盘时间-1ms）
+func (c hlCandle) kline() Kline {
+	open, _ := strconv.ParseFloat(c.Open, 64)
+	high, _ := strconv.ParseFloat(c.High, 64)
+	low, _ := strconv.ParseFloat(c.Low, 64)
+	closePrice, _ := strconv.ParseFloat(c.Close, 64)
+	volume, _ := strconv.ParseFloat(c.Volume, 64)
+	intervalMs := int64(getIntervalMinutes(c.Interval)) * 60 * 1000
+	return Kline{
+		OpenTime:  c.OpenTime,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     closePrice,
+		Volume:    volume,
+		CloseTime: c.OpenTime + intervalMs - 1,
+	}
+}
+
+// NewHyperliquidSource 创建Hyperliquid行情数据源
+func NewHyperliquidSource() *HyperliquidSource {
+	return &HyperliquidSource{
+		wsURL:      "wss://api.hyperliquid.xyz/ws",
+		infoURL:    "https://api.hyperliquid.xyz/info",
+		candles:    make(map[string][]Kline),
+		limits:     make(map[string]int),
+		updatedAt:  make(map[string]time.Time),
+		ctxs:       make(map[string]hlAssetCtx),
+		subscribed: make(map[string]map[string]string),
+	}
+}
+
+// Name 数据源名称
+func (s *HyperliquidSource) Name() string { return SourceHyperliquid }
+
+// hyperliquidCoin BTCUSDT/BTCUSDC -> BTC
+func hyperliquidCoin(symbol string) string {
+	return BaseAsset(Normalize(symbol))
+}
+
+// Start 启动推送订阅（自动重连，重连后重新订阅）
+func (s *HyperliquidSource) Start() {
+	s.mu.Lock()
+	s.isRunning = true
+	s.mu.Unlock()
+
+	go s.connectLoop()
+	log.Println("🔌 Hyperliquid行情推送已启动")
+}
+
+// Stop 停止订阅
+func (s *HyperliquidSource) Stop() {
+	s.mu.Lock()
+	s.isRunning = false
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+	log.Println("🔌 Hyperliquid行情推送已停止")
+}
+
+func (s *HyperliquidSource) running() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isRunning
+}
+
+// connectLoop 连接循环（断开后5秒重连）
+func (s *HyperliquidSource) connectLoop() {
+	for s.running() {
+		dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
+		conn, _, err := dialer.Dial(s.wsURL, nil)
+		if err != nil {
+			log.Printf("❌ Hyperliquid行情推送连接失败: %v，5秒后重试...", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		s.mu.Lock()
+		s.conn = conn
+		subs := make([]map[string]string, 0, len(s.subscribed))
+		for _, sub := range s.subscribed {
+			subs = append(subs, sub)
+		}
+		// 断线期间可能漏掉K线，全部重新冷启动
+		s.candles = make(map[string][]Kline)
+		s.mu.Unlock()
+
+		for _, sub := range subs {
+			s.sendSubscribe(sub)
+		}
+
+		done := make(chan struct{})
+		go s.pingLoop(conn, done)
+		s.receiveMessages(conn)
+		close(done)
+
+		if s.running() {
+			log.Println("⚠️ Hyperliquid行情推送连接断开，5秒后重连...")
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+// pingLoop 服务端60秒无消息会断开连接，每30秒发送一次ping
+func (s *HyperliquidSource) pingLoop(conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.writeMu.Lock()
+			err := conn.WriteJSON(map[string]string{"method": "ping"})
+			s.writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// receiveMessages 接收K线和资产上下文推送
+func (s *HyperliquidSource) receiveMessages(conn *websocket.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		s.conn = nil
+		s.mu.Unlock()
+	}()
+
+	for s.running() {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if s.running() {
+				log.Printf("⚠️ Hyperliquid行情推送读取错误: %v", err)
+			}
+			return
+		}
+
+		var msg struct {
+			Channel string          `json:"channel"`
+			Data    json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(message, &msg); err != nil {
+			continue
+		}
+
+		switch msg.Channel {
+		case "candle":
+			var candle hlCandle
+			if err := json.Unmarshal(msg.Data, &candle); err == nil {
+				s.applyCandle(candle)
+			}
+		case "activeAssetCtx":
+			var data struct {
+				Coin string   `json:"coin"`
+				Ctx  hlRawCtx `json:"ctx"`
+			}
+			if err := json.Unmarshal(msg.Data, &data); err == nil && data.Coin != "" {
+				s.mu.Lock()
+				s.ctxs[data.Coin] = data.Ctx.parse()
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// subscribe 记录订阅并在已连接时立即发送（未连接时由connectLoop统一订阅）
+func (s *HyperliquidSource) subscribe(sub map[string]string) {
+	key := sub["type"] + "|" + sub["coin"] + "|" + sub["interval"]
+	s.mu.Lock()
+	_, exists := s.subscribed[key]
+	s.subscribed[key] = sub
+	s.mu.Unlock()
+
+	if !exists {
+		s.sendSubscribe(sub)
+	}
+}
+
+func (s *HyperliquidSource) sendSubscribe(sub map[string]string) {
+	s.mu.RLock()
+	conn := s.conn
+	s.mu.RUnlock()
+	if conn == nil {
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	req := map[string]interface{}{"method": "subscribe", "subscription": sub}
+	if err := conn.WriteJSON(req); err != nil {
+		log.Printf("⚠️ Hyperliquid行情订阅失败 %v: %v", sub, err)
+	}
+}
+
+// applyCandle 用推送更新滚动K线：同一根K线覆盖，新K线追加，出现缺口则丢弃等待REST重新冷启动
+func (s *HyperliquidSource) applyCandle(c hlCandle) {
+	k := c.kline()
+	key := c.Coin + "|" + c.Interval
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candles, ok := s.candles[key]
+	if !ok || len(candles) == 0 {
+		return // 尚未冷启动
+	}
+
+	last := candles[len(candles)-1]
+	intervalMs := int64(getIntervalMinutes(c.Interval)) * 60 * 1000
+	switch {
+	case k.OpenTime == last.OpenTime:
+		candles[len(candles)-1] = k
+	case k.OpenTime == last.OpenTime+intervalMs:
+		candles = append(candles, k)
+		if limit := s.limits[key]; limit > 0 && len(candles) > limit {
+			candles = candles[len(candles)-limit:]
+		}
+	case k.OpenTime > last.OpenTime:
+		log.Printf("⚠️ Hyperliquid %s K线推送出现缺口，重新通过REST冷启动", c.Coin)
+		delete(s.candles, key)
+		return
+	default:
+		return // 过期推送
+	}
+	s.candles[key] = candles
+	s.updatedAt[key] = time.Now()
+}
+
+// Klines 获取K线：优先使用推送维护的K线，未冷启动或推送超过2个周期没有更新时走REST并订阅
+func (s *HyperliquidSource) Klines(symbol, interval string, limit int) ([]Kline, error) {
+	coin := hyperliquidCoin(symbol)
+	key := coin + "|" + interval
+
+	s.mu.RLock()
+	candles, ok := s.candles[key]
+	fresh := s.conn != nil && ok && len(candles) >= limit/2 &&
+		time.Since(s.updatedAt[key]) <= 2*time.Duration(getIntervalMinutes(interval))*time.Minute
+	if fresh {
+		result := make([]Kline, len(candles))
+		copy(result, candles)
+		s.mu.RUnlock()
+		return result, nil
+	}
+	s.mu.RUnlock()
+
+	klines, err := s.fetchCandles(coin, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	seeded := make([]Kline, len(klines))
+	copy(seeded, klines)
+	s.candles[key] = seeded
+	s.limits[key] = limit
+	s.updatedAt[key] = time.Now()
+	s.mu.Unlock()
+
+	s.subscribe(map[string]string{"type": "candle", "coin": coin, "interval": interval})
+	s.subscribe(map[string]string{"type": "activeAssetCtx", "coin": coin})
+	return klines, nil
+}
+
+// fetchCandles 通过REST获取最近limit根K线
+func (s *HyperliquidSource) fetchCandles(coin, interval string, limit int) ([]Kline, error) {
+	intervalMs := int64(getIntervalMinutes(interval)) * 60 * 1000
+	end := time.Now().UnixMilli()
+	req := map[string]interface{}{
+		"type": "candleSnapshot",
+		"req": map[string]interface{}{
+			"coin":      coin,
+			"interval":  interval,
+			"startTime": end - int64(limit+1)*intervalMs,
+			"endTime":   end,
+		},
+	}
+
+	var raw []hlCandle
+	if err := s.info(req, &raw); err != nil {
+		return nil, fmt.Errorf("获取Hyperliquid K线失败: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("Hyperliquid没有%s的K线数据", coin)
+	}
+
+	klines := make([]Kline, 0, len(raw))
+	for _, c := range raw {
+		if c.Interval == "" {
+			c.Interval = interval
+		}
+		klines = append(klines, c.kline())
+	}
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	return klines, nil
+}
+
+// assetCtx 获取资产上下文：推送超过1分钟没有更新时通过REST刷新全部资产（30秒内最多刷新一次）
+func (s *HyperliquidSource) assetCtx(symbol string) (hlAssetCtx, error) {
+	coin := hyperliquidCoin(symbol)
+
+	s.mu.RLock()
+	ctx, ok := s.ctxs[coin]
+	recentlyFetched := time.Since(s.ctxFetched) < 30*time.Second
+	s.mu.RUnlock()
+	if ok && time.Since(ctx.updatedAt) <= time.Minute {
+		return ctx, nil
+	}
+
+	if !recentlyFetched {
+		if err := s.refreshAssetCtxs(); err != nil {
+			return hlAssetCtx{}, err
+		}
+		s.subscribe(map[string]string{"type": "activeAssetCtx", "coin": coin})
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ctx, ok = s.ctxs[coin]
+	if !ok {
+		return hlAssetCtx{}, fmt.Errorf("Hyperliquid没有%s的资产数据", coin)
+	}
+	return ctx, nil
+}
+
+// refreshAssetCtxs 通过 metaAndAssetCtxs 刷新所有资产的资金费率和持仓量
+func (s *HyperliquidSource) refreshAssetCtxs() error {
+	var raw []json.RawMessage
+	if err := s.info(map[string]string{"type": "metaAndAssetCtxs"}, &raw); err != nil {
+		return fmt.Errorf("获取Hyperliquid资产数据失败: %w", err)
+	}
+	if len(raw) < 2 {
+		return fmt.Errorf("Hyperliquid资产数据格式异常")
+	}
+
+	var meta struct {
+		Universe []struct {
+			Name string `json:"name"`
+		} `json:"universe"`
+	}
+	var ctxs []hlRawCtx
+	if err := json.Unmarshal(raw[0], &meta); err != nil {
+		return fmt.Errorf("解析Hyperliquid元数据失败: %w", err)
+	}
+	if err := json.Unmarshal(raw[1], &ctxs); err != nil {
+		return fmt.Errorf("解析Hyperliquid资产数据失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, asset := range meta.Universe {
+		if i < len(ctxs) {
+			s.ctxs[asset.Name] = ctxs[i].parse()
+		}
+	}
+	s.ctxFetched = time.Now()
+	return nil
+}
+
+// FundingRate 资金费率：Hyperliquid每小时结算，乘以8折算为与币安一致的8小时费率
+func (s *HyperliquidSource) FundingRate(symbol string) (float64, error) {
+	ctx, err := s.assetCtx(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return ctx.funding * 8, nil
+}
+
+// OpenInterest 持仓量（币数量）
+func (s *HyperliquidSource) OpenInterest(symbol string) (float64, error) {
+	ctx, err := s.assetCtx(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return ctx.openInterest, nil
+}
+
+// MarkPrice 标记价格
+func (s *HyperliquidSource) MarkPrice(symbol string) (float64, error) {
+	ctx, err := s.assetCtx(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return ctx.markPrice, nil
+}
+
+// info 调用 /info 接口
+func (s *HyperliquidSource) info(req interface{}, out interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Post(s.infoURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(data))
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,305 @@
+package market
+
+import (
+	"math"
+	"sync"
+)
+
+// 🧮 增量指标引擎：每个币种保存 EMA/RSI/ATR/MACD 的流式状态，新K线收盘时只推进一步，
+// 不再每个周期对300根K线从头重算。K线不连续（重启、周期切换、推送缺口）时从头重建。
+
+// seriesLen 日内/长期序列保留的点数
+const seriesLen = 10
+
+// emaState 流式EMA（前period个值的SMA作为初始值）
+type emaState struct {
+	period int
+	n      int
+	sum    float64
+	value  float64
+}
+
+func (s *emaState) update(v float64) {
+	s.n++
+	if s.n < s.period {
+		s.sum += v
+		return
+	}
+	if s.n == s.period {
+		s.value = (s.sum + v) / float64(s.period)
+		return
+	}
+	multiplier := 2.0 / float64(s.period+1)
+	s.value = (v-s.value)*multiplier + s.value
+}
+
+func (s *emaState) ready() bool { return s.n >= s.period }
+
+// wilderState 流式Wilder平滑（前period个值的SMA作为初始值）
+type wilderState struct {
+	period int
+	n      int
+	sum    float64
+	value  float64
+}
+
+func (s *wilderState) update(v float64) {
+	s.n++
+	if s.n < s.period {
+		s.sum += v
+		return
+	}
+	if s.n == s.period {
+		s.value = (s.sum + v) / float64(s.period)
+		return
+	}
+	s.value = (s.value*float64(s.period-1) + v) / float64(s.period)
+}
+
+func (s *wilderState) ready() bool { return s.n >= s.period }
+
+// rsiState 流式RSI（Wilder平滑的平均涨幅/跌幅）
+type rsiState struct {
+	prevClose float64
+	hasPrev   bool
+	gain      wilderState
+	loss      wilderState
+}
+
+func newRSIState(period int) rsiState {
+	return rsiState{gain: wilderState{period: period}, loss: wilderState{period: period}}
+}
+
+func (s *rsiState) update(closePrice float64) {
+	if s.hasPrev {
+		change := closePrice - s.prevClose
+		s.gain.update(math.Max(change, 0))
+		s.loss.update(math.Max(-change, 0))
+	}
+	s.prevClose = closePrice
+	s.hasPrev = true
+}
+
+func (s *rsiState) value() float64 {
+	if !s.gain.ready() {
+		return 0
+	}
+	if s.loss.value == 0 {
+		return 100
+	}
+	rs := s.gain.value / s.loss.value
+	return 100 - (100 / (1 + rs))
+}
+
+// atrState 流式ATR（真实波幅的Wilder平滑）
+type atrState struct {
+	prevClose float64
+	hasPrev   bool
+	tr        wilderState
+}
+
+func (s *atrState) update(k Kline) {
+	if s.hasPrev {
+		tr := math.Max(k.High-k.Low, math.Max(math.Abs(k.High-s.prevClose), math.Abs(k.Low-s.prevClose)))
+		s.tr.update(tr)
+	}
+	s.prevClose = k.Close
+	s.hasPrev = true
+}
+
+func (s *atrState) value() float64 {
+	if !s.tr.ready() {
+		return 0
+	}
+	return s.tr.value
+}
+
+// macdState 流式MACD（EMA12-EMA26，信号线为MACD的9期EMA）
+type macdState struct {
+	ema12  emaState
+	ema26  emaState
+	signal emaState
+}
+
+func newMACDState() macdState {
+	return macdState{ema12: emaState{period: 12}, ema26: emaState{period: 26}, signal: emaState{period: 9}}
+}
+
+func (s *macdState) update(closePrice float64) {
+	s.ema12.update(closePrice)
+	s.ema26.update(closePrice)
+	if s.ema26.ready() {
+		s.signal.update(s.value())
+	}
+}
+
+func (s *macdState) value() float64 {
+	if !s.ema26.ready() {
+		return 0
+	}
+	return s.ema12.value - s.ema26.value
+}
+
+func (s *macdState) signalValue() float64 {
+	if !s.signal.ready() {
+		return 0
+	}
+	return s.signal.value
+}
+
+// ring 定长滑动序列（保留最近seriesLen个值）
+type ring []float64
+
+func (r ring) push(v float64) ring {
+	r = append(r, v)
+	if len(r) > seriesLen {
+		r = r[len(r)-seriesLen:]
+	}
+	return r
+}
+
+// indicatorEngine 单个币种单个周期的指标状态
+type indicatorEngine struct {
+	interval     string
+	lastOpenTime int64 // 最后一根已处理的收盘K线
+	count        int
+
+	ema20, ema50, ema200 emaState
+	macd                 macdState
+	rsi7, rsi14          rsiState
+	atr3, atr14          atrState
+
+	// 最近10根K线的指标序列（日内序列保留未就绪的0值，长期序列跳过0值，与原有输出一致）
+	closes      ring
+	ema20Series ring
+	macdSeries  ring
+	rsi7Series  ring
+	rsi14Series ring
+}
+
+func newIndicatorEngine(interval string) *indicatorEngine {
+	return &indicatorEngine{
+		interval: interval,
+		ema20:    emaState{period: 20},
+		ema50:    emaState{period: 50},
+		ema200:   emaState{period: 200},
+		macd:     newMACDState(),
+		rsi7:     newRSIState(7),
+		rsi14:    newRSIState(14),
+		atr3:     atrState{tr: wilderState{period: 3}},
+		atr14:    atrState{tr: wilderState{period: 14}},
+	}
+}
+
+// update 推进一根收盘K线
+func (e *indicatorEngine) update(k Kline) {
+	e.ema20.update(k.Close)
+	e.ema50.update(k.Close)
+	e.ema200.update(k.Close)
+	e.macd.update(k.Close)
+	e.rsi7.update(k.Close)
+	e.rsi14.update(k.Close)
+	e.atr3.update(k)
+	e.atr14.update(k)
+
+	e.closes = e.closes.push(k.Close)
+	ema20 := 0.0
+	if e.ema20.ready() {
+		ema20 = e.ema20.value
+	}
+	e.ema20Series = e.ema20Series.push(ema20)
+	e.macdSeries = e.macdSeries.push(e.macd.value())
+	e.rsi7Series = e.rsi7Series.push(e.rsi7.value())
+	e.rsi14Series = e.rsi14Series.push(e.rsi14.value())
+
+	e.lastOpenTime = k.OpenTime
+	e.count++
+}
+
+func emaValue(s emaState) float64 {
+	if !s.ready() {
+		return 0
+	}
+	return s.value
+}
+
+// intradaySeries 日内系列数据（最近10根收盘K线）
+func (e *indicatorEngine) intradaySeries() *IntradayData {
+	return &IntradayData{
+		MidPrices:   append([]float64(nil), e.closes...),
+		EMA20Values: append([]float64(nil), e.ema20Series...),
+		MACDValues:  append([]float64(nil), e.macdSeries...),
+		RSI7Values:  append([]float64(nil), e.rsi7Series...),
+		RSI14Values: append([]float64(nil), e.rsi14Series...),
+	}
+}
+
+// longerTermData 长期数据（成交量统计基于当前K线窗口）
+func (e *indicatorEngine) longerTermData(klines []Kline) *LongerTermData {
+	data := &LongerTermData{
+		EMA20:       emaValue(e.ema20),
+		EMA50:       emaValue(e.ema50),
+		EMA200:      emaValue(e.ema200),
+		ATR3:        e.atr3.value(),
+		ATR14:       e.atr14.value(),
+		MACDValues:  make([]float64, 0, seriesLen),
+		RSI14Values: make([]float64, 0, seriesLen),
+	}
+
+	if len(klines) > 0 {
+		data.CurrentVolume = klines[len(klines)-1].Volume
+		sum := 0.0
+		for _, k := range klines {
+			sum += k.Volume
+		}
+		data.AverageVolume = sum / float64(len(klines))
+	}
+
+	for _, v := range e.macdSeries {
+		if v != 0 {
+			data.MACDValues = append(data.MACDValues, v)
+		}
+	}
+	for _, v := range e.rsi14Series {
+		if v != 0 {
+			data.RSI14Values = append(data.RSI14Values, v)
+		}
+	}
+	return data
+}
+
+var (
+	indicatorEnginesMu sync.Mutex
+	indicatorEngines   = make(map[string]*indicatorEngine)
+)
+
+// advanceIndicators 用已收盘K线推进币种的指标引擎，返回引擎副本
+// 按币种+周期分别保存（日内/长期背景/入场时机可使用不同周期）；只处理上次之后的新K线，K线不连续时从头重建
+func advanceIndicators(symbol, interval string, confirmed []Kline) *indicatorEngine {
+	indicatorEnginesMu.Lock()
+	defer indicatorEnginesMu.Unlock()
+
+	key := symbol + "|" + interval
+	engine, ok := indicatorEngines[key]
+	start := -1
+	if ok && engine.interval == interval {
+		for i := len(confirmed) - 1; i >= 0; i-- {
+			if confirmed[i].OpenTime == engine.lastOpenTime {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	if start < 0 {
+		engine = newIndicatorEngine(interval)
+		start = 0
+	}
+	for _, k := range confirmed[start:] {
+		engine.update(k)
+	}
+	indicatorEngines[key] = engine
+
+	snapshot := *engine
+	return &snapshot
+}
@@ -0,0 +1,406 @@
+package market
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// klineStreamMessage 币安合约K线推送（<symbol>@kline_<interval>）
+type klineStreamMessage struct {
+	Event  string `json:"e"`
+	Symbol string `json:"s"`
+	Kline  struct {
+		OpenTime  int64  `json:"t"`
+		CloseTime int64  `json:"T"`
+		Interval  string `json:"i"`
+		Open      string `json:"o"`
+		Close     string `json:"c"`
+		High      string `json:"h"`
+		Low       string `json:"l"`
+		Volume    string `json:"v"`
+	} `json:"k"`
+}
+
+// markPriceStreamMessage 币安标记价格推送（<symbol>@markPrice@1s），附带最新资金费率
+type markPriceStreamMessage struct {
+	Event       string `json:"e"`
+	Symbol      string `json:"s"`
+	MarkPrice   string `json:"p"`
+	FundingRate string `json:"r"`
+}
+
+// streamMarkPrice 标记价格与资金费率（推送时间用于判断是否过期）
+type streamMarkPrice struct {
+	price       float64
+	fundingRate float64
+	updatedAt   time.Time
+}
+
+// KlineStream 基于币安K线/标记价格推送的滚动K线存储
+// 每个币种首次请求时用REST冷启动（getKlines），之后由推送增量更新，指标在本地计算
+type KlineStream struct {
+	wsURL string
+
+	mu         sync.RWMutex
+	interval   string
+	limit      int
+	candles    map[string][]Kline // symbol -> 滚动K线（最后一根为未收盘K线）
+	updatedAt  map[string]time.Time
+	marks      map[string]streamMarkPrice
+	subscribed map[string]bool
+	conn       *websocket.Conn
+	isRunning  bool
+
+	writeMu sync.Mutex // gorilla/websocket 不允许并发写
+	nextID  int
+}
+
+var (
+	klineStreamMu sync.RWMutex
+	klineStream   *KlineStream
+)
+
+// NewKlineStream 创建K线推送订阅器
+func NewKlineStream() *KlineStream {
+	return &KlineStream{
+		wsURL:      "wss://fstream.binance.com/ws",
+		interval:   defaultInterval,
+		limit:      defaultLimit,
+		candles:    make(map[string][]Kline),
+		updatedAt:  make(map[string]time.Time),
+		marks:      make(map[string]streamMarkPrice),
+		subscribed: make(map[string]bool),
+	}
+}
+
+// SetKlineStream 设置K线推送源（nil表示每次都通过REST获取K线）
+func SetKlineStream(s *KlineStream) {
+	klineStreamMu.Lock()
+	defer klineStreamMu.Unlock()
+	klineStream = s
+}
+
+func currentKlineStream() *KlineStream {
+	klineStreamMu.RLock()
+	defer klineStreamMu.RUnlock()
+	return klineStream
+}
+
+// Start 启动推送订阅（自动重连，重连后重新订阅已跟踪的币种）
+func (s *KlineStream) Start() {
+	s.mu.Lock()
+	s.isRunning = true
+	s.mu.Unlock()
+
+	go s.connectLoop()
+	log.Println("🔌 K线推送已启动（本地计算指标，REST仅用于冷启动）")
+}
+
+// Stop 停止订阅
+func (s *KlineStream) Stop() {
+	s.mu.Lock()
+	s.isRunning = false
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+	log.Println("🔌 K线推送已停止")
+}
+
+// Connected K线推送当前是否已连接
+func (s *KlineStream) Connected() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isRunning && s.conn != nil
+}
+
+func (s *KlineStream) running() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isRunning
+}
+
+// connectLoop 连接循环（断开后5秒重连）
+func (s *KlineStream) connectLoop() {
+	for s.running() {
+		dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
+		conn, _, err := dialer.Dial(s.wsURL, nil)
+		if err != nil {
+			log.Printf("❌ K线推送连接失败: %v，5秒后重试...", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		s.mu.Lock()
+		s.conn = conn
+		symbols := make([]string, 0, len(s.subscribed))
+		for symbol := range s.subscribed {
+			symbols = append(symbols, symbol)
+		}
+		// 断线期间可能漏掉K线，全部重新冷启动
+		s.candles = make(map[string][]Kline)
+		s.mu.Unlock()
+
+		if len(symbols) > 0 {
+			s.sendSubscribe(symbols)
+		}
+
+		s.receiveMessages(conn)
+
+		if s.running() {
+			log.Println("⚠️ K线推送连接断开，5秒后重连...")
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+// receiveMessages 接收K线和标记价格推送
+func (s *KlineStream) receiveMessages(conn *websocket.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		s.conn = nil
+		s.mu.Unlock()
+	}()
+
+	for s.running() {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if s.running() {
+				log.Printf("⚠️ K线推送读取错误: %v", err)
+			}
+			return
+		}
+
+		var head struct {
+			Event string `json:"e"`
+		}
+		if err := json.Unmarshal(message, &head); err != nil {
+			continue // 静默跳过解析错误（包括订阅应答）
+		}
+
+		switch head.Event {
+		case "kline":
+			var msg klineStreamMessage
+			if err := json.Unmarshal(message, &msg); err == nil {
+				s.applyKline(msg)
+			}
+		case "markPriceUpdate":
+			var msg markPriceStreamMessage
+			if err := json.Unmarshal(message, &msg); err == nil {
+				s.applyMarkPrice(msg)
+			}
+		}
+	}
+}
+
+// sendSubscribe 订阅币种的K线和标记价格推送
+func (s *KlineStream) sendSubscribe(symbols []string) {
+	s.mu.RLock()
+	conn := s.conn
+	interval := s.interval
+	s.mu.RUnlock()
+	if conn == nil {
+		return // 未连接：连接建立后统一订阅
+	}
+
+	params := make([]string, 0, len(symbols)*2)
+	for _, symbol := range symbols {
+		lower := strings.ToLower(symbol)
+		params = append(params, lower+"@kline_"+interval, lower+"@markPrice@1s")
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.nextID++
+	req := map[string]interface{}{"method": "SUBSCRIBE", "params": params, "id": s.nextID}
+	if err := conn.WriteJSON(req); err != nil {
+		log.Printf("⚠️ K线推送订阅失败 %v: %v", symbols, err)
+	}
+}
+
+// applyKline 用推送更新滚动K线：同一根K线覆盖，新K线追加，出现缺口则丢弃等待REST重新冷启动
+func (s *KlineStream) applyKline(msg klineStreamMessage) {
+	open, _ := strconv.ParseFloat(msg.Kline.Open, 64)
+	high, _ := strconv.ParseFloat(msg.Kline.High, 64)
+	low, _ := strconv.ParseFloat(msg.Kline.Low, 64)
+	closePrice, _ := strconv.ParseFloat(msg.Kline.Close, 64)
+	volume, _ := strconv.ParseFloat(msg.Kline.Volume, 64)
+	k := Kline{
+		OpenTime:  msg.Kline.OpenTime,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     closePrice,
+		Volume:    volume,
+		CloseTime: msg.Kline.CloseTime,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if msg.Kline.Interval != s.interval {
+		return // 切换周期前的残留推送
+	}
+	candles, ok := s.candles[msg.Symbol]
+	if !ok || len(candles) == 0 {
+		return // 尚未冷启动
+	}
+
+	last := candles[len(candles)-1]
+	intervalMs := int64(getIntervalMinutes(s.interval)) * 60 * 1000
+	switch {
+	case k.OpenTime == last.OpenTime:
+		candles[len(candles)-1] = k
+	case k.OpenTime == last.OpenTime+intervalMs:
+		candles = append(candles, k)
+		if len(candles) > s.limit {
+			candles = candles[len(candles)-s.limit:]
+		}
+	case k.OpenTime > last.OpenTime:
+		log.Printf("⚠️ %s K线推送出现缺口，重新通过REST冷启动", msg.Symbol)
+		delete(s.candles, msg.Symbol)
+		return
+	default:
+		return // 过期推送
+	}
+	s.candles[msg.Symbol] = candles
+	s.updatedAt[msg.Symbol] = time.Now()
+}
+
+// applyMarkPrice 记录标记价格和资金费率
+func (s *KlineStream) applyMarkPrice(msg markPriceStreamMessage) {
+	price, _ := strconv.ParseFloat(msg.MarkPrice, 64)
+	rate, err := strconv.ParseFloat(msg.FundingRate, 64)
+	if price <= 0 || err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.marks[msg.Symbol] = streamMarkPrice{price: price, fundingRate: rate, updatedAt: time.Now()}
+	s.mu.Unlock()
+}
+
+// setInterval 切换K线周期：清空已有K线，按新周期重新订阅（旧周期的推送会被忽略）
+func (s *KlineStream) setInterval(interval string, limit int) {
+	s.mu.Lock()
+	changed := s.interval != interval
+	s.interval = interval
+	s.limit = limit
+	if changed {
+		s.candles = make(map[string][]Kline)
+	}
+	symbols := make([]string, 0, len(s.subscribed))
+	for symbol := range s.subscribed {
+		symbols = append(symbols, symbol)
+	}
+	s.mu.Unlock()
+
+	if changed && len(symbols) > 0 {
+		s.sendSubscribe(symbols)
+	}
+}
+
+// Klines 获取推送维护的K线（未连接、周期不一致、未冷启动或超过2个周期没有更新时返回false，调用方回退到REST）
+func (s *KlineStream) Klines(symbol, interval string) ([]Kline, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.conn == nil || s.interval != interval {
+		return nil, false
+	}
+	candles, ok := s.candles[symbol]
+	if !ok || len(candles) < s.limit/2 {
+		return nil, false
+	}
+	maxAge := 2 * time.Duration(getIntervalMinutes(s.interval)) * time.Minute
+	if time.Since(s.updatedAt[symbol]) > maxAge {
+		return nil, false
+	}
+
+	result := make([]Kline, len(candles))
+	copy(result, candles)
+	return result, true
+}
+
+// Seed 用REST获取的K线冷启动币种，并订阅后续推送（周期与推送不一致的K线忽略，例如长期背景的独立周期）
+func (s *KlineStream) Seed(symbol, interval string, klines []Kline) {
+	s.mu.Lock()
+	if s.interval != interval {
+		s.mu.Unlock()
+		return
+	}
+	if len(klines) > s.limit {
+		klines = klines[len(klines)-s.limit:]
+	}
+	candles := make([]Kline, len(klines))
+	copy(candles, klines)
+	s.candles[symbol] = candles
+	s.updatedAt[symbol] = time.Now()
+	isNew := !s.subscribed[symbol]
+	s.subscribed[symbol] = true
+	s.mu.Unlock()
+
+	if isNew {
+		s.sendSubscribe([]string{symbol})
+	}
+}
+
+// FundingRate 推送的最新资金费率（超过1分钟没有更新时返回false）
+func (s *KlineStream) FundingRate(symbol string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	mark, ok := s.marks[symbol]
+	if !ok || time.Since(mark.updatedAt) > time.Minute {
+		return 0, false
+	}
+	return mark.fundingRate, true
+}
+
+// MarkPrice 推送的最新标记价格（超过1分钟没有更新时返回false）
+func (s *KlineStream) MarkPrice(symbol string) (float64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	mark, ok := s.marks[symbol]
+	if !ok || time.Since(mark.updatedAt) > time.Minute {
+		return 0, false
+	}
+	return mark.price, true
+}
+
+// loadKlines 获取计算指标用的K线：优先使用推送维护的K线，冷启动或推送不可用时走REST（启用磁盘缓存时只补齐缺失部分）
+func loadKlines(symbol, interval string, limit int) ([]Kline, error) {
+	stream := currentKlineStream()
+	if stream != nil {
+		if klines, ok := stream.Klines(symbol, interval); ok {
+			return klines, nil
+		}
+	}
+
+	klines, err := fetchKlines(symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+	if stream != nil {
+		stream.Seed(symbol, interval, klines)
+	}
+	return klines, nil
+}
+
+// loadFundingRate 获取资金费率：优先使用标记价格推送，否则走REST
+func loadFundingRate(symbol string) (float64, error) {
+	if stream := currentKlineStream(); stream != nil {
+		if rate, ok := stream.FundingRate(symbol); ok {
+			return rate, nil
+		}
+	}
+	return getFundingRate(symbol)
+}
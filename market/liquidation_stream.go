@@ -0,0 +1,308 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// LiquidationProvider 清算数据源（填充 ExtendedData.Liquidation）
+type LiquidationProvider interface {
+	Name() string
+	GetLiquidationData(symbol string) (*LiquidationData, error)
+}
+
+var (
+	liquidationProviderMu sync.RWMutex
+	liquidationProvider   LiquidationProvider
+)
+
+// SetLiquidationProvider 设置清算数据源（nil表示只使用订单簿估算）
+// 数据源返回错误（如数据不足）时，GetExtendedData 回退到订单簿估算
+func SetLiquidationProvider(p LiquidationProvider) {
+	liquidationProviderMu.Lock()
+	defer liquidationProviderMu.Unlock()
+	liquidationProvider = p
+}
+
+// getLiquidationData 优先使用已配置的数据源，失败时回退到订单簿估算
+func getLiquidationData(symbol string) (*LiquidationData, error) {
+	liquidationProviderMu.RLock()
+	p := liquidationProvider
+	liquidationProviderMu.RUnlock()
+
+	if p != nil {
+		data, err := p.GetLiquidationData(symbol)
+		if err == nil && data != nil {
+			return data, nil
+		}
+	}
+
+	// 使用订单簿深度 + 数学估算来预测清算密集区
+	return estimateLiquidationZones(symbol)
+}
+
+// forceOrderMessage 币安强平订单推送（!forceOrder@arr）
+type forceOrderMessage struct {
+	Order struct {
+		Symbol       string `json:"s"`
+		Side         string `json:"S"` // SELL=多头被强平, BUY=空头被强平
+		AveragePrice string `json:"ap"`
+		FilledQty    string `json:"z"`
+		TradeTime    int64  `json:"T"`
+	} `json:"o"`
+}
+
+// liquidationEvent 单笔强平事件
+type liquidationEvent struct {
+	Side     string // "long"（多头被强平）/ "short"（空头被强平）
+	Price    float64
+	Notional float64 // USD
+	Time     time.Time
+}
+
+// LiquidationStreamMonitor 基于币安强平订单流的清算热力图
+// 订阅全市场强平推送，按价格分桶聚合最近一段时间的强平量，得到真实发生的清算密集区
+type LiquidationStreamMonitor struct {
+	wsURL     string
+	Window    time.Duration // 聚合窗口
+	BucketPct float64       // 价格分桶宽度（占当前价比例）
+	MaxZones  int           // 每个方向最多输出的密集区数量
+	MinEvents int           // 最少事件数（不足时返回错误，回退到订单簿估算）
+
+	mu        sync.RWMutex
+	events    map[string][]liquidationEvent // symbol -> 事件（按时间顺序）
+	conn      *websocket.Conn
+	isRunning bool
+}
+
+// NewLiquidationStreamMonitor 创建强平订单流监控器
+func NewLiquidationStreamMonitor() *LiquidationStreamMonitor {
+	return &LiquidationStreamMonitor{
+		wsURL:     "wss://fstream.binance.com/ws/!forceOrder@arr",
+		Window:    4 * time.Hour,
+		BucketPct: 0.0025, // 0.25%一个桶
+		MaxZones:  5,
+		MinEvents: 3,
+		events:    make(map[string][]liquidationEvent),
+	}
+}
+
+// Name 数据源名称
+func (m *LiquidationStreamMonitor) Name() string { return "binance_force_order" }
+
+// Start 启动强平订单流订阅（自动重连）
+func (m *LiquidationStreamMonitor) Start() {
+	m.mu.Lock()
+	m.isRunning = true
+	m.mu.Unlock()
+
+	go m.connectLoop()
+	log.Println("🔌 强平订单流监控已启动（清算热力图）")
+}
+
+// Stop 停止订阅
+func (m *LiquidationStreamMonitor) Stop() {
+	m.mu.Lock()
+	m.isRunning = false
+	conn := m.conn
+	m.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+	log.Println("🔌 强平订单流监控已停止")
+}
+
+// Connected 强平订单流当前是否已连接
+func (m *LiquidationStreamMonitor) Connected() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.isRunning && m.conn != nil
+}
+
+// LiquidationStreamStatus 清算数据源的连接状态（enabled=是否配置了推送型数据源）
+func LiquidationStreamStatus() (name string, enabled, connected bool) {
+	liquidationProviderMu.RLock()
+	p := liquidationProvider
+	liquidationProviderMu.RUnlock()
+
+	stream, ok := p.(interface{ Connected() bool })
+	if !ok {
+		return "", false, false
+	}
+	return p.Name(), true, stream.Connected()
+}
+
+func (m *LiquidationStreamMonitor) running() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.isRunning
+}
+
+// connectLoop 连接循环（断开后5秒重连）
+func (m *LiquidationStreamMonitor) connectLoop() {
+	for m.running() {
+		dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
+		conn, _, err := dialer.Dial(m.wsURL, nil)
+		if err != nil {
+			log.Printf("❌ 强平订单流连接失败: %v，5秒后重试...", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		m.mu.Lock()
+		m.conn = conn
+		m.mu.Unlock()
+
+		m.receiveMessages(conn)
+
+		if m.running() {
+			log.Println("⚠️ 强平订单流连接断开，5秒后重连...")
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+// receiveMessages 接收强平推送
+func (m *LiquidationStreamMonitor) receiveMessages(conn *websocket.Conn) {
+	defer func() {
+		conn.Close()
+		m.mu.Lock()
+		m.conn = nil
+		m.mu.Unlock()
+	}()
+
+	for m.running() {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if m.running() {
+				log.Printf("⚠️ 强平订单流读取错误: %v", err)
+			}
+			return
+		}
+
+		var msg forceOrderMessage
+		if err := json.Unmarshal(message, &msg); err != nil {
+			continue // 静默跳过解析错误
+		}
+		m.addEvent(msg)
+	}
+}
+
+// addEvent 记录一笔强平，并清理窗口外的旧事件
+func (m *LiquidationStreamMonitor) addEvent(msg forceOrderMessage) {
+	price, _ := strconv.ParseFloat(msg.Order.AveragePrice, 64)
+	qty, _ := strconv.ParseFloat(msg.Order.FilledQty, 64)
+	if price <= 0 || qty <= 0 {
+		return
+	}
+
+	side := "long"
+	if msg.Order.Side == "BUY" {
+		side = "short"
+	}
+	event := liquidationEvent{
+		Side:     side,
+		Price:    price,
+		Notional: price * qty,
+		Time:     time.UnixMilli(msg.Order.TradeTime),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	events := append(m.events[msg.Order.Symbol], event)
+	cutoff := time.Now().Add(-m.Window)
+	start := 0
+	for start < len(events) && events[start].Time.Before(cutoff) {
+		start++
+	}
+	m.events[msg.Order.Symbol] = events[start:]
+}
+
+// GetLiquidationData 聚合窗口内的强平事件为清算密集区
+func (m *LiquidationStreamMonitor) GetLiquidationData(symbol string) (*LiquidationData, error) {
+	symbol = Normalize(symbol)
+
+	m.mu.RLock()
+	cutoff := time.Now().Add(-m.Window)
+	var events []liquidationEvent
+	for _, e := range m.events[symbol] {
+		if !e.Time.Before(cutoff) {
+			events = append(events, e)
+		}
+	}
+	m.mu.RUnlock()
+
+	if len(events) < m.MinEvents {
+		return nil, fmt.Errorf("%s 强平事件不足（%d笔）", symbol, len(events))
+	}
+
+	// 以最新成交价确定桶宽
+	bucketWidth := events[len(events)-1].Price * m.BucketPct
+	if bucketWidth <= 0 {
+		return nil, fmt.Errorf("%s 价格无效", symbol)
+	}
+
+	longBuckets := make(map[int64]*LiqZone)
+	shortBuckets := make(map[int64]*LiqZone)
+	var longVol, shortVol, recentVol float64
+	recentCutoff := time.Now().Add(-time.Hour)
+
+	for _, e := range events {
+		buckets := longBuckets
+		if e.Side == "short" {
+			buckets = shortBuckets
+			shortVol += e.Notional
+		} else {
+			longVol += e.Notional
+		}
+		if !e.Time.Before(recentCutoff) {
+			recentVol += e.Notional
+		}
+
+		idx := int64(math.Floor(e.Price / bucketWidth))
+		zone, ok := buckets[idx]
+		if !ok {
+			zone = &LiqZone{Price: (float64(idx) + 0.5) * bucketWidth}
+			buckets[idx] = zone
+		}
+		zone.Volume += e.Notional
+	}
+
+	liqTrend := "balanced"
+	if longVol > shortVol*1.5 {
+		liqTrend = "long_heavy"
+	} else if shortVol > longVol*1.5 {
+		liqTrend = "short_heavy"
+	}
+
+	return &LiquidationData{
+		LongLiqZones:  topLiqZones(longBuckets, m.MaxZones),
+		ShortLiqZones: topLiqZones(shortBuckets, m.MaxZones),
+		RecentLiqVol:  recentVol,
+		LiqTrend:      liqTrend,
+	}, nil
+}
+
+// topLiqZones 取清算量最大的N个桶，再按价格排序（与 LiquidationData 的约定一致）
+func topLiqZones(buckets map[int64]*LiqZone, limit int) []LiqZone {
+	zones := make([]LiqZone, 0, len(buckets))
+	for _, z := range buckets {
+		zones = append(zones, *z)
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Volume > zones[j].Volume })
+	if len(zones) > limit {
+		zones = zones[:limit]
+	}
+	sortLiqZones(zones)
+	return zones
+}
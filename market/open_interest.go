@@ -0,0 +1,84 @@
+package market
+
+import "math"
+
+// OI与价格关系（4小时窗口）
+const (
+	OILongBuild     = "long_build"     // 价涨OI增：新多入场，趋势确认
+	OIShortCovering = "short_covering" // 价涨OI减：空头回补，上涨动能偏弱
+	OIShortBuild    = "short_build"    // 价跌OI增：新空入场，下跌趋势确认
+	OILongUnwinding = "long_unwinding" // 价跌OI减：多头平仓，下跌动能偏弱
+)
+
+const (
+	oiHistoryPeriod   = "5m" // openInterestHist 采样周期
+	oiHistoryLimit    = 289  // 24小时 + 1个点
+	minOISignalChange = 1.0  // OI变化绝对值低于1%时不判断OI与价格关系
+	minPriceChange    = 0.3  // 价格变化绝对值低于0.3%时视为横盘
+)
+
+// OIChanges OI历史变化（百分比，数据不足的窗口为0）
+type OIChanges struct {
+	Change1h  float64
+	Change4h  float64
+	Change24h float64
+}
+
+// getOIChanges 通过 openInterestHist 计算1h/4h/24h的OI变化
+// latest 为实时OI（来自 /fapi/v1/openInterest），为0时使用历史最后一个点
+func getOIChanges(symbol string, latest float64) (*OIChanges, error) {
+	history, err := getOIHistory(symbol, oiHistoryPeriod, oiHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	return calculateOIChanges(history, latest), nil
+}
+
+// calculateOIChanges 计算各窗口的OI变化（5分钟采样：1h=12个点，4h=48个点，24h=288个点）
+func calculateOIChanges(history []OIHistoryPoint, latest float64) *OIChanges {
+	changes := &OIChanges{}
+	if len(history) == 0 {
+		return changes
+	}
+	if latest <= 0 {
+		latest = history[len(history)-1].OpenInterest
+	}
+
+	changeSince := func(periods int) float64 {
+		if len(history) < periods+1 {
+			return 0
+		}
+		past := history[len(history)-1-periods].OpenInterest
+		if past <= 0 {
+			return 0
+		}
+		return (latest - past) / past * 100
+	}
+
+	changes.Change1h = changeSince(12)
+	changes.Change4h = changeSince(48)
+	changes.Change24h = changeSince(288)
+	return changes
+}
+
+// ClassifyOIPrice 根据同一窗口的价格变化与OI变化判断资金行为（变化太小返回空）
+func ClassifyOIPrice(priceChangePct, oiChangePct float64) string {
+	if math.Abs(oiChangePct) < minOISignalChange || math.Abs(priceChangePct) < minPriceChange {
+		return ""
+	}
+	switch {
+	case priceChangePct > 0 && oiChangePct > 0:
+		return OILongBuild
+	case priceChangePct > 0:
+		return OIShortCovering
+	case oiChangePct > 0:
+		return OIShortBuild
+	default:
+		return OILongUnwinding
+	}
+}
+
+// isOIDivergence 价格与OI反向变动（上涨/下跌缺少新资金支持）
+func isOIDivergence(signal string) bool {
+	return signal == OIShortCovering || signal == OILongUnwinding
+}
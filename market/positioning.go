@@ -0,0 +1,156 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 多空拥挤度
+const (
+	CrowdedLong  = "crowded_long"  // 散户账户大幅偏多（反向风险：多头踩踏）
+	CrowdedShort = "crowded_short" // 散户账户大幅偏空（反向风险：空头挤压）
+	Balanced     = "balanced"
+)
+
+const (
+	positioningPeriod   = "5m"             // 币安合约数据接口统计周期
+	positioningTTL      = 5 * time.Minute  // 与统计周期一致，周期内数据不变
+	positioningStaleTTL = 30 * time.Minute // 获取失败时可继续使用的旧数据时长
+	crowdedLongRatio    = 2.0              // 全市场多空账户比 ≥ 2.0 视为多头拥挤
+	crowdedShortRatio   = 0.7              // 全市场多空账户比 ≤ 0.7 视为空头拥挤
+)
+
+// PositioningData 多空持仓结构（币安合约数据接口，5分钟统计）
+type PositioningData struct {
+	GlobalLongShortRatio float64   `json:"global_long_short_ratio"` // 全市场多空账户数比
+	GlobalLongPct        float64   `json:"global_long_pct"`         // 全市场多头账户占比（%）
+	TopLongShortRatio    float64   `json:"top_long_short_ratio"`    // 大户多空持仓量比
+	TakerBuySellRatio    float64   `json:"taker_buy_sell_ratio"`    // 主动买卖量比（>1主动买入占优）
+	Crowding             string    `json:"crowding"`                // crowded_long/crowded_short/balanced
+	FetchedAt            time.Time `json:"fetched_at"`
+}
+
+var (
+	positioningMu    sync.Mutex
+	positioningCache = make(map[string]*PositioningData)
+)
+
+// GetPositioningData 获取多空持仓结构（带缓存，三个接口任一成功即返回）
+func GetPositioningData(symbol string) (*PositioningData, error) {
+	symbol = Normalize(symbol)
+
+	positioningMu.Lock()
+	cached := positioningCache[symbol]
+	positioningMu.Unlock()
+	if cached != nil && time.Since(cached.FetchedAt) < positioningTTL {
+		return cached, nil
+	}
+
+	data, err := fetchPositioningData(symbol)
+	if err != nil {
+		if cached != nil && time.Since(cached.FetchedAt) < positioningStaleTTL {
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	positioningMu.Lock()
+	positioningCache[symbol] = data
+	positioningMu.Unlock()
+	return data, nil
+}
+
+// fetchPositioningData 请求全市场多空比、大户持仓多空比、主动买卖量比
+func fetchPositioningData(symbol string) (*PositioningData, error) {
+	data := &PositioningData{Crowding: Balanced, FetchedAt: time.Now()}
+	var errs []error
+
+	var global []struct {
+		LongShortRatio string `json:"longShortRatio"`
+		LongAccount    string `json:"longAccount"`
+	}
+	if err := getFuturesData("globalLongShortAccountRatio", symbol, &global); err != nil {
+		errs = append(errs, err)
+	} else if len(global) > 0 {
+		data.GlobalLongShortRatio, _ = strconv.ParseFloat(global[0].LongShortRatio, 64)
+		longAccount, _ := strconv.ParseFloat(global[0].LongAccount, 64)
+		data.GlobalLongPct = longAccount * 100
+	}
+
+	var top []struct {
+		LongShortRatio string `json:"longShortRatio"`
+	}
+	if err := getFuturesData("topLongShortPositionRatio", symbol, &top); err != nil {
+		errs = append(errs, err)
+	} else if len(top) > 0 {
+		data.TopLongShortRatio, _ = strconv.ParseFloat(top[0].LongShortRatio, 64)
+	}
+
+	var taker []struct {
+		BuySellRatio string `json:"buySellRatio"`
+	}
+	if err := getFuturesData("takerlongshortRatio", symbol, &taker); err != nil {
+		errs = append(errs, err)
+	} else if len(taker) > 0 {
+		data.TakerBuySellRatio, _ = strconv.ParseFloat(taker[0].BuySellRatio, 64)
+	}
+
+	if len(errs) == 3 {
+		return nil, fmt.Errorf("获取%s多空数据失败: %v", symbol, errs[0])
+	}
+
+	switch {
+	case data.GlobalLongShortRatio >= crowdedLongRatio:
+		data.Crowding = CrowdedLong
+	case data.GlobalLongShortRatio > 0 && data.GlobalLongShortRatio <= crowdedShortRatio:
+		data.Crowding = CrowdedShort
+	}
+	return data, nil
+}
+
+// getFuturesData 请求币安合约数据接口（/futures/data/<endpoint>，取最新一个统计周期）
+func getFuturesData(endpoint, symbol string, out interface{}) error {
+	url := fmt.Sprintf("https://fapi.binance.com/futures/data/%s?symbol=%s&period=%s&limit=1",
+		endpoint, symbol, positioningPeriod)
+
+	resp, err := httpGetWithRateLimit(url)
+	if err != nil {
+		return fmt.Errorf("HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("%s HTTP %d: %s", endpoint, resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%s JSON解析失败: %w", endpoint, err)
+	}
+	return nil
+}
+
+// FormatPositioning 多空持仓结构的紧凑描述，如 "ls=2.31 top_ls=1.12 taker=0.87 crowded_long"
+func FormatPositioning(p *PositioningData) string {
+	var parts []string
+	if p.GlobalLongShortRatio > 0 {
+		parts = append(parts, fmt.Sprintf("ls=%.2f(long %.0f%%)", p.GlobalLongShortRatio, p.GlobalLongPct))
+	}
+	if p.TopLongShortRatio > 0 {
+		parts = append(parts, fmt.Sprintf("top_ls=%.2f", p.TopLongShortRatio))
+	}
+	if p.TakerBuySellRatio > 0 {
+		parts = append(parts, fmt.Sprintf("taker=%.2f", p.TakerBuySellRatio))
+	}
+	if p.Crowding != "" && p.Crowding != Balanced {
+		parts = append(parts, p.Crowding)
+	}
+	return strings.Join(parts, " ")
+}
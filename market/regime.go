@@ -0,0 +1,34 @@
+package market
+
+// 量化市场体制（与决策提示词「1. 量化市场体制」的规则一致，由Go确定性计算，不依赖AI自述）
+const (
+	RegimeUptrend   = "A1" // 上升趋势：价格>EMA50>EMA200
+	RegimeDowntrend = "A2" // 下降趋势：价格<EMA50<EMA200
+	RegimeRange     = "B"  // 宽幅震荡
+	RegimeSqueeze   = "C"  // 窄幅盘整：4h ATR% < 1.0%
+
+	regimeNarrowATRPct = 1.0
+)
+
+// QuantRegime 根据BTC的4h数据判断市场体制（数据不足时返回空）
+func QuantRegime(btc *Data) string {
+	if btc == nil || btc.LongerTermContext == nil || btc.CurrentPrice <= 0 {
+		return ""
+	}
+	lt := btc.LongerTermContext
+	if lt.ATR14 <= 0 || lt.EMA50 <= 0 || lt.EMA200 <= 0 {
+		return ""
+	}
+
+	if lt.ATR14/btc.CurrentPrice*100 < regimeNarrowATRPct {
+		return RegimeSqueeze
+	}
+	switch {
+	case btc.CurrentPrice > lt.EMA50 && lt.EMA50 > lt.EMA200:
+		return RegimeUptrend
+	case btc.CurrentPrice < lt.EMA50 && lt.EMA50 < lt.EMA200:
+		return RegimeDowntrend
+	default:
+		return RegimeRange
+	}
+}
@@ -0,0 +1,240 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 情绪数据缓存时间
+// 恐慌贪婪指数每天更新一次，没必要每个币种每个周期都请求
+const (
+	fearGreedTTL      = 30 * time.Minute
+	fearGreedStaleTTL = 24 * time.Hour // 请求失败时，24小时内的旧数据仍可使用
+	socialTTL         = 10 * time.Minute
+	socialStaleTTL    = 2 * time.Hour
+)
+
+// FearGreedIndex 恐慌贪婪指数（alternative.me）
+type FearGreedIndex struct {
+	Value          int       `json:"value"`          // 0-100
+	Classification string    `json:"classification"` // Extreme Fear / Fear / Neutral / Greed / Extreme Greed
+	FetchedAt      time.Time `json:"fetched_at"`
+}
+
+// SocialSentiment 单个币种的社交/新闻情绪
+type SocialSentiment struct {
+	SocialVolume float64 `json:"social_volume"` // 讨论量变化百分比
+	Sentiment    string  `json:"sentiment"`     // "bullish", "bearish", "neutral"
+	NewsImpact   string  `json:"news_impact"`   // "positive", "negative", "neutral"
+}
+
+// SocialSentimentProvider 可插拔的社交情绪数据源
+type SocialSentimentProvider interface {
+	Name() string
+	GetSocialSentiment(symbol string) (*SocialSentiment, error)
+}
+
+type cachedSocial struct {
+	data      *SocialSentiment
+	fetchedAt time.Time
+}
+
+var (
+	sentimentMu      sync.Mutex
+	fearGreedCache   *FearGreedIndex
+	socialProvider   SocialSentimentProvider
+	socialCache      = make(map[string]cachedSocial)
+	fearGreedBaseURL = "https://api.alternative.me/fng/?limit=1"
+)
+
+// SetSocialSentimentProvider 设置社交情绪数据源（nil表示只根据恐慌贪婪指数推断）
+func SetSocialSentimentProvider(p SocialSentimentProvider) {
+	sentimentMu.Lock()
+	defer sentimentMu.Unlock()
+	socialProvider = p
+	socialCache = make(map[string]cachedSocial)
+}
+
+// getSentimentData 获取情绪数据（恐慌贪婪指数 + 可选的社交情绪）
+func getSentimentData(symbol string) (*SentimentData, error) {
+	fng, err := GetFearGreedIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	data := &SentimentData{
+		FearGreedIndex:  fng.Value,
+		SocialVolume:    0, // 未配置社交数据源时无数据
+		SocialSentiment: sentimentFromFearGreed(fng.Classification),
+		NewsImpact:      "neutral",
+	}
+
+	// 社交数据源可选：失败时保留恐慌贪婪指数推断的结果
+	if social := getSocialSentiment(symbol); social != nil {
+		data.SocialVolume = social.SocialVolume
+		if social.Sentiment != "" {
+			data.SocialSentiment = social.Sentiment
+		}
+		if social.NewsImpact != "" {
+			data.NewsImpact = social.NewsImpact
+		}
+	}
+
+	return data, nil
+}
+
+// GetFearGreedIndex 获取恐慌贪婪指数（带缓存，请求失败时使用未过期的旧数据）
+func GetFearGreedIndex() (*FearGreedIndex, error) {
+	sentimentMu.Lock()
+	cached := fearGreedCache
+	sentimentMu.Unlock()
+
+	if cached != nil && time.Since(cached.FetchedAt) < fearGreedTTL {
+		return cached, nil
+	}
+
+	fng, err := fetchFearGreedIndex()
+	if err != nil {
+		if cached != nil && time.Since(cached.FetchedAt) < fearGreedStaleTTL {
+			log.Printf("⚠️  获取恐慌贪婪指数失败，使用%s前的缓存: %v",
+				time.Since(cached.FetchedAt).Round(time.Minute), err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	sentimentMu.Lock()
+	fearGreedCache = fng
+	sentimentMu.Unlock()
+	return fng, nil
+}
+
+// fetchFearGreedIndex 请求alternative.me恐慌贪婪指数（免费API）
+func fetchFearGreedIndex() (*FearGreedIndex, error) {
+	resp, err := httpGetWithRateLimit(fearGreedBaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data []struct {
+			Value               string `json:"value"`
+			ValueClassification string `json:"value_classification"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("JSON解析失败: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return nil, fmt.Errorf("无数据")
+	}
+
+	value := 50 // 默认中性
+	if v, err := strconv.Atoi(result.Data[0].Value); err == nil {
+		value = v
+	}
+
+	return &FearGreedIndex{
+		Value:          value,
+		Classification: result.Data[0].ValueClassification,
+		FetchedAt:      time.Now(),
+	}, nil
+}
+
+// sentimentFromFearGreed 根据恐慌贪婪分类推断整体情绪
+func sentimentFromFearGreed(classification string) string {
+	switch classification {
+	case "Extreme Fear", "Fear":
+		return "bearish"
+	case "Greed", "Extreme Greed":
+		return "bullish"
+	default:
+		return "neutral"
+	}
+}
+
+// getSocialSentiment 获取社交情绪（带缓存，未配置数据源或失败且无缓存时返回nil）
+func getSocialSentiment(symbol string) *SocialSentiment {
+	sentimentMu.Lock()
+	provider := socialProvider
+	cached, hasCache := socialCache[symbol]
+	sentimentMu.Unlock()
+
+	if provider == nil {
+		return nil
+	}
+	if hasCache && time.Since(cached.fetchedAt) < socialTTL {
+		return cached.data
+	}
+
+	data, err := provider.GetSocialSentiment(symbol)
+	if err != nil || data == nil {
+		if hasCache && time.Since(cached.fetchedAt) < socialStaleTTL {
+			return cached.data
+		}
+		if err != nil {
+			log.Printf("⚠️  获取%s社交情绪失败[%s]: %v", symbol, provider.Name(), err)
+		}
+		return nil
+	}
+
+	sentimentMu.Lock()
+	socialCache[symbol] = cachedSocial{data: data, fetchedAt: time.Now()}
+	sentimentMu.Unlock()
+	return data
+}
+
+// HTTPSocialFeedProvider 通用HTTP社交情绪数据源
+// URL中的 {symbol} 会被替换为币种（如 BTC），响应格式与 SocialSentiment 的JSON字段一致
+type HTTPSocialFeedProvider struct {
+	URLTemplate string
+}
+
+// NewHTTPSocialFeedProvider 创建HTTP社交情绪数据源
+func NewHTTPSocialFeedProvider(urlTemplate string) *HTTPSocialFeedProvider {
+	return &HTTPSocialFeedProvider{URLTemplate: urlTemplate}
+}
+
+// Name 数据源名称
+func (p *HTTPSocialFeedProvider) Name() string { return "http_feed" }
+
+// GetSocialSentiment 请求外部社交情绪API
+func (p *HTTPSocialFeedProvider) GetSocialSentiment(symbol string) (*SocialSentiment, error) {
+	base := BaseAsset(Normalize(symbol))
+	url := strings.ReplaceAll(p.URLTemplate, "{symbol}", base)
+
+	resp, err := httpGetWithRateLimit(url)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var data SocialSentiment
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("JSON解析失败: %w", err)
+	}
+	return &data, nil
+}
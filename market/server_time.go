@@ -0,0 +1,38 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// ServerTimeSkew 本地时钟相对币安合约服务器时间的偏差（正值=本地时间超前）
+// 以请求往返的中点作为服务器时间对应的本地时刻
+func ServerTimeSkew() (time.Duration, error) {
+	start := time.Now()
+	resp, err := httpGetWithRateLimit("https://fapi.binance.com/fapi/v1/time")
+	if err != nil {
+		return 0, fmt.Errorf("HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	end := time.Now()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != 200 {
+		return 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("JSON解析失败: %w", err)
+	}
+
+	local := start.Add(end.Sub(start) / 2)
+	return local.Sub(time.UnixMilli(result.ServerTime)), nil
+}
@@ -0,0 +1,82 @@
+package market
+
+import (
+	"fmt"
+	"sync"
+)
+
+// 行情数据源名称
+const (
+	SourceBinance     = "binance"
+	SourceHyperliquid = "hyperliquid"
+)
+
+// Source 行情数据源：决策使用的K线、资金费率和持仓量
+// 默认使用币安；在其他交易所交易时可以切换到该交易所自己的数据，避免价格和资金费率与实际成交场所不一致
+// 衍生品扩展数据（多空比、清算热力图、OI历史等）仍来自币安，作为全市场参考
+type Source interface {
+	Name() string
+	// Klines 获取K线（最后一根为未收盘K线）
+	Klines(symbol, interval string, limit int) ([]Kline, error)
+	// FundingRate 资金费率（按8小时折算，与币安口径一致）
+	FundingRate(symbol string) (float64, error)
+	// OpenInterest 持仓量（币数量）
+	OpenInterest(symbol string) (float64, error)
+}
+
+// binanceSource 币安合约数据源（K线推送/磁盘缓存/REST）
+type binanceSource struct{}
+
+func (binanceSource) Name() string { return SourceBinance }
+
+func (binanceSource) Klines(symbol, interval string, limit int) ([]Kline, error) {
+	return loadKlines(symbol, interval, limit)
+}
+
+func (binanceSource) FundingRate(symbol string) (float64, error) {
+	return loadFundingRate(symbol)
+}
+
+func (binanceSource) OpenInterest(symbol string) (float64, error) {
+	oi, err := getOpenInterestData(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return oi.Latest, nil
+}
+
+var (
+	sourcesMu sync.Mutex
+	sources   = map[string]Source{SourceBinance: binanceSource{}}
+)
+
+// SourceFor 按名称获取行情数据源（空字符串为币安），同名数据源在所有trader间共享，首次使用时启动
+func SourceFor(name string) (Source, error) {
+	if name == "" {
+		name = SourceBinance
+	}
+
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if src, ok := sources[name]; ok {
+		return src, nil
+	}
+
+	switch name {
+	case SourceHyperliquid:
+		src := NewHyperliquidSource()
+		src.Start()
+		sources[name] = src
+		return src, nil
+	default:
+		return nil, fmt.Errorf("不支持的行情数据源: %s", name)
+	}
+}
+
+// sourceKey 缓存键：币安保持原有的币种键，其他数据源加前缀隔离
+func sourceKey(src Source, symbol string) string {
+	if src == nil || src.Name() == SourceBinance {
+		return symbol
+	}
+	return src.Name() + ":" + symbol
+}
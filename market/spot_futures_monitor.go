@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"net/http"
+	"nofx/ratelimit"
 	"strconv"
 	"sync"
 	"time"
@@ -19,13 +21,13 @@ type SpotFuturesSignal struct {
 	Timestamp       time.Time `json:"timestamp"`
 	SpotPrice       float64   `json:"spot_price"`
 	FuturesPrice    float64   `json:"futures_price"`
-	PriceDiffPct    float64   `json:"price_diff_pct"`    // 价差百分比
-	SpotVolume24h   float64   `json:"spot_volume_24h"`   // 现货24h成交量
-	FuturesOI       float64   `json:"futures_oi"`        // 期货持仓量
-	Confidence      int       `json:"confidence"`        // 1-3星
-	SignalType      string    `json:"signal_type"`       // "early" 早期信号
-	SuggestedAction string    `json:"suggested_action"`  // "watch" 或 "prepare_long"
-	Reasoning       string    `json:"reasoning"`         // 信号原因
+	PriceDiffPct    float64   `json:"price_diff_pct"`   // 价差百分比
+	SpotVolume24h   float64   `json:"spot_volume_24h"`  // 现货24h成交量
+	FuturesOI       float64   `json:"futures_oi"`       // 期货持仓量
+	Confidence      int       `json:"confidence"`       // 1-3星
+	SignalType      string    `json:"signal_type"`      // "early" 早期信号
+	SuggestedAction string    `json:"suggested_action"` // "watch" 或 "prepare_long"
+	Reasoning       string    `json:"reasoning"`        // 信号原因
 }
 
 // SpotFuturesMonitor 现货期货价差监控器
@@ -35,17 +37,20 @@ type SpotFuturesMonitor struct {
 	wsMonitor     *AltcoinWSMonitor // 复用WebSocket获取期货价格
 
 	// 价差阈值
-	minPriceDiff  float64 // 最小价差（默认0.5%）
+	minPriceDiff float64 // 最小价差（默认0.5%）
 
-	mu              sync.RWMutex
-	lastScanTime    time.Time
-	signalCount     int
+	mu           sync.RWMutex
+	lastScanTime time.Time
+	signalCount  int
 }
 
 // NewSpotFuturesMonitor 创建现货期货价差监控器
 func NewSpotFuturesMonitor(spotAPIKey, spotSecretKey string, futuresClient *futures.Client, wsMonitor *AltcoinWSMonitor) *SpotFuturesMonitor {
+	spotClient := binance.NewClient(spotAPIKey, spotSecretKey)
+	spotClient.HTTPClient = &http.Client{Transport: ratelimit.NewTransport(nil)} // 🆕 价差扫描为非关键请求，预算不足时跳过
+
 	return &SpotFuturesMonitor{
-		spotClient:    binance.NewClient(spotAPIKey, spotSecretKey),
+		spotClient:    spotClient,
 		futuresClient: futuresClient,
 		wsMonitor:     wsMonitor,
 		minPriceDiff:  0.5, // 0.5%价差触发
@@ -168,7 +173,7 @@ func (m *SpotFuturesMonitor) checkSymbolPriceDiff(symbol string) (*SpotFuturesSi
 
 // getSpotPrice 获取现货价格
 func (m *SpotFuturesMonitor) getSpotPrice(symbol string) (float64, error) {
-	prices, err := m.spotClient.NewListPricesService().Symbol(symbol).Do(context.Background())
+	prices, err := m.spotClient.NewListPricesService().Symbol(symbol).Do(ratelimit.NonCritical(context.Background()))
 	if err != nil {
 		return 0, err
 	}
@@ -188,7 +193,7 @@ func (m *SpotFuturesMonitor) getSpotPrice(symbol string) (float64, error) {
 
 // getSpotVolume24h 获取现货24h成交量
 func (m *SpotFuturesMonitor) getSpotVolume24h(symbol string) (float64, error) {
-	stats, err := m.spotClient.NewListPriceChangeStatsService().Symbol(symbol).Do(context.Background())
+	stats, err := m.spotClient.NewListPriceChangeStatsService().Symbol(symbol).Do(ratelimit.NonCritical(context.Background()))
 	if err != nil {
 		return 0, err
 	}
@@ -208,7 +213,7 @@ func (m *SpotFuturesMonitor) getSpotVolume24h(symbol string) (float64, error) {
 
 // getFuturesOI 获取期货持仓量
 func (m *SpotFuturesMonitor) getFuturesOI(symbol string) (float64, error) {
-	oi, err := m.futuresClient.NewOpenInterestStatisticsService().Symbol(symbol).Period("5m").Do(context.Background())
+	oi, err := m.futuresClient.NewOpenInterestStatisticsService().Symbol(symbol).Period("5m").Do(ratelimit.NonCritical(context.Background()))
 	if err != nil {
 		return 0, err
 	}
@@ -2,11 +2,15 @@ package mcp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"nofx/errs"
 	"strings"
 	"time"
 )
@@ -28,7 +32,9 @@ type Client struct {
 	BaseURL    string
 	Model      string
 	Timeout    time.Duration
-	UseFullURL bool // 是否使用完整URL（不添加/chat/completions）
+	UseFullURL bool      // 是否使用完整URL（不添加/chat/completions）
+	MaxTokens  int       // 单次回复最大token数（0=默认2000）
+	Failover   *Failover // 🔁 主模型连续超时/5xx时切换到备用模型（nil=不启用，复制的客户端共享健康状态）
 }
 
 func New() *Client {
@@ -36,7 +42,7 @@ func New() *Client {
 	var defaultClient = Client{
 		Provider: ProviderDeepSeek,
 		BaseURL:  "https://api.deepseek.com/v1",
-		Model:    "deepseek-chat", // DeepSeek Chat 标准对话模型（返回JSON格式）
+		Model:    "deepseek-chat",   // DeepSeek Chat 标准对话模型（返回JSON格式）
 		Timeout:  240 * time.Second, // 增加到240秒，DeepSeek在高峰期可能响应较慢
 	}
 	return &defaultClient
@@ -87,10 +93,23 @@ func (cfg *Client) SetClient(Client Client) {
 
 // CallWithMessages 使用 system + user prompt 调用AI API（推荐）
 func (cfg *Client) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	return cfg.CallWithContext(context.Background(), systemPrompt, userPrompt)
+}
+
+// CallWithContext 带上下文的AI调用：ctx到期（如周期时间预算耗尽）时立即中止请求和重试
+// 单次请求仍受 cfg.Timeout 限制
+func (cfg *Client) CallWithContext(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
 	if cfg.APIKey == "" {
 		return "", fmt.Errorf("AI API密钥未设置，请先调用 SetDeepSeekAPIKey() 或 SetQwenAPIKey()")
 	}
+	if cfg.Failover != nil {
+		return cfg.Failover.call(ctx, cfg, systemPrompt, userPrompt)
+	}
+	return cfg.callWithRetry(ctx, systemPrompt, userPrompt, nil)
+}
 
+// callWithRetry 带重试的调用；giveUp在每次可重试的失败后调用，返回true时不再重试（故障切换已生效）
+func (cfg *Client) callWithRetry(ctx context.Context, systemPrompt, userPrompt string, giveUp func(err error) bool) (string, error) {
 	// 重试配置
 	maxRetries := 3
 	var lastErr error
@@ -100,7 +119,7 @@ func (cfg *Client) CallWithMessages(systemPrompt, userPrompt string) (string, er
 			fmt.Printf("⚠️  AI API调用失败，正在重试 (%d/%d)...\n", attempt, maxRetries)
 		}
 
-		result, err := cfg.callOnce(systemPrompt, userPrompt)
+		result, err := cfg.callOnce(ctx, systemPrompt, userPrompt)
 		if err == nil {
 			if attempt > 1 {
 				fmt.Printf("✓ AI API重试成功\n")
@@ -109,8 +128,15 @@ func (cfg *Client) CallWithMessages(systemPrompt, userPrompt string) (string, er
 		}
 
 		lastErr = err
-		// 如果不是网络错误，不重试
-		if !isRetryableError(err) {
+		// ⌛ 上下文已取消/超出时间预算，不再重试
+		if ctx.Err() != nil {
+			return "", abortedError(ctx)
+		}
+		// 如果不是网络错误/超时/限流，不重试
+		if !errs.Retryable(err) {
+			return "", err
+		}
+		if giveUp != nil && giveUp(err) {
 			return "", err
 		}
 
@@ -118,15 +144,26 @@ func (cfg *Client) CallWithMessages(systemPrompt, userPrompt string) (string, er
 		if attempt < maxRetries {
 			waitTime := time.Duration(attempt) * 2 * time.Second
 			fmt.Printf("⏳ 等待%v后重试...\n", waitTime)
-			time.Sleep(waitTime)
+			select {
+			case <-time.After(waitTime):
+			case <-ctx.Done():
+				return "", abortedError(ctx)
+			}
 		}
 	}
 
 	return "", fmt.Errorf("重试%d次后仍然失败: %w", maxRetries, lastErr)
 }
 
+func (cfg *Client) maxTokens() int {
+	if cfg.MaxTokens > 0 {
+		return cfg.MaxTokens
+	}
+	return 2000
+}
+
 // callOnce 单次调用AI API（内部使用）
-func (cfg *Client) callOnce(systemPrompt, userPrompt string) (string, error) {
+func (cfg *Client) callOnce(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
 	startTime := time.Now()
 	fmt.Printf("📡 调用AI API (%s)...\n", cfg.Provider)
 
@@ -152,7 +189,7 @@ func (cfg *Client) callOnce(systemPrompt, userPrompt string) (string, error) {
 		"model":       cfg.Model,
 		"messages":    messages,
 		"temperature": 0.5, // 降低temperature以提高JSON格式稳定性
-		"max_tokens":  2000,
+		"max_tokens":  cfg.maxTokens(),
 	}
 
 	// 注意：response_format 参数仅 OpenAI 支持，DeepSeek/Qwen 不支持
@@ -172,7 +209,7 @@ func (cfg *Client) callOnce(systemPrompt, userPrompt string) (string, error) {
 		// 默认行为：添加/chat/completions
 		url = fmt.Sprintf("%s/chat/completions", cfg.BaseURL)
 	}
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("创建请求失败: %w", err)
 	}
@@ -197,18 +234,19 @@ func (cfg *Client) callOnce(systemPrompt, userPrompt string) (string, error) {
 	resp, err := client.Do(req)
 	if err != nil {
 		elapsed := time.Since(startTime)
-		return "", fmt.Errorf("发送请求失败 (耗时%.1fs): %w", elapsed.Seconds(), err)
+		return "", errs.Wrap(requestErrorKind(err), 0, fmt.Errorf("发送请求失败 (耗时%.1fs): %w", elapsed.Seconds(), err))
 	}
 	defer resp.Body.Close()
 
 	// 读取响应
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("读取响应失败: %w", err)
+		return "", errs.Wrap(requestErrorKind(err), 0, fmt.Errorf("读取响应失败: %w", err))
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API返回错误 (status %d): %s", resp.StatusCode, string(body))
+		return "", errs.Wrap(statusErrorKind(resp.StatusCode), int64(resp.StatusCode),
+			fmt.Errorf("API返回错误 (status %d): %s", resp.StatusCode, string(body)))
 	}
 
 	// 解析响应 (支持 DeepSeek R1 的 reasoning_content)
@@ -222,11 +260,11 @@ func (cfg *Client) callOnce(systemPrompt, userPrompt string) (string, error) {
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("解析响应失败: %w", err)
+		return "", errs.Wrap(errs.AIParseError, 0, fmt.Errorf("解析响应失败: %w", err))
 	}
 
 	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("API返回空响应")
+		return "", errs.New(errs.AIParseError, "API返回空响应")
 	}
 
 	elapsed := time.Since(startTime)
@@ -250,22 +288,31 @@ func (cfg *Client) callOnce(systemPrompt, userPrompt string) (string, error) {
 	return content, nil
 }
 
-// isRetryableError 判断错误是否可重试
-func isRetryableError(err error) bool {
-	errStr := err.Error()
-	// 网络错误、超时、EOF等可以重试
-	retryableErrors := []string{
-		"EOF",
-		"timeout",
-		"connection reset",
-		"connection refused",
-		"temporary failure",
-		"no such host",
+// requestErrorKind 请求/读取响应失败的错误类别（超时单独归为 AITimeout）
+func requestErrorKind(err error) errs.Kind {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return errs.AITimeout
 	}
-	for _, retryable := range retryableErrors {
-		if strings.Contains(errStr, retryable) {
-			return true
-		}
+	return errs.Network(err)
+}
+
+// statusErrorKind 非200响应的错误类别（429限流、5xx服务端错误可重试）
+func statusErrorKind(status int) errs.Kind {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return errs.RateLimited
+	case status >= 500:
+		return errs.Transient
+	}
+	return errs.Unknown
+}
+
+// abortedError 上下文取消/超出时间预算时的错误（超出预算归为 AITimeout）
+func abortedError(ctx context.Context) error {
+	err := fmt.Errorf("AI调用已中止: %w", ctx.Err())
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errs.Wrap(errs.AITimeout, 0, err)
 	}
-	return false
+	return err
 }
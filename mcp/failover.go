@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"nofx/errs"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailoverMaxFailures = 2                // 默认连续失败多少次后切换
+	defaultFailbackAfter       = 10 * time.Minute // 默认切换后多久重新尝试主模型
+	latencyAlpha               = 0.3              // 主模型延迟EWMA平滑系数
+)
+
+// Failover 🔁 AI故障切换：主模型连续超时/5xx（或响应慢于阈值）时切换到备用模型，
+// 直到BeginCycle发现冷却期已过才重新尝试主模型（周期内不来回切换），主模型成功后自动切回
+type Failover struct {
+	Secondary     *Client
+	MaxFailures   int           // 连续失败多少次后切换（0=默认2）
+	SlowThreshold time.Duration // 主模型成功但耗时超过该值时也记为一次失败（0=不按延迟判断）
+	FailbackAfter time.Duration // 切换后多久重新尝试主模型（0=默认10分钟）
+
+	mu          sync.Mutex
+	failures    int           // 主模型连续失败次数
+	active      bool          // 是否正在使用备用模型
+	since       time.Time     // 最近一次切换到备用模型的时间
+	probing     bool          // 冷却期已过，正在重新尝试主模型
+	lastError   string        // 主模型最近一次失败原因
+	lastFailure time.Time     // 主模型最近一次失败时间
+	latency     time.Duration // 主模型成功响应耗时（EWMA）
+	switches    int           // 累计切换次数
+	secondaryOK int           // 备用模型累计成功次数
+	secondaryNG int           // 备用模型累计失败次数
+}
+
+// NewFailover 创建故障切换（secondary为备用模型客户端）
+func NewFailover(secondary *Client) *Failover {
+	return &Failover{Secondary: secondary}
+}
+
+func (f *Failover) maxFailures() int {
+	if f.MaxFailures > 0 {
+		return f.MaxFailures
+	}
+	return defaultFailoverMaxFailures
+}
+
+func (f *Failover) failbackAfter() time.Duration {
+	if f.FailbackAfter > 0 {
+		return f.FailbackAfter
+	}
+	return defaultFailbackAfter
+}
+
+// BeginCycle 决策周期开始时调用：切换已超过冷却期时本周期重新尝试主模型（再失败一次立即切回备用）
+func (f *Failover) BeginCycle() {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.active && time.Since(f.since) >= f.failbackAfter() {
+		f.active = false
+		f.probing = true
+		f.failures = f.maxFailures() - 1
+		log.Printf("🔁 备用AI已使用%.0f分钟，本周期重新尝试主模型", time.Since(f.since).Minutes())
+	}
+}
+
+// usingSecondary 当前是否使用备用模型
+func (f *Failover) usingSecondary() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.active
+}
+
+// recordFailure 记录主模型一次失败，返回true表示已切换到备用模型
+func (f *Failover) recordFailure(primary *Client, err error) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures++
+	f.lastError = err.Error()
+	f.lastFailure = time.Now()
+	if !f.active && f.failures >= f.maxFailures() {
+		f.active = true
+		f.probing = false
+		f.since = time.Now()
+		f.switches++
+		log.Printf("🔁 主模型(%s)连续失败%d次，切换到备用模型(%s %s): %v",
+			primary.Provider, f.failures, f.Secondary.Provider, f.Secondary.Model, err)
+	}
+	return f.active
+}
+
+// recordSuccess 记录主模型一次成功响应（耗时超过SlowThreshold时按失败计数）
+func (f *Failover) recordSuccess(primary *Client, elapsed time.Duration) {
+	if f.SlowThreshold > 0 && elapsed > f.SlowThreshold {
+		f.recordFailure(primary, fmt.Errorf("响应过慢(%.1fs > %.0fs)", elapsed.Seconds(), f.SlowThreshold.Seconds()))
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.latency == 0 {
+		f.latency = elapsed
+	} else {
+		f.latency = time.Duration(latencyAlpha*float64(elapsed) + (1-latencyAlpha)*float64(f.latency))
+	}
+	if f.probing {
+		log.Printf("🔁 主模型(%s)恢复正常（耗时%.1fs），切回主模型", primary.Provider, elapsed.Seconds())
+		f.probing = false
+	}
+	f.failures = 0
+}
+
+// call 按健康状态选择主/备用模型调用
+func (f *Failover) call(ctx context.Context, primary *Client, systemPrompt, userPrompt string) (string, error) {
+	if !f.usingSecondary() {
+		start := time.Now()
+		result, err := primary.callWithRetry(ctx, systemPrompt, userPrompt, func(err error) bool {
+			return f.recordFailure(primary, err)
+		})
+		if err == nil {
+			f.recordSuccess(primary, time.Since(start))
+			return result, nil
+		}
+		// 不可重试的错误（密钥无效、请求格式等）或时间预算耗尽，不切换
+		if ctx.Err() != nil || !errs.Retryable(err) || !f.usingSecondary() {
+			return "", err
+		}
+		log.Printf("🔁 本次调用改用备用模型(%s)", f.Secondary.Provider)
+	}
+
+	result, err := f.Secondary.callWithRetry(ctx, systemPrompt, userPrompt, nil)
+	f.mu.Lock()
+	if err == nil {
+		f.secondaryOK++
+	} else {
+		f.secondaryNG++
+	}
+	f.mu.Unlock()
+	if err != nil {
+		return "", fmt.Errorf("备用模型(%s)调用失败: %w", f.Secondary.Provider, err)
+	}
+	return result, nil
+}
+
+// Status 故障切换状态（状态API）
+func (f *Failover) Status() map[string]interface{} {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status := map[string]interface{}{
+		"active":                 f.active,
+		"secondary_provider":     string(f.Secondary.Provider),
+		"secondary_model":        f.Secondary.Model,
+		"primary_failures":       f.failures,
+		"primary_latency_sec":    f.latency.Seconds(),
+		"switches":               f.switches,
+		"secondary_success":      f.secondaryOK,
+		"secondary_failures":     f.secondaryNG,
+		"max_failures":           f.maxFailures(),
+		"failback_after_minutes": f.failbackAfter().Minutes(),
+	}
+	if f.lastError != "" {
+		status["last_primary_error"] = f.lastError
+		status["last_primary_failure"] = f.lastFailure
+	}
+	if f.active {
+		status["since"] = f.since
+		status["failback_at"] = f.since.Add(f.failbackAfter())
+	}
+	return status
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 归因维度
+const (
+	AttributionSignal = "signal" // 开仓推理中提取的信号（MACD、RSI、突破…）
+	AttributionRegime = "regime" // 开仓时的市场体制
+)
+
+// 归因结论的样本与阈值
+const (
+	attributionMinSamples = 5    // 少于5笔不下结论
+	attributionAvoidR     = -0.2 // 期望值低于-0.2R建议回避
+	attributionFavorR     = 0.3  // 期望值高于0.3R可优先考虑
+)
+
+// AttributionStat 单个信号/体制的已实现盈亏归因（保存累计值，便于增量更新和修正）
+type AttributionStat struct {
+	Kind      string  `json:"kind"` // signal/regime/regime_side
+	Name      string  `json:"name"`
+	Count     int     `json:"count"`
+	WinCount  int     `json:"win_count"`
+	LossCount int     `json:"loss_count"`
+	SumReturn float64 `json:"sum_return"` // 收益率%之和
+	SumR      float64 `json:"sum_r"`      // R倍数之和（仅统计有止损距离的交易）
+	RCount    int     `json:"r_count"`
+}
+
+// AvgReturn 平均收益率%
+func (s *AttributionStat) AvgReturn() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.SumReturn / float64(s.Count)
+}
+
+// ExpectancyR 每笔期望值（R倍数），没有止损距离数据时返回false
+func (s *AttributionStat) ExpectancyR() (float64, bool) {
+	if s.RCount == 0 {
+		return 0, false
+	}
+	return s.SumR / float64(s.RCount), true
+}
+
+// findOpenFor 查找平仓记录对应的开仓记录（RecentTrades中该平仓之前最近的同币种同方向开仓）
+func (m *Manager) findOpenFor(closeIdx int, symbol, side string) *TradeEntry {
+	for i := closeIdx - 1; i >= 0; i-- {
+		trade := &m.memory.RecentTrades[i]
+		if trade.Action == "open" && trade.Symbol == symbol && trade.Side == side {
+			return trade
+		}
+	}
+	return nil
+}
+
+// attribute 把一笔平仓结果计入（sign=1）或移出（sign=-1）开仓信号和体制的归因统计
+// ⚠️ 调用者需持有锁
+func (m *Manager) attribute(open *TradeEntry, closed *TradeEntry, sign int) {
+	if open == nil || closed.Result == "" {
+		return
+	}
+	if m.memory.Attribution == nil {
+		m.memory.Attribution = make(map[string]*AttributionStat)
+	}
+
+	keys := make([][2]string, 0, len(open.Signals)+2)
+	for _, signal := range open.Signals {
+		if isResultKeyword(signal) {
+			continue
+		}
+		keys = append(keys, [2]string{AttributionSignal, signal})
+	}
+	if open.MarketRegime != "" && open.MarketRegime != "unknown" {
+		keys = append(keys, [2]string{AttributionRegime, open.MarketRegime})
+	}
+	if open.QuantRegime != "" && open.Side != "" {
+		keys = append(keys, [2]string{AttributionRegimeSide, open.QuantRegime + "/" + open.Side})
+	}
+
+	for _, k := range keys {
+		key := k[0] + ":" + k[1]
+		stat, ok := m.memory.Attribution[key]
+		if !ok {
+			stat = &AttributionStat{Kind: k[0], Name: k[1]}
+			m.memory.Attribution[key] = stat
+		}
+
+		stat.Count += sign
+		stat.SumReturn += float64(sign) * closed.ReturnPct
+		switch closed.Result {
+		case "win":
+			stat.WinCount += sign
+		case "loss":
+			stat.LossCount += sign
+		}
+		if open.RiskPct > 0 {
+			stat.SumR += float64(sign) * closed.ReturnPct / open.RiskPct
+			stat.RCount += sign
+		}
+
+		if stat.Count <= 0 {
+			delete(m.memory.Attribution, key)
+		}
+	}
+}
+
+// GetAttribution 获取盈亏归因表（按样本量从多到少）
+func (m *Manager) GetAttribution() []AttributionStat {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := make([]AttributionStat, 0, len(m.memory.Attribution))
+	for _, stat := range m.memory.Attribution {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// formatAttribution 格式化盈亏归因表（样本≥5），给出回避/优先建议
+func formatAttribution(attribution map[string]*AttributionStat) string {
+	stats := make([]*AttributionStat, 0, len(attribution))
+	for _, stat := range attribution {
+		// 体制+方向单独生成指引（formatRegimeGuidance）
+		if stat.Count >= attributionMinSamples && stat.Kind != AttributionRegimeSide {
+			stats = append(stats, stat)
+		}
+	}
+	if len(stats) == 0 {
+		return ""
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Kind != stats[j].Kind {
+			return stats[i].Kind < stats[j].Kind
+		}
+		return stats[i].Count > stats[j].Count
+	})
+
+	var sb strings.Builder
+	sb.WriteString("### 💰 开仓信号盈亏归因（已实现，样本≥5）\n\n")
+	sb.WriteString("| 类型 | 信号/体制 | 样本 | 胜率 | 平均收益 | 期望值 | 建议 |\n")
+	sb.WriteString("|------|-----------|------|------|----------|--------|------|\n")
+	for _, stat := range stats {
+		kind := "信号"
+		if stat.Kind == AttributionRegime {
+			kind = "体制"
+		}
+
+		expectancy, advice := "-", ""
+		if r, ok := stat.ExpectancyR(); ok {
+			expectancy = fmt.Sprintf("%+.2fR", r)
+			if stat.RCount >= attributionMinSamples {
+				if r < attributionAvoidR {
+					advice = "❌ 回避"
+				} else if r > attributionFavorR {
+					advice = "✅ 优先"
+				}
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf("| %s | %s | %d | %.0f%% | %+.2f%% | %s | %s |\n",
+			kind, stat.Name, stat.Count, float64(stat.WinCount)/float64(stat.Count)*100,
+			stat.AvgReturn(), expectancy, advice))
+	}
+	sb.WriteString("\n期望值=平均每笔盈亏/开仓时止损距离（1R）。标记\"回避\"的信号在历史上长期亏损，除非有其他强证据，不要仅凭它开仓。\n\n")
+	return sb.String()
+}
@@ -14,6 +14,32 @@ type Manager struct {
 	filepath string
 	memory   *SimpleMemory
 	mu       sync.RWMutex
+
+	breakEvenPct float64 // 收益率在±该范围内记为保本（0=默认，按账户级别设置）
+}
+
+// DefaultBreakEvenPct 默认保本区间：亏损不超过0.1%记为保本
+const DefaultBreakEvenPct = 0.1
+
+// SetBreakEvenPct 设置保本区间（按账户级别调整，<=0恢复默认）
+func (m *Manager) SetBreakEvenPct(pct float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.breakEvenPct = pct
+}
+
+// BreakEvenPct 当前保本区间（收益率%）
+func (m *Manager) BreakEvenPct() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.breakEvenLocked()
+}
+
+func (m *Manager) breakEvenLocked() float64 {
+	if m.breakEvenPct > 0 {
+		return m.breakEvenPct
+	}
+	return DefaultBreakEvenPct
 }
 
 // NewManager 创建或加载记忆管理器
@@ -82,6 +108,23 @@ func (m *Manager) Load() error {
 	return json.Unmarshal(data, m.memory)
 }
 
+// Verify 校验记忆文件完整性（重新读取并解析磁盘上的文件，不影响内存中的记忆）
+func (m *Manager) Verify() error {
+	data, err := os.ReadFile(m.filepath)
+	if err != nil {
+		return fmt.Errorf("读取记忆文件失败: %w", err)
+	}
+
+	var mem SimpleMemory
+	if err := json.Unmarshal(data, &mem); err != nil {
+		return fmt.Errorf("记忆文件损坏: %w", err)
+	}
+	if mem.TraderID == "" {
+		return fmt.Errorf("记忆文件缺少trader_id")
+	}
+	return nil
+}
+
 // Save 保存记忆到文件
 func (m *Manager) Save() error {
 	m.mu.RLock()
@@ -118,6 +161,12 @@ func (m *Manager) AddTrade(entry TradeEntry) error {
 		m.memory.RecentTrades = m.memory.RecentTrades[1:]
 	}
 
+	// 💰 平仓结果归因到对应开仓的信号和市场体制
+	if entry.Action == "close" {
+		last := len(m.memory.RecentTrades) - 1
+		m.attribute(m.findOpenFor(last, entry.Symbol, entry.Side), &m.memory.RecentTrades[last], 1)
+	}
+
 	m.memory.TotalTrades++
 	m.memory.UpdatedAt = time.Now()
 
@@ -141,8 +190,60 @@ func (m *Manager) AddTrade(entry TradeEntry) error {
 	return m.Save()
 }
 
-// GetContextPrompt 生成上下文提示（供AI决策时使用）
-func (m *Manager) GetContextPrompt() string {
+// CorrectTradeResult 按交易所对账结果修正平仓记录的收益率（匹配平仓时间前后10分钟内的同币种同方向平仓记录）
+// 返回是否找到并修正了记录
+func (m *Manager) CorrectTradeResult(symbol, side string, closeTime time.Time, returnPct float64) (bool, error) {
+	m.mu.Lock()
+
+	corrected := false
+	for i := len(m.memory.RecentTrades) - 1; i >= 0; i-- {
+		trade := &m.memory.RecentTrades[i]
+		if trade.Action != "close" || trade.Symbol != symbol || trade.Side != side {
+			continue
+		}
+		diff := trade.Timestamp.Sub(closeTime)
+		if diff < -10*time.Minute || diff > 10*time.Minute {
+			continue
+		}
+
+		// 💰 先移出旧结果的归因，修正后重新计入
+		open := m.findOpenFor(i, symbol, side)
+		m.attribute(open, trade, -1)
+
+		trade.ReturnPct = returnPct
+		if returnPct > 0 {
+			trade.Result = "win"
+		} else if returnPct < -m.breakEvenLocked() {
+			trade.Result = "loss"
+		} else {
+			trade.Result = "break_even"
+		}
+		trade.Reconciled = true
+		m.attribute(open, trade, 1)
+		corrected = true
+		break
+	}
+
+	if !corrected {
+		m.mu.Unlock()
+		return false, nil
+	}
+
+	m.memory.UpdatedAt = time.Now()
+	if m.memory.TotalTrades >= 10 {
+		if err := m.UpdateLearningSummary(); err != nil {
+			fmt.Printf("⚠️  更新学习总结失败: %v\n", err)
+		}
+	}
+
+	// 与AddTrade一致：Save内部需要获取锁，先释放
+	m.mu.Unlock()
+
+	return true, m.Save()
+}
+
+// GetContextPrompt 生成上下文提示（供AI决策时使用），currentRegime为当前量化体制（A1/A2/B/C，未知传空）
+func (m *Manager) GetContextPrompt(currentRegime string) string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -216,6 +317,16 @@ func (m *Manager) GetContextPrompt() string {
 		prompt += formatLearningSummary(m.memory.LearningSummary)
 	}
 
+	// 💰 开仓信号盈亏归因（累计统计，样本足够时才显示）
+	if table := formatAttribution(m.memory.Attribution); table != "" {
+		prompt += "\n" + table
+	}
+
+	// 🧭 分体制交易指引（按开仓时的量化体制和方向统计）
+	if guidance := formatRegimeGuidance(m.memory.Attribution, currentRegime); guidance != "" {
+		prompt += "\n" + guidance
+	}
+
 	return prompt
 }
 
@@ -389,4 +500,3 @@ func formatLearningSummary(summary *LearningSummary) string {
 
 	return result
 }
-
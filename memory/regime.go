@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AttributionRegimeSide 按开仓时的量化体制（A1/A2/B/C）和方向归因，名称形如 "B/short"
+const AttributionRegimeSide = "regime_side"
+
+// regimeNames 量化体制的中文名
+var regimeNames = map[string]string{
+	"A1": "(A1)上升趋势",
+	"A2": "(A2)下降趋势",
+	"B":  "(B)宽幅震荡",
+	"C":  "(C)窄幅盘整",
+}
+
+// regimeStrategy 体制+方向对应的交易类型
+func regimeStrategy(regime, side string) string {
+	switch {
+	case (regime == "A1" && side == "long") || (regime == "A2" && side == "short"):
+		return "顺势"
+	case regime == "A1" || regime == "A2":
+		return "逆势"
+	case regime == "B":
+		return "均值回归"
+	default:
+		return "盘整期"
+	}
+}
+
+// sideName 方向的中文名
+func sideName(side string) string {
+	if side == "short" {
+		return "做空"
+	}
+	return "做多"
+}
+
+// formatRegimeGuidance 🧭 按开仓时量化体制统计的已实现结果（样本≥5）生成指引，当前体制排在最前
+// 体制由Go按BTC 4h数据计算，结果来自实际平仓，不依赖AI对自身表现的描述
+func formatRegimeGuidance(attribution map[string]*AttributionStat, current string) string {
+	stats := make([]*AttributionStat, 0)
+	for _, stat := range attribution {
+		if stat.Kind == AttributionRegimeSide && stat.Count >= attributionMinSamples {
+			stats = append(stats, stat)
+		}
+	}
+	if len(stats) == 0 {
+		return ""
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		ci := strings.HasPrefix(stats[i].Name, current+"/")
+		cj := strings.HasPrefix(stats[j].Name, current+"/")
+		if ci != cj {
+			return ci
+		}
+		return stats[i].Name < stats[j].Name
+	})
+
+	var lines []string
+	for _, stat := range stats {
+		regime, side, _ := strings.Cut(stat.Name, "/")
+		r, ok := stat.ExpectancyR()
+		if !ok || stat.RCount < attributionMinSamples {
+			continue
+		}
+
+		label := fmt.Sprintf("%s中的%s%s", regimeNames[regime], regimeStrategy(regime, side), sideName(side))
+		record := fmt.Sprintf("%d笔，胜率%.0f%%，期望值%+.2fR", stat.Count, float64(stat.WinCount)/float64(stat.Count)*100, r)
+		marker := ""
+		if current != "" && regime == current {
+			marker = "👉 "
+		}
+		switch {
+		case r < attributionAvoidR:
+			lines = append(lines, fmt.Sprintf("- %s❌ 你在%s交易持续亏损（%s）：跳过这类交易", marker, label, record))
+		case r > attributionFavorR:
+			lines = append(lines, fmt.Sprintf("- %s✅ 你在%s交易表现良好（%s）：可以优先考虑", marker, label, record))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### 🧭 分体制交易指引（按实际平仓结果统计，样本≥5）\n\n")
+	if name, ok := regimeNames[current]; ok {
+		sb.WriteString(fmt.Sprintf("当前体制（BTC 4h量化计算）: %s，标记👉的条目与当前体制直接相关。\n\n", name))
+	}
+	sb.WriteString(strings.Join(lines, "\n"))
+	sb.WriteString("\n\n")
+	return sb.String()
+}
@@ -4,12 +4,12 @@ import "time"
 
 // SimpleMemory Sprint 1版本：工作记忆 + 基础记录
 type SimpleMemory struct {
-	Version      string       `json:"version"`
-	TraderID     string       `json:"trader_id"`
-	CreatedAt    time.Time    `json:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at"`
-	TotalTrades  int          `json:"total_trades"`
-	Status       string       `json:"status"` // learning/mature
+	Version     string    `json:"version"`
+	TraderID    string    `json:"trader_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	TotalTrades int       `json:"total_trades"`
+	Status      string    `json:"status"` // learning/mature
 
 	// Working Memory: 最近20笔交易
 	RecentTrades []TradeEntry `json:"recent_trades"`
@@ -19,6 +19,9 @@ type SimpleMemory struct {
 
 	// 🆕 自适应学习模块
 	LearningSummary *LearningSummary `json:"learning_summary,omitempty"`
+
+	// 🆕 盈亏归因：按开仓信号/市场体制/量化体制+方向累计（不受RecentTrades窗口限制）
+	Attribution map[string]*AttributionStat `json:"attribution,omitempty"`
 }
 
 // 🆕 LearningSummary 学习总结（自动生成）
@@ -40,13 +43,13 @@ type LearningSummary struct {
 
 // 🆕 SignalStat 信号统计
 type SignalStat struct {
-	SignalName  string  `json:"signal_name"`
-	TotalCount  int     `json:"total_count"`
-	WinCount    int     `json:"win_count"`
-	LossCount   int     `json:"loss_count"`
-	WinRate     float64 `json:"win_rate"`
-	AvgReturn   float64 `json:"avg_return"`
-	LastUsed    time.Time `json:"last_used"`
+	SignalName string    `json:"signal_name"`
+	TotalCount int       `json:"total_count"`
+	WinCount   int       `json:"win_count"`
+	LossCount  int       `json:"loss_count"`
+	WinRate    float64   `json:"win_rate"`
+	AvgReturn  float64   `json:"avg_return"`
+	LastUsed   time.Time `json:"last_used"`
 }
 
 // TradeEntry 单笔交易记录
@@ -56,8 +59,9 @@ type TradeEntry struct {
 	Timestamp time.Time `json:"timestamp"`
 
 	// 市场环境
-	MarketRegime string `json:"market_regime"` // accumulation/markup/distribution/markdown
-	RegimeStage  string `json:"regime_stage"`  // early/mid/late
+	MarketRegime string `json:"market_regime"`          // accumulation/markup/distribution/markdown
+	RegimeStage  string `json:"regime_stage"`           // early/mid/late
+	QuantRegime  string `json:"quant_regime,omitempty"` // 🆕 量化体制 A1/A2/B/C（Go按BTC 4h数据计算）
 
 	// 决策信息
 	Action    string   `json:"action"`    // open/close/hold
@@ -76,6 +80,7 @@ type TradeEntry struct {
 	ExitPrice   float64 `json:"exit_price,omitempty"`
 	PositionPct float64 `json:"position_pct"` // 仓位占比%
 	Leverage    int     `json:"leverage,omitempty"`
+	RiskPct     float64 `json:"risk_pct,omitempty"` // 🆕 开仓时止损距离（与ReturnPct同口径：价格距离%×杠杆，即1R）
 
 	// 🆕 限价单信息
 	IsLimitOrder bool    `json:"is_limit_order,omitempty"` // 是否是限价单
@@ -89,6 +94,7 @@ type TradeEntry struct {
 	HoldMinutes int     `json:"hold_minutes,omitempty"` // 持仓时长
 	ReturnPct   float64 `json:"return_pct"`             // 收益率%
 	Result      string  `json:"result"`                 // win/loss/break_even
+	Reconciled  bool    `json:"reconciled,omitempty"`   // 🆕 收益率已按交易所流水修正
 }
 
 // 🆕 MarketSnapshot 市场数值快照（用于精准复盘）
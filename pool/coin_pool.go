@@ -43,7 +43,7 @@ var coinPoolConfig = CoinPoolConfig{
 type CoinPoolCache struct {
 	Coins      []CoinInfo `json:"coins"`
 	FetchedAt  time.Time  `json:"fetched_at"`
-	SourceType string     `json:"source_type"` // "api" or "cache"
+	SourceType string     `json:"source_type"` // 数据源名称: "remote" / "local"
 }
 
 // CoinInfo 币种信息
@@ -99,40 +99,34 @@ func GetCoinPool() ([]CoinInfo, error) {
 		return convertSymbolsToCoins(defaultMainstreamCoins), nil
 	}
 
-	// 检查API URL是否配置
-	if strings.TrimSpace(coinPoolConfig.APIURL) == "" {
+	// 按数据源链依次尝试（远程API / 本地排名 / 默认币种）
+	providers := activeProviders()
+	if len(providers) == 0 {
 		log.Printf("⚠️  未配置币种池API URL，使用默认主流币种列表")
 		return convertSymbolsToCoins(defaultMainstreamCoins), nil
 	}
 
-	maxRetries := 3
 	var lastErr error
-
-	// 尝试从API获取
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if attempt > 1 {
-			log.Printf("⚠️  第%d次重试获取币种池（共%d次）...", attempt, maxRetries)
-			time.Sleep(2 * time.Second) // 重试前等待2秒
-		}
-
-		coins, err := fetchCoinPool()
-		if err == nil {
-			if attempt > 1 {
-				log.Printf("✓ 第%d次重试成功", attempt)
-			}
-			// 成功获取后保存到缓存
-			if err := saveCoinPoolCache(coins); err != nil {
-				log.Printf("⚠️  保存币种池缓存失败: %v", err)
+	for _, provider := range providers {
+		coins, err := provider.FetchCoins()
+		if err == nil && len(coins) > 0 {
+			// 默认币种无需缓存
+			if provider.Name() != "default" {
+				if err := saveCoinPoolCache(coins, provider.Name()); err != nil {
+					log.Printf("⚠️  保存币种池缓存失败: %v", err)
+				}
 			}
 			return coins, nil
 		}
-
+		if err == nil {
+			err = fmt.Errorf("币种列表为空")
+		}
 		lastErr = err
-		log.Printf("❌ 第%d次请求失败: %v", attempt, err)
+		log.Printf("⚠️  币种池数据源[%s]失败: %v，尝试下一个", provider.Name(), err)
 	}
 
 	// API获取失败，尝试使用缓存
-	log.Printf("⚠️  API请求全部失败，尝试使用历史缓存数据...")
+	log.Printf("⚠️  币种池数据源全部失败，尝试使用历史缓存数据...")
 	cachedCoins, err := loadCoinPoolCache()
 	if err == nil {
 		log.Printf("✓ 使用历史缓存数据（共%d个币种）", len(cachedCoins))
@@ -192,7 +186,7 @@ func fetchCoinPool() ([]CoinInfo, error) {
 }
 
 // saveCoinPoolCache 保存币种池到缓存文件
-func saveCoinPoolCache(coins []CoinInfo) error {
+func saveCoinPoolCache(coins []CoinInfo, sourceType string) error {
 	// 确保缓存目录存在
 	if err := os.MkdirAll(coinPoolConfig.CacheDir, 0755); err != nil {
 		return fmt.Errorf("创建缓存目录失败: %w", err)
@@ -201,7 +195,7 @@ func saveCoinPoolCache(coins []CoinInfo) error {
 	cache := CoinPoolCache{
 		Coins:      coins,
 		FetchedAt:  time.Now(),
-		SourceType: "api",
+		SourceType: sourceType,
 	}
 
 	data, err := json.MarshalIndent(cache, "", "  ")
@@ -247,6 +241,11 @@ func loadCoinPoolCache() ([]CoinInfo, error) {
 			cacheAge.Minutes())
 	}
 
+	// IsAvailable 不参与序列化，从缓存恢复时需重新标记
+	for i := range cache.Coins {
+		cache.Coins[i].IsAvailable = true
+	}
+
 	return cache.Coins, nil
 }
 
@@ -324,8 +323,8 @@ func normalizeSymbol(symbol string) string {
 	// 转为大写
 	symbol = toUpper(symbol)
 
-	// 确保以USDT结尾
-	if !endsWith(symbol, "USDT") {
+	// 确保以USDT结尾（USDC交易对保持不变）
+	if !endsWith(symbol, "USDT") && !endsWith(symbol, "USDC") {
 		symbol = symbol + "USDT"
 	}
 
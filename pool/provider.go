@@ -0,0 +1,276 @@
+package pool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CoinPoolProvider 候选币种池数据源
+type CoinPoolProvider interface {
+	Name() string
+	FetchCoins() ([]CoinInfo, error)
+}
+
+// coinPoolProviders 已配置的数据源（按顺序尝试，nil表示使用默认链）
+var coinPoolProviders []CoinPoolProvider
+
+// SetCoinPoolProviders 按名称配置币种池数据源链（按顺序尝试，前一个失败时回退到下一个）
+// 支持: "remote"（CoinPoolAPIURL）、"local"（币安USDT永续按成交额/OI变化本地排名）、"default"（默认币种列表）
+func SetCoinPoolProviders(names []string) error {
+	var providers []CoinPoolProvider
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "remote":
+			providers = append(providers, &RemoteCoinPoolProvider{})
+		case "local":
+			providers = append(providers, NewLocalRankingProvider())
+		case "default":
+			providers = append(providers, &DefaultCoinPoolProvider{})
+		default:
+			return fmt.Errorf("未知的币种池数据源: %s", name)
+		}
+	}
+	coinPoolProviders = providers
+	if len(providers) > 0 {
+		log.Printf("✓ 币种池数据源链: %v", names)
+	}
+	return nil
+}
+
+// activeProviders 返回当前生效的数据源链
+// 未显式配置时保持原有行为：配置了API URL则只用远程API，否则直接使用默认币种
+func activeProviders() []CoinPoolProvider {
+	if len(coinPoolProviders) > 0 {
+		return coinPoolProviders
+	}
+	if strings.TrimSpace(coinPoolConfig.APIURL) != "" {
+		return []CoinPoolProvider{&RemoteCoinPoolProvider{}}
+	}
+	return nil
+}
+
+// ========== 远程API数据源（AI500） ==========
+
+// RemoteCoinPoolProvider 远程币种池API（使用 SetCoinPoolAPI 配置的地址）
+type RemoteCoinPoolProvider struct{}
+
+// Name 数据源名称
+func (p *RemoteCoinPoolProvider) Name() string { return "remote" }
+
+// FetchCoins 请求远程API（最多重试3次）
+func (p *RemoteCoinPoolProvider) FetchCoins() ([]CoinInfo, error) {
+	if strings.TrimSpace(coinPoolConfig.APIURL) == "" {
+		return nil, fmt.Errorf("未配置币种池API URL")
+	}
+
+	maxRetries := 3
+	var lastErr error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		if attempt > 1 {
+			log.Printf("⚠️  第%d次重试获取币种池（共%d次）...", attempt, maxRetries)
+			time.Sleep(2 * time.Second) // 重试前等待2秒
+		}
+
+		coins, err := fetchCoinPool()
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("✓ 第%d次重试成功", attempt)
+			}
+			return coins, nil
+		}
+
+		lastErr = err
+		log.Printf("❌ 第%d次请求失败: %v", attempt, err)
+	}
+	return nil, lastErr
+}
+
+// ========== 默认币种数据源 ==========
+
+// DefaultCoinPoolProvider 默认主流币种列表
+type DefaultCoinPoolProvider struct{}
+
+// Name 数据源名称
+func (p *DefaultCoinPoolProvider) Name() string { return "default" }
+
+// FetchCoins 返回默认币种
+func (p *DefaultCoinPoolProvider) FetchCoins() ([]CoinInfo, error) {
+	return convertSymbolsToCoins(defaultMainstreamCoins), nil
+}
+
+// ========== 本地排名数据源（币安USDT永续） ==========
+
+// LocalRankingProvider 本地排名：按24h成交额和OI变化给币安USDT永续打分，不依赖外部服务
+type LocalRankingProvider struct {
+	BaseURL        string        // 币安合约公共API地址
+	Limit          int           // 输出币种数量
+	CandidateSize  int           // 按成交额预选的候选数量（再查询OI变化）
+	MinQuoteVolume float64       // 最低24h成交额（USDT）
+	CacheTTL       time.Duration // 结果缓存时间（一个周期内多次调用只请求一次）
+	Timeout        time.Duration
+
+	mu       sync.Mutex
+	cached   []CoinInfo
+	cachedAt time.Time
+}
+
+// NewLocalRankingProvider 创建本地排名数据源
+func NewLocalRankingProvider() *LocalRankingProvider {
+	return &LocalRankingProvider{
+		BaseURL:        "https://fapi.binance.com",
+		Limit:          30,
+		CandidateSize:  60,
+		MinQuoteVolume: 50_000_000, // 5000万USDT
+		CacheTTL:       5 * time.Minute,
+		Timeout:        15 * time.Second,
+	}
+}
+
+// Name 数据源名称
+func (p *LocalRankingProvider) Name() string { return "local" }
+
+// binanceTicker24h /fapi/v1/ticker/24hr 返回结构（只取需要的字段）
+type binanceTicker24h struct {
+	Symbol             string `json:"symbol"`
+	QuoteVolume        string `json:"quoteVolume"`
+	PriceChangePercent string `json:"priceChangePercent"`
+	LastPrice          string `json:"lastPrice"`
+}
+
+// binanceOIHist /futures/data/openInterestHist 返回结构
+type binanceOIHist struct {
+	SumOpenInterestValue string `json:"sumOpenInterestValue"`
+	Timestamp            int64  `json:"timestamp"`
+}
+
+// FetchCoins 拉取全市场24h行情，按成交额预选后结合24h OI变化打分排序
+func (p *LocalRankingProvider) FetchCoins() ([]CoinInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.cached) > 0 && time.Since(p.cachedAt) < p.CacheTTL {
+		return p.cached, nil
+	}
+
+	log.Printf("🔄 正在本地计算币种池排名（币安USDT永续）...")
+	client := &http.Client{Timeout: p.Timeout}
+
+	var tickers []binanceTicker24h
+	if err := getJSON(client, p.BaseURL+"/fapi/v1/ticker/24hr", &tickers); err != nil {
+		return nil, fmt.Errorf("获取24h行情失败: %w", err)
+	}
+
+	type candidate struct {
+		symbol      string
+		quoteVolume float64
+		priceChange float64
+		price       float64
+		oiChange    float64
+	}
+	var candidates []*candidate
+	for _, t := range tickers {
+		// 只要USDT永续（交割合约带下划线日期后缀）
+		if !strings.HasSuffix(t.Symbol, "USDT") || strings.Contains(t.Symbol, "_") {
+			continue
+		}
+		quoteVolume, _ := strconv.ParseFloat(t.QuoteVolume, 64)
+		if quoteVolume < p.MinQuoteVolume {
+			continue
+		}
+		priceChange, _ := strconv.ParseFloat(t.PriceChangePercent, 64)
+		price, _ := strconv.ParseFloat(t.LastPrice, 64)
+		candidates = append(candidates, &candidate{
+			symbol:      t.Symbol,
+			quoteVolume: quoteVolume,
+			priceChange: priceChange,
+			price:       price,
+		})
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("没有满足成交额要求的USDT永续合约")
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].quoteVolume > candidates[j].quoteVolume
+	})
+	if len(candidates) > p.CandidateSize {
+		candidates = candidates[:p.CandidateSize]
+	}
+
+	// 并发查询24h OI变化（限制并发，避免触发限流）
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 8)
+	for _, c := range candidates {
+		wg.Add(1)
+		go func(c *candidate) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var hist []binanceOIHist
+			url := fmt.Sprintf("%s/futures/data/openInterestHist?symbol=%s&period=1h&limit=25", p.BaseURL, c.symbol)
+			if err := getJSON(client, url, &hist); err != nil || len(hist) < 2 {
+				return
+			}
+			first, _ := strconv.ParseFloat(hist[0].SumOpenInterestValue, 64)
+			last, _ := strconv.ParseFloat(hist[len(hist)-1].SumOpenInterestValue, 64)
+			if first > 0 {
+				c.oiChange = (last - first) / first * 100
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	// 评分：成交额排名（60%）+ OI变化（40%，±20%映射到0-100）
+	n := float64(len(candidates))
+	coins := make([]CoinInfo, 0, len(candidates))
+	for rank, c := range candidates {
+		volumeScore := 100 * (1 - float64(rank)/n)
+		oiScore := math.Max(0, math.Min(100, 50+c.oiChange*2.5))
+		score := volumeScore*0.6 + oiScore*0.4
+		coins = append(coins, CoinInfo{
+			Pair:            c.symbol,
+			Score:           score,
+			LastScore:       score,
+			StartPrice:      c.price,
+			IncreasePercent: c.priceChange,
+			IsAvailable:     true,
+		})
+	}
+	sort.Slice(coins, func(i, j int) bool { return coins[i].Score > coins[j].Score })
+	if len(coins) > p.Limit {
+		coins = coins[:p.Limit]
+	}
+
+	log.Printf("✓ 本地排名完成：%d个币种（预选%d个）", len(coins), len(candidates))
+	p.cached = coins
+	p.cachedAt = time.Now()
+	return coins, nil
+}
+
+// getJSON GET请求并解析JSON
+func getJSON(client *http.Client, url string, out interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,258 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"nofx/errs"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 交易所限流预算名称
+const (
+	Binance      = "binance"       // 币安合约 fapi（IP权重2400/分钟）
+	BinanceCoinM = "binance_coinm" // 币安币本位合约 dapi（IP权重2400/分钟）
+	BinanceSpot  = "binance_spot"  // 币安现货 api（IP权重6000/分钟）
+	Hyperliquid  = "hyperliquid"   // Hyperliquid（IP权重1200/分钟）
+	Aster        = "aster"         // Aster（与币安合约API一致，2400/分钟）
+	Bybit        = "bybit"         // Bybit v5（IP 600次/5秒）
+)
+
+const (
+	defaultLowWaterPct = 0.2              // 剩余权重低于20%时拒绝非关键请求
+	defaultBanCooldown = 2 * time.Minute  // 被封禁但未给出解封时间时的冷却时长
+	bybitBanCooldown   = 10 * time.Minute // Bybit IP超限返回403，封禁10分钟
+)
+
+var (
+	// ErrCooldown 交易所限流封禁冷却中（所有请求直接拒绝，避免延长封禁）
+	ErrCooldown = errs.Wrap(errs.RateLimited, 0, errors.New("交易所限流冷却中"))
+	// ErrBudgetLow 剩余权重不足（仅拒绝非关键请求）
+	ErrBudgetLow = errs.Wrap(errs.RateLimited, 0, errors.New("限流预算不足"))
+)
+
+// Budget 单个交易所的限流预算
+// 本地按固定窗口累计请求权重，并用交易所返回的实际已用权重校正；
+// 触发限流/封禁（429、418、-1003）后进入冷却，冷却期间所有请求直接失败
+type Budget struct {
+	name     string
+	limit    int
+	window   time.Duration
+	lowWater float64
+
+	mu            sync.Mutex
+	used          int
+	windowStart   time.Time
+	cooldownUntil time.Time
+	cooldownCause string
+	rejected      int64 // 被短路的非关键请求数
+}
+
+var (
+	budgetsMu sync.Mutex
+	budgets   = map[string]*Budget{
+		Binance:      newBudget(Binance, 2400, time.Minute),
+		BinanceSpot:  newBudget(BinanceSpot, 6000, time.Minute),
+		BinanceCoinM: newBudget(BinanceCoinM, 2400, time.Minute),
+		Hyperliquid:  newBudget(Hyperliquid, 1200, time.Minute),
+		Aster:        newBudget(Aster, 2400, time.Minute),
+		Bybit:        newBudget(Bybit, 600, 5*time.Second),
+	}
+)
+
+func newBudget(name string, limit int, window time.Duration) *Budget {
+	return &Budget{name: name, limit: limit, window: window, lowWater: defaultLowWaterPct}
+}
+
+// For 获取指定交易所的限流预算（未知名称时按默认2400/分钟创建）
+func For(name string) *Budget {
+	budgetsMu.Lock()
+	defer budgetsMu.Unlock()
+
+	b, ok := budgets[name]
+	if !ok {
+		b = newBudget(name, 2400, time.Minute)
+		budgets[name] = b
+	}
+	return b
+}
+
+// ForHost 根据请求域名匹配限流预算（不认识的域名返回nil，不做限制）
+func ForHost(host string) *Budget {
+	switch {
+	case strings.HasPrefix(host, "fapi.binance.com"), strings.HasPrefix(host, "testnet.binancefuture.com"):
+		return For(Binance)
+	case strings.HasPrefix(host, "dapi.binance.com"):
+		return For(BinanceCoinM)
+	case strings.HasPrefix(host, "api.binance.com"), strings.HasPrefix(host, "testnet.binance.vision"):
+		return For(BinanceSpot)
+	case strings.Contains(host, "hyperliquid"):
+		return For(Hyperliquid)
+	case strings.Contains(host, "asterdex.com"):
+		return For(Aster)
+	case strings.Contains(host, "bybit.com"):
+		return For(Bybit)
+	}
+	return nil
+}
+
+// rollWindow 窗口到期时重置计数（调用方持有锁）
+func (b *Budget) rollWindow(now time.Time) {
+	start := now.Truncate(b.window)
+	if !start.Equal(b.windowStart) {
+		b.windowStart = start
+		b.used = 0
+	}
+}
+
+// Acquire 申请权重：冷却期间一律拒绝；剩余不足时只拒绝非关键请求（关键请求如下单/平仓仍放行）
+func (b *Budget) Acquire(weight int, critical bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(b.cooldownUntil) {
+		return fmt.Errorf("%w: %s 剩余%s（%s）", ErrCooldown, b.name,
+			b.cooldownUntil.Sub(now).Round(time.Second), b.cooldownCause)
+	}
+
+	b.rollWindow(now)
+	if !critical && b.remainingPct() < b.lowWater {
+		b.rejected++
+		return fmt.Errorf("%w: %s 已用%d/%d，跳过非关键请求", ErrBudgetLow, b.name, b.used, b.limit)
+	}
+
+	b.used += weight
+	return nil
+}
+
+// AllowNonCritical 当前是否允许非关键请求（如山寨币扫描）
+func (b *Budget) AllowNonCritical() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(b.cooldownUntil) {
+		return false
+	}
+	b.rollWindow(now)
+	return b.remainingPct() >= b.lowWater
+}
+
+func (b *Budget) remainingPct() float64 {
+	if b.limit <= 0 {
+		return 1
+	}
+	return float64(b.limit-b.used) / float64(b.limit)
+}
+
+// SyncUsed 用交易所返回的已用权重校正本地计数（如币安 X-MBX-USED-WEIGHT-1M）
+func (b *Budget) SyncUsed(used int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rollWindow(time.Now())
+	b.used = used
+}
+
+// Cooldown 进入冷却状态（已在更长的冷却中时不缩短）
+func (b *Budget) Cooldown(d time.Duration, cause string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.Before(b.cooldownUntil) {
+		return
+	}
+	b.cooldownUntil = until
+	b.cooldownCause = cause
+	log.Printf("🚧 [%s] 触发限流保护，暂停请求%s: %s", b.name, d.Round(time.Second), cause)
+}
+
+// InCooldown 是否处于冷却中
+func (b *Budget) InCooldown() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Now().Before(b.cooldownUntil)
+}
+
+// bannedUntilRe 币安封禁信息中的解封时间，如 "IP banned until 1700000000000"
+var bannedUntilRe = regexp.MustCompile(`banned until (\d{13})`)
+
+// ObserveError 检查交易所错误，遇到限流/封禁时进入冷却（返回是否为限流错误）
+func (b *Budget) ObserveError(err error) bool {
+	if err == nil || errors.Is(err, ErrCooldown) || errors.Is(err, ErrBudgetLow) {
+		return false // 本地短路的请求，不是交易所返回的限流
+	}
+	msg := err.Error()
+	if !errs.Is(err, errs.RateLimited) && !IsRateLimitMessage(msg) {
+		return false
+	}
+	b.Cooldown(banDuration(msg), msg)
+	return true
+}
+
+// IsRateLimitMessage 是否为限流/封禁错误（-1003、HTTP 429/418）
+func IsRateLimitMessage(msg string) bool {
+	return strings.Contains(msg, "-1003") ||
+		strings.Contains(msg, "Too many requests") ||
+		strings.Contains(msg, "Too Many Requests") ||
+		strings.Contains(msg, "status 429") ||
+		strings.Contains(msg, "status 418") ||
+		strings.Contains(msg, "banned until")
+}
+
+// banDuration 从错误信息中解析冷却时长（解析不到时使用默认值）
+func banDuration(msg string) time.Duration {
+	if m := bannedUntilRe.FindStringSubmatch(msg); m != nil {
+		if ms, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+			if d := time.Until(time.UnixMilli(ms)); d > 0 {
+				return d
+			}
+		}
+	}
+	return defaultBanCooldown
+}
+
+// Status 预算状态（用于状态接口）
+func (b *Budget) Status() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.rollWindow(now)
+	status := map[string]interface{}{
+		"name":        b.name,
+		"used":        b.used,
+		"limit":       b.limit,
+		"remaining":   b.limit - b.used,
+		"cooling":     now.Before(b.cooldownUntil),
+		"rejected":    b.rejected,
+		"window_secs": int(b.window.Seconds()),
+	}
+	if now.Before(b.cooldownUntil) {
+		status["cooldown_until"] = b.cooldownUntil.Format(time.RFC3339)
+		status["cooldown_cause"] = b.cooldownCause
+	}
+	return status
+}
+
+// AllStatus 所有交易所的预算状态
+func AllStatus() map[string]interface{} {
+	budgetsMu.Lock()
+	list := make([]*Budget, 0, len(budgets))
+	for _, b := range budgets {
+		list = append(list, b)
+	}
+	budgetsMu.Unlock()
+
+	result := make(map[string]interface{}, len(list))
+	for _, b := range list {
+		result[b.name] = b.Status()
+	}
+	return result
+}
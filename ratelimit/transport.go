@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type nonCriticalKey struct{}
+
+// NonCritical 标记请求为非关键（预算不足时直接跳过，如山寨币扫描）
+func NonCritical(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nonCriticalKey{}, true)
+}
+
+func isNonCritical(ctx context.Context) bool {
+	v, _ := ctx.Value(nonCriticalKey{}).(bool)
+	return v
+}
+
+// Transport 按请求域名套用限流预算的 http.RoundTripper
+// 请求前检查冷却/预算，响应后用已用权重头校正计数，遇到429/418进入冷却
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport 创建限流Transport（base为nil时使用 http.DefaultTransport）
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base}
+}
+
+// NewHTTPClient 创建带限流Transport的HTTP客户端
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: NewTransport(nil)}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	budget := ForHost(req.URL.Host)
+	if budget == nil {
+		return t.Base.RoundTrip(req)
+	}
+
+	if err := budget.Acquire(1, !isNonCritical(req.Context())); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 币安/Aster 返回当前窗口已用权重
+	if used := resp.Header.Get("X-Mbx-Used-Weight-1m"); used != "" {
+		if n, err := strconv.Atoi(used); err == nil {
+			budget.SyncUsed(n)
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		// 读取响应体解析封禁时间，再放回去供调用方解析错误
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		d := banDuration(string(body))
+		if retry := resp.Header.Get("Retry-After"); retry != "" {
+			if secs, err := strconv.Atoi(retry); err == nil && time.Duration(secs)*time.Second > d {
+				d = time.Duration(secs) * time.Second
+			}
+		}
+		budget.Cooldown(d, "HTTP "+strconv.Itoa(resp.StatusCode)+": "+string(body))
+	}
+
+	// Bybit IP超限不返回429，而是403（access too frequent）
+	if resp.StatusCode == http.StatusForbidden && budget.name == Bybit {
+		budget.Cooldown(bybitBanCooldown, "HTTP 403: IP访问过于频繁")
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// scanTailBytes 泄漏扫描只读取每个文件末尾的这么多字节
+const scanTailBytes = 8 << 20
+
+// CheckStartup 🔐 启动检查：明文密钥、配置文件权限、已有日志/决策记录中的密钥泄漏，返回警告（不含密钥值）
+func CheckStartup(configPath string, plaintextFields []string, scanPaths []string) []string {
+	var warnings []string
+
+	if len(plaintextFields) > 0 {
+		warnings = append(warnings, fmt.Sprintf("配置文件中有%d个明文密钥字段（%s），建议改为 \"secret:<name>\" 或 \"env:<VAR>\" 引用",
+			len(plaintextFields), strings.Join(plaintextFields, ", ")))
+		if info, err := os.Stat(configPath); err == nil && info.Mode().Perm()&0077 != 0 {
+			warnings = append(warnings, fmt.Sprintf("配置文件%s包含明文密钥但权限为%v，其他用户可读，建议 chmod 600", configPath, info.Mode().Perm()))
+		}
+	}
+
+	for _, path := range ScanForLeaks(scanPaths) {
+		warnings = append(warnings, fmt.Sprintf("%s 中发现密钥明文（早于脱敏写入），请删除或手动清理该文件", path))
+	}
+	return warnings
+}
+
+// ScanForLeaks 检查文件末尾是否包含已登记的密钥，返回命中的文件
+func ScanForLeaks(paths []string) []string {
+	if Tracked() == 0 {
+		return nil
+	}
+	var leaked []string
+	for _, path := range paths {
+		data, err := readTail(path, scanTailBytes)
+		if err != nil {
+			continue
+		}
+		if string(RedactBytes(data)) != string(data) {
+			leaked = append(leaked, path)
+		}
+	}
+	return leaked
+}
+
+func readTail(path string, n int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > n {
+		if _, err := f.Seek(-n, io.SeekEnd); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(f)
+}
@@ -0,0 +1,27 @@
+package secrets
+
+import (
+	"os"
+	"strings"
+)
+
+// EnvProvider 环境变量提供者：secret:binance_api_key → NOFX_SECRET_BINANCE_API_KEY
+type EnvProvider struct{}
+
+// Name 提供者名称
+func (EnvProvider) Name() string { return "env" }
+
+// Get 读取 NOFX_SECRET_<NAME>（名称转大写，-和.替换为_）
+func (EnvProvider) Get(name string) (string, bool, error) {
+	value, ok := os.LookupEnv(EnvName(name))
+	if !ok || value == "" {
+		return "", false, nil
+	}
+	return value, true, nil
+}
+
+// EnvName 密钥名称对应的环境变量名
+func EnvName(name string) string {
+	name = strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(name))
+	return "NOFX_SECRET_" + name
+}
@@ -0,0 +1,144 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// DefaultFile 默认加密密钥文件
+const DefaultFile = "secrets.enc"
+
+// PassphraseEnv 加密文件口令的环境变量
+const PassphraseEnv = "NOFX_SECRETS_PASSPHRASE"
+
+// 文件格式：magic | salt(16) | nonce(24) | secretbox(JSON map)，密钥由口令经scrypt派生
+var fileMagic = []byte("NOFXSEC1")
+
+const (
+	saltSize  = 16
+	nonceSize = 24
+)
+
+// FileProvider 🔐 加密文件提供者（NaCl secretbox + scrypt）
+type FileProvider struct {
+	path   string
+	values map[string]string
+}
+
+// OpenFileProvider 解密并加载密钥文件
+func OpenFileProvider(path, passphrase string) (*FileProvider, error) {
+	values, err := ReadFile(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &FileProvider{path: path, values: values}, nil
+}
+
+// Name 提供者名称
+func (p *FileProvider) Name() string { return "file:" + p.path }
+
+// Get 查找密钥
+func (p *FileProvider) Get(name string) (string, bool, error) {
+	value, ok := p.values[name]
+	return value, ok && value != "", nil
+}
+
+// Names 文件中的密钥名称（不含值）
+func (p *FileProvider) Names() []string {
+	names := make([]string, 0, len(p.values))
+	for name := range p.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReadFile 解密密钥文件（文件不存在时返回空表）
+func ReadFile(path, passphrase string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("密钥文件%s需要口令，请设置环境变量%s", path, PassphraseEnv)
+	}
+	if len(data) < len(fileMagic)+saltSize+nonceSize+secretbox.Overhead || !bytes.Equal(data[:len(fileMagic)], fileMagic) {
+		return nil, fmt.Errorf("密钥文件%s格式无效", path)
+	}
+	data = data[len(fileMagic):]
+	salt, data := data[:saltSize], data[saltSize:]
+	var nonce [nonceSize]byte
+	copy(nonce[:], data[:nonceSize])
+	data = data[nonceSize:]
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, ok := secretbox.Open(nil, data, &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("解密密钥文件%s失败（口令错误或文件已损坏）", path)
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal(plain, &values); err != nil {
+		return nil, fmt.Errorf("解析密钥文件失败: %w", err)
+	}
+	return values, nil
+}
+
+// WriteFile 加密写入密钥文件（每次写入使用新的salt和nonce，权限0600，原子替换）
+func WriteFile(path, passphrase string, values map[string]string) error {
+	if passphrase == "" {
+		return fmt.Errorf("写入密钥文件需要口令，请设置环境变量%s", PassphraseEnv)
+	}
+	plain, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("序列化密钥失败: %w", err)
+	}
+
+	salt := make([]byte, saltSize)
+	var nonce [nonceSize]byte
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return fmt.Errorf("生成随机数失败: %w", err)
+	}
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return fmt.Errorf("生成随机数失败: %w", err)
+	}
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	out := append([]byte{}, fileMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce[:]...)
+	out = secretbox.Seal(out, plain, &nonce, key)
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, out, 0600); err != nil {
+		return fmt.Errorf("写入密钥文件失败: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func deriveKey(passphrase string, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %w", err)
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// DefaultKeyringService 默认keyring服务名
+const DefaultKeyringService = "nofx"
+
+// KeyringProvider 🔑 系统钥匙串提供者：macOS使用security（Keychain），Linux使用secret-tool（Secret Service）
+// 不引入cgo依赖；工具不存在时视为未找到，继续查找下一个提供者
+type KeyringProvider struct {
+	service string
+}
+
+// NewKeyringProvider 创建keyring提供者
+func NewKeyringProvider(service string) *KeyringProvider {
+	if service == "" {
+		service = DefaultKeyringService
+	}
+	return &KeyringProvider{service: service}
+}
+
+// Name 提供者名称
+func (p *KeyringProvider) Name() string { return "keyring:" + p.service }
+
+// Get 从系统钥匙串读取（条目的account/key为密钥名称）
+func (p *KeyringProvider) Get(name string) (string, bool, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", p.service, "-a", name, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", p.service, "key", name)
+	default:
+		return "", false, nil
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) || errors.Is(err, exec.ErrNotFound) {
+			// 条目不存在（非0退出）或未安装工具
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("读取钥匙串失败: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	value := strings.TrimRight(string(out), "\r\n")
+	return value, value != "", nil
+}
@@ -0,0 +1,204 @@
+// Package secrets 密钥读取与脱敏
+// 配置中的密钥字段可以写成引用而不是明文：
+//
+//	"secret:<name>"  按提供者链（keyring → 加密文件 → 环境变量，可配置）查找
+//	"env:<VAR>"      直接读取环境变量
+//
+// 所有解析出的密钥（包括仍写在配置里的明文）都会登记到脱敏表，日志和决策记录写出前替换为***
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 配置值前缀
+const (
+	SecretPrefix = "secret:"
+	EnvPrefix    = "env:"
+)
+
+// Mask 脱敏后的占位符
+const Mask = "***"
+
+// minRedactLen 短于此长度的值不登记（避免把"1"、"true"之类的短串全部替换）
+const minRedactLen = 8
+
+// Provider 密钥提供者
+type Provider interface {
+	Name() string
+	// Get 查找密钥，不存在时返回 ok=false（不是错误）
+	Get(name string) (value string, ok bool, err error)
+}
+
+// Chain 按顺序查找的提供者链
+type Chain []Provider
+
+// Get 依次查找，返回第一个找到的值
+func (c Chain) Get(name string) (string, error) {
+	var errs []string
+	for _, p := range c {
+		value, ok, err := p.Get(name)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", p.Name(), err))
+			continue
+		}
+		if ok {
+			return value, nil
+		}
+	}
+	if len(errs) > 0 {
+		return "", fmt.Errorf("未找到密钥%s（%s）", name, strings.Join(errs, "; "))
+	}
+	return "", fmt.Errorf("未找到密钥%s（已查找: %s）", name, strings.Join(c.names(), ", "))
+}
+
+func (c Chain) names() []string {
+	names := make([]string, 0, len(c))
+	for _, p := range c {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+// Options 提供者链配置
+type Options struct {
+	Providers      []string // "keyring", "file", "env"（空=全部，按此顺序）
+	File           string   // 加密密钥文件路径（默认secrets.enc）
+	Passphrase     string   // 加密文件口令（通常来自 NOFX_SECRETS_PASSPHRASE）
+	KeyringService string   // keyring服务名（默认nofx）
+}
+
+// NewChain 按配置创建提供者链（加密文件不存在时跳过，未设置口令时报错）
+func NewChain(opts Options) (Chain, error) {
+	names := opts.Providers
+	if len(names) == 0 {
+		names = []string{"keyring", "file", "env"}
+	}
+	var chain Chain
+	for _, name := range names {
+		switch name {
+		case "keyring":
+			chain = append(chain, NewKeyringProvider(opts.KeyringService))
+		case "file":
+			path := opts.File
+			if path == "" {
+				path = DefaultFile
+			}
+			if _, err := os.Stat(path); os.IsNotExist(err) && len(opts.Providers) == 0 {
+				continue
+			}
+			p, err := OpenFileProvider(path, opts.Passphrase)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, p)
+		case "env":
+			chain = append(chain, EnvProvider{})
+		default:
+			return nil, fmt.Errorf("不支持的密钥提供者: %s（可选 keyring/file/env）", name)
+		}
+	}
+	return chain, nil
+}
+
+// Resolve 解析配置值：secret:/env:引用替换为实际密钥，明文原样返回；结果都会登记脱敏
+func Resolve(chain Chain, value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, SecretPrefix):
+		resolved, err := chain.Get(strings.TrimPrefix(value, SecretPrefix))
+		if err != nil {
+			return "", err
+		}
+		value = resolved
+	case strings.HasPrefix(value, EnvPrefix):
+		name := strings.TrimPrefix(value, EnvPrefix)
+		resolved, ok := os.LookupEnv(name)
+		if !ok || resolved == "" {
+			return "", fmt.Errorf("环境变量%s未设置", name)
+		}
+		value = resolved
+	}
+	Track(value)
+	return value, nil
+}
+
+// IsReference 配置值是否是密钥引用（而不是明文）
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, SecretPrefix) || strings.HasPrefix(value, EnvPrefix)
+}
+
+var (
+	mu      sync.RWMutex
+	tracked = make(map[string]bool)
+	ordered []string // 按长度倒序，避免短密钥先替换掉长密钥的一部分
+)
+
+// Track 登记需要脱敏的密钥值
+func Track(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minRedactLen {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if tracked[value] {
+		return
+	}
+	tracked[value] = true
+	ordered = append(ordered, value)
+	sort.Slice(ordered, func(i, j int) bool { return len(ordered[i]) > len(ordered[j]) })
+}
+
+// Tracked 已登记的密钥数量
+func Tracked() int {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(ordered)
+}
+
+// Redact 把字符串中已登记的密钥替换为***
+func Redact(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, v := range ordered {
+		if strings.Contains(s, v) {
+			s = strings.ReplaceAll(s, v, Mask)
+		}
+	}
+	return s
+}
+
+// RedactBytes Redact的[]byte版本（没有命中时返回原切片）
+func RedactBytes(b []byte) []byte {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, v := range ordered {
+		if bytes.Contains(b, []byte(v)) {
+			b = bytes.ReplaceAll(b, []byte(v), []byte(Mask))
+		}
+	}
+	return b
+}
+
+// redactWriter 写出前脱敏（log包每条日志调用一次Write，不会把密钥拆到两次写入中）
+type redactWriter struct {
+	w io.Writer
+}
+
+// NewRedactWriter 包装日志输出，写出前替换已登记的密钥
+func NewRedactWriter(w io.Writer) io.Writer {
+	return &redactWriter{w: w}
+}
+
+func (r *redactWriter) Write(p []byte) (int, error) {
+	if _, err := r.w.Write(RedactBytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,149 @@
+package trader
+
+import (
+	"log"
+	"nofx/events"
+	"nofx/i18n"
+	"nofx/logger"
+	"time"
+)
+
+// 账户异常类型
+const (
+	AnomalyExternalPosition = "external_position" // 出现非本系统开仓的持仓（手动干预）
+	AnomalyLeverageChanged  = "leverage_changed"  // 持仓杠杆被外部修改
+	AnomalyMissingStop      = "missing_stop"      // 记录有止损但交易所上没有止损单
+	AnomalyBalanceDrop      = "balance_drop"      // 钱包余额下降且没有平仓可以解释（划转/提现/未知扣费）
+)
+
+const defaultAnomalyBalanceDropPct = 2.0 // 钱包余额无法解释的下降阈值（%）
+
+// accountSnapshot 上一周期的账户快照（钱包余额 + 持仓数量/杠杆）
+type accountSnapshot struct {
+	Time             time.Time
+	WalletBalance    float64
+	AvailableBalance float64
+	UnrealizedPnL    float64
+	Positions        map[string]snapshotPosition // symbol_side
+}
+
+type snapshotPosition struct {
+	Symbol   string
+	Side     string
+	Quantity float64
+	Leverage int
+}
+
+// detectAccountAnomalies 🕵️ 对比相邻周期的账户快照，发现外部干预并通知
+// 非本系统开仓的持仓和被外部修改杠杆的持仓标记为"外部管理"（与启动对账的ignore相同，不再交给AI管理）
+func (at *AutoTrader) detectAccountAnomalies(record *logger.DecisionRecord) {
+	balance, err := at.trader.GetBalance()
+	if err != nil {
+		log.Printf("⚠️  账户异常检测: 获取余额失败: %v", err)
+		return
+	}
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		log.Printf("⚠️  账户异常检测: 获取持仓失败: %v", err)
+		return
+	}
+
+	current := &accountSnapshot{Time: time.Now(), Positions: make(map[string]snapshotPosition)}
+	current.WalletBalance = balance.TotalWalletBalance
+	current.AvailableBalance = balance.AvailableBalance
+	current.UnrealizedPnL = balance.TotalUnrealizedProfit
+	for _, pos := range positions {
+		p := snapshotPosition{Symbol: pos.Symbol, Side: pos.Side, Quantity: pos.Quantity, Leverage: pos.Leverage}
+		current.Positions[p.Symbol+"_"+p.Side] = p
+	}
+
+	last := at.lastAccountSnapshot
+	at.lastAccountSnapshot = current
+	if last == nil {
+		return // 首个周期：启动对账已处理未知持仓
+	}
+
+	// 🔒 外部提现/划转：锁定开新仓直到确认
+	at.detectWithdrawal(last, current, record)
+
+	var anomalies []logger.AccountAnomaly
+	flag := func(a logger.AccountAnomaly) {
+		anomalies = append(anomalies, a)
+		i18n.Logf("anomaly.entry", a.Kind, a.Symbol, a.Side, a.Detail)
+		at.events.Publish(events.AccountAnomalyEvent{
+			TraderID: at.id, Kind: a.Kind, Symbol: a.Symbol, Side: a.Side, Detail: a.Detail, Time: current.Time,
+		})
+	}
+
+	positionReduced := false
+	for key, prev := range last.Positions {
+		if cur, ok := current.Positions[key]; !ok || cur.Quantity < prev.Quantity {
+			positionReduced = true // 平仓/减仓（含止损触发），余额变化可以解释
+		}
+	}
+
+	for key, p := range current.Positions {
+		if at.ignoredPositions[key] || (at.basis != nil && at.basis.Manages(p.Symbol)) {
+			continue
+		}
+		protection, known := at.orderManager.GetProtection(p.Symbol, p.Side)
+		prev, existed := last.Positions[key]
+
+		// 1. 本系统没有开仓记录的新持仓
+		if !existed && !known {
+			at.ignoredPositions[key] = true
+			flag(logger.AccountAnomaly{Kind: AnomalyExternalPosition, Symbol: p.Symbol, Side: p.Side,
+				Detail: i18n.T("anomaly.external_position", p.Quantity, p.Leverage)})
+			continue
+		}
+
+		// 2. 杠杆被外部修改（本周期之间本系统在该币种有新开仓时杠杆变化是预期的）
+		if existed && prev.Leverage > 0 && p.Leverage != prev.Leverage && !at.openedSince(p.Symbol, last.Time) {
+			at.ignoredPositions[key] = true
+			flag(logger.AccountAnomaly{Kind: AnomalyLeverageChanged, Symbol: p.Symbol, Side: p.Side,
+				Detail: i18n.T("anomaly.leverage_changed", prev.Leverage, p.Leverage)})
+			continue
+		}
+
+		// 3. 记录有止损但交易所上没有止损单（被手动撤销或丢失）
+		if known && protection.StopLoss > 0 && !at.config.ObserveMode {
+			if _, ok := at.trader.(openOrderLister); ok {
+				if sl, _ := at.protectiveOrderPrices(p.Symbol, p.Side); sl == 0 {
+					flag(logger.AccountAnomaly{Kind: AnomalyMissingStop, Symbol: p.Symbol, Side: p.Side,
+						Detail: i18n.T("anomaly.missing_stop", protection.StopLoss)})
+				}
+			}
+		}
+	}
+
+	// 4. 钱包余额下降且期间没有平仓/减仓（开仓手续费和资金费通常远小于阈值）
+	threshold := at.config.AnomalyBalanceDropPct
+	if threshold == 0 {
+		threshold = defaultAnomalyBalanceDropPct
+	}
+	if threshold > 0 && !positionReduced && last.WalletBalance > 0 {
+		dropPct := (last.WalletBalance - current.WalletBalance) / last.WalletBalance * 100
+		if dropPct > threshold {
+			flag(logger.AccountAnomaly{Kind: AnomalyBalanceDrop,
+				Detail: i18n.T("anomaly.balance_drop", last.WalletBalance, current.WalletBalance, dropPct)})
+		}
+	}
+
+	if len(anomalies) == 0 {
+		return
+	}
+	record.AccountAnomalies = append(record.AccountAnomalies, anomalies...)
+	for _, a := range anomalies {
+		record.ExecutionLog = append(record.ExecutionLog, i18n.T("anomaly.entry", a.Kind, a.Symbol, a.Side, a.Detail))
+	}
+}
+
+// openedSince 本系统在since之后是否在该币种开过仓
+func (at *AutoTrader) openedSince(symbol string, since time.Time) bool {
+	for _, side := range []string{"long", "short"} {
+		if p, ok := at.orderManager.GetProtection(symbol, side); ok && p.OpenTime.After(since) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package trader
+
+import (
+	"log"
+	"nofx/decision/agents"
+	"nofx/memory"
+	"sort"
+	"sync"
+)
+
+// AccountTierConfig 🏦 账户级别：按当前净值选择，风控阈值和记忆启发式随账户规模调整
+type AccountTierConfig struct {
+	Name               string
+	MinEquity          float64 // 净值≥该值时适用（USDT，取满足条件的最高一档）
+	MaxLossPct         float64 // 累计亏损达到该比例时禁止开仓（0=默认20），谨慎档位按比例缩放
+	MaxRiskPerTradePct float64 // 单笔风险上限占净值的百分比（0=只用MaxRiskPerTradeUSD）
+	BreakEvenPct       float64 // 记忆中收益率在±该范围内记为保本（0=默认0.1）
+}
+
+// accountTierState 当前生效的账户级别（每个周期按净值更新）
+type accountTierState struct {
+	mu     sync.RWMutex
+	tier   *AccountTierConfig
+	equity float64
+}
+
+// sortAccountTiers 按起始净值从低到高排序（返回副本）
+func sortAccountTiers(tiers []AccountTierConfig) []AccountTierConfig {
+	sorted := append([]AccountTierConfig(nil), tiers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].MinEquity < sorted[j].MinEquity
+	})
+	return sorted
+}
+
+// updateAccountTier 按当前净值选择账户级别，返回本周期的累计亏损风控阈值
+// 未配置账户级别时返回默认阈值（亏损20%禁止开仓）
+func (at *AutoTrader) updateAccountTier(equity float64) agents.AccountRiskGate {
+	var tier *AccountTierConfig
+	for i := range at.config.AccountTiers {
+		if equity >= at.config.AccountTiers[i].MinEquity {
+			tier = &at.config.AccountTiers[i]
+		}
+	}
+	if tier == nil && len(at.config.AccountTiers) > 0 {
+		tier = &at.config.AccountTiers[0] // 净值低于最低一档时按最低一档处理
+	}
+
+	state := &at.accountTier
+	state.mu.Lock()
+	changed := tier != state.tier
+	state.tier = tier
+	state.equity = equity
+	state.mu.Unlock()
+
+	if tier == nil {
+		return agents.AccountRiskGate{}
+	}
+	if changed {
+		log.Printf("🏦 [%s] 账户级别: %s（净值%.2f USDT）| 累计亏损上限%.0f%% | 单笔风险%.2f%%净值 | 保本区间±%.2f%%",
+			at.name, tier.Name, equity, agents.AccountRiskGate{MaxLossPct: tier.MaxLossPct}.MaxLoss(),
+			tier.MaxRiskPerTradePct, at.breakEvenPct(tier))
+		at.memoryManager.SetBreakEvenPct(at.breakEvenPct(tier))
+	}
+	return agents.AccountRiskGate{Tier: tier.Name, MaxLossPct: tier.MaxLossPct}
+}
+
+// breakEvenPct 记忆中记为保本的收益率范围
+func (at *AutoTrader) breakEvenPct(tier *AccountTierConfig) float64 {
+	if tier != nil && tier.BreakEvenPct > 0 {
+		return tier.BreakEvenPct
+	}
+	return memory.DefaultBreakEvenPct
+}
+
+// maxRiskPerTradeUSD 单笔风险上限（USDT）：固定金额与账户级别按净值比例的上限取较小者，0=不限制
+func (at *AutoTrader) maxRiskPerTradeUSD() float64 {
+	state := &at.accountTier
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+	return tierMaxRisk(at.config.MaxRiskPerTradeUSD, state.tier, state.equity)
+}
+
+func tierMaxRisk(maxRisk float64, tier *AccountTierConfig, equity float64) float64 {
+	if tier != nil && tier.MaxRiskPerTradePct > 0 && equity > 0 {
+		tierRisk := equity * tier.MaxRiskPerTradePct / 100
+		if maxRisk <= 0 || tierRisk < maxRisk {
+			maxRisk = tierRisk
+		}
+	}
+	return maxRisk
+}
+
+// getAccountTierStatus 当前账户级别（状态API）
+func (at *AutoTrader) getAccountTierStatus() map[string]interface{} {
+	state := &at.accountTier
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+	if state.tier == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"name":                   state.tier.Name,
+		"equity":                 state.equity,
+		"max_loss_pct":           agents.AccountRiskGate{MaxLossPct: state.tier.MaxLossPct}.MaxLoss(),
+		"max_risk_per_trade_usd": tierMaxRisk(at.config.MaxRiskPerTradeUSD, state.tier, state.equity),
+		"break_even_pct":         at.breakEvenPct(state.tier),
+	}
+}
@@ -0,0 +1,132 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/market"
+	"sync"
+	"time"
+)
+
+const (
+	defaultATRSpikeRatio = 1.5 // ATR3/ATR14 ≥ 1.5 视为波动放大
+	defaultNearLevelPct  = 0.5 // 标记价距止损/止盈 ≤ 0.5% 视为临近
+	chopATRRatio         = 0.8 // ATR3/ATR14 < 0.8 且4h涨跌幅 < 1% 视为震荡
+	chopPriceChangePct   = 1.0
+)
+
+// AdaptiveScanConfig ⏱️ 自适应扫描间隔：波动放大或持仓临近止损/止盈时缩短到MinInterval，无持仓的震荡行情延长到MaxInterval
+type AdaptiveScanConfig struct {
+	MinInterval   time.Duration // 最短间隔（0=基础扫描间隔，不缩短）
+	MaxInterval   time.Duration // 最长间隔（0=基础扫描间隔，不延长）
+	ATRSpikeRatio float64       // ATR3/ATR14 达到此倍数视为波动放大（0=默认1.5）
+	NearLevelPct  float64       // 标记价距止损/止盈在此百分比内视为临近（0=默认0.5）
+}
+
+// Enabled 是否启用自适应扫描
+func (c AdaptiveScanConfig) Enabled() bool {
+	return c.MinInterval > 0 || c.MaxInterval > 0
+}
+
+// adaptiveScanState 下一周期的扫描间隔（由决策周期计算，主循环读取）
+type adaptiveScanState struct {
+	mu       sync.RWMutex
+	interval time.Duration
+	reason   string
+}
+
+// currentScanInterval 当前生效的扫描间隔
+func (at *AutoTrader) currentScanInterval() time.Duration {
+	at.adaptive.mu.RLock()
+	defer at.adaptive.mu.RUnlock()
+	if at.adaptive.interval > 0 {
+		return at.adaptive.interval
+	}
+	return at.config.ScanInterval
+}
+
+// adaptScanInterval 根据本周期的行情和持仓计算下一周期的扫描间隔
+func (at *AutoTrader) adaptScanInterval(ctx *decision.Context) {
+	cfg := at.config.AdaptiveScan
+	if !cfg.Enabled() || ctx == nil {
+		return
+	}
+
+	base := at.config.ScanInterval
+	minInterval, maxInterval := base, base
+	if cfg.MinInterval > 0 && cfg.MinInterval < base {
+		minInterval = cfg.MinInterval
+	}
+	if cfg.MaxInterval > base {
+		maxInterval = cfg.MaxInterval
+	}
+
+	interval, reason := base, "常规"
+	if fast := at.fastScanReason(ctx, cfg); fast != "" {
+		interval, reason = minInterval, fast
+	} else if len(ctx.Positions) == 0 && isChoppy(ctx.MarketDataMap["BTCUSDT"]) {
+		interval, reason = maxInterval, "无持仓且BTC震荡"
+	}
+
+	at.adaptive.mu.Lock()
+	changed := at.adaptive.interval != interval
+	at.adaptive.interval = interval
+	at.adaptive.reason = reason
+	at.adaptive.mu.Unlock()
+
+	if changed {
+		log.Printf("⏱️ [%s] 下一周期扫描间隔: %v（%s）", at.name, interval, reason)
+	}
+}
+
+// fastScanReason 需要加快扫描的原因（空=不需要）
+func (at *AutoTrader) fastScanReason(ctx *decision.Context, cfg AdaptiveScanConfig) string {
+	spike := cfg.ATRSpikeRatio
+	if spike <= 0 {
+		spike = defaultATRSpikeRatio
+	}
+	near := cfg.NearLevelPct
+	if near <= 0 {
+		near = defaultNearLevelPct
+	}
+
+	if ratio := atrRatio(ctx.MarketDataMap["BTCUSDT"]); ratio >= spike {
+		return fmt.Sprintf("BTC波动放大(ATR3/ATR14=%.2f)", ratio)
+	}
+
+	for _, pos := range ctx.Positions {
+		if ratio := atrRatio(ctx.MarketDataMap[pos.Symbol]); ratio >= spike {
+			return fmt.Sprintf("%s波动放大(ATR3/ATR14=%.2f)", pos.Symbol, ratio)
+		}
+		if pos.MarkPrice <= 0 {
+			continue
+		}
+		p, ok := at.orderManager.GetProtection(pos.Symbol, pos.Side)
+		if !ok {
+			continue
+		}
+		if p.StopLoss > 0 && math.Abs(pos.MarkPrice-p.StopLoss)/pos.MarkPrice*100 <= near {
+			return fmt.Sprintf("%s %s临近止损", pos.Symbol, pos.Side)
+		}
+		if p.TakeProfit > 0 && math.Abs(p.TakeProfit-pos.MarkPrice)/pos.MarkPrice*100 <= near {
+			return fmt.Sprintf("%s %s临近止盈", pos.Symbol, pos.Side)
+		}
+	}
+	return ""
+}
+
+// atrRatio 4h ATR3/ATR14（数据缺失时返回0）
+func atrRatio(md *market.Data) float64 {
+	if md == nil || md.LongerTermContext == nil || md.LongerTermContext.ATR14 <= 0 {
+		return 0
+	}
+	return md.LongerTermContext.ATR3 / md.LongerTermContext.ATR14
+}
+
+// isChoppy 波动收缩且4h涨跌幅很小
+func isChoppy(md *market.Data) bool {
+	ratio := atrRatio(md)
+	return ratio > 0 && ratio < chopATRRatio && math.Abs(md.PriceChange4h) < chopPriceChangePct
+}
@@ -0,0 +1,52 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/mcp"
+	"time"
+)
+
+// FallbackModelConfig 🔁 备用AI模型：主模型连续超时/5xx时切换，冷却后自动切回
+type FallbackModelConfig struct {
+	Provider      string // "deepseek", "qwen" 或 "custom"
+	APIKey        string
+	APIURL        string // custom时必填
+	Model         string
+	Timeout       time.Duration // 单次请求超时（0=沿用主模型）
+	MaxFailures   int           // 主模型连续失败多少次后切换（0=默认2）
+	SlowThreshold time.Duration // 主模型响应慢于该值时记为一次失败（0=不按延迟判断）
+	FailbackAfter time.Duration // 切换后多久重新尝试主模型（0=默认10分钟）
+}
+
+// newFailover 按配置创建主模型的故障切换（cfg为nil时返回nil）
+func newFailover(main *mcp.Client, cfg *FallbackModelConfig) (*mcp.Failover, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	secondary := mcp.New()
+	switch cfg.Provider {
+	case "deepseek":
+		secondary.SetDeepSeekAPIKey(cfg.APIKey)
+	case "qwen":
+		secondary.SetQwenAPIKey(cfg.APIKey, "")
+	case "custom":
+		secondary.SetCustomAPI(cfg.APIURL, cfg.APIKey, cfg.Model)
+	default:
+		return nil, fmt.Errorf("fallback_model: 不支持的provider '%s'", cfg.Provider)
+	}
+	if cfg.Model != "" {
+		secondary.Model = cfg.Model
+	}
+	secondary.Timeout = main.Timeout
+	if cfg.Timeout > 0 {
+		secondary.Timeout = cfg.Timeout
+	}
+	secondary.MaxTokens = main.MaxTokens
+
+	failover := mcp.NewFailover(secondary)
+	failover.MaxFailures = cfg.MaxFailures
+	failover.SlowThreshold = cfg.SlowThreshold
+	failover.FailbackAfter = cfg.FailbackAfter
+	return failover, nil
+}
@@ -0,0 +1,145 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/market"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	altcoinSignalSource        = "altcoin_signal" // 候选来源标签
+	altcoinSignalTTL           = time.Hour        // 信号有效期（扫描间隔30分钟，保留最近两轮）
+	maxAltcoinSignalCandidates = 5                // 每周期最多注入的信号币种数
+	minAnomalyConfidence       = 4                // 异动信号最低星级（1-5星）
+	minSpotFuturesConfidence   = 2                // 现货期货价差信号最低星级（1-3星）
+)
+
+// altcoinSignal 注入决策的异动信号
+type altcoinSignal struct {
+	symbol     string
+	kind       string // "anomaly"（量价/OI异动）或 "spot_futures"（现货期货价差）
+	direction  string
+	confidence int // 归一化到1-5星
+	summary    string
+	at         time.Time
+}
+
+// altcoinSignalBuffer 山寨币扫描器与决策周期之间的信号缓冲（每个币种保留最新一条）
+type altcoinSignalBuffer struct {
+	mu      sync.Mutex
+	signals map[string]*altcoinSignal
+}
+
+func newAltcoinSignalBuffer() *altcoinSignalBuffer {
+	return &altcoinSignalBuffer{signals: make(map[string]*altcoinSignal)}
+}
+
+func (b *altcoinSignalBuffer) put(sig *altcoinSignal) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if old, ok := b.signals[sig.symbol]; ok && old.at.After(sig.at) {
+		return
+	}
+	b.signals[sig.symbol] = sig
+}
+
+// recordAnomaly 记录量价/OI异动信号（仅高星级、非追高阶段）
+func (b *altcoinSignalBuffer) recordAnomaly(s *market.AnomalySignal) {
+	if s.Confidence < minAnomalyConfidence || s.SignalTier == "late" {
+		return
+	}
+	b.put(&altcoinSignal{
+		symbol:     s.Symbol,
+		kind:       "anomaly",
+		direction:  s.Direction,
+		confidence: s.Confidence,
+		summary: fmt.Sprintf("%s %d星 方向%s | OI 1h %+.1f%% | 价格15m %+.2f%% | 成交量1h %+.0f%% | 资金费率%.4f%%",
+			s.TierLabel, s.Confidence, s.Direction, s.OIChange1h, s.PriceChange15m, s.VolumeChange1h, s.FundingRate),
+		at: s.Timestamp,
+	})
+}
+
+// recordSpotFutures 记录现货期货价差信号（现货领先期货）
+func (b *altcoinSignalBuffer) recordSpotFutures(s *market.SpotFuturesSignal) {
+	if s.Confidence < minSpotFuturesConfidence {
+		return
+	}
+	b.put(&altcoinSignal{
+		symbol:     s.Symbol,
+		kind:       "spot_futures",
+		direction:  "up",
+		confidence: s.Confidence * 5 / 3,
+		summary: fmt.Sprintf("现货领先期货 %d星 | 现货$%.4f 期货$%.4f 价差%.2f%% | %s",
+			s.Confidence, s.SpotPrice, s.FuturesPrice, s.PriceDiffPct, s.Reasoning),
+		at: s.Timestamp,
+	})
+}
+
+// recent 有效期内的信号（星级高、时间新的在前），同时清理过期信号
+func (b *altcoinSignalBuffer) recent() []*altcoinSignal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var result []*altcoinSignal
+	for symbol, sig := range b.signals {
+		if time.Since(sig.at) > altcoinSignalTTL {
+			delete(b.signals, symbol)
+			continue
+		}
+		result = append(result, sig)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].confidence != result[j].confidence {
+			return result[i].confidence > result[j].confidence
+		}
+		return result[i].at.After(result[j].at)
+	})
+	if len(result) > maxAltcoinSignalCandidates {
+		result = result[:maxAltcoinSignalCandidates]
+	}
+	return result
+}
+
+// injectAltcoinSignals 把近期高置信异动信号作为额外候选来源注入
+// 已在候选池中的币种追加来源标签；新币种放在最前面，避免被候选数量上限截断
+func (at *AutoTrader) injectAltcoinSignals(candidates []decision.CandidateCoin) []decision.CandidateCoin {
+	if at.altcoinSignals == nil {
+		return candidates
+	}
+
+	signals := at.altcoinSignals.recent()
+	if len(signals) == 0 {
+		return candidates
+	}
+
+	index := make(map[string]int, len(candidates))
+	for i, c := range candidates {
+		index[c.Symbol] = i
+	}
+
+	var injected []decision.CandidateCoin
+	for _, sig := range signals {
+		if at.basis != nil && at.basis.Manages(sig.symbol) {
+			continue // 📐 基差策略币种不交给AI
+		}
+		if i, ok := index[sig.symbol]; ok {
+			candidates[i].Sources = append(candidates[i].Sources, altcoinSignalSource)
+			candidates[i].Signal = sig.summary
+			continue
+		}
+		injected = append(injected, decision.CandidateCoin{
+			Symbol:  sig.symbol,
+			Sources: []string{altcoinSignalSource},
+			Signal:  sig.summary,
+		})
+	}
+
+	log.Printf("🚨 注入%d个山寨币异动信号（新增候选%d个）", len(signals), len(injected))
+	return append(injected, candidates...)
+}
@@ -0,0 +1,44 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// GetSymbolStatuses 获取全部合约的交易状态（/fapi/v3/exchangeInfo，缓存5分钟）
+func (t *AsterTrader) GetSymbolStatuses(refresh bool) (map[string]string, error) {
+	if cached, ok := t.statuses.get(refresh); ok {
+		return cached, nil
+	}
+
+	resp, err := t.client.Get(t.baseURL + "/fapi/v3/exchangeInfo")
+	if err != nil {
+		return nil, fmt.Errorf("获取交易规则失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("获取交易规则失败: HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var info struct {
+		Symbols []struct {
+			Symbol string `json:"symbol"`
+			Status string `json:"status"`
+		} `json:"symbols"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("解析交易规则失败: %w", err)
+	}
+	statuses := make(map[string]string, len(info.Symbols))
+	for _, s := range info.Symbols {
+		statuses[s.Symbol] = s.Status
+	}
+	t.statuses.set(statuses)
+	return statuses, nil
+}
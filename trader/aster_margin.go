@@ -0,0 +1,81 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// asterBracketCache Aster杠杆档位缓存
+type asterBracketCache struct {
+	mu        sync.RWMutex
+	brackets  map[string][]LeverageBracket
+	fetchedAt map[string]time.Time
+}
+
+// asterLeverageBracket /fapi/v3/leverageBracket 返回（字段与币安一致）
+type asterLeverageBracket struct {
+	Symbol   string `json:"symbol"`
+	Brackets []struct {
+		InitialLeverage int         `json:"initialLeverage"`
+		NotionalCap     json.Number `json:"notionalCap"`
+		NotionalFloor   json.Number `json:"notionalFloor"`
+	} `json:"brackets"`
+}
+
+// GetLeverageBrackets 获取币种杠杆档位（/fapi/v3/leverageBracket，缓存1小时）
+func (t *AsterTrader) GetLeverageBrackets(symbol string) ([]LeverageBracket, error) {
+	t.brackets.mu.RLock()
+	cached, ok := t.brackets.brackets[symbol]
+	fetchedAt := t.brackets.fetchedAt[symbol]
+	t.brackets.mu.RUnlock()
+	if ok && time.Since(fetchedAt) < leverageBracketTTL {
+		return cached, nil
+	}
+
+	body, err := t.request("GET", "/fapi/v3/leverageBracket", map[string]interface{}{"symbol": symbol})
+	if err != nil {
+		if ok {
+			return cached, nil // 获取失败时继续使用旧档位
+		}
+		return nil, fmt.Errorf("获取杠杆档位失败: %w", err)
+	}
+
+	// 指定symbol时可能返回单个对象而不是数组
+	var res []asterLeverageBracket
+	if err := json.Unmarshal(body, &res); err != nil {
+		var single asterLeverageBracket
+		if err := json.Unmarshal(body, &single); err != nil {
+			return nil, fmt.Errorf("解析杠杆档位失败: %w", err)
+		}
+		res = []asterLeverageBracket{single}
+	}
+
+	var brackets []LeverageBracket
+	for _, lb := range res {
+		if lb.Symbol != symbol {
+			continue
+		}
+		for _, b := range lb.Brackets {
+			floor, _ := strconv.ParseFloat(b.NotionalFloor.String(), 64)
+			notionalCap, _ := strconv.ParseFloat(b.NotionalCap.String(), 64)
+			brackets = append(brackets, LeverageBracket{
+				InitialLeverage: b.InitialLeverage,
+				NotionalFloor:   floor,
+				NotionalCap:     notionalCap,
+			})
+		}
+	}
+
+	t.brackets.mu.Lock()
+	if t.brackets.brackets == nil {
+		t.brackets.brackets = make(map[string][]LeverageBracket)
+		t.brackets.fetchedAt = make(map[string]time.Time)
+	}
+	t.brackets.brackets[symbol] = brackets
+	t.brackets.fetchedAt[symbol] = time.Now()
+	t.brackets.mu.Unlock()
+	return brackets, nil
+}
@@ -0,0 +1,253 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"nofx/errs"
+	"strconv"
+)
+
+// asterOrder Aster订单返回（字段与币安合约一致，数值为字符串）
+type asterOrder struct {
+	OrderID       int64  `json:"orderId"`
+	Symbol        string `json:"symbol"`
+	Status        string `json:"status"`
+	ClientOrderID string `json:"clientOrderId"`
+	Side          string `json:"side"`
+	Type          string `json:"type"`
+	Price         string `json:"price"`
+	AvgPrice      string `json:"avgPrice"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	StopPrice     string `json:"stopPrice"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+// toResult 转换为统一的订单结果（价格数量解析为float64）
+func (o *asterOrder) toResult() *OrderResult {
+	result := &OrderResult{
+		OrderID:       o.OrderID,
+		ClientOrderID: o.ClientOrderID,
+		Symbol:        o.Symbol,
+		Status:        o.Status,
+		Side:          o.Side,
+		Type:          o.Type,
+		UpdateTime:    o.UpdateTime,
+	}
+	result.Price, _ = strconv.ParseFloat(o.Price, 64)
+	result.OrigQty, _ = strconv.ParseFloat(o.OrigQty, 64)
+	result.ExecutedQty, _ = strconv.ParseFloat(o.ExecutedQty, 64)
+	result.AvgPrice, _ = strconv.ParseFloat(o.AvgPrice, 64)
+	result.StopPrice, _ = strconv.ParseFloat(o.StopPrice, 64)
+	return result
+}
+
+func parseAsterOrder(body []byte) (*asterOrder, error) {
+	var order asterOrder
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, fmt.Errorf("解析订单失败: %w", err)
+	}
+	return &order, nil
+}
+
+// placeLimit 按精度格式化后下限价单（reduceOnly=true时只减仓）
+func (t *AsterTrader) placeLimit(symbol, side string, quantity, price float64, reduceOnly bool, clientOrderID string) (*asterOrder, string, error) {
+	formattedPrice, err := t.formatPrice(symbol, price)
+	if err != nil {
+		return nil, "", err
+	}
+	formattedQty, err := t.formatQuantity(symbol, quantity)
+	if err != nil {
+		return nil, "", err
+	}
+	prec, err := t.getPrecision(symbol)
+	if err != nil {
+		return nil, "", err
+	}
+
+	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
+	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
+	if formattedQty <= 0 {
+		return nil, "", fmt.Errorf("%s 数量%.8f按精度格式化后为0", symbol, quantity)
+	}
+
+	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
+		price, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
+
+	params := map[string]interface{}{
+		"symbol":       symbol,
+		"positionSide": "BOTH",
+		"type":         "LIMIT",
+		"side":         side,
+		"timeInForce":  "GTC",
+		"quantity":     qtyStr,
+		"price":        priceStr,
+	}
+	if reduceOnly {
+		params["reduceOnly"] = "true" // 只减仓，数量过期或竞态时不会反向开仓
+	}
+	if clientOrderID == "" {
+		clientOrderID = botOrderID("lmt")
+	}
+	params["newClientOrderId"] = clientOrderID
+
+	body, err := t.request("POST", "/fapi/v3/order", params)
+	if err != nil {
+		return nil, "", err
+	}
+	order, err := parseAsterOrder(body)
+	if err != nil {
+		return nil, "", err
+	}
+	return order, qtyStr, nil
+}
+
+// ensureMinNotional 数量不满足交易所最小名义价值时按step size向上调整
+func (t *AsterTrader) ensureMinNotional(symbol string, quantity, price float64) float64 {
+	prec, err := t.getPrecision(symbol)
+	if err != nil || prec.MinNotional <= 0 || price <= 0 {
+		return quantity
+	}
+	formattedQty, err := t.formatQuantity(symbol, quantity)
+	if err != nil || formattedQty*price >= prec.MinNotional {
+		return quantity
+	}
+
+	step := prec.StepSize
+	if step <= 0 {
+		step = math.Pow10(-prec.QuantityPrecision)
+	}
+	adjusted := math.Ceil(prec.MinNotional/price/step) * step
+	log.Printf("  ⚠️ 调整数量以满足最小名义价值%.2f USDT: %.8f (%.2f USDT) → %.8f (%.2f USDT)",
+		prec.MinNotional, formattedQty, formattedQty*price, adjusted, adjusted*price)
+	return adjusted
+}
+
+// fillPrice 订单成交均价（下单返回中没有成交信息时查询一次订单）
+func (t *AsterTrader) fillPrice(symbol string, order *asterOrder) float64 {
+	avgPrice, _ := strconv.ParseFloat(order.AvgPrice, 64)
+	if avgPrice > 0 {
+		return avgPrice
+	}
+	status, err := t.GetOrderStatus(symbol, order.OrderID)
+	if err != nil {
+		log.Printf("  ⚠ 查询平仓成交价失败: %v", err)
+		return 0
+	}
+	return status.AvgPrice
+}
+
+// FormatPrice 格式化价格到正确的精度（tick size）
+func (t *AsterTrader) FormatPrice(symbol string, price float64) (string, error) {
+	formatted, err := t.formatPrice(symbol, price)
+	if err != nil {
+		return "", err
+	}
+	prec, err := t.getPrecision(symbol)
+	if err != nil {
+		return "", err
+	}
+	return t.formatFloatWithPrecision(formatted, prec.PricePrecision), nil
+}
+
+// ==================== 限价单功能 ====================
+
+// PlaceLimitOrder 下限价单（与币安限价单模式一致：清理旧委托、设置杠杆、最小名义价值调整）
+func (t *AsterTrader) PlaceLimitOrder(symbol string, side OrderSide, price, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
+	// 先取消该币种的所有委托单（清理旧限价单）
+	if err := t.CancelAllOrders(symbol); err != nil {
+		log.Printf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
+	}
+
+	if err := t.SetLeverage(symbol, leverage); err != nil {
+		return nil, fmt.Errorf("设置杠杆失败: %w", err)
+	}
+
+	quantity = t.ensureMinNotional(symbol, quantity, price)
+	order, qtyStr, err := t.placeLimit(symbol, string(side), quantity, price, false, clientOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("下限价单失败: %w", err)
+	}
+
+	log.Printf("✅ 限价单已提交: %s %s @ %s (数量: %s, 订单ID: %d)",
+		symbol, side, order.Price, qtyStr, order.OrderID)
+
+	result := order.toResult()
+	result.Price = price
+	result.OrigQty, _ = strconv.ParseFloat(qtyStr, 64)
+	return result, nil
+}
+
+// CancelLimitOrder 取消限价单
+func (t *AsterTrader) CancelLimitOrder(symbol string, orderID int64) error {
+	params := map[string]interface{}{
+		"symbol":  symbol,
+		"orderId": orderID,
+	}
+	if _, err := t.request("DELETE", "/fapi/v3/order", params); err != nil {
+		return fmt.Errorf("取消订单失败: %w", err)
+	}
+
+	log.Printf("🗑️  已取消限价单: %s (订单ID: %d)", symbol, orderID)
+	return nil
+}
+
+// GetOrderStatus 查询订单状态
+func (t *AsterTrader) GetOrderStatus(symbol string, orderID int64) (*OrderResult, error) {
+	params := map[string]interface{}{
+		"symbol":  symbol,
+		"orderId": orderID,
+	}
+	body, err := t.request("GET", "/fapi/v3/order", params)
+	if err != nil {
+		return nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+	order, err := parseAsterOrder(body)
+	if err != nil {
+		return nil, err
+	}
+	return order.toResult(), nil
+}
+
+// GetOpenOrders 获取指定币种的所有挂单（用于检查止损止盈是否存在）
+func (t *AsterTrader) GetOpenOrders(symbol string) ([]OrderResult, error) {
+	params := map[string]interface{}{
+		"symbol": symbol,
+	}
+	body, err := t.request("GET", "/fapi/v3/openOrders", params)
+	if err != nil {
+		return nil, fmt.Errorf("查询挂单失败: %w", err)
+	}
+
+	var orders []asterOrder
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, fmt.Errorf("解析挂单失败: %w", err)
+	}
+	results := make([]OrderResult, 0, len(orders))
+	for i := range orders {
+		results = append(results, *orders[i].toResult())
+	}
+	return results, nil
+}
+
+// FindOrderByClientID 按客户端订单ID查询订单（不存在时返回nil, nil）
+func (t *AsterTrader) FindOrderByClientID(symbol, clientOrderID string) (*OrderResult, error) {
+	params := map[string]interface{}{
+		"symbol":            symbol,
+		"origClientOrderId": clientOrderID,
+	}
+	body, err := t.request("GET", "/fapi/v3/order", params)
+	if err != nil {
+		if errs.Is(err, errs.OrderNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+	order, err := parseAsterOrder(body)
+	if err != nil {
+		return nil, err
+	}
+	return order.toResult(), nil
+}
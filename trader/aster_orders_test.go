@@ -0,0 +1,239 @@
+package trader
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// asterRequest 模拟服务器收到的请求
+type asterRequest struct {
+	method string
+	path   string
+	params url.Values
+}
+
+// fakeAster 模拟Aster合约API：记录请求、按路径返回固定响应
+type fakeAster struct {
+	t        *testing.T
+	server   *httptest.Server
+	mu       sync.Mutex
+	requests []asterRequest
+	orders   map[string]string // clientOrderId -> 订单JSON
+}
+
+func newFakeAster(t *testing.T) *fakeAster {
+	f := &fakeAster{t: t, orders: make(map[string]string)}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeAster) handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		f.t.Errorf("解析请求失败: %v", err)
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, asterRequest{method: r.Method, path: r.URL.Path, params: r.Form})
+	f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/fapi/v3/exchangeInfo":
+		fmt.Fprint(w, `{"symbols":[{"symbol":"BTCUSDT","pricePrecision":1,"quantityPrecision":3,"filters":[
+			{"filterType":"PRICE_FILTER","tickSize":"0.1"},
+			{"filterType":"LOT_SIZE","stepSize":"0.001"},
+			{"filterType":"MIN_NOTIONAL","notional":"5"}]}]}`)
+	case r.URL.Path == "/fapi/v3/openOrders":
+		fmt.Fprint(w, `[]`)
+	case r.URL.Path == "/fapi/v3/leverage":
+		fmt.Fprintf(w, `{"symbol":%q,"leverage":%s}`, r.Form.Get("symbol"), r.Form.Get("leverage"))
+	case r.URL.Path == "/fapi/v3/order" && r.Method == http.MethodPost:
+		order := fmt.Sprintf(`{"orderId":42,"symbol":%q,"status":"NEW","clientOrderId":%q,"side":%q,"type":"LIMIT","price":%q,"origQty":%q,"executedQty":"0","avgPrice":"0"}`,
+			r.Form.Get("symbol"), r.Form.Get("newClientOrderId"), r.Form.Get("side"), r.Form.Get("price"), r.Form.Get("quantity"))
+		f.mu.Lock()
+		f.orders[r.Form.Get("newClientOrderId")] = order
+		f.mu.Unlock()
+		fmt.Fprint(w, order)
+	case r.URL.Path == "/fapi/v3/order" && r.Method == http.MethodGet:
+		f.mu.Lock()
+		order, ok := f.orders[r.Form.Get("origClientOrderId")]
+		f.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code":-2013,"msg":"Order does not exist."}`)
+			return
+		}
+		fmt.Fprint(w, order)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"code":-1,"msg":"unexpected %s %s"}`, r.Method, r.URL.Path)
+	}
+}
+
+// last 最后一次访问path的请求
+func (f *fakeAster) last(method, path string) (asterRequest, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.requests) - 1; i >= 0; i-- {
+		if f.requests[i].method == method && f.requests[i].path == path {
+			return f.requests[i], true
+		}
+	}
+	return asterRequest{}, false
+}
+
+// newTestAsterTrader 指向模拟服务器的Aster交易器（不启动时钟同步）
+func newTestAsterTrader(t *testing.T, baseURL string) *AsterTrader {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := &AsterTrader{
+		user:            "0x1111111111111111111111111111111111111111",
+		signer:          crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		privateKey:      key,
+		client:          &http.Client{},
+		baseURL:         baseURL,
+		symbolPrecision: make(map[string]SymbolPrecision),
+	}
+	tr.clock = newClockSync("aster", tr.serverTime, nil)
+	return tr
+}
+
+// recoverAsterSigner 按Aster签名规则（参数JSON + user + signer + nonce 的ABI编码）还原签名地址
+func recoverAsterSigner(t *testing.T, tr *AsterTrader, params url.Values) common.Address {
+	t.Helper()
+	signed := make(map[string]interface{})
+	for k := range params {
+		switch k {
+		case "user", "signer", "signature", "nonce":
+			continue
+		}
+		signed[k] = params.Get(k)
+	}
+	jsonStr, err := tr.normalizeAndStringify(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, ok := new(big.Int).SetString(params.Get("nonce"), 10)
+	if !ok {
+		t.Fatalf("nonce无效: %q", params.Get("nonce"))
+	}
+
+	tString, _ := abi.NewType("string", "", nil)
+	tAddress, _ := abi.NewType("address", "", nil)
+	tUint256, _ := abi.NewType("uint256", "", nil)
+	packed, err := abi.Arguments{{Type: tString}, {Type: tAddress}, {Type: tAddress}, {Type: tUint256}}.
+		Pack(jsonStr, common.HexToAddress(params.Get("user")), common.HexToAddress(params.Get("signer")), nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := crypto.Keccak256(packed)
+	msgHash := crypto.Keccak256Hash([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(hash), hash)))
+
+	sig, err := hex.DecodeString(strings.TrimPrefix(params.Get("signature"), "0x"))
+	if err != nil || len(sig) != 65 {
+		t.Fatalf("签名格式无效: %q", params.Get("signature"))
+	}
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(msgHash.Bytes(), sig)
+	if err != nil {
+		t.Fatalf("还原签名公钥失败: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pub)
+}
+
+func TestAsterPlaceLimitOrder(t *testing.T) {
+	fake := newFakeAster(t)
+	tr := newTestAsterTrader(t, fake.server.URL)
+
+	// 数量0.0001按最小名义价值5 USDT向上调整到0.001，价格按tick size取整
+	result, err := tr.PlaceLimitOrder("BTCUSDT", OrderSideBuy, 50000.04, 0.0001, 10, "nofx_test_1")
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+	if result.OrderID != 42 || result.ClientOrderID != "nofx_test_1" {
+		t.Errorf("result = %+v, want orderId 42 / clientOrderId nofx_test_1", result)
+	}
+	if result.OrigQty != 0.001 {
+		t.Errorf("OrigQty = %v, want 0.001", result.OrigQty)
+	}
+
+	lev, ok := fake.last(http.MethodPost, "/fapi/v3/leverage")
+	if !ok || lev.params.Get("leverage") != "10" {
+		t.Errorf("设置杠杆请求 = %+v, want leverage=10", lev.params)
+	}
+
+	req, ok := fake.last(http.MethodPost, "/fapi/v3/order")
+	if !ok {
+		t.Fatal("没有收到下单请求")
+	}
+	want := map[string]string{
+		"symbol":           "BTCUSDT",
+		"side":             "BUY",
+		"type":             "LIMIT",
+		"timeInForce":      "GTC",
+		"positionSide":     "BOTH",
+		"price":            "50000",
+		"quantity":         "0.001",
+		"newClientOrderId": "nofx_test_1",
+		"recvWindow":       "50000",
+		"user":             tr.user,
+		"signer":           tr.signer,
+	}
+	for k, v := range want {
+		if got := req.params.Get(k); got != v {
+			t.Errorf("参数 %s = %q, want %q", k, got, v)
+		}
+	}
+	if req.params.Get("reduceOnly") != "" {
+		t.Errorf("开仓限价单不应带reduceOnly")
+	}
+	if _, err := strconv.ParseInt(req.params.Get("timestamp"), 10, 64); err != nil {
+		t.Errorf("timestamp无效: %q", req.params.Get("timestamp"))
+	}
+	if got := recoverAsterSigner(t, tr, req.params); got != common.HexToAddress(tr.signer) {
+		t.Errorf("签名还原地址 = %s, want %s", got.Hex(), tr.signer)
+	}
+}
+
+func TestAsterFindOrderByClientID(t *testing.T) {
+	fake := newFakeAster(t)
+	tr := newTestAsterTrader(t, fake.server.URL)
+
+	if _, err := tr.PlaceLimitOrder("BTCUSDT", OrderSideSell, 60000, 0.002, 5, "nofx_test_2"); err != nil {
+		t.Fatalf("PlaceLimitOrder() error = %v", err)
+	}
+
+	order, err := tr.FindOrderByClientID("BTCUSDT", "nofx_test_2")
+	if err != nil {
+		t.Fatalf("FindOrderByClientID() error = %v", err)
+	}
+	if order == nil || order.OrderID != 42 || order.Side != "SELL" || order.Price != 60000 || order.OrigQty != 0.002 {
+		t.Fatalf("FindOrderByClientID() = %+v", order)
+	}
+	req, _ := fake.last(http.MethodGet, "/fapi/v3/order")
+	if req.params.Get("origClientOrderId") != "nofx_test_2" || req.params.Get("symbol") != "BTCUSDT" {
+		t.Errorf("查询参数 = %v", req.params)
+	}
+	if got := recoverAsterSigner(t, tr, req.params); got != common.HexToAddress(tr.signer) {
+		t.Errorf("签名还原地址 = %s, want %s", got.Hex(), tr.signer)
+	}
+
+	// 订单不存在（-2013）返回nil, nil，供崩溃恢复判断"未下单"
+	order, err = tr.FindOrderByClientID("BTCUSDT", "nofx_missing")
+	if err != nil || order != nil {
+		t.Fatalf("FindOrderByClientID(不存在) = %+v, %v, want nil, nil", order, err)
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"math/big"
 	"net/http"
 	"net/url"
+	"nofx/errs"
+	"nofx/ratelimit"
 	"sort"
 	"strconv"
 	"strings"
@@ -27,11 +29,16 @@ import (
 // AsterTrader Aster交易平台实现
 type AsterTrader struct {
 	ctx        context.Context
-	user       string           // 主钱包地址 (ERC20)
-	signer     string           // API钱包地址
+	user       string            // 主钱包地址 (ERC20)
+	signer     string            // API钱包地址
 	privateKey *ecdsa.PrivateKey // API钱包私钥
 	client     *http.Client
 	baseURL    string
+	clock      *clockSync        // 🆕 服务器时间同步（签名timestamp校正）
+	brackets   asterBracketCache // 杠杆档位缓存
+	statuses   symbolStatusCache // 🚧 交易对状态缓存
+
+	cancelAllOrders bool // 🏷️ 撤单范围：false（默认）只撤本系统下的挂单，true撤掉全部挂单
 
 	// 缓存交易对精度信息
 	symbolPrecision map[string]SymbolPrecision
@@ -44,6 +51,7 @@ type SymbolPrecision struct {
 	QuantityPrecision int
 	TickSize          float64 // 价格步进值
 	StepSize          float64 // 数量步进值
+	MinNotional       float64 // 最小名义价值（USDT）
 }
 
 // NewAsterTrader 创建Aster交易器
@@ -57,7 +65,7 @@ func NewAsterTrader(user, signer, privateKeyHex string) (*AsterTrader, error) {
 		return nil, fmt.Errorf("解析私钥失败: %w", err)
 	}
 
-	return &AsterTrader{
+	t := &AsterTrader{
 		ctx:             context.Background(),
 		user:            user,
 		signer:          signer,
@@ -65,14 +73,46 @@ func NewAsterTrader(user, signer, privateKeyHex string) (*AsterTrader, error) {
 		symbolPrecision: make(map[string]SymbolPrecision),
 		client: &http.Client{
 			Timeout: 30 * time.Second, // 增加到30秒
-			Transport: &http.Transport{
+			Transport: ratelimit.NewTransport(&http.Transport{
 				TLSHandshakeTimeout:   10 * time.Second,
 				ResponseHeaderTimeout: 10 * time.Second,
 				IdleConnTimeout:       90 * time.Second,
-			},
+			}), // 🆕 限流预算：权重计数 + 429/418冷却
 		},
 		baseURL: "https://fapi.asterdex.com",
-	}, nil
+	}
+
+	// 🆕 服务器时间同步：签名timestamp按偏差校正
+	t.clock = newClockSync("aster", t.serverTime, nil)
+	t.clock.Start()
+
+	return t, nil
+}
+
+// serverTime 获取Aster服务器时间（毫秒）
+func (t *AsterTrader) serverTime(ctx context.Context) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", t.baseURL+"/fapi/v1/time", nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("解析服务器时间失败: %w", err)
+	}
+	return result.ServerTime, nil
+}
+
+// ClockOffset 本地时钟相对交易所服务器的偏差
+func (t *AsterTrader) ClockOffset() time.Duration {
+	return t.clock.Offset()
 }
 
 // genNonce 生成微秒时间戳
@@ -89,25 +129,39 @@ func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 	}
 	t.mu.RUnlock()
 
-	// 获取交易所信息
+	if _, err := t.PrefetchPrecisions(); err != nil {
+		return SymbolPrecision{}, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if prec, ok := t.symbolPrecision[symbol]; ok {
+		return prec, nil
+	}
+
+	return SymbolPrecision{}, fmt.Errorf("未找到交易对 %s 的精度信息", symbol)
+}
+
+// PrefetchPrecisions 下载exchangeInfo，缓存全部交易对的精度（启动时预取，每天刷新）
+func (t *AsterTrader) PrefetchPrecisions() (int, error) {
 	resp, err := t.client.Get(t.baseURL + "/fapi/v3/exchangeInfo")
 	if err != nil {
-		return SymbolPrecision{}, err
+		return 0, err
 	}
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
 	var info struct {
 		Symbols []struct {
-			Symbol            string `json:"symbol"`
-			PricePrecision    int    `json:"pricePrecision"`
-			QuantityPrecision int    `json:"quantityPrecision"`
+			Symbol            string                   `json:"symbol"`
+			PricePrecision    int                      `json:"pricePrecision"`
+			QuantityPrecision int                      `json:"quantityPrecision"`
 			Filters           []map[string]interface{} `json:"filters"`
 		} `json:"symbols"`
 	}
 
 	if err := json.Unmarshal(body, &info); err != nil {
-		return SymbolPrecision{}, err
+		return 0, err
 	}
 
 	// 缓存所有交易对的精度
@@ -130,6 +184,12 @@ func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 				if stepSizeStr, ok := filter["stepSize"].(string); ok {
 					prec.StepSize, _ = strconv.ParseFloat(stepSizeStr, 64)
 				}
+			case "MIN_NOTIONAL":
+				if notionalStr, ok := filter["notional"].(string); ok {
+					prec.MinNotional, _ = strconv.ParseFloat(notionalStr, 64)
+				} else if notionalStr, ok := filter["minNotional"].(string); ok {
+					prec.MinNotional, _ = strconv.ParseFloat(notionalStr, 64)
+				}
 			}
 		}
 
@@ -137,11 +197,7 @@ func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 	}
 	t.mu.Unlock()
 
-	if prec, ok := t.symbolPrecision[symbol]; ok {
-		return prec, nil
-	}
-
-	return SymbolPrecision{}, fmt.Errorf("未找到交易对 %s 的精度信息", symbol)
+	return len(info.Symbols), nil
 }
 
 // roundToTickSize 将价格/数量四舍五入到tick size/step size的整数倍
@@ -264,7 +320,7 @@ func (t *AsterTrader) normalize(v interface{}) (interface{}, error) {
 func (t *AsterTrader) sign(params map[string]interface{}, nonce uint64) error {
 	// 添加时间戳和接收窗口
 	params["recvWindow"] = "50000"
-	params["timestamp"] = strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	params["timestamp"] = strconv.FormatInt(t.clock.NowMillis(), 10) // 🆕 按服务器时间偏差校正
 
 	// 规范化参数为JSON字符串
 	jsonStr, err := t.normalizeAndStringify(params)
@@ -346,10 +402,17 @@ func (t *AsterTrader) request(method, endpoint string, params map[string]interfa
 
 		lastErr = err
 
+		// 🆕 时间戳超出recvWindow：重新校准后重试（重试时重新签名）
+		if errs.Is(err, errs.ClockSkew) {
+			t.clock.ObserveError(err.Error())
+			if attempt < maxRetries {
+				time.Sleep(time.Second)
+				continue
+			}
+		}
+
 		// 如果是网络超时或临时错误，重试
-		if strings.Contains(err.Error(), "timeout") ||
-			strings.Contains(err.Error(), "connection reset") ||
-			strings.Contains(err.Error(), "EOF") {
+		if errs.Is(err, errs.Transient) {
 			if attempt < maxRetries {
 				waitTime := time.Duration(attempt) * time.Second
 				time.Sleep(waitTime)
@@ -384,13 +447,13 @@ func (t *AsterTrader) doRequest(method, endpoint string, params map[string]inter
 
 		resp, err := t.client.Do(req)
 		if err != nil {
-			return nil, err
+			return nil, errs.Wrap(errs.Network(err), 0, err)
 		}
 		defer resp.Body.Close()
 
 		body, _ := io.ReadAll(resp.Body)
 		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+			return nil, classifyAsterResponse(resp.StatusCode, body)
 		}
 		return body, nil
 
@@ -410,13 +473,13 @@ func (t *AsterTrader) doRequest(method, endpoint string, params map[string]inter
 
 		resp, err := t.client.Do(req)
 		if err != nil {
-			return nil, err
+			return nil, errs.Wrap(errs.Network(err), 0, err)
 		}
 		defer resp.Body.Close()
 
 		body, _ := io.ReadAll(resp.Body)
 		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+			return nil, classifyAsterResponse(resp.StatusCode, body)
 		}
 		return body, nil
 
@@ -426,7 +489,7 @@ func (t *AsterTrader) doRequest(method, endpoint string, params map[string]inter
 }
 
 // GetBalance 获取账户余额
-func (t *AsterTrader) GetBalance() (map[string]interface{}, error) {
+func (t *AsterTrader) GetBalance() (*Balance, error) {
 	params := make(map[string]interface{})
 	body, err := t.request("GET", "/fapi/v3/balance", params)
 	if err != nil {
@@ -458,137 +521,84 @@ func (t *AsterTrader) GetBalance() (map[string]interface{}, error) {
 		}
 	}
 
-	// 返回与Binance相同的字段名，确保AutoTrader能正确解析
-	return map[string]interface{}{
-		"totalWalletBalance":    totalBalance,
-		"availableBalance":      availableBalance,
-		"totalUnrealizedProfit": crossUnPnl,
+	return &Balance{
+		TotalWalletBalance:    totalBalance,
+		AvailableBalance:      availableBalance,
+		TotalUnrealizedProfit: crossUnPnl,
 	}, nil
 }
 
 // GetPositions 获取持仓信息
-func (t *AsterTrader) GetPositions() ([]map[string]interface{}, error) {
+func (t *AsterTrader) GetPositions() ([]Position, error) {
 	params := make(map[string]interface{})
 	body, err := t.request("GET", "/fapi/v3/positionRisk", params)
 	if err != nil {
 		return nil, err
 	}
 
-	var positions []map[string]interface{}
+	var positions []asterPosition
 	if err := json.Unmarshal(body, &positions); err != nil {
 		return nil, err
 	}
 
-	result := []map[string]interface{}{}
+	result := []Position{}
 	for _, pos := range positions {
-		posAmtStr, ok := pos["positionAmt"].(string)
-		if !ok {
-			continue
-		}
-
-		posAmt, _ := strconv.ParseFloat(posAmtStr, 64)
+		posAmt, _ := strconv.ParseFloat(pos.PositionAmt, 64)
 		if posAmt == 0 {
 			continue // 跳过空仓位
 		}
 
-		entryPrice, _ := strconv.ParseFloat(pos["entryPrice"].(string), 64)
-		markPrice, _ := strconv.ParseFloat(pos["markPrice"].(string), 64)
-		unRealizedProfit, _ := strconv.ParseFloat(pos["unRealizedProfit"].(string), 64)
-		leverageVal, _ := strconv.ParseFloat(pos["leverage"].(string), 64)
-		liquidationPrice, _ := strconv.ParseFloat(pos["liquidationPrice"].(string), 64)
-
 		// 判断方向（与Binance一致）
-		side := "long"
+		p := Position{Symbol: pos.Symbol, Side: "long", Quantity: posAmt}
 		if posAmt < 0 {
-			side = "short"
-			posAmt = -posAmt
+			p.Side = "short"
+			p.Quantity = -posAmt
 		}
+		p.EntryPrice, _ = strconv.ParseFloat(pos.EntryPrice, 64)
+		p.MarkPrice, _ = strconv.ParseFloat(pos.MarkPrice, 64)
+		p.UnrealizedPnL, _ = strconv.ParseFloat(pos.UnRealizedProfit, 64)
+		p.Leverage, _ = strconv.Atoi(pos.Leverage)
+		p.LiquidationPrice, _ = strconv.ParseFloat(pos.LiquidationPrice, 64)
 
-		// 返回与Binance相同的字段名
-		result = append(result, map[string]interface{}{
-			"symbol":            pos["symbol"],
-			"side":              side,
-			"positionAmt":       posAmt,
-			"entryPrice":        entryPrice,
-			"markPrice":         markPrice,
-			"unRealizedProfit":  unRealizedProfit,
-			"leverage":          leverageVal,
-			"liquidationPrice":  liquidationPrice,
-		})
+		result = append(result, p)
 	}
 
 	return result, nil
 }
 
-// OpenLong 开多单
-func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
-	// 开仓前先取消所有挂单,防止残留挂单导致仓位叠加
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败(继续开仓): %v", err)
-	}
-
-	// 先设置杠杆
-	if err := t.SetLeverage(symbol, leverage); err != nil {
-		return nil, fmt.Errorf("设置杠杆失败: %w", err)
-	}
-
-	// 获取当前价格
-	price, err := t.GetMarketPrice(symbol)
-	if err != nil {
-		return nil, err
-	}
-
-	// 使用限价单模拟市价单（价格设置得稍高一些以确保成交）
-	limitPrice := price * 1.01
-
-	// 格式化价格和数量到正确精度
-	formattedPrice, err := t.formatPrice(symbol, limitPrice)
-	if err != nil {
-		return nil, err
-	}
-	formattedQty, err := t.formatQuantity(symbol, quantity)
-	if err != nil {
-		return nil, err
-	}
-
-	// 获取精度信息
-	prec, err := t.getPrecision(symbol)
-	if err != nil {
-		return nil, err
-	}
-
-	// 转换为字符串，使用正确的精度格式
-	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
-	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
-
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
-		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
+// asterPosition Aster持仓返回（字段与币安合约一致，数值为字符串）
+type asterPosition struct {
+	Symbol           string `json:"symbol"`
+	PositionAmt      string `json:"positionAmt"`
+	EntryPrice       string `json:"entryPrice"`
+	MarkPrice        string `json:"markPrice"`
+	UnRealizedProfit string `json:"unRealizedProfit"`
+	Leverage         string `json:"leverage"`
+	LiquidationPrice string `json:"liquidationPrice"`
+}
 
-	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "LIMIT",
-		"side":         "BUY",
-		"timeInForce":  "GTC",
-		"quantity":     qtyStr,
-		"price":        priceStr,
-	}
+// OpenLong 开多单
+func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return t.OpenLongWithClientID(symbol, quantity, leverage, "")
+}
 
-	body, err := t.request("POST", "/fapi/v3/order", params)
-	if err != nil {
-		return nil, err
-	}
+// OpenLongWithClientID 开多单（携带客户端订单ID，用于幂等防重复执行，为空时由交易所生成）
+func (t *AsterTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
+	return t.openPosition(symbol, "long", quantity, leverage, clientOrderID)
+}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
-	}
+// OpenShort 开空单
+func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return t.OpenShortWithClientID(symbol, quantity, leverage, "")
+}
 
-	return result, nil
+// OpenShortWithClientID 开空单（携带客户端订单ID，用于幂等防重复执行，为空时由交易所生成）
+func (t *AsterTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
+	return t.openPosition(symbol, "short", quantity, leverage, clientOrderID)
 }
 
-// OpenShort 开空单
-func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+// openPosition 开仓：使用限价单模拟市价单（价格偏离1%以确保成交）
+func (t *AsterTrader) openPosition(symbol, side string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
 	// 开仓前先取消所有挂单,防止残留挂单导致仓位叠加
 	if err := t.CancelAllOrders(symbol); err != nil {
 		log.Printf("  ⚠ 取消挂单失败(继续开仓): %v", err)
@@ -605,216 +615,104 @@ func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (
 		return nil, err
 	}
 
-	// 使用限价单模拟市价单（价格设置得稍低一些以确保成交）
-	limitPrice := price * 0.99
-
-	// 格式化价格和数量到正确精度
-	formattedPrice, err := t.formatPrice(symbol, limitPrice)
-	if err != nil {
-		return nil, err
-	}
-	formattedQty, err := t.formatQuantity(symbol, quantity)
-	if err != nil {
-		return nil, err
+	orderSide, limitPrice, label := "BUY", price*1.01, "多"
+	if side == "short" {
+		orderSide, limitPrice, label = "SELL", price*0.99, "空"
 	}
 
-	// 获取精度信息
-	prec, err := t.getPrecision(symbol)
+	quantity = t.ensureMinNotional(symbol, quantity, price)
+	order, qtyStr, err := t.placeLimit(symbol, orderSide, quantity, limitPrice, false, clientOrderID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("开%s仓失败: %w", label, err)
 	}
 
-	// 转换为字符串，使用正确的精度格式
-	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
-	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
-
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
-		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
-
-	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "LIMIT",
-		"side":         "SELL",
-		"timeInForce":  "GTC",
-		"quantity":     qtyStr,
-		"price":        priceStr,
-	}
+	log.Printf("✓ 开%s仓成功: %s 数量: %s", label, symbol, qtyStr)
+	log.Printf("  订单ID: %d", order.OrderID)
 
-	body, err := t.request("POST", "/fapi/v3/order", params)
-	if err != nil {
-		return nil, err
-	}
+	return order.toResult(), nil
+}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
-	}
+// CloseLong 平多单
+func (t *AsterTrader) CloseLong(symbol string, quantity float64) (*OrderResult, error) {
+	return t.CloseLongWithClientID(symbol, quantity, "")
+}
 
-	return result, nil
+// CloseLongWithClientID 平多单（携带客户端订单ID，用于幂等防重复执行，为空时由交易所生成）
+func (t *AsterTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (*OrderResult, error) {
+	return t.closePosition(symbol, "long", quantity, clientOrderID)
 }
 
-// CloseLong 平多单
-func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
-	// 如果数量为0，获取当前持仓数量
-	if quantity == 0 {
-		positions, err := t.GetPositions()
-		if err != nil {
-			return nil, err
-		}
+// CloseShort 平空单
+func (t *AsterTrader) CloseShort(symbol string, quantity float64) (*OrderResult, error) {
+	return t.CloseShortWithClientID(symbol, quantity, "")
+}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "long" {
-				quantity = pos["positionAmt"].(float64)
-				break
-			}
-		}
+// CloseShortWithClientID 平空单（携带客户端订单ID，用于幂等防重复执行，为空时由交易所生成）
+func (t *AsterTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (*OrderResult, error) {
+	return t.closePosition(symbol, "short", quantity, clientOrderID)
+}
 
-		if quantity == 0 {
-			return nil, fmt.Errorf("没有找到 %s 的多仓", symbol)
-		}
-		log.Printf("  📊 获取到多仓数量: %.8f", quantity)
+// closePosition 平仓（quantity=0表示全部平仓），返回realized_pnl并启动动态冷却期
+func (t *AsterTrader) closePosition(symbol, side string, quantity float64, clientOrderID string) (*OrderResult, error) {
+	label := "多"
+	if side == "short" {
+		label = "空"
 	}
 
-	price, err := t.GetMarketPrice(symbol)
-	if err != nil {
+	// 获取持仓（数量为0时取全部数量，同时取入场价用于计算realized_pnl）
+	var entryPrice float64
+	positions, err := t.GetPositions()
+	if err != nil && quantity == 0 {
 		return nil, err
 	}
-
-	limitPrice := price * 0.99
-
-	// 格式化价格和数量到正确精度
-	formattedPrice, err := t.formatPrice(symbol, limitPrice)
-	if err != nil {
-		return nil, err
+	if pos := FindPosition(positions, symbol, side); pos != nil {
+		entryPrice = pos.EntryPrice
+		if quantity == 0 {
+			quantity = pos.Quantity
+			log.Printf("  📊 获取到%s仓数量: %.8f", label, quantity)
+		}
 	}
-	formattedQty, err := t.formatQuantity(symbol, quantity)
-	if err != nil {
-		return nil, err
+	if quantity == 0 {
+		return nil, fmt.Errorf("没有找到 %s 的%s仓", symbol, label)
 	}
 
-	// 获取精度信息
-	prec, err := t.getPrecision(symbol)
+	price, err := t.GetMarketPrice(symbol)
 	if err != nil {
 		return nil, err
 	}
 
-	// 转换为字符串，使用正确的精度格式
-	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
-	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
-
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
-		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
-
-	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "LIMIT",
-		"side":         "SELL",
-		"timeInForce":  "GTC",
-		"quantity":     qtyStr,
-		"price":        priceStr,
+	orderSide, limitPrice := "SELL", price*0.99
+	if side == "short" {
+		orderSide, limitPrice = "BUY", price*1.01
 	}
 
-	body, err := t.request("POST", "/fapi/v3/order", params)
+	order, qtyStr, err := t.placeLimit(symbol, orderSide, quantity, limitPrice, true, clientOrderID)
 	if err != nil {
-		return nil, err
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("平%s仓失败: %w", label, err)
 	}
 
-	log.Printf("✓ 平多仓成功: %s 数量: %s", symbol, qtyStr)
+	log.Printf("✓ 平%s仓成功: %s 数量: %s", label, symbol, qtyStr)
 
 	// 平仓后取消该币种的所有挂单(止损止盈单)
 	if err := t.CancelAllOrders(symbol); err != nil {
 		log.Printf("  ⚠ 取消挂单失败: %v", err)
 	}
 
-	return result, nil
-}
-
-// CloseShort 平空单
-func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
-	// 如果数量为0，获取当前持仓数量
-	if quantity == 0 {
-		positions, err := t.GetPositions()
-		if err != nil {
-			return nil, err
-		}
-
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "short" {
-				// Aster的GetPositions已经将空仓数量转换为正数，直接使用
-				quantity = pos["positionAmt"].(float64)
-				break
+	// 按成交均价计算realized_pnl（Aster未单独追踪手续费，为毛盈亏）
+	realizedPnL := 0.0
+	if entryPrice > 0 {
+		if avgPrice := t.fillPrice(symbol, order); avgPrice > 0 {
+			closedQty, _ := strconv.ParseFloat(qtyStr, 64)
+			realizedPnL = (avgPrice - entryPrice) * closedQty
+			if side == "short" {
+				realizedPnL = -realizedPnL
 			}
+			log.Printf("  💰 平仓盈亏: 入场%.4f → 平仓%.4f | 盈亏%+.2f USDT", entryPrice, avgPrice, realizedPnL)
 		}
-
-		if quantity == 0 {
-			return nil, fmt.Errorf("没有找到 %s 的空仓", symbol)
-		}
-		log.Printf("  📊 获取到空仓数量: %.8f", quantity)
-	}
-
-	price, err := t.GetMarketPrice(symbol)
-	if err != nil {
-		return nil, err
-	}
-
-	limitPrice := price * 1.01
-
-	// 格式化价格和数量到正确精度
-	formattedPrice, err := t.formatPrice(symbol, limitPrice)
-	if err != nil {
-		return nil, err
-	}
-	formattedQty, err := t.formatQuantity(symbol, quantity)
-	if err != nil {
-		return nil, err
-	}
-
-	// 获取精度信息
-	prec, err := t.getPrecision(symbol)
-	if err != nil {
-		return nil, err
 	}
 
-	// 转换为字符串，使用正确的精度格式
-	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
-	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
-
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
-		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
-
-	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "LIMIT",
-		"side":         "BUY",
-		"timeInForce":  "GTC",
-		"quantity":     qtyStr,
-		"price":        priceStr,
-	}
-
-	body, err := t.request("POST", "/fapi/v3/order", params)
-	if err != nil {
-		return nil, err
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
-	}
-
-	log.Printf("✓ 平空仓成功: %s 数量: %s", symbol, qtyStr)
-
-	// 平仓后取消该币种的所有挂单(止损止盈单)
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败: %v", err)
-	}
+	result := order.toResult()
+	result.RealizedPnL = realizedPnL
 
 	return result, nil
 }
@@ -857,90 +755,102 @@ func (t *AsterTrader) GetMarketPrice(symbol string) (float64, error) {
 	return strconv.ParseFloat(priceStr, 64)
 }
 
-// SetStopLoss 设置止损
+// SetStopLoss 设置止损（与币安一致：closePosition触发时平掉整个仓位，按合约最新价触发）
 func (t *AsterTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	stopPriceStr, err := t.placeProtectiveOrder(symbol, positionSide, "STOP_MARKET", stopPrice)
+	if err != nil {
+		return fmt.Errorf("设置止损失败: %w", err)
+	}
+
+	log.Printf("  止损价设置: %s", stopPriceStr)
+	return nil
+}
+
+// SetTakeProfit 设置止盈（与币安一致：closePosition触发时平掉整个仓位，按合约最新价触发）
+func (t *AsterTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	takeProfitPriceStr, err := t.placeProtectiveOrder(symbol, positionSide, "TAKE_PROFIT_MARKET", takeProfitPrice)
+	if err != nil {
+		return fmt.Errorf("设置止盈失败: %w", err)
+	}
+
+	log.Printf("  止盈价设置: %s", takeProfitPriceStr)
+	return nil
+}
+
+// SetPartialTakeProfit 🪜 设置只减仓指定数量的止盈单（分批止盈，不使用closePosition）
+func (t *AsterTrader) SetPartialTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
 	side := "SELL"
 	if positionSide == "SHORT" {
 		side = "BUY"
 	}
 
-	// 格式化价格和数量到正确精度
-	formattedPrice, err := t.formatPrice(symbol, stopPrice)
-	if err != nil {
-		return err
-	}
-	formattedQty, err := t.formatQuantity(symbol, quantity)
+	priceStr, err := t.FormatPrice(symbol, takeProfitPrice)
 	if err != nil {
 		return err
 	}
-
-	// 获取精度信息
-	prec, err := t.getPrecision(symbol)
+	quantityStr, err := t.FormatQuantity(symbol, quantity)
 	if err != nil {
 		return err
 	}
 
-	// 转换为字符串，使用正确的精度格式
-	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
-	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
-
 	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "STOP_MARKET",
-		"side":         side,
-		"stopPrice":    priceStr,
-		"quantity":     qtyStr,
-		"timeInForce":  "GTC",
+		"symbol":           symbol,
+		"positionSide":     "BOTH",
+		"type":             "TAKE_PROFIT_MARKET",
+		"side":             side,
+		"stopPrice":        priceStr,
+		"quantity":         quantityStr,
+		"reduceOnly":       "true",
+		"workingType":      "CONTRACT_PRICE",
+		"newClientOrderId": botOrderID("tpl"),
+	}
+	if _, err := t.request("POST", "/fapi/v3/order", params); err != nil {
+		return fmt.Errorf("设置分批止盈失败: %w", err)
 	}
 
-	_, err = t.request("POST", "/fapi/v3/order", params)
-	return err
+	log.Printf("  分批止盈设置: %s 数量: %s", priceStr, quantityStr)
+	return nil
 }
 
-// SetTakeProfit 设置止盈
-func (t *AsterTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+// placeProtectiveOrder 下止损/止盈条件单，返回格式化后的触发价
+func (t *AsterTrader) placeProtectiveOrder(symbol, positionSide, orderType string, triggerPrice float64) (string, error) {
 	side := "SELL"
 	if positionSide == "SHORT" {
 		side = "BUY"
 	}
 
-	// 格式化价格和数量到正确精度
-	formattedPrice, err := t.formatPrice(symbol, takeProfitPrice)
+	priceStr, err := t.FormatPrice(symbol, triggerPrice)
 	if err != nil {
-		return err
-	}
-	formattedQty, err := t.formatQuantity(symbol, quantity)
-	if err != nil {
-		return err
+		return "", err
 	}
 
-	// 获取精度信息
-	prec, err := t.getPrecision(symbol)
-	if err != nil {
-		return err
+	kind := "sl"
+	if orderType == "TAKE_PROFIT_MARKET" {
+		kind = "tp"
 	}
-
-	// 转换为字符串，使用正确的精度格式
-	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
-	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
-
 	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "TAKE_PROFIT_MARKET",
-		"side":         side,
-		"stopPrice":    priceStr,
-		"quantity":     qtyStr,
-		"timeInForce":  "GTC",
+		"symbol":           symbol,
+		"positionSide":     "BOTH",
+		"type":             orderType,
+		"side":             side,
+		"stopPrice":        priceStr,
+		"closePosition":    "true",
+		"workingType":      "CONTRACT_PRICE",
+		"newClientOrderId": botOrderID(kind), // 🏷️ 撤单时只撤本系统的挂单
 	}
 
-	_, err = t.request("POST", "/fapi/v3/order", params)
-	return err
+	if _, err := t.request("POST", "/fapi/v3/order", params); err != nil {
+		return "", err
+	}
+	return priceStr, nil
 }
 
-// CancelAllOrders 取消所有订单
+// CancelAllOrders 取消本系统下的订单（配置 cancel_all_orders 时取消全部订单）
 func (t *AsterTrader) CancelAllOrders(symbol string) error {
+	if !t.cancelAllOrders {
+		return t.cancelBotOrders(symbol)
+	}
+
 	params := map[string]interface{}{
 		"symbol": symbol,
 	}
@@ -955,5 +865,9 @@ func (t *AsterTrader) FormatQuantity(symbol string, quantity float64) (string, e
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("%v", formatted), nil
+	prec, err := t.getPrecision(symbol)
+	if err != nil {
+		return "", err
+	}
+	return t.formatFloatWithPrecision(formatted, prec.QuantityPrecision), nil
 }
@@ -4,12 +4,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"nofx/decision"
+	"nofx/decision/agents"
+	"nofx/decision/prompts"
+	"nofx/events"
+	"nofx/i18n"
 	"nofx/logger"
 	"nofx/market"
 	"nofx/mcp"
 	"nofx/memory"
 	"nofx/pool"
+	"nofx/ratelimit"
 	"os"
 	"path/filepath"
 	"runtime/debug"
@@ -26,7 +32,7 @@ type AutoTraderConfig struct {
 	QwenModel string // Qwen模型具体版本（qwen-plus/qwen-max等）
 
 	// 交易平台选择
-	Exchange string // "binance", "hyperliquid" 或 "aster"
+	Exchange string // "binance", "binance_coinm", "hyperliquid", "aster" 或 "bybit"
 
 	// 币安API配置
 	BinanceAPIKey    string
@@ -43,6 +49,11 @@ type AutoTraderConfig struct {
 	AsterSigner     string // Aster API钱包地址
 	AsterPrivateKey string // Aster API钱包私钥
 
+	// Bybit配置
+	BybitAPIKey    string
+	BybitSecretKey string
+	BybitTestnet   bool
+
 	CoinPoolAPIURL string
 
 	// AI配置
@@ -56,9 +67,13 @@ type AutoTraderConfig struct {
 	CustomModelName string
 
 	// 扫描配置
-	ScanInterval time.Duration // 扫描间隔（建议3分钟）
+	ScanInterval  time.Duration // 扫描间隔（建议3分钟）
 	KlineInterval string        // K线周期（如 "5m", "10m", "15m"）
 
+	// 🕰️ 长期背景/入场时机检查的K线周期（空=与KlineInterval相同）
+	LongTermInterval    string
+	EntryTimingInterval string
+
 	// 账户配置
 	InitialBalance float64 // 初始金额（用于计算盈亏，需手动设置）
 
@@ -71,8 +86,166 @@ type AutoTraderConfig struct {
 	MaxDrawdown     float64       // 最大回撤百分比（提示）
 	StopTradingTime time.Duration // 触发风控后暂停时长
 
+	// 🧯 组合止损：净值从高水位回撤超限时暂停（可选全部平仓），独立冷却期
+	PortfolioStop PortfolioStopConfig
+
+	// 🌙 定时空仓窗口：窗口内平掉全部持仓并拒绝开仓（如周末避险）
+	FlatWindows []FlatWindowConfig
+
+	// 🚦 过度交易保护：最近N笔期望为负时提高开仓门槛、延长冷却期（未配置时不启用）
+	OvertradingGuard OvertradingGuardConfig
+
+	// 🔀 决策一致性检查：价格几乎没动时高信心反向开仓需要更高信心度（默认启用）
+	Contradiction ContradictionConfig
+
+	// 🧐 大仓位自我审查：名义价值或杠杆达到阈值的开仓下单前再调用一次AI找问题（未配置时不审查）
+	SelfCritique SelfCritiqueConfig
+
+	// 🎯 按AI信心度调整仓位（未配置时不调整）
+	ConfidenceSizing ConfidenceSizingConfig
+
 	// 限价单模式
 	UseLimitOrders bool // 是否使用限价单模式（默认false=市价单）
+
+	// 👁️ 观察模式：完整运行决策流程（行情、预测、决策、风控检查），但不下单，决策记录为假设执行
+	ObserveMode bool
+
+	// 🆕 盈亏对账（交易所收益流水 vs 内部账本）
+	PnLReconcileInterval time.Duration // 对账间隔（0=默认1小时，<0=禁用）
+
+	// 🆕 启动对账：无法匹配到决策记录的未知持仓如何处理（adopt=接管, close=平仓, ignore=忽略），默认adopt
+	UnknownPositionPolicy string
+
+	// 🕵️ 账户异常检测：钱包余额无法解释的下降阈值（%，0=默认2%，<0=不检测余额）
+	AnomalyBalanceDropPct float64
+
+	// 🔒 提现/划转锁：没有交易时可用余额下降超过该比例(%)暂停开新仓直到确认（0=默认5%，<0=不启用）；Ack=启动时确认解除已有的锁
+	WithdrawalLockPct float64
+	WithdrawalLockAck bool
+
+	// 🛡️ 单笔最大风险（USDT，仓位×止损距离），超过时缩仓，0=不限制
+	MaxRiskPerTradeUSD float64
+
+	// 🎯 按止损距离推导杠杆：止损触发时亏损保证金的该比例（%），0=使用AI给出的杠杆
+	StopMarginLossPct float64
+
+	// ⏱️ 价格过期保护：预测快照到下单之间价格变动超过止损距离的该比例时放弃开仓（0=默认0.5，<0=不检查）
+	StalePriceStopFraction float64
+
+	// 📏 止损止盈距当前价的最小距离（价格步进数，0=默认2；百分比，0=不限制）及不足时的处理（adjust/reject）
+	MinStopTicks       int
+	MinStopDistancePct float64
+	StopDistanceAction string
+
+	// ⌛ 最长持仓时间（按市场体制区分，0=默认趋势24小时/震荡8小时，<0=不限制）
+	MaxHoldTrend time.Duration
+	MaxHoldRange time.Duration
+
+	// ⌛ AI调用超时：单次请求超时（0=默认240秒）；周期时间预算（0=扫描间隔的80%，<0=不限制）
+	AITimeout   time.Duration
+	CycleBudget time.Duration
+
+	// 🗳️ 多模型集成预测：2-3个模型并行预测，按多数投票（majority）或历史准确率加权（weighted）合并
+	EnsembleModels []EnsembleModelConfig
+	EnsembleMode   string
+
+	// 🚨 山寨币异动信号注入决策（启用山寨币扫描，高置信信号作为额外候选来源）
+	AltcoinSignals bool
+
+	// 🪙 候选币种的计价币种偏好（按顺序选第一个处于交易状态的合约，空=只用USDT）
+	QuoteAssets []string
+
+	// 📐 现货期货基差策略（nil=不启用；独立风险预算，策略币种不交给AI）
+	Basis *BasisConfig
+
+	// 🎚️ 保证金模式与杠杆策略（nil=全部逐仓，杠杆仅受交易所档位约束）
+	LeveragePolicy *LeveragePolicy
+
+	// 📐 组合仓位分配：""（逐个计算）、"risk_parity" 或 "equal_weight"；单周期最多新开仓数量（0=1个）
+	PositionAllocation      string
+	MaxNewPositionsPerCycle int
+
+	// 🎟️ 冷却期豁免：每日最多次数（0=不允许）和最低信心度（0=默认85）
+	CooldownOverridesPerDay       int
+	CooldownOverrideMinConfidence int
+
+	// 🎚️ 按类别（majors/alts）和币种覆盖的冷却期/最短持仓/开仓次数；主流币列表（空=BTCUSDT、ETHUSDT）
+	ConstraintOverrides map[string]SymbolConstraints
+	MajorSymbols        []string
+
+	// 🗜️ 决策日志归档：N天前的记录按月压缩归档（0=不归档），归档保留天数（0=永久保留）
+	DecisionLogArchiveDays   int
+	DecisionLogRetentionDays int
+
+	// 🔗 决策记录审计哈希链（每条记录包含上一条记录的哈希）
+	AuditChain bool
+
+	// 🏦 账户级别（按净值选择，风控阈值和记忆保本区间随账户规模调整；空=默认阈值）
+	AccountTiers []AccountTierConfig
+
+	// 🛡️ 持仓上限：总持仓数、多/空仓币种数、总名义敞口（净值倍数），编排层和执行层同时生效
+	PositionLimits agents.PositionLimits
+
+	// 🎛️ 策略档案（scalper/swing/conservative，空=不使用）及开仓阈值/止损倍数
+	StrategyProfile string
+	Tuning          agents.EntryTuning
+
+	// ⏳ 大额开仓TWAP拆单
+	TWAP TWAPConfig
+
+	// 🧪 故障注入（仅模拟交易）
+	Chaos ChaosConfig
+
+	// 🪜 分批止盈策略（决策没有指定take_profit_ladder时按止损距离的R倍数生成，空=不分批）
+	TakeProfitLadder []TakeProfitRung
+
+	// ⭐ 用户关注币种（不论排名始终加入候选池，可通过gRPC SetWatchlist运行时修改）
+	Watchlist []string
+
+	// 🚦 决策策略规则（AI决策执行前依次检查，可否决或修改决策）
+	DecisionPolicies []PolicyRule
+
+	// 📐 绩效统计窗口（周期数，0=默认100）
+	PerformanceWindow int
+
+	// ⏰ 开仓时机保护（资金费结算前极端费率、宏观事件前后）
+	EntryTiming EntryTimingConfig
+
+	// ⏱️ 自适应扫描间隔（未配置上下限时固定为ScanInterval）
+	AdaptiveScan AdaptiveScanConfig
+
+	// 🪞 决策引擎（multi_agent/monolithic，空=multi_agent）与影子对比引擎（空=不对比，只记录不执行）
+	DecisionEngine string
+	ShadowEngine   string
+
+	// 💸 持仓评估专用模型（nil=与开仓预测共用主模型；仅multi_agent引擎）
+	PositionModel *PositionModelConfig
+
+	// 🔁 备用AI模型（nil=不启用）：主模型连续超时/5xx时从本周期剩余调用开始改用备用模型，冷却后自动切回
+	FallbackModel *FallbackModelConfig
+
+	// 📡 行情数据源（空=币安，hyperliquid=使用Hyperliquid的K线/资金费率/持仓量）
+	MarketDataSource string
+
+	// 💧 候选币种流动性过滤（持仓价值或24h成交额阈值）
+	Liquidity decision.LiquidityFilter
+
+	// 🐕 交易所失联看门狗：持续失联多久判定为中断（0=默认3分钟，<0=禁用）；恢复后是否平掉没有止损单的持仓
+	ConnectivityLossTimeout       time.Duration
+	FlattenUnprotectedOnReconnect bool
+
+	// 🛡️ 止损单巡检间隔（0=默认1分钟，<0=禁用）；连续补设失败多少次后告警（0=默认3）
+	ProtectiveMonitorInterval   time.Duration
+	ProtectiveMonitorAlertAfter int
+
+	// 🏷️ 撤单时撤掉全部挂单（默认只撤本系统下的挂单，不影响手动下的单）
+	CancelAllOrders bool
+
+	// ⏱️ 按币种类别的AI预测频率（主流币/山寨币每N个周期预测一次，持仓管理不受影响）
+	Cadence agents.ScanCadence
+
+	// 🏅 AI预测前的本地候选币种初筛（每周期只预测评分前TopK个）
+	Ranking agents.CandidateRanking
 }
 
 // AutoTrader 自动交易器
@@ -84,6 +257,9 @@ type AutoTrader struct {
 	config                AutoTraderConfig
 	trader                Trader // 使用Trader接口（支持多平台）
 	mcpClient             *mcp.Client
+	ensemble              *agents.Ensemble       // 🗳️ 多模型集成预测（nil=单模型）
+	positionClient        *mcp.Client            // 💸 持仓评估专用模型（nil=使用主模型）
+	marketSource          market.Source          // 📡 决策和下单使用的行情数据源
 	decisionLogger        *logger.DecisionLogger // 决策日志记录器
 	constraints           *TradingConstraints    // 交易硬约束管理器
 	memoryManager         *memory.Manager        // 🧠 记忆管理器（Sprint 1）
@@ -98,13 +274,65 @@ type AutoTrader struct {
 	positionFirstSeenTime map[string]int64 // 持仓首次出现时间 (symbol_side -> timestamp毫秒)
 	lastPositionSnapshot  map[string]decision.PositionInfo
 	manualCloseTracker    map[string]time.Time // 手动/程序主动平仓的时间戳，用于与止损触发区分
+	pnlReconciler         *PnLReconciler       // 🆕 盈亏对账器（仅支持收益流水的交易所）
+	ignoredPositions      map[string]bool      // 🆕 启动对账时选择忽略的未知持仓（symbol_side），不交给AI管理
+	lastAccountSnapshot   *accountSnapshot     // 🕵️ 上一周期账户快照（异常检测）
+	control               controlState         // 🎛️ 外部控制（暂停/风控覆盖/停止唤醒）
 
 	// 山寨币异动扫描（WebSocket方案 - 只观察不交易）
-	altcoinWSMonitor       *market.AltcoinWSMonitor
-	altcoinScanner         *market.AltcoinScanner
-	altcoinLogger          *market.AltcoinSignalLogger
-	spotFuturesMonitor     *market.SpotFuturesMonitor  // 现货期货价差监控
-	altcoinScanEnabled     bool // 是否启用山寨币扫描
+	altcoinWSMonitor   *market.AltcoinWSMonitor
+	altcoinScanner     *market.AltcoinScanner
+	altcoinLogger      *market.AltcoinSignalLogger
+	spotFuturesMonitor *market.SpotFuturesMonitor // 现货期货价差监控
+	altcoinScanEnabled bool                       // 是否启用山寨币扫描
+	altcoinSignals     *altcoinSignalBuffer       // 🚨 待注入决策的异动信号（未启用时为nil）
+
+	basis *BasisStrategy // 📐 现货期货基差策略（未启用时为nil）
+
+	events *events.Bus // 📣 交易器事件总线（开平仓、止损触发、拒单、风控暂停）
+
+	watchdog  *connectivityWatchdog // 🐕 交易所连通性看门狗
+	stopGuard *protectiveMonitor    // 🛡️ 止损单巡检
+
+	watchlist watchlist // ⭐ 用户置顶关注的币种（始终加入候选池）
+
+	accountTier accountTierState // 🏦 当前生效的账户级别
+
+	tradeIdeas *tradeIdeaInbox // 📥 webhook推送的外部交易想法（下一周期注入候选）
+
+	precisionState precisionPrefetchState // 📐 交易对精度预取状态
+
+	fallbackState fallbackPolicyState // 🛟 AI决策失败时的兜底策略统计
+
+	policies []DecisionPolicy // 🚦 本trader的决策策略（全局策略见 RegisterDecisionPolicy）
+
+	equity *EquityCurve // 📈 持久化净值曲线（日收益/回撤风控）
+
+	lastPerformance *logger.PerformanceAnalysis // 📐 最近一次绩效分析（状态API）
+
+	calendar *eventCalendar // 📅 宏观事件日历（未配置时为nil）
+
+	adaptive adaptiveScanState // ⏱️ 自适应扫描间隔
+
+	shadow *ShadowEvaluator // 🪞 影子引擎对比（未启用时为nil）
+
+	portfolioStop *portfolioStop // 🧯 组合止损高水位（未启用时为nil）
+
+	overtrading overtradingGuard // 🚦 过度交易保护状态
+
+	contradictions *contradictionChecker // 🔀 上一周期各币种的开仓方向（决策一致性检查）
+
+	withdrawal *withdrawalLock // 🔒 提现/划转锁（未启用时为nil）
+
+	journal *executionJournal // 🧾 执行预写日志
+
+	flatWindows []flatWindow    // 🌙 定时空仓窗口
+	flatState   flatWindowState // 🌙 空仓窗口通知/执行状态
+
+	snapshot     priceSnapshot    // ⏱️ 本周期预测所依据的价格（下单前价格过期检查）
+	cycleTimings cycleTimingStats // ⏱️ 最近周期的阶段耗时
+
+	exchangeState exchangeStatus // 🚧 交易所维护状态与交易对状态
 }
 
 // NewAutoTrader 创建自动交易器
@@ -124,6 +352,21 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 		}
 	}
 
+	// 🎛️ 策略档案：补全未显式配置的扫描周期、K线周期、开仓阈值等
+	if err := applyStrategyProfile(&config); err != nil {
+		return nil, err
+	}
+	if config.ScanInterval <= 0 {
+		config.ScanInterval = 3 * time.Minute
+	}
+	config.AccountTiers = sortAccountTiers(config.AccountTiers)
+	if config.KlineInterval == "" {
+		config.KlineInterval = "5m"
+	}
+	if config.StrategyProfile != "" {
+		log.Printf("🎛️  [%s] 策略档案: %s | 扫描%v | K线%s", config.Name, config.StrategyProfile, config.ScanInterval, config.KlineInterval)
+	}
+
 	mcpClient := mcp.New()
 
 	// 初始化AI
@@ -143,6 +386,47 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 		mcpClient.SetDeepSeekAPIKey(config.DeepSeekKey)
 		log.Printf("🤖 [%s] 使用DeepSeek AI", config.Name)
 	}
+	if config.AITimeout > 0 {
+		mcpClient.Timeout = config.AITimeout
+		log.Printf("⌛ [%s] AI单次调用超时: %v", config.Name, config.AITimeout)
+	}
+
+	// 🔁 备用AI模型（持仓评估沿用主模型provider时共享健康状态）
+	failover, failoverErr := newFailover(mcpClient, config.FallbackModel)
+	if failoverErr != nil {
+		return nil, fmt.Errorf("初始化备用AI模型失败: %w", failoverErr)
+	}
+	if failover != nil {
+		mcpClient.Failover = failover
+		log.Printf("🔁 [%s] 备用AI模型: %s (%s)", config.Name, failover.Secondary.Provider, failover.Secondary.Model)
+	}
+
+	// 🗳️ 多模型集成预测
+	ensemble, ensembleErr := newEnsemble(config.EnsembleModels, config.EnsembleMode, config.AITimeout)
+	if ensembleErr != nil {
+		return nil, fmt.Errorf("初始化集成预测失败: %w", ensembleErr)
+	}
+	if ensemble != nil {
+		log.Printf("🗳️  [%s] 启用多模型集成预测: %d个模型 (%s投票)", config.Name, len(ensemble.Members), ensemble.Mode)
+	}
+
+	// 💸 持仓评估专用模型
+	positionClient, positionErr := newPositionClient(mcpClient, config.PositionModel)
+	if positionErr != nil {
+		return nil, fmt.Errorf("初始化持仓评估模型失败: %w", positionErr)
+	}
+	if positionClient != nil {
+		log.Printf("💸 [%s] 持仓评估使用独立模型: %s (超时%v, max_tokens=%d)", config.Name, positionClient.Model, positionClient.Timeout, positionClient.MaxTokens)
+	}
+
+	// 📡 行情数据源
+	marketSource, sourceErr := market.SourceFor(config.MarketDataSource)
+	if sourceErr != nil {
+		return nil, sourceErr
+	}
+	if marketSource.Name() != market.SourceBinance {
+		log.Printf("📡 [%s] 行情数据源: %s", config.Name, marketSource.Name())
+	}
 
 	// 初始化币种池API
 	if config.CoinPoolAPIURL != "" {
@@ -162,6 +446,9 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 	case "binance":
 		log.Printf("🏦 [%s] 使用币安合约交易", config.Name)
 		trader = NewFuturesTrader(config.BinanceAPIKey, config.BinanceSecretKey, config.BinanceTestnet)
+	case "binance_coinm":
+		log.Printf("🏦 [%s] 使用币安币本位合约交易（保证金与盈亏以币结算）", config.Name)
+		trader = NewCoinMFuturesTrader(config.BinanceAPIKey, config.BinanceSecretKey, config.BinanceTestnet)
 	case "hyperliquid":
 		log.Printf("🏦 [%s] 使用Hyperliquid交易", config.Name)
 		trader, err = NewHyperliquidTrader(config.HyperliquidPrivateKey, config.HyperliquidWalletAddr, config.HyperliquidTestnet)
@@ -174,13 +461,32 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 		if err != nil {
 			return nil, fmt.Errorf("初始化Aster交易器失败: %w", err)
 		}
+	case "bybit":
+		log.Printf("🏦 [%s] 使用Bybit交易", config.Name)
+		trader, err = NewBybitTrader(config.BybitAPIKey, config.BybitSecretKey, config.BybitTestnet)
+		if err != nil {
+			return nil, fmt.Errorf("初始化Bybit交易器失败: %w", err)
+		}
 	case "mock":
 		log.Printf("🧪 [%s] 使用本地模拟交易（真实市场数据）", config.Name)
 		trader = NewMockTrader(config.InitialBalance)
+		if config.Chaos.Enabled() {
+			log.Printf("🧪 [%s] 故障注入已启用: 错误率%.0f%% | 最大延迟%v | 部分成交率%.0f%%",
+				config.Name, config.Chaos.ErrorRate*100, config.Chaos.MaxLatency, config.Chaos.PartialFillRate*100)
+			trader = NewChaosTrader(trader, config.Chaos)
+		}
 	default:
 		return nil, fmt.Errorf("不支持的交易平台: %s", config.Exchange)
 	}
 
+	// 🏷️ 撤单范围（默认只撤本系统下的挂单）
+	if scoped, ok := trader.(orderScopeSetter); ok {
+		scoped.SetCancelAllOrders(config.CancelAllOrders)
+		if config.CancelAllOrders {
+			log.Printf("⚠️  [%s] cancel_all_orders已启用: 撤单时会撤掉手动下的挂单", config.Name)
+		}
+	}
+
 	// 验证初始金额配置
 	if config.InitialBalance <= 0 {
 		return nil, fmt.Errorf("初始金额必须大于0，请在配置中设置InitialBalance")
@@ -189,10 +495,34 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 	// 初始化决策日志记录器（使用trader ID创建独立目录）
 	logDir := fmt.Sprintf("decision_logs/%s", config.ID)
 	decisionLogger := logger.NewDecisionLogger(logDir)
+	if config.AuditChain {
+		decisionLogger.EnableAuditChain()
+	}
 
 	// 初始化交易硬约束管理器
 	constraints := NewTradingConstraints()
+	constraints.SetMaxPositions(config.PositionLimits.Total())
 	log.Printf("🛡️ [%s] 硬约束已启用: 冷却期20分钟 | 日上限999次 | 时上限3次 | 最短持仓15分钟", config.Name)
+	log.Printf("🛡️ [%s] 持仓上限: 总数%d | 多仓%d | 空仓%d", config.Name,
+		config.PositionLimits.Total(), config.PositionLimits.Side("long"), config.PositionLimits.Side("short"))
+	if config.Cadence.Enabled() {
+		log.Printf("⏱️ [%s] AI预测频率: 主流币每%d个周期，山寨币每%d个周期", config.Name,
+			max(config.Cadence.MajorEvery, 1), max(config.Cadence.AltEvery, 1))
+	}
+	if config.Ranking.Enabled() {
+		log.Printf("🏅 [%s] 候选币种本地初筛: 每周期最多%d个交给AI预测", config.Name, config.Ranking.TopK)
+	}
+	if config.PositionLimits.MaxNotionalMultiple > 0 {
+		log.Printf("🛡️ [%s] 总名义敞口上限: %.1f倍净值", config.Name, config.PositionLimits.MaxNotionalMultiple)
+	}
+	if config.CooldownOverridesPerDay > 0 {
+		constraints.SetCooldownOverridePolicy(config.CooldownOverridesPerDay, config.CooldownOverrideMinConfidence)
+		log.Printf("🎟️ [%s] 冷却期豁免已启用: 每日最多%d次", config.Name, config.CooldownOverridesPerDay)
+	}
+	if len(config.ConstraintOverrides) > 0 {
+		constraints.SetSymbolOverrides(config.MajorSymbols, config.ConstraintOverrides)
+		log.Printf("🎚️ [%s] 硬约束覆盖: %d项（类别/币种）", config.Name, len(config.ConstraintOverrides))
+	}
 
 	// 🧠 初始化AI记忆系统（Sprint 1）
 	memoryManager, err := memory.NewManager(config.ID)
@@ -208,7 +538,8 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 	var altcoinScanner *market.AltcoinScanner
 	var altcoinLogger *market.AltcoinSignalLogger
 	var spotFuturesMonitor *market.SpotFuturesMonitor // 🆕 现货期货价差监控
-	altcoinScanEnabled := false // 🔧 禁用WebSocket方案（减少服务器压力）
+	altcoinScanEnabled := config.AltcoinSignals       // 🔧 默认禁用WebSocket方案（减少服务器压力），开启信号注入时启用
+	var altcoinSignals *altcoinSignalBuffer
 
 	if config.Exchange == "binance" && altcoinScanEnabled {
 		// 获取Binance客户端
@@ -237,14 +568,42 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 					altcoinWSMonitor,
 				)
 				log.Printf("📊 [%s] 现货期货价差监控已启用（捕捉DEX/现货先行信号）", config.Name)
+
+				altcoinSignals = newAltcoinSignalBuffer()
+				log.Printf("🚨 [%s] 山寨币异动信号将注入决策候选（来源标签: %s）", config.Name, altcoinSignalSource)
 			}
 		}
 	}
 
 	// 🎯 设置全局K线周期（根据配置）
 	market.SetDefaultInterval(config.KlineInterval)
+	market.SetContextIntervals(config.LongTermInterval, config.EntryTimingInterval)
+
+	// 🎚️ 按币种保证金模式
+	if setter, ok := trader.(MarginModeSetter); ok && config.LeveragePolicy != nil {
+		setter.SetMarginModePolicy(config.LeveragePolicy.MarginMode)
+		log.Printf("🎚️ [%s] 保证金模式策略: 默认%s，%d个币种覆盖",
+			config.Name, config.LeveragePolicy.For("").MarginMode, len(config.LeveragePolicy.Symbols))
+	}
 
-	return &AutoTrader{
+	// 🧾 初始化盈亏对账器（交易所支持收益流水时启用）
+	var pnlReconciler *PnLReconciler
+	if provider, ok := trader.(IncomeHistoryProvider); ok && config.PnLReconcileInterval >= 0 {
+		pnlReconciler = NewPnLReconciler(provider, decisionLogger, memoryManager, config.PnLReconcileInterval)
+		log.Printf("🧾 [%s] 盈亏对账已启用（间隔%v）", config.Name, pnlReconciler.interval)
+	}
+
+	// 📐 现货期货基差策略
+	var basis *BasisStrategy
+	if config.Basis != nil {
+		basis, err = NewBasisStrategy(*config.Basis, trader, config.ID, config.ObserveMode)
+		if err != nil {
+			return nil, fmt.Errorf("初始化基差策略失败: %w", err)
+		}
+		log.Printf("📐 [%s] 基差策略已启用: %s %v", config.Name, config.Basis.Mode, config.Basis.Symbols)
+	}
+
+	at := &AutoTrader{
 		id:                    config.ID,
 		name:                  config.Name,
 		aiModel:               config.AIModel,
@@ -252,6 +611,9 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 		config:                config,
 		trader:                trader,
 		mcpClient:             mcpClient,
+		ensemble:              ensemble,
+		positionClient:        positionClient,
+		marketSource:          marketSource,
 		decisionLogger:        decisionLogger,
 		constraints:           constraints,
 		memoryManager:         memoryManager,     // 🧠 记忆系统
@@ -264,24 +626,112 @@ func NewAutoTrader(config AutoTraderConfig) (*AutoTrader, error) {
 		positionFirstSeenTime: make(map[string]int64),
 		lastPositionSnapshot:  make(map[string]decision.PositionInfo),
 		manualCloseTracker:    make(map[string]time.Time),
-		altcoinWSMonitor:      altcoinWSMonitor,      // WebSocket监控器
-		altcoinScanner:        altcoinScanner,        // 山寨币扫描器
-		altcoinLogger:         altcoinLogger,         // 信号日志器
-		spotFuturesMonitor:    spotFuturesMonitor,    // 🆕 现货期货价差监控
+		ignoredPositions:      make(map[string]bool),
+		pnlReconciler:         pnlReconciler,
+		altcoinWSMonitor:      altcoinWSMonitor,   // WebSocket监控器
+		altcoinScanner:        altcoinScanner,     // 山寨币扫描器
+		altcoinLogger:         altcoinLogger,      // 信号日志器
+		spotFuturesMonitor:    spotFuturesMonitor, // 🆕 现货期货价差监控
 		altcoinScanEnabled:    altcoinScanEnabled,
-	}, nil
+		altcoinSignals:        altcoinSignals,
+		basis:                 basis,
+		events:                events.NewBus(),
+		watchdog:              newConnectivityWatchdog(),
+		stopGuard:             newProtectiveMonitor(),
+		policies:              policyRulesFromConfig(config.DecisionPolicies),
+		equity:                NewEquityCurve(logDir),
+		calendar:              newEventCalendar(config.EntryTiming.EventCalendarFile),
+		contradictions:        newContradictionChecker(logDir),
+		tradeIdeas:            newTradeIdeaInbox(),
+		journal:               newExecutionJournal(logDir),
+		flatState:             flatWindowState{notified: make(map[string]time.Time), active: make(map[string][2]time.Time)},
+	}
+	if config.ShadowEngine != "" {
+		primary := config.DecisionEngine
+		if primary == "" {
+			primary = decision.EngineMultiAgent
+		}
+		at.shadow = NewShadowEvaluator(logDir, primary, config.ShadowEngine)
+		log.Printf("🪞 [%s] 影子引擎对比已启用: 主引擎%s，影子引擎%s（只记录不执行）", config.Name, primary, config.ShadowEngine)
+	}
+	if config.PortfolioStop.Enabled() {
+		at.portfolioStop = newPortfolioStop(logDir)
+		if at.portfolioStop.state.HighWaterMark == 0 {
+			at.portfolioStop.state.HighWaterMark = at.equity.Stats(time.Now()).PeakEquity
+		}
+		if until := at.portfolioStop.pausedUntil(time.Now()); !until.IsZero() {
+			at.stopUntil = until
+			log.Printf("🧯 [%s] 组合止损冷却期中，暂停交易至 %s", config.Name, until.Format("01-02 15:04"))
+		}
+		action := "暂停交易"
+		if config.PortfolioStop.Flatten {
+			action = "暂停并全部平仓"
+		}
+		log.Printf("🧯 [%s] 组合止损: 净值较高水位(%.2f)回撤≥%.1f%%时%s", config.Name,
+			at.portfolioStop.state.HighWaterMark, config.PortfolioStop.DrawdownPct, action)
+	}
+	if config.WithdrawalLockPct >= 0 {
+		at.withdrawal = newWithdrawalLock(logDir)
+		if config.WithdrawalLockAck && at.withdrawal.acknowledge("config") {
+			log.Printf("🔓 [%s] 提现/划转锁已通过配置 withdrawal_lock_ack 确认解除", config.Name)
+		} else if state := at.withdrawal.snapshot(); state.Locked {
+			log.Printf("🔒 [%s] 提现/划转锁生效中（%s 可用余额下降%.2f%%），确认前不开新仓", config.Name, state.Since.Format("01-02 15:04"), state.DropPct)
+		}
+	}
+	if at.flatWindows, err = newFlatWindows(config.FlatWindows); err != nil {
+		return nil, err
+	}
+	at.subscribeMemory()
+	at.subscribeStopMoves()
+	if skipper, ok := at.trader.(trailingSkipper); ok {
+		skipper.SetTrailingSkip(at.isManualPosition)
+	}
+	if basis != nil {
+		basis.SetLedger(at.recordLedger)
+	}
+	if len(config.Watchlist) > 0 {
+		if err := at.SetWatchlist(config.Watchlist); err != nil {
+			return nil, fmt.Errorf("关注列表配置错误: %w", err)
+		}
+	}
+	return at, nil
 }
 
 // Run 运行自动交易主循环
 func (at *AutoTrader) Run() error {
 	at.isRunning = true
-	log.Println("🚀 AI驱动自动交易系统启动")
+	stopCh := make(chan struct{})
+	at.control.mu.Lock()
+	at.control.stopCh = stopCh
+	at.control.loopActive = true
+	at.control.mu.Unlock()
+	defer func() {
+		at.control.mu.Lock()
+		at.control.loopActive = false
+		at.control.mu.Unlock()
+	}()
+
+	i18n.Logf("trader.started")
 	log.Printf("💰 初始余额: %.2f USDT", at.initialBalance)
 	log.Printf("⚙️  扫描间隔: %v", at.config.ScanInterval)
 	log.Println("🤖 AI将全权决定杠杆、仓位大小、止损止盈等参数")
 
+	if at.config.ObserveMode {
+		log.Println("👁️  观察模式已启用：完整运行决策流程，但不会下单（决策记录为假设执行）")
+	}
+
+	// 📐 预取全部交易对精度（首笔订单不再临时下载exchangeInfo），之后每天刷新
+	if at.prefetchPrecisions() {
+		go at.runPrecisionRefresh(stopCh)
+	}
+
+	// 🆕 启动对账：匹配已有持仓与决策记录，恢复开仓时间和止损止盈，处理未知持仓
+	if err := at.reconcileStartupPositions(); err != nil {
+		log.Printf("⚠️  启动持仓对账失败: %v（将继续运行，请手动检查持仓）", err)
+	}
+
 	// 🛡️ 启动时恢复缺失的止损止盈（防止重启导致持仓失去保护）
-	if at.config.UseLimitOrders {
+	if at.config.UseLimitOrders && !at.config.ObserveMode {
 		log.Println("🔧 检查限价单持仓是否有缺失的止损保护...")
 		if err := at.RecoverMissingStopLoss(); err != nil {
 			log.Printf("⚠️  恢复止损失败: %v（将继续运行，但请手动检查持仓）", err)
@@ -303,13 +753,46 @@ func (at *AutoTrader) Run() error {
 		go at.runAltcoinScanner()
 	}
 
-	ticker := time.NewTicker(at.config.ScanInterval)
+	// 📐 基差策略独立运行（不受AI决策周期影响）
+	if at.basis != nil {
+		go at.basis.Run(stopCh)
+	}
+
+	// 🗜️ 决策日志归档（每天执行一次）
+	if at.config.DecisionLogArchiveDays > 0 {
+		go at.runLogRotation(stopCh)
+	}
+
+	// 🐕 交易所失联看门狗
+	if at.connectivityLossThreshold() > 0 {
+		go at.runConnectivityWatchdog(stopCh)
+	}
+
+	// 🛡️ 止损单巡检：移动止损撤单后重设失败、手动误撤等情况下补设止损
+	if at.protectiveMonitorInterval() > 0 && !at.config.ObserveMode {
+		if _, ok := at.trader.(openOrderLister); ok {
+			go at.runProtectiveMonitor(stopCh)
+		}
+	}
+
+	// 🧪 模拟交易：独立监控止损止盈（模拟交易所条件单，不依赖决策周期）
+	if mock, ok := unwrapTrader(at.trader).(*MockTrader); ok {
+		go mock.RunProtection(stopCh)
+	}
+
+	scanInterval := at.config.ScanInterval
+	ticker := time.NewTicker(scanInterval)
 	defer ticker.Stop()
 
-	log.Printf("⏰ 等待第一个决策周期（%v后）...", at.config.ScanInterval)
+	i18n.Logf("trader.waiting_first_cycle", at.config.ScanInterval)
 
 	for at.isRunning {
 		select {
+		case <-stopCh:
+			// Stop() 已将 isRunning 置为 false，立即退出循环
+		case downtime := <-at.watchdog.recovered:
+			// 🐕 交易所失联恢复：立即对账（在主循环中执行，不与决策周期并发）
+			at.recoverFromOutage(downtime)
 		case <-ticker.C:
 			// 🛡️ 添加panic recovery，防止单次执行失败导致整个循环停止
 			func() {
@@ -324,6 +807,13 @@ func (at *AutoTrader) Run() error {
 					log.Printf("❌ 执行失败: %v", err)
 				}
 			}()
+
+			// 🎛️ 策略档案切换或⏱️ 自适应扫描后按新的扫描周期调度
+			if next := at.currentScanInterval(); scanInterval != next {
+				scanInterval = next
+				ticker.Reset(scanInterval)
+				i18n.Logf("trader.interval_adjusted", scanInterval)
+			}
 		}
 	}
 
@@ -343,37 +833,77 @@ func (at *AutoTrader) Run() error {
 // Stop 停止自动交易
 func (at *AutoTrader) Stop() {
 	at.isRunning = false
+	at.control.mu.Lock()
+	if at.control.stopCh != nil {
+		close(at.control.stopCh)
+		at.control.stopCh = nil
+	}
+	at.control.mu.Unlock()
 
 	// 停止WebSocket监控器
 	if at.altcoinWSMonitor != nil {
 		at.altcoinWSMonitor.Stop()
 	}
 
-	log.Println("⏹ 自动交易系统停止")
+	i18n.Logf("trader.stopped")
 }
 
 // runCycle 运行一个交易周期（使用AI全权决策）
 func (at *AutoTrader) runCycle() error {
+	// 🎛️ 外部暂停（gRPC等）：跳过整个决策周期
+	if paused, until := at.PauseState(); paused {
+		if until.IsZero() {
+			i18n.Logf("cycle.paused_manual", at.name)
+		} else {
+			log.Printf("⏸  [%s] 已手动暂停，剩余 %.0f 分钟", at.name, time.Until(until).Minutes())
+		}
+		return nil
+	}
+	// 🐕 交易所失联中：跳过决策周期，等待看门狗确认恢复
+	if at.exchangeOutage() {
+		i18n.Logf("cycle.exchange_unreachable", at.name)
+		return nil
+	}
+	// 🚧 交易所维护中：跳过决策周期，每个周期重新检查
+	if at.exchangeMaintenance() {
+		log.Printf("🚧 [%s] 交易所维护中，跳过本周期", at.name)
+		return nil
+	}
+	at.applyPendingControl()
+	at.applyPendingProfile()
+	at.applyPendingPositionCommands()
+
+	cycleStart := time.Now()
+	timer := newCycleTimer() // ⏱️ 阶段耗时
 	at.callCount++
+	at.mcpClient.Failover.BeginCycle() // 🔁 冷却期已过时本周期重新尝试主模型
 
 	log.Print("\n" + strings.Repeat("=", 70))
-	log.Printf("⏰ %s - AI决策周期 #%d", time.Now().Format("2006-01-02 15:04:05"), at.callCount)
+	i18n.Logf("cycle.header", time.Now().Format("2006-01-02 15:04:05"), at.callCount)
 	log.Print(strings.Repeat("=", 70))
+	at.events.Publish(events.CycleStartedEvent{TraderID: at.id, Cycle: at.callCount, Time: cycleStart})
 
 	// 创建决策记录
 	record := &logger.DecisionRecord{
 		CycleNumber:  at.callCount, // 🔧 修复：使用callCount作为周期号，确保同一周期的多次日志记录使用相同的周期号
 		ExecutionLog: []string{},
 		Success:      true,
+		ObserveMode:  at.config.ObserveMode,
 	}
 
 	// 1. 检查是否需要停止交易
 	if time.Now().Before(at.stopUntil) {
 		remaining := at.stopUntil.Sub(time.Now())
-		log.Printf("⏸ 风险控制：暂停交易中，剩余 %.0f 分钟", remaining.Minutes())
+		i18n.Logf("cycle.risk_paused", remaining.Minutes())
 		record.Success = false
 		record.ErrorMessage = fmt.Sprintf("风险控制暂停中，剩余 %.0f 分钟", remaining.Minutes())
-		at.decisionLogger.LogDecision(record)
+		at.logCycle(record, timer)
+		return nil
+	}
+
+	// 1.1 🌙 定时空仓窗口：平掉全部持仓，不调用AI
+	if at.enforceFlatWindows(record) {
+		at.logCycle(record, timer)
 		return nil
 	}
 
@@ -381,26 +911,54 @@ func (at *AutoTrader) runCycle() error {
 	if time.Since(at.lastResetTime) > 24*time.Hour {
 		at.dailyPnL = 0
 		at.lastResetTime = time.Now()
-		log.Println("📅 日盈亏已重置")
+		i18n.Logf("cycle.daily_reset")
 	}
 
 	// 2.5 检查并更新限价单状态（在AI决策前处理已成交订单）
+	donePrepare := timer.track(stagePrepare)
 	if err := at.checkAndUpdateLimitOrders(); err != nil {
 		log.Printf("⚠️  检查限价单状态失败: %v", err)
 		// 不影响主流程，继续执行
 	}
 
+	// 2.6 🧾 定期与交易所收益流水对账（校正日内盈亏和性能统计）
+	at.reconcilePnLIfDue()
+
+	// 2.7 🕵️ 账户快照对比：外部持仓/杠杆修改标记为外部管理，止损缺失和余额异常告警
+	at.detectAccountAnomalies(record)
+
+	// 2.8 🪜 分批止盈成交跟踪
+	at.trackTakeProfitLadders()
+	donePrepare()
+
 	// 3. 收集交易上下文
-	ctx, err := at.buildTradingContext()
+	ctx, err := at.buildTradingContext(timer)
 	if err != nil {
 		record.Success = false
 		record.ErrorMessage = fmt.Sprintf("构建交易上下文失败: %v", err)
-		at.decisionLogger.LogDecision(record)
+		record.ErrorKind = errorKind(err)
+		at.logCycle(record, timer)
 		return fmt.Errorf("构建交易上下文失败: %w", err)
 	}
 
+	// 3.1 ⏱️ 候选币种市场数据与历史表现分析并发执行
+	if err := at.loadDecisionInputs(ctx, timer); err != nil {
+		record.Success = false
+		record.ErrorMessage = err.Error()
+		record.ErrorKind = errorKind(err)
+		// 🛟 没有市场数据就没有AI决策：到期平仓照常执行，再走兜底策略
+		at.enforcePositionExpiry(ctx, record)
+		at.runFallbackPolicy(err.Error(), record)
+		at.logCycle(record, timer)
+		return err
+	}
+	at.setPriceSnapshot(ctx)
+
+	// 3.5 ⌛ 持仓到期检查（不依赖AI，超过最长持仓时间且未明显盈利的持仓直接平仓）
+	at.enforcePositionExpiry(ctx, record)
+
 	// 🧠 注入AI记忆（Sprint 1）
-	ctx.MemoryPrompt = at.memoryManager.GetContextPrompt()
+	ctx.MemoryPrompt = at.memoryManager.GetContextPrompt(at.quantRegime(ctx))
 
 	// 保存账户状态快照
 	record.AccountState = logger.AccountSnapshot{
@@ -430,57 +988,84 @@ func (at *AutoTrader) runCycle() error {
 		record.CandidateCoins = append(record.CandidateCoins, coin.Symbol)
 	}
 
-	log.Printf("📊 账户净值: %.2f USDT | 可用: %.2f USDT | 持仓: %d",
+	i18n.Logf("cycle.account",
 		ctx.Account.TotalEquity, ctx.Account.AvailableBalance, ctx.Account.PositionCount)
 
+	// 📈 记录净值采样（持久化，重启后日收益和回撤不丢失）
+	at.equity.Record(ctx.Account.TotalEquity, time.Now())
+
+	// 🧯 组合止损（高水位回撤，可选全部平仓）
+	if at.checkPortfolioStop(ctx.Account.TotalEquity, record) {
+		return nil
+	}
+
 	// ✅ 修复: 检查风险控制参数（MaxDailyLoss、MaxDrawdown）
 	if at.config.MaxDailyLoss > 0 || at.config.MaxDrawdown > 0 {
-		// 计算日盈亏百分比
-		dailyPnLPct := 0.0
-		if at.initialBalance > 0 {
-			dailyPnLPct = (at.dailyPnL / at.initialBalance) * 100
-		}
-
-		// 计算最大回撤百分比
-		drawdownPct := 0.0
-		if at.initialBalance > 0 && ctx.Account.TotalEquity < at.initialBalance {
-			drawdownPct = ((at.initialBalance - ctx.Account.TotalEquity) / at.initialBalance) * 100
-		}
+		// 日收益相对UTC当日开盘净值，回撤相对净值曲线高点
+		stats := at.equity.Stats(time.Now())
+		dailyPnLPct := stats.DailyReturnPct
+		drawdownPct := stats.DrawdownPct
 
-		log.Printf("📊 风险监控: 日盈亏%.2f%% (限制%.0f%%) | 回撤%.2f%% (限制%.0f%%)",
-			dailyPnLPct, at.config.MaxDailyLoss, drawdownPct, at.config.MaxDrawdown)
+		i18n.Logf("risk.monitor",
+			dailyPnLPct, at.config.MaxDailyLoss, drawdownPct, at.config.MaxDrawdown,
+			stats.WeeklyReturnPct, stats.MaxDrawdownPct, stats.Sharpe)
 
 		// 检查日亏损限制
 		if at.config.MaxDailyLoss > 0 && dailyPnLPct < -at.config.MaxDailyLoss {
 			at.stopUntil = time.Now().Add(at.config.StopTradingTime)
-			log.Printf("🛑 风险控制触发: 日亏损%.2f%% 超过限制%.0f%%, 暂停交易%.0f分钟",
+			i18n.Logf("risk.daily_loss_triggered",
 				dailyPnLPct, at.config.MaxDailyLoss, at.config.StopTradingTime.Minutes())
 			record.Success = false
-			record.ErrorMessage = fmt.Sprintf("日亏损%.2f%% 超限，暂停交易", dailyPnLPct)
-			at.decisionLogger.LogDecision(record)
+			record.ErrorMessage = i18n.T("risk.daily_loss_paused", dailyPnLPct)
+			at.events.Publish(events.RiskPausedEvent{TraderID: at.id, Reason: record.ErrorMessage, Until: at.stopUntil, Time: time.Now()})
+			at.logCycle(record, timer)
 			return nil
 		}
 
 		// 检查最大回撤限制
 		if at.config.MaxDrawdown > 0 && drawdownPct > at.config.MaxDrawdown {
 			at.stopUntil = time.Now().Add(at.config.StopTradingTime)
-			log.Printf("🛑 风险控制触发: 回撤%.2f%% 超过限制%.0f%%, 暂停交易%.0f分钟",
+			i18n.Logf("risk.drawdown_triggered",
 				drawdownPct, at.config.MaxDrawdown, at.config.StopTradingTime.Minutes())
 			record.Success = false
-			record.ErrorMessage = fmt.Sprintf("回撤%.2f%% 超限，暂停交易", drawdownPct)
-			at.decisionLogger.LogDecision(record)
+			record.ErrorMessage = i18n.T("risk.drawdown_paused", drawdownPct)
+			at.events.Publish(events.RiskPausedEvent{TraderID: at.id, Reason: record.ErrorMessage, Until: at.stopUntil, Time: time.Now()})
+			at.logCycle(record, timer)
 			return nil
 		}
 	}
 
 	// 4. 调用AI获取完整决策
-	log.Println("🤖 正在请求AI分析并决策...")
-	decision, err := decision.GetFullDecision(ctx, at.mcpClient)
+	i18n.Logf("cycle.requesting_ai")
+	if budget := at.cycleBudget(); budget > 0 {
+		ctx.Deadline = cycleStart.Add(budget) // ⌛ 周期时间预算
+	}
+	ctx.Ensemble = at.ensemble
+	ctx.PositionClient = at.positionClient
+	ctx.Allocation = at.config.PositionAllocation
+	ctx.MaxNewPositions = at.config.MaxNewPositionsPerCycle
+	ctx.PositionLimits = at.config.PositionLimits
+	ctx.RiskGate = at.updateAccountTier(ctx.Account.TotalEquity) // 🏦 按净值选择账户级别
+	ctx.Tuning = at.entryTuning()                                // 🚦 过度交易保护生效时提高门槛
+	ctx.Cadence = at.config.Cadence
+	ctx.Ranking = at.config.Ranking
+	record.PromptVersion = prompts.Version()
+	doneAI := timer.track(stageAI)
+	decision, err := decision.DecideWithEngine(at.config.DecisionEngine, ctx, at.mcpClient)
+	doneAI()
+	record.FilteredSymbols = ctx.FilteredSymbols // 💧 因流动性不足未分析的候选币种
 
 	// 即使有错误，也保存思维链、决策和输入prompt（用于debug）
 	if decision != nil {
 		record.InputPrompt = decision.UserPrompt
 		record.CoTTrace = decision.CoTTrace
+		record.Attributions = decision.Attributions
+		at.publishPredictions(decision.Attributions)
+		if decision.TimedOut {
+			record.AITimedOut = true
+			record.ExecutionLog = append(record.ExecutionLog,
+				fmt.Sprintf("⌛ AI决策超出周期时间预算(%v)，剩余预测已中止，持仓默认持有", at.cycleBudget()))
+		}
 		if len(decision.Decisions) > 0 {
 			decisionJSON, _ := json.MarshalIndent(decision.Decisions, "", "  ")
 			record.DecisionJSON = string(decisionJSON)
@@ -490,33 +1075,35 @@ func (at *AutoTrader) runCycle() error {
 	if err != nil {
 		record.Success = false
 		record.ErrorMessage = fmt.Sprintf("获取AI决策失败: %v", err)
+		record.ErrorKind = errorKind(err)
 
 		// 打印AI思维链（即使有错误）
 		if decision != nil && decision.CoTTrace != "" {
 			log.Print("\n" + strings.Repeat("-", 70))
-			log.Println("💭 AI思维链分析（错误情况）:")
+			i18n.Logf("cycle.cot_on_error")
 			log.Println(strings.Repeat("-", 70))
 			log.Println(decision.CoTTrace)
 			log.Print(strings.Repeat("-", 70) + "\n")
 		}
 
-		at.decisionLogger.LogDecision(record)
+		at.runFallbackPolicy(record.ErrorMessage, record) // 🛟 确定性兜底：管理已有持仓，不开新仓
+		at.logCycle(record, timer)
 		return fmt.Errorf("获取AI决策失败: %w", err)
 	}
 
 	// 5. 打印AI思维链
 	log.Print("\n" + strings.Repeat("-", 70))
-	log.Println("💭 AI思维链分析:")
+	i18n.Logf("cycle.cot")
 	log.Println(strings.Repeat("-", 70))
 	log.Println(decision.CoTTrace)
 	log.Print(strings.Repeat("-", 70) + "\n")
 
 	// 6. 打印AI决策
-	log.Printf("📋 AI决策列表 (%d 个):\n", len(decision.Decisions))
+	i18n.Logf("cycle.decision_list", len(decision.Decisions))
 	for i, d := range decision.Decisions {
 		log.Printf("  [%d] %s: %s - %s", i+1, d.Symbol, d.Action, d.Reasoning)
 		if d.Action == "open_long" || d.Action == "open_short" {
-			log.Printf("      杠杆: %dx | 仓位: %.2f USDT | 止损: %.4f | 止盈: %.4f",
+			i18n.Logf("cycle.decision_params",
 				d.Leverage, d.PositionSizeUSD, d.StopLoss, d.TakeProfit)
 		}
 	}
@@ -525,86 +1112,153 @@ func (at *AutoTrader) runCycle() error {
 	// 7. 对决策排序：确保先平仓后开仓（防止仓位叠加超限）
 	sortedDecisions := sortDecisionsByPriority(decision.Decisions)
 
-	log.Println("🔄 执行顺序（已优化）: 先平仓→后开仓")
+	i18n.Logf("cycle.execution_order")
 	for i, d := range sortedDecisions {
 		log.Printf("  [%d] %s %s", i+1, d.Symbol, d.Action)
 	}
 	log.Println()
 
 	// 执行决策并记录结果
+	doneExecution := timer.track(stageExecution)
+	var retryQueue []*pendingExecution // 🔁 因瞬时错误失败、等待重试的开仓
 	for _, d := range sortedDecisions {
-		actionRecord := logger.DecisionAction{
-			Action:    d.Action,
-			Symbol:    d.Symbol,
-			Quantity:  0,
-			Leverage:  d.Leverage,
-			Price:     0,
-			Timestamp: time.Now(),
-			Success:   false,
-			Reasoning: d.Reasoning, // ✅ NEW: 添加平仓原因
-		}
-
-		if err := at.executeDecisionWithRecord(&d, &actionRecord); err != nil {
-			log.Printf("❌ 执行决策失败 (%s %s): %v", d.Symbol, d.Action, err)
-			actionRecord.Error = err.Error()
-			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s %s 失败: %v", d.Symbol, d.Action, err))
-		} else {
-			actionRecord.Success = true
-			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("✓ %s %s 成功", d.Symbol, d.Action))
-
-			// 🧠 记录到AI记忆（Sprint 1）
-			if d.Action != "hold" && d.Action != "wait" {
-				tradeEntry := at.buildTradeEntry(&d, &actionRecord, ctx)
-				if err := at.memoryManager.AddTrade(tradeEntry); err != nil {
-					log.Printf("⚠️  记录交易到记忆失败: %v", err)
+		// 🚦 决策策略：否决的决策直接记录为失败，修改的决策记录修改说明
+		policyNotes, vetoErr := at.applyDecisionPolicies(&d)
+		if vetoErr != nil {
+			actionRecord := newActionRecord(&d)
+			actionRecord.PolicyNotes = policyNotes
+			at.recordExecution(record, ctx, &d, actionRecord, vetoErr)
+			continue
+		}
+
+		// 🔀 与上一周期同币种的开仓方向对比：价格几乎没动就高信心反手时提高门槛
+		if note, contradictionErr := at.checkContradiction(&d, ctx); note != "" {
+			policyNotes = append(policyNotes, note)
+			if contradictionErr != nil {
+				actionRecord := newActionRecord(&d)
+				actionRecord.PolicyNotes = policyNotes
+				at.recordExecution(record, ctx, &d, actionRecord, contradictionErr)
+				continue
+			}
+		}
+
+		// 🎯 按信心度（及该信心度档位的历史胜率）调整仓位
+		sizeMultiplier := at.applyConfidenceSizing(&d)
+
+		// 🔄 换仓：预检新仓位后先平后开，拆成平仓、开仓两条执行记录
+		if d.Action == "replace_position" {
+			for _, step := range at.executeReplacePosition(&d) {
+				step.actionRecord.PolicyNotes = policyNotes
+				if strings.HasPrefix(step.actionRecord.Action, "open_") {
+					step.actionRecord.SizeMultiplier = sizeMultiplier
 				}
+				at.recordExecution(record, ctx, step.decision, step.actionRecord, step.err)
 			}
+			continue
+		}
 
-			// 成功执行后短暂延迟
-			time.Sleep(1 * time.Second)
+		actionRecord := newActionRecord(&d)
+		actionRecord.PolicyNotes = policyNotes
+		actionRecord.SizeMultiplier = sizeMultiplier
+		err := at.executeDecisionWithRecord(&d, actionRecord)
+		if at.shouldQueueRetry(&d, err) {
+			i18n.Logf("exec.retry_queued", d.Symbol, d.Action, err)
+			actionRecord.Attempts = 1
+			retryQueue = append(retryQueue, &pendingExecution{decision: d, actionRecord: actionRecord, lastErr: err})
+			continue
 		}
+		at.recordExecution(record, ctx, &d, actionRecord, err)
+	}
+	at.retryPendingExecutions(record, ctx, retryQueue)
+	doneExecution()
 
-		record.Decisions = append(record.Decisions, actionRecord)
+	// 🪞 影子引擎：对同一上下文决策并模拟结果（不执行）
+	doneShadow := timer.track(stageShadow)
+	if note := at.runShadowEngine(ctx, decision); note != "" {
+		record.ExecutionLog = append(record.ExecutionLog, note)
 	}
+	doneShadow()
+
+	// 📸 保存执行决策时的市场快照（复盘时还原AI看到的数据）
+	at.saveMarketSnapshot(record, ctx, decision.ExtendedData)
+
+	// ⏱️ 根据波动和持仓调整下一周期的扫描间隔
+	at.adaptScanInterval(ctx)
 
 	// 8. 保存决策记录
-	if err := at.decisionLogger.LogDecision(record); err != nil {
+	if err := at.logCycle(record, timer); err != nil {
 		log.Printf("⚠ 保存决策记录失败: %v", err)
 	}
 
 	return nil
 }
 
+// recordExecution 记录单个决策的执行结果（执行日志、AI记忆、决策记录）
+func (at *AutoTrader) recordExecution(record *logger.DecisionRecord, ctx *decision.Context, d *decision.Decision, actionRecord *logger.DecisionAction, err error) {
+	if err != nil {
+		i18n.Logf("exec.failed", d.Symbol, d.Action, err)
+		actionRecord.Error = err.Error()
+		actionRecord.ErrorKind = errorKind(err)
+		at.skipRejectedSymbol(d.Symbol, err)
+		record.ExecutionLog = append(record.ExecutionLog, i18n.T("exec.log_failed", d.Symbol, d.Action, err))
+		at.events.Publish(events.DecisionRejectedEvent{TraderID: at.id, Symbol: d.Symbol, Action: d.Action, Reason: err.Error(), Time: time.Now()})
+	} else if actionRecord.Hypothetical {
+		// 👁️ 观察模式：假设执行不写入AI记忆（假设持仓没有真实平仓结果）
+		actionRecord.Success = true
+		record.ExecutionLog = append(record.ExecutionLog, i18n.T("exec.log_hypothetical", d.Symbol, d.Action))
+		at.publishExecuted(actionRecord)
+	} else {
+		actionRecord.Success = true
+		record.ExecutionLog = append(record.ExecutionLog, i18n.T("exec.log_success", d.Symbol, d.Action))
+		at.publishExecuted(actionRecord)
+		at.ledgerFromAction(actionRecord) // 🏷️ 成交台账
+
+		// 🧠 记录到AI记忆（Sprint 1）
+		if d.Action != "hold" && d.Action != "wait" {
+			tradeEntry := at.buildTradeEntry(d, actionRecord, ctx)
+			if err := at.memoryManager.AddTrade(tradeEntry); err != nil {
+				log.Printf("⚠️  记录交易到记忆失败: %v", err)
+			}
+		}
+
+		// 成功执行后短暂延迟
+		time.Sleep(1 * time.Second)
+	}
+
+	record.Decisions = append(record.Decisions, *actionRecord)
+}
+
 // buildTradingContext 构建交易上下文
-func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
+func (at *AutoTrader) buildTradingContext(timer *cycleTimer) (*decision.Context, error) {
+	// ⏱️ 持仓与余额互不依赖，并发请求（币安获取持仓时还会执行移动止损，耗时较长）
+	var positions []Position
+	var positionsErr error
+	positionsDone := make(chan struct{})
+	go func() {
+		defer close(positionsDone)
+		defer timer.track(stagePositions)()
+		positions, positionsErr = at.trader.GetPositions()
+	}()
+
 	// 1. 获取账户信息
+	doneBalance := timer.track(stageBalance)
 	balance, err := at.trader.GetBalance()
+	doneBalance()
 	if err != nil {
+		<-positionsDone
 		return nil, fmt.Errorf("获取账户余额失败: %w", err)
 	}
 
 	// 获取账户字段
-	totalWalletBalance := 0.0
-	totalUnrealizedProfit := 0.0
-	availableBalance := 0.0
-
-	if wallet, ok := balance["totalWalletBalance"].(float64); ok {
-		totalWalletBalance = wallet
-	}
-	if unrealized, ok := balance["totalUnrealizedProfit"].(float64); ok {
-		totalUnrealizedProfit = unrealized
-	}
-	if avail, ok := balance["availableBalance"].(float64); ok {
-		availableBalance = avail
-	}
+	availableBalance := balance.AvailableBalance
 
 	// Total Equity = 钱包余额 + 未实现盈亏
-	totalEquity := totalWalletBalance + totalUnrealizedProfit
+	totalEquity := balance.Equity()
 
 	// 2. 获取持仓信息
-	positions, err := at.trader.GetPositions()
-	if err != nil {
-		return nil, fmt.Errorf("获取持仓失败: %w", err)
+	<-positionsDone
+	if positionsErr != nil {
+		return nil, fmt.Errorf("获取持仓失败: %w", positionsErr)
 	}
 
 	var positionInfos []decision.PositionInfo
@@ -616,21 +1270,18 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	newSnapshot := make(map[string]decision.PositionInfo)
 
 	for _, pos := range positions {
-		symbol := pos["symbol"].(string)
-		side := pos["side"].(string)
-		entryPrice := pos["entryPrice"].(float64)
-		markPrice := pos["markPrice"].(float64)
-		quantity := pos["positionAmt"].(float64)
-		if quantity < 0 {
-			quantity = -quantity // 空仓数量为负，转为正数
-		}
-		unrealizedPnl := pos["unRealizedProfit"].(float64)
-		liquidationPrice := pos["liquidationPrice"].(float64)
+		symbol := pos.Symbol
+		side := pos.Side
+		entryPrice := pos.EntryPrice
+		markPrice := pos.MarkPrice
+		quantity := pos.Quantity
+		unrealizedPnl := pos.UnrealizedPnL
+		liquidationPrice := pos.LiquidationPrice
 
 		// 计算占用保证金（估算）
-		leverage := 10 // 默认值，实际应该从持仓信息获取
-		if lev, ok := pos["leverage"].(float64); ok {
-			leverage = int(lev)
+		leverage := pos.Leverage
+		if leverage <= 0 {
+			leverage = 10 // 交易所未返回杠杆时的默认值
 		}
 		marginUsed := (quantity * markPrice) / float64(leverage)
 		totalMarginUsed += marginUsed
@@ -646,6 +1297,12 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 		// 跟踪持仓首次出现时间
 		posKey := symbol + "_" + side
 		currentPositionKeys[posKey] = true
+		if at.ignoredPositions[posKey] {
+			continue // 🆕 启动对账时选择忽略的持仓，不交给AI管理（保证金仍计入）
+		}
+		if at.basis != nil && at.basis.Manages(symbol) {
+			continue // 📐 基差策略持仓，不交给AI管理（保证金仍计入）
+		}
 		if _, exists := at.positionFirstSeenTime[posKey]; !exists {
 			// ⚠️ 检测到"新"持仓（可能是系统重启后的现有持仓）
 			// 使用保守估计：假设已持仓60分钟（避免将旧持仓误判为"0分钟新持仓"）
@@ -685,61 +1342,55 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	// 检测已消失的持仓（例如止损/强平生效）
 	for key, last := range at.lastPositionSnapshot {
 		if !currentPositionKeys[key] {
+			strategy := at.positionStrategy(last.Symbol, last.Side)
+			at.orderManager.RemoveProtection(last.Symbol, last.Side)
 			isManualClose := false
 			if ts, ok := at.manualCloseTracker[key]; ok && time.Since(ts) < 2*time.Minute {
-				log.Printf("📤 持仓已主动平仓: %s %s | 入场价 %.4f | 上次价格 %.4f | 未实现盈亏 %.2f%%",
+				i18n.Logf("position.closed_by_bot",
 					last.Symbol, strings.ToUpper(last.Side), last.EntryPrice, last.MarkPrice, last.UnrealizedPnLPct)
 				delete(at.manualCloseTracker, key)
 				isManualClose = true
 			} else {
-				log.Printf("🚨 检测到持仓消失，可能为止损/强平触发: %s %s | 入场价 %.4f | 上次价格 %.4f | 未实现盈亏 %.2f%%",
+				i18n.Logf("position.vanished",
 					last.Symbol, strings.ToUpper(last.Side), last.EntryPrice, last.MarkPrice, last.UnrealizedPnLPct)
 			}
 
-			// 🧠 记录止损/止盈到AI记忆
+			// 📣 止损/止盈触发事件（AI记忆等订阅者异步处理）
 			if !isManualClose {
-				// 构建交易记录
 				holdMinutes := 0
 				if !last.OpenTime.IsZero() {
 					holdMinutes = int(time.Since(last.OpenTime).Minutes())
 				}
 
-				result := "break_even"
-				if last.UnrealizedPnLPct > 0.1 {
-					result = "win"
-				} else if last.UnrealizedPnLPct < -0.1 {
-					result = "loss"
-				}
-
-				// 推断止损还是止盈
-				triggerType := "止损"
-				if last.UnrealizedPnLPct > 0 {
-					triggerType = "止盈"
-				}
-
-				tradeEntry := memory.TradeEntry{
-					Cycle:       at.callCount,
-					Timestamp:   time.Now(),
-					Action:      "close",
-					Symbol:      last.Symbol,
-					Side:        last.Side,
-					Signals:     []string{triggerType + "自动触发"},
-					Reasoning:   fmt.Sprintf("%s自动触发（持仓消失，未经主动平仓决策）", triggerType),
-					EntryPrice:  last.EntryPrice,
-					ExitPrice:   last.MarkPrice,
-					PositionPct: (last.MarginUsed / totalEquity) * 100,
-					Leverage:    last.Leverage,
-					HoldMinutes: holdMinutes,
-					ReturnPct:   last.UnrealizedPnLPct,
-					Result:      result,
+				// 🎯 从订单历史确认止损/止盈/强平及实际成交价（查询失败时按盈亏推断）
+				audit := at.auditVanishedPosition(last)
+				at.ledgerVanished(last, strategy, audit)
+				returnPct := last.UnrealizedPnLPct
+				if audit.FillPrice > 0 && last.EntryPrice > 0 {
+					returnPct = (audit.FillPrice - last.EntryPrice) / last.EntryPrice * float64(last.Leverage) * 100
+					if last.Side == "short" {
+						returnPct = -returnPct
+					}
 				}
 
-				if err := at.memoryManager.AddTrade(tradeEntry); err != nil {
-					log.Printf("⚠️  记录止损/止盈到记忆失败: %v", err)
-				} else {
-					log.Printf("✅ 已记录%s到交易记忆：%s %s, 收益%.2f%%",
-						triggerType, last.Symbol, last.Side, last.UnrealizedPnLPct)
-				}
+				at.events.Publish(events.StopTriggeredEvent{
+					TraderID:     at.id,
+					Cycle:        at.callCount,
+					Symbol:       last.Symbol,
+					Side:         last.Side,
+					Trigger:      audit.Reason,
+					Confirmed:    audit.Source == "exchange",
+					EntryPrice:   last.EntryPrice,
+					LastPrice:    last.MarkPrice,
+					TriggerPrice: audit.TriggerPrice,
+					FillPrice:    audit.FillPrice,
+					SlippagePct:  audit.SlippagePct,
+					ReturnPct:    returnPct,
+					Leverage:     last.Leverage,
+					PositionPct:  (last.MarginUsed / totalEquity) * 100,
+					HoldMinutes:  holdMinutes,
+					Time:         time.Now(),
+				})
 			}
 		}
 	}
@@ -751,6 +1402,12 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 			delete(at.positionFirstSeenTime, key)
 		}
 	}
+	for key := range at.ignoredPositions {
+		if !currentPositionKeys[key] {
+			delete(at.ignoredPositions, key)
+			at.forgetManualPosition(key)
+		}
+	}
 
 	for key, ts := range at.manualCloseTracker {
 		if time.Since(ts) > 10*time.Minute {
@@ -764,6 +1421,8 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	const ai500Limit = 20 // AI500取前20个评分最高的币种
 
 	// 获取合并后的币种池（AI500 + OI Top）
+	doneCandidates := timer.track(stageCandidates)
+	defer doneCandidates()
 	mergedPool, err := pool.GetMergedCoinPool(ai500Limit)
 	if err != nil {
 		return nil, fmt.Errorf("获取合并币种池失败: %w", err)
@@ -772,6 +1431,9 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	// 构建候选币种列表（包含来源信息）
 	var candidateCoins []decision.CandidateCoin
 	for _, symbol := range mergedPool.AllSymbols {
+		if at.basis != nil && at.basis.Manages(symbol) {
+			continue // 📐 基差策略币种不交给AI
+		}
 		sources := mergedPool.SymbolSources[symbol]
 		candidateCoins = append(candidateCoins, decision.CandidateCoin{
 			Symbol:  symbol,
@@ -782,6 +1444,21 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	log.Printf("📋 合并币种池: AI500前%d + OI_Top20 = 总计%d个候选币种",
 		ai500Limit, len(candidateCoins))
 
+	// 🪙 按计价币种偏好把币种池的USDT交易对换成USDC永续
+	candidateCoins = at.applyQuoteAssets(candidateCoins)
+
+	// 🚨 注入近期高置信山寨币异动信号（额外候选来源）
+	candidateCoins = at.injectAltcoinSignals(candidateCoins)
+
+	// 📥 注入webhook推送的外部交易想法（AI确认或否决）
+	candidateCoins = at.injectTradeIdeas(candidateCoins)
+
+	// ⭐ 用户关注币种始终加入候选池
+	candidateCoins = at.injectWatchlist(candidateCoins)
+
+	// 🚧 移除SETTLING/BREAK等非交易状态的币种（同时检测交易所整体维护）
+	candidateCoins = at.filterTradableCandidates(candidateCoins)
+
 	// 4. 计算总盈亏
 	totalPnL := totalEquity - at.initialBalance
 	totalPnLPct := 0.0
@@ -794,20 +1471,7 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 		marginUsedPct = (totalMarginUsed / totalEquity) * 100
 	}
 
-	// 5. 分析历史表现（最近100个周期，避免长期持仓的交易记录丢失）
-	// 假设每3分钟一个周期，100个周期 = 5小时，足够覆盖大部分交易
-	performance, err := at.decisionLogger.AnalyzePerformance(100)
-	if err != nil {
-		log.Printf("⚠️  分析历史表现失败: %v", err)
-		// 不影响主流程，继续执行（但设置performance为nil以避免传递错误数据）
-		performance = nil
-	}
-
-	// 🧠 获取交易员记忆（实际交易历史）
-	var memoryPrompt string
-	if at.memoryManager != nil {
-		memoryPrompt = at.memoryManager.GetContextPrompt()
-	}
+	// 5. 历史表现分析与市场数据并发执行（见 loadDecisionInputs）
 
 	// 6. 构建上下文
 	ctx := &decision.Context{
@@ -827,9 +1491,9 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 		},
 		Positions:      positionInfos,
 		CandidateCoins: candidateCoins,
-		Performance:    performance,            // 添加历史表现分析
-		MemoryPrompt:   memoryPrompt,          // 🧠 注入交易员记忆
 		UseLimitOrders: at.config.UseLimitOrders, // 传递限价单模式配置
+		MarketSource:   at.marketSource,
+		Liquidity:      at.config.Liquidity,
 	}
 
 	return ctx, nil
@@ -837,6 +1501,18 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 
 // executeDecisionWithRecord 执行AI决策并记录详细信息
 func (at *AutoTrader) executeDecisionWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
+	// 🏷️ 策略标签（编码进客户端订单ID，写入成交台账）
+	at.tagStrategy(decision, actionRecord)
+	// 📈 决策ID（开仓台账据此把平仓盈亏归因到本周期）
+	if actionRecord.DecisionID == "" {
+		actionRecord.DecisionID = decisionID(at.callCount, decision.Symbol, decision.Action)
+	}
+
+	// 🙋 人工管理的持仓：执行层拒绝操作（AI看不到这类持仓，防止同币种同方向的决策误动）
+	if err := at.checkManualPosition(decision.Symbol, decision.Action); err != nil {
+		return err
+	}
+
 	// 🆕 限价单模式：检查是否是限价单开仓决策
 	if decision.IsLimitOrder && (decision.Action == "open_long" || decision.Action == "open_short") {
 		return at.executeOpenLimitOrderWithRecord(decision, actionRecord)
@@ -862,7 +1538,7 @@ func (at *AutoTrader) executeDecisionWithRecord(decision *decision.Decision, act
 
 // executeOpenLongWithRecord 执行开多仓并记录详细信息
 func (at *AutoTrader) executeOpenLongWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  📈 开多仓: %s", decision.Symbol)
+	i18n.Logf("exec.open_long", decision.Symbol)
 
 	// ⚠️ 关键修复：强制刷新缓存，确保获取最新持仓信息（防止缓存导致同方向检查失效）
 	if binanceTrader, ok := at.trader.(*FuturesTrader); ok {
@@ -875,137 +1551,44 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *decision.Decision, act
 		return fmt.Errorf("获取持仓失败: %w", err)
 	}
 
-	// 🛡️ 硬约束检查（冷却期、日交易上限、小时上限、最大持仓数量）
-	if err := at.constraints.CanOpenPosition(decision.Symbol, len(positions)); err != nil {
-		log.Printf("  ⚠️  硬约束拦截: %v", err)
-		return fmt.Errorf("硬约束拦截: %w", err)
-	}
-
-	// 🆕 同方向单仓位限制：检查是否已有其他币种的多仓
-	for _, pos := range positions {
-		if pos["symbol"] != decision.Symbol && pos["side"] == "long" {
-			existingSymbol := pos["symbol"].(string)
-			return fmt.Errorf("❌ 同方向只能持有一个币种：已有%s多仓，拒绝开%s多仓。如需换仓，请先平掉%s",
-				existingSymbol, decision.Symbol, existingSymbol)
-		}
-	}
-
-	// ⚠️ 关键：检查是否已有同币种同方向持仓，如果有则拒绝开仓（防止仓位叠加超限）
-	for _, pos := range positions {
-		if pos["symbol"] == decision.Symbol && pos["side"] == "long" {
-			return fmt.Errorf("❌ %s 已有多仓，拒绝开仓以防止仓位叠加超限。如需换仓，请先给出 close_long 决策", decision.Symbol)
-		}
-	}
-
-	// ✅ 修复: 检查可用保证金是否充足 + 总保证金使用率
-	balance, err := at.trader.GetBalance()
-	if err != nil {
-		return fmt.Errorf("获取账户余额失败: %w", err)
-	}
-	availableBalance := 0.0
-	totalEquity := 0.0
-	if avail, ok := balance["availableBalance"].(float64); ok {
-		availableBalance = avail
-	}
-	if equity, ok := balance["totalWalletBalance"].(float64); ok {
-		totalEquity = equity
-	}
-
-	// 计算当前总已用保证金（所有持仓的保证金之和）
-	totalMarginUsed := 0.0
-	for _, pos := range positions {
-		// 获取持仓信息
-		positionAmt := 0.0
-		markPrice := 0.0
-		leverage := 1
-
-		if amt, ok := pos["positionAmt"].(float64); ok {
-			positionAmt = amt
-			if positionAmt < 0 {
-				positionAmt = -positionAmt // 空仓取绝对值
-			}
-		}
-		if price, ok := pos["markPrice"].(float64); ok {
-			markPrice = price
-		}
-		if lev, ok := pos["leverage"].(float64); ok {
-			leverage = int(lev)
-		}
-
-		// 保证金 = (持仓价值) / 杠杆
-		if leverage > 0 && markPrice > 0 {
-			positionValue := positionAmt * markPrice
-			marginForThisPosition := positionValue / float64(leverage)
-			totalMarginUsed += marginForThisPosition
-		}
-	}
-
-	// 计算所需保证金 = 仓位价值 / 杠杆
-	requiredMargin := decision.PositionSizeUSD / float64(decision.Leverage)
-
-	// 🚨 关键检查：总保证金使用率不能超过90%（硬约束）
-	newTotalMarginUsed := totalMarginUsed + requiredMargin
-	marginUtilizationRate := 0.0
-	if totalEquity > 0 {
-		marginUtilizationRate = (newTotalMarginUsed / totalEquity) * 100
-	}
-
-	if marginUtilizationRate > 90.0 {
-		return fmt.Errorf("❌ 总保证金使用率将超过90%%限制: 当前%.2f%% + 新仓位%.2f USDT = %.2f%% (账户净值:%.2f USDT)",
-			(totalMarginUsed/totalEquity)*100, requiredMargin, marginUtilizationRate, totalEquity)
-	}
-
-	// 检查可用保证金
-	if requiredMargin > availableBalance {
-		return fmt.Errorf("❌ 可用保证金不足: 需要%.2f USDT, 可用%.2f USDT", requiredMargin, availableBalance)
+	// 🔁 幂等检查（在重复持仓检查之前）：崩溃重启后重跑同一周期时，已成交的订单补完止损止盈等后续步骤，不再重复下单
+	if !at.config.ObserveMode && at.adoptExecutedOpen(decision, "long", positions, actionRecord) {
+		return nil
 	}
-	log.Printf("  💰 保证金检查通过: 需要%.2f USDT, 可用%.2f USDT, 总使用率%.1f%%", requiredMargin, availableBalance, marginUtilizationRate)
 
-	// 获取当前价格
-	marketData, err := market.Get(decision.Symbol)
+	// 🚪 开仓检查（硬约束、持仓上限、杠杆、保证金、止损距离、单笔风险、自我审查、价格过期）
+	price, err := at.checkOpenGates(decision, "long", positions, nil, actionRecord)
 	if err != nil {
 		return err
 	}
 
 	// 计算数量
-	quantity := decision.PositionSizeUSD / marketData.CurrentPrice
+	quantity := decision.PositionSizeUSD / price
 	actionRecord.Quantity = quantity
-	actionRecord.Price = marketData.CurrentPrice
+	actionRecord.Price = price
 
 	// 开仓
-	order, err := at.trader.OpenLong(decision.Symbol, quantity, decision.Leverage)
-	if err != nil {
-		return err
-	}
-
-	// 记录订单ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
+	// 👁️ 观察模式：风控检查已全部通过，只记录假设执行（含止损止盈）
+	if at.config.ObserveMode {
+		return at.recordHypotheticalExecution(decision, actionRecord, price, quantity)
 	}
 
-	log.Printf("  ✓ 开仓成功，订单ID: %v, 数量: %.4f", order["orderId"], quantity)
-
-	// 🛡️ 记录开仓到硬约束管理器
-	at.constraints.RecordOpenPosition(decision.Symbol, "long")
-
-	// 记录开仓时间
-	posKey := decision.Symbol + "_long"
-	at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
-
-	// 设置止损止盈
-	if err := at.trader.SetStopLoss(decision.Symbol, "LONG", quantity, decision.StopLoss); err != nil {
-		log.Printf("  ⚠ 设置止损失败: %v", err)
-	}
-	if err := at.trader.SetTakeProfit(decision.Symbol, "LONG", quantity, decision.TakeProfit); err != nil {
-		log.Printf("  ⚠ 设置止盈失败: %v", err)
+	// ⏳ 大额仓位按TWAP拆单，止损止盈按实际成交数量设置
+	// 🧾 下单前先写执行意图（崩溃后按交易所订单记录核对）
+	journalSeq := at.journalOrder(decision, "long", quantity, actionRecord)
+	order, quantity, err := at.placeEntryOrder(decision, "long", quantity, price, actionRecord)
+	if err != nil {
+		at.journal.finish(journalSeq, journalPhase(err), 0, err)
+		return err
 	}
-
+	i18n.Logf("exec.opened", order.OrderID, quantity)
+	at.completeOpen(decision, "long", order, quantity, price, journalSeq, actionRecord)
 	return nil
 }
 
 // executeOpenShortWithRecord 执行开空仓并记录详细信息
 func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  📉 开空仓: %s", decision.Symbol)
+	i18n.Logf("exec.open_short", decision.Symbol)
 
 	// ⚠️ 关键修复：强制刷新缓存，确保获取最新持仓信息（防止缓存导致同方向检查失效）
 	if binanceTrader, ok := at.trader.(*FuturesTrader); ok {
@@ -1018,166 +1601,152 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, ac
 		return fmt.Errorf("获取持仓失败: %w", err)
 	}
 
-	// 🛡️ 硬约束检查（冷却期、日交易上限、小时上限、最大持仓数量）
-	if err := at.constraints.CanOpenPosition(decision.Symbol, len(positions)); err != nil {
-		log.Printf("  ⚠️  硬约束拦截: %v", err)
-		return fmt.Errorf("硬约束拦截: %w", err)
-	}
-
-	// 🆕 同方向单仓位限制：检查是否已有其他币种的空仓
-	for _, pos := range positions {
-		if pos["symbol"] != decision.Symbol && pos["side"] == "short" {
-			existingSymbol := pos["symbol"].(string)
-			return fmt.Errorf("❌ 同方向只能持有一个币种：已有%s空仓，拒绝开%s空仓。如需换仓，请先平掉%s",
-				existingSymbol, decision.Symbol, existingSymbol)
-		}
-	}
-
-	// ⚠️ 关键：检查是否已有同币种同方向持仓，如果有则拒绝开仓（防止仓位叠加超限）
-	for _, pos := range positions {
-		if pos["symbol"] == decision.Symbol && pos["side"] == "short" {
-			return fmt.Errorf("❌ %s 已有空仓，拒绝开仓以防止仓位叠加超限。如需换仓，请先给出 close_short 决策", decision.Symbol)
-		}
-	}
-
-	// ✅ 修复: 检查可用保证金是否充足 + 总保证金使用率
-	balance, err := at.trader.GetBalance()
-	if err != nil {
-		return fmt.Errorf("获取账户余额失败: %w", err)
-	}
-	availableBalance := 0.0
-	totalEquity := 0.0
-	if avail, ok := balance["availableBalance"].(float64); ok {
-		availableBalance = avail
-	}
-	if equity, ok := balance["totalWalletBalance"].(float64); ok {
-		totalEquity = equity
-	}
-
-	// 计算当前总已用保证金（所有持仓的保证金之和）
-	totalMarginUsed := 0.0
-	for _, pos := range positions {
-		// 获取持仓信息
-		positionAmt := 0.0
-		markPrice := 0.0
-		leverage := 1
-
-		if amt, ok := pos["positionAmt"].(float64); ok {
-			positionAmt = amt
-			if positionAmt < 0 {
-				positionAmt = -positionAmt // 空仓取绝对值
-			}
-		}
-		if price, ok := pos["markPrice"].(float64); ok {
-			markPrice = price
-		}
-		if lev, ok := pos["leverage"].(float64); ok {
-			leverage = int(lev)
-		}
-
-		// 保证金 = (持仓价值) / 杠杆
-		if leverage > 0 && markPrice > 0 {
-			positionValue := positionAmt * markPrice
-			marginForThisPosition := positionValue / float64(leverage)
-			totalMarginUsed += marginForThisPosition
-		}
-	}
-
-	// 计算所需保证金 = 仓位价值 / 杠杆
-	requiredMargin := decision.PositionSizeUSD / float64(decision.Leverage)
-
-	// 🚨 关键检查：总保证金使用率不能超过90%（硬约束）
-	newTotalMarginUsed := totalMarginUsed + requiredMargin
-	marginUtilizationRate := 0.0
-	if totalEquity > 0 {
-		marginUtilizationRate = (newTotalMarginUsed / totalEquity) * 100
-	}
-
-	if marginUtilizationRate > 90.0 {
-		return fmt.Errorf("❌ 总保证金使用率将超过90%%限制: 当前%.2f%% + 新仓位%.2f USDT = %.2f%% (账户净值:%.2f USDT)",
-			(totalMarginUsed/totalEquity)*100, requiredMargin, marginUtilizationRate, totalEquity)
-	}
-
-	// 检查可用保证金
-	if requiredMargin > availableBalance {
-		return fmt.Errorf("❌ 可用保证金不足: 需要%.2f USDT, 可用%.2f USDT", requiredMargin, availableBalance)
+	// 🔁 幂等检查（在重复持仓检查之前）：崩溃重启后重跑同一周期时，已成交的订单补完止损止盈等后续步骤，不再重复下单
+	if !at.config.ObserveMode && at.adoptExecutedOpen(decision, "short", positions, actionRecord) {
+		return nil
 	}
-	log.Printf("  💰 保证金检查通过: 需要%.2f USDT, 可用%.2f USDT, 总使用率%.1f%%", requiredMargin, availableBalance, marginUtilizationRate)
 
-	// 获取当前价格
-	marketData, err := market.Get(decision.Symbol)
+	// 🚪 开仓检查（硬约束、持仓上限、杠杆、保证金、止损距离、单笔风险、自我审查、价格过期）
+	price, err := at.checkOpenGates(decision, "short", positions, nil, actionRecord)
 	if err != nil {
 		return err
 	}
 
 	// 计算数量
-	quantity := decision.PositionSizeUSD / marketData.CurrentPrice
+	quantity := decision.PositionSizeUSD / price
 	actionRecord.Quantity = quantity
-	actionRecord.Price = marketData.CurrentPrice
+	actionRecord.Price = price
 
 	// 开仓
-	order, err := at.trader.OpenShort(decision.Symbol, quantity, decision.Leverage)
+	// 👁️ 观察模式：风控检查已全部通过，只记录假设执行（含止损止盈）
+	if at.config.ObserveMode {
+		return at.recordHypotheticalExecution(decision, actionRecord, price, quantity)
+	}
+
+	// ⏳ 大额仓位按TWAP拆单，止损止盈按实际成交数量设置
+	// 🧾 下单前先写执行意图（崩溃后按交易所订单记录核对）
+	journalSeq := at.journalOrder(decision, "short", quantity, actionRecord)
+	order, quantity, err := at.placeEntryOrder(decision, "short", quantity, price, actionRecord)
 	if err != nil {
+		at.journal.finish(journalSeq, journalPhase(err), 0, err)
 		return err
 	}
+	i18n.Logf("exec.opened", order.OrderID, quantity)
+	at.completeOpen(decision, "short", order, quantity, price, journalSeq, actionRecord)
+	return nil
+}
 
-	// 记录订单ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
-	}
+// completeOpen 开仓成交后的步骤：记录保护信息、硬约束、开仓事件，设置止损止盈
+// journalSeq 为开仓意图的日志序号（幂等检查接管已成交订单时为0）
+func (at *AutoTrader) completeOpen(decision *decision.Decision, side string, order *OrderResult, quantity, price float64, journalSeq int64, actionRecord *logger.DecisionAction) {
+	actionRecord.Quantity = quantity
 
-	log.Printf("  ✓ 开仓成功，订单ID: %v, 数量: %.4f", order["orderId"], quantity)
+	// 记录订单ID
+	actionRecord.OrderID = order.OrderID
+	// 🆕 记录开仓手续费（用于净盈亏分析）
+	actionRecord.Commission = order.Commission
+
+	// 🆕 记录止损止盈价格（用于仪表盘展示持仓保护，重启后对账恢复）
+	actionRecord.StopLoss = decision.StopLoss
+	actionRecord.TakeProfit = decision.TakeProfit
+	at.orderManager.SetProtection(&PositionProtection{
+		Symbol:     decision.Symbol,
+		Side:       side,
+		StopLoss:   decision.StopLoss,
+		TakeProfit: decision.TakeProfit,
+		OpenTime:   time.Now(),
+		Reasoning:  decision.Reasoning,
+		Source:     "open",
+		Strategy:   actionRecord.Strategy,
+	})
+	at.journal.finish(journalSeq, journalDone, order.OrderID, nil)
 
 	// 🛡️ 记录开仓到硬约束管理器
-	at.constraints.RecordOpenPosition(decision.Symbol, "short")
+	at.constraints.RecordOpenPosition(decision.Symbol, side)
+	at.consumeCooldownOverride(decision, actionRecord)
+	at.events.Publish(events.PositionOpenedEvent{
+		TraderID:   at.id,
+		Symbol:     decision.Symbol,
+		Side:       side,
+		Quantity:   actionRecord.Quantity,
+		Price:      actionRecord.Price,
+		Leverage:   decision.Leverage,
+		StopLoss:   decision.StopLoss,
+		TakeProfit: decision.TakeProfit,
+		Time:       time.Now(),
+	})
 
 	// 记录开仓时间
-	posKey := decision.Symbol + "_short"
+	posKey := decision.Symbol + "_" + side
 	at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
 
 	// 设置止损止盈
-	if err := at.trader.SetStopLoss(decision.Symbol, "SHORT", quantity, decision.StopLoss); err != nil {
+	stopSeq := at.journal.begin(journalEntry{Op: journalSetStopLoss, Symbol: decision.Symbol, Side: side, Quantity: quantity, StopLoss: decision.StopLoss, TakeProfit: decision.TakeProfit, Cycle: at.callCount})
+	err := at.trader.SetStopLoss(decision.Symbol, strings.ToUpper(side), quantity, decision.StopLoss)
+	at.journal.finish(stopSeq, journalPhase(err), 0, err)
+	if err != nil {
 		log.Printf("  ⚠ 设置止损失败: %v", err)
 	}
-	if err := at.trader.SetTakeProfit(decision.Symbol, "SHORT", quantity, decision.TakeProfit); err != nil {
-		log.Printf("  ⚠ 设置止盈失败: %v", err)
-	}
-
-	return nil
+	ladder := at.takeProfitLadder(decision, side, price, quantity)
+	at.recordTakeProfitLadder(decision.Symbol, side, quantity, at.setTakeProfits(decision.Symbol, side, quantity, decision.TakeProfit, ladder))
 }
 
 // executeCloseLongWithRecord 执行平多仓并记录详细信息
 func (at *AutoTrader) executeCloseLongWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  🔄 平多仓: %s", decision.Symbol)
+	i18n.Logf("exec.close_long", decision.Symbol)
 
 	// 获取当前价格
-	marketData, err := market.Get(decision.Symbol)
+	marketData, err := market.GetFrom(at.marketSource, decision.Symbol)
 	if err != nil {
 		return err
 	}
 	actionRecord.Price = marketData.CurrentPrice
 
 	// 平仓
-	order, err := at.trader.CloseLong(decision.Symbol, 0) // 0 = 全部平仓
+	// 👁️ 观察模式：不平仓，只记录假设执行
+	if at.config.ObserveMode {
+		return at.recordHypotheticalExecution(decision, actionRecord, marketData.CurrentPrice, 0)
+	}
+
+	// 🔁 幂等检查：崩溃重启后重跑同一周期时，已执行过的订单不再重复下单
+	if at.skipDuplicateExecution(decision.Symbol, decision.Action, actionRecord) {
+		return nil
+	}
+
+	journalSeq := at.journalOrder(decision, "long", 0, actionRecord)
+	order, err := at.placeOrder(decision.Action, decision.Symbol, 0, 0, actionRecord.ClientOrderID) // 0 = 全部平仓
 	if err != nil {
+		at.journal.finish(journalSeq, journalPhase(err), 0, err)
 		return err
 	}
+	at.journal.finish(journalSeq, journalDone, order.OrderID, nil)
 
 	// 记录订单ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
-	}
+	actionRecord.OrderID = order.OrderID
 
-	// ✅ 修复: 更新日内盈亏
-	if realizedPnL, ok := order["realized_pnl"].(float64); ok {
-		at.dailyPnL += realizedPnL
-		log.Printf("  💰 平仓盈亏: %+.2f USDT | 日内累计: %+.2f USDT", realizedPnL, at.dailyPnL)
-	}
+	// 🆕 记录平仓手续费和资金费（用于净盈亏分析）
+	actionRecord.Commission = order.Commission
+	actionRecord.FundingFee = order.FundingFee
 
-	log.Printf("  ✓ 平仓成功")
+	// ✅ 修复: 更新日内盈亏（RealizedPnL 为净盈亏）
+	actionRecord.RealizedPnL = order.RealizedPnL
+	at.dailyPnL += order.RealizedPnL
+	log.Printf("  💰 平仓盈亏: %+.2f USDT | 日内累计: %+.2f USDT", order.RealizedPnL, at.dailyPnL)
+
+	i18n.Logf("exec.closed")
 
 	// 🛡️ 记录平仓到硬约束管理器（设置冷却期）
-	at.constraints.RecordClosePosition(decision.Symbol, "long")
+	at.constraints.RecordClosePosition(decision.Symbol, "long", order.RealizedPnL)
+	at.orderManager.RemoveProtection(decision.Symbol, "long")
+	at.events.Publish(events.PositionClosedEvent{
+		TraderID:    at.id,
+		Symbol:      decision.Symbol,
+		Side:        "long",
+		Price:       actionRecord.Price,
+		RealizedPnL: order.RealizedPnL,
+		Reason:      decision.Reasoning,
+		Time:        time.Now(),
+	})
 
 	// 标记为手动/策略主动平仓，防止后续被误判为止损
 	posKey := decision.Symbol + "_long"
@@ -1188,36 +1757,60 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *decision.Decision, ac
 
 // executeCloseShortWithRecord 执行平空仓并记录详细信息
 func (at *AutoTrader) executeCloseShortWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  🔄 平空仓: %s", decision.Symbol)
+	i18n.Logf("exec.close_short", decision.Symbol)
 
 	// 获取当前价格
-	marketData, err := market.Get(decision.Symbol)
+	marketData, err := market.GetFrom(at.marketSource, decision.Symbol)
 	if err != nil {
 		return err
 	}
 	actionRecord.Price = marketData.CurrentPrice
 
 	// 平仓
-	order, err := at.trader.CloseShort(decision.Symbol, 0) // 0 = 全部平仓
+	// 👁️ 观察模式：不平仓，只记录假设执行
+	if at.config.ObserveMode {
+		return at.recordHypotheticalExecution(decision, actionRecord, marketData.CurrentPrice, 0)
+	}
+
+	// 🔁 幂等检查：崩溃重启后重跑同一周期时，已执行过的订单不再重复下单
+	if at.skipDuplicateExecution(decision.Symbol, decision.Action, actionRecord) {
+		return nil
+	}
+
+	journalSeq := at.journalOrder(decision, "short", 0, actionRecord)
+	order, err := at.placeOrder(decision.Action, decision.Symbol, 0, 0, actionRecord.ClientOrderID) // 0 = 全部平仓
 	if err != nil {
+		at.journal.finish(journalSeq, journalPhase(err), 0, err)
 		return err
 	}
+	at.journal.finish(journalSeq, journalDone, order.OrderID, nil)
 
 	// 记录订单ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
-	}
+	actionRecord.OrderID = order.OrderID
 
-	// ✅ 修复: 更新日内盈亏
-	if realizedPnL, ok := order["realized_pnl"].(float64); ok {
-		at.dailyPnL += realizedPnL
-		log.Printf("  💰 平仓盈亏: %+.2f USDT | 日内累计: %+.2f USDT", realizedPnL, at.dailyPnL)
-	}
+	// 🆕 记录平仓手续费和资金费（用于净盈亏分析）
+	actionRecord.Commission = order.Commission
+	actionRecord.FundingFee = order.FundingFee
 
-	log.Printf("  ✓ 平仓成功")
+	// ✅ 修复: 更新日内盈亏（RealizedPnL 为净盈亏）
+	actionRecord.RealizedPnL = order.RealizedPnL
+	at.dailyPnL += order.RealizedPnL
+	log.Printf("  💰 平仓盈亏: %+.2f USDT | 日内累计: %+.2f USDT", order.RealizedPnL, at.dailyPnL)
+
+	i18n.Logf("exec.closed")
 
 	// 🛡️ 记录平仓到硬约束管理器（设置冷却期）
-	at.constraints.RecordClosePosition(decision.Symbol, "short")
+	at.constraints.RecordClosePosition(decision.Symbol, "short", order.RealizedPnL)
+	at.orderManager.RemoveProtection(decision.Symbol, "short")
+	at.events.Publish(events.PositionClosedEvent{
+		TraderID:    at.id,
+		Symbol:      decision.Symbol,
+		Side:        "short",
+		Price:       actionRecord.Price,
+		RealizedPnL: order.RealizedPnL,
+		Reason:      decision.Reasoning,
+		Time:        time.Now(),
+	})
 
 	// 标记为手动/策略主动平仓，防止后续被误判为止损
 	posKey := decision.Symbol + "_short"
@@ -1251,6 +1844,18 @@ func (at *AutoTrader) GetMemoryManager() *memory.Manager {
 	return at.memoryManager
 }
 
+// getAuditChainStatus 审计哈希链链头（状态API，可定期保存到外部用于 audit_verify -head 核对）
+func (at *AutoTrader) getAuditChainStatus() map[string]interface{} {
+	hash, cycle, enabled := at.decisionLogger.AuditChainHead()
+	if !enabled {
+		return nil
+	}
+	return map[string]interface{}{
+		"head_hash":  hash,
+		"head_cycle": cycle,
+	}
+}
+
 // GetStatus 获取系统状态（用于API）
 func (at *AutoTrader) GetStatus() map[string]interface{} {
 	aiProvider := "DeepSeek"
@@ -1258,47 +1863,134 @@ func (at *AutoTrader) GetStatus() map[string]interface{} {
 		aiProvider = "Qwen"
 	}
 
-	return map[string]interface{}{
-		"trader_id":       at.id,
-		"trader_name":     at.name,
-		"ai_model":        at.aiModel,
-		"exchange":        at.exchange,
-		"is_running":      at.isRunning,
-		"start_time":      at.startTime.Format(time.RFC3339),
-		"runtime_minutes": int(time.Since(at.startTime).Minutes()),
-		"call_count":      at.callCount,
-		"initial_balance": at.initialBalance,
-		"scan_interval":   at.config.ScanInterval.String(),
-		"stop_until":      at.stopUntil.Format(time.RFC3339),
-		"last_reset_time": at.lastResetTime.Format(time.RFC3339),
-		"ai_provider":     aiProvider,
+	// 🎛️ 手动暂停状态（无限期暂停时paused_until为空）
+	paused, until := at.PauseState()
+	pausedUntil := ""
+	if paused && !until.IsZero() {
+		pausedUntil = until.Format(time.RFC3339)
+	}
+
+	status := map[string]interface{}{
+		"trader_id":          at.id,
+		"trader_name":        at.name,
+		"ai_model":           at.aiModel,
+		"exchange":           at.exchange,
+		"is_running":         at.isRunning,
+		"start_time":         at.startTime.Format(time.RFC3339),
+		"runtime_minutes":    int(time.Since(at.startTime).Minutes()),
+		"call_count":         at.callCount,
+		"initial_balance":    at.initialBalance,
+		"scan_interval":      at.currentScanInterval().String(),
+		"kline_intervals":    market.KlineIntervals(),
+		"stop_until":         at.stopUntil.Format(time.RFC3339),
+		"last_reset_time":    at.lastResetTime.Format(time.RFC3339),
+		"ai_provider":        aiProvider,
+		"ai_failover":        at.mcpClient.Failover.Status(),
+		"observe_mode":       at.config.ObserveMode,
+		"pnl_reconcile":      at.getReconcileReport(),
+		"constraints":        at.constraints.GetStatus(),
+		"paused":             paused,
+		"paused_until":       pausedUntil,
+		"rate_limit":         ratelimit.For(at.exchange).Status(),
+		"equity_stats":       at.equity.Stats(time.Now()),
+		"shadow":             at.GetShadowReport(),
+		"portfolio_stop":     at.getPortfolioStopStatus(),
+		"withdrawal_lock":    at.getWithdrawalLockStatus(),
+		"flat_windows":       at.getFlatWindowStatus(),
+		"audit_chain":        at.getAuditChainStatus(),
+		"account_tier":       at.getAccountTierStatus(),
+		"execution_latency":  at.getExecutionLatencyStatus(),
+		"symbol_status":      at.getSymbolStatusReport(),
+		"protective_orders":  at.getProtectiveMonitorStatus(),
+		"position_overrides": at.getPositionOverrideStatus(),
+		"trade_ideas":        at.getTradeIdeaStatus(),
+		"precision_cache":    at.getPrecisionStatus(),
+		"fallback_policy":    at.getFallbackPolicyStatus(),
+		"cycle_timing":       at.getCycleTimingStatus(),
+	}
+	if at.config.OvertradingGuard.Enabled() {
+		status["overtrading_guard"] = at.getOvertradingStatus()
+	}
+	if at.config.AdaptiveScan.Enabled() {
+		at.adaptive.mu.RLock()
+		status["scan_interval_reason"] = at.adaptive.reason
+		at.adaptive.mu.RUnlock()
+	}
+	if perf := at.lastPerformance; perf != nil {
+		status["performance"] = map[string]interface{}{
+			"lookback_cycles": perf.LookbackCycles,
+			"total_trades":    perf.TotalTrades,
+			"win_rate":        perf.WinRate,
+			"profit_factor":   perf.ProfitFactor,
+			"expectancy":      perf.Expectancy,
+			"sharpe_ratio":    perf.SharpeRatio,
+			"sortino_ratio":   perf.SortinoRatio,
+			"avg_win_r":       perf.AvgWinR,
+			"avg_loss_r":      perf.AvgLossR,
+			"expectancy_r":    perf.ExpectancyR,
+			"max_win_streak":  perf.MaxWinStreak,
+			"max_loss_streak": perf.MaxLossStreak,
+			"current_streak":  perf.CurrentStreak,
+		}
+	}
+	if at.basis != nil {
+		status["basis"] = at.basis.Status()
 	}
+	if clock, ok := at.trader.(ClockOffsetProvider); ok {
+		status["clock_offset_ms"] = clock.ClockOffset().Milliseconds()
+	}
+	return status
 }
 
-// GetAccountInfo 获取账户信息（用于API）
-func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
-	balance, err := at.trader.GetBalance()
-	if err != nil {
-		return nil, fmt.Errorf("获取余额失败: %w", err)
+// PerformanceWindow 绩效统计窗口（周期数）
+func (at *AutoTrader) PerformanceWindow() int {
+	if at.config.PerformanceWindow > 0 {
+		return at.config.PerformanceWindow
 	}
+	return 100
+}
 
-	// 获取账户字段
-	totalWalletBalance := 0.0
-	totalUnrealizedProfit := 0.0
-	availableBalance := 0.0
+// GetEquityCurve 获取since之后的净值采样与当前风险指标
+func (at *AutoTrader) GetEquityCurve(since time.Time) ([]EquitySample, EquityStats) {
+	return at.equity.Samples(since), at.equity.Stats(time.Now())
+}
 
-	if wallet, ok := balance["totalWalletBalance"].(float64); ok {
-		totalWalletBalance = wallet
+// reconcilePnLIfDue 到期时执行盈亏对账，日内盈亏偏差过大时以交易所数据为准
+func (at *AutoTrader) reconcilePnLIfDue() {
+	if at.pnlReconciler == nil || !at.pnlReconciler.Due() {
+		return
 	}
-	if unrealized, ok := balance["totalUnrealizedProfit"].(float64); ok {
-		totalUnrealizedProfit = unrealized
+
+	report, err := at.pnlReconciler.Reconcile(at.lastResetTime)
+	if err != nil {
+		log.Printf("⚠️  盈亏对账失败: %v", err)
+		return
+	}
+
+	if math.Abs(report.DailyExchangeNetPnL-at.dailyPnL) > at.pnlReconciler.absTolerance {
+		log.Printf("🧾 [对账] 日内盈亏校正: 内部%+.2f → 交易所%+.2f USDT",
+			at.dailyPnL, report.DailyExchangeNetPnL)
+		at.dailyPnL = report.DailyExchangeNetPnL
 	}
-	if avail, ok := balance["availableBalance"].(float64); ok {
-		availableBalance = avail
+}
+
+// getReconcileReport 获取最近一次对账报告（未启用时返回nil）
+func (at *AutoTrader) getReconcileReport() *ReconcileReport {
+	if at.pnlReconciler == nil {
+		return nil
+	}
+	return at.pnlReconciler.LastReport()
+}
+
+// GetAccountInfo 获取账户信息（用于API）
+func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
+	balance, err := at.trader.GetBalance()
+	if err != nil {
+		return nil, fmt.Errorf("获取余额失败: %w", err)
 	}
 
 	// Total Equity = 钱包余额 + 未实现盈亏
-	totalEquity := totalWalletBalance + totalUnrealizedProfit
+	totalEquity := balance.Equity()
 
 	// 获取持仓计算总保证金
 	positions, err := at.trader.GetPositions()
@@ -1309,20 +2001,13 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 	totalMarginUsed := 0.0
 	totalUnrealizedPnL := 0.0
 	for _, pos := range positions {
-		markPrice := pos["markPrice"].(float64)
-		quantity := pos["positionAmt"].(float64)
-		if quantity < 0 {
-			quantity = -quantity
-		}
-		unrealizedPnl := pos["unRealizedProfit"].(float64)
-		totalUnrealizedPnL += unrealizedPnl
+		totalUnrealizedPnL += pos.UnrealizedPnL
 
-		leverage := 10
-		if lev, ok := pos["leverage"].(float64); ok {
-			leverage = int(lev)
+		leverage := pos.Leverage
+		if leverage <= 0 {
+			leverage = 10
 		}
-		marginUsed := (quantity * markPrice) / float64(leverage)
-		totalMarginUsed += marginUsed
+		totalMarginUsed += pos.Notional() / float64(leverage)
 	}
 
 	totalPnL := totalEquity - at.initialBalance
@@ -1336,12 +2021,12 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 		marginUsedPct = (totalMarginUsed / totalEquity) * 100
 	}
 
-	return map[string]interface{}{
+	info := map[string]interface{}{
 		// 核心字段
-		"total_equity":      totalEquity,           // 账户净值 = wallet + unrealized
-		"wallet_balance":    totalWalletBalance,    // 钱包余额（不含未实现盈亏）
-		"unrealized_profit": totalUnrealizedProfit, // 未实现盈亏（从API）
-		"available_balance": availableBalance,      // 可用余额
+		"total_equity":      totalEquity,                   // 账户净值 = wallet + unrealized
+		"wallet_balance":    balance.TotalWalletBalance,    // 钱包余额（不含未实现盈亏）
+		"unrealized_profit": balance.TotalUnrealizedProfit, // 未实现盈亏（从API）
+		"available_balance": balance.AvailableBalance,      // 可用余额
 
 		// 盈亏统计
 		"total_pnl":            totalPnL,           // 总盈亏 = equity - initial
@@ -1354,7 +2039,21 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 		"position_count":  len(positions),  // 持仓数量
 		"margin_used":     totalMarginUsed, // 保证金占用
 		"margin_used_pct": marginUsedPct,   // 保证金使用率
-	}, nil
+	}
+
+	// 🪙 单资产保证金模式：按USDT/USDC分别展示余额
+	if balance.MarginAssets != nil {
+		assets := make(map[string]interface{}, len(balance.MarginAssets))
+		for asset, b := range balance.MarginAssets {
+			assets[asset] = map[string]interface{}{
+				"wallet_balance":    b.WalletBalance,
+				"available_balance": b.AvailableBalance,
+				"unrealized_profit": b.UnrealizedProfit,
+			}
+		}
+		info["margin_assets"] = assets
+	}
+	return info, nil
 }
 
 // GetPositions 获取持仓列表（用于API）
@@ -1364,22 +2063,22 @@ func (at *AutoTrader) GetPositions() ([]map[string]interface{}, error) {
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
 
+	// 🆕 从决策日志查找当前持仓的止损止盈
+	protection := at.latestProtectionLevels()
+
 	var result []map[string]interface{}
 	for _, pos := range positions {
-		symbol := pos["symbol"].(string)
-		side := pos["side"].(string)
-		entryPrice := pos["entryPrice"].(float64)
-		markPrice := pos["markPrice"].(float64)
-		quantity := pos["positionAmt"].(float64)
-		if quantity < 0 {
-			quantity = -quantity
-		}
-		unrealizedPnl := pos["unRealizedProfit"].(float64)
-		liquidationPrice := pos["liquidationPrice"].(float64)
-
-		leverage := 10
-		if lev, ok := pos["leverage"].(float64); ok {
-			leverage = int(lev)
+		symbol := pos.Symbol
+		side := pos.Side
+		entryPrice := pos.EntryPrice
+		markPrice := pos.MarkPrice
+		quantity := pos.Quantity
+		unrealizedPnl := pos.UnrealizedPnL
+		liquidationPrice := pos.LiquidationPrice
+
+		leverage := pos.Leverage
+		if leverage <= 0 {
+			leverage = 10
 		}
 
 		pnlPct := 0.0
@@ -1402,6 +2101,10 @@ func (at *AutoTrader) GetPositions() ([]map[string]interface{}, error) {
 			"unrealized_pnl_pct": pnlPct,
 			"liquidation_price":  liquidationPrice,
 			"margin_used":        marginUsed,
+			"stop_loss":          protection[symbol+"_"+side].StopLoss,
+			"take_profit":        protection[symbol+"_"+side].TakeProfit,
+			"manual":             protection[symbol+"_"+side].Manual,
+			"note":               protection[symbol+"_"+side].Note,
 		})
 	}
 
@@ -1418,8 +2121,8 @@ func sortDecisionsByPriority(decisions []decision.Decision) []decision.Decision
 	// 定义优先级
 	getActionPriority := func(action string) int {
 		switch action {
-		case "close_long", "close_short":
-			return 1 // 最高优先级：先平仓
+		case "close_long", "close_short", "replace_position":
+			return 1 // 最高优先级：先平仓（换仓包含平仓）
 		case "open_long", "open_short":
 			return 2 // 次优先级：后开仓
 		case "hold", "wait":
@@ -1529,6 +2232,20 @@ func (at *AutoTrader) runAltcoinScanner() {
 			}
 		}
 
+		// 🆕 限流预算不足或交易所封禁冷却中：跳过本轮扫描，把权重留给交易请求
+		if !ratelimit.For(ratelimit.Binance).AllowNonCritical() {
+			log.Printf("🚧 [扫描 #%d] Binance限流预算不足或冷却中，跳过本次扫描", scanCount)
+			select {
+			case <-ticker.C:
+				continue
+			case <-time.After(scanInterval):
+				if !at.isRunning {
+					return
+				}
+				continue
+			}
+		}
+
 		log.Printf("📊 [扫描 #%d] 使用WebSocket提供的Top%d币种", scanCount, len(top50Symbols))
 
 		// 🆕 先扫描现货期货价差（早期信号 - 捕捉DEX/现货先行）
@@ -1550,6 +2267,9 @@ func (at *AutoTrader) runAltcoinScanner() {
 						sfSignal.SuggestedAction,
 					)
 					log.Printf("      原因: %s", sfSignal.Reasoning)
+					if at.altcoinSignals != nil {
+						at.altcoinSignals.recordSpotFutures(sfSignal)
+					}
 				}
 			} else {
 				log.Printf("✅ [扫描 #%d] 未发现现货期货价差信号", scanCount)
@@ -1564,6 +2284,9 @@ func (at *AutoTrader) runAltcoinScanner() {
 			// 记录每个信号
 			for _, signal := range signals {
 				at.altcoinLogger.LogSignal(signal)
+				if at.altcoinSignals != nil {
+					at.altcoinSignals.recordAnomaly(signal)
+				}
 
 				// 保存JSON（供后续分析）
 				if err := at.altcoinLogger.SaveSignalJSON(signal); err != nil {
@@ -1598,6 +2321,20 @@ func (at *AutoTrader) runAltcoinScanner() {
 	log.Printf("🛑 山寨币异动扫描器已停止")
 }
 
+// quantRegime 当前量化市场体制（A1/A2/B/C，上下文中没有BTC数据时单独获取，失败返回空）
+func (at *AutoTrader) quantRegime(ctx *decision.Context) string {
+	if ctx != nil {
+		if btc, ok := ctx.MarketDataMap["BTCUSDT"]; ok && btc != nil {
+			return market.QuantRegime(btc)
+		}
+	}
+	btc, err := market.GetFrom(at.marketSource, "BTCUSDT")
+	if err != nil {
+		return ""
+	}
+	return market.QuantRegime(btc)
+}
+
 // buildTradeEntry 构建交易记录条目（用于AI记忆系统）
 func (at *AutoTrader) buildTradeEntry(
 	decision *decision.Decision,
@@ -1646,7 +2383,7 @@ func (at *AutoTrader) buildTradeEntry(
 	// 提取持仓信息（如果有）
 	var entryPrice, exitPrice, positionPct float64
 	var holdMinutes int
-	var returnPct float64
+	var returnPct, riskPct float64
 	var result string
 
 	if action == "close" {
@@ -1666,7 +2403,7 @@ func (at *AutoTrader) buildTradeEntry(
 				returnPct = pos.UnrealizedPnLPct
 				if returnPct > 0 {
 					result = "win"
-				} else if returnPct < -0.1 { // 亏损>0.1%才算loss
+				} else if returnPct < -at.memoryManager.BreakEvenPct() { // 亏损超过保本区间才算loss
 					result = "loss"
 				} else {
 					result = "break_even"
@@ -1678,6 +2415,15 @@ func (at *AutoTrader) buildTradeEntry(
 		// 开仓：记录开仓信息，结果为空（需要等待平仓）
 		entryPrice = actionRecord.Price
 		positionPct = (decision.PositionSizeUSD / float64(decision.Leverage)) / ctx.Account.TotalEquity * 100
+
+		// 💰 止损距离（1R，与收益率同口径），用于盈亏归因的R倍数
+		refPrice := entryPrice
+		if refPrice <= 0 {
+			refPrice = decision.LimitPrice
+		}
+		if refPrice > 0 && decision.StopLoss > 0 {
+			riskPct = math.Abs(refPrice-decision.StopLoss) / refPrice * float64(decision.Leverage) * 100
+		}
 	}
 
 	// 🆕 提取限价单信息（如果是限价单开仓）
@@ -1748,6 +2494,7 @@ func (at *AutoTrader) buildTradeEntry(
 		Timestamp:          time.Now(),
 		MarketRegime:       marketRegime,
 		RegimeStage:        regimeStage,
+		QuantRegime:        at.quantRegime(ctx),
 		Action:             action,
 		Symbol:             decision.Symbol,
 		Side:               side,
@@ -1760,9 +2507,10 @@ func (at *AutoTrader) buildTradeEntry(
 		ExitPrice:          exitPrice,
 		PositionPct:        positionPct,
 		Leverage:           decision.Leverage,
-		IsLimitOrder:       isLimitOrder,  // 🆕 限价单标识
-		LimitPrice:         limitPrice,     // 🆕 限价单价格
-		CurrentPrice:       currentPrice,   // 🆕 提交时市价
+		RiskPct:            riskPct,
+		IsLimitOrder:       isLimitOrder, // 🆕 限价单标识
+		LimitPrice:         limitPrice,   // 🆕 限价单价格
+		CurrentPrice:       currentPrice, // 🆕 提交时市价
 		MarketSnapshot:     marketSnapshot,
 		HoldMinutes:        holdMinutes,
 		ReturnPct:          returnPct,
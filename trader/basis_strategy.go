@@ -0,0 +1,505 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"nofx/logger"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 基差策略模式
+const (
+	BasisDirectional  = "directional"   // 方向性：现货溢价做多合约，合约溢价做空合约，基差收敛后平仓
+	BasisDeltaNeutral = "delta_neutral" // 中性：合约溢价时买现货+空合约，基差收敛后同时平掉两腿
+)
+
+// BasisConfig 现货期货基差策略配置（独立于AI决策的风险预算）
+type BasisConfig struct {
+	Mode         string
+	Symbols      []string
+	EntryPct     float64       // 开仓基差阈值（%，绝对值）
+	ExitPct      float64       // 平仓基差阈值（%，绝对值，收敛到此以内平仓）
+	PositionUSDT float64       // 单笔保证金（USDT）
+	BudgetUSDT   float64       // 策略总保证金预算（USDT）
+	MaxLossUSDT  float64       // 策略累计亏损上限（USDT，达到后停止开新仓）
+	Leverage     int           // 合约杠杆
+	StopLossPct  float64       // 方向性持仓止损（价格反向变动%）
+	MaxHold      time.Duration // 最长持仓时间（到期无论基差都平仓）
+	Interval     time.Duration // 检查间隔
+}
+
+// basisPosition 基差策略持仓（持久化，重启后继续管理）
+type basisPosition struct {
+	Symbol       string    `json:"symbol"`
+	Mode         string    `json:"mode"`
+	Side         string    `json:"side"` // 合约方向 long/short
+	Quantity     float64   `json:"quantity"`
+	SpotQuantity float64   `json:"spot_quantity,omitempty"` // 中性模式的现货数量
+	Margin       float64   `json:"margin"`
+	EntryBasis   float64   `json:"entry_basis"`
+	EntryFutures float64   `json:"entry_futures"`
+	EntrySpot    float64   `json:"entry_spot"`
+	OpenTime     time.Time `json:"open_time"`
+}
+
+// basisState 基差策略持久化状态
+type basisState struct {
+	Positions   map[string]*basisPosition `json:"positions"`
+	RealizedPnL float64                   `json:"realized_pnl"`
+	Trades      int                       `json:"trades"`
+}
+
+// BasisStrategy 现货期货基差策略
+// 与AI决策完全隔离：使用独立的保证金预算和亏损上限，策略持仓不出现在AI上下文中，策略币种也不进入AI候选
+type BasisStrategy struct {
+	config   BasisConfig
+	trader   Trader
+	spot     SpotTrader
+	observe  bool                     // 观察模式：只记录信号不下单
+	traderID string                   // 生成客户端订单ID
+	ledger   func(logger.LedgerEntry) // 🏷️ 成交台账（未设置时不记录）
+
+	mu       sync.Mutex
+	state    basisState
+	path     string
+	lastScan time.Time
+	halted   bool // 累计亏损达到上限，停止开新仓
+}
+
+// NewBasisStrategy 创建基差策略（交易器需支持现货交易）
+func NewBasisStrategy(cfg BasisConfig, t Trader, traderID string, observe bool) (*BasisStrategy, error) {
+	spot, ok := t.(SpotTrader)
+	if !ok {
+		return nil, fmt.Errorf("交易所不支持现货交易，无法启用基差策略")
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = BasisDirectional
+	}
+	if cfg.Leverage <= 0 {
+		cfg.Leverage = 2
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+
+	s := &BasisStrategy{
+		config:   cfg,
+		trader:   t,
+		spot:     spot,
+		observe:  observe,
+		traderID: traderID,
+		state:    basisState{Positions: make(map[string]*basisPosition)},
+		path:     filepath.Join("basis_logs", traderID, "state.json"),
+	}
+	s.load()
+	s.halted = cfg.MaxLossUSDT > 0 && -s.state.RealizedPnL >= cfg.MaxLossUSDT
+	return s, nil
+}
+
+// SetLedger 设置成交台账回调（策略开平仓按basis标签记入台账）
+func (s *BasisStrategy) SetLedger(ledger func(logger.LedgerEntry)) {
+	s.ledger = ledger
+}
+
+// clientOrderID 基差策略的客户端订单ID（以当前时间秒数代替决策周期）
+func (s *BasisStrategy) clientOrderID(symbol, action string) string {
+	return ClientOrderID(s.traderID, int(time.Now().Unix()), symbol, action, StrategyBasis)
+}
+
+// recordLedger 写入成交台账
+func (s *BasisStrategy) recordLedger(entry logger.LedgerEntry) {
+	if s.ledger == nil {
+		return
+	}
+	entry.Strategy = StrategyBasis
+	s.ledger(entry)
+}
+
+// load 加载持久化状态
+func (s *BasisStrategy) load() {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️  加载基差策略状态失败: %v", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &s.state); err != nil {
+		log.Printf("⚠️  解析基差策略状态失败: %v", err)
+		return
+	}
+	if s.state.Positions == nil {
+		s.state.Positions = make(map[string]*basisPosition)
+	}
+	if len(s.state.Positions) > 0 {
+		log.Printf("📐 恢复%d个基差策略持仓", len(s.state.Positions))
+	}
+}
+
+// save 持久化状态（调用方持有锁）
+func (s *BasisStrategy) save() {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		log.Printf("⚠️  创建基差策略目录失败: %v", err)
+		return
+	}
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		log.Printf("⚠️  基差策略状态序列化失败: %v", err)
+		return
+	}
+	tmpFile := s.path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		log.Printf("⚠️  写入基差策略状态失败: %v", err)
+		return
+	}
+	if err := os.Rename(tmpFile, s.path); err != nil {
+		log.Printf("⚠️  保存基差策略状态失败: %v", err)
+	}
+}
+
+// Manages 该币种是否由基差策略管理（AI不参与）
+func (s *BasisStrategy) Manages(symbol string) bool {
+	for _, sym := range s.config.Symbols {
+		if sym == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 基差策略主循环（随trader停止退出）
+func (s *BasisStrategy) Run(stopCh <-chan struct{}) {
+	log.Printf("📐 基差策略已启动（%s，%d个币种，开仓阈值%.2f%%，平仓阈值%.2f%%，预算%.0f USDT）",
+		s.config.Mode, len(s.config.Symbols), s.config.EntryPct, s.config.ExitPct, s.config.BudgetUSDT)
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.scan()
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan 检查所有币种的基差，管理已有持仓并寻找新机会
+func (s *BasisStrategy) scan() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastScan = time.Now()
+	s.syncWithExchange()
+
+	for _, symbol := range s.config.Symbols {
+		futuresPrice, err := s.trader.GetMarketPrice(symbol)
+		if err != nil {
+			log.Printf("⚠️  [基差] %s获取合约价格失败: %v", symbol, err)
+			continue
+		}
+		spotPrice, err := s.spot.GetSpotPrice(symbol)
+		if err != nil {
+			log.Printf("⚠️  [基差] %s获取现货价格失败: %v", symbol, err)
+			continue
+		}
+		if futuresPrice <= 0 || spotPrice <= 0 {
+			continue
+		}
+
+		// 基差 = (现货 - 合约) / 合约，正值表示现货溢价
+		basis := (spotPrice - futuresPrice) / futuresPrice * 100
+
+		if pos, ok := s.state.Positions[symbol]; ok {
+			if reason := s.exitReason(pos, basis, futuresPrice); reason != "" {
+				s.close(pos, basis, futuresPrice, spotPrice, reason)
+			}
+			continue
+		}
+
+		side := s.entrySide(basis)
+		if side == "" {
+			continue
+		}
+		if err := s.checkBudget(); err != nil {
+			log.Printf("📐 [基差] %s基差%.2f%%达到开仓阈值，但%v", symbol, basis, err)
+			continue
+		}
+		s.open(symbol, side, basis, futuresPrice, spotPrice)
+	}
+}
+
+// entrySide 根据基差判断开仓方向（不满足条件返回空）
+func (s *BasisStrategy) entrySide(basis float64) string {
+	switch {
+	case basis >= s.config.EntryPct && s.config.Mode == BasisDirectional:
+		return "long" // 现货领先，合约补涨
+	case basis <= -s.config.EntryPct:
+		return "short" // 合约溢价：方向性做空合约；中性模式买现货+空合约
+	}
+	return ""
+}
+
+// exitReason 判断是否平仓（不平仓返回空）
+func (s *BasisStrategy) exitReason(pos *basisPosition, basis, futuresPrice float64) string {
+	if math.Abs(basis) <= s.config.ExitPct {
+		return fmt.Sprintf("基差收敛至%.2f%%", basis)
+	}
+	if s.config.MaxHold > 0 && time.Since(pos.OpenTime) >= s.config.MaxHold {
+		return fmt.Sprintf("持仓超过%v", s.config.MaxHold)
+	}
+	if pos.Mode == BasisDirectional && s.config.StopLossPct > 0 {
+		move := (futuresPrice - pos.EntryFutures) / pos.EntryFutures * 100
+		if pos.Side == "short" {
+			move = -move
+		}
+		if move <= -s.config.StopLossPct {
+			return fmt.Sprintf("触发止损（%.2f%%）", move)
+		}
+	}
+	return ""
+}
+
+// checkBudget 检查策略独立风险预算
+func (s *BasisStrategy) checkBudget() error {
+	if s.halted {
+		return fmt.Errorf("累计亏损%.2f USDT已达上限，停止开新仓", -s.state.RealizedPnL)
+	}
+	used := 0.0
+	for _, pos := range s.state.Positions {
+		used += pos.Margin
+	}
+	if used+s.config.PositionUSDT > s.config.BudgetUSDT {
+		return fmt.Errorf("保证金预算不足（已用%.2f/%.2f USDT）", used, s.config.BudgetUSDT)
+	}
+	return nil
+}
+
+// open 开仓（中性模式先买现货，再按到账数量做空合约）
+func (s *BasisStrategy) open(symbol, side string, basis, futuresPrice, spotPrice float64) {
+	notional := s.config.PositionUSDT * float64(s.config.Leverage)
+	log.Printf("📐 [基差] %s 基差%.2f%%（现货%.4f 合约%.4f），开仓%s（%s，名义价值%.2f USDT）",
+		symbol, basis, spotPrice, futuresPrice, side, s.config.Mode, notional)
+
+	if s.observe {
+		log.Printf("👁️  [基差] 观察模式：跳过下单")
+		return
+	}
+
+	pos := &basisPosition{
+		Symbol:       symbol,
+		Mode:         s.config.Mode,
+		Side:         side,
+		Margin:       s.config.PositionUSDT,
+		EntryBasis:   basis,
+		EntryFutures: futuresPrice,
+		EntrySpot:    spotPrice,
+		OpenTime:     time.Now(),
+	}
+
+	quantity := notional / futuresPrice
+	if s.config.Mode == BasisDeltaNeutral {
+		// 现货腿不加杠杆，合约腿数量与现货到账数量对齐
+		spotQty, err := s.spot.SpotMarketBuy(symbol, s.config.PositionUSDT)
+		if err != nil {
+			log.Printf("❌ [基差] %s现货买入失败: %v", symbol, err)
+			return
+		}
+		pos.SpotQuantity = spotQty
+		pos.Margin = s.config.PositionUSDT + spotQty*futuresPrice/float64(s.config.Leverage)
+		quantity = spotQty
+	}
+
+	formatted, err := s.trader.FormatQuantity(symbol, quantity)
+	if err == nil {
+		fmt.Sscanf(formatted, "%f", &quantity)
+	}
+
+	clientOrderID := s.clientOrderID(symbol, "open_"+side)
+	_, err = placeOrderWithClientID(s.trader, "open_"+side, symbol, quantity, s.config.Leverage, clientOrderID)
+	if err != nil {
+		log.Printf("❌ [基差] %s合约开仓失败: %v", symbol, err)
+		if pos.SpotQuantity > 0 {
+			// 合约腿失败：卖回现货，避免留下裸现货敞口
+			if sellErr := s.spot.SpotMarketSell(symbol, pos.SpotQuantity); sellErr != nil {
+				log.Printf("🚨 [基差] %s现货回滚失败，请手动卖出%.6f: %v", symbol, pos.SpotQuantity, sellErr)
+			}
+		}
+		return
+	}
+	pos.Quantity = quantity
+
+	// 方向性持仓挂交易所止损（程序异常时的兜底保护）
+	if pos.Mode == BasisDirectional && s.config.StopLossPct > 0 {
+		stop := futuresPrice * (1 - s.config.StopLossPct/100)
+		positionSide := "LONG"
+		if side == "short" {
+			stop = futuresPrice * (1 + s.config.StopLossPct/100)
+			positionSide = "SHORT"
+		}
+		if err := s.trader.SetStopLoss(symbol, positionSide, quantity, stop); err != nil {
+			log.Printf("⚠️  [基差] %s设置止损失败: %v", symbol, err)
+		}
+	}
+
+	s.state.Positions[symbol] = pos
+	s.save()
+	s.recordLedger(logger.LedgerEntry{
+		Symbol:        symbol,
+		Side:          side,
+		Action:        "open",
+		Quantity:      quantity,
+		Price:         futuresPrice,
+		ClientOrderID: clientOrderID,
+		Note:          fmt.Sprintf("基差%.2f%%", basis),
+	})
+	log.Printf("✅ [基差] %s %s开仓成功，数量%.6f", symbol, side, quantity)
+}
+
+// close 平仓并结算策略盈亏（合约腿价格盈亏 + 现货腿盈亏，不含手续费）
+func (s *BasisStrategy) close(pos *basisPosition, basis, futuresPrice, spotPrice float64, reason string) {
+	log.Printf("📐 [基差] %s %s平仓：%s", pos.Symbol, pos.Side, reason)
+	if s.observe {
+		return
+	}
+
+	clientOrderID := s.clientOrderID(pos.Symbol, "close_"+pos.Side)
+	_, err := placeOrderWithClientID(s.trader, "close_"+pos.Side, pos.Symbol, 0, 0, clientOrderID)
+	if err != nil {
+		log.Printf("❌ [基差] %s合约平仓失败: %v（下次检查重试）", pos.Symbol, err)
+		return
+	}
+	if err := s.trader.CancelAllOrders(pos.Symbol); err != nil {
+		log.Printf("⚠️  [基差] %s取消止损单失败: %v", pos.Symbol, err)
+	}
+
+	pnl := (futuresPrice - pos.EntryFutures) * pos.Quantity
+	if pos.Side == "short" {
+		pnl = -pnl
+	}
+	if pos.SpotQuantity > 0 {
+		if err := s.spot.SpotMarketSell(pos.Symbol, pos.SpotQuantity); err != nil {
+			log.Printf("🚨 [基差] %s现货卖出失败，请手动卖出%.6f: %v", pos.Symbol, pos.SpotQuantity, err)
+		}
+		pnl += (spotPrice - pos.EntrySpot) * pos.SpotQuantity
+	}
+
+	s.settle(pos.Symbol, pnl)
+	s.recordLedger(logger.LedgerEntry{
+		Symbol:        pos.Symbol,
+		Side:          pos.Side,
+		Action:        "close",
+		Quantity:      pos.Quantity,
+		Price:         futuresPrice,
+		PnL:           pnl,
+		ClientOrderID: clientOrderID,
+		Note:          reason,
+	})
+	log.Printf("✅ [基差] %s平仓完成，基差%.2f%% → %.2f%%，策略盈亏%+.2f USDT（累计%+.2f）",
+		pos.Symbol, pos.EntryBasis, basis, pnl, s.state.RealizedPnL)
+}
+
+// settle 记录已实现盈亏并移除持仓（调用方持有锁）
+func (s *BasisStrategy) settle(symbol string, pnl float64) {
+	s.state.RealizedPnL += pnl
+	s.state.Trades++
+	delete(s.state.Positions, symbol)
+	if s.config.MaxLossUSDT > 0 && -s.state.RealizedPnL >= s.config.MaxLossUSDT && !s.halted {
+		s.halted = true
+		log.Printf("🚨 [基差] 累计亏损%.2f USDT达到上限%.2f，停止开新仓", -s.state.RealizedPnL, s.config.MaxLossUSDT)
+	}
+	s.save()
+}
+
+// syncWithExchange 合约持仓已不存在时（止损触发/手动平仓/强平）清理策略记录（调用方持有锁）
+func (s *BasisStrategy) syncWithExchange() {
+	if len(s.state.Positions) == 0 {
+		return
+	}
+	positions, err := s.trader.GetPositions()
+	if err != nil {
+		log.Printf("⚠️  [基差] 获取持仓失败: %v", err)
+		return
+	}
+
+	open := make(map[string]bool)
+	for _, p := range positions {
+		open[p.Symbol+"_"+p.Side] = true
+	}
+
+	for symbol, pos := range s.state.Positions {
+		if open[symbol+"_"+pos.Side] {
+			continue
+		}
+		log.Printf("⚠️  [基差] %s %s合约持仓已不在交易所（止损或手动平仓）", symbol, pos.Side)
+
+		pnl := 0.0
+		if price, err := s.trader.GetMarketPrice(symbol); err == nil {
+			pnl = (price - pos.EntryFutures) * pos.Quantity
+			if pos.Side == "short" {
+				pnl = -pnl
+			}
+		}
+		if pos.SpotQuantity > 0 {
+			// 合约腿已消失：卖出现货恢复中性
+			spotPrice, _ := s.spot.GetSpotPrice(symbol)
+			if err := s.spot.SpotMarketSell(symbol, pos.SpotQuantity); err != nil {
+				log.Printf("🚨 [基差] %s现货卖出失败，请手动卖出%.6f: %v", symbol, pos.SpotQuantity, err)
+			} else if spotPrice > 0 {
+				pnl += (spotPrice - pos.EntrySpot) * pos.SpotQuantity
+			}
+		}
+		s.settle(symbol, pnl)
+		s.recordLedger(logger.LedgerEntry{
+			Symbol:   symbol,
+			Side:     pos.Side,
+			Action:   "close",
+			Quantity: pos.Quantity,
+			PnL:      pnl,
+			Note:     "合约持仓已不在交易所",
+		})
+	}
+}
+
+// Status 策略状态（用于状态接口）
+func (s *BasisStrategy) Status() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	used := 0.0
+	positions := make([]map[string]interface{}, 0, len(s.state.Positions))
+	for _, pos := range s.state.Positions {
+		used += pos.Margin
+		positions = append(positions, map[string]interface{}{
+			"symbol":        pos.Symbol,
+			"side":          pos.Side,
+			"mode":          pos.Mode,
+			"quantity":      pos.Quantity,
+			"spot_quantity": pos.SpotQuantity,
+			"margin":        pos.Margin,
+			"entry_basis":   pos.EntryBasis,
+			"open_time":     pos.OpenTime.Format(time.RFC3339),
+		})
+	}
+
+	status := map[string]interface{}{
+		"mode":         s.config.Mode,
+		"symbols":      s.config.Symbols,
+		"budget_usdt":  s.config.BudgetUSDT,
+		"used_usdt":    used,
+		"realized_pnl": s.state.RealizedPnL,
+		"trades":       s.state.Trades,
+		"halted":       s.halted,
+		"positions":    positions,
+	}
+	if !s.lastScan.IsZero() {
+		status["last_scan"] = s.lastScan.Format(time.RFC3339)
+	}
+	return status
+}
@@ -0,0 +1,669 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"nofx/errs"
+	"nofx/ratelimit"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/delivery"
+)
+
+// 🪙 币安币本位合约（COIN-M，dapi）
+// 系统内部仍使用U本位的币种名（BTCUSDT）和币数量，下单时换算为永续合约（BTCUSD_PERP）的张数；
+// 保证金和盈亏以币结算，对外返回的余额和盈亏按当前价格折算为USD
+
+const (
+	coinMPerpSuffix  = "USD_PERP"
+	coinMContractTTL = 24 * time.Hour // 合约规格很少变化，每天刷新一次
+)
+
+// coinMContract 币本位永续合约规格
+type coinMContract struct {
+	Symbol       string  // BTCUSD_PERP
+	MarginAsset  string  // 结算币种（BTC）
+	ContractSize float64 // 每张合约面值（USD）
+	PricePrec    int     // 价格精度（PRICE_FILTER tickSize）
+}
+
+// CoinMFuturesTrader 币安币本位合约交易器
+type CoinMFuturesTrader struct {
+	client *delivery.Client
+	clock  *clockSync
+
+	contractsMu sync.RWMutex
+	contracts   map[string]coinMContract // key: BTCUSD_PERP
+	fetchedAt   time.Time
+
+	positionMode binancePositionMode
+	marginMode   func(symbol string) string
+	marginMu     sync.RWMutex
+
+	// 余额缓存（与U本位一致，60秒）
+	cachedBalance     *Balance
+	balanceCacheTime  time.Time
+	balanceCacheMutex sync.RWMutex
+	cacheDuration     time.Duration
+
+	cancelAllOrders bool
+}
+
+// NewCoinMFuturesTrader 创建币本位合约交易器
+func NewCoinMFuturesTrader(apiKey, secretKey string, useTestnet bool) *CoinMFuturesTrader {
+	client := delivery.NewClient(apiKey, secretKey)
+	client.HTTPClient = &http.Client{Transport: ratelimit.NewTransport(nil)}
+	if useTestnet {
+		client.BaseURL = delivery.BaseApiTestnetUrl
+		log.Printf("🧪 使用Binance COIN-M Testnet: %s", client.BaseURL)
+	} else {
+		log.Printf("🪙 使用Binance COIN-M（币本位）主网")
+	}
+
+	clock := newClockSync("binance_coinm", func(ctx context.Context) (int64, error) {
+		return client.NewServerTimeService().Do(ctx)
+	}, func(offsetMs int64) {
+		client.TimeOffset = offsetMs
+	})
+	client.HTTPClient.Transport = &clockTransport{base: client.HTTPClient.Transport, clock: clock}
+	clock.Start()
+
+	return &CoinMFuturesTrader{
+		client:        client,
+		clock:         clock,
+		cacheDuration: 60 * time.Second,
+	}
+}
+
+// coinMSymbol U本位币种名转为币本位永续合约（BTCUSDT → BTCUSD_PERP，已是合约名时原样返回）
+func coinMSymbol(symbol string) string {
+	if strings.Contains(symbol, "_") {
+		return symbol
+	}
+	return strings.TrimSuffix(symbol, "USDT") + coinMPerpSuffix
+}
+
+// usdtSymbol 币本位永续合约转为系统内的币种名（BTCUSD_PERP → BTCUSDT）
+func usdtSymbol(contract string) string {
+	if !strings.HasSuffix(contract, coinMPerpSuffix) {
+		return contract
+	}
+	return strings.TrimSuffix(contract, coinMPerpSuffix) + "USDT"
+}
+
+// coinMPnL 币本位盈亏（以结算币计）：面值 × (1/开仓价 − 1/平仓价)，空仓取反
+func coinMPnL(side string, contracts, contractSize, entryPrice, exitPrice float64) float64 {
+	if entryPrice <= 0 || exitPrice <= 0 {
+		return 0
+	}
+	pnl := contracts * contractSize * (1/entryPrice - 1/exitPrice)
+	if side == "short" {
+		return -pnl
+	}
+	return pnl
+}
+
+// ClockOffset 本地时钟相对交易所服务器的偏差
+func (t *CoinMFuturesTrader) ClockOffset() time.Duration {
+	return t.clock.Offset()
+}
+
+// SetCancelAllOrders all=true时CancelAllOrders撤掉该币种的全部挂单（包括手动下的单）
+func (t *CoinMFuturesTrader) SetCancelAllOrders(all bool) { t.cancelAllOrders = all }
+
+// SetMarginModePolicy 设置按币种的保证金模式（nil=全部逐仓）
+func (t *CoinMFuturesTrader) SetMarginModePolicy(marginMode func(symbol string) string) {
+	t.marginMu.Lock()
+	defer t.marginMu.Unlock()
+	t.marginMode = marginMode
+}
+
+// contract 获取合约规格（缓存过期或找不到时重新拉取exchangeInfo）
+func (t *CoinMFuturesTrader) contract(symbol string) (coinMContract, error) {
+	name := coinMSymbol(symbol)
+	t.contractsMu.RLock()
+	c, ok := t.contracts[name]
+	fresh := time.Since(t.fetchedAt) < coinMContractTTL
+	t.contractsMu.RUnlock()
+	if ok && fresh {
+		return c, nil
+	}
+
+	info, err := t.client.NewExchangeInfoService().Do(context.Background())
+	if err != nil {
+		if ok {
+			return c, nil // 刷新失败时继续使用旧规格
+		}
+		return coinMContract{}, fmt.Errorf("获取币本位合约信息失败: %w", classifyBinanceError(err))
+	}
+
+	contracts := make(map[string]coinMContract, len(info.Symbols))
+	for _, s := range info.Symbols {
+		spec := coinMContract{
+			Symbol:       s.Symbol,
+			MarginAsset:  s.MarginAsset,
+			ContractSize: float64(s.ContractSize),
+			PricePrec:    s.PricePrecision,
+		}
+		for _, filter := range s.Filters {
+			if filter["filterType"] == "PRICE_FILTER" {
+				if tick, ok := filter["tickSize"].(string); ok {
+					spec.PricePrec = calculatePrecision(tick)
+				}
+			}
+		}
+		contracts[s.Symbol] = spec
+	}
+
+	t.contractsMu.Lock()
+	t.contracts = contracts
+	t.fetchedAt = time.Now()
+	t.contractsMu.Unlock()
+
+	c, ok = contracts[name]
+	if !ok {
+		return coinMContract{}, errs.New(errs.InvalidSymbol, "币本位合约 %s 不存在", name)
+	}
+	return c, nil
+}
+
+// toContracts 币数量按当前价格换算为合约张数（向下取整，不足1张时报精度错误）
+func (t *CoinMFuturesTrader) toContracts(symbol string, quantity float64) (int64, coinMContract, error) {
+	c, err := t.contract(symbol)
+	if err != nil {
+		return 0, c, err
+	}
+	price, err := t.GetMarketPrice(symbol)
+	if err != nil {
+		return 0, c, err
+	}
+	contracts := int64(math.Floor(quantity*price/c.ContractSize + 1e-9))
+	if contracts < 1 {
+		return 0, c, errs.New(errs.PrecisionError, "%s 名义价值%.2f USD不足1张合约（每张%.0f USD）",
+			symbol, quantity*price, c.ContractSize)
+	}
+	return contracts, c, nil
+}
+
+// assetPrice 结算币种的USD价格（用于折算余额和盈亏）
+func (t *CoinMFuturesTrader) assetPrice(asset string) (float64, error) {
+	return t.GetMarketPrice(asset + coinMPerpSuffix)
+}
+
+// GetBalance 获取账户余额（各结算币种按当前价格折算为USD，带缓存）
+func (t *CoinMFuturesTrader) GetBalance() (*Balance, error) {
+	t.balanceCacheMutex.RLock()
+	if t.cachedBalance != nil && time.Since(t.balanceCacheTime) < t.cacheDuration {
+		balance := *t.cachedBalance
+		t.balanceCacheMutex.RUnlock()
+		return &balance, nil
+	}
+	t.balanceCacheMutex.RUnlock()
+
+	account, err := t.client.NewGetAccountService().Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("获取币本位账户信息失败: %w", classifyBinanceError(err))
+	}
+
+	var wallet, available, unrealized float64
+	assets := make(map[string]float64)
+	for _, a := range account.Assets {
+		walletCoin, _ := strconv.ParseFloat(a.WalletBalance, 64)
+		unrealizedCoin, _ := strconv.ParseFloat(a.UnrealizedProfit, 64)
+		if walletCoin == 0 && unrealizedCoin == 0 {
+			continue
+		}
+		availableCoin, _ := strconv.ParseFloat(a.AvailableBalance, 64)
+		price, err := t.assetPrice(a.Asset)
+		if err != nil {
+			return nil, fmt.Errorf("获取%s价格失败: %w", a.Asset, err)
+		}
+		wallet += walletCoin * price
+		available += availableCoin * price
+		unrealized += unrealizedCoin * price
+		assets[a.Asset] = walletCoin
+	}
+
+	result := &Balance{
+		TotalWalletBalance:    wallet,
+		AvailableBalance:      available,
+		TotalUnrealizedProfit: unrealized,
+		SettlementAssets:      assets, // 🪙 各结算币种的原始余额（币）
+	}
+	log.Printf("✓ 币本位账户: 折合总余额=%.2f USD, 可用=%.2f USD, 未实现盈亏=%.2f USD（%d个结算币种）",
+		wallet, available, unrealized, len(assets))
+
+	t.balanceCacheMutex.Lock()
+	cached := *result
+	t.cachedBalance = &cached
+	t.balanceCacheTime = time.Now()
+	t.balanceCacheMutex.Unlock()
+	return result, nil
+}
+
+// invalidateBalance 交易后清空余额缓存
+func (t *CoinMFuturesTrader) invalidateBalance() {
+	t.balanceCacheMutex.Lock()
+	t.cachedBalance = nil
+	t.balanceCacheMutex.Unlock()
+}
+
+// coinMPosition 币本位持仓（在统一持仓字段外保留合约张数和结算币盈亏）
+type coinMPosition struct {
+	Position
+	Contracts      float64 // 合约张数（正数）
+	UnrealizedCoin float64 // 未实现盈亏（结算币）
+}
+
+// GetPositions 获取所有持仓（张数换算为币数量，未实现盈亏折算为USD；不缓存，保证开平仓前的检查使用最新持仓）
+func (t *CoinMFuturesTrader) GetPositions() ([]Position, error) {
+	positions, err := t.positions()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Position, 0, len(positions))
+	for _, pos := range positions {
+		result = append(result, pos.Position)
+	}
+	return result, nil
+}
+
+// positions 查询币本位永续持仓（跳过交割合约）
+func (t *CoinMFuturesTrader) positions() ([]coinMPosition, error) {
+	positions, err := t.client.NewGetPositionRiskService().Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("获取币本位持仓失败: %w", classifyBinanceError(err))
+	}
+
+	var result []coinMPosition
+	for _, pos := range positions {
+		contracts, _ := strconv.ParseFloat(pos.PositionAmt, 64)
+		if contracts == 0 || !strings.HasSuffix(pos.Symbol, coinMPerpSuffix) {
+			continue // 跳过无持仓和交割合约
+		}
+		c, err := t.contract(pos.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		markPrice, _ := strconv.ParseFloat(pos.MarkPrice, 64)
+		if markPrice <= 0 {
+			continue
+		}
+		entryPrice, _ := strconv.ParseFloat(pos.EntryPrice, 64)
+		unrealizedCoin, _ := strconv.ParseFloat(pos.UnRealizedProfit, 64)
+		leverage, _ := strconv.Atoi(pos.Leverage)
+		liquidationPrice, _ := strconv.ParseFloat(pos.LiquidationPrice, 64)
+
+		side := "long"
+		if contracts < 0 {
+			side = "short"
+			contracts = -contracts
+		}
+		result = append(result, coinMPosition{
+			Position: Position{
+				Symbol:           usdtSymbol(pos.Symbol),
+				Side:             side,
+				Quantity:         contracts * c.ContractSize / markPrice,
+				EntryPrice:       entryPrice,
+				MarkPrice:        markPrice,
+				UnrealizedPnL:    unrealizedCoin * markPrice,
+				Leverage:         leverage,
+				LiquidationPrice: liquidationPrice,
+			},
+			Contracts:      contracts,
+			UnrealizedCoin: unrealizedCoin,
+		})
+	}
+	return result, nil
+}
+
+// isHedgeMode 账户是否为双向持仓模式（查询失败时按双向处理）
+func (t *CoinMFuturesTrader) isHedgeMode() bool {
+	t.positionMode.once.Do(func() {
+		res, err := t.client.NewGetPositionModeService().Do(context.Background())
+		if err != nil {
+			log.Printf("  ⚠ 查询币本位持仓模式失败，按双向持仓处理: %v", err)
+			t.positionMode.hedge = true
+			return
+		}
+		t.positionMode.hedge = res.DualSidePosition
+	})
+	return t.positionMode.hedge
+}
+
+// positionSideFor 下单使用的 positionSide（双向持仓=LONG/SHORT，单向持仓=BOTH）
+func (t *CoinMFuturesTrader) positionSideFor(side string) delivery.PositionSideType {
+	if !t.isHedgeMode() {
+		return delivery.PositionSideTypeBoth
+	}
+	if side == "long" || side == "LONG" {
+		return delivery.PositionSideTypeLong
+	}
+	return delivery.PositionSideTypeShort
+}
+
+// SetLeverage 设置杠杆
+func (t *CoinMFuturesTrader) SetLeverage(symbol string, leverage int) error {
+	_, err := t.client.NewChangeLeverageService().
+		Symbol(coinMSymbol(symbol)).
+		Leverage(leverage).
+		Do(context.Background())
+	if err != nil {
+		err = classifyBinanceError(err)
+		if errs.Is(err, errs.NoChange) {
+			return nil
+		}
+		return fmt.Errorf("设置杠杆失败: %w", err)
+	}
+	log.Printf("  ✓ %s 杠杆已切换为 %dx", coinMSymbol(symbol), leverage)
+	return nil
+}
+
+// setMarginType 按币种策略设置保证金模式（默认逐仓）
+func (t *CoinMFuturesTrader) setMarginType(symbol string) error {
+	t.marginMu.RLock()
+	marginMode := t.marginMode
+	t.marginMu.RUnlock()
+
+	marginType := delivery.MarginTypeIsolated
+	if marginMode != nil && marginMode(symbol) == MarginCross {
+		marginType = delivery.MarginTypeCrossed
+	}
+	err := t.client.NewChangeMarginTypeService().
+		Symbol(coinMSymbol(symbol)).
+		MarginType(marginType).
+		Do(context.Background())
+	if err != nil {
+		err = classifyBinanceError(err)
+		if errs.Is(err, errs.NoChange) {
+			return nil
+		}
+		return fmt.Errorf("设置保证金模式失败: %w", err)
+	}
+	log.Printf("  ✓ %s 保证金模式已切换为 %s", coinMSymbol(symbol), marginType)
+	return nil
+}
+
+// OpenLong 开多仓（quantity为币数量）
+func (t *CoinMFuturesTrader) OpenLong(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return t.openPosition(symbol, "long", quantity, leverage)
+}
+
+// OpenShort 开空仓（quantity为币数量）
+func (t *CoinMFuturesTrader) OpenShort(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return t.openPosition(symbol, "short", quantity, leverage)
+}
+
+func (t *CoinMFuturesTrader) openPosition(symbol, side string, quantity float64, leverage int) (*OrderResult, error) {
+	if err := t.CancelAllOrders(symbol); err != nil {
+		log.Printf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
+	}
+	if err := t.SetLeverage(symbol, leverage); err != nil {
+		return nil, err
+	}
+	if err := t.setMarginType(symbol); err != nil {
+		return nil, err
+	}
+
+	contracts, c, err := t.toContracts(symbol, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	orderSide := delivery.SideTypeBuy
+	if side == "short" {
+		orderSide = delivery.SideTypeSell
+	}
+	order, err := t.client.NewCreateOrderService().
+		Symbol(c.Symbol).
+		Side(orderSide).
+		PositionSide(t.positionSideFor(side)).
+		Type(delivery.OrderTypeMarket).
+		Quantity(strconv.FormatInt(contracts, 10)).
+		NewClientOrderID(botOrderID("open")).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("币本位开仓失败: %w", classifyBinanceError(err))
+	}
+	t.invalidateBalance()
+
+	log.Printf("✓ 币本位开%s成功: %s %d张（每张%.0f USD，约%.6f %s）",
+		map[string]string{"long": "多", "short": "空"}[side], c.Symbol, contracts, c.ContractSize, quantity, c.MarginAsset)
+
+	return &OrderResult{
+		OrderID:         order.OrderID,
+		ClientOrderID:   order.ClientOrderID,
+		Symbol:          symbol,
+		Status:          string(order.Status),
+		Side:            string(order.Side),
+		Type:            string(order.Type),
+		SettlementAsset: c.MarginAsset,
+	}, nil
+}
+
+// CloseLong 平多仓（quantity为币数量，0表示全部平仓）
+func (t *CoinMFuturesTrader) CloseLong(symbol string, quantity float64) (*OrderResult, error) {
+	return t.closePosition(symbol, "long", quantity)
+}
+
+// CloseShort 平空仓（quantity为币数量，0表示全部平仓）
+func (t *CoinMFuturesTrader) CloseShort(symbol string, quantity float64) (*OrderResult, error) {
+	return t.closePosition(symbol, "short", quantity)
+}
+
+// closePosition 市价平仓，盈亏按结算币计算后折算为USD
+func (t *CoinMFuturesTrader) closePosition(symbol, side string, quantity float64) (*OrderResult, error) {
+	positions, err := t.positions()
+	if err != nil {
+		return nil, err
+	}
+	var pos *coinMPosition
+	for i := range positions {
+		if positions[i].Symbol == symbol && positions[i].Side == side {
+			pos = &positions[i]
+			break
+		}
+	}
+	if pos == nil {
+		return nil, fmt.Errorf("没有找到 %s 的%s仓", symbol, map[string]string{"long": "多", "short": "空"}[side])
+	}
+	held := pos.Contracts
+	entryPrice := pos.EntryPrice
+
+	c, err := t.contract(symbol)
+	if err != nil {
+		return nil, err
+	}
+	contracts := held
+	if quantity > 0 {
+		contracts = math.Min(held, math.Round(quantity*pos.MarkPrice/c.ContractSize))
+		if contracts < 1 {
+			return nil, errs.New(errs.PrecisionError, "%s 平仓数量不足1张合约", symbol)
+		}
+	}
+
+	orderSide := delivery.SideTypeSell
+	if side == "short" {
+		orderSide = delivery.SideTypeBuy
+	}
+	svc := t.client.NewCreateOrderService().
+		Symbol(c.Symbol).
+		Side(orderSide).
+		PositionSide(t.positionSideFor(side)).
+		Type(delivery.OrderTypeMarket).
+		Quantity(strconv.FormatFloat(contracts, 'f', 0, 64)).
+		NewClientOrderID(botOrderID("close"))
+	if !t.isHedgeMode() {
+		svc = svc.ReduceOnly(true)
+	}
+	order, err := svc.Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("币本位平仓失败: %w", classifyBinanceError(err))
+	}
+	t.invalidateBalance()
+
+	if contracts == held {
+		if err := t.CancelAllOrders(symbol); err != nil {
+			log.Printf("  ⚠ 取消挂单失败: %v", err)
+		}
+	}
+
+	// 成交均价计算结算币盈亏，再按成交价折算为USD
+	var pnlCoin, realizedPnL float64
+	detail, err := t.client.NewGetOrderService().Symbol(c.Symbol).OrderID(order.OrderID).Do(context.Background())
+	if err == nil {
+		if avgPrice, _ := strconv.ParseFloat(detail.AvgPrice, 64); avgPrice > 0 {
+			pnlCoin = coinMPnL(side, contracts, c.ContractSize, entryPrice, avgPrice)
+			realizedPnL = pnlCoin * avgPrice
+			log.Printf("  💰 平仓盈亏: 入场%.4f → 平仓%.4f | %+.8f %s（约%+.2f USD）",
+				entryPrice, avgPrice, pnlCoin, c.MarginAsset, realizedPnL)
+		}
+	}
+
+	return &OrderResult{
+		OrderID:         order.OrderID,
+		ClientOrderID:   order.ClientOrderID,
+		Symbol:          symbol,
+		Status:          string(order.Status),
+		Side:            string(order.Side),
+		Type:            string(order.Type),
+		RealizedPnL:     realizedPnL, // USD（未扣手续费）
+		RealizedPnLCoin: pnlCoin,
+		SettlementAsset: c.MarginAsset,
+	}, nil
+}
+
+// GetMarketPrice 获取合约最新价格（USD）
+func (t *CoinMFuturesTrader) GetMarketPrice(symbol string) (float64, error) {
+	prices, err := t.client.NewListPricesService().Symbol(coinMSymbol(symbol)).Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("获取价格失败: %w", classifyBinanceError(err))
+	}
+	if len(prices) == 0 {
+		return 0, errs.New(errs.InvalidSymbol, "未找到 %s 的价格", coinMSymbol(symbol))
+	}
+	return strconv.ParseFloat(prices[0].Price, 64)
+}
+
+// SetStopLoss 设置止损单（closePosition=true，数量由交易所按持仓处理）
+func (t *CoinMFuturesTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	if err := t.placeProtection(symbol, positionSide, delivery.OrderTypeStopMarket, stopPrice, "sl"); err != nil {
+		return fmt.Errorf("设置止损失败: %w", err)
+	}
+	return nil
+}
+
+// SetTakeProfit 设置止盈单（closePosition=true）
+func (t *CoinMFuturesTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	if err := t.placeProtection(symbol, positionSide, delivery.OrderTypeTakeProfitMarket, takeProfitPrice, "tp"); err != nil {
+		return fmt.Errorf("设置止盈失败: %w", err)
+	}
+	return nil
+}
+
+func (t *CoinMFuturesTrader) placeProtection(symbol, positionSide string, orderType delivery.OrderType, price float64, kind string) error {
+	side := delivery.SideTypeBuy
+	if positionSide == "LONG" {
+		side = delivery.SideTypeSell
+	}
+	priceStr, err := t.FormatPrice(symbol, price)
+	if err != nil {
+		return err
+	}
+	_, err = t.client.NewCreateOrderService().
+		Symbol(coinMSymbol(symbol)).
+		Side(side).
+		PositionSide(t.positionSideFor(positionSide)).
+		Type(orderType).
+		StopPrice(priceStr).
+		WorkingType(delivery.WorkingTypeContractPrice).
+		ClosePosition(true).
+		NewClientOrderID(botOrderID(kind)).
+		Do(context.Background())
+	if err != nil {
+		return classifyBinanceError(err)
+	}
+	log.Printf("  %s价设置: %s", map[string]string{"sl": "止损", "tp": "止盈"}[kind], priceStr)
+	return nil
+}
+
+// CancelAllOrders 取消该币种的挂单（默认只撤本系统下的挂单）
+func (t *CoinMFuturesTrader) CancelAllOrders(symbol string) error {
+	name := coinMSymbol(symbol)
+	if t.cancelAllOrders {
+		if err := t.client.NewCancelAllOpenOrdersService().Symbol(name).Do(context.Background()); err != nil {
+			return fmt.Errorf("取消挂单失败: %w", classifyBinanceError(err))
+		}
+		log.Printf("  ✓ 已取消 %s 的所有挂单", name)
+		return nil
+	}
+
+	orders, err := t.client.NewListOpenOrdersService().Symbol(name).Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("获取挂单失败: %w", classifyBinanceError(err))
+	}
+	cancelled, kept := 0, 0
+	for _, order := range orders {
+		if !IsBotOrder(order.ClientOrderID) {
+			kept++
+			continue
+		}
+		if _, err := t.client.NewCancelOrderService().Symbol(name).OrderID(order.OrderID).Do(context.Background()); err != nil {
+			return fmt.Errorf("取消挂单失败: %w", classifyBinanceError(err))
+		}
+		cancelled++
+	}
+	logCancelled(name, cancelled, kept)
+	return nil
+}
+
+// GetOpenOrders 获取指定币种的所有挂单（origQty/executedQty 为合约张数）
+func (t *CoinMFuturesTrader) GetOpenOrders(symbol string) ([]OrderResult, error) {
+	orders, err := t.client.NewListOpenOrdersService().Symbol(coinMSymbol(symbol)).Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("查询挂单失败: %w", classifyBinanceError(err))
+	}
+
+	results := make([]OrderResult, 0, len(orders))
+	for _, order := range orders {
+		result := OrderResult{
+			OrderID:       order.OrderID,
+			ClientOrderID: order.ClientOrderID,
+			Symbol:        symbol,
+			Status:        string(order.Status),
+			Side:          string(order.Side),
+			Type:          string(order.Type),
+			UpdateTime:    order.UpdateTime,
+		}
+		result.Price, _ = strconv.ParseFloat(order.Price, 64)
+		result.OrigQty, _ = strconv.ParseFloat(order.OrigQuantity, 64)
+		result.ExecutedQty, _ = strconv.ParseFloat(order.ExecutedQuantity, 64)
+		result.StopPrice, _ = strconv.ParseFloat(order.StopPrice, 64)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// FormatQuantity 币数量按当前价格换算为合约张数（币本位下单数量单位为张）
+func (t *CoinMFuturesTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
+	contracts, _, err := t.toContracts(symbol, quantity)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(contracts, 10), nil
+}
+
+// FormatPrice 格式化价格到合约的价格精度
+func (t *CoinMFuturesTrader) FormatPrice(symbol string, price float64) (string, error) {
+	c, err := t.contract(symbol)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(price, 'f', c.PricePrec, 64), nil
+}
@@ -0,0 +1,24 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetSymbolStatuses 获取全部合约的交易状态（exchangeInfo，缓存5分钟）
+func (t *FuturesTrader) GetSymbolStatuses(refresh bool) (map[string]string, error) {
+	if cached, ok := t.statuses.get(refresh); ok {
+		return cached, nil
+	}
+
+	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("获取交易规则失败: %w", classifyBinanceError(err))
+	}
+	statuses := make(map[string]string, len(exchangeInfo.Symbols))
+	for _, s := range exchangeInfo.Symbols {
+		statuses[s.Symbol] = s.Status
+	}
+	t.statuses.set(statuses)
+	return statuses, nil
+}
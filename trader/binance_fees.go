@@ -0,0 +1,173 @@
+package trader
+
+import (
+	"context"
+	"log"
+	"nofx/market"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// positionFeeInfo 持仓费用追踪（开仓手续费 + 开仓时间，用于平仓时计算净盈亏）
+type positionFeeInfo struct {
+	OpenTime       time.Time
+	OpenCommission float64 // 开仓手续费（USDT，正数表示支出）
+}
+
+// fillSummary 订单成交汇总（来自 /fapi/v1/userTrades）
+type fillSummary struct {
+	Commission  float64 // 手续费合计（USDT，正数表示支出）
+	RealizedPnL float64 // 交易所计算的毛盈亏（不含手续费）
+	Fills       int     // 成交笔数
+}
+
+// feeTracker 手续费/资金费追踪器（按 symbol_side 记录）
+type feeTracker struct {
+	mu        sync.Mutex
+	positions map[string]*positionFeeInfo
+}
+
+func newFeeTracker() *feeTracker {
+	return &feeTracker{positions: make(map[string]*positionFeeInfo)}
+}
+
+// recordOpen 记录开仓手续费（加仓时累加，开仓时间保持首次开仓）
+func (ft *feeTracker) recordOpen(symbol, side string, commission float64) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	key := symbol + "_" + side
+	if info, exists := ft.positions[key]; exists {
+		info.OpenCommission += commission
+		return
+	}
+	ft.positions[key] = &positionFeeInfo{
+		OpenTime:       time.Now(),
+		OpenCommission: commission,
+	}
+}
+
+// pop 取出并删除持仓费用记录（平仓时使用）
+func (ft *feeTracker) pop(symbol, side string) (*positionFeeInfo, bool) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	key := symbol + "_" + side
+	info, exists := ft.positions[key]
+	if exists {
+		delete(ft.positions, key)
+	}
+	return info, exists
+}
+
+// getOrderFills 查询订单成交明细，汇总手续费和交易所毛盈亏
+// 注意：市价单成交记录可能有少量延迟，查询不到时返回空汇总
+func (t *FuturesTrader) getOrderFills(symbol string, orderID int64) (*fillSummary, error) {
+	trades, err := t.client.NewListAccountTradeService().
+		Symbol(symbol).
+		OrderID(orderID).
+		Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &fillSummary{}
+	for _, trade := range trades {
+		if trade.OrderID != orderID {
+			continue
+		}
+		commission, _ := strconv.ParseFloat(trade.Commission, 64)
+		realizedPnl, _ := strconv.ParseFloat(trade.RealizedPnl, 64)
+		if trade.CommissionAsset != "" && !market.IsQuoteAsset(trade.CommissionAsset) {
+			// BNB抵扣等非USDT/USDC手续费无法直接折算，仅记录日志
+			log.Printf("  ⚠️ %s 订单%d 手续费资产为%s（%.6f），未计入净盈亏",
+				symbol, orderID, trade.CommissionAsset, commission)
+			commission = 0
+		}
+		summary.Commission += commission
+		summary.RealizedPnL += realizedPnl
+		summary.Fills++
+	}
+	return summary, nil
+}
+
+// getFundingFeeSince 查询某币种自指定时间以来的资金费（正数=收取，负数=支付）
+// 注意：币安资金费按币种结算，双向持仓模式下无法区分多空，这里按币种整体累计
+func (t *FuturesTrader) getFundingFeeSince(symbol string, since time.Time) (float64, error) {
+	incomes, err := t.client.NewGetIncomeHistoryService().
+		Symbol(symbol).
+		IncomeType("FUNDING_FEE").
+		StartTime(since.UnixMilli()).
+		Limit(1000).
+		Do(context.Background())
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+	for _, income := range incomes {
+		if income.Asset != "" && !market.IsQuoteAsset(income.Asset) {
+			continue
+		}
+		amount, _ := strconv.ParseFloat(income.Income, 64)
+		total += amount
+	}
+	return total, nil
+}
+
+// recordOpenCommission 开仓成交后记录手续费，返回本次开仓手续费
+func (t *FuturesTrader) recordOpenCommission(symbol, side string, orderID int64) float64 {
+	commission := 0.0
+	if fills, err := t.getOrderFills(symbol, orderID); err != nil {
+		log.Printf("  ⚠️ 查询开仓手续费失败: %v", err)
+	} else {
+		commission = fills.Commission
+	}
+	t.fees.recordOpen(symbol, side, commission)
+	if commission > 0 {
+		log.Printf("  💸 开仓手续费: %.4f USDT", commission)
+	}
+	return commission
+}
+
+// settleCloseFees 平仓后结算费用：平仓手续费、开仓手续费、持仓期间资金费
+// grossPnL 为按价格差计算的毛盈亏，交易所成交记录可用时优先使用交易所数据
+// fullClose=false（部分平仓）时只扣除平仓手续费，开仓费和资金费留到全部平仓时结算
+// 费用和净盈亏写入平仓结果
+func (t *FuturesTrader) settleCloseFees(result *OrderResult, side string, grossPnL float64, fullClose bool) {
+	symbol := result.Symbol
+	closeCommission := 0.0
+	if fills, err := t.getOrderFills(symbol, result.OrderID); err != nil {
+		log.Printf("  ⚠️ 查询平仓手续费失败: %v", err)
+	} else if fills.Fills > 0 {
+		closeCommission = fills.Commission
+		grossPnL = fills.RealizedPnL
+	}
+
+	openCommission := 0.0
+	fundingFee := 0.0
+	if !fullClose {
+		// 部分平仓：持仓费用记录保留
+	} else if info, exists := t.fees.pop(symbol, side); exists {
+		openCommission = info.OpenCommission
+		if funding, err := t.getFundingFeeSince(symbol, info.OpenTime); err != nil {
+			log.Printf("  ⚠️ 查询资金费失败: %v", err)
+		} else {
+			fundingFee = funding
+		}
+	} else {
+		// 系统重启前开的仓（或限价单成交），无开仓记录，仅计平仓手续费
+		log.Printf("  ⚠️ %s %s 无开仓费用记录，净盈亏仅扣除平仓手续费", symbol, side)
+	}
+
+	netPnL := grossPnL - openCommission - closeCommission + fundingFee
+	log.Printf("  🧾 费用明细: 毛盈亏%+.4f | 开仓费-%.4f | 平仓费-%.4f | 资金费%+.4f → 净盈亏%+.4f USDT",
+		grossPnL, openCommission, closeCommission, fundingFee, netPnL)
+
+	result.GrossPnL = grossPnL
+	result.OpenCommission = openCommission
+	result.Commission = closeCommission
+	result.FundingFee = fundingFee
+	result.RealizedPnL = netPnL
+}
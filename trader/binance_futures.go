@@ -5,72 +5,104 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"net/http"
+	"nofx/errs"
+	"nofx/market"
+	"nofx/ratelimit"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/adshao/go-binance/v2"
 	"github.com/adshao/go-binance/v2/futures"
 )
 
-// CloseInfo 平仓信息（用于动态冷却期）
-type CloseInfo struct {
-	Time       time.Time
-	RealizedPnL float64 // 已实现盈亏
-}
-
 // FuturesTrader 币安合约交易器
 type FuturesTrader struct {
-	client *futures.Client
+	client       *futures.Client
+	spot         *binance.Client      // 🆕 现货客户端（基差策略的现货腿）
+	clock        *clockSync           // 🆕 服务器时间同步（签名timestamp校正）
+	margin       binanceMarginState   // 🆕 按币种保证金模式 + 杠杆档位缓存
+	positionMode binancePositionMode  // 🆕 账户持仓模式（双向/单向）
+	statuses     symbolStatusCache    // 🚧 交易对状态缓存（SETTLING/BREAK等）
+	precisions   symbolPrecisionCache // 📐 数量/价格精度缓存（启动时预取，每天刷新）
 
 	// 余额缓存
-	cachedBalance     map[string]interface{}
+	cachedBalance     *Balance
 	balanceCacheTime  time.Time
 	balanceCacheMutex sync.RWMutex
 
 	// 持仓缓存
-	cachedPositions     []map[string]interface{}
+	cachedPositions     []Position
 	positionsCacheTime  time.Time
 	positionsCacheMutex sync.RWMutex
 
-	// 冷却期管理：记录每个币种的平仓信息（时间+盈亏）
-	lastCloseInfos     map[string]CloseInfo
-	closeTimeMutex     sync.RWMutex
-	cooldownDuration   time.Duration // 默认冷却期（盈利时）
+	// 🆕 手续费/资金费追踪（平仓时计算净盈亏）
+	fees *feeTracker
 
 	// 缓存有效期（60秒）- 防止API限流
 	cacheDuration time.Duration
+
+	// 📣 移动止损更新回调（推送事件用，可为nil）
+	onStopMoved StopMovedHandler
+
+	// 🏷️ 撤单范围：false（默认）只撤本系统下的挂单，true撤掉全部挂单
+	cancelAllOrders bool
+
+	// 🙋 返回true时跳过该持仓的移动止损（人工管理的持仓，可为nil）
+	skipTrailing func(symbol, side string) bool
 }
 
 // NewFuturesTrader 创建合约交易器
 func NewFuturesTrader(apiKey, secretKey string, useTestnet bool) *FuturesTrader {
 	client := futures.NewClient(apiKey, secretKey)
+	client.HTTPClient = &http.Client{Transport: ratelimit.NewTransport(nil)} // 🆕 统一限流预算（-1003封禁时进入冷却）
+	spot := binance.NewClient(apiKey, secretKey)
+	spot.HTTPClient = &http.Client{Transport: ratelimit.NewTransport(nil)}
 
 	// 如果使用testnet，设置测试网URL
 	if useTestnet {
 		client.BaseURL = "https://testnet.binancefuture.com"
+		spot.BaseURL = "https://testnet.binance.vision"
 		log.Printf("🧪 使用Binance Futures Testnet: %s", client.BaseURL)
 	} else {
 		log.Printf("💰 使用Binance Futures主网")
 	}
 
+	// 🆕 服务器时间同步：签名请求的timestamp按偏差校正，遇到-1021立即重新校准
+	clock := newClockSync("binance", func(ctx context.Context) (int64, error) {
+		return client.NewServerTimeService().Do(ctx)
+	}, func(offsetMs int64) {
+		client.TimeOffset = offsetMs
+	})
+	client.HTTPClient.Transport = &clockTransport{base: client.HTTPClient.Transport, clock: clock}
+	clock.Start()
+
 	return &FuturesTrader{
-		client:           client,
-		cacheDuration:    60 * time.Second,  // 60秒缓存（防止币安API限流封禁）
-		lastCloseInfos:   make(map[string]CloseInfo), // 初始化冷却期记录
-		cooldownDuration: 10 * time.Minute,  // 默认10分钟（盈利时）
+		client:        client,
+		spot:          spot,
+		clock:         clock,
+		cacheDuration: 60 * time.Second, // 60秒缓存（防止币安API限流封禁）
+		fees:          newFeeTracker(),
 	}
 }
 
+// ClockOffset 本地时钟相对交易所服务器的偏差
+func (t *FuturesTrader) ClockOffset() time.Duration {
+	return t.clock.Offset()
+}
+
 // GetBalance 获取账户余额（带缓存）
-func (t *FuturesTrader) GetBalance() (map[string]interface{}, error) {
+func (t *FuturesTrader) GetBalance() (*Balance, error) {
 	// 先检查缓存是否有效
 	t.balanceCacheMutex.RLock()
 	if t.cachedBalance != nil && time.Since(t.balanceCacheTime) < t.cacheDuration {
 		cacheAge := time.Since(t.balanceCacheTime)
+		balance := *t.cachedBalance
 		t.balanceCacheMutex.RUnlock()
 		log.Printf("✓ 使用缓存的账户余额（缓存时间: %.1f秒前）", cacheAge.Seconds())
-		return t.cachedBalance, nil
+		return &balance, nil
 	}
 	t.balanceCacheMutex.RUnlock()
 
@@ -79,22 +111,28 @@ func (t *FuturesTrader) GetBalance() (map[string]interface{}, error) {
 	account, err := t.client.NewGetAccountService().Do(context.Background())
 	if err != nil {
 		log.Printf("❌ 币安API调用失败: %v", err)
-		return nil, fmt.Errorf("获取账户信息失败: %w", err)
+		return nil, fmt.Errorf("获取账户信息失败: %w", classifyBinanceError(err))
 	}
 
-	result := make(map[string]interface{})
-	result["totalWalletBalance"], _ = strconv.ParseFloat(account.TotalWalletBalance, 64)
-	result["availableBalance"], _ = strconv.ParseFloat(account.AvailableBalance, 64)
-	result["totalUnrealizedProfit"], _ = strconv.ParseFloat(account.TotalUnrealizedProfit, 64)
+	result := &Balance{}
+	result.TotalWalletBalance, _ = strconv.ParseFloat(account.TotalWalletBalance, 64)
+	result.AvailableBalance, _ = strconv.ParseFloat(account.AvailableBalance, 64)
+	result.TotalUnrealizedProfit, _ = strconv.ParseFloat(account.TotalUnrealizedProfit, 64)
 
 	log.Printf("✓ 币安API返回: 总余额=%s, 可用=%s, 未实现盈亏=%s",
 		account.TotalWalletBalance,
 		account.AvailableBalance,
 		account.TotalUnrealizedProfit)
 
+	// 🪙 单资产模式下账户汇总只含USDT，USDC保证金独立：按资产记录余额，USDC按1:1计入总额
+	if !account.MultiAssetsMargin {
+		addMarginAssets(result, account.Assets)
+	}
+
 	// 更新缓存
 	t.balanceCacheMutex.Lock()
-	t.cachedBalance = result
+	cached := *result
+	t.cachedBalance = &cached
 	t.balanceCacheTime = time.Now()
 	t.balanceCacheMutex.Unlock()
 
@@ -102,14 +140,15 @@ func (t *FuturesTrader) GetBalance() (map[string]interface{}, error) {
 }
 
 // GetPositions 获取所有持仓（带缓存）
-func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
+func (t *FuturesTrader) GetPositions() ([]Position, error) {
 	// 先检查缓存是否有效
 	t.positionsCacheMutex.RLock()
 	if t.cachedPositions != nil && time.Since(t.positionsCacheTime) < t.cacheDuration {
 		cacheAge := time.Since(t.positionsCacheTime)
+		positions := append([]Position(nil), t.cachedPositions...)
 		t.positionsCacheMutex.RUnlock()
 		log.Printf("✓ 使用缓存的持仓信息（缓存时间: %.1f秒前）", cacheAge.Seconds())
-		return t.cachedPositions, nil
+		return positions, nil
 	}
 	t.positionsCacheMutex.RUnlock()
 
@@ -117,44 +156,46 @@ func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
 	log.Printf("🔄 缓存过期，正在调用币安API获取持仓信息...")
 	positions, err := t.client.NewGetPositionRiskService().Do(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("获取持仓失败: %w", err)
+		return nil, fmt.Errorf("获取持仓失败: %w", classifyBinanceError(err))
 	}
 
-	var result []map[string]interface{}
+	var result []Position
 	for _, pos := range positions {
 		posAmt, _ := strconv.ParseFloat(pos.PositionAmt, 64)
 		if posAmt == 0 {
 			continue // 跳过无持仓的
 		}
 
-		posMap := make(map[string]interface{})
-		posMap["symbol"] = pos.Symbol
-		posMap["positionAmt"], _ = strconv.ParseFloat(pos.PositionAmt, 64)
-		posMap["entryPrice"], _ = strconv.ParseFloat(pos.EntryPrice, 64)
-		posMap["markPrice"], _ = strconv.ParseFloat(pos.MarkPrice, 64)
-		posMap["unRealizedProfit"], _ = strconv.ParseFloat(pos.UnRealizedProfit, 64)
-		posMap["leverage"], _ = strconv.ParseFloat(pos.Leverage, 64)
-		posMap["liquidationPrice"], _ = strconv.ParseFloat(pos.LiquidationPrice, 64)
+		p := Position{Symbol: pos.Symbol, Quantity: math.Abs(posAmt)}
+		p.EntryPrice, _ = strconv.ParseFloat(pos.EntryPrice, 64)
+		p.MarkPrice, _ = strconv.ParseFloat(pos.MarkPrice, 64)
+		p.UnrealizedPnL, _ = strconv.ParseFloat(pos.UnRealizedProfit, 64)
+		p.Leverage, _ = strconv.Atoi(pos.Leverage)
+		p.LiquidationPrice, _ = strconv.ParseFloat(pos.LiquidationPrice, 64)
 
 		// 判断方向
 		if posAmt > 0 {
-			posMap["side"] = "long"
+			p.Side = "long"
 		} else {
-			posMap["side"] = "short"
+			p.Side = "short"
 		}
 
-		result = append(result, posMap)
+		result = append(result, p)
 	}
 
 	// 动态移动止损逻辑（在缓存更新前执行）
-	for _, posMap := range result {
-		symbol := posMap["symbol"].(string)
-		side := posMap["side"].(string)
-		entryPrice := posMap["entryPrice"].(float64) // 需要入场价用于保本保护
-		markPrice := posMap["markPrice"].(float64)
-		unRealizedProfit := posMap["unRealizedProfit"].(float64)
-		leverage := int(posMap["leverage"].(float64))
-		positionAmt := posMap["positionAmt"].(float64)
+	for _, pos := range result {
+		symbol := pos.Symbol
+		side := pos.Side
+		entryPrice := pos.EntryPrice // 需要入场价用于保本保护
+		markPrice := pos.MarkPrice
+		unRealizedProfit := pos.UnrealizedPnL
+		leverage := pos.Leverage
+		positionAmt := pos.Quantity
+
+		if t.skipTrailing != nil && t.skipTrailing(symbol, side) {
+			continue // 🙋 人工管理的持仓不移动止损
+		}
 
 		// 🔧 修复：使用盈利百分比而不是价格变动百分比
 		// 问题：之前使用价格变动（0.75%），但6倍杠杆时盈利是4.49%
@@ -205,18 +246,18 @@ func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
 		// 新策略：止损 = 入场价 + (当前价格 - 入场价) × 保护比例
 		// 例如：价格涨3%，保护70%利润 → 止损在入场价+2.1%
 		var newStopLoss float64
-		var protectionRatio float64  // 利润保护比例
+		var protectionRatio float64 // 利润保护比例
 
 		if priceMovePct >= 10.0 {
-			protectionRatio = 0.80  // 价格涨≥10%，保护80%利润
+			protectionRatio = 0.80 // 价格涨≥10%，保护80%利润
 		} else if priceMovePct >= 7.0 {
-			protectionRatio = 0.70  // 价格涨≥7%，保护70%利润
+			protectionRatio = 0.70 // 价格涨≥7%，保护70%利润
 		} else if priceMovePct >= 5.0 {
-			protectionRatio = 0.60  // 价格涨≥5%，保护60%利润
+			protectionRatio = 0.60 // 价格涨≥5%，保护60%利润
 		} else if priceMovePct >= 3.0 {
-			protectionRatio = 0.50  // 价格涨≥3%，保护50%利润
+			protectionRatio = 0.50 // 价格涨≥3%，保护50%利润
 		} else {
-			protectionRatio = 0.40  // 价格涨<3%，保护40%利润（最低保护）
+			protectionRatio = 0.40 // 价格涨<3%，保护40%利润（最低保护）
 		}
 
 		if side == "long" {
@@ -232,9 +273,9 @@ func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
 		// 计算保本价
 		var breakEvenPrice float64
 		if side == "long" {
-			breakEvenPrice = entryPrice * 1.001  // 保本价（入场价+0.1%手续费）
+			breakEvenPrice = entryPrice * 1.001 // 保本价（入场价+0.1%手续费）
 		} else {
-			breakEvenPrice = entryPrice * 0.999  // 保本价（入场价-0.1%手续费）
+			breakEvenPrice = entryPrice * 0.999 // 保本价（入场价-0.1%手续费）
 		}
 
 		// 获取当前止损订单
@@ -288,26 +329,29 @@ func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
 			}
 		}
 
-			if shouldUpdate {
-				// 更新止损
-				err := t.updateStopLoss(symbol, side, positionAmt, newStopLoss)
-				if err != nil {
-					log.Printf("⚠️  [移动止损失败] %s %s: %v", symbol, side, err)
+		if shouldUpdate {
+			// 更新止损
+			err := t.updateStopLoss(symbol, side, positionAmt, newStopLoss)
+			if err != nil {
+				log.Printf("⚠️  [移动止损失败] %s %s: %v", symbol, side, err)
+			} else {
+				if t.onStopMoved != nil {
+					t.onStopMoved(symbol, side, oldStopLoss, newStopLoss)
+				}
+				if oldStopLoss > 0 {
+					log.Printf("📈 [移动止损] %s %s | 盈利%.2f%% (价格变动%.2f%%) | 当前价%.4f | 止损 %.4f → %.4f | 保护%.0f%%利润",
+						symbol, strings.ToUpper(side), profitPct, priceMovePct, markPrice, oldStopLoss, newStopLoss, protectionRatio*100)
 				} else {
-					if oldStopLoss > 0 {
-						log.Printf("📈 [移动止损] %s %s | 盈利%.2f%% (价格变动%.2f%%) | 当前价%.4f | 止损 %.4f → %.4f | 保护%.0f%%利润",
-							symbol, strings.ToUpper(side), profitPct, priceMovePct, markPrice, oldStopLoss, newStopLoss, protectionRatio*100)
-					} else {
-						log.Printf("📈 [设置止损] %s %s | 盈利%.2f%% (价格变动%.2f%%) | 当前价%.4f | 新止损 %.4f | 保护%.0f%%利润",
-							symbol, strings.ToUpper(side), profitPct, priceMovePct, markPrice, newStopLoss, protectionRatio*100)
-					}
+					log.Printf("📈 [设置止损] %s %s | 盈利%.2f%% (价格变动%.2f%%) | 当前价%.4f | 新止损 %.4f | 保护%.0f%%利润",
+						symbol, strings.ToUpper(side), profitPct, priceMovePct, markPrice, newStopLoss, protectionRatio*100)
 				}
 			}
+		}
 	}
 
 	// 更新缓存
 	t.positionsCacheMutex.Lock()
-	t.cachedPositions = result
+	t.cachedPositions = append([]Position(nil), result...)
 	t.positionsCacheTime = time.Now()
 	t.positionsCacheMutex.Unlock()
 
@@ -346,11 +390,9 @@ func (t *FuturesTrader) SetLeverage(symbol string, leverage int) error {
 	positions, err := t.GetPositions()
 	if err == nil {
 		for _, pos := range positions {
-			if pos["symbol"] == symbol {
-				if lev, ok := pos["leverage"].(float64); ok {
-					currentLeverage = int(lev)
-					break
-				}
+			if pos.Symbol == symbol {
+				currentLeverage = pos.Leverage
+				break
 			}
 		}
 	}
@@ -368,8 +410,9 @@ func (t *FuturesTrader) SetLeverage(symbol string, leverage int) error {
 		Do(context.Background())
 
 	if err != nil {
-		// 如果错误信息包含"No need to change"，说明杠杆已经是目标值
-		if contains(err.Error(), "No need to change") {
+		err = classifyBinanceError(err)
+		// 杠杆已经是目标值
+		if errs.Is(err, errs.NoChange) {
 			log.Printf("  ✓ %s 杠杆已是 %dx", symbol, leverage)
 			return nil
 		}
@@ -384,73 +427,6 @@ func (t *FuturesTrader) SetLeverage(symbol string, leverage int) error {
 	return nil
 }
 
-// checkCooldown 检查币种是否在冷却期内（动态冷却期）
-func (t *FuturesTrader) checkCooldown(symbol string) error {
-	t.closeTimeMutex.RLock()
-	closeInfo, exists := t.lastCloseInfos[symbol]
-	t.closeTimeMutex.RUnlock()
-
-	if !exists {
-		// 从未平仓过，允许开仓
-		return nil
-	}
-
-	// 动态计算冷却期：
-	// - 盈利：10分钟冷却（保持策略运行）
-	// - 小亏（<5 USDT）：20分钟冷却
-	// - 中亏（5-20 USDT）：30分钟冷却
-	// - 大亏（>20 USDT）：60分钟冷却
-	var cooldown time.Duration
-	if closeInfo.RealizedPnL >= 0 {
-		cooldown = 10 * time.Minute // 盈利
-	} else if closeInfo.RealizedPnL > -5 {
-		cooldown = 20 * time.Minute // 小亏
-	} else if closeInfo.RealizedPnL > -20 {
-		cooldown = 30 * time.Minute // 中亏
-	} else {
-		cooldown = 60 * time.Minute // 大亏
-	}
-
-	elapsed := time.Since(closeInfo.Time)
-	if elapsed < cooldown {
-		remaining := cooldown - elapsed
-		pnlStr := fmt.Sprintf("%+.2f", closeInfo.RealizedPnL)
-		return fmt.Errorf("%s在冷却期内（上次盈亏%s USDT，冷却%.0f分钟，已过%.0f分钟，还需%.0f分钟）",
-			symbol,
-			pnlStr,
-			cooldown.Minutes(),
-			elapsed.Minutes(),
-			remaining.Minutes())
-	}
-
-	return nil
-}
-
-// recordCloseTime 记录平仓时间和盈亏（用于动态冷却期）
-func (t *FuturesTrader) recordCloseTime(symbol string, realizedPnL float64) {
-	t.closeTimeMutex.Lock()
-	t.lastCloseInfos[symbol] = CloseInfo{
-		Time:       time.Now(),
-		RealizedPnL: realizedPnL,
-	}
-	t.closeTimeMutex.Unlock()
-
-	// 计算冷却期时长
-	var cooldown time.Duration
-	if realizedPnL >= 0 {
-		cooldown = 10 * time.Minute
-	} else if realizedPnL > -5 {
-		cooldown = 20 * time.Minute
-	} else if realizedPnL > -20 {
-		cooldown = 30 * time.Minute
-	} else {
-		cooldown = 60 * time.Minute
-	}
-
-	log.Printf("  🕐 已记录 %s 平仓（盈亏%+.2f USDT），冷却%.0f分钟",
-		symbol, realizedPnL, cooldown.Minutes())
-}
-
 // SetMarginType 设置保证金模式
 func (t *FuturesTrader) SetMarginType(symbol string, marginType futures.MarginType) error {
 	err := t.client.NewChangeMarginTypeService().
@@ -459,13 +435,14 @@ func (t *FuturesTrader) SetMarginType(symbol string, marginType futures.MarginTy
 		Do(context.Background())
 
 	if err != nil {
+		err = classifyBinanceError(err)
 		// 如果已经是该模式，不算错误
-		if contains(err.Error(), "No need to change") {
+		if errs.Is(err, errs.NoChange) {
 			log.Printf("  ✓ %s 保证金模式已是 %s", symbol, marginType)
 			return nil
 		}
 		// 如果是多资产模式冲突，跳过保证金模式设置
-		if contains(err.Error(), "-4168") || contains(err.Error(), "Multi-Assets mode") {
+		if errs.CodeOf(err) == binanceCodeMultiAssets {
 			log.Printf("  ⚠ %s 检测到多资产模式，跳过保证金模式设置", symbol)
 			return nil
 		}
@@ -482,12 +459,12 @@ func (t *FuturesTrader) SetMarginType(symbol string, marginType futures.MarginTy
 }
 
 // OpenLong 开多仓
-func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
-	// ✅ 冷却期检查：防止同币种频繁交易
-	if err := t.checkCooldown(symbol); err != nil {
-		return nil, err
-	}
+func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return t.OpenLongWithClientID(symbol, quantity, leverage, "")
+}
 
+// OpenLongWithClientID 开多仓（携带客户端订单ID，用于幂等防重复执行，为空时由交易所生成）
+func (t *FuturesTrader) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
 	// 先取消该币种的所有委托单（清理旧的止损止盈单）
 	if err := t.CancelAllOrders(symbol); err != nil {
 		log.Printf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
@@ -498,8 +475,8 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 		return nil, err
 	}
 
-	// 设置逐仓模式
-	if err := t.SetMarginType(symbol, futures.MarginTypeIsolated); err != nil {
+	// 设置保证金模式（按币种策略，默认逐仓）
+	if err := t.SetMarginType(symbol, t.marginTypeFor(symbol)); err != nil {
 		return nil, err
 	}
 
@@ -536,16 +513,19 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 	}
 
 	// 创建市价买入订单
-	order, err := t.client.NewCreateOrderService().
+	orderService := t.client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(futures.SideTypeBuy).
-		PositionSide(futures.PositionSideTypeLong).
+		PositionSide(t.positionSideFor("long")).
 		Type(futures.OrderTypeMarket).
-		Quantity(quantityStr).
-		Do(context.Background())
+		Quantity(quantityStr)
+	if clientOrderID != "" {
+		orderService = orderService.NewClientOrderID(clientOrderID)
+	}
+	order, err := orderService.Do(context.Background())
 
 	if err != nil {
-		return nil, fmt.Errorf("开多仓失败: %w", err)
+		return nil, fmt.Errorf("开多仓失败: %w", classifyBinanceError(err))
 	}
 
 	log.Printf("✓ 开多仓成功: %s 数量: %s", symbol, quantityStr)
@@ -554,20 +534,27 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 	// ✅ 修复: 交易后立即清空缓存，确保下次查询返回最新的余额和持仓
 	t.invalidateCache()
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	return result, nil
+	// 🆕 记录开仓手续费（平仓时从盈亏中扣除）
+	commission := t.recordOpenCommission(symbol, "long", order.OrderID)
+
+	return &OrderResult{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        string(order.Status),
+		Side:          string(order.Side),
+		Type:          string(order.Type),
+		Commission:    commission,
+	}, nil
 }
 
 // OpenShort 开空仓
-func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
-	// ✅ 冷却期检查：防止同币种频繁交易
-	if err := t.checkCooldown(symbol); err != nil {
-		return nil, err
-	}
+func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return t.OpenShortWithClientID(symbol, quantity, leverage, "")
+}
 
+// OpenShortWithClientID 开空仓（携带客户端订单ID，用于幂等防重复执行，为空时由交易所生成）
+func (t *FuturesTrader) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
 	// 先取消该币种的所有委托单（清理旧的止损止盈单）
 	if err := t.CancelAllOrders(symbol); err != nil {
 		log.Printf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
@@ -578,8 +565,8 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 		return nil, err
 	}
 
-	// 设置逐仓模式
-	if err := t.SetMarginType(symbol, futures.MarginTypeIsolated); err != nil {
+	// 设置保证金模式（按币种策略，默认逐仓）
+	if err := t.SetMarginType(symbol, t.marginTypeFor(symbol)); err != nil {
 		return nil, err
 	}
 
@@ -616,16 +603,19 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 	}
 
 	// 创建市价卖出订单
-	order, err := t.client.NewCreateOrderService().
+	orderService := t.client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(futures.SideTypeSell).
-		PositionSide(futures.PositionSideTypeShort).
+		PositionSide(t.positionSideFor("short")).
 		Type(futures.OrderTypeMarket).
-		Quantity(quantityStr).
-		Do(context.Background())
+		Quantity(quantityStr)
+	if clientOrderID != "" {
+		orderService = orderService.NewClientOrderID(clientOrderID)
+	}
+	order, err := orderService.Do(context.Background())
 
 	if err != nil {
-		return nil, fmt.Errorf("开空仓失败: %w", err)
+		return nil, fmt.Errorf("开空仓失败: %w", classifyBinanceError(err))
 	}
 
 	log.Printf("✓ 开空仓成功: %s 数量: %s", symbol, quantityStr)
@@ -634,19 +624,33 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 	// ✅ 修复: 交易后立即清空缓存，确保下次查询返回最新的余额和持仓
 	t.invalidateCache()
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	return result, nil
+	// 🆕 记录开仓手续费（平仓时从盈亏中扣除）
+	commission := t.recordOpenCommission(symbol, "short", order.OrderID)
+
+	return &OrderResult{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        string(order.Status),
+		Side:          string(order.Side),
+		Type:          string(order.Type),
+		Commission:    commission,
+	}, nil
 }
 
 // CloseLong 平多仓
-func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (*OrderResult, error) {
+	return t.CloseLongWithClientID(symbol, quantity, "")
+}
+
+// CloseLongWithClientID 平多仓（携带客户端订单ID，用于幂等防重复执行，为空时由交易所生成）
+func (t *FuturesTrader) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (*OrderResult, error) {
 	// ✅ 修复: 平仓前获取持仓信息以计算realized_pnl
 	var entryPrice float64
 	var positionAmt float64
 
+	fullClose := quantity == 0
+
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -654,13 +658,10 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 			return nil, err
 		}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "long" {
-				quantity = pos["positionAmt"].(float64)
-				positionAmt = quantity
-				entryPrice = pos["entryPrice"].(float64)
-				break
-			}
+		if pos := FindPosition(positions, symbol, "long"); pos != nil {
+			quantity = pos.Quantity
+			positionAmt = quantity
+			entryPrice = pos.EntryPrice
 		}
 
 		if quantity == 0 {
@@ -670,12 +671,9 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 		// 如果指定了数量，也需要获取入场价
 		positions, err := t.GetPositions()
 		if err == nil {
-			for _, pos := range positions {
-				if pos["symbol"] == symbol && pos["side"] == "long" {
-					entryPrice = pos["entryPrice"].(float64)
-					positionAmt = quantity
-					break
-				}
+			if pos := FindPosition(positions, symbol, "long"); pos != nil {
+				entryPrice = pos.EntryPrice
+				positionAmt = quantity
 			}
 		}
 	}
@@ -686,17 +684,20 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 		return nil, err
 	}
 
-	// 创建市价卖出订单（平多）
-	order, err := t.client.NewCreateOrderService().
+	// 创建市价卖出订单（平多，只减仓）
+	orderService := t.reduceOnly(t.client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(futures.SideTypeSell).
-		PositionSide(futures.PositionSideTypeLong).
+		PositionSide(t.positionSideFor("long")).
 		Type(futures.OrderTypeMarket).
-		Quantity(quantityStr).
-		Do(context.Background())
+		Quantity(quantityStr))
+	if clientOrderID != "" {
+		orderService = orderService.NewClientOrderID(clientOrderID)
+	}
+	order, err := orderService.Do(context.Background())
 
 	if err != nil {
-		return nil, fmt.Errorf("平多仓失败: %w", err)
+		return nil, fmt.Errorf("平多仓失败: %w", classifyBinanceError(err))
 	}
 
 	log.Printf("✓ 平多仓成功: %s 数量: %s", symbol, quantityStr)
@@ -727,24 +728,32 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 		}
 	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	result["realized_pnl"] = realizedPnL // ✅ 添加realized_pnl字段
-
-	// ✅ 记录平仓时间和盈亏，启动动态冷却期
-	t.recordCloseTime(symbol, realizedPnL)
-
+	// 🆕 扣除手续费和资金费，realized_pnl 返回净盈亏
+	result := &OrderResult{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        string(order.Status),
+		Side:          string(order.Side),
+		Type:          string(order.Type),
+	}
+	t.settleCloseFees(result, "long", realizedPnL, fullClose) // ✅ RealizedPnL 为净盈亏（已扣除手续费、计入资金费）
 	return result, nil
 }
 
 // CloseShort 平空仓
-func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (*OrderResult, error) {
+	return t.CloseShortWithClientID(symbol, quantity, "")
+}
+
+// CloseShortWithClientID 平空仓（携带客户端订单ID，用于幂等防重复执行，为空时由交易所生成）
+func (t *FuturesTrader) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (*OrderResult, error) {
 	// ✅ 修复: 平仓前获取持仓信息以计算realized_pnl
 	var entryPrice float64
 	var positionAmt float64
 
+	fullClose := quantity == 0
+
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -752,13 +761,10 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 			return nil, err
 		}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "short" {
-				quantity = -pos["positionAmt"].(float64) // 空仓数量是负的，取绝对值
-				positionAmt = quantity
-				entryPrice = pos["entryPrice"].(float64)
-				break
-			}
+		if pos := FindPosition(positions, symbol, "short"); pos != nil {
+			quantity = pos.Quantity
+			positionAmt = quantity
+			entryPrice = pos.EntryPrice
 		}
 
 		if quantity == 0 {
@@ -768,12 +774,9 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 		// 如果指定了数量，也需要获取入场价
 		positions, err := t.GetPositions()
 		if err == nil {
-			for _, pos := range positions {
-				if pos["symbol"] == symbol && pos["side"] == "short" {
-					entryPrice = pos["entryPrice"].(float64)
-					positionAmt = quantity
-					break
-				}
+			if pos := FindPosition(positions, symbol, "short"); pos != nil {
+				entryPrice = pos.EntryPrice
+				positionAmt = quantity
 			}
 		}
 	}
@@ -784,17 +787,20 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 		return nil, err
 	}
 
-	// 创建市价买入订单（平空）
-	order, err := t.client.NewCreateOrderService().
+	// 创建市价买入订单（平空，只减仓）
+	orderService := t.reduceOnly(t.client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(futures.SideTypeBuy).
-		PositionSide(futures.PositionSideTypeShort).
+		PositionSide(t.positionSideFor("short")).
 		Type(futures.OrderTypeMarket).
-		Quantity(quantityStr).
-		Do(context.Background())
+		Quantity(quantityStr))
+	if clientOrderID != "" {
+		orderService = orderService.NewClientOrderID(clientOrderID)
+	}
+	order, err := orderService.Do(context.Background())
 
 	if err != nil {
-		return nil, fmt.Errorf("平空仓失败: %w", err)
+		return nil, fmt.Errorf("平空仓失败: %w", classifyBinanceError(err))
 	}
 
 	log.Printf("✓ 平空仓成功: %s 数量: %s", symbol, quantityStr)
@@ -825,26 +831,31 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 		}
 	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	result["realized_pnl"] = realizedPnL // ✅ 添加realized_pnl字段
-
-	// ✅ 记录平仓时间和盈亏，启动动态冷却期
-	t.recordCloseTime(symbol, realizedPnL)
-
+	// 🆕 扣除手续费和资金费，realized_pnl 返回净盈亏
+	result := &OrderResult{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        string(order.Status),
+		Side:          string(order.Side),
+		Type:          string(order.Type),
+	}
+	t.settleCloseFees(result, "short", realizedPnL, fullClose) // ✅ RealizedPnL 为净盈亏（已扣除手续费、计入资金费）
 	return result, nil
 }
 
-// CancelAllOrders 取消该币种的所有挂单
+// CancelAllOrders 取消该币种本系统下的挂单（配置 cancel_all_orders 时取消全部挂单）
 func (t *FuturesTrader) CancelAllOrders(symbol string) error {
+	if !t.cancelAllOrders {
+		return t.cancelBotOrders(symbol)
+	}
+
 	err := t.client.NewCancelAllOpenOrdersService().
 		Symbol(symbol).
 		Do(context.Background())
 
 	if err != nil {
-		return fmt.Errorf("取消挂单失败: %w", err)
+		return fmt.Errorf("取消挂单失败: %w", classifyBinanceError(err))
 	}
 
 	log.Printf("  ✓ 已取消 %s 的所有挂单", symbol)
@@ -855,7 +866,7 @@ func (t *FuturesTrader) CancelAllOrders(symbol string) error {
 func (t *FuturesTrader) GetMarketPrice(symbol string) (float64, error) {
 	prices, err := t.client.NewListPricesService().Symbol(symbol).Do(context.Background())
 	if err != nil {
-		return 0, fmt.Errorf("获取价格失败: %w", err)
+		return 0, fmt.Errorf("获取价格失败: %w", classifyBinanceError(err))
 	}
 
 	if len(prices) == 0 {
@@ -880,16 +891,11 @@ func (t *FuturesTrader) CalculatePositionSize(balance, riskPercent, price float6
 
 // SetStopLoss 设置止损单
 func (t *FuturesTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
-	var side futures.SideType
-	var posSide futures.PositionSideType
-
+	side := futures.SideTypeBuy
 	if positionSide == "LONG" {
 		side = futures.SideTypeSell
-		posSide = futures.PositionSideTypeLong
-	} else {
-		side = futures.SideTypeBuy
-		posSide = futures.PositionSideTypeShort
 	}
+	posSide := t.positionSideFor(positionSide)
 
 	// 格式化数量和价格
 	quantityStr, err := t.FormatQuantity(symbol, quantity)
@@ -911,10 +917,11 @@ func (t *FuturesTrader) SetStopLoss(symbol string, positionSide string, quantity
 		Quantity(quantityStr).
 		WorkingType(futures.WorkingTypeContractPrice).
 		ClosePosition(true).
+		NewClientOrderID(botOrderID("sl")).
 		Do(context.Background())
 
 	if err != nil {
-		return fmt.Errorf("设置止损失败: %w", err)
+		return fmt.Errorf("设置止损失败: %w", classifyBinanceError(err))
 	}
 
 	log.Printf("  止损价设置: %s", stopPriceStr)
@@ -923,16 +930,11 @@ func (t *FuturesTrader) SetStopLoss(symbol string, positionSide string, quantity
 
 // SetTakeProfit 设置止盈单
 func (t *FuturesTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
-	var side futures.SideType
-	var posSide futures.PositionSideType
-
+	side := futures.SideTypeBuy
 	if positionSide == "LONG" {
 		side = futures.SideTypeSell
-		posSide = futures.PositionSideTypeLong
-	} else {
-		side = futures.SideTypeBuy
-		posSide = futures.PositionSideTypeShort
 	}
+	posSide := t.positionSideFor(positionSide)
 
 	// 格式化数量和价格
 	quantityStr, err := t.FormatQuantity(symbol, quantity)
@@ -954,35 +956,59 @@ func (t *FuturesTrader) SetTakeProfit(symbol string, positionSide string, quanti
 		Quantity(quantityStr).
 		WorkingType(futures.WorkingTypeContractPrice).
 		ClosePosition(true).
+		NewClientOrderID(botOrderID("tp")).
 		Do(context.Background())
 
 	if err != nil {
-		return fmt.Errorf("设置止盈失败: %w", err)
+		return fmt.Errorf("设置止盈失败: %w", classifyBinanceError(err))
 	}
 
 	log.Printf("  止盈价设置: %s", takeProfitPriceStr)
 	return nil
 }
 
-// GetSymbolPrecision 获取交易对的数量精度
+// SetPartialTakeProfit 🪜 设置只减仓指定数量的止盈单（分批止盈，不使用closePosition）
+func (t *FuturesTrader) SetPartialTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	side := futures.SideTypeBuy
+	if positionSide == "LONG" {
+		side = futures.SideTypeSell
+	}
+
+	quantityStr, err := t.FormatQuantity(symbol, quantity)
+	if err != nil {
+		return err
+	}
+	takeProfitPriceStr, err := t.FormatPrice(symbol, takeProfitPrice)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.reduceOnly(t.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		PositionSide(t.positionSideFor(positionSide)).
+		Type(futures.OrderTypeTakeProfitMarket).
+		StopPrice(takeProfitPriceStr).
+		Quantity(quantityStr).
+		WorkingType(futures.WorkingTypeContractPrice).
+		NewClientOrderID(botOrderID("tpl"))).
+		Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("设置分批止盈失败: %w", classifyBinanceError(err))
+	}
+
+	log.Printf("  分批止盈设置: %s 数量: %s", takeProfitPriceStr, quantityStr)
+	return nil
+}
+
+// GetSymbolPrecision 获取交易对的数量精度（LOT_SIZE，来自精度缓存）
 func (t *FuturesTrader) GetSymbolPrecision(symbol string) (int, error) {
-	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
+	precision, ok, err := t.cachedPrecision(symbol, false)
 	if err != nil {
-		return 0, fmt.Errorf("获取交易规则失败: %w", err)
-	}
-
-	for _, s := range exchangeInfo.Symbols {
-		if s.Symbol == symbol {
-			// 从LOT_SIZE filter获取精度
-			for _, filter := range s.Filters {
-				if filter["filterType"] == "LOT_SIZE" {
-					stepSize := filter["stepSize"].(string)
-					precision := calculatePrecision(stepSize)
-					log.Printf("  %s 数量精度: %d (stepSize: %s)", symbol, precision, stepSize)
-					return precision, nil
-				}
-			}
-		}
+		return 0, err
+	}
+	if ok {
+		return precision, nil
 	}
 
 	log.Printf("  ⚠ %s 未找到精度信息，使用默认精度3", symbol)
@@ -1056,25 +1082,14 @@ func (t *FuturesTrader) FormatPrice(symbol string, price float64) (string, error
 	return fmt.Sprintf(format, price), nil
 }
 
-// GetSymbolPricePrecision 获取交易对的价格精度
+// GetSymbolPricePrecision 获取交易对的价格精度（PRICE_FILTER，来自精度缓存）
 func (t *FuturesTrader) GetSymbolPricePrecision(symbol string) (int, error) {
-	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
+	precision, ok, err := t.cachedPrecision(symbol, true)
 	if err != nil {
-		return 0, fmt.Errorf("获取交易规则失败: %w", err)
-	}
-
-	for _, s := range exchangeInfo.Symbols {
-		if s.Symbol == symbol {
-			// 从PRICE_FILTER filter获取精度
-			for _, filter := range s.Filters {
-				if filter["filterType"] == "PRICE_FILTER" {
-					tickSize := filter["tickSize"].(string)
-					precision := calculatePrecision(tickSize)
-					log.Printf("  %s 价格精度: %d (tickSize: %s)", symbol, precision, tickSize)
-					return precision, nil
-				}
-			}
-		}
+		return 0, err
+	}
+	if ok {
+		return precision, nil
 	}
 
 	log.Printf("  ⚠ %s 未找到价格精度信息，使用默认精度2", symbol)
@@ -1089,19 +1104,18 @@ func (t *FuturesTrader) getCurrentStopLoss(symbol string, side string) (float64,
 		Do(context.Background())
 
 	if err != nil {
-		return 0, fmt.Errorf("获取挂单失败: %w", err)
+		return 0, fmt.Errorf("获取挂单失败: %w", classifyBinanceError(err))
 	}
 
 	// 查找止损单
-	var positionSide futures.PositionSideType
+	positionSide := t.positionSideFor(side)
+	closeSide := futures.SideTypeBuy
 	if side == "long" {
-		positionSide = futures.PositionSideTypeLong
-	} else {
-		positionSide = futures.PositionSideTypeShort
+		closeSide = futures.SideTypeSell
 	}
 
 	for _, order := range orders {
-		if order.Type == futures.OrderTypeStopMarket && order.PositionSide == positionSide {
+		if order.Type == futures.OrderTypeStopMarket && order.PositionSide == positionSide && order.Side == closeSide {
 			stopPrice, err := strconv.ParseFloat(order.StopPrice, 64)
 			if err != nil {
 				continue
@@ -1114,21 +1128,47 @@ func (t *FuturesTrader) getCurrentStopLoss(symbol string, side string) (float64,
 	return 0, fmt.Errorf("未找到止损单")
 }
 
+// cancelStopOrders 只取消该方向的止损单
+func (t *FuturesTrader) cancelStopOrders(symbol string, side string) error {
+	orders, err := t.client.NewListOpenOrdersService().
+		Symbol(symbol).
+		Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("获取挂单失败: %w", classifyBinanceError(err))
+	}
+
+	positionSide := t.positionSideFor(side)
+	closeSide := futures.SideTypeBuy
+	if side == "long" {
+		closeSide = futures.SideTypeSell
+	}
+	for _, order := range orders {
+		if order.Type != futures.OrderTypeStopMarket || order.PositionSide != positionSide || order.Side != closeSide {
+			continue
+		}
+		if !t.cancelAllOrders && !IsBotOrder(order.ClientOrderID) {
+			continue // 🏷️ 用户手动下的止损单不动
+		}
+		if _, err := t.client.NewCancelOrderService().
+			Symbol(symbol).
+			OrderID(order.OrderID).
+			Do(context.Background()); err != nil {
+			return fmt.Errorf("取消止损单失败: %w", classifyBinanceError(err))
+		}
+	}
+	return nil
+}
+
 // updateStopLoss 更新止损价格（先验证参数，再取消旧的，最后设置新的）
 func (t *FuturesTrader) updateStopLoss(symbol string, side string, positionAmt float64, newStopLoss float64) error {
 	// ========================================
 	// 第1步：先准备所有参数（避免取消旧止损后设置新止损失败）
 	// ========================================
-	var orderSide futures.SideType
-	var posSide futures.PositionSideType
-
+	orderSide := futures.SideTypeBuy
 	if side == "long" {
 		orderSide = futures.SideTypeSell
-		posSide = futures.PositionSideTypeLong
-	} else {
-		orderSide = futures.SideTypeBuy
-		posSide = futures.PositionSideTypeShort
 	}
+	posSide := t.positionSideFor(side)
 
 	// 格式化数量（在取消旧止损之前完成，确保参数正确）
 	if positionAmt < 0 {
@@ -1148,11 +1188,9 @@ func (t *FuturesTrader) updateStopLoss(symbol string, side string, positionAmt f
 	}
 
 	// ========================================
-	// 第2步：取消旧止损（参数已验证，安全）
+	// 第2步：取消旧止损（参数已验证，安全；只撤止损单，保留止盈/分批止盈单）
 	// ========================================
-	err = t.client.NewCancelAllOpenOrdersService().
-		Symbol(symbol).
-		Do(context.Background())
+	err = t.cancelStopOrders(symbol, side)
 
 	if err != nil {
 		// 取消失败，保留旧止损
@@ -1171,13 +1209,14 @@ func (t *FuturesTrader) updateStopLoss(symbol string, side string, positionAmt f
 		Quantity(quantityStr).
 		WorkingType(futures.WorkingTypeContractPrice).
 		ClosePosition(true).
+		NewClientOrderID(botOrderID("sl")).
 		Do(context.Background())
 
 	if err != nil {
 		// 🚨 严重错误：旧止损已取消，新止损设置失败！持仓无保护！
 		log.Printf("🚨🚨🚨 严重错误：%s %s 旧止损已取消但新止损设置失败！持仓无保护！错误: %v", symbol, side, err)
 		log.Printf("🚨 请立即手动设置止损！止损价: %s, 数量: %s", stopPriceStr, quantityStr)
-		return fmt.Errorf("🚨 设置新止损失败（旧止损已取消）: %w", err)
+		return fmt.Errorf("🚨 设置新止损失败（旧止损已取消）: %w", classifyBinanceError(err))
 	}
 
 	log.Printf("  ✅ 止损已更新: %s %s | 新止损价: %s", symbol, side, stopPriceStr)
@@ -1201,12 +1240,7 @@ func stringContains(s, substr string) bool {
 // ==================== 限价单功能 ====================
 
 // PlaceLimitOrder 下限价单
-func (t *FuturesTrader) PlaceLimitOrder(symbol string, side OrderSide, price, quantity float64, leverage int) (map[string]interface{}, error) {
-	// ✅ 冷却期检查
-	if err := t.checkCooldown(symbol); err != nil {
-		return nil, err
-	}
-
+func (t *FuturesTrader) PlaceLimitOrder(symbol string, side OrderSide, price, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
 	// 先取消该币种的所有委托单（清理旧限价单）
 	if err := t.CancelAllOrders(symbol); err != nil {
 		log.Printf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
@@ -1217,8 +1251,8 @@ func (t *FuturesTrader) PlaceLimitOrder(symbol string, side OrderSide, price, qu
 		return nil, err
 	}
 
-	// 设置逐仓模式
-	if err := t.SetMarginType(symbol, futures.MarginTypeIsolated); err != nil {
+	// 设置保证金模式（按币种策略，默认逐仓）
+	if err := t.SetMarginType(symbol, t.marginTypeFor(symbol)); err != nil {
 		return nil, err
 	}
 
@@ -1265,7 +1299,7 @@ func (t *FuturesTrader) PlaceLimitOrder(symbol string, side OrderSide, price, qu
 
 		// 🔧 修复：再次格式化可能导致精度丢失，所以直接构造字符串
 		// quantityStr, _ = t.FormatQuantity(symbol, adjustedQty)  // 旧代码
-		quantityStr = fmt.Sprintf(fmt.Sprintf("%%.%df", precision), adjustedQty)  // 直接格式化，避免重复调用
+		quantityStr = fmt.Sprintf(fmt.Sprintf("%%.%df", precision), adjustedQty) // 直接格式化，避免重复调用
 
 		// 验证调整后的结果
 		finalQty, _ := strconv.ParseFloat(quantityStr, 64)
@@ -1286,30 +1320,30 @@ func (t *FuturesTrader) PlaceLimitOrder(symbol string, side OrderSide, price, qu
 	}
 
 	// 确定订单方向
-	var orderSide futures.SideType
-	var positionSide futures.PositionSideType
-
+	orderSide := futures.SideTypeSell
+	positionSide := t.positionSideFor("short")
 	if side == OrderSideBuy {
 		orderSide = futures.SideTypeBuy
-		positionSide = futures.PositionSideTypeLong
-	} else {
-		orderSide = futures.SideTypeSell
-		positionSide = futures.PositionSideTypeShort
+		positionSide = t.positionSideFor("long")
 	}
 
 	// 创建限价单
-	order, err := t.client.NewCreateOrderService().
+	orderService := t.client.NewCreateOrderService().
 		Symbol(symbol).
 		Side(orderSide).
 		PositionSide(positionSide).
 		Type(futures.OrderTypeLimit).
 		TimeInForce(futures.TimeInForceTypeGTC). // GTC: Good Till Cancel
 		Quantity(quantityStr).
-		Price(priceStr).
-		Do(context.Background())
+		Price(priceStr)
+	if clientOrderID == "" {
+		clientOrderID = botOrderID("lmt")
+	}
+	orderService = orderService.NewClientOrderID(clientOrderID) // 🏷️ 携带策略标签
+	order, err := orderService.Do(context.Background())
 
 	if err != nil {
-		return nil, fmt.Errorf("下限价单失败: %w", err)
+		return nil, fmt.Errorf("下限价单失败: %w", classifyBinanceError(err))
 	}
 
 	log.Printf("✅ 限价单已提交: %s %s @ %s (数量: %s, 订单ID: %d)",
@@ -1318,14 +1352,16 @@ func (t *FuturesTrader) PlaceLimitOrder(symbol string, side OrderSide, price, qu
 	// 清空缓存
 	t.invalidateCache()
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	result["price"] = price
-	result["quantity"] = formattedQty
-
-	return result, nil
+	return &OrderResult{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        string(order.Status),
+		Side:          string(order.Side),
+		Type:          string(order.Type),
+		Price:         price,
+		OrigQty:       formattedQty,
+	}, nil
 }
 
 // CancelLimitOrder 取消限价单
@@ -1336,7 +1372,7 @@ func (t *FuturesTrader) CancelLimitOrder(symbol string, orderID int64) error {
 		Do(context.Background())
 
 	if err != nil {
-		return fmt.Errorf("取消订单失败: %w", err)
+		return fmt.Errorf("取消订单失败: %w", classifyBinanceError(err))
 	}
 
 	log.Printf("🗑️  已取消限价单: %s (订单ID: %d)", symbol, orderID)
@@ -1345,56 +1381,84 @@ func (t *FuturesTrader) CancelLimitOrder(symbol string, orderID int64) error {
 }
 
 // GetOrderStatus 查询订单状态
-func (t *FuturesTrader) GetOrderStatus(symbol string, orderID int64) (map[string]interface{}, error) {
+func (t *FuturesTrader) GetOrderStatus(symbol string, orderID int64) (*OrderResult, error) {
 	order, err := t.client.NewGetOrderService().
 		Symbol(symbol).
 		OrderID(orderID).
 		Do(context.Background())
 
 	if err != nil {
-		return nil, fmt.Errorf("查询订单失败: %w", err)
+		return nil, fmt.Errorf("查询订单失败: %w", classifyBinanceError(err))
 	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = string(order.Status) // 🔧 修复：转换枚举为字符串
-	result["side"] = string(order.Side)     // 🔧 修复：转换枚举为字符串
-	result["type"] = string(order.Type)     // 🔧 修复：转换枚举为字符串
-	result["price"], _ = strconv.ParseFloat(order.Price, 64)
-	result["origQty"], _ = strconv.ParseFloat(order.OrigQuantity, 64)
-	result["executedQty"], _ = strconv.ParseFloat(order.ExecutedQuantity, 64)
-	result["avgPrice"], _ = strconv.ParseFloat(order.AvgPrice, 64)
-	result["updateTime"] = order.UpdateTime
+	result := &OrderResult{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        string(order.Status),
+		Side:          string(order.Side),
+		Type:          string(order.Type),
+		UpdateTime:    order.UpdateTime,
+	}
+	result.Price, _ = strconv.ParseFloat(order.Price, 64)
+	result.OrigQty, _ = strconv.ParseFloat(order.OrigQuantity, 64)
+	result.ExecutedQty, _ = strconv.ParseFloat(order.ExecutedQuantity, 64)
+	result.AvgPrice, _ = strconv.ParseFloat(order.AvgPrice, 64)
 
 	return result, nil
 }
 
 // GetOpenOrders 获取指定币种的所有挂单（用于检查止损止盈是否存在）
-func (t *FuturesTrader) GetOpenOrders(symbol string) ([]map[string]interface{}, error) {
+func (t *FuturesTrader) GetOpenOrders(symbol string) ([]OrderResult, error) {
 	orders, err := t.client.NewListOpenOrdersService().
 		Symbol(symbol).
 		Do(context.Background())
 
 	if err != nil {
-		return nil, fmt.Errorf("查询挂单失败: %w", err)
+		return nil, fmt.Errorf("查询挂单失败: %w", classifyBinanceError(err))
 	}
 
-	results := make([]map[string]interface{}, 0, len(orders))
+	results := make([]OrderResult, 0, len(orders))
 	for _, order := range orders {
-		result := make(map[string]interface{})
-		result["orderId"] = order.OrderID
-		result["symbol"] = order.Symbol
-		result["status"] = string(order.Status)
-		result["side"] = string(order.Side)
-		result["type"] = string(order.Type)
-		result["price"], _ = strconv.ParseFloat(order.Price, 64)
-		result["origQty"], _ = strconv.ParseFloat(order.OrigQuantity, 64)
-		result["executedQty"], _ = strconv.ParseFloat(order.ExecutedQuantity, 64)
-		result["stopPrice"], _ = strconv.ParseFloat(order.StopPrice, 64)
-		result["updateTime"] = order.UpdateTime
+		result := OrderResult{
+			OrderID:       order.OrderID,
+			ClientOrderID: order.ClientOrderID,
+			Symbol:        order.Symbol,
+			Status:        string(order.Status),
+			Side:          string(order.Side),
+			Type:          string(order.Type),
+			UpdateTime:    order.UpdateTime,
+		}
+		result.Price, _ = strconv.ParseFloat(order.Price, 64)
+		result.OrigQty, _ = strconv.ParseFloat(order.OrigQuantity, 64)
+		result.ExecutedQty, _ = strconv.ParseFloat(order.ExecutedQuantity, 64)
+		result.StopPrice, _ = strconv.ParseFloat(order.StopPrice, 64)
 		results = append(results, result)
 	}
 
 	return results, nil
 }
+
+// addMarginAssets 记录单资产模式下各保证金资产的余额（USDT始终记录，其他资产有余额时记录并计入总额）
+func addMarginAssets(result *Balance, assets []*futures.AccountAsset) {
+	result.MarginAssets = make(map[string]AssetBalance)
+	for _, asset := range assets {
+		if !market.IsQuoteAsset(asset.Asset) {
+			continue
+		}
+		var b AssetBalance
+		b.WalletBalance, _ = strconv.ParseFloat(asset.WalletBalance, 64)
+		b.AvailableBalance, _ = strconv.ParseFloat(asset.AvailableBalance, 64)
+		b.UnrealizedProfit, _ = strconv.ParseFloat(asset.UnrealizedProfit, 64)
+		if asset.Asset != "USDT" {
+			if b.WalletBalance == 0 && b.UnrealizedProfit == 0 {
+				continue
+			}
+			result.TotalWalletBalance += b.WalletBalance
+			result.AvailableBalance += b.AvailableBalance
+			result.TotalUnrealizedProfit += b.UnrealizedProfit
+			log.Printf("✓ %s保证金: 余额=%.2f, 可用=%.2f, 未实现盈亏=%.2f", asset.Asset, b.WalletBalance, b.AvailableBalance, b.UnrealizedProfit)
+		}
+		result.MarginAssets[asset.Asset] = b
+	}
+}
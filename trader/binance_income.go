@@ -0,0 +1,79 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"nofx/market"
+	"strconv"
+	"time"
+)
+
+// IncomeRecord 交易所收益流水（/fapi/v1/income）
+type IncomeRecord struct {
+	Symbol     string    `json:"symbol"`
+	IncomeType string    `json:"income_type"` // REALIZED_PNL / FUNDING_FEE / COMMISSION
+	Income     float64   `json:"income"`      // 正数=收入，负数=支出
+	Time       time.Time `json:"time"`
+}
+
+// IncomeHistoryProvider 支持查询收益流水的交易器（用于盈亏对账）
+type IncomeHistoryProvider interface {
+	GetIncomeHistory(start, end time.Time) ([]IncomeRecord, error)
+}
+
+// reconcileIncomeTypes 对账关注的收益类型
+var reconcileIncomeTypes = map[string]bool{
+	"REALIZED_PNL": true,
+	"FUNDING_FEE":  true,
+	"COMMISSION":   true,
+}
+
+// GetIncomeHistory 拉取时间窗口内的收益流水（只保留 REALIZED_PNL / FUNDING_FEE / COMMISSION）
+// 币安单次最多返回1000条，按时间推进分页拉取
+func (t *FuturesTrader) GetIncomeHistory(start, end time.Time) ([]IncomeRecord, error) {
+	const pageLimit = 1000
+	var records []IncomeRecord
+
+	cursor := start.UnixMilli()
+	endMs := end.UnixMilli()
+	for page := 0; page < 20 && cursor <= endMs; page++ {
+		incomes, err := t.client.NewGetIncomeHistoryService().
+			StartTime(cursor).
+			EndTime(endMs).
+			Limit(pageLimit).
+			Do(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("查询收益流水失败: %w", err)
+		}
+
+		lastTime := cursor
+		for _, income := range incomes {
+			if income.Time > lastTime {
+				lastTime = income.Time
+			}
+			if !reconcileIncomeTypes[income.IncomeType] {
+				continue
+			}
+			if income.Asset != "" && !market.IsQuoteAsset(income.Asset) {
+				continue
+			}
+			amount, err := strconv.ParseFloat(income.Income, 64)
+			if err != nil {
+				continue
+			}
+			records = append(records, IncomeRecord{
+				Symbol:     income.Symbol,
+				IncomeType: income.IncomeType,
+				Income:     amount,
+				Time:       time.UnixMilli(income.Time),
+			})
+		}
+
+		if len(incomes) < pageLimit {
+			break
+		}
+		cursor = lastTime + 1
+	}
+
+	return records, nil
+}
@@ -0,0 +1,82 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+const leverageBracketTTL = time.Hour // 杠杆档位很少变化
+
+// binanceMarginState 币安保证金模式策略与杠杆档位缓存
+type binanceMarginState struct {
+	mu         sync.RWMutex
+	marginMode func(symbol string) string
+	brackets   map[string][]LeverageBracket
+	fetchedAt  map[string]time.Time
+}
+
+// SetMarginModePolicy 设置按币种的保证金模式（nil=全部逐仓）
+func (t *FuturesTrader) SetMarginModePolicy(marginMode func(symbol string) string) {
+	t.margin.mu.Lock()
+	defer t.margin.mu.Unlock()
+	t.margin.marginMode = marginMode
+}
+
+// marginTypeFor 币种下单时使用的保证金模式
+func (t *FuturesTrader) marginTypeFor(symbol string) futures.MarginType {
+	t.margin.mu.RLock()
+	marginMode := t.margin.marginMode
+	t.margin.mu.RUnlock()
+
+	if marginMode != nil && marginMode(symbol) == MarginCross {
+		return futures.MarginTypeCrossed
+	}
+	return futures.MarginTypeIsolated
+}
+
+// GetLeverageBrackets 获取币种杠杆档位（/fapi/v1/leverageBracket，缓存1小时）
+func (t *FuturesTrader) GetLeverageBrackets(symbol string) ([]LeverageBracket, error) {
+	t.margin.mu.RLock()
+	cached, ok := t.margin.brackets[symbol]
+	fetchedAt := t.margin.fetchedAt[symbol]
+	t.margin.mu.RUnlock()
+	if ok && time.Since(fetchedAt) < leverageBracketTTL {
+		return cached, nil
+	}
+
+	res, err := t.client.NewGetLeverageBracketService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		if ok {
+			return cached, nil // 获取失败时继续使用旧档位
+		}
+		return nil, fmt.Errorf("获取杠杆档位失败: %w", err)
+	}
+
+	var brackets []LeverageBracket
+	for _, lb := range res {
+		if lb.Symbol != symbol {
+			continue
+		}
+		for _, b := range lb.Brackets {
+			brackets = append(brackets, LeverageBracket{
+				InitialLeverage: b.InitialLeverage,
+				NotionalFloor:   b.NotionalFloor,
+				NotionalCap:     b.NotionalCap,
+			})
+		}
+	}
+
+	t.margin.mu.Lock()
+	if t.margin.brackets == nil {
+		t.margin.brackets = make(map[string][]LeverageBracket)
+		t.margin.fetchedAt = make(map[string]time.Time)
+	}
+	t.margin.brackets[symbol] = brackets
+	t.margin.fetchedAt[symbol] = time.Now()
+	t.margin.mu.Unlock()
+	return brackets, nil
+}
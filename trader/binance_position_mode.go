@@ -0,0 +1,53 @@
+package trader
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// binancePositionMode 账户持仓模式缓存（双向持仓/单向持仓，首次下单时查询）
+type binancePositionMode struct {
+	once  sync.Once
+	hedge bool
+}
+
+// isHedgeMode 账户是否为双向持仓模式（查询失败时按双向处理，与原有下单逻辑一致）
+func (t *FuturesTrader) isHedgeMode() bool {
+	t.positionMode.once.Do(func() {
+		res, err := t.client.NewGetPositionModeService().Do(context.Background())
+		if err != nil {
+			log.Printf("  ⚠ 查询持仓模式失败，按双向持仓处理: %v", err)
+			t.positionMode.hedge = true
+			return
+		}
+		t.positionMode.hedge = res.DualSidePosition
+		if !res.DualSidePosition {
+			log.Printf("  ℹ️ 账户为单向持仓模式，平仓和保护单将使用 reduceOnly")
+		}
+	})
+	return t.positionMode.hedge
+}
+
+// positionSideFor 下单使用的 positionSide（双向持仓=LONG/SHORT，单向持仓=BOTH）
+func (t *FuturesTrader) positionSideFor(side string) futures.PositionSideType {
+	if !t.isHedgeMode() {
+		return futures.PositionSideTypeBoth
+	}
+	if side == "long" || side == "LONG" {
+		return futures.PositionSideTypeLong
+	}
+	return futures.PositionSideTypeShort
+}
+
+// reduceOnly 标记平仓/保护单只减仓，防止数量过期或竞态导致反向开仓
+// 双向持仓下 positionSide 已限定只能减少对应方向的仓位，币安不接受 reduceOnly 参数；
+// closePosition=true 的条件单本身即只减仓，同样不能再带 reduceOnly
+func (t *FuturesTrader) reduceOnly(svc *futures.CreateOrderService) *futures.CreateOrderService {
+	if t.isHedgeMode() {
+		return svc
+	}
+	return svc.ReduceOnly(true)
+}
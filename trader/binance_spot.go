@@ -0,0 +1,108 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// SpotTrader 现货交易能力（基差策略的现货腿，目前仅币安支持）
+type SpotTrader interface {
+	// GetSpotPrice 获取现货最新价格
+	GetSpotPrice(symbol string) (float64, error)
+	// SpotMarketBuy 按USDT金额市价买入现货，返回扣除手续费后的实际到账数量（已按现货步长取整）
+	SpotMarketBuy(symbol string, quoteUSDT float64) (float64, error)
+	// SpotMarketSell 市价卖出现货
+	SpotMarketSell(symbol string, quantity float64) error
+}
+
+// GetSpotPrice 获取现货最新价格
+func (t *FuturesTrader) GetSpotPrice(symbol string) (float64, error) {
+	prices, err := t.spot.NewListPricesService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("获取现货价格失败: %w", err)
+	}
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("%s没有现货交易对", symbol)
+	}
+	return strconv.ParseFloat(prices[0].Price, 64)
+}
+
+// SpotMarketBuy 按USDT金额市价买入现货
+func (t *FuturesTrader) SpotMarketBuy(symbol string, quoteUSDT float64) (float64, error) {
+	res, err := t.spot.NewCreateOrderService().
+		Symbol(symbol).
+		Side(binance.SideTypeBuy).
+		Type(binance.OrderTypeMarket).
+		QuoteOrderQty(strconv.FormatFloat(quoteUSDT, 'f', 2, 64)).
+		Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("现货买入失败: %w", err)
+	}
+
+	executed, err := strconv.ParseFloat(res.ExecutedQuantity, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析成交数量失败: %w", err)
+	}
+
+	// 手续费以基础币扣除时，实际到账数量要减去手续费
+	base := strings.TrimSuffix(symbol, "USDT")
+	for _, fill := range res.Fills {
+		if fill.CommissionAsset == base {
+			if fee, err := strconv.ParseFloat(fill.Commission, 64); err == nil {
+				executed -= fee
+			}
+		}
+	}
+
+	step, precision, err := t.spotStepSize(symbol)
+	if err != nil {
+		log.Printf("⚠️  获取%s现货步长失败: %v（使用原始数量）", symbol, err)
+		return executed, nil
+	}
+	// 按精度格式化，避免浮点误差导致数量不符合步长
+	return strconv.ParseFloat(strconv.FormatFloat(math.Floor(executed/step)*step, 'f', precision, 64), 64)
+}
+
+// SpotMarketSell 市价卖出现货
+func (t *FuturesTrader) SpotMarketSell(symbol string, quantity float64) error {
+	_, err := t.spot.NewCreateOrderService().
+		Symbol(symbol).
+		Side(binance.SideTypeSell).
+		Type(binance.OrderTypeMarket).
+		Quantity(strconv.FormatFloat(quantity, 'f', -1, 64)).
+		Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("现货卖出失败: %w", err)
+	}
+	return nil
+}
+
+// spotStepSize 现货数量步长（LOT_SIZE）及对应的小数位数
+func (t *FuturesTrader) spotStepSize(symbol string) (float64, int, error) {
+	info, err := t.spot.NewExchangeInfoService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, s := range info.Symbols {
+		if s.Symbol != symbol {
+			continue
+		}
+		if f := s.LotSizeFilter(); f != nil {
+			step, err := strconv.ParseFloat(f.StepSize, 64)
+			if err == nil && step > 0 {
+				precision := 0
+				if i := strings.Index(f.StepSize, "."); i >= 0 {
+					precision = len(strings.TrimRight(f.StepSize[i+1:], "0"))
+				}
+				return step, precision, nil
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("%s没有LOT_SIZE过滤器", symbol)
+}
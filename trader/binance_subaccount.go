@@ -0,0 +1,74 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"nofx/ratelimit"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// binanceUSDTFutures 万向划转中的U本位合约钱包类型
+const binanceUSDTFutures = "USDT_FUTURE"
+
+// BinanceMasterAccount 🏦 币安主账户（仅用于主账户与子账户U本位合约钱包之间的资金划转）
+type BinanceMasterAccount struct {
+	client *binance.Client
+}
+
+// NewBinanceMasterAccount 创建主账户划转客户端（API密钥需开启万向划转权限）
+func NewBinanceMasterAccount(apiKey, secretKey string) *BinanceMasterAccount {
+	client := binance.NewClient(apiKey, secretKey)
+	client.HTTPClient = &http.Client{Transport: ratelimit.NewTransport(nil)}
+	return &BinanceMasterAccount{client: client}
+}
+
+// TransferToSubAccount 主账户合约钱包 → 子账户合约钱包
+func (m *BinanceMasterAccount) TransferToSubAccount(email string, amount float64) error {
+	return m.transfer("", email, amount)
+}
+
+// TransferFromSubAccount 子账户合约钱包 → 主账户合约钱包
+func (m *BinanceMasterAccount) TransferFromSubAccount(email string, amount float64) error {
+	return m.transfer(email, "", amount)
+}
+
+// transfer 万向划转USDT（邮箱为空表示主账户）
+func (m *BinanceMasterAccount) transfer(fromEmail, toEmail string, amount float64) error {
+	if amount <= 0 {
+		return fmt.Errorf("划转金额必须大于0")
+	}
+	if fromEmail == "" && toEmail == "" {
+		return fmt.Errorf("划转双方不能都是主账户")
+	}
+
+	svc := m.client.NewSubAccountUniversalTransferService().
+		FromAccountType(binanceUSDTFutures).
+		ToAccountType(binanceUSDTFutures).
+		Asset("USDT").
+		Amount(strconv.FormatFloat(amount, 'f', 2, 64))
+	if fromEmail != "" {
+		svc = svc.FromEmail(fromEmail)
+	}
+	if toEmail != "" {
+		svc = svc.ToEmail(toEmail)
+	}
+
+	res, err := svc.Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("子账户划转失败: %w", err)
+	}
+	log.Printf("🏦 子账户划转成功: %s → %s %.2f USDT (tranId=%d)",
+		accountLabel(fromEmail), accountLabel(toEmail), amount, res.TranId)
+	return nil
+}
+
+func accountLabel(email string) string {
+	if email == "" {
+		return "主账户"
+	}
+	return email
+}
@@ -0,0 +1,808 @@
+package trader
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"nofx/errs"
+	"nofx/ratelimit"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🟡 Bybit v5 统一账户（USDT永续，category=linear）
+// 要求单向持仓模式（positionIdx=0）；止损止盈为本系统下的条件单（带 nofx_ 前缀），撤单范围与币安一致
+
+const (
+	bybitMainnetURL    = "https://api.bybit.com"
+	bybitTestnetURL    = "https://api-testnet.bybit.com"
+	bybitRecvWindow    = "5000"
+	bybitCategory      = "linear"
+	bybitInstrumentTTL = 24 * time.Hour
+)
+
+// bybitInstrument 合约交易规则
+type bybitInstrument struct {
+	TickSize    float64
+	QtyStep     float64
+	MinQty      float64
+	MinNotional float64
+	fetchedAt   time.Time
+}
+
+// bybitResponse v5 通用响应
+type bybitResponse struct {
+	RetCode int64           `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+	Time    int64           `json:"time"`
+}
+
+// bybitOrder 订单（实时/历史查询）
+type bybitOrder struct {
+	OrderID      string `json:"orderId"`
+	OrderLinkID  string `json:"orderLinkId"`
+	Symbol       string `json:"symbol"`
+	Side         string `json:"side"`
+	OrderType    string `json:"orderType"`
+	OrderStatus  string `json:"orderStatus"`
+	Price        string `json:"price"`
+	Qty          string `json:"qty"`
+	CumExecQty   string `json:"cumExecQty"`
+	AvgPrice     string `json:"avgPrice"`
+	TriggerPrice string `json:"triggerPrice"`
+	UpdatedTime  string `json:"updatedTime"`
+}
+
+// BybitTrader Bybit交易器
+type BybitTrader struct {
+	apiKey    string
+	secretKey string
+	baseURL   string
+	client    *http.Client
+	clock     *clockSync
+
+	cancelAllOrders bool
+	marginMode      func(symbol string) string
+
+	mu          sync.RWMutex
+	instruments map[string]bybitInstrument
+}
+
+// NewBybitTrader 创建Bybit交易器
+func NewBybitTrader(apiKey, secretKey string, useTestnet bool) (*BybitTrader, error) {
+	if apiKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("bybit_api_key和bybit_secret_key不能为空")
+	}
+
+	t := &BybitTrader{
+		apiKey:      apiKey,
+		secretKey:   secretKey,
+		baseURL:     bybitMainnetURL,
+		client:      ratelimit.NewHTTPClient(30 * time.Second), // 🆕 限流预算：600次/5秒，403时冷却
+		instruments: make(map[string]bybitInstrument),
+	}
+	if useTestnet {
+		t.baseURL = bybitTestnetURL
+		log.Printf("🧪 使用Bybit Testnet: %s", t.baseURL)
+	} else {
+		log.Printf("💰 使用Bybit主网")
+	}
+
+	t.clock = newClockSync("bybit", t.serverTime, nil)
+	t.clock.Start()
+	return t, nil
+}
+
+// serverTime 获取Bybit服务器时间（毫秒）
+func (t *BybitTrader) serverTime(ctx context.Context) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", t.baseURL+"/v5/market/time", nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var result bybitResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("解析服务器时间失败: %w", err)
+	}
+	return result.Time, nil
+}
+
+// ClockOffset 本地时钟相对交易所服务器的偏差
+func (t *BybitTrader) ClockOffset() time.Duration {
+	return t.clock.Offset()
+}
+
+// SetCancelAllOrders all=true时CancelAllOrders撤掉该币种的全部挂单（包括手动下的单）
+func (t *BybitTrader) SetCancelAllOrders(all bool) { t.cancelAllOrders = all }
+
+// SetMarginModePolicy 设置按币种的保证金模式（nil=全部逐仓）
+func (t *BybitTrader) SetMarginModePolicy(marginMode func(symbol string) string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.marginMode = marginMode
+}
+
+// sign v5签名：HMAC_SHA256(timestamp + apiKey + recvWindow + payload)
+func (t *BybitTrader) sign(timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(t.secretKey))
+	mac.Write([]byte(timestamp + t.apiKey + bybitRecvWindow + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// request 发送请求（时间戳错误和瞬时错误重试，每次重试重新签名）
+func (t *BybitTrader) request(method, endpoint string, params map[string]interface{}) (json.RawMessage, error) {
+	const maxRetries = 3
+	var lastErr error
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		result, err := t.doRequest(method, endpoint, params)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		if errs.Is(err, errs.ClockSkew) {
+			t.clock.ObserveError(err.Error())
+			if attempt < maxRetries {
+				time.Sleep(time.Second)
+				continue
+			}
+		}
+		if errs.Is(err, errs.Transient) && attempt < maxRetries {
+			time.Sleep(time.Duration(attempt) * time.Second)
+			continue
+		}
+		return nil, err
+	}
+	return nil, fmt.Errorf("请求失败（已重试%d次）: %w", maxRetries, lastErr)
+}
+
+// doRequest 发送一次签名请求（GET参数在querystring，POST参数为JSON body）
+func (t *BybitTrader) doRequest(method, endpoint string, params map[string]interface{}) (json.RawMessage, error) {
+	var payload string
+	var body io.Reader
+	fullURL := t.baseURL + endpoint
+
+	if method == "GET" {
+		keys := make([]string, 0, len(params))
+		for k := range params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		q := url.Values{}
+		for _, k := range keys {
+			q.Set(k, fmt.Sprintf("%v", params[k]))
+		}
+		payload = q.Encode()
+		if payload != "" {
+			fullURL += "?" + payload
+		}
+	} else {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		payload = string(data)
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, fullURL, body)
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(t.clock.NowMillis(), 10)
+	req.Header.Set("X-BAPI-API-KEY", t.apiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", bybitRecvWindow)
+	req.Header.Set("X-BAPI-SIGN", t.sign(timestamp, payload))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, errs.Wrap(errs.Network(err), 0, err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyBybitResponse(resp.StatusCode, 0, "", data)
+	}
+
+	var result bybitResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, errs.Wrap(errs.Transient, 0, fmt.Errorf("解析Bybit响应失败: %w", err))
+	}
+	if result.RetCode != 0 {
+		err := classifyBybitResponse(resp.StatusCode, result.RetCode, result.RetMsg, data)
+		ratelimit.For(ratelimit.Bybit).ObserveError(err)
+		return nil, err
+	}
+	return result.Result, nil
+}
+
+// bybitList 解析 result.list
+func bybitList(raw json.RawMessage, out interface{}) error {
+	var wrapper struct {
+		List json.RawMessage `json:"list"`
+	}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return err
+	}
+	return json.Unmarshal(wrapper.List, out)
+}
+
+// instrument 获取合约交易规则（缓存一天）
+func (t *BybitTrader) instrument(symbol string) (bybitInstrument, error) {
+	t.mu.RLock()
+	inst, ok := t.instruments[symbol]
+	t.mu.RUnlock()
+	if ok && time.Since(inst.fetchedAt) < bybitInstrumentTTL {
+		return inst, nil
+	}
+
+	raw, err := t.request("GET", "/v5/market/instruments-info", map[string]interface{}{
+		"category": bybitCategory,
+		"symbol":   symbol,
+	})
+	if err != nil {
+		if ok {
+			return inst, nil // 刷新失败时继续使用旧规则
+		}
+		return bybitInstrument{}, fmt.Errorf("获取%s交易规则失败: %w", symbol, err)
+	}
+
+	var list []struct {
+		Status        string `json:"status"`
+		LotSizeFilter struct {
+			QtyStep          string `json:"qtyStep"`
+			MinOrderQty      string `json:"minOrderQty"`
+			MinNotionalValue string `json:"minNotionalValue"`
+		} `json:"lotSizeFilter"`
+		PriceFilter struct {
+			TickSize string `json:"tickSize"`
+		} `json:"priceFilter"`
+	}
+	if err := bybitList(raw, &list); err != nil {
+		return bybitInstrument{}, fmt.Errorf("解析%s交易规则失败: %w", symbol, err)
+	}
+	if len(list) == 0 {
+		return bybitInstrument{}, errs.New(errs.InvalidSymbol, "Bybit合约 %s 不存在", symbol)
+	}
+	if list[0].Status != "Trading" {
+		return bybitInstrument{}, errs.New(errs.InvalidSymbol, "Bybit合约 %s 状态为 %s", symbol, list[0].Status)
+	}
+
+	inst = bybitInstrument{fetchedAt: time.Now()}
+	inst.TickSize, _ = strconv.ParseFloat(list[0].PriceFilter.TickSize, 64)
+	inst.QtyStep, _ = strconv.ParseFloat(list[0].LotSizeFilter.QtyStep, 64)
+	inst.MinQty, _ = strconv.ParseFloat(list[0].LotSizeFilter.MinOrderQty, 64)
+	inst.MinNotional, _ = strconv.ParseFloat(list[0].LotSizeFilter.MinNotionalValue, 64)
+
+	t.mu.Lock()
+	t.instruments[symbol] = inst
+	t.mu.Unlock()
+	return inst, nil
+}
+
+// formatStep 按步进向下取整并格式化（小数位数由步进决定）
+func formatStep(value, step float64) string {
+	if step <= 0 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	rounded := math.Floor(value/step+1e-9) * step
+	return strconv.FormatFloat(rounded, 'f', calculatePrecision(strconv.FormatFloat(step, 'f', -1, 64)), 64)
+}
+
+// GetBalance 获取统一账户余额（USD计价）
+func (t *BybitTrader) GetBalance() (*Balance, error) {
+	raw, err := t.request("GET", "/v5/account/wallet-balance", map[string]interface{}{"accountType": "UNIFIED"})
+	if err != nil {
+		return nil, fmt.Errorf("获取Bybit余额失败: %w", err)
+	}
+
+	var list []struct {
+		TotalWalletBalance    string `json:"totalWalletBalance"`
+		TotalAvailableBalance string `json:"totalAvailableBalance"`
+		TotalPerpUPL          string `json:"totalPerpUPL"`
+	}
+	if err := bybitList(raw, &list); err != nil || len(list) == 0 {
+		return nil, fmt.Errorf("解析Bybit余额失败: %v", err)
+	}
+
+	result := &Balance{}
+	result.TotalWalletBalance, _ = strconv.ParseFloat(list[0].TotalWalletBalance, 64)
+	result.AvailableBalance, _ = strconv.ParseFloat(list[0].TotalAvailableBalance, 64)
+	result.TotalUnrealizedProfit, _ = strconv.ParseFloat(list[0].TotalPerpUPL, 64)
+	return result, nil
+}
+
+// GetPositions 获取USDT永续持仓
+func (t *BybitTrader) GetPositions() ([]Position, error) {
+	raw, err := t.request("GET", "/v5/position/list", map[string]interface{}{
+		"category":   bybitCategory,
+		"settleCoin": "USDT",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取Bybit持仓失败: %w", err)
+	}
+
+	var list []struct {
+		Symbol        string `json:"symbol"`
+		Side          string `json:"side"`
+		Size          string `json:"size"`
+		AvgPrice      string `json:"avgPrice"`
+		MarkPrice     string `json:"markPrice"`
+		UnrealisedPnl string `json:"unrealisedPnl"`
+		Leverage      string `json:"leverage"`
+		LiqPrice      string `json:"liqPrice"`
+	}
+	if err := bybitList(raw, &list); err != nil {
+		return nil, fmt.Errorf("解析Bybit持仓失败: %w", err)
+	}
+
+	var result []Position
+	for _, pos := range list {
+		size, _ := strconv.ParseFloat(pos.Size, 64)
+		if size == 0 || pos.Side == "" {
+			continue
+		}
+		p := Position{Symbol: pos.Symbol, Side: "long", Quantity: size}
+		if pos.Side == "Sell" {
+			p.Side = "short"
+		}
+		p.EntryPrice, _ = strconv.ParseFloat(pos.AvgPrice, 64)
+		p.MarkPrice, _ = strconv.ParseFloat(pos.MarkPrice, 64)
+		p.UnrealizedPnL, _ = strconv.ParseFloat(pos.UnrealisedPnl, 64)
+		leverage, _ := strconv.ParseFloat(pos.Leverage, 64) // Bybit杠杆可能带小数（如"12.5"）
+		p.Leverage = int(leverage)
+		p.LiquidationPrice, _ = strconv.ParseFloat(pos.LiqPrice, 64)
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// SetLeverage 设置杠杆（多空相同）
+func (t *BybitTrader) SetLeverage(symbol string, leverage int) error {
+	_, err := t.request("POST", "/v5/position/set-leverage", map[string]interface{}{
+		"category":     bybitCategory,
+		"symbol":       symbol,
+		"buyLeverage":  strconv.Itoa(leverage),
+		"sellLeverage": strconv.Itoa(leverage),
+	})
+	if err != nil {
+		if errs.Is(err, errs.NoChange) {
+			return nil
+		}
+		return fmt.Errorf("设置杠杆失败: %w", err)
+	}
+	log.Printf("  ✓ %s 杠杆已切换为 %dx", symbol, leverage)
+	return nil
+}
+
+// setMarginMode 按币种策略切换逐仓/全仓（统一账户在账户级别设置时跳过）
+func (t *BybitTrader) setMarginMode(symbol string, leverage int) error {
+	t.mu.RLock()
+	marginMode := t.marginMode
+	t.mu.RUnlock()
+
+	tradeMode, label := 1, "逐仓"
+	if marginMode != nil && marginMode(symbol) == MarginCross {
+		tradeMode, label = 0, "全仓"
+	}
+	_, err := t.request("POST", "/v5/position/switch-isolated", map[string]interface{}{
+		"category":     bybitCategory,
+		"symbol":       symbol,
+		"tradeMode":    tradeMode,
+		"buyLeverage":  strconv.Itoa(leverage),
+		"sellLeverage": strconv.Itoa(leverage),
+	})
+	if err != nil {
+		if errs.Is(err, errs.NoChange) {
+			return nil
+		}
+		if errs.CodeOf(err) == bybitCodeUnifiedMargin {
+			log.Printf("  ⚠ %s 统一账户的保证金模式在账户级别设置，跳过按币种切换", symbol)
+			return nil
+		}
+		return fmt.Errorf("设置保证金模式失败: %w", err)
+	}
+	log.Printf("  ✓ %s 保证金模式已切换为%s", symbol, label)
+	return nil
+}
+
+// placeOrder 下市价单/条件单，返回订单ID
+func (t *BybitTrader) placeOrder(params map[string]interface{}) (string, error) {
+	params["category"] = bybitCategory
+	params["positionIdx"] = 0
+	raw, err := t.request("POST", "/v5/order/create", params)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		OrderID string `json:"orderId"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", fmt.Errorf("解析下单结果失败: %w", err)
+	}
+	return result.OrderID, nil
+}
+
+// OpenLong 开多仓
+func (t *BybitTrader) OpenLong(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return t.openPosition(symbol, "long", quantity, leverage)
+}
+
+// OpenShort 开空仓
+func (t *BybitTrader) OpenShort(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return t.openPosition(symbol, "short", quantity, leverage)
+}
+
+func (t *BybitTrader) openPosition(symbol, side string, quantity float64, leverage int) (*OrderResult, error) {
+	if err := t.CancelAllOrders(symbol); err != nil {
+		log.Printf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
+	}
+	if err := t.setMarginMode(symbol, leverage); err != nil {
+		return nil, err
+	}
+	if err := t.SetLeverage(symbol, leverage); err != nil {
+		return nil, err
+	}
+
+	inst, err := t.instrument(symbol)
+	if err != nil {
+		return nil, err
+	}
+	price, err := t.GetMarketPrice(symbol)
+	if err != nil {
+		return nil, err
+	}
+	// 不满足最小下单量/名义价值时向上调整
+	if quantity < inst.MinQty {
+		quantity = inst.MinQty
+	}
+	if inst.MinNotional > 0 && quantity*price < inst.MinNotional {
+		quantity = math.Ceil(inst.MinNotional/price/inst.QtyStep) * inst.QtyStep
+	}
+	qtyStr := formatStep(quantity, inst.QtyStep)
+
+	orderSide, label := "Buy", "多"
+	if side == "short" {
+		orderSide, label = "Sell", "空"
+	}
+	linkID := botOrderID("open")
+	orderID, err := t.placeOrder(map[string]interface{}{
+		"symbol":      symbol,
+		"side":        orderSide,
+		"orderType":   "Market",
+		"qty":         qtyStr,
+		"orderLinkId": linkID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("开%s仓失败: %w", label, err)
+	}
+	log.Printf("✓ 开%s仓成功: %s 数量: %s", label, symbol, qtyStr)
+	log.Printf("  订单ID: %s", orderID)
+
+	result := &OrderResult{
+		ClientOrderID: linkID, // Bybit订单ID为字符串（UUID），OrderID留空
+		Symbol:        symbol,
+		Status:        "NEW",
+		Side:          strings.ToUpper(orderSide),
+		Type:          "MARKET",
+	}
+	result.OrigQty, _ = strconv.ParseFloat(qtyStr, 64)
+	return result, nil
+}
+
+// CloseLong 平多仓（quantity=0表示全部平仓）
+func (t *BybitTrader) CloseLong(symbol string, quantity float64) (*OrderResult, error) {
+	return t.closePosition(symbol, "long", quantity)
+}
+
+// CloseShort 平空仓（quantity=0表示全部平仓）
+func (t *BybitTrader) CloseShort(symbol string, quantity float64) (*OrderResult, error) {
+	return t.closePosition(symbol, "short", quantity)
+}
+
+// closePosition 市价只减仓平仓，按成交均价计算realized_pnl（毛盈亏）并启动动态冷却期
+func (t *BybitTrader) closePosition(symbol, side string, quantity float64) (*OrderResult, error) {
+	label := "多"
+	if side == "short" {
+		label = "空"
+	}
+
+	var entryPrice float64
+	positions, err := t.GetPositions()
+	if err != nil && quantity == 0 {
+		return nil, err
+	}
+	if pos := FindPosition(positions, symbol, side); pos != nil {
+		entryPrice = pos.EntryPrice
+		if quantity == 0 {
+			quantity = pos.Quantity
+		}
+	}
+	if quantity == 0 {
+		return nil, fmt.Errorf("没有找到 %s 的%s仓", symbol, label)
+	}
+
+	inst, err := t.instrument(symbol)
+	if err != nil {
+		return nil, err
+	}
+	qtyStr := formatStep(quantity, inst.QtyStep)
+
+	orderSide := "Sell"
+	if side == "short" {
+		orderSide = "Buy"
+	}
+	linkID := botOrderID("close")
+	orderID, err := t.placeOrder(map[string]interface{}{
+		"symbol":      symbol,
+		"side":        orderSide,
+		"orderType":   "Market",
+		"qty":         qtyStr,
+		"reduceOnly":  true,
+		"orderLinkId": linkID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("平%s仓失败: %w", label, err)
+	}
+	log.Printf("✓ 平%s仓成功: %s 数量: %s", label, symbol, qtyStr)
+
+	if err := t.CancelAllOrders(symbol); err != nil {
+		log.Printf("  ⚠ 取消挂单失败: %v", err)
+	}
+
+	result := &OrderResult{
+		ClientOrderID: linkID,
+		Symbol:        symbol,
+		Status:        "FILLED",
+		Side:          strings.ToUpper(orderSide),
+		Type:          "MARKET",
+	}
+	result.OrigQty, _ = strconv.ParseFloat(qtyStr, 64)
+	if entryPrice > 0 {
+		if order, err := t.getOrder(symbol, orderID); err == nil {
+			result.AvgPrice, _ = strconv.ParseFloat(order.AvgPrice, 64)
+			result.ExecutedQty, _ = strconv.ParseFloat(order.CumExecQty, 64)
+			if result.AvgPrice > 0 {
+				result.RealizedPnL = (result.AvgPrice - entryPrice) * result.ExecutedQty
+				if side == "short" {
+					result.RealizedPnL = -result.RealizedPnL
+				}
+				log.Printf("  💰 平仓盈亏: 入场%.4f → 平仓%.4f | 盈亏%+.2f USDT", entryPrice, result.AvgPrice, result.RealizedPnL)
+			}
+		} else {
+			log.Printf("  ⚠ 查询平仓成交价失败: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+// getOrder 查询订单（市价单成交后在实时订单接口保留一段时间，查不到时查历史）
+func (t *BybitTrader) getOrder(symbol, orderID string) (*bybitOrder, error) {
+	params := map[string]interface{}{
+		"category": bybitCategory,
+		"symbol":   symbol,
+		"orderId":  orderID,
+	}
+	for _, endpoint := range []string{"/v5/order/realtime", "/v5/order/history"} {
+		raw, err := t.request("GET", endpoint, params)
+		if err != nil {
+			return nil, err
+		}
+		var list []bybitOrder
+		if err := bybitList(raw, &list); err != nil {
+			return nil, err
+		}
+		if len(list) > 0 {
+			return &list[0], nil
+		}
+	}
+	return nil, errs.New(errs.OrderNotFound, "订单 %s 不存在", orderID)
+}
+
+// GetMarketPrice 获取最新成交价
+func (t *BybitTrader) GetMarketPrice(symbol string) (float64, error) {
+	raw, err := t.request("GET", "/v5/market/tickers", map[string]interface{}{
+		"category": bybitCategory,
+		"symbol":   symbol,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("获取价格失败: %w", err)
+	}
+	var list []struct {
+		LastPrice string `json:"lastPrice"`
+	}
+	if err := bybitList(raw, &list); err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, errs.New(errs.InvalidSymbol, "未找到 %s 的价格", symbol)
+	}
+	return strconv.ParseFloat(list[0].LastPrice, 64)
+}
+
+// SetStopLoss 设置止损（按最新价触发的只减仓条件市价单）
+func (t *BybitTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	priceStr, err := t.placeConditional(symbol, positionSide, quantity, stopPrice, "sl")
+	if err != nil {
+		return fmt.Errorf("设置止损失败: %w", err)
+	}
+	log.Printf("  止损价设置: %s", priceStr)
+	return nil
+}
+
+// SetTakeProfit 设置止盈（按最新价触发的只减仓条件市价单）
+func (t *BybitTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	priceStr, err := t.placeConditional(symbol, positionSide, quantity, takeProfitPrice, "tp")
+	if err != nil {
+		return fmt.Errorf("设置止盈失败: %w", err)
+	}
+	log.Printf("  止盈价设置: %s", priceStr)
+	return nil
+}
+
+// placeConditional 下止损/止盈条件单：多仓止损在价格下跌时触发，止盈在上涨时触发，空仓相反
+func (t *BybitTrader) placeConditional(symbol, positionSide string, quantity, triggerPrice float64, kind string) (string, error) {
+	inst, err := t.instrument(symbol)
+	if err != nil {
+		return "", err
+	}
+
+	long := positionSide == "LONG"
+	side := "Buy"
+	if long {
+		side = "Sell"
+	}
+	// triggerDirection: 1=价格上涨到触发价, 2=价格下跌到触发价
+	rising := (kind == "tp") == long
+	direction := 2
+	if rising {
+		direction = 1
+	}
+
+	priceStr := formatStep(triggerPrice, inst.TickSize)
+	_, err = t.placeOrder(map[string]interface{}{
+		"symbol":           symbol,
+		"side":             side,
+		"orderType":        "Market",
+		"qty":              formatStep(quantity, inst.QtyStep),
+		"triggerPrice":     priceStr,
+		"triggerDirection": direction,
+		"triggerBy":        "LastPrice",
+		"reduceOnly":       true,
+		"closeOnTrigger":   true,
+		"orderLinkId":      botOrderID(kind), // 🏷️ 撤单时只撤本系统的挂单
+	})
+	if err != nil {
+		return "", err
+	}
+	return priceStr, nil
+}
+
+// CancelAllOrders 取消本系统下的挂单（配置 cancel_all_orders 时取消全部挂单，含条件单）
+func (t *BybitTrader) CancelAllOrders(symbol string) error {
+	if t.cancelAllOrders {
+		_, err := t.request("POST", "/v5/order/cancel-all", map[string]interface{}{
+			"category": bybitCategory,
+			"symbol":   symbol,
+		})
+		if err != nil {
+			return fmt.Errorf("取消挂单失败: %w", err)
+		}
+		log.Printf("  ✓ 已取消 %s 的所有挂单", symbol)
+		return nil
+	}
+
+	orders, err := t.listOpenOrders(symbol)
+	if err != nil {
+		return err
+	}
+	cancelled, kept := 0, 0
+	for _, order := range orders {
+		if !IsBotOrder(order.OrderLinkID) {
+			kept++
+			continue
+		}
+		_, err := t.request("POST", "/v5/order/cancel", map[string]interface{}{
+			"category": bybitCategory,
+			"symbol":   symbol,
+			"orderId":  order.OrderID,
+		})
+		if err != nil && !errs.Is(err, errs.OrderNotFound) {
+			return fmt.Errorf("取消挂单失败: %w", err)
+		}
+		cancelled++
+	}
+	logCancelled(symbol, cancelled, kept)
+	return nil
+}
+
+// listOpenOrders 未成交订单（含未触发的条件单）
+func (t *BybitTrader) listOpenOrders(symbol string) ([]bybitOrder, error) {
+	raw, err := t.request("GET", "/v5/order/realtime", map[string]interface{}{
+		"category": bybitCategory,
+		"symbol":   symbol,
+		"openOnly": 0,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取挂单失败: %w", err)
+	}
+	var orders []bybitOrder
+	if err := bybitList(raw, &orders); err != nil {
+		return nil, fmt.Errorf("解析挂单失败: %w", err)
+	}
+	return orders, nil
+}
+
+// GetOpenOrders 获取指定币种的所有挂单（类型与币安一致，条件单的触发价为StopPrice）
+func (t *BybitTrader) GetOpenOrders(symbol string) ([]OrderResult, error) {
+	orders, err := t.listOpenOrders(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]OrderResult, 0, len(orders))
+	for _, order := range orders {
+		orderType := strings.ToUpper(order.OrderType)
+		if order.TriggerPrice != "" && order.TriggerPrice != "0" {
+			orderType = "STOP_MARKET"
+			if strings.Contains(order.OrderLinkID, "_tp_") {
+				orderType = "TAKE_PROFIT_MARKET"
+			}
+		}
+		result := OrderResult{
+			ClientOrderID: order.OrderLinkID,
+			Symbol:        order.Symbol,
+			Status:        strings.ToUpper(order.OrderStatus),
+			Side:          strings.ToUpper(order.Side),
+			Type:          orderType,
+		}
+		result.Price, _ = strconv.ParseFloat(order.Price, 64)
+		result.OrigQty, _ = strconv.ParseFloat(order.Qty, 64)
+		result.ExecutedQty, _ = strconv.ParseFloat(order.CumExecQty, 64)
+		result.StopPrice, _ = strconv.ParseFloat(order.TriggerPrice, 64)
+		result.UpdateTime, _ = strconv.ParseInt(order.UpdatedTime, 10, 64)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// FormatQuantity 格式化数量到qtyStep
+func (t *BybitTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
+	inst, err := t.instrument(symbol)
+	if err != nil {
+		return "", err
+	}
+	return formatStep(quantity, inst.QtyStep), nil
+}
+
+// FormatPrice 格式化价格到tickSize
+func (t *BybitTrader) FormatPrice(symbol string, price float64) (string, error) {
+	inst, err := t.instrument(symbol)
+	if err != nil {
+		return "", err
+	}
+	return formatStep(price, inst.TickSize), nil
+}
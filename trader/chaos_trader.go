@@ -0,0 +1,235 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"nofx/errs"
+	"sync"
+	"time"
+)
+
+// ChaosConfig 🧪 故障注入配置（仅用于模拟交易，验证交易所异常时机器人的表现）
+type ChaosConfig struct {
+	ErrorRate       float64 // 每次交易所调用返回瞬时错误的概率（0-1）
+	MaxLatency      time.Duration
+	PartialFillRate float64 // 开仓只成交一部分（30%-90%）的概率（0-1）
+	Seed            int64   // 随机种子（0=按时间，固定种子可复现同一故障序列）
+}
+
+// Enabled 是否注入任何故障
+func (c *ChaosConfig) Enabled() bool {
+	return c != nil && (c.ErrorRate > 0 || c.MaxLatency > 0 || c.PartialFillRate > 0)
+}
+
+// chaosErrors 注入的错误（与真实交易所的报错文本和错误类别一致，触发相同的重试/降级逻辑）
+var chaosErrors = []struct {
+	kind errs.Kind
+	code int64
+	msg  string
+}{
+	{errs.RateLimited, -1003, "<APIError> code=-1003, msg=Too many requests; current limit is 2400 requests per minute."},
+	{errs.Transient, -1001, "<APIError> code=-1001, msg=Internal error; unable to process your request. Please try again."},
+	{errs.InsufficientMargin, -2019, "<APIError> code=-2019, msg=Margin is insufficient."},
+	{errs.Transient, 0, "read tcp 10.0.0.1:443: i/o timeout"},
+	{errs.Maintenance, 0, "HTTP 503: Service Unavailable"},
+}
+
+// chaosAmbiguousError 下单已执行但响应丢失（执行状态未知）
+const chaosAmbiguousError = "<APIError> code=-1007, msg=Timeout waiting for response from backend server. Send status unknown; execution status unknown."
+
+// randomError 随机取一个注入错误
+func (c *ChaosTrader) randomError(op string) error {
+	e := chaosErrors[c.intn(len(chaosErrors))]
+	log.Printf("🧪 [故障注入] %s: %s", op, e.msg)
+	return errs.Wrap(e.kind, e.code, errors.New(e.msg))
+}
+
+// ChaosTrader 在Trader接口外包一层故障注入：随机错误、延迟、开仓部分成交
+// 下单类调用注入的错误有一半发生在订单实际执行之后（模拟响应丢失），用于验证重试不会重复开仓
+type ChaosTrader struct {
+	Trader
+	cfg ChaosConfig
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewChaosTrader 包装交易器
+func NewChaosTrader(inner Trader, cfg ChaosConfig) *ChaosTrader {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &ChaosTrader{Trader: inner, cfg: cfg, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Unwrap 被包装的交易器
+func (c *ChaosTrader) Unwrap() Trader {
+	return c.Trader
+}
+
+func (c *ChaosTrader) float64() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rng.Float64()
+}
+
+func (c *ChaosTrader) intn(n int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rng.Intn(n)
+}
+
+// before 调用前：随机延迟，按概率返回错误
+func (c *ChaosTrader) before(op string) error {
+	if c.cfg.MaxLatency > 0 {
+		time.Sleep(time.Duration(c.float64() * float64(c.cfg.MaxLatency)))
+	}
+	if c.cfg.ErrorRate > 0 && c.float64() < c.cfg.ErrorRate {
+		return c.randomError(op)
+	}
+	return nil
+}
+
+// order 下单类调用：错误可能发生在执行前，也可能发生在执行后（响应丢失）
+func (c *ChaosTrader) order(op string, call func() (*OrderResult, error)) (*OrderResult, error) {
+	if c.cfg.MaxLatency > 0 {
+		time.Sleep(time.Duration(c.float64() * float64(c.cfg.MaxLatency)))
+	}
+	if c.cfg.ErrorRate > 0 && c.float64() < c.cfg.ErrorRate {
+		if c.float64() < 0.5 {
+			return nil, c.randomError(op)
+		}
+		if _, err := call(); err != nil {
+			return nil, err
+		}
+		log.Printf("🧪 [故障注入] %s 已执行但返回: %s", op, chaosAmbiguousError)
+		return nil, errs.Wrap(errs.Transient, -1007, errors.New(chaosAmbiguousError))
+	}
+	return call()
+}
+
+// open 开仓：按概率只成交一部分
+func (c *ChaosTrader) open(op, symbol string, quantity float64, call func(float64) (*OrderResult, error)) (*OrderResult, error) {
+	filled := quantity
+	if c.cfg.PartialFillRate > 0 && c.float64() < c.cfg.PartialFillRate {
+		filled = quantity * (0.3 + c.float64()*0.6)
+		log.Printf("🧪 [故障注入] %s %s 部分成交: %.6f / %.6f", op, symbol, filled, quantity)
+	}
+	result, err := c.order(op+" "+symbol, func() (*OrderResult, error) { return call(filled) })
+	if err != nil || filled == quantity {
+		return result, err
+	}
+	result.OrigQty = quantity
+	result.ExecutedQty = filled
+	result.Status = "PARTIALLY_FILLED"
+	return result, nil
+}
+
+func (c *ChaosTrader) GetBalance() (*Balance, error) {
+	if err := c.before("GetBalance"); err != nil {
+		return nil, err
+	}
+	return c.Trader.GetBalance()
+}
+
+func (c *ChaosTrader) GetPositions() ([]Position, error) {
+	if err := c.before("GetPositions"); err != nil {
+		return nil, err
+	}
+	return c.Trader.GetPositions()
+}
+
+func (c *ChaosTrader) OpenLong(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return c.open("OpenLong", symbol, quantity, func(q float64) (*OrderResult, error) {
+		return c.Trader.OpenLong(symbol, q, leverage)
+	})
+}
+
+func (c *ChaosTrader) OpenShort(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return c.open("OpenShort", symbol, quantity, func(q float64) (*OrderResult, error) {
+		return c.Trader.OpenShort(symbol, q, leverage)
+	})
+}
+
+func (c *ChaosTrader) CloseLong(symbol string, quantity float64) (*OrderResult, error) {
+	return c.order("CloseLong "+symbol, func() (*OrderResult, error) {
+		return c.Trader.CloseLong(symbol, quantity)
+	})
+}
+
+func (c *ChaosTrader) CloseShort(symbol string, quantity float64) (*OrderResult, error) {
+	return c.order("CloseShort "+symbol, func() (*OrderResult, error) {
+		return c.Trader.CloseShort(symbol, quantity)
+	})
+}
+
+func (c *ChaosTrader) SetLeverage(symbol string, leverage int) error {
+	if err := c.before("SetLeverage " + symbol); err != nil {
+		return err
+	}
+	return c.Trader.SetLeverage(symbol, leverage)
+}
+
+func (c *ChaosTrader) GetMarketPrice(symbol string) (float64, error) {
+	if err := c.before("GetMarketPrice " + symbol); err != nil {
+		return 0, err
+	}
+	return c.Trader.GetMarketPrice(symbol)
+}
+
+func (c *ChaosTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+	if err := c.before("SetStopLoss " + symbol); err != nil {
+		return err
+	}
+	return c.Trader.SetStopLoss(symbol, positionSide, quantity, stopPrice)
+}
+
+func (c *ChaosTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	if err := c.before("SetTakeProfit " + symbol); err != nil {
+		return err
+	}
+	return c.Trader.SetTakeProfit(symbol, positionSide, quantity, takeProfitPrice)
+}
+
+func (c *ChaosTrader) SetPartialTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	setter, ok := c.Trader.(partialTakeProfitSetter)
+	if !ok {
+		return fmt.Errorf("交易器不支持分批止盈")
+	}
+	if err := c.before("SetPartialTakeProfit " + symbol); err != nil {
+		return err
+	}
+	return setter.SetPartialTakeProfit(symbol, positionSide, quantity, takeProfitPrice)
+}
+
+func (c *ChaosTrader) CancelAllOrders(symbol string) error {
+	if err := c.before("CancelAllOrders " + symbol); err != nil {
+		return err
+	}
+	return c.Trader.CancelAllOrders(symbol)
+}
+
+// SetStopMovedHandler 转发移动止损回调注册
+func (c *ChaosTrader) SetStopMovedHandler(handler StopMovedHandler) {
+	if reporter, ok := c.Trader.(stopMoveReporter); ok {
+		reporter.SetStopMovedHandler(handler)
+	}
+}
+
+// SetTrailingSkip 转发移动止损跳过判断
+func (c *ChaosTrader) SetTrailingSkip(skip func(symbol, side string) bool) {
+	if skipper, ok := c.Trader.(trailingSkipper); ok {
+		skipper.SetTrailingSkip(skip)
+	}
+}
+
+// unwrapTrader 去掉故障注入层，得到实际交易器（用于具体类型判断）
+func unwrapTrader(t Trader) Trader {
+	if chaos, ok := t.(*ChaosTrader); ok {
+		return chaos.Unwrap()
+	}
+	return t
+}
@@ -0,0 +1,159 @@
+package trader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	clockSyncInterval   = 10 * time.Minute // 定期与交易所服务器时间校准
+	clockSkewWarn       = time.Second      // 本地时钟偏差超过1秒时告警
+	clockResyncCooldown = 10 * time.Second // -1021触发的重新校准最小间隔
+)
+
+// clockSync 交易所服务器时间同步
+// 定期请求服务器时间，计算本地时钟偏差（正值=本地超前），签名请求的timestamp减去偏差；
+// 遇到 -1021（timestamp超出recvWindow）时立即重新校准
+type clockSync struct {
+	name       string
+	serverTime func(ctx context.Context) (int64, error) // 服务器时间（毫秒）
+	apply      func(offsetMs int64)                     // 把偏差应用到客户端（可为nil）
+
+	offsetMs atomic.Int64
+	mu       sync.Mutex
+	lastSync time.Time
+	syncing  bool
+}
+
+func newClockSync(name string, serverTime func(ctx context.Context) (int64, error), apply func(offsetMs int64)) *clockSync {
+	return &clockSync{name: name, serverTime: serverTime, apply: apply}
+}
+
+// Sync 校准一次（以请求往返的中点作为服务器时间对应的本地时刻）
+func (c *clockSync) Sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	server, err := c.serverTime(ctx)
+	if err != nil {
+		return fmt.Errorf("获取%s服务器时间失败: %w", c.name, err)
+	}
+	end := time.Now()
+
+	local := start.Add(end.Sub(start) / 2).UnixMilli()
+	offset := local - server
+	c.offsetMs.Store(offset)
+	if c.apply != nil {
+		c.apply(offset)
+	}
+
+	c.mu.Lock()
+	c.lastSync = time.Now()
+	c.mu.Unlock()
+
+	skew := time.Duration(offset) * time.Millisecond
+	if skew > clockSkewWarn || skew < -clockSkewWarn {
+		log.Printf("🚨 [%s] 本地时钟与交易所服务器偏差%+v（已自动校正签名时间戳，请检查系统NTP同步）", c.name, skew)
+	}
+	return nil
+}
+
+// Start 启动定期校准（首次同步失败不影响启动，按本地时间签名）
+func (c *clockSync) Start() {
+	if err := c.Sync(); err != nil {
+		log.Printf("⚠️  %v（将使用本地时间）", err)
+	} else {
+		log.Printf("🕒 [%s] 服务器时间已校准，本地偏差%+dms", c.name, c.offsetMs.Load())
+	}
+
+	go func() {
+		ticker := time.NewTicker(clockSyncInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := c.Sync(); err != nil {
+				log.Printf("⚠️  %v", err)
+			}
+		}
+	}()
+}
+
+// NowMillis 校正后的当前时间戳（毫秒，用于签名）
+func (c *clockSync) NowMillis() int64 {
+	return time.Now().UnixMilli() - c.offsetMs.Load()
+}
+
+// Offset 当前本地时钟偏差
+func (c *clockSync) Offset() time.Duration {
+	return time.Duration(c.offsetMs.Load()) * time.Millisecond
+}
+
+// ObserveError 遇到 -1021 时异步重新校准（返回是否为时间戳错误）
+func (c *clockSync) ObserveError(msg string) bool {
+	if !isTimestampError(msg) {
+		return false
+	}
+
+	c.mu.Lock()
+	if c.syncing || time.Since(c.lastSync) < clockResyncCooldown {
+		c.mu.Unlock()
+		return true
+	}
+	c.syncing = true
+	c.mu.Unlock()
+
+	log.Printf("🚨 [%s] 签名时间戳超出recvWindow(-1021)，立即重新校准服务器时间", c.name)
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			c.syncing = false
+			c.mu.Unlock()
+		}()
+		if err := c.Sync(); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	}()
+	return true
+}
+
+// isTimestampError 是否为时间戳超出recvWindow错误（Bybit为 recv_window）
+func isTimestampError(msg string) bool {
+	return strings.Contains(msg, "-1021") || strings.Contains(msg, "outside of the recvWindow") ||
+		strings.Contains(msg, "recv_window")
+}
+
+// clockTransport 检查响应中的 -1021 错误并触发重新校准
+type clockTransport struct {
+	base  http.RoundTripper
+	clock *clockSync
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *clockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusBadRequest {
+		return resp, err
+	}
+
+	// 读取响应体检查错误码，再放回去供调用方解析
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if readErr == nil {
+		t.clock.ObserveError(string(body))
+	}
+	return resp, nil
+}
+
+// ClockOffsetProvider 支持服务器时间同步的交易器
+type ClockOffsetProvider interface {
+	ClockOffset() time.Duration
+}
@@ -0,0 +1,44 @@
+package trader
+
+import (
+	"time"
+)
+
+// CloseInfo 平仓信息（用于动态冷却期）
+type CloseInfo struct {
+	Time        time.Time
+	RealizedPnL float64 // 已实现盈亏
+}
+
+// cooldownForPnL 按平仓盈亏计算冷却时长（所有交易所共用，由TradingConstraints统一判断）
+// 盈利10分钟、小亏(<5 USDT)20分钟、中亏(5-20 USDT)30分钟、大亏60分钟
+func cooldownForPnL(realizedPnL float64) time.Duration {
+	switch {
+	case realizedPnL >= 0:
+		return 10 * time.Minute // 盈利
+	case realizedPnL > -5:
+		return 20 * time.Minute // 小亏
+	case realizedPnL > -20:
+		return 30 * time.Minute // 中亏
+	default:
+		return 60 * time.Minute // 大亏
+	}
+}
+
+// cooldownDuration 币种平仓后的冷却时长，再乘以过度交易倍数（调用方持有锁）
+// 币种/类别显式配置了cooldown_minutes时以配置为准，否则取全局冷却期与按盈亏分级冷却期的较大值
+func (tc *TradingConstraints) cooldownDuration(symbol string, info CloseInfo) time.Duration {
+	var cooldown time.Duration
+	if minutes, ok := tc.configuredCooldown(symbol); ok {
+		cooldown = time.Duration(minutes) * time.Minute
+	} else {
+		cooldown = time.Duration(tc.cooldownMinutes) * time.Minute
+		if byPnL := cooldownForPnL(info.RealizedPnL); byPnL > cooldown {
+			cooldown = byPnL
+		}
+	}
+	if tc.cooldownMultiplier > 0 {
+		cooldown = time.Duration(float64(cooldown) * tc.cooldownMultiplier)
+	}
+	return cooldown
+}
@@ -0,0 +1,95 @@
+package trader
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCooldownForPnL(t *testing.T) {
+	tests := []struct {
+		pnl  float64
+		want time.Duration
+	}{
+		{12.5, 10 * time.Minute},
+		{0, 10 * time.Minute},
+		{-0.01, 20 * time.Minute},
+		{-4.99, 20 * time.Minute},
+		{-5, 30 * time.Minute},
+		{-19.99, 30 * time.Minute},
+		{-20, 60 * time.Minute},
+		{-500, 60 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := cooldownForPnL(tt.pnl); got != tt.want {
+			t.Errorf("cooldownForPnL(%v) = %v, want %v", tt.pnl, got, tt.want)
+		}
+	}
+}
+
+// closedAgo 模拟minutes分钟前平仓
+func closedAgo(tc *TradingConstraints, symbol string, minutes, pnl float64) {
+	tc.cooldownMap[symbol] = CloseInfo{Time: time.Now().Add(-time.Duration(minutes * float64(time.Minute))), RealizedPnL: pnl}
+}
+
+func TestTradingConstraintsCloseCooldown(t *testing.T) {
+	tests := []struct {
+		name       string
+		overrides  map[string]SymbolConstraints
+		multiplier float64
+		pnl        float64
+		minutesAgo float64
+		blocked    bool
+	}{
+		{"盈利取默认20分钟：15分钟前拦截", nil, 0, 5, 15, true},
+		{"盈利取默认20分钟：25分钟前放行", nil, 0, 5, 25, false},
+		{"中亏30分钟：25分钟前拦截", nil, 0, -10, 25, true},
+		{"大亏60分钟：45分钟前拦截", nil, 0, -50, 45, true},
+		{"大亏60分钟：65分钟前放行", nil, 0, -50, 65, false},
+		{"过度交易倍数2：盈利35分钟前拦截", nil, 2, 5, 35, true},
+		{"币种配置10分钟覆盖盈亏分级：大亏15分钟前放行", map[string]SymbolConstraints{"BTCUSDT": {CooldownMinutes: 10}}, 0, -50, 15, false},
+		{"类别配置10分钟覆盖盈亏分级：大亏5分钟前拦截", map[string]SymbolConstraints{SymbolClassMajors: {CooldownMinutes: 10}}, 0, -50, 5, true},
+		{"类别配置10分钟覆盖盈亏分级：大亏12分钟前放行", map[string]SymbolConstraints{SymbolClassMajors: {CooldownMinutes: 10}}, 0, -50, 12, false},
+		{"其他字段覆盖不影响盈亏分级", map[string]SymbolConstraints{"BTCUSDT": {MinHoldingMinutes: 5}}, 0, -50, 45, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := NewTradingConstraints()
+			if tt.overrides != nil {
+				tc.SetSymbolOverrides(nil, tt.overrides)
+			}
+			tc.SetCooldownMultiplier(tt.multiplier)
+			closedAgo(tc, "BTCUSDT", tt.minutesAgo, tt.pnl)
+
+			err := tc.CanOpenPosition("BTCUSDT", 0)
+			if blocked := errors.Is(err, ErrCooldown); blocked != tt.blocked {
+				t.Errorf("CanOpenPosition() = %v, want blocked=%v", err, tt.blocked)
+			}
+			if other := tc.CanOpenPosition("ETHUSDT", 0); other != nil {
+				t.Errorf("其他币种不应受冷却期影响: %v", other)
+			}
+		})
+	}
+}
+
+func TestCooldownOverrideBypassesPnLCooldown(t *testing.T) {
+	tc := NewTradingConstraints()
+	tc.SetCooldownOverridePolicy(1, 85)
+	tc.RecordClosePosition("BTCUSDT", "long", -50)
+
+	if err := tc.CanOpenPosition("BTCUSDT", 0); !errors.Is(err, ErrCooldown) {
+		t.Fatalf("CanOpenPosition() = %v, want ErrCooldown", err)
+	}
+	if err := tc.CanOverrideCooldown("BTCUSDT", 0, 80); err == nil {
+		t.Errorf("信心度80低于要求时不应豁免")
+	}
+	if err := tc.CanOverrideCooldown("BTCUSDT", 0, 90); err != nil {
+		t.Fatalf("CanOverrideCooldown() = %v, want nil", err)
+	}
+
+	// 豁免次数在开仓成功后才扣减
+	tc.RecordCooldownOverride("BTCUSDT", 90)
+	if err := tc.CanOverrideCooldown("BTCUSDT", 0, 90); err == nil {
+		t.Errorf("当日豁免次数用完后不应再豁免")
+	}
+}
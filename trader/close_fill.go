@@ -0,0 +1,160 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// 平仓原因
+const (
+	CloseReasonStopLoss    = "stop_loss"
+	CloseReasonTakeProfit  = "take_profit"
+	CloseReasonLiquidation = "liquidation"
+	CloseReasonManual      = "manual" // 非机器人决策的普通平仓（如交易所App手动平仓）
+)
+
+// CloseFill 交易所订单历史中的平仓成交
+type CloseFill struct {
+	OrderID      int64
+	OrderType    string  // 原始订单类型（STOP_MARKET / TAKE_PROFIT_MARKET / MARKET / LIQUIDATION...）
+	Reason       string  // CloseReasonXxx
+	TriggerPrice float64 // 条件单触发价（非条件单为0）
+	FillPrice    float64 // 成交均价
+	Quantity     float64
+	Time         time.Time // 成交时间
+}
+
+// CloseFillProvider 支持从订单历史查询平仓成交的交易器
+type CloseFillProvider interface {
+	// GetCloseFill 查询since之后该持仓方向最近一笔平仓成交（没有时返回nil, nil）
+	GetCloseFill(symbol, side string, since time.Time) (*CloseFill, error)
+}
+
+// GetCloseFill 从订单历史中找出持仓消失对应的平仓成交
+func (t *FuturesTrader) GetCloseFill(symbol, side string, since time.Time) (*CloseFill, error) {
+	orders, err := t.client.NewListOrdersService().
+		Symbol(symbol).
+		StartTime(since.UnixMilli()).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("查询订单历史失败: %w", err)
+	}
+
+	closeSide := futures.SideTypeSell
+	positionSide := futures.PositionSideTypeLong
+	if side == "short" {
+		closeSide = futures.SideTypeBuy
+		positionSide = futures.PositionSideTypeShort
+	}
+
+	var latest *futures.Order
+	for _, order := range orders {
+		if order.Status != futures.OrderStatusTypeFilled || order.Side != closeSide {
+			continue
+		}
+		if order.PositionSide != positionSide && order.PositionSide != futures.PositionSideTypeBoth {
+			continue
+		}
+		if latest == nil || order.UpdateTime > latest.UpdateTime {
+			latest = order
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+
+	fill := &CloseFill{
+		OrderID:   latest.OrderID,
+		OrderType: string(latest.OrigType),
+		Time:      time.UnixMilli(latest.UpdateTime),
+	}
+	if fill.OrderType == "" {
+		fill.OrderType = string(latest.Type)
+	}
+	fill.TriggerPrice, _ = strconv.ParseFloat(latest.StopPrice, 64)
+	fill.FillPrice, _ = strconv.ParseFloat(latest.AvgPrice, 64)
+	fill.Quantity, _ = strconv.ParseFloat(latest.ExecutedQuantity, 64)
+
+	switch {
+	// 强平/自动减仓单的clientOrderId以autoclose-或adl_autoclose开头
+	case futures.OrderType(fill.OrderType) == futures.OrderTypeLiquidation ||
+		strings.HasPrefix(latest.ClientOrderID, "autoclose-") ||
+		strings.HasPrefix(latest.ClientOrderID, "adl_autoclose"):
+		fill.Reason = CloseReasonLiquidation
+	case futures.OrderType(fill.OrderType) == futures.OrderTypeStopMarket ||
+		futures.OrderType(fill.OrderType) == futures.OrderTypeStop ||
+		futures.OrderType(fill.OrderType) == futures.OrderTypeTrailingStopMarket:
+		fill.Reason = CloseReasonStopLoss
+	case futures.OrderType(fill.OrderType) == futures.OrderTypeTakeProfitMarket ||
+		futures.OrderType(fill.OrderType) == futures.OrderTypeTakeProfit:
+		fill.Reason = CloseReasonTakeProfit
+	default:
+		fill.Reason = CloseReasonManual
+	}
+	return fill, nil
+}
+
+// slippagePct 相对触发价的不利滑点（%，正数=成交价比触发价差）
+func slippagePct(side string, triggerPrice, fillPrice float64) float64 {
+	if triggerPrice <= 0 || fillPrice <= 0 {
+		return 0
+	}
+	if side == "long" {
+		return (triggerPrice - fillPrice) / triggerPrice * 100 // 平多=卖出，成交低于触发价为不利
+	}
+	return (fillPrice - triggerPrice) / triggerPrice * 100
+}
+
+// auditVanishedPosition 🎯 持仓在交易所侧消失（非机器人平仓）时，从订单历史确认平仓原因、触发价和成交价并写入审计记录
+// 交易所不支持或查询失败时退回按最后一次盈亏推断
+func (at *AutoTrader) auditVanishedPosition(last decision.PositionInfo) *logger.CloseFillRecord {
+	record := &logger.CloseFillRecord{
+		Time:       time.Now(),
+		Symbol:     last.Symbol,
+		Side:       last.Side,
+		Reason:     CloseReasonStopLoss,
+		Source:     "inferred",
+		EntryPrice: last.EntryPrice,
+		LastPrice:  last.MarkPrice,
+	}
+	if last.UnrealizedPnLPct > 0 {
+		record.Reason = CloseReasonTakeProfit
+	}
+
+	if provider, ok := at.trader.(CloseFillProvider); ok {
+		// 币安订单历史查询窗口最多7天
+		since := last.OpenTime
+		if since.IsZero() || time.Since(since) > 7*24*time.Hour {
+			since = time.Now().Add(-7 * 24 * time.Hour)
+		}
+		fill, err := provider.GetCloseFill(last.Symbol, last.Side, since.Add(-time.Minute))
+		if err != nil {
+			log.Printf("⚠️  [%s] 查询%s %s平仓成交失败，按盈亏推断平仓原因: %v", at.name, last.Symbol, last.Side, err)
+		} else if fill != nil {
+			record.Time = fill.Time
+			record.Reason = fill.Reason
+			record.Source = "exchange"
+			record.OrderID = fill.OrderID
+			record.OrderType = fill.OrderType
+			record.TriggerPrice = fill.TriggerPrice
+			record.FillPrice = fill.FillPrice
+			record.Quantity = fill.Quantity
+			record.SlippagePct = slippagePct(last.Side, fill.TriggerPrice, fill.FillPrice)
+			log.Printf("🎯 [%s] %s %s 平仓确认: %s | 触发价 %.4f | 成交价 %.4f | 滑点 %.3f%%",
+				at.name, last.Symbol, strings.ToUpper(last.Side), fill.Reason, fill.TriggerPrice, fill.FillPrice, record.SlippagePct)
+		}
+	}
+
+	if err := at.decisionLogger.LogCloseFill(record); err != nil {
+		log.Printf("⚠️  [%s] 写入平仓审计记录失败: %v", at.name, err)
+	}
+	return record
+}
@@ -0,0 +1,61 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/logger"
+)
+
+// confidenceCalibrationMinTrades 信心度档位至少有这么多笔历史交易才参与校准
+const confidenceCalibrationMinTrades = 5
+
+// ConfidenceSizingConfig 🎯 按AI信心度调整仓位：信心度50及以下用Min倍，100用Max倍，中间线性插值；
+// 该信心度档位有足够历史交易时，按档位胜率相对整体胜率的比例（0.5-1.5）修正
+type ConfidenceSizingConfig struct {
+	Min float64 // 最小仓位倍数（如0.5）
+	Max float64 // 最大仓位倍数（如1.5）
+}
+
+// Enabled 是否启用信心度仓位调整
+func (c ConfidenceSizingConfig) Enabled() bool {
+	return c.Min > 0 && c.Max > 0
+}
+
+// confidenceMultiplier 计算仓位倍数和说明（perf为nil或档位样本不足时只按信心度计算）
+func confidenceMultiplier(cfg ConfidenceSizingConfig, confidence int, perf *logger.PerformanceAnalysis) (float64, string) {
+	score := math.Max(0, math.Min(1, float64(confidence-50)/50))
+	note := fmt.Sprintf("信心度%d", confidence)
+
+	if perf != nil && perf.WinRate > 0 {
+		if stats, ok := perf.ConfidenceStats[logger.ConfidenceBucket(confidence)]; ok && stats.TotalTrades >= confidenceCalibrationMinTrades {
+			calibration := math.Max(0.5, math.Min(1.5, stats.WinRate/perf.WinRate))
+			score = math.Max(0, math.Min(1, score*calibration))
+			note += fmt.Sprintf("，%d-%d档历史胜率%.0f%%（整体%.0f%%，%d笔）校准×%.2f",
+				stats.Bucket, stats.Bucket+9, stats.WinRate, perf.WinRate, stats.TotalTrades, calibration)
+		}
+	}
+	return cfg.Min + (cfg.Max-cfg.Min)*score, note
+}
+
+// applyConfidenceSizing 开仓前按信心度缩放仓位，返回实际倍数（0=未调整；未提供信心度时不调整，缩仓不低于最小下单金额）
+func (at *AutoTrader) applyConfidenceSizing(d *decision.Decision) float64 {
+	cfg := at.config.ConfidenceSizing
+	if !cfg.Enabled() || d.Confidence <= 0 || d.PositionSizeUSD <= 0 {
+		return 0
+	}
+	if d.Action != "open_long" && d.Action != "open_short" && d.Action != "replace_position" {
+		return 0
+	}
+
+	multiplier, note := confidenceMultiplier(cfg, d.Confidence, at.lastPerformance)
+	oldSize := d.PositionSizeUSD
+	newSize := oldSize * multiplier
+	if newSize < minOrderNotionalUSD && oldSize >= minOrderNotionalUSD {
+		newSize = minOrderNotionalUSD
+	}
+	d.PositionSizeUSD = newSize
+	log.Printf("  🎯 %s 信心度仓位调整 ×%.2f: %.2f → %.2f USDT（%s）", d.Symbol, multiplier, oldSize, newSize, note)
+	return newSize / oldSize
+}
@@ -0,0 +1,150 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/events"
+	"sync"
+	"time"
+)
+
+const (
+	watchdogProbeInterval      = 30 * time.Second
+	defaultConnectivityLossMax = 3 * time.Minute // 默认连续失联3分钟判定为中断
+)
+
+// connectivityWatchdog 交易所连通性看门狗：持续失联判定为中断，恢复后通知主循环对账
+type connectivityWatchdog struct {
+	mu        sync.Mutex
+	lostSince time.Time // 首次探测失败时间（零值=连接正常）
+	outage    bool      // 失联已超过阈值
+
+	recovered chan time.Duration // 中断恢复通知（值为失联时长），由主循环处理，避免与决策周期并发修改状态
+}
+
+func newConnectivityWatchdog() *connectivityWatchdog {
+	return &connectivityWatchdog{recovered: make(chan time.Duration, 1)}
+}
+
+// connectivityLossThreshold 判定中断的失联时长（0=默认3分钟，<0=禁用看门狗）
+func (at *AutoTrader) connectivityLossThreshold() time.Duration {
+	if at.config.ConnectivityLossTimeout == 0 {
+		return defaultConnectivityLossMax
+	}
+	return at.config.ConnectivityLossTimeout
+}
+
+// runConnectivityWatchdog 🐕 定期探测交易所API
+func (at *AutoTrader) runConnectivityWatchdog(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(watchdogProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			at.probeConnectivity()
+		}
+	}
+}
+
+// probeConnectivity 单次探测：记录失联起点，超过阈值标记中断；中断后首次探测成功时通知主循环
+func (at *AutoTrader) probeConnectivity() {
+	_, err := at.trader.GetMarketPrice("BTCUSDT")
+	now := time.Now()
+
+	w := at.watchdog
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err != nil {
+		if w.lostSince.IsZero() {
+			w.lostSince = now
+			log.Printf("⚠️  [%s] 交易所API探测失败: %v", at.name, err)
+		}
+		if !w.outage && now.Sub(w.lostSince) >= at.connectivityLossThreshold() {
+			w.outage = true
+			log.Printf("🚨 [%s] 交易所已失联%.0f分钟，暂停决策周期；恢复后将立即对账并检查止损单",
+				at.name, now.Sub(w.lostSince).Minutes())
+			at.events.Publish(events.RiskPausedEvent{
+				TraderID: at.id,
+				Reason:   fmt.Sprintf("交易所失联: %v", err),
+				Time:     now,
+			})
+		}
+		return
+	}
+
+	if w.outage {
+		downtime := now.Sub(w.lostSince)
+		log.Printf("🔌 [%s] 交易所连接已恢复（失联%.0f分钟）", at.name, downtime.Minutes())
+		select {
+		case w.recovered <- downtime:
+		default:
+		}
+	} else if !w.lostSince.IsZero() {
+		log.Printf("✓ [%s] 交易所API探测恢复（短暂失联%.0f秒）", at.name, now.Sub(w.lostSince).Seconds())
+	}
+	w.outage = false
+	w.lostSince = time.Time{}
+}
+
+// exchangeOutage 交易所是否处于中断状态
+func (at *AutoTrader) exchangeOutage() bool {
+	at.watchdog.mu.Lock()
+	defer at.watchdog.mu.Unlock()
+	return at.watchdog.outage
+}
+
+// recoverFromOutage 中断恢复：重新对账持仓、补设缺失的止损；仍无止损的持仓按配置平仓
+func (at *AutoTrader) recoverFromOutage(downtime time.Duration) {
+	log.Printf("🔎 [%s] 失联%.0f分钟后重新对账持仓...", at.name, downtime.Minutes())
+
+	if binanceTrader, ok := at.trader.(*FuturesTrader); ok {
+		binanceTrader.InvalidatePositionsCache()
+	}
+	if err := at.reconcileStartupPositions(); err != nil {
+		log.Printf("❌ [%s] 恢复后对账失败: %v（请手动检查持仓）", at.name, err)
+		return
+	}
+
+	// 不支持查询挂单的交易所无法确认止损是否存在，不做平仓处理
+	if _, ok := at.trader.(openOrderLister); !ok {
+		log.Printf("⚠️  [%s] 交易所不支持查询挂单，无法核实止损单，请手动检查", at.name)
+		return
+	}
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		log.Printf("❌ [%s] 获取持仓失败: %v", at.name, err)
+		return
+	}
+
+	unprotected := 0
+	for _, pos := range positions {
+		symbol, side := pos.Symbol, pos.Side
+		if at.ignoredPositions[symbol+"_"+side] {
+			continue
+		}
+		if stopLoss, _ := at.protectiveOrderPrices(symbol, side); stopLoss > 0 {
+			continue
+		}
+
+		unprotected++
+		if !at.config.FlattenUnprotectedOnReconnect || at.config.ObserveMode {
+			log.Printf("🚨 [%s] %s %s 没有止损单，请手动处理", at.name, symbol, side)
+			continue
+		}
+		log.Printf("🚨 [%s] %s %s 没有止损单，按配置立即平仓", at.name, symbol, side)
+		if err := at.closeUnknownPosition(symbol, side); err != nil {
+			log.Printf("  ❌ 平仓失败: %v", err)
+			continue
+		}
+		at.orderManager.RemoveProtection(symbol, side)
+	}
+
+	if unprotected == 0 {
+		log.Printf("✅ [%s] 恢复对账完成：%d个持仓均有止损保护", at.name, len(positions))
+	}
+}
@@ -1,21 +1,26 @@
 package trader
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 )
 
+// ErrCooldown 同币种冷却期拦截（可被AI的 override_cooldown 豁免）
+var ErrCooldown = errors.New("冷却期限制")
+
 // TradingConstraints 交易硬约束管理器
 type TradingConstraints struct {
 	mu sync.RWMutex
 
-	// 冷却期追踪：symbol -> 平仓时间
-	cooldownMap map[string]time.Time
+	// 冷却期追踪：symbol -> 平仓时间和盈亏（交易所适配器不再单独判断冷却期）
+	cooldownMap map[string]CloseInfo
 
 	// 日交易计数
-	dailyOpenCount  int
-	dailyResetTime  time.Time
+	dailyOpenCount int
+	dailyResetTime time.Time
 
 	// 小时交易计数
 	hourlyOpenCount int
@@ -25,33 +30,72 @@ type TradingConstraints struct {
 	positionOpenTime map[string]time.Time
 
 	// 配置参数
-	cooldownMinutes      int // 同币种冷却期（分钟）
-	maxDailyTrades       int // 每日最大开仓次数
-	maxHourlyTrades      int // 每小时最大开仓次数
-	minHoldingMinutes    int // 最短持仓时间（分钟）
-	maxPositions         int // 最大持仓数量
+	cooldownMinutes   int // 同币种冷却期（分钟）
+	maxDailyTrades    int // 每日最大开仓次数
+	maxHourlyTrades   int // 每小时最大开仓次数
+	minHoldingMinutes int // 最短持仓时间（分钟）
+	maxPositions      int // 最大持仓数量
+
+	cooldownMultiplier float64 // 🚦 冷却期倍数（过度交易保护生效时>1，0=不调整）
+
+	// 🎚️ 按类别/币种覆盖的约束（见 SetSymbolOverrides）
+	majorSymbols map[string]bool
+	overrides    map[string]SymbolConstraints
+	symbolOpens  map[string][]time.Time // 各币种24小时内的开仓时间
+
+	// 🎟️ 冷却期豁免（AI标记override_cooldown的强信心反手重新入场）
+	maxCooldownOverrides  int       // 每日最多豁免次数（0=不允许）
+	minOverrideConfidence int       // 豁免要求的最低信心度（0-100）
+	overrideCount         int       // 当日已用豁免次数
+	overrideResetTime     time.Time // 豁免计数重置时间
 }
 
 // NewTradingConstraints 创建交易约束管理器
 func NewTradingConstraints() *TradingConstraints {
 	return &TradingConstraints{
-		cooldownMap:          make(map[string]time.Time),
-		positionOpenTime:     make(map[string]time.Time),
-		dailyResetTime:       time.Now(),
-		hourlyResetTime:      time.Now(),
-		cooldownMinutes:      20,  // 20分钟冷却期（与binance_futures统一）
-		maxDailyTrades:       999, // 实际取消日交易上限
-		maxHourlyTrades:      3,   // 【优化】每小时最多3次（从2次放宽）
-		minHoldingMinutes:    15,  // 最短持有15分钟
-		maxPositions:         3,   // 最多持仓3个币种
+		cooldownMap:           make(map[string]CloseInfo),
+		positionOpenTime:      make(map[string]time.Time),
+		symbolOpens:           make(map[string][]time.Time),
+		dailyResetTime:        time.Now(),
+		hourlyResetTime:       time.Now(),
+		cooldownMinutes:       20,  // 20分钟冷却期（与binance_futures统一）
+		maxDailyTrades:        999, // 实际取消日交易上限
+		maxHourlyTrades:       3,   // 【优化】每小时最多3次（从2次放宽）
+		minHoldingMinutes:     15,  // 最短持有15分钟
+		maxPositions:          3,   // 最多持仓3个币种
+		minOverrideConfidence: 85,  // 冷却期豁免要求信心度≥85
+		overrideResetTime:     time.Now(),
+	}
+}
+
+// SetMaxPositions 设置最大持仓数量
+func (tc *TradingConstraints) SetMaxPositions(n int) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if n > 0 {
+		tc.maxPositions = n
 	}
 }
 
+// SetCooldownMultiplier 设置冷却期倍数（1=恢复正常）
+func (tc *TradingConstraints) SetCooldownMultiplier(m float64) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.cooldownMultiplier = m
+}
+
 // CanOpenPosition 检查是否允许开仓
 func (tc *TradingConstraints) CanOpenPosition(symbol string, currentPositionCount int) error {
 	tc.mu.RLock()
 	defer tc.mu.RUnlock()
 
+	return tc.canOpen(symbol, currentPositionCount, true)
+}
+
+// canOpen 开仓检查（checkCooldown=false 用于冷却期豁免，调用方持有锁）
+func (tc *TradingConstraints) canOpen(symbol string, currentPositionCount int, checkCooldown bool) error {
 	now := time.Now()
 
 	// 0. 检查最大持仓数量（新增）
@@ -60,13 +104,14 @@ func (tc *TradingConstraints) CanOpenPosition(symbol string, currentPositionCoun
 			currentPositionCount, tc.maxPositions)
 	}
 
-	// 1. 检查冷却期
-	if lastCloseTime, exists := tc.cooldownMap[symbol]; exists {
-		cooldownDuration := time.Duration(tc.cooldownMinutes) * time.Minute
-		if now.Sub(lastCloseTime) < cooldownDuration {
-			remaining := cooldownDuration - now.Sub(lastCloseTime)
-			return fmt.Errorf("冷却期限制：%s 在 %.1f 分钟前刚平仓，需等待 %.1f 分钟后才能重新开仓",
-				symbol, now.Sub(lastCloseTime).Minutes(), remaining.Minutes())
+	limits := tc.limitsFor(symbol)
+
+	// 1. 检查冷却期（按上次平仓盈亏动态延长）
+	if closeInfo, exists := tc.cooldownMap[symbol]; exists && checkCooldown {
+		cooldownDuration := tc.cooldownDuration(symbol, closeInfo)
+		if elapsed := now.Sub(closeInfo.Time); elapsed < cooldownDuration {
+			return fmt.Errorf("%w：%s 在 %.1f 分钟前刚平仓（盈亏%+.2f USDT，冷却%.0f分钟），需等待 %.1f 分钟后才能重新开仓",
+				ErrCooldown, symbol, elapsed.Minutes(), closeInfo.RealizedPnL, cooldownDuration.Minutes(), (cooldownDuration - elapsed).Minutes())
 		}
 	}
 
@@ -93,7 +138,8 @@ func (tc *TradingConstraints) CanOpenPosition(symbol string, currentPositionCoun
 			hourlyCount, tc.maxHourlyTrades, remaining.Minutes())
 	}
 
-	return nil
+	// 4. 🎚️ 币种自身的开仓次数上限
+	return tc.checkSymbolTradeCaps(symbol, limits, now)
 }
 
 // RecordOpenPosition 记录开仓（增加计数）
@@ -119,20 +165,30 @@ func (tc *TradingConstraints) RecordOpenPosition(symbol, side string) {
 	tc.dailyOpenCount++
 	tc.hourlyOpenCount++
 
+	tc.recordSymbolOpen(symbol, now)
+
 	// 记录持仓开启时间
 	key := symbol + "_" + side
 	tc.positionOpenTime[key] = now
 }
 
-// RecordClosePosition 记录平仓（设置冷却期）
-func (tc *TradingConstraints) RecordClosePosition(symbol, side string) {
+// RestorePositionOpenTime 恢复持仓开仓时间（启动对账用，不计入开仓次数）
+func (tc *TradingConstraints) RestorePositionOpenTime(symbol, side string, openTime time.Time) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.positionOpenTime[symbol+"_"+side] = openTime
+}
+
+// RecordClosePosition 记录平仓（设置冷却期，realizedPnL未知时传0）
+func (tc *TradingConstraints) RecordClosePosition(symbol, side string, realizedPnL float64) {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
 	now := time.Now()
 
 	// 设置冷却期
-	tc.cooldownMap[symbol] = now
+	tc.cooldownMap[symbol] = CloseInfo{Time: now, RealizedPnL: realizedPnL}
 
 	// 清理持仓开启时间
 	key := symbol + "_" + side
@@ -158,12 +214,13 @@ func (tc *TradingConstraints) CanClosePosition(symbol, side string, isStopLoss b
 
 	now := time.Now()
 	holdingDuration := now.Sub(openTime)
-	minDuration := time.Duration(tc.minHoldingMinutes) * time.Minute
+	minHolding := tc.limitsFor(symbol).MinHoldingMinutes
+	minDuration := time.Duration(minHolding) * time.Minute
 
 	if holdingDuration < minDuration {
 		remaining := minDuration - holdingDuration
 		return fmt.Errorf("最短持仓限制：%s %s 持有时间仅 %.1f 分钟，需至少持有 %d 分钟（还需 %.1f 分钟）",
-			symbol, side, holdingDuration.Minutes(), tc.minHoldingMinutes, remaining.Minutes())
+			symbol, side, holdingDuration.Minutes(), minHolding, remaining.Minutes())
 	}
 
 	return nil
@@ -193,13 +250,26 @@ func (tc *TradingConstraints) GetStatus() map[string]interface{} {
 	dailyRemaining := 24*time.Hour - now.Sub(tc.dailyResetTime)
 	hourlyRemaining := time.Hour - now.Sub(tc.hourlyResetTime)
 
-	return map[string]interface{}{
-		"daily_trades":       tc.dailyOpenCount,
-		"max_daily_trades":   tc.maxDailyTrades,
-		"daily_reset_in":     fmt.Sprintf("%.1f小时", dailyRemaining.Hours()),
-		"hourly_trades":      tc.hourlyOpenCount,
-		"max_hourly_trades":  tc.maxHourlyTrades,
-		"hourly_reset_in":    fmt.Sprintf("%.0f分钟", hourlyRemaining.Minutes()),
-		"cooldown_symbols":   len(tc.cooldownMap),
+	status := map[string]interface{}{
+		"daily_trades":      tc.dailyOpenCount,
+		"max_daily_trades":  tc.maxDailyTrades,
+		"daily_reset_in":    fmt.Sprintf("%.1f小时", dailyRemaining.Hours()),
+		"hourly_trades":     tc.hourlyOpenCount,
+		"max_hourly_trades": tc.maxHourlyTrades,
+		"hourly_reset_in":   fmt.Sprintf("%.0f分钟", hourlyRemaining.Minutes()),
+		"cooldown_symbols":  len(tc.cooldownMap),
+		"cooldown_minutes":  float64(tc.cooldownMinutes) * math.Max(tc.cooldownMultiplier, 1),
+	}
+	if len(tc.overrides) > 0 {
+		overrides := make(map[string]SymbolConstraints, len(tc.overrides))
+		for key := range tc.overrides {
+			if key == SymbolClassMajors || key == SymbolClassAlts {
+				overrides[key] = SymbolConstraints{CooldownMinutes: tc.cooldownMinutes, MinHoldingMinutes: tc.minHoldingMinutes}.merge(tc.overrides[key])
+			} else {
+				overrides[key] = tc.limitsFor(key)
+			}
+		}
+		status["symbol_overrides"] = overrides // 各覆盖项合并默认值后的生效约束
 	}
+	return status
 }
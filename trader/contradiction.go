@@ -0,0 +1,170 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/decision/prompts"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	defaultContradictionMinConfidence      = 75  // 前后两次都达到该信心度才算"高信心反转"
+	defaultContradictionMaxMovePct         = 1.0 // 价格变动小于该比例(%)时反转视为自相矛盾
+	defaultContradictionRequiredConfidence = 90  // 自相矛盾的开仓需要的信心度
+)
+
+// ContradictionConfig 🔀 决策一致性检查：上一周期高信心做多、本周期高信心做空（或反之）且价格几乎没动时，标记为自相矛盾并提高开仓门槛
+type ContradictionConfig struct {
+	MinConfidence      int     // 高信心阈值（0=默认75）
+	MaxMovePct         float64 // 价格变动阈值（%，0=默认1，<0=不检查）
+	RequiredConfidence int     // 矛盾开仓要求的信心度（0=默认90）
+}
+
+func (c ContradictionConfig) minConfidence() int {
+	if c.MinConfidence > 0 {
+		return c.MinConfidence
+	}
+	return defaultContradictionMinConfidence
+}
+
+func (c ContradictionConfig) maxMovePct() float64 {
+	if c.MaxMovePct != 0 {
+		return c.MaxMovePct
+	}
+	return defaultContradictionMaxMovePct
+}
+
+func (c ContradictionConfig) requiredConfidence() int {
+	if c.RequiredConfidence > 0 {
+		return c.RequiredConfidence
+	}
+	return defaultContradictionRequiredConfidence
+}
+
+// directionalDecision 一个周期内某币种的开仓方向
+type directionalDecision struct {
+	Cycle      int       `json:"cycle"`
+	Side       string    `json:"side"`
+	Confidence int       `json:"confidence"`
+	Price      float64   `json:"price"`
+	Reasoning  string    `json:"reasoning"`
+	Time       time.Time `json:"time"`
+}
+
+// contradictionRecord 自相矛盾记录（contradictions.jsonl，用于调整prompt）
+type contradictionRecord struct {
+	Symbol        string              `json:"symbol"`
+	Previous      directionalDecision `json:"previous"`
+	Current       directionalDecision `json:"current"`
+	PriceMovePct  float64             `json:"price_move_pct"`
+	Rejected      bool                `json:"rejected"`
+	PromptVersion string              `json:"prompt_version,omitempty"`
+}
+
+// contradictionChecker 记录上一周期各币种的开仓方向
+type contradictionChecker struct {
+	mu       sync.Mutex
+	logPath  string
+	previous map[string]directionalDecision // 上一周期
+	current  map[string]directionalDecision // 本周期（周期结束后成为previous）
+	cycle    int
+}
+
+func newContradictionChecker(dir string) *contradictionChecker {
+	return &contradictionChecker{
+		logPath:  filepath.Join(dir, "contradictions.jsonl"),
+		previous: make(map[string]directionalDecision),
+		current:  make(map[string]directionalDecision),
+	}
+}
+
+// decisionSide 决策的开仓方向（非开仓返回空）
+func decisionSide(d *decision.Decision) string {
+	switch d.Action {
+	case "open_long":
+		return "long"
+	case "open_short":
+		return "short"
+	case "replace_position":
+		return d.Side
+	}
+	return ""
+}
+
+// checkContradiction 🔀 与上一周期同币种的高信心开仓方向对比：价格几乎没动就反向高信心开仓时标记矛盾，信心度不足要求值则拒绝
+// 返回的note记入执行记录；本周期的开仓方向会被记住，供下一周期对比
+func (at *AutoTrader) checkContradiction(d *decision.Decision, ctx *decision.Context) (string, error) {
+	side := decisionSide(d)
+	cfg := at.config.Contradiction
+	if side == "" || cfg.maxMovePct() < 0 {
+		return "", nil
+	}
+
+	price := 0.0
+	if md, ok := ctx.MarketDataMap[d.Symbol]; ok && md != nil {
+		price = md.CurrentPrice
+	} else if p, err := at.trader.GetMarketPrice(d.Symbol); err == nil {
+		price = p
+	}
+
+	c := at.contradictions
+	c.mu.Lock()
+	if c.cycle != at.callCount {
+		c.previous, c.current, c.cycle = c.current, make(map[string]directionalDecision), at.callCount
+	}
+	cur := directionalDecision{Cycle: at.callCount, Side: side, Confidence: d.Confidence, Price: price, Reasoning: d.Reasoning, Time: time.Now()}
+	c.current[d.Symbol] = cur
+	prev, ok := c.previous[d.Symbol]
+	c.mu.Unlock()
+
+	if !ok || prev.Cycle != at.callCount-1 || prev.Side == side || prev.Price <= 0 || price <= 0 {
+		return "", nil
+	}
+	if prev.Confidence < cfg.minConfidence() || d.Confidence < cfg.minConfidence() {
+		return "", nil
+	}
+	movePct := (price - prev.Price) / prev.Price * 100
+	if math.Abs(movePct) >= cfg.maxMovePct() {
+		return "", nil
+	}
+
+	required := cfg.requiredConfidence()
+	rejected := d.Confidence < required
+	note := fmt.Sprintf("🔀 决策自相矛盾: 上一周期%s(信心%d @%.4f) → 本周期%s(信心%d @%.4f)，价格仅变动%+.2f%%",
+		prev.Side, prev.Confidence, prev.Price, side, d.Confidence, price, movePct)
+	log.Printf("  %s", note)
+
+	c.appendLog(contradictionRecord{
+		Symbol:        d.Symbol,
+		Previous:      prev,
+		Current:       cur,
+		PriceMovePct:  movePct,
+		Rejected:      rejected,
+		PromptVersion: prompts.Version(),
+	})
+
+	if rejected {
+		return note, fmt.Errorf("决策自相矛盾：与上一周期方向相反且价格几乎没动，需要信心度≥%d（当前%d）", required, d.Confidence)
+	}
+	return note + fmt.Sprintf("，信心度≥%d，允许执行", required), nil
+}
+
+func (c *contradictionChecker) appendLog(record contradictionRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(c.logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("⚠️  写入决策矛盾记录失败: %v", err)
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
@@ -0,0 +1,150 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// controlState 外部控制请求（gRPC等）与主循环共享的状态
+// 风控参数覆盖先暂存，在下一个决策周期开始时由主循环应用，避免与执行中的周期并发修改配置
+type controlState struct {
+	mu               sync.Mutex
+	paused           bool
+	pausedUntil      time.Time // paused且为零值时表示无限期暂停
+	pendingRisk      *RiskOverride
+	pendingProfile   string                  // 🎛️ 待切换的策略档案
+	pendingPositions []positionCommand       // 🙋 待执行的持仓人工指令
+	positionResults  []positionCommandResult // 最近执行的持仓人工指令
+	stopCh           chan struct{}           // Stop时关闭，唤醒等待中的主循环
+	loopActive       bool                    // 主循环是否仍在运行（Stop后到循环真正退出之间为true）
+}
+
+// RiskOverride 运行时风控参数覆盖（nil字段表示不修改）
+type RiskOverride struct {
+	MaxDailyLoss       *float64
+	MaxDrawdown        *float64
+	MaxRiskPerTradeUSD *float64
+	StopTradingTime    *time.Duration
+}
+
+// IsRunning 主循环是否处于运行状态
+func (at *AutoTrader) IsRunning() bool {
+	return at.isRunning
+}
+
+// LoopActive 主循环goroutine是否仍未退出（用于判断能否重新启动）
+func (at *AutoTrader) LoopActive() bool {
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+	return at.control.loopActive
+}
+
+// Pause 暂停决策周期（d<=0表示无限期暂停，直到Resume）
+// 暂停期间主循环继续运行但跳过决策，交易所上的止损止盈单不受影响
+func (at *AutoTrader) Pause(d time.Duration) {
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+
+	at.control.paused = true
+	if d > 0 {
+		at.control.pausedUntil = time.Now().Add(d)
+		log.Printf("⏸  [%s] 已暂停决策周期，%s后自动恢复", at.name, d)
+	} else {
+		at.control.pausedUntil = time.Time{}
+		log.Printf("⏸  [%s] 已暂停决策周期（直到手动恢复）", at.name)
+	}
+}
+
+// Resume 解除暂停
+func (at *AutoTrader) Resume() {
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+
+	at.control.paused = false
+	at.control.pausedUntil = time.Time{}
+	log.Printf("▶️  [%s] 已恢复决策周期", at.name)
+}
+
+// PauseState 当前暂停状态（paused=false时until无意义；until为零值表示无限期）
+func (at *AutoTrader) PauseState() (paused bool, until time.Time) {
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+
+	if at.control.paused && !at.control.pausedUntil.IsZero() && time.Now().After(at.control.pausedUntil) {
+		at.control.paused = false
+		at.control.pausedUntil = time.Time{}
+	}
+	return at.control.paused, at.control.pausedUntil
+}
+
+// OverrideRisk 提交风控参数覆盖，下一个决策周期开始时生效
+func (at *AutoTrader) OverrideRisk(o RiskOverride) error {
+	for name, v := range map[string]*float64{
+		"max_daily_loss":         o.MaxDailyLoss,
+		"max_drawdown":           o.MaxDrawdown,
+		"max_risk_per_trade_usd": o.MaxRiskPerTradeUSD,
+	} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("%s不能为负数", name)
+		}
+	}
+	if o.StopTradingTime != nil && *o.StopTradingTime < 0 {
+		return fmt.Errorf("stop_trading_time不能为负数")
+	}
+
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+
+	// 合并尚未应用的覆盖
+	if pending := at.control.pendingRisk; pending != nil {
+		if o.MaxDailyLoss == nil {
+			o.MaxDailyLoss = pending.MaxDailyLoss
+		}
+		if o.MaxDrawdown == nil {
+			o.MaxDrawdown = pending.MaxDrawdown
+		}
+		if o.MaxRiskPerTradeUSD == nil {
+			o.MaxRiskPerTradeUSD = pending.MaxRiskPerTradeUSD
+		}
+		if o.StopTradingTime == nil {
+			o.StopTradingTime = pending.StopTradingTime
+		}
+	}
+	at.control.pendingRisk = &o
+	return nil
+}
+
+// applyPendingControl 应用暂存的风控参数覆盖（在决策周期开始时调用）
+func (at *AutoTrader) applyPendingControl() {
+	at.control.mu.Lock()
+	o := at.control.pendingRisk
+	at.control.pendingRisk = nil
+	at.control.mu.Unlock()
+
+	if o == nil {
+		return
+	}
+	if o.MaxDailyLoss != nil {
+		log.Printf("🎛️  风控覆盖: 最大日亏损 %.2f%% → %.2f%%", at.config.MaxDailyLoss, *o.MaxDailyLoss)
+		at.config.MaxDailyLoss = *o.MaxDailyLoss
+	}
+	if o.MaxDrawdown != nil {
+		log.Printf("🎛️  风控覆盖: 最大回撤 %.2f%% → %.2f%%", at.config.MaxDrawdown, *o.MaxDrawdown)
+		at.config.MaxDrawdown = *o.MaxDrawdown
+	}
+	if o.MaxRiskPerTradeUSD != nil {
+		log.Printf("🎛️  风控覆盖: 单笔最大风险 %.2f → %.2f USDT", at.config.MaxRiskPerTradeUSD, *o.MaxRiskPerTradeUSD)
+		at.config.MaxRiskPerTradeUSD = *o.MaxRiskPerTradeUSD
+	}
+	if o.StopTradingTime != nil {
+		log.Printf("🎛️  风控覆盖: 触发风控后暂停 %v → %v", at.config.StopTradingTime, *o.StopTradingTime)
+		at.config.StopTradingTime = *o.StopTradingTime
+	}
+}
+
+// GetConfig 获取当前配置（只读副本）
+func (at *AutoTrader) GetConfig() AutoTraderConfig {
+	return at.config
+}
@@ -0,0 +1,105 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"time"
+)
+
+// SetCooldownOverridePolicy 设置冷却期豁免策略（每日次数上限、最低信心度）
+func (tc *TradingConstraints) SetCooldownOverridePolicy(maxPerDay, minConfidence int) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.maxCooldownOverrides = maxPerDay
+	if minConfidence > 0 {
+		tc.minOverrideConfidence = minConfidence
+	}
+}
+
+// CanOverrideCooldown 检查是否允许豁免冷却期开仓：当日豁免次数未用完、信心度达标，且其余硬约束全部通过
+func (tc *TradingConstraints) CanOverrideCooldown(symbol string, currentPositionCount int, confidence int) error {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	if tc.maxCooldownOverrides <= 0 {
+		return fmt.Errorf("未启用冷却期豁免")
+	}
+	used := tc.overrideCount
+	if time.Since(tc.overrideResetTime) >= 24*time.Hour {
+		used = 0
+	}
+	if used >= tc.maxCooldownOverrides {
+		return fmt.Errorf("今日冷却期豁免已用完（%d/%d）", used, tc.maxCooldownOverrides)
+	}
+	if confidence < tc.minOverrideConfidence {
+		return fmt.Errorf("信心度%d低于豁免要求%d", confidence, tc.minOverrideConfidence)
+	}
+	return tc.canOpen(symbol, currentPositionCount, false)
+}
+
+// RecordCooldownOverride 记录一次冷却期豁免（开仓成功后调用）
+func (tc *TradingConstraints) RecordCooldownOverride(symbol string, confidence int) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if time.Since(tc.overrideResetTime) >= 24*time.Hour {
+		tc.overrideCount = 0
+		tc.overrideResetTime = time.Now()
+	}
+	tc.overrideCount++
+	log.Printf("🎟️ 冷却期豁免已使用: %s 信心度%d（今日%d/%d）", symbol, confidence, tc.overrideCount, tc.maxCooldownOverrides)
+}
+
+// checkOpenConstraints 开仓硬约束检查；冷却期拦截且AI标记 override_cooldown 时尝试豁免
+// 豁免成功会写入执行记录，开仓成功后再由 consumeCooldownOverride 扣减当日次数
+func (at *AutoTrader) checkOpenConstraints(d *decision.Decision, positionCount int, actionRecord *logger.DecisionAction) error {
+	// 🚧 币种处于SETTLING/BREAK等非交易状态时下单必然失败
+	if err := at.checkSymbolTradable(d.Symbol); err != nil {
+		return err
+	}
+
+	// 🔒 提现/划转锁：确认前不开新仓
+	if err := at.checkWithdrawalLock(); err != nil {
+		return err
+	}
+
+	// 🌙 定时空仓窗口内不开新仓
+	if err := at.checkFlatWindow(); err != nil {
+		return err
+	}
+
+	// ⏰ 开仓时机保护（资金费结算/宏观事件）不可豁免
+	if err := at.checkEntryTiming(d); err != nil {
+		return err
+	}
+
+	// 🚦 过度交易保护：近期期望为负时只接受高信心度开仓
+	if err := at.checkOvertradingGuard(d); err != nil {
+		return err
+	}
+
+	err := at.constraints.CanOpenPosition(d.Symbol, positionCount)
+	if err == nil || !d.OverrideCooldown || !errors.Is(err, ErrCooldown) {
+		return err
+	}
+
+	if overrideErr := at.constraints.CanOverrideCooldown(d.Symbol, positionCount, d.Confidence); overrideErr != nil {
+		log.Printf("  🎟️ %s 请求冷却期豁免被拒绝: %v", d.Symbol, overrideErr)
+		return fmt.Errorf("%w（豁免被拒绝: %v）", err, overrideErr)
+	}
+
+	log.Printf("  🎟️ %s 冷却期豁免通过（信心度%d）: %s", d.Symbol, d.Confidence, d.Reasoning)
+	actionRecord.CooldownOverride = true
+	return nil
+}
+
+// consumeCooldownOverride 开仓成功后扣减冷却期豁免次数
+func (at *AutoTrader) consumeCooldownOverride(d *decision.Decision, actionRecord *logger.DecisionAction) {
+	if actionRecord.CooldownOverride {
+		at.constraints.RecordCooldownOverride(d.Symbol, d.Confidence)
+	}
+}
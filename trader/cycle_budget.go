@@ -0,0 +1,17 @@
+package trader
+
+import "time"
+
+// defaultCycleBudgetRatio 默认周期时间预算占扫描间隔的比例（留出执行下单的时间，避免周期重叠）
+const defaultCycleBudgetRatio = 0.8
+
+// cycleBudget 本周期AI决策的时间预算（0=不限制）
+func (at *AutoTrader) cycleBudget() time.Duration {
+	switch {
+	case at.config.CycleBudget < 0:
+		return 0
+	case at.config.CycleBudget > 0:
+		return at.config.CycleBudget
+	}
+	return time.Duration(float64(at.currentScanInterval()) * defaultCycleBudgetRatio)
+}
@@ -0,0 +1,177 @@
+package trader
+
+import (
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"sync"
+	"time"
+)
+
+// 决策周期各阶段（并发执行的阶段各自计时，total为整个周期的实际耗时）
+const (
+	stagePrepare     = "prepare"     // 限价单/盈亏对账/账户异常/分批止盈跟踪
+	stageBalance     = "balance"     // 获取账户余额
+	stagePositions   = "positions"   // 获取持仓
+	stageCandidates  = "candidates"  // 候选币种池
+	stageMarketData  = "market_data" // 候选币种市场数据并发获取
+	stagePerformance = "performance" // 历史表现分析（与市场数据并发）
+	stageAI          = "ai"          // AI决策
+	stageExecution   = "execution"   // 执行决策（含重试）
+	stageShadow      = "shadow"      // 影子引擎
+	stageTotal       = "total"
+
+	maxCycleTimingSamples = 100 // 状态API统计的最近周期数
+	slowCycleRatio        = 0.5 // 周期耗时超过扫描间隔的该比例时告警
+)
+
+// cycleTimer ⏱️ 单个决策周期的阶段耗时
+type cycleTimer struct {
+	mu     sync.Mutex
+	start  time.Time
+	stages map[string]time.Duration
+}
+
+func newCycleTimer() *cycleTimer {
+	return &cycleTimer{start: time.Now(), stages: make(map[string]time.Duration)}
+}
+
+// track 开始计时，返回的函数结束计时（用法: defer timer.track(stageAI)()）
+func (t *cycleTimer) track(stage string) func() {
+	start := time.Now()
+	return func() {
+		t.mu.Lock()
+		t.stages[stage] += time.Since(start)
+		t.mu.Unlock()
+	}
+}
+
+// millis 各阶段耗时（毫秒，含截至目前的total）
+func (t *cycleTimer) millis() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make(map[string]int64, len(t.stages)+1)
+	for stage, d := range t.stages {
+		result[stage] = d.Milliseconds()
+	}
+	result[stageTotal] = time.Since(t.start).Milliseconds()
+	return result
+}
+
+// cycleTimingStats 最近周期的阶段耗时（状态API）
+type cycleTimingStats struct {
+	mu      sync.Mutex
+	samples []map[string]int64
+	slow    int // 超过扫描间隔一半的周期数
+}
+
+// logCycle 写入阶段耗时后保存决策记录，并计入耗时统计
+func (at *AutoTrader) logCycle(record *logger.DecisionRecord, timer *cycleTimer) error {
+	timings := timer.millis()
+	record.StageTimings = timings
+	at.recordCycleTiming(timings)
+	return at.decisionLogger.LogDecision(record)
+}
+
+func (at *AutoTrader) recordCycleTiming(timings map[string]int64) {
+	interval := at.currentScanInterval()
+	total := time.Duration(timings[stageTotal]) * time.Millisecond
+	slow := interval > 0 && total > time.Duration(float64(interval)*slowCycleRatio)
+
+	s := &at.cycleTimings
+	s.mu.Lock()
+	s.samples = append(s.samples, timings)
+	if len(s.samples) > maxCycleTimingSamples {
+		s.samples = s.samples[len(s.samples)-maxCycleTimingSamples:]
+	}
+	if slow {
+		s.slow++
+	}
+	s.mu.Unlock()
+
+	if slow {
+		stage, ms := slowestStage(timings)
+		log.Printf("⏱️  [%s] 周期耗时%v，超过扫描间隔%v的%.0f%%（最慢阶段: %s %dms）",
+			at.name, total, interval, slowCycleRatio*100, stage, ms)
+	}
+}
+
+// slowestStage 耗时最长的阶段（不含total）
+func slowestStage(timings map[string]int64) (string, int64) {
+	var stage string
+	var max int64 = -1
+	for name, ms := range timings {
+		if name != stageTotal && (ms > max || (ms == max && name < stage)) {
+			stage, max = name, ms
+		}
+	}
+	return stage, max
+}
+
+// loadDecisionInputs 并发获取候选币种市场数据和分析历史表现（两者互不依赖）
+func (at *AutoTrader) loadDecisionInputs(ctx *decision.Context, timer *cycleTimer) error {
+	var wg sync.WaitGroup
+	var performance *logger.PerformanceAnalysis
+	var perfErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer timer.track(stagePerformance)()
+		// 默认最近100个周期，避免长期持仓的交易记录丢失
+		performance, perfErr = at.decisionLogger.AnalyzePerformance(at.PerformanceWindow())
+	}()
+
+	done := timer.track(stageMarketData)
+	err := decision.FetchMarketData(ctx)
+	done()
+	wg.Wait()
+
+	if perfErr != nil {
+		log.Printf("⚠️  分析历史表现失败: %v", perfErr)
+		// 不影响主流程，继续执行（但performance为nil以避免传递错误数据）
+	} else {
+		ctx.Performance = performance
+		at.lastPerformance = performance
+		at.updateOvertradingGuard(performance) // 🚦 按近期期望收益调整开仓门槛
+	}
+	return err
+}
+
+// getCycleTimingStatus 最近周期各阶段的平均/最大耗时（状态API）
+func (at *AutoTrader) getCycleTimingStatus() map[string]interface{} {
+	s := &at.cycleTimings
+	s.mu.Lock()
+	samples := append([]map[string]int64(nil), s.samples...)
+	slow := s.slow
+	s.mu.Unlock()
+
+	status := map[string]interface{}{
+		"samples":               len(samples),
+		"slow_cycles":           slow,
+		"scan_interval_seconds": at.currentScanInterval().Seconds(),
+	}
+	if len(samples) == 0 {
+		return status
+	}
+
+	sums := make(map[string]int64)
+	maxes := make(map[string]int64)
+	counts := make(map[string]int64)
+	for _, timings := range samples {
+		for stage, ms := range timings {
+			sums[stage] += ms
+			counts[stage]++
+			if ms > maxes[stage] {
+				maxes[stage] = ms
+			}
+		}
+	}
+	avg := make(map[string]int64, len(sums))
+	for stage, sum := range sums {
+		avg[stage] = sum / counts[stage]
+	}
+	status["last_ms"] = samples[len(samples)-1]
+	status["avg_ms"] = avg
+	status["max_ms"] = maxes
+	return status
+}
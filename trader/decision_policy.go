@@ -0,0 +1,170 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/market"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🚦 决策策略中间件：AI决策与执行之间的规则链，可以否决或修改决策（如"永不做空BTC"、"周末山寨币杠杆≤3x"）
+// 两种注册方式：Go代码调用 RegisterDecisionPolicy（所有trader生效），或配置 decision_policies 规则（单个trader生效）
+
+// PolicyFunc 决策策略：可直接修改决策（如降低杠杆），note为修改说明；veto=true时否决执行，note为否决原因
+type PolicyFunc func(d *decision.Decision, now time.Time) (note string, veto bool)
+
+// DecisionPolicy 命名的决策策略
+type DecisionPolicy struct {
+	Name  string
+	Apply PolicyFunc
+}
+
+var (
+	globalPoliciesMu sync.RWMutex
+	globalPolicies   []DecisionPolicy
+)
+
+// RegisterDecisionPolicy 注册对所有trader生效的决策策略（需在trader启动前调用）
+func RegisterDecisionPolicy(p DecisionPolicy) {
+	globalPoliciesMu.Lock()
+	defer globalPoliciesMu.Unlock()
+	globalPolicies = append(globalPolicies, p)
+}
+
+// PolicyRule 配置化的决策规则（所有条件同时满足时生效）
+type PolicyRule struct {
+	Name           string
+	Symbols        []string // 适用币种（BTCUSDT或BTC，空=全部）
+	Scope          string   // "major"=仅BTC/ETH，"alt"=仅山寨币，空=全部
+	Actions        []string // 适用动作（open_long/open_short/close_long/close_short，空=全部开仓动作）
+	Weekdays       []string // 适用星期（UTC，mon..sun，空=每天）
+	Veto           bool     // 命中即否决
+	MaxLeverage    int      // 杠杆上限（0=不限制）
+	MaxPositionUSD float64  // 仓位上限（0=不限制）
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// effectiveAction 换仓按新开仓方向匹配规则
+func effectiveAction(d *decision.Decision) string {
+	if d.Action == "replace_position" {
+		return "open_" + d.Side
+	}
+	return d.Action
+}
+
+func (r PolicyRule) matches(d *decision.Decision, now time.Time) bool {
+	action := effectiveAction(d)
+	if len(r.Actions) == 0 {
+		if action != "open_long" && action != "open_short" {
+			return false
+		}
+	} else if !containsFold(r.Actions, action) {
+		return false
+	}
+	// 币种可写完整交易对（BTCUSDT）或币名（BTC）
+	if len(r.Symbols) > 0 && !containsFold(r.Symbols, d.Symbol) && !containsFold(r.Symbols, market.BaseAsset(d.Symbol)) {
+		return false
+	}
+	switch r.Scope {
+	case "major":
+		if base := market.BaseAsset(d.Symbol); base != "BTC" && base != "ETH" {
+			return false
+		}
+	case "alt":
+		if base := market.BaseAsset(d.Symbol); base == "BTC" || base == "ETH" {
+			return false
+		}
+	}
+	if len(r.Weekdays) > 0 {
+		today := now.UTC().Weekday()
+		hit := false
+		for _, name := range r.Weekdays {
+			if weekdayNames[strings.ToLower(name)] == today {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	return true
+}
+
+// Policy 把配置规则转换为决策策略
+func (r PolicyRule) Policy() DecisionPolicy {
+	return DecisionPolicy{
+		Name: r.Name,
+		Apply: func(d *decision.Decision, now time.Time) (string, bool) {
+			if !r.matches(d, now) {
+				return "", false
+			}
+			if r.Veto {
+				return fmt.Sprintf("%s %s 被规则禁止", d.Symbol, effectiveAction(d)), true
+			}
+
+			var notes []string
+			if r.MaxLeverage > 0 && d.Leverage > r.MaxLeverage {
+				notes = append(notes, fmt.Sprintf("杠杆%dx→%dx", d.Leverage, r.MaxLeverage))
+				d.Leverage = r.MaxLeverage
+			}
+			if r.MaxPositionUSD > 0 && d.PositionSizeUSD > r.MaxPositionUSD {
+				notes = append(notes, fmt.Sprintf("仓位%.0f→%.0f USDT", d.PositionSizeUSD, r.MaxPositionUSD))
+				d.PositionSizeUSD = r.MaxPositionUSD
+			}
+			return strings.Join(notes, "，"), false
+		},
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDecisionPolicies 依次执行全局策略和本trader的规则，返回修改/否决说明；被否决时返回错误
+func (at *AutoTrader) applyDecisionPolicies(d *decision.Decision) ([]string, error) {
+	if d.Action == "hold" || d.Action == "wait" {
+		return nil, nil
+	}
+
+	globalPoliciesMu.RLock()
+	chain := append(append([]DecisionPolicy(nil), globalPolicies...), at.policies...)
+	globalPoliciesMu.RUnlock()
+
+	now := time.Now()
+	var notes []string
+	for _, p := range chain {
+		note, veto := p.Apply(d, now)
+		if veto {
+			log.Printf("  🚫 [%s] 策略[%s]否决 %s %s: %s", at.name, p.Name, d.Symbol, d.Action, note)
+			notes = append(notes, fmt.Sprintf("[%s] 否决: %s", p.Name, note))
+			return notes, fmt.Errorf("策略否决[%s]: %s", p.Name, note)
+		}
+		if note != "" {
+			log.Printf("  🚦 [%s] 策略[%s]修改 %s %s: %s", at.name, p.Name, d.Symbol, d.Action, note)
+			notes = append(notes, fmt.Sprintf("[%s] %s", p.Name, note))
+		}
+	}
+	return notes, nil
+}
+
+// policyRulesFromConfig 构建配置规则链
+func policyRulesFromConfig(rules []PolicyRule) []DecisionPolicy {
+	policies := make([]DecisionPolicy, 0, len(rules))
+	for _, r := range rules {
+		policies = append(policies, r.Policy())
+	}
+	return policies
+}
@@ -0,0 +1,61 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/decision/agents"
+	"nofx/mcp"
+	"time"
+)
+
+// EnsembleModelConfig 集成预测中单个模型的配置
+type EnsembleModelConfig struct {
+	Name     string // 模型名称（用于投票记录和准确率统计，默认取provider）
+	Provider string // "deepseek", "qwen" 或 "custom"
+	APIKey   string
+	APIURL   string // custom时必填
+	Model    string // 模型名（qwen/custom可选）
+}
+
+// newEnsemble 按配置创建集成预测模型（少于2个模型时返回nil，使用单模型）
+func newEnsemble(models []EnsembleModelConfig, mode string, timeout time.Duration) (*agents.Ensemble, error) {
+	if len(models) < 2 {
+		return nil, nil
+	}
+	if mode == "" {
+		mode = agents.EnsembleMajority
+	}
+
+	ensemble := &agents.Ensemble{Mode: mode}
+	seen := make(map[string]bool)
+	for i, m := range models {
+		client := mcp.New()
+		switch m.Provider {
+		case "deepseek":
+			client.SetDeepSeekAPIKey(m.APIKey)
+		case "qwen":
+			client.SetQwenAPIKey(m.APIKey, "")
+		case "custom":
+			client.SetCustomAPI(m.APIURL, m.APIKey, m.Model)
+		default:
+			return nil, fmt.Errorf("ensemble_models[%d]: 不支持的provider '%s'", i, m.Provider)
+		}
+		if m.Model != "" {
+			client.Model = m.Model
+		}
+		if timeout > 0 {
+			client.Timeout = timeout
+		}
+
+		name := m.Name
+		if name == "" {
+			name = m.Provider
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("ensemble_models[%d]: 模型名称'%s'重复，请设置不同的name", i, name)
+		}
+		seen[name] = true
+
+		ensemble.Members = append(ensemble.Members, agents.EnsembleMember{Name: name, Client: client})
+	}
+	return ensemble, nil
+}
@@ -0,0 +1,135 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/market"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFundingGuardRatePct = 0.05             // 默认极端资金费率阈值（0.05%/期）
+	defaultEventGuardWindow    = 30 * time.Minute // 默认宏观事件前后禁止开仓时长
+)
+
+// EntryTimingConfig ⏰ 开仓时机保护：资金费结算前的极端费率、宏观事件（CPI/FOMC）前后
+type EntryTimingConfig struct {
+	FundingWindow     time.Duration // 资金费结算前多久内检查（0=禁用）
+	FundingExtremePct float64       // 资金费率绝对值≥此值（%）且对开仓方向不利时禁止开仓（0=默认0.05）
+	EventCalendarFile string        // 宏观事件日历文件（JSON，空=禁用）
+	EventWindow       time.Duration // 事件前后多久内禁止开仓（0=默认30分钟）
+}
+
+// MacroEvent 宏观事件（日历文件格式：[{"name": "CPI", "time": "2026-11-12T13:30:00Z"}]）
+type MacroEvent struct {
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+}
+
+// eventCalendar 宏观事件日历（文件修改后自动重新加载）
+type eventCalendar struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	events  []MacroEvent
+}
+
+func newEventCalendar(path string) *eventCalendar {
+	if path == "" {
+		return nil
+	}
+	c := &eventCalendar{path: path}
+	if err := c.reload(); err != nil {
+		log.Printf("⚠️  加载宏观事件日历失败: %v", err)
+	} else {
+		log.Printf("📅 已加载宏观事件日历: %d个事件 (%s)", len(c.events), path)
+	}
+	return c
+}
+
+// reload 文件有变化时重新加载（调用方持有锁或在初始化中）
+func (c *eventCalendar) reload() error {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(c.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+	var events []MacroEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return fmt.Errorf("解析事件日历失败: %w", err)
+	}
+	c.events = events
+	c.modTime = info.ModTime()
+	return nil
+}
+
+// active 返回now前后window内的事件
+func (c *eventCalendar) active(now time.Time, window time.Duration) (MacroEvent, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.reload(); err != nil {
+		log.Printf("⚠️  重新加载宏观事件日历失败，沿用旧数据: %v", err)
+	}
+	for _, e := range c.events {
+		if now.After(e.Time.Add(-window)) && now.Before(e.Time.Add(window)) {
+			return e, true
+		}
+	}
+	return MacroEvent{}, false
+}
+
+// checkEntryTiming 开仓时机检查：宏观事件窗口内、或临近资金费结算且费率对开仓方向极端不利时拒绝开仓
+func (at *AutoTrader) checkEntryTiming(d *decision.Decision) error {
+	cfg := at.config.EntryTiming
+	now := time.Now()
+
+	if at.calendar != nil {
+		window := cfg.EventWindow
+		if window <= 0 {
+			window = defaultEventGuardWindow
+		}
+		if e, ok := at.calendar.active(now, window); ok {
+			return fmt.Errorf("⏰ 宏观事件%s(%s)前后%v内禁止开仓", e.Name, e.Time.Format("01-02 15:04 UTC"), window)
+		}
+	}
+
+	if cfg.FundingWindow <= 0 {
+		return nil
+	}
+	schedule, err := market.GetFundingSchedule(d.Symbol)
+	if err != nil {
+		log.Printf("  ⚠️ 获取%s资金费结算时间失败，跳过资金费检查: %v", d.Symbol, err)
+		return nil
+	}
+	untilFunding := schedule.NextFunding.Sub(now)
+	if untilFunding < 0 || untilFunding > cfg.FundingWindow {
+		return nil
+	}
+
+	extreme := cfg.FundingExtremePct
+	if extreme <= 0 {
+		extreme = defaultFundingGuardRatePct
+	}
+	ratePct := schedule.Rate * 100
+	side := "long"
+	if d.Action == "open_short" || (d.Action == "replace_position" && d.Side == "short") {
+		side = "short"
+	}
+	// 正费率多头支付，负费率空头支付
+	if (side == "long" && ratePct >= extreme) || (side == "short" && ratePct <= -extreme) {
+		return fmt.Errorf("⏰ 距资金费结算%.0f分钟，费率%+.4f%%对%s极端不利，暂不开仓", untilFunding.Minutes(), ratePct, side)
+	}
+	return nil
+}
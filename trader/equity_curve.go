@@ -0,0 +1,234 @@
+package trader
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const equityCurveRetention = 90 * 24 * time.Hour // 净值曲线保留90天
+
+// EquitySample 单次净值采样
+type EquitySample struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+// EquityStats 📈 基于净值曲线计算的风险指标（重启后不丢失）
+type EquityStats struct {
+	Equity          float64   `json:"equity"`
+	PeakEquity      float64   `json:"peak_equity"`       // 保留期内的净值高点
+	DrawdownPct     float64   `json:"drawdown_pct"`      // 当前相对高点的回撤
+	MaxDrawdownPct  float64   `json:"max_drawdown_pct"`  // 保留期内的最大回撤
+	DailyReturnPct  float64   `json:"daily_return_pct"`  // 相对UTC当日开盘净值
+	WeeklyReturnPct float64   `json:"weekly_return_pct"` // 相对7天前净值
+	Sharpe          float64   `json:"sharpe"`            // 按日收益年化的夏普比率（不足2天为0）
+	Samples         int       `json:"samples"`
+	Since           time.Time `json:"since"`
+}
+
+// EquityCurve 📈 净值曲线：每个周期记录一次净值到磁盘，供风控计算日收益和回撤
+type EquityCurve struct {
+	mu      sync.RWMutex
+	path    string
+	samples []EquitySample
+}
+
+// NewEquityCurve 从 <dir>/equity_curve.jsonl 加载净值曲线（超过保留期的采样会被清理）
+func NewEquityCurve(dir string) *EquityCurve {
+	ec := &EquityCurve{path: filepath.Join(dir, "equity_curve.jsonl")}
+
+	file, err := os.Open(ec.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️  加载净值曲线失败: %v", err)
+		}
+		return ec
+	}
+	defer file.Close()
+
+	cutoff := time.Now().Add(-equityCurveRetention)
+	expired := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var s EquitySample
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			continue
+		}
+		if s.Time.Before(cutoff) {
+			expired++
+			continue
+		}
+		ec.samples = append(ec.samples, s)
+	}
+	sort.Slice(ec.samples, func(i, j int) bool { return ec.samples[i].Time.Before(ec.samples[j].Time) })
+
+	if expired > 0 {
+		if err := ec.rewrite(); err != nil {
+			log.Printf("⚠️  清理过期净值采样失败: %v", err)
+		}
+	}
+	if len(ec.samples) > 0 {
+		log.Printf("📈 已加载净值曲线: %d个采样（自%s）", len(ec.samples), ec.samples[0].Time.Format("2006-01-02 15:04"))
+	}
+	return ec
+}
+
+// Record 记录一次净值采样并追加写入磁盘
+func (ec *EquityCurve) Record(equity float64, now time.Time) {
+	if equity <= 0 {
+		return
+	}
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+
+	sample := EquitySample{Time: now, Equity: equity}
+	ec.samples = append(ec.samples, sample)
+
+	cutoff := now.Add(-equityCurveRetention)
+	drop := 0
+	for drop < len(ec.samples) && ec.samples[drop].Time.Before(cutoff) {
+		drop++
+	}
+	ec.samples = ec.samples[drop:]
+
+	if err := ec.appendSample(sample); err != nil {
+		log.Printf("⚠️  写入净值曲线失败: %v", err)
+	}
+}
+
+func (ec *EquityCurve) appendSample(s EquitySample) error {
+	if err := os.MkdirAll(filepath.Dir(ec.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(ec.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// rewrite 用内存中的采样重写文件（原子替换）
+func (ec *EquityCurve) rewrite() error {
+	tmpFile := ec.path + ".tmp"
+	file, err := os.Create(tmpFile)
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	w := bufio.NewWriter(file)
+	for _, s := range ec.samples {
+		data, _ := json.Marshal(s)
+		w.Write(append(data, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	file.Close()
+	return os.Rename(tmpFile, ec.path)
+}
+
+// Samples 返回since之后的采样
+func (ec *EquityCurve) Samples(since time.Time) []EquitySample {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	i := sort.Search(len(ec.samples), func(i int) bool { return !ec.samples[i].Time.Before(since) })
+	return append([]EquitySample(nil), ec.samples[i:]...)
+}
+
+// Stats 计算当前风险指标
+func (ec *EquityCurve) Stats(now time.Time) EquityStats {
+	ec.mu.RLock()
+	defer ec.mu.RUnlock()
+
+	var stats EquityStats
+	if len(ec.samples) == 0 {
+		return stats
+	}
+	stats.Samples = len(ec.samples)
+	stats.Since = ec.samples[0].Time
+	stats.Equity = ec.samples[len(ec.samples)-1].Equity
+
+	// 高点与最大回撤
+	for _, s := range ec.samples {
+		if s.Equity > stats.PeakEquity {
+			stats.PeakEquity = s.Equity
+		}
+		if dd := (stats.PeakEquity - s.Equity) / stats.PeakEquity * 100; dd > stats.MaxDrawdownPct {
+			stats.MaxDrawdownPct = dd
+		}
+	}
+	stats.DrawdownPct = (stats.PeakEquity - stats.Equity) / stats.PeakEquity * 100
+
+	// 日/周收益：以基准时间点之前最后一个采样为基准（没有则用最早的采样）
+	dayStart := now.UTC().Truncate(24 * time.Hour)
+	if base := ec.equityAt(dayStart); base > 0 {
+		stats.DailyReturnPct = (stats.Equity - base) / base * 100
+	}
+	if base := ec.equityAt(now.Add(-7 * 24 * time.Hour)); base > 0 {
+		stats.WeeklyReturnPct = (stats.Equity - base) / base * 100
+	}
+
+	stats.Sharpe = ec.dailySharpe()
+	return stats
+}
+
+// equityAt t时刻的净值（t之前最后一个采样，t早于全部采样时返回第一个采样）
+func (ec *EquityCurve) equityAt(t time.Time) float64 {
+	i := sort.Search(len(ec.samples), func(i int) bool { return ec.samples[i].Time.After(t) })
+	if i == 0 {
+		return ec.samples[0].Equity
+	}
+	return ec.samples[i-1].Equity
+}
+
+// dailySharpe 按UTC日收盘净值计算日收益，年化夏普比率（无风险利率按0）
+func (ec *EquityCurve) dailySharpe() float64 {
+	var closes []float64
+	var lastDay time.Time
+	for _, s := range ec.samples {
+		day := s.Time.UTC().Truncate(24 * time.Hour)
+		if len(closes) > 0 && day.Equal(lastDay) {
+			closes[len(closes)-1] = s.Equity
+			continue
+		}
+		closes = append(closes, s.Equity)
+		lastDay = day
+	}
+	if len(closes) < 3 {
+		return 0
+	}
+
+	returns := make([]float64, 0, len(closes)-1)
+	mean := 0.0
+	for i := 1; i < len(closes); i++ {
+		r := (closes[i] - closes[i-1]) / closes[i-1]
+		returns = append(returns, r)
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+	return mean / std * math.Sqrt(365)
+}
@@ -0,0 +1,155 @@
+package trader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nofx/errs"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+// binanceErrorKinds 币安合约错误码 → 错误类别（Aster 沿用币安错误码）
+var binanceErrorKinds = map[int64]errs.Kind{
+	-1003: errs.RateLimited,        // 请求过多
+	-1015: errs.RateLimited,        // 下单过于频繁
+	-1001: errs.Transient,          // 内部连接断开
+	-1006: errs.Transient,          // 异常响应，执行状态未知
+	-1007: errs.Transient,          // 后端超时，执行状态未知
+	-1008: errs.Transient,          // 服务器繁忙
+	-1021: errs.ClockSkew,          // 时间戳超出recvWindow
+	-1013: errs.PrecisionError,     // 不满足过滤器（最小数量/名义价值）
+	-1111: errs.PrecisionError,     // 精度超出允许范围
+	-4003: errs.PrecisionError,     // 数量小于等于0
+	-4014: errs.PrecisionError,     // 价格不是tickSize的整数倍
+	-4023: errs.PrecisionError,     // 数量不是stepSize的整数倍
+	-4164: errs.PrecisionError,     // 名义价值低于最小值
+	-1121: errs.InvalidSymbol,      // 交易对不存在
+	-1122: errs.InvalidSymbol,      // 交易对状态不可交易
+	-4140: errs.InvalidSymbol,      // 交易对状态不允许开仓
+	-2018: errs.InsufficientMargin, // 余额不足
+	-2019: errs.InsufficientMargin, // 保证金不足
+	-2011: errs.OrderNotFound,      // 撤单失败（订单不存在）
+	-2013: errs.OrderNotFound,      // 订单不存在
+	-4046: errs.NoChange,           // 保证金模式无需修改
+	-4059: errs.NoChange,           // 持仓模式无需修改
+}
+
+// binanceCodeMultiAssets 多资产模式下不能切换逐仓
+const binanceCodeMultiAssets = -4168
+
+// classifyBinanceError 按币安错误码给错误标注类别（无错误码时识别网络错误）
+func classifyBinanceError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *common.APIError
+	if errors.As(err, &apiErr) {
+		if !apiErr.IsValid() {
+			// 网关返回的非JSON响应（维护页面或5xx）
+			if strings.Contains(strings.ToLower(string(apiErr.Response)), "maintenance") {
+				return errs.Wrap(errs.Maintenance, 0, err)
+			}
+			return errs.Wrap(errs.Transient, 0, err)
+		}
+		return errs.Wrap(binanceErrorKinds[apiErr.Code], apiErr.Code, err)
+	}
+	return errs.Wrap(errs.Network(err), 0, err)
+}
+
+// errorKind 错误类别名称（写入决策日志，未分类返回空）
+func errorKind(err error) string {
+	if kind := errs.KindOf(err); kind != errs.Unknown {
+		return kind.String()
+	}
+	return ""
+}
+
+// asterAPIError Aster 接口错误响应体（与币安格式相同）
+type asterAPIError struct {
+	Code int64  `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// classifyAsterResponse 非200响应转为带类别的错误（优先按错误码，否则按HTTP状态码）
+func classifyAsterResponse(status int, body []byte) error {
+	err := fmt.Errorf("HTTP %d: %s", status, string(body))
+	var apiErr asterAPIError
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Code != 0 {
+		if kind, ok := binanceErrorKinds[apiErr.Code]; ok {
+			return errs.Wrap(kind, apiErr.Code, err)
+		}
+		return &errs.Error{Kind: errs.HTTPStatus(status), Code: apiErr.Code, Err: err}
+	}
+	return errs.Wrap(errs.HTTPStatus(status), 0, err)
+}
+
+// hyperliquidErrorKinds Hyperliquid 不返回错误码，按拒单原因映射（小写匹配）
+var hyperliquidErrorKinds = []struct {
+	pattern string
+	kind    errs.Kind
+}{
+	{"too many requests", errs.RateLimited},
+	{"rate limit", errs.RateLimited},
+	{"insufficient margin", errs.InsufficientMargin},
+	{"insufficient balance", errs.InsufficientMargin},
+	{"invalid size", errs.PrecisionError},
+	{"invalid price", errs.PrecisionError},
+	{"tick size", errs.PrecisionError},
+	{"minimum value", errs.PrecisionError},
+	{"asset not found", errs.InvalidSymbol},
+	{"unknown asset", errs.InvalidSymbol},
+	{"status 429", errs.RateLimited},
+	{"status 5", errs.Transient},
+}
+
+// classifyHyperliquidError 按拒单原因给Hyperliquid错误标注类别
+func classifyHyperliquidError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if kind := errs.Network(err); kind != errs.Unknown {
+		return errs.Wrap(kind, 0, err)
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range hyperliquidErrorKinds {
+		if strings.Contains(msg, m.pattern) {
+			return errs.Wrap(m.kind, 0, err)
+		}
+	}
+	return err
+}
+
+// bybitErrorKinds Bybit v5 retCode → 错误类别
+var bybitErrorKinds = map[int64]errs.Kind{
+	10006:  errs.RateLimited,        // 请求过多（UID限频）
+	10018:  errs.RateLimited,        // IP限频
+	10016:  errs.Transient,          // 服务器内部错误
+	10002:  errs.ClockSkew,          // 时间戳超出recv_window
+	110007: errs.InsufficientMargin, // 可用余额不足
+	110012: errs.InsufficientMargin, // 可用余额不足（减仓）
+	110045: errs.InsufficientMargin, // 钱包余额不足
+	110094: errs.PrecisionError,     // 不满足最小名义价值
+	110017: errs.PrecisionError,     // 数量不满足只减仓规则（持仓已变化）
+	110074: errs.InvalidSymbol,      // 合约未上线或已下架
+	110001: errs.OrderNotFound,      // 订单不存在
+	110043: errs.NoChange,           // 杠杆无需修改
+	110026: errs.NoChange,           // 保证金模式无需修改
+}
+
+// bybitCodeUnifiedMargin 统一账户不支持按币种切换逐仓（保证金模式在账户级别设置）
+const bybitCodeUnifiedMargin = 100028
+
+// classifyBybitResponse 按 retCode 给Bybit错误标注类别（retCode=0时由HTTP状态码决定）
+func classifyBybitResponse(status int, retCode int64, retMsg string, body []byte) error {
+	if retCode == 0 {
+		err := fmt.Errorf("HTTP %d: %s", status, string(body))
+		if status == 403 {
+			return errs.Wrap(errs.RateLimited, 0, err) // IP访问过于频繁
+		}
+		return errs.Wrap(errs.HTTPStatus(status), 0, err)
+	}
+	err := fmt.Errorf("bybit %d: %s", retCode, retMsg)
+	return &errs.Error{Kind: bybitErrorKinds[retCode], Code: retCode, Err: err}
+}
@@ -0,0 +1,328 @@
+package trader
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/errs"
+	"nofx/logger"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🧾 执行预写日志（WAL）：每次改变交易所状态前先落盘意图，完成后再追加结果
+// 崩溃发生在"已下单、未记录保护信息/未设止损"之间时，重启后按交易所订单记录核对未完成的条目
+
+// 日志条目阶段
+const (
+	journalIntent    = "intent"    // 即将执行
+	journalDone      = "done"      // 已完成
+	journalFailed    = "failed"    // 交易所明确拒绝（未执行）
+	journalUnknown   = "unknown"   // 超时/网络错误，执行状态未知
+	journalRecovered = "recovered" // 重启后核对：交易所已执行
+	journalAborted   = "aborted"   // 重启后核对：交易所未执行
+)
+
+// 日志操作（开平仓沿用决策动作名）
+const journalSetStopLoss = "set_stop_loss"
+
+// journalEntry 执行日志条目（意图记录字段完整，结果记录只有序号、阶段和订单信息）
+type journalEntry struct {
+	Seq           int64     `json:"seq"`
+	Time          time.Time `json:"time"`
+	Phase         string    `json:"phase"`
+	Op            string    `json:"op,omitempty"` // open_long/open_short/close_long/close_short/set_stop_loss
+	Symbol        string    `json:"symbol,omitempty"`
+	Side          string    `json:"side,omitempty"` // long/short
+	Quantity      float64   `json:"quantity,omitempty"`
+	Leverage      int       `json:"leverage,omitempty"`
+	StopLoss      float64   `json:"stop_loss,omitempty"`
+	TakeProfit    float64   `json:"take_profit,omitempty"`
+	Strategy      string    `json:"strategy,omitempty"`
+	Reasoning     string    `json:"reasoning,omitempty"`
+	Cycle         int       `json:"cycle,omitempty"`
+	ClientOrderID string    `json:"client_order_id,omitempty"`
+	OrderID       int64     `json:"order_id,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// executionJournal 追加写入的执行日志（<dir>/execution_journal.jsonl，每条写入后fsync）
+type executionJournal struct {
+	mu   sync.Mutex
+	path string
+	seq  int64
+}
+
+// newExecutionJournal 打开执行日志并恢复序号
+func newExecutionJournal(dir string) *executionJournal {
+	j := &executionJournal{path: filepath.Join(dir, "execution_journal.jsonl")}
+	entries, err := j.read()
+	if err != nil {
+		log.Printf("⚠️  读取执行日志失败: %v", err)
+	}
+	for _, e := range entries {
+		if e.Seq > j.seq {
+			j.seq = e.Seq
+		}
+	}
+	return j
+}
+
+// read 读取全部条目（损坏的行跳过：崩溃可能留下半行）
+func (j *executionJournal) read() ([]journalEntry, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []journalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// append 追加一条记录并同步到磁盘（调用方持有锁）
+func (j *executionJournal) append(e journalEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("JSON序列化失败: %w", err)
+	}
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// begin 记录执行意图，返回序号（日志未启用时返回0）
+func (j *executionJournal) begin(e journalEntry) int64 {
+	if j == nil {
+		return 0
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	e.Seq = j.seq
+	e.Time = time.Now()
+	e.Phase = journalIntent
+	if err := j.append(e); err != nil {
+		log.Printf("  ⚠️ 写入执行日志失败: %v", err)
+	}
+	return e.Seq
+}
+
+// finish 记录执行结果
+func (j *executionJournal) finish(seq int64, phase string, orderID int64, err error) {
+	if j == nil || seq == 0 {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	e := journalEntry{Seq: seq, Time: time.Now(), Phase: phase, OrderID: orderID}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if werr := j.append(e); werr != nil {
+		log.Printf("  ⚠️ 写入执行日志失败: %v", werr)
+	}
+}
+
+// pending 没有最终结果的意图（仍为intent或状态未知）
+func (j *executionJournal) pending() ([]journalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.read()
+	if err != nil {
+		return nil, err
+	}
+	intents := make(map[int64]journalEntry)
+	var order []int64
+	for _, e := range entries {
+		if e.Phase == journalIntent {
+			intents[e.Seq] = e
+			order = append(order, e.Seq)
+			continue
+		}
+		if e.Phase != journalUnknown {
+			delete(intents, e.Seq)
+		}
+	}
+
+	var result []journalEntry
+	for _, seq := range order {
+		if e, ok := intents[seq]; ok {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+// compact 重写日志，只保留仍未完成的意图（对账后调用，防止文件无限增长）
+func (j *executionJournal) compact(keep []journalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var sb strings.Builder
+	for _, e := range keep {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("JSON序列化失败: %w", err)
+		}
+		sb.Write(data)
+		sb.WriteByte('\n')
+	}
+	tmpFile := j.path + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	return os.Rename(tmpFile, j.path)
+}
+
+// journalPhase 按下单错误判断结果阶段（超时/网络错误或未分类错误时订单可能已到达交易所，留待对账）
+func journalPhase(err error) string {
+	if err == nil {
+		return journalDone
+	}
+	if errs.Is(err, errs.Transient, errs.Unknown) {
+		return journalUnknown
+	}
+	return journalFailed
+}
+
+// reconcileJournal 核对未完成的执行意图（启动及恢复连接时在持仓对账前调用）
+// 已成交但未记录保护信息的开仓按意图补记止损止盈，随后的持仓对账会把它当作已知持仓并补设缺失的止损
+func (at *AutoTrader) reconcileJournal(positions []Position) {
+	if at.journal == nil {
+		return
+	}
+	pending, err := at.journal.pending()
+	if err != nil {
+		log.Printf("⚠️  读取执行日志失败: %v", err)
+		return
+	}
+
+	var unresolved []journalEntry
+	for _, e := range pending {
+		executed, known := at.journalExecuted(e, positions)
+		if !known {
+			log.Printf("  ⚠️ [%s %s] 未完成的%s（#%d）暂时无法核对，保留到下次对账", e.Symbol, e.Side, e.Op, e.Seq)
+			unresolved = append(unresolved, e)
+			continue
+		}
+		if !executed {
+			log.Printf("  🧾 [%s %s] 未完成的%s（#%d, %s）未在交易所执行", e.Symbol, e.Side, e.Op, e.Seq, e.Time.Format("01-02 15:04:05"))
+			at.journal.finish(e.Seq, journalAborted, 0, nil)
+			continue
+		}
+
+		log.Printf("  🧾 [%s %s] 未完成的%s（#%d, %s）已在交易所执行", e.Symbol, e.Side, e.Op, e.Seq, e.Time.Format("01-02 15:04:05"))
+		if e.Op == "open_long" || e.Op == "open_short" || e.Op == journalSetStopLoss {
+			at.restoreJournalProtection(e, positions)
+		}
+		at.journal.finish(e.Seq, journalRecovered, 0, nil)
+	}
+
+	if err := at.journal.compact(unresolved); err != nil {
+		log.Printf("⚠️  压缩执行日志失败: %v", err)
+	}
+}
+
+// journalExecuted 判断意图是否已在交易所执行（known=false 表示查询失败无法判断）
+// 有客户端订单ID时按交易所订单记录核对，否则按当前持仓推断
+func (at *AutoTrader) journalExecuted(e journalEntry, positions []Position) (executed, known bool) {
+	hasPosition := FindPosition(positions, e.Symbol, e.Side) != nil
+	switch e.Op {
+	case journalSetStopLoss:
+		// 止损是否已挂由持仓对账核对，这里只确认持仓仍在
+		return hasPosition, true
+	case "open_long", "open_short", "close_long", "close_short":
+	default:
+		return false, true
+	}
+
+	if idempotent, ok := at.trader.(IdempotentTrader); ok && e.ClientOrderID != "" {
+		order, err := idempotent.FindOrderByClientID(e.Symbol, e.ClientOrderID)
+		if err != nil {
+			log.Printf("  ⚠️ [%s] 查询订单%s失败: %v", e.Symbol, e.ClientOrderID, err)
+			return false, false
+		}
+		if order != nil {
+			return order.ExecutedQty > 0, true
+		}
+		// TWAP首个分片使用原始订单ID，查不到说明整笔未下单
+		return false, true
+	}
+
+	if strings.HasPrefix(e.Op, "open_") {
+		return hasPosition, true
+	}
+	return !hasPosition, true
+}
+
+// restoreJournalProtection 已成交的开仓缺少保护信息时按意图补记（已有记录或持仓已不存在时跳过）
+func (at *AutoTrader) restoreJournalProtection(e journalEntry, positions []Position) {
+	if FindPosition(positions, e.Symbol, e.Side) == nil {
+		return
+	}
+	if _, ok := at.orderManager.GetProtection(e.Symbol, e.Side); ok {
+		return
+	}
+	if e.StopLoss <= 0 && e.TakeProfit <= 0 {
+		return
+	}
+	at.orderManager.SetProtection(&PositionProtection{
+		Symbol:     e.Symbol,
+		Side:       e.Side,
+		StopLoss:   e.StopLoss,
+		TakeProfit: e.TakeProfit,
+		OpenTime:   e.Time,
+		Reasoning:  e.Reasoning,
+		Source:     "journal",
+		Strategy:   e.Strategy,
+	})
+	log.Printf("  ✓ [%s %s] 已按执行日志恢复保护信息: 止损=%.4f 止盈=%.4f", e.Symbol, e.Side, e.StopLoss, e.TakeProfit)
+}
+
+// journalOrder 记录开平仓意图（平仓数量为0表示全部平仓）
+func (at *AutoTrader) journalOrder(d *decision.Decision, side string, quantity float64, actionRecord *logger.DecisionAction) int64 {
+	return at.journal.begin(journalEntry{
+		Op:            d.Action,
+		Symbol:        d.Symbol,
+		Side:          side,
+		Quantity:      quantity,
+		Leverage:      d.Leverage,
+		StopLoss:      d.StopLoss,
+		TakeProfit:    d.TakeProfit,
+		Strategy:      actionRecord.Strategy,
+		Reasoning:     d.Reasoning,
+		Cycle:         at.callCount,
+		ClientOrderID: actionRecord.ClientOrderID,
+	})
+}
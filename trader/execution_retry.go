@@ -0,0 +1,131 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/errs"
+	"nofx/i18n"
+	"nofx/logger"
+	"time"
+)
+
+// 🔁 开仓失败重试：限流、网络抖动、保证金检查竞态等瞬时错误不直接放弃机会，本周期内退避重试
+const (
+	maxExecutionRetries  = 3               // 最多重试次数（不含首次执行）
+	executionRetryBase   = 2 * time.Second // 首次退避时间，之后每次翻倍
+	executionRetryWindow = 30 * time.Second
+)
+
+// isTransientExecError 是否瞬时错误（值得重试）
+// 除限流/网络/时间戳等瞬时类别外，交易所503和保证金不足（平仓释放保证金与开仓检查竞态）也在本周期内重试
+// 执行状态未知（超时、-1007）归为瞬时错误，重试前先按客户端订单ID核对首次请求是否已成交（见checkRetriedOpen）
+func isTransientExecError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errs.Retryable(err) || errs.Is(err, errs.Maintenance, errs.InsufficientMargin)
+}
+
+// pendingExecution 等待重试的开仓决策
+type pendingExecution struct {
+	decision     decision.Decision
+	actionRecord *logger.DecisionAction
+	lastErr      error
+}
+
+// shouldQueueRetry 首次执行失败后是否进入重试队列（仅开仓、瞬时错误、非观察模式）
+func (at *AutoTrader) shouldQueueRetry(d *decision.Decision, err error) bool {
+	if at.config.ObserveMode {
+		return false
+	}
+	if d.Action != "open_long" && d.Action != "open_short" {
+		return false // 平仓失败由下一周期持仓管理处理，避免在价格变化后重复平仓
+	}
+	return isTransientExecError(err)
+}
+
+// retryPendingExecutions 按退避时间重试队列中的开仓决策，记录最终结果
+// 每次重试前先核对首次请求的订单：已成交的补完止损止盈等后续步骤，不再走开仓检查（否则会被"已有持仓"拒绝，留下没有止损的仓位）
+func (at *AutoTrader) retryPendingExecutions(record *logger.DecisionRecord, ctx *decision.Context, queue []*pendingExecution) {
+	if len(queue) == 0 {
+		return
+	}
+	i18n.Logf("exec.retry_batch", len(queue))
+
+	deadline := time.Now().Add(executionRetryWindow)
+	backoff := executionRetryBase
+	for attempt := 1; attempt <= maxExecutionRetries && len(queue) > 0; attempt++ {
+		if time.Now().Add(backoff).After(deadline) {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+
+		var remaining []*pendingExecution
+		for _, p := range queue {
+			p.actionRecord.Error = ""
+			p.actionRecord.ErrorKind = ""
+			p.actionRecord.Attempts = attempt + 1
+			i18n.Logf("exec.retry_attempt", attempt, p.decision.Symbol, p.decision.Action, p.lastErr)
+			done, err := at.checkRetriedOpen(p)
+			if !done && err == nil {
+				err = at.executeDecisionWithRecord(&p.decision, p.actionRecord)
+			}
+			switch {
+			case err == nil:
+				record.ExecutionLog = append(record.ExecutionLog,
+					i18n.T("exec.retry_succeeded", p.decision.Symbol, p.decision.Action, attempt))
+				at.recordExecution(record, ctx, &p.decision, p.actionRecord, nil)
+			case isTransientExecError(err) || errors.Is(err, errRetryLookup):
+				p.lastErr = err
+				remaining = append(remaining, p)
+			default:
+				// 非瞬时错误（如已有持仓、风控拦截），不再重试
+				at.recordExecution(record, ctx, &p.decision, p.actionRecord, fmt.Errorf("第%d次重试失败: %w", attempt, err))
+			}
+		}
+		queue = remaining
+	}
+
+	for _, p := range queue {
+		at.recordExecution(record, ctx, &p.decision, p.actionRecord,
+			fmt.Errorf("重试%d次后仍失败，放弃: %w", max(p.actionRecord.Attempts-1, 0), p.lastErr))
+	}
+}
+
+// errRetryLookup 重试前核对首次订单失败（首次请求是否成交仍未知，继续留在队列中，不能直接重新开仓）
+var errRetryLookup = errors.New("核对首次开仓订单失败")
+
+// checkRetriedOpen 重试开仓前按客户端订单ID核对首次请求（超时/-1007时首次请求可能已经成交）
+// 已有相同订单ID的订单时补完成交后的步骤（保护信息、止损止盈、硬约束记录），返回done=true，不再重新开仓
+// 限价开仓和不支持客户端订单ID的交易器不核对，直接重跑开仓流程
+func (at *AutoTrader) checkRetriedOpen(p *pendingExecution) (done bool, err error) {
+	idempotent, ok := at.trader.(IdempotentTrader)
+	if !ok || p.decision.IsLimitOrder {
+		return false, nil
+	}
+	d, actionRecord := &p.decision, p.actionRecord
+	actionRecord.ClientOrderID = at.clientOrderID(d.Symbol, d.Action, actionRecord.Strategy)
+	order, err := idempotent.FindOrderByClientID(d.Symbol, actionRecord.ClientOrderID)
+	if err != nil {
+		return false, fmt.Errorf("%w %s: %v", errRetryLookup, actionRecord.ClientOrderID, err)
+	}
+	if !orderReachedExchange(order) {
+		return false, nil // 首次请求未到达交易所或已撤销，按正常流程重新开仓
+	}
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return false, fmt.Errorf("%w: 获取持仓失败: %v", errRetryLookup, err)
+	}
+	side := "long"
+	if d.Action == "open_short" {
+		side = "short"
+	}
+	log.Printf("  🔁 %s %s 首次请求已到达交易所（%s, 状态%s, 成交%.6f），不再重新开仓",
+		d.Symbol, d.Action, actionRecord.ClientOrderID, order.Status, order.ExecutedQty)
+	at.completeExecutedOpen(d, side, order, positions, actionRecord)
+	return true, nil
+}
@@ -0,0 +1,77 @@
+package trader
+
+import (
+	"errors"
+	"nofx/decision"
+	"nofx/logger"
+	"testing"
+)
+
+// 首次开仓超时（状态未知）但实际已成交：重试前按订单ID核对，补设止损止盈，不再走开仓检查
+func TestCheckRetriedOpenAdoptsFilledOrder(t *testing.T) {
+	const cycle = 12
+	exchange := newFakeExchange()
+	clientID := ClientOrderID("test_trader", cycle, "SOLUSDT", "open_short", "")
+	exchange.orders[clientID] = &OrderResult{OrderID: 77, ClientOrderID: clientID, Symbol: "SOLUSDT", Status: "FILLED", Side: "SELL", ExecutedQty: 3}
+	exchange.positions = []Position{{Symbol: "SOLUSDT", Side: "short", Quantity: 3, EntryPrice: 150, MarkPrice: 150, Leverage: 3}}
+
+	at := newRestartedTrader(t, exchange, cycle)
+	p := &pendingExecution{
+		decision:     decision.Decision{Symbol: "SOLUSDT", Action: "open_short", Leverage: 3, PositionSizeUSD: 450, StopLoss: 160, TakeProfit: 130},
+		actionRecord: &logger.DecisionAction{},
+	}
+	done, err := at.checkRetriedOpen(p)
+	if !done || err != nil {
+		t.Fatalf("checkRetriedOpen() = %v, %v, want true, nil", done, err)
+	}
+	if exchange.opened != 0 {
+		t.Errorf("重试重复下了%d笔开仓单", exchange.opened)
+	}
+	want := protectiveOrder{"SOLUSDT", "SHORT", 3, 160}
+	if len(exchange.stopLosses) != 1 || exchange.stopLosses[0] != want {
+		t.Errorf("止损单 = %+v, want [%+v]", exchange.stopLosses, want)
+	}
+	if _, ok := at.orderManager.GetProtection("SOLUSDT", "short"); !ok {
+		t.Errorf("未记录保护信息")
+	}
+	if p.actionRecord.OrderID != 77 || at.constraints.dailyOpenCount != 1 {
+		t.Errorf("orderId=%d dailyOpenCount=%d, want 77 / 1", p.actionRecord.OrderID, at.constraints.dailyOpenCount)
+	}
+}
+
+func TestCheckRetriedOpen(t *testing.T) {
+	newPending := func() *pendingExecution {
+		return &pendingExecution{
+			decision:     decision.Decision{Symbol: "BTCUSDT", Action: "open_long", Leverage: 5, PositionSizeUSD: 100, StopLoss: 48000, TakeProfit: 55000},
+			actionRecord: &logger.DecisionAction{},
+		}
+	}
+
+	t.Run("首次请求未到达交易所时重新开仓", func(t *testing.T) {
+		at := newRestartedTrader(t, newFakeExchange(), 1)
+		if done, err := at.checkRetriedOpen(newPending()); done || err != nil {
+			t.Errorf("checkRetriedOpen() = %v, %v, want false, nil", done, err)
+		}
+	})
+
+	t.Run("查询失败时留在队列，不重新开仓", func(t *testing.T) {
+		exchange := newFakeExchange()
+		exchange.findErr = errors.New("timeout")
+		at := newRestartedTrader(t, exchange, 1)
+		done, err := at.checkRetriedOpen(newPending())
+		if done || !errors.Is(err, errRetryLookup) {
+			t.Errorf("checkRetriedOpen() = %v, %v, want false, errRetryLookup", done, err)
+		}
+	})
+
+	t.Run("限价开仓不核对", func(t *testing.T) {
+		exchange := newFakeExchange()
+		exchange.findErr = errors.New("should not be called")
+		at := newRestartedTrader(t, exchange, 1)
+		p := newPending()
+		p.decision.IsLimitOrder = true
+		if done, err := at.checkRetriedOpen(p); done || err != nil {
+			t.Errorf("checkRetriedOpen() = %v, %v, want false, nil", done, err)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/events"
+	"nofx/logger"
+	"sync"
+	"time"
+)
+
+// 🛟 AI决策失败时的确定性兜底策略：整个周期没有AI决策时仍然管理已有持仓
+// 只做保护性动作（确认止损单、保本/移动止损），绝不开新仓；持仓到期平仓在AI决策之前已执行
+const (
+	fallbackBreakevenR     = 1.0   // 浮盈达到1R（入场价到记录止损的距离）时止损移到保本
+	fallbackBreakevenFee   = 0.001 // 保本价预留0.1%手续费
+	fallbackTrailRatio     = 0.5   // 止损已在保本以上时，锁定50%的浮盈
+	fallbackMinStopStepPct = 0.2   // 新止损至少改善0.2%（按价格）才移动，避免频繁撤单重下
+	maxFallbackLogEntries  = 10    // 状态API保留的最近兜底动作数
+)
+
+// fallbackPolicyState 兜底策略运行统计（状态API）
+type fallbackPolicyState struct {
+	mu         sync.Mutex
+	runs       int
+	stopsMoved int
+	lastRun    time.Time
+	lastReason string
+	recent     []string
+}
+
+// runFallbackPolicy 🛟 AI决策失败后执行兜底策略，动作写入本周期决策记录
+func (at *AutoTrader) runFallbackPolicy(reason string, record *logger.DecisionRecord) {
+	log.Printf("🛟 [%s] AI决策失败，执行兜底策略（不开新仓）: %s", at.name, reason)
+	record.AIFallback = true
+
+	var actions []string
+	note := func(format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		log.Printf("  🛟 %s", msg)
+		actions = append(actions, msg)
+	}
+	defer func() {
+		record.ExecutionLog = append(record.ExecutionLog, prefixAll("🛟 兜底策略: ", actions)...)
+		at.recordFallbackRun(reason, actions)
+	}()
+
+	if at.config.ObserveMode {
+		note("观察模式，不调整保护单")
+		return
+	}
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		note("获取持仓失败，无法管理持仓: %v", err)
+		return
+	}
+	if len(positions) == 0 {
+		note("无持仓，本周期不开新仓")
+		return
+	}
+
+	open := PositionsByKey(positions)
+
+	// 1. 确认止损单：有止损记录但交易所上缺失的持仓交给止损巡检补设（连续两次缺失才补设，避免与移动止损冲突）
+	if _, ok := at.trader.(openOrderLister); ok && at.protectiveMonitorInterval() > 0 {
+		at.checkProtectiveOrders()
+	}
+
+	// 2. 保本/移动止损（按持久化的止损记录，各交易所统一）
+	for key, pos := range open {
+		symbol, side := pos.Symbol, pos.Side
+		if at.ignoredPositions[key] {
+			continue // 外部/人工管理的持仓
+		}
+		protection, ok := at.orderManager.GetProtection(symbol, side)
+		if !ok || protection.StopLoss <= 0 {
+			note("⚠️ %s 没有止损记录，无法按规则管理，请人工检查", key)
+			continue
+		}
+		if protection.Manual {
+			continue
+		}
+		if at.stopGuard.isMissing(key) {
+			note("%s 交易所上缺少止损单，等待止损巡检补设（记录止损%.4f）", key, protection.StopLoss)
+		}
+
+		entry, mark := pos.EntryPrice, pos.MarkPrice
+		newStop, rule := fallbackStop(side, entry, mark, protection.StopLoss)
+		if newStop <= 0 {
+			continue
+		}
+
+		cmd := positionCommand{Kind: "adjust", Symbol: symbol, Side: side, StopLoss: newStop}
+		if err := at.adjustPosition(cmd, open); err != nil {
+			note("%s %s: 止损%.4f → %.4f 失败: %v", key, rule, protection.StopLoss, newStop, err)
+			continue
+		}
+		note("%s %s: 止损%.4f → %.4f（入场%.4f 现价%.4f）", key, rule, protection.StopLoss, newStop, entry, mark)
+		at.events.Publish(events.StopMovedEvent{
+			TraderID: at.id, Symbol: symbol, Side: side, OldStop: protection.StopLoss, NewStop: newStop, Time: time.Now(),
+		})
+		at.fallbackState.mu.Lock()
+		at.fallbackState.stopsMoved++
+		at.fallbackState.mu.Unlock()
+	}
+
+	if len(actions) == 0 {
+		note("%d个持仓的保护单无需调整，本周期不开新仓", len(open))
+	}
+}
+
+// fallbackStop 按确定性规则计算新止损（返回0表示不移动）
+// 止损仍在亏损一侧时：浮盈≥1R移到保本；止损已在保本以上时：锁定50%浮盈，只向有利方向移动
+func fallbackStop(side string, entry, mark, stop float64) (float64, string) {
+	if entry <= 0 || mark <= 0 {
+		return 0, ""
+	}
+	sign := 1.0
+	if side == "short" {
+		sign = -1.0
+	}
+	gain := (mark - entry) * sign
+	if gain <= 0 {
+		return 0, ""
+	}
+
+	breakeven := entry * (1 + sign*fallbackBreakevenFee)
+	var candidate float64
+	var rule string
+	if (breakeven-stop)*sign > 0 {
+		risk := (entry - stop) * sign
+		if risk <= 0 || gain < risk*fallbackBreakevenR {
+			return 0, ""
+		}
+		candidate, rule = breakeven, "保本"
+	} else {
+		candidate, rule = entry+sign*gain*fallbackTrailRatio, "移动止损"
+		if (candidate-breakeven)*sign <= 0 {
+			return 0, ""
+		}
+	}
+
+	if (candidate-stop)*sign < mark*fallbackMinStopStepPct/100 {
+		return 0, ""
+	}
+	if (mark-candidate)*sign <= 0 {
+		return 0, "" // 新止损不能越过现价（会立即触发）
+	}
+	return candidate, rule
+}
+
+func prefixAll(prefix string, lines []string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = prefix + line
+	}
+	return result
+}
+
+func (at *AutoTrader) recordFallbackRun(reason string, actions []string) {
+	s := &at.fallbackState
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs++
+	s.lastRun = time.Now()
+	s.lastReason = reason
+	s.recent = append(s.recent, actions...)
+	if len(s.recent) > maxFallbackLogEntries {
+		s.recent = s.recent[len(s.recent)-maxFallbackLogEntries:]
+	}
+}
+
+// getFallbackPolicyStatus 兜底策略状态（状态API）
+func (at *AutoTrader) getFallbackPolicyStatus() map[string]interface{} {
+	s := &at.fallbackState
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := map[string]interface{}{
+		"runs":        s.runs,
+		"stops_moved": s.stopsMoved,
+	}
+	if s.runs > 0 {
+		status["last_run"] = s.lastRun
+		status["last_reason"] = s.lastReason
+		status["recent_actions"] = append([]string(nil), s.recent...)
+	}
+	return status
+}
@@ -0,0 +1,200 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/events"
+	"nofx/logger"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultFlatWindowNotice = 30 * time.Minute // 默认提前通知时间
+
+// FlatWindowConfig 🌙 定时空仓窗口：窗口内平掉全部持仓并拒绝开仓（如周五22:00到周日22:00的周末避险）
+type FlatWindowConfig struct {
+	Name     string
+	Start    string        // 开始时间（"fri 22:00"，按Timezone解释）
+	End      string        // 结束时间（早于开始时间时跨到下一周，如周五到周日）
+	Timezone string        // IANA时区（空=UTC）
+	Notice   time.Duration // 开始前多久发出通知（0=默认30分钟，<0=不通知）
+}
+
+// flatWindow 解析后的空仓窗口
+type flatWindow struct {
+	FlatWindowConfig
+	loc                *time.Location
+	startDay, startMin int // 星期（周日=0）和当天分钟
+	endDay, endMin     int
+}
+
+// parseWeekTime 解析 "fri 22:00" 形式的每周时间
+func parseWeekTime(s string) (day, minute int, err error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("时间格式应为 \"fri 22:00\": %q", s)
+	}
+	weekday, ok := weekdayNames[strings.ToLower(fields[0])]
+	if !ok {
+		return 0, 0, fmt.Errorf("无效的星期: %q（应为mon..sun）", fields[0])
+	}
+	t, err := time.Parse("15:04", fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("无效的时间: %q（应为HH:MM）", fields[1])
+	}
+	return int(weekday), t.Hour()*60 + t.Minute(), nil
+}
+
+// newFlatWindows 解析空仓窗口配置
+func newFlatWindows(cfgs []FlatWindowConfig) ([]flatWindow, error) {
+	windows := make([]flatWindow, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		w := flatWindow{FlatWindowConfig: cfg, loc: time.UTC}
+		if cfg.Timezone != "" {
+			loc, err := time.LoadLocation(cfg.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("空仓窗口[%s]时区无效: %w", cfg.Name, err)
+			}
+			w.loc = loc
+		}
+		var err error
+		if w.startDay, w.startMin, err = parseWeekTime(cfg.Start); err != nil {
+			return nil, fmt.Errorf("空仓窗口[%s]开始时间: %w", cfg.Name, err)
+		}
+		if w.endDay, w.endMin, err = parseWeekTime(cfg.End); err != nil {
+			return nil, fmt.Errorf("空仓窗口[%s]结束时间: %w", cfg.Name, err)
+		}
+		if w.startDay == w.endDay && w.startMin == w.endMin {
+			return nil, fmt.Errorf("空仓窗口[%s]开始和结束时间相同", cfg.Name)
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// next 当前所在或下一次窗口的开始/结束时间（start<=now 表示处于窗口中）
+// 按当地日期计算，夏令时切换当周窗口的时长跟随墙上时间
+func (w flatWindow) next(now time.Time) (start, end time.Time) {
+	local := now.In(w.loc)
+	y, m, d := local.Date()
+	sunday := d - int(local.Weekday())
+	for week := -1; week <= 1; week++ {
+		start = time.Date(y, m, sunday+week*7+w.startDay, 0, w.startMin, 0, 0, w.loc)
+		end = time.Date(y, m, sunday+week*7+w.endDay, 0, w.endMin, 0, 0, w.loc)
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 7)
+		}
+		if now.Before(end) {
+			return start, end
+		}
+	}
+	return start, end
+}
+
+func (w flatWindow) notice() time.Duration {
+	if w.Notice == 0 {
+		return defaultFlatWindowNotice
+	}
+	return w.Notice
+}
+
+// flatWindowState 空仓窗口通知/执行状态（每次窗口只通知一次）
+type flatWindowState struct {
+	mu       sync.Mutex
+	notified map[string]time.Time    // 窗口名 → 已通知的开始时间
+	active   map[string][2]time.Time // 窗口名 → 正在执行的窗口开始/结束时间
+}
+
+// activeFlatWindow 当前生效的空仓窗口（多个窗口重叠时取结束最晚的）
+func (at *AutoTrader) activeFlatWindow(now time.Time) (w flatWindow, start, end time.Time, ok bool) {
+	for _, candidate := range at.flatWindows {
+		s, e := candidate.next(now)
+		if s.After(now) {
+			continue
+		}
+		if !ok || e.After(end) {
+			w, start, end, ok = candidate, s, e, true
+		}
+	}
+	return w, start, end, ok
+}
+
+// enforceFlatWindows 🌙 定时空仓：窗口开始前发出通知，窗口内每个周期平掉剩余持仓并跳过AI决策
+// 返回true表示本周期处于空仓窗口（调用方记录日志后结束周期）
+func (at *AutoTrader) enforceFlatWindows(record *logger.DecisionRecord) bool {
+	if len(at.flatWindows) == 0 {
+		return false
+	}
+	now := time.Now()
+	state := &at.flatState
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	for _, w := range at.flatWindows {
+		start, end := w.next(now)
+		if start.After(now) {
+			// 不在窗口中：刚结束的窗口发出结束通知
+			if prev, ok := state.active[w.Name]; ok {
+				delete(state.active, w.Name)
+				log.Printf("🌙 [%s] 空仓窗口[%s]结束，恢复交易", at.name, w.Name)
+				at.events.Publish(events.FlatWindowEvent{TraderID: at.id, Name: w.Name, Phase: "ended", Start: prev[0], End: prev[1], Time: now})
+			}
+			// 开始前通知
+			if notice := w.notice(); notice > 0 && start.Sub(now) <= notice && !state.notified[w.Name].Equal(start) {
+				state.notified[w.Name] = start
+				log.Printf("🌙 [%s] 空仓窗口[%s]将于 %s 开始（%.0f分钟后），届时平掉全部持仓并停止开仓，至 %s",
+					at.name, w.Name, start.Format("01-02 15:04 MST"), start.Sub(now).Minutes(), end.Format("01-02 15:04 MST"))
+				at.events.Publish(events.FlatWindowEvent{TraderID: at.id, Name: w.Name, Phase: "upcoming", Start: start, End: end, Time: now})
+			}
+			continue
+		}
+		if !state.active[w.Name][0].Equal(start) {
+			state.active[w.Name] = [2]time.Time{start, end}
+			log.Printf("🌙 [%s] 进入空仓窗口[%s]，平掉全部持仓，停止开仓至 %s", at.name, w.Name, end.Format("01-02 15:04 MST"))
+			at.events.Publish(events.FlatWindowEvent{TraderID: at.id, Name: w.Name, Phase: "started", Start: start, End: end, Time: now})
+		}
+	}
+
+	w, _, end, ok := at.activeFlatWindow(now)
+	if !ok {
+		return false
+	}
+
+	// 每个周期都检查：上次平仓失败或窗口内出现的新持仓（人工开仓等）继续平掉
+	closed, failed := at.flattenAllPositions(record, "🌙 定时空仓")
+	record.Success = false
+	record.ErrorMessage = fmt.Sprintf("空仓窗口[%s]中，停止开仓至 %s", w.Name, end.Format("01-02 15:04 MST"))
+	if closed > 0 || failed > 0 {
+		record.ErrorMessage += fmt.Sprintf("（平仓%d个，失败%d个）", closed, failed)
+	}
+	return true
+}
+
+// checkFlatWindow 空仓窗口内拒绝开仓
+func (at *AutoTrader) checkFlatWindow() error {
+	if w, _, end, ok := at.activeFlatWindow(time.Now()); ok {
+		return fmt.Errorf("空仓窗口[%s]中，%s 前不开新仓", w.Name, end.Format("01-02 15:04 MST"))
+	}
+	return nil
+}
+
+// getFlatWindowStatus 空仓窗口状态（状态API）
+func (at *AutoTrader) getFlatWindowStatus() []map[string]interface{} {
+	if len(at.flatWindows) == 0 {
+		return nil
+	}
+	now := time.Now()
+	status := make([]map[string]interface{}, 0, len(at.flatWindows))
+	for _, w := range at.flatWindows {
+		start, end := w.next(now)
+		status = append(status, map[string]interface{}{
+			"name":     w.Name,
+			"timezone": w.loc.String(),
+			"active":   !start.After(now),
+			"start":    start,
+			"end":      end,
+		})
+	}
+	return status
+}
@@ -0,0 +1,142 @@
+package trader
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// 健康检查状态（按严重程度递增）
+const (
+	HealthOK        = "ok"
+	HealthDegraded  = "degraded"
+	HealthUnhealthy = "unhealthy"
+)
+
+// HealthCheck 单项健康检查结果
+type HealthCheck struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+	LatencyMs int64  `json:"latency_ms,omitempty"`
+}
+
+const (
+	healthProbeTimeout = 5 * time.Second
+	slowProbeLatency   = 3 * time.Second // 超过此延迟视为degraded
+)
+
+// HealthSeverity 状态的严重程度（用于汇总取最差）
+func HealthSeverity(status string) int {
+	switch status {
+	case HealthUnhealthy:
+		return 2
+	case HealthDegraded:
+		return 1
+	}
+	return 0
+}
+
+// HealthChecks trader自检：交易所API、LLM API、WebSocket、记忆文件、挂起的限价单
+func (at *AutoTrader) HealthChecks() []HealthCheck {
+	checks := []HealthCheck{
+		at.checkExchange(),
+		at.checkLLM(),
+		at.checkMemoryFile(),
+		at.checkPendingOrders(),
+	}
+	if at.altcoinWSMonitor != nil {
+		checks = append(checks, at.checkAltcoinWS())
+	}
+	return checks
+}
+
+// checkExchange 交易所API可达性（获取BTC价格）
+func (at *AutoTrader) checkExchange() HealthCheck {
+	check := HealthCheck{Name: "exchange_api", Status: HealthOK}
+
+	start := time.Now()
+	_, err := at.trader.GetMarketPrice("BTCUSDT")
+	latency := time.Since(start)
+	check.LatencyMs = latency.Milliseconds()
+
+	switch {
+	case err != nil:
+		check.Status = HealthUnhealthy
+		check.Message = fmt.Sprintf("%s: %v", at.exchange, err)
+	case latency > slowProbeLatency:
+		check.Status = HealthDegraded
+		check.Message = fmt.Sprintf("%s响应缓慢", at.exchange)
+	}
+	return check
+}
+
+// checkLLM LLM API可达性（只检查网络连通，不消耗token：任何HTTP响应都视为可达）
+func (at *AutoTrader) checkLLM() HealthCheck {
+	check := HealthCheck{Name: "llm_api", Status: HealthOK}
+
+	client := &http.Client{Timeout: healthProbeTimeout}
+	start := time.Now()
+	resp, err := client.Get(at.mcpClient.BaseURL)
+	latency := time.Since(start)
+	check.LatencyMs = latency.Milliseconds()
+
+	if err != nil {
+		check.Status = HealthUnhealthy
+		check.Message = fmt.Sprintf("%s不可达: %v", at.mcpClient.Provider, err)
+		return check
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		check.Status = HealthDegraded
+		check.Message = fmt.Sprintf("%s返回HTTP %d", at.mcpClient.Provider, resp.StatusCode)
+	case latency > slowProbeLatency:
+		check.Status = HealthDegraded
+		check.Message = fmt.Sprintf("%s响应缓慢", at.mcpClient.Provider)
+	}
+	return check
+}
+
+// checkAltcoinWS 山寨币WebSocket连接状态
+func (at *AutoTrader) checkAltcoinWS() HealthCheck {
+	check := HealthCheck{Name: "altcoin_ws", Status: HealthOK}
+	if !at.altcoinWSMonitor.Connected() {
+		check.Status = HealthDegraded
+		check.Message = "WebSocket未连接（重连中）"
+		return check
+	}
+	check.Message = fmt.Sprintf("%d个ticker", at.altcoinWSMonitor.GetTickerCount())
+	return check
+}
+
+// checkMemoryFile 记忆文件完整性
+func (at *AutoTrader) checkMemoryFile() HealthCheck {
+	check := HealthCheck{Name: "memory_file", Status: HealthOK}
+	if err := at.memoryManager.Verify(); err != nil {
+		check.Status = HealthDegraded
+		check.Message = err.Error()
+	}
+	return check
+}
+
+// checkPendingOrders 挂起的限价单（长时间未成交/未清理的限价单视为degraded）
+func (at *AutoTrader) checkPendingOrders() HealthCheck {
+	check := HealthCheck{Name: "pending_limit_orders", Status: HealthOK}
+
+	orders := at.orderManager.GetAllOrders()
+	stale := 0
+	for _, order := range orders {
+		if at.orderManager.GetOrderAge(order.Symbol) > 3*at.config.ScanInterval {
+			stale++
+		}
+	}
+
+	check.Message = fmt.Sprintf("%d个挂单", len(orders))
+	if stale > 0 {
+		check.Status = HealthDegraded
+		check.Message = fmt.Sprintf("%d个挂单，其中%d个超过3个扫描周期未处理", len(orders), stale)
+	}
+	return check
+}
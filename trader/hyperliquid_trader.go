@@ -5,18 +5,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"nofx/ratelimit"
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/sonirico/go-hyperliquid"
 )
 
+// hlRate Hyperliquid限流预算（SDK无法注入HTTP客户端，只能在调用前后手动计数）
+// 权重参考官方文档：clearinghouseState/allMids=2，openOrders=20，交易动作=1
+var hlRate = ratelimit.For(ratelimit.Hyperliquid)
+
+// hlError 记录限流并给SDK返回的错误标注类别
+func hlError(err error) error {
+	err = classifyHyperliquidError(err)
+	hlRate.ObserveError(err)
+	return err
+}
+
 // HyperliquidTrader Hyperliquid交易器
 type HyperliquidTrader struct {
 	exchange   *hyperliquid.Exchange
 	ctx        context.Context
 	walletAddr string
-	meta       *hyperliquid.Meta // 缓存meta信息（包含精度等）
+	meta       *hyperliquid.Meta          // 缓存meta信息（包含精度等）
+	marginMode func(symbol string) string // 🎚️ 按币种保证金模式（nil=逐仓）
+}
+
+// SetMarginModePolicy 设置按币种保证金模式（逐仓/全仓在UpdateLeverage时一并指定）
+func (t *HyperliquidTrader) SetMarginModePolicy(marginMode func(symbol string) string) {
+	t.marginMode = marginMode
 }
 
 // NewHyperliquidTrader 创建Hyperliquid交易器
@@ -71,18 +90,22 @@ func NewHyperliquidTrader(privateKeyHex string, walletAddr string, testnet bool)
 }
 
 // GetBalance 获取账户余额
-func (t *HyperliquidTrader) GetBalance() (map[string]interface{}, error) {
+func (t *HyperliquidTrader) GetBalance() (*Balance, error) {
 	log.Printf("🔄 正在调用Hyperliquid API获取账户余额...")
 
 	// 获取账户状态
+	if err := hlRate.Acquire(2, true); err != nil {
+		return nil, err
+	}
 	accountState, err := t.exchange.Info().UserState(t.ctx, t.walletAddr)
 	if err != nil {
+		err = hlError(err)
 		log.Printf("❌ Hyperliquid API调用失败: %v", err)
 		return nil, fmt.Errorf("获取账户信息失败: %w", err)
 	}
 
 	// 解析余额信息（MarginSummary字段都是string）
-	result := make(map[string]interface{})
+	result := &Balance{}
 
 	// 🔍 调试：打印API返回的完整CrossMarginSummary结构
 	summaryJSON, _ := json.MarshalIndent(accountState.MarginSummary, "  ", "  ")
@@ -107,29 +130,33 @@ func (t *HyperliquidTrader) GetBalance() (map[string]interface{}, error) {
 	// 需要返回"不包含未实现盈亏的钱包余额"
 	walletBalanceWithoutUnrealized := accountValue - totalUnrealizedPnl
 
-	result["totalWalletBalance"] = walletBalanceWithoutUnrealized // 钱包余额（不含未实现盈亏）
-	result["availableBalance"] = accountValue - totalMarginUsed   // 可用余额（总净值 - 占用保证金）
-	result["totalUnrealizedProfit"] = totalUnrealizedPnl          // 未实现盈亏
+	result.TotalWalletBalance = walletBalanceWithoutUnrealized // 钱包余额（不含未实现盈亏）
+	result.AvailableBalance = accountValue - totalMarginUsed   // 可用余额（总净值 - 占用保证金）
+	result.TotalUnrealizedProfit = totalUnrealizedPnl          // 未实现盈亏
 
 	log.Printf("✓ Hyperliquid 账户: 总净值=%.2f (钱包%.2f+未实现%.2f), 可用=%.2f, 保证金占用=%.2f",
 		accountValue,
 		walletBalanceWithoutUnrealized,
 		totalUnrealizedPnl,
-		result["availableBalance"],
+		result.AvailableBalance,
 		totalMarginUsed)
 
 	return result, nil
 }
 
 // GetPositions 获取所有持仓
-func (t *HyperliquidTrader) GetPositions() ([]map[string]interface{}, error) {
+func (t *HyperliquidTrader) GetPositions() ([]Position, error) {
 	// 获取账户状态
+	if err := hlRate.Acquire(2, true); err != nil {
+		return nil, err
+	}
 	accountState, err := t.exchange.Info().UserState(t.ctx, t.walletAddr)
 	if err != nil {
+		err = hlError(err)
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
 
-	var result []map[string]interface{}
+	var result []Position
 
 	// 遍历所有持仓
 	for _, assetPos := range accountState.AssetPositions {
@@ -142,19 +169,16 @@ func (t *HyperliquidTrader) GetPositions() ([]map[string]interface{}, error) {
 			continue // 跳过无持仓的
 		}
 
-		posMap := make(map[string]interface{})
-
 		// 标准化symbol格式（Hyperliquid使用如"BTC"，我们转换为"BTCUSDT"）
-		symbol := position.Coin + "USDT"
-		posMap["symbol"] = symbol
+		pos := Position{Symbol: position.Coin + "USDT"}
 
 		// 持仓数量和方向
 		if posAmt > 0 {
-			posMap["side"] = "long"
-			posMap["positionAmt"] = posAmt
+			pos.Side = "long"
+			pos.Quantity = posAmt
 		} else {
-			posMap["side"] = "short"
-			posMap["positionAmt"] = -posAmt // 转为正数
+			pos.Side = "short"
+			pos.Quantity = -posAmt // 转为正数
 		}
 
 		// 价格信息（EntryPx和LiquidationPx是指针类型）
@@ -175,13 +199,13 @@ func (t *HyperliquidTrader) GetPositions() ([]map[string]interface{}, error) {
 			markPrice = positionValue / absFloat(posAmt)
 		}
 
-		posMap["entryPrice"] = entryPrice
-		posMap["markPrice"] = markPrice
-		posMap["unRealizedProfit"] = unrealizedPnl
-		posMap["leverage"] = float64(position.Leverage.Value)
-		posMap["liquidationPrice"] = liquidationPx
+		pos.EntryPrice = entryPrice
+		pos.MarkPrice = markPrice
+		pos.UnrealizedPnL = unrealizedPnl
+		pos.Leverage = position.Leverage.Value
+		pos.LiquidationPrice = liquidationPx
 
-		result = append(result, posMap)
+		result = append(result, pos)
 	}
 
 	return result, nil
@@ -193,8 +217,13 @@ func (t *HyperliquidTrader) SetLeverage(symbol string, leverage int) error {
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 调用UpdateLeverage (leverage int, name string, isCross bool)
-	_, err := t.exchange.UpdateLeverage(t.ctx, leverage, coin, false) // false = 逐仓模式
+	if err := hlRate.Acquire(1, true); err != nil {
+		return err
+	}
+	isCross := t.marginMode != nil && t.marginMode(symbol) == MarginCross // 默认逐仓
+	_, err := t.exchange.UpdateLeverage(t.ctx, leverage, coin, isCross)
 	if err != nil {
+		err = hlError(err)
 		return fmt.Errorf("设置杠杆失败: %w", err)
 	}
 
@@ -203,7 +232,7 @@ func (t *HyperliquidTrader) SetLeverage(symbol string, leverage int) error {
 }
 
 // OpenLong 开多仓
-func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage int) (*OrderResult, error) {
 	// 先取消该币种的所有委托单
 	if err := t.CancelAllOrders(symbol); err != nil {
 		log.Printf("  ⚠ 取消旧委托单失败: %v", err)
@@ -245,23 +274,24 @@ func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage i
 		ReduceOnly: false,
 	}
 
+	if err := hlRate.Acquire(1, true); err != nil {
+		return nil, err
+	}
 	_, err = t.exchange.Order(t.ctx, order, nil)
 	if err != nil {
+		err = hlError(err)
 		return nil, fmt.Errorf("开多仓失败: %w", err)
 	}
 
 	log.Printf("✓ 开多仓成功: %s 数量: %.4f", symbol, roundedQuantity)
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0 // Hyperliquid没有返回order ID
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	result := &OrderResult{Symbol: symbol, Status: "FILLED"} // Hyperliquid没有返回order ID
 
 	return result, nil
 }
 
 // OpenShort 开空仓
-func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage int) (*OrderResult, error) {
 	// 先取消该币种的所有委托单
 	if err := t.CancelAllOrders(symbol); err != nil {
 		log.Printf("  ⚠ 取消旧委托单失败: %v", err)
@@ -303,23 +333,24 @@ func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage
 		ReduceOnly: false,
 	}
 
+	if err := hlRate.Acquire(1, true); err != nil {
+		return nil, err
+	}
 	_, err = t.exchange.Order(t.ctx, order, nil)
 	if err != nil {
+		err = hlError(err)
 		return nil, fmt.Errorf("开空仓失败: %w", err)
 	}
 
 	log.Printf("✓ 开空仓成功: %s 数量: %.4f", symbol, roundedQuantity)
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	result := &OrderResult{Symbol: symbol, Status: "FILLED"}
 
 	return result, nil
 }
 
 // CloseLong 平多仓
-func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (*OrderResult, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -327,11 +358,8 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 			return nil, err
 		}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "long" {
-				quantity = pos["positionAmt"].(float64)
-				break
-			}
+		if pos := FindPosition(positions, symbol, "long"); pos != nil {
+			quantity = pos.Quantity
 		}
 
 		if quantity == 0 {
@@ -370,8 +398,12 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 		ReduceOnly: true, // 只平仓，不开新仓
 	}
 
+	if err := hlRate.Acquire(1, true); err != nil {
+		return nil, err
+	}
 	_, err = t.exchange.Order(t.ctx, order, nil)
 	if err != nil {
+		err = hlError(err)
 		return nil, fmt.Errorf("平多仓失败: %w", err)
 	}
 
@@ -382,16 +414,13 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 		log.Printf("  ⚠ 取消挂单失败: %v", err)
 	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	result := &OrderResult{Symbol: symbol, Status: "FILLED"}
 
 	return result, nil
 }
 
 // CloseShort 平空仓
-func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (*OrderResult, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
 		positions, err := t.GetPositions()
@@ -399,11 +428,8 @@ func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[str
 			return nil, err
 		}
 
-		for _, pos := range positions {
-			if pos["symbol"] == symbol && pos["side"] == "short" {
-				quantity = pos["positionAmt"].(float64)
-				break
-			}
+		if pos := FindPosition(positions, symbol, "short"); pos != nil {
+			quantity = pos.Quantity
 		}
 
 		if quantity == 0 {
@@ -442,8 +468,12 @@ func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[str
 		ReduceOnly: true,
 	}
 
+	if err := hlRate.Acquire(1, true); err != nil {
+		return nil, err
+	}
 	_, err = t.exchange.Order(t.ctx, order, nil)
 	if err != nil {
+		err = hlError(err)
 		return nil, fmt.Errorf("平空仓失败: %w", err)
 	}
 
@@ -454,10 +484,7 @@ func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[str
 		log.Printf("  ⚠ 取消挂单失败: %v", err)
 	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	result := &OrderResult{Symbol: symbol, Status: "FILLED"}
 
 	return result, nil
 }
@@ -467,8 +494,12 @@ func (t *HyperliquidTrader) CancelAllOrders(symbol string) error {
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 获取所有挂单
+	if err := hlRate.Acquire(20, true); err != nil {
+		return err
+	}
 	openOrders, err := t.exchange.Info().OpenOrders(t.ctx, t.walletAddr)
 	if err != nil {
+		err = hlError(err)
 		return fmt.Errorf("获取挂单失败: %w", err)
 	}
 
@@ -477,6 +508,7 @@ func (t *HyperliquidTrader) CancelAllOrders(symbol string) error {
 		if order.Coin == coin {
 			_, err := t.exchange.Cancel(t.ctx, coin, order.Oid)
 			if err != nil {
+				err = hlError(err)
 				log.Printf("  ⚠ 取消订单失败 (oid=%d): %v", order.Oid, err)
 			}
 		}
@@ -491,8 +523,12 @@ func (t *HyperliquidTrader) GetMarketPrice(symbol string) (float64, error) {
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 获取所有市场价格
+	if err := hlRate.Acquire(2, true); err != nil {
+		return 0, err
+	}
 	allMids, err := t.exchange.Info().AllMids(t.ctx)
 	if err != nil {
+		err = hlError(err)
 		return 0, fmt.Errorf("获取价格失败: %w", err)
 	}
 
@@ -536,8 +572,12 @@ func (t *HyperliquidTrader) SetStopLoss(symbol string, positionSide string, quan
 		ReduceOnly: true,
 	}
 
+	if err := hlRate.Acquire(1, true); err != nil {
+		return err
+	}
 	_, err := t.exchange.Order(t.ctx, order, nil)
 	if err != nil {
+		err = hlError(err)
 		return fmt.Errorf("设置止损失败: %w", err)
 	}
 
@@ -573,8 +613,12 @@ func (t *HyperliquidTrader) SetTakeProfit(symbol string, positionSide string, qu
 		ReduceOnly: true,
 	}
 
+	if err := hlRate.Acquire(1, true); err != nil {
+		return err
+	}
 	_, err := t.exchange.Order(t.ctx, order, nil)
 	if err != nil {
+		err = hlError(err)
 		return fmt.Errorf("设置止盈失败: %w", err)
 	}
 
@@ -582,6 +626,11 @@ func (t *HyperliquidTrader) SetTakeProfit(symbol string, positionSide string, qu
 	return nil
 }
 
+// SetPartialTakeProfit 🪜 分批止盈（Hyperliquid的止盈单本身按数量只减仓，直接复用）
+func (t *HyperliquidTrader) SetPartialTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	return t.SetTakeProfit(symbol, positionSide, quantity, takeProfitPrice)
+}
+
 // FormatQuantity 格式化数量到正确的精度
 func (t *HyperliquidTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
 	coin := convertSymbolToHyperliquid(symbol)
@@ -624,6 +673,19 @@ func (t *HyperliquidTrader) roundToSzDecimals(coin string, quantity float64) flo
 	return float64(int(quantity*multiplier+0.5)) / multiplier
 }
 
+// FormatPrice 格式化价格（5位有效数字，小数位随价格数量级变化）
+func (t *HyperliquidTrader) FormatPrice(symbol string, price float64) (string, error) {
+	rounded := t.roundPriceToSigfigs(price)
+	decimals := 0
+	if rounded > 0 {
+		decimals = 4 - int(math.Floor(math.Log10(rounded)))
+		if decimals < 0 {
+			decimals = 0
+		}
+	}
+	return strconv.FormatFloat(rounded, 'f', decimals, 64), nil
+}
+
 // roundPriceToSigfigs 将价格四舍五入到5位有效数字
 // Hyperliquid要求价格使用5位有效数字（significant figures）
 func (t *HyperliquidTrader) roundPriceToSigfigs(price float64) float64 {
@@ -0,0 +1,182 @@
+package trader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/errs"
+	"nofx/logger"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// IdempotentTrader 支持客户端订单ID的交易器
+// 同一周期、同一币种、同一动作始终生成相同的订单ID，崩溃重启后重跑同一周期时可识别已执行的订单，避免重复开平仓
+type IdempotentTrader interface {
+	OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error)
+	OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error)
+	CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (*OrderResult, error)
+	CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (*OrderResult, error)
+
+	// FindOrderByClientID 按客户端订单ID查询订单（不存在时返回nil, nil）
+	FindOrderByClientID(symbol, clientOrderID string) (*OrderResult, error)
+}
+
+// ClientOrderID 由 (traderID, 周期, 币种, 动作) 生成确定性的客户端订单ID，🏷️ 策略标签编码在前缀中（nofx_<strategy>_<hash>）
+// 币安要求 ^[.A-Z:/a-z0-9_-]{1,36}$，这里取哈希前24位保证长度和字符合法（标签不超过6个字符）
+func ClientOrderID(traderID string, cycle int, symbol, action, strategy string) string {
+	key := fmt.Sprintf("%s|%d|%s|%s", traderID, cycle, symbol, action)
+	sum := sha256.Sum256([]byte(key))
+	if strategy == "" {
+		return "nofx_" + hex.EncodeToString(sum[:])[:24]
+	}
+	return "nofx_" + strategy + "_" + hex.EncodeToString(sum[:])[:24]
+}
+
+// FindOrderByClientID 按客户端订单ID查询订单
+func (t *FuturesTrader) FindOrderByClientID(symbol, clientOrderID string) (*OrderResult, error) {
+	order, err := t.client.NewGetOrderService().
+		Symbol(symbol).
+		OrigClientOrderID(clientOrderID).
+		Do(context.Background())
+	if err != nil {
+		err = classifyBinanceError(err)
+		if errs.Is(err, errs.OrderNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+
+	result := &OrderResult{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        string(order.Status),
+		Side:          string(order.Side),
+		Type:          string(order.Type),
+	}
+	result.ExecutedQty, _ = strconv.ParseFloat(order.ExecutedQuantity, 64)
+	return result, nil
+}
+
+// clientOrderID 当前周期某个决策的客户端订单ID
+func (at *AutoTrader) clientOrderID(symbol, action, strategy string) string {
+	return ClientOrderID(at.id, at.callCount, symbol, action, strategy)
+}
+
+// findExecutedOrder 下单前检查：同一订单ID的订单是否已经成交或挂单（重启后重复执行同一周期的情况）
+// 查询失败时不阻塞交易，只打印警告
+func (at *AutoTrader) findExecutedOrder(symbol, clientOrderID string) *OrderResult {
+	idempotent, ok := at.trader.(IdempotentTrader)
+	if !ok {
+		return nil
+	}
+
+	order, err := idempotent.FindOrderByClientID(symbol, clientOrderID)
+	if err != nil {
+		log.Printf("  ⚠️ 幂等检查失败（继续执行）: %v", err)
+		return nil
+	}
+	if !orderReachedExchange(order) {
+		return nil
+	}
+	return order
+}
+
+// orderReachedExchange 订单是否算已执行：有成交或仍在挂单（已撤销/过期且未成交的订单不算）
+func orderReachedExchange(order *OrderResult) bool {
+	if order == nil {
+		return false
+	}
+	return order.ExecutedQty > 0 || order.Status == string(futures.OrderStatusTypeNew)
+}
+
+// skipDuplicateExecution 已存在相同订单ID的订单时跳过执行，并把已有订单记入决策日志
+func (at *AutoTrader) skipDuplicateExecution(symbol, action string, actionRecord *logger.DecisionAction) bool {
+	actionRecord.ClientOrderID = at.clientOrderID(symbol, action, actionRecord.Strategy)
+	existing := at.findExecutedOrder(symbol, actionRecord.ClientOrderID)
+	if existing == nil {
+		return false
+	}
+
+	actionRecord.OrderID = existing.OrderID
+	log.Printf("  🔁 %s %s 已有相同订单ID的订单（%s, 状态%s），跳过重复执行",
+		symbol, action, actionRecord.ClientOrderID, existing.Status)
+	return true
+}
+
+// adoptExecutedOpen 开仓前的幂等检查，必须在重复持仓检查之前调用（已成交的订单会让持仓检查直接拒绝，后续步骤就没有机会补上）
+// 已有相同订单ID且已成交、但还没有保护信息时（崩溃发生在成交和设止损之间），按本次决策补完成交后的步骤
+// 返回true表示已有相同订单ID的订单，调用方不再下单
+func (at *AutoTrader) adoptExecutedOpen(d *decision.Decision, side string, positions []Position, actionRecord *logger.DecisionAction) bool {
+	actionRecord.ClientOrderID = at.clientOrderID(d.Symbol, d.Action, actionRecord.Strategy)
+	existing := at.findExecutedOrder(d.Symbol, actionRecord.ClientOrderID)
+	if existing == nil {
+		return false
+	}
+	at.completeExecutedOpen(d, side, existing, positions, actionRecord)
+	return true
+}
+
+// completeExecutedOpen 已有相同订单ID的开仓单：已成交且还没有保护信息时补完成交后的步骤，挂单或已处理过的只记录订单ID
+func (at *AutoTrader) completeExecutedOpen(d *decision.Decision, side string, existing *OrderResult, positions []Position, actionRecord *logger.DecisionAction) {
+	actionRecord.OrderID = existing.OrderID
+	if existing.ExecutedQty <= 0 {
+		log.Printf("  🔁 %s %s 已有相同订单ID的挂单（%s, 状态%s），跳过重复执行",
+			d.Symbol, d.Action, actionRecord.ClientOrderID, existing.Status)
+		return
+	}
+	if _, ok := at.orderManager.GetProtection(d.Symbol, side); ok {
+		// 成交后的步骤已执行过（止损缺失由持仓巡检补设）
+		log.Printf("  🔁 %s %s 已有相同订单ID的订单（%s, 状态%s），跳过重复执行",
+			d.Symbol, d.Action, actionRecord.ClientOrderID, existing.Status)
+		return
+	}
+	pos := FindPosition(positions, d.Symbol, side)
+	if pos == nil {
+		log.Printf("  ⚠️ %s %s 订单%s已成交但持仓已不存在，跳过补设止损", d.Symbol, side, actionRecord.ClientOrderID)
+		return
+	}
+
+	log.Printf("  🔁 %s %s 订单%s已成交但未设置保护，补设止损止盈: 数量=%.6f 均价=%.4f",
+		d.Symbol, side, actionRecord.ClientOrderID, pos.Quantity, pos.EntryPrice)
+	actionRecord.Price = pos.EntryPrice
+	at.completeOpen(d, side, existing, pos.Quantity, pos.EntryPrice, 0, actionRecord)
+}
+
+// placeOrder 下单（支持幂等的交易器携带客户端订单ID，其他交易器走原有接口）
+func (at *AutoTrader) placeOrder(action, symbol string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
+	return placeOrderWithClientID(at.trader, action, symbol, quantity, leverage, clientOrderID)
+}
+
+// placeOrderWithClientID 下单（交易器支持幂等时携带客户端订单ID）
+func placeOrderWithClientID(t Trader, action, symbol string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
+	idempotent, ok := t.(IdempotentTrader)
+	switch action {
+	case "open_long":
+		if ok {
+			return idempotent.OpenLongWithClientID(symbol, quantity, leverage, clientOrderID)
+		}
+		return t.OpenLong(symbol, quantity, leverage)
+	case "open_short":
+		if ok {
+			return idempotent.OpenShortWithClientID(symbol, quantity, leverage, clientOrderID)
+		}
+		return t.OpenShort(symbol, quantity, leverage)
+	case "close_long":
+		if ok {
+			return idempotent.CloseLongWithClientID(symbol, quantity, clientOrderID)
+		}
+		return t.CloseLong(symbol, quantity)
+	case "close_short":
+		if ok {
+			return idempotent.CloseShortWithClientID(symbol, quantity, clientOrderID)
+		}
+		return t.CloseShort(symbol, quantity)
+	}
+	return nil, fmt.Errorf("未知的下单动作: %s", action)
+}
@@ -0,0 +1,165 @@
+package trader
+
+import (
+	"errors"
+	"nofx/decision"
+	"nofx/logger"
+	"testing"
+)
+
+// protectiveOrder 模拟交易所记录的止损/止盈单
+type protectiveOrder struct {
+	symbol       string
+	positionSide string
+	quantity     float64
+	price        float64
+}
+
+// fakeExchange 内存模拟的支持客户端订单ID的交易所（只实现开仓恢复用到的接口）
+type fakeExchange struct {
+	positions   []Position
+	orders      map[string]*OrderResult // clientOrderId -> 订单
+	opened      int                     // 收到的开仓请求数
+	findErr     error                   // 查询订单返回的错误
+	stopLosses  []protectiveOrder
+	takeProfits []protectiveOrder
+}
+
+func newFakeExchange() *fakeExchange {
+	return &fakeExchange{orders: make(map[string]*OrderResult)}
+}
+
+func (f *fakeExchange) GetBalance() (*Balance, error) {
+	return &Balance{TotalWalletBalance: 1000, AvailableBalance: 1000}, nil
+}
+func (f *fakeExchange) GetPositions() ([]Position, error) { return f.positions, nil }
+func (f *fakeExchange) OpenLong(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return f.OpenLongWithClientID(symbol, quantity, leverage, "")
+}
+func (f *fakeExchange) OpenShort(symbol string, quantity float64, leverage int) (*OrderResult, error) {
+	return f.OpenShortWithClientID(symbol, quantity, leverage, "")
+}
+func (f *fakeExchange) CloseLong(symbol string, quantity float64) (*OrderResult, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeExchange) CloseShort(symbol string, quantity float64) (*OrderResult, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeExchange) SetLeverage(symbol string, leverage int) error { return nil }
+func (f *fakeExchange) GetMarketPrice(symbol string) (float64, error) {
+	return 0, errors.New("not implemented")
+}
+func (f *fakeExchange) CancelAllOrders(symbol string) error { return nil }
+func (f *fakeExchange) FormatQuantity(symbol string, q float64) (string, error) {
+	return "", errors.New("not implemented")
+}
+func (f *fakeExchange) SetStopLoss(symbol, positionSide string, quantity, stopPrice float64) error {
+	f.stopLosses = append(f.stopLosses, protectiveOrder{symbol, positionSide, quantity, stopPrice})
+	return nil
+}
+func (f *fakeExchange) SetTakeProfit(symbol, positionSide string, quantity, takeProfitPrice float64) error {
+	f.takeProfits = append(f.takeProfits, protectiveOrder{symbol, positionSide, quantity, takeProfitPrice})
+	return nil
+}
+func (f *fakeExchange) OpenLongWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
+	f.opened++
+	return nil, errors.New("unexpected open")
+}
+func (f *fakeExchange) OpenShortWithClientID(symbol string, quantity float64, leverage int, clientOrderID string) (*OrderResult, error) {
+	f.opened++
+	return nil, errors.New("unexpected open")
+}
+func (f *fakeExchange) CloseLongWithClientID(symbol string, quantity float64, clientOrderID string) (*OrderResult, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeExchange) CloseShortWithClientID(symbol string, quantity float64, clientOrderID string) (*OrderResult, error) {
+	return nil, errors.New("not implemented")
+}
+func (f *fakeExchange) FindOrderByClientID(symbol, clientOrderID string) (*OrderResult, error) {
+	if f.findErr != nil {
+		return nil, f.findErr
+	}
+	return f.orders[clientOrderID], nil
+}
+
+// newRestartedTrader 模拟重启后的交易器：本地没有保护信息，周期号与崩溃前相同
+func newRestartedTrader(t *testing.T, exchange Trader, cycle int) *AutoTrader {
+	dir := t.TempDir()
+	return &AutoTrader{
+		id:                    "test_trader",
+		trader:                exchange,
+		callCount:             cycle,
+		constraints:           NewTradingConstraints(),
+		orderManager:          NewOrderManagerWithPath(dir),
+		journal:               newExecutionJournal(dir),
+		positionFirstSeenTime: make(map[string]int64),
+	}
+}
+
+// 崩溃发生在开仓成交之后、设置止损之前：重跑同一周期时按已成交订单补设止损止盈，不重复开仓也不被"已有持仓"拒绝
+func TestRerunCycleAfterCrashBetweenFillAndStopLoss(t *testing.T) {
+	const cycle = 7
+	exchange := newFakeExchange()
+	clientID := ClientOrderID("test_trader", cycle, "BTCUSDT", "open_long", "")
+	exchange.orders[clientID] = &OrderResult{OrderID: 42, ClientOrderID: clientID, Symbol: "BTCUSDT", Status: "FILLED", Side: "BUY", ExecutedQty: 0.002}
+	exchange.positions = []Position{{Symbol: "BTCUSDT", Side: "long", Quantity: 0.002, EntryPrice: 50000, MarkPrice: 50000, Leverage: 5}}
+
+	at := newRestartedTrader(t, exchange, cycle)
+	d := &decision.Decision{Symbol: "BTCUSDT", Action: "open_long", Leverage: 5, PositionSizeUSD: 100, StopLoss: 48000, TakeProfit: 55000}
+	record := &logger.DecisionAction{}
+
+	if err := at.executeOpenLongWithRecord(d, record); err != nil {
+		t.Fatalf("executeOpenLongWithRecord() error = %v", err)
+	}
+	if exchange.opened != 0 {
+		t.Errorf("重跑周期重复下了%d笔开仓单", exchange.opened)
+	}
+	if record.OrderID != 42 || record.ClientOrderID != clientID {
+		t.Errorf("record = orderId %d / clientOrderId %s, want 42 / %s", record.OrderID, record.ClientOrderID, clientID)
+	}
+
+	want := protectiveOrder{"BTCUSDT", "LONG", 0.002, 48000}
+	if len(exchange.stopLosses) != 1 || exchange.stopLosses[0] != want {
+		t.Errorf("止损单 = %+v, want [%+v]", exchange.stopLosses, want)
+	}
+	want.price = 55000
+	if len(exchange.takeProfits) != 1 || exchange.takeProfits[0] != want {
+		t.Errorf("止盈单 = %+v, want [%+v]", exchange.takeProfits, want)
+	}
+
+	p, ok := at.orderManager.GetProtection("BTCUSDT", "long")
+	if !ok || p.StopLoss != 48000 || p.TakeProfit != 55000 {
+		t.Errorf("保护信息 = %+v, %v", p, ok)
+	}
+	if at.constraints.dailyOpenCount != 1 {
+		t.Errorf("dailyOpenCount = %d, want 1", at.constraints.dailyOpenCount)
+	}
+
+	// 再次重跑：保护信息已存在，不再重复设置止损
+	if err := at.executeOpenLongWithRecord(d, &logger.DecisionAction{}); err != nil {
+		t.Fatalf("second rerun error = %v", err)
+	}
+	if exchange.opened != 0 || len(exchange.stopLosses) != 1 {
+		t.Errorf("第二次重跑: 开仓%d次、止损%d个, want 0 / 1", exchange.opened, len(exchange.stopLosses))
+	}
+}
+
+// 相同订单ID的挂单未成交时只跳过，不补设止损
+func TestRerunCycleWithRestingOpenOrder(t *testing.T) {
+	const cycle = 3
+	exchange := newFakeExchange()
+	clientID := ClientOrderID("test_trader", cycle, "ETHUSDT", "open_short", "")
+	exchange.orders[clientID] = &OrderResult{OrderID: 9, ClientOrderID: clientID, Symbol: "ETHUSDT", Status: "NEW", Side: "SELL"}
+
+	at := newRestartedTrader(t, exchange, cycle)
+	d := &decision.Decision{Symbol: "ETHUSDT", Action: "open_short", Leverage: 5, PositionSizeUSD: 100, StopLoss: 3100, TakeProfit: 2800}
+	if err := at.executeOpenShortWithRecord(d, &logger.DecisionAction{}); err != nil {
+		t.Fatalf("executeOpenShortWithRecord() error = %v", err)
+	}
+	if exchange.opened != 0 || len(exchange.stopLosses) != 0 {
+		t.Errorf("开仓%d次、止损%d个, want 0 / 0", exchange.opened, len(exchange.stopLosses))
+	}
+	if _, ok := at.orderManager.GetProtection("ETHUSDT", "short"); ok {
+		t.Errorf("挂单未成交时不应记录保护信息")
+	}
+}
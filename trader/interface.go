@@ -4,22 +4,22 @@ package trader
 // 支持多个交易平台（币安、Hyperliquid等）
 type Trader interface {
 	// GetBalance 获取账户余额
-	GetBalance() (map[string]interface{}, error)
+	GetBalance() (*Balance, error)
 
 	// GetPositions 获取所有持仓
-	GetPositions() ([]map[string]interface{}, error)
+	GetPositions() ([]Position, error)
 
 	// OpenLong 开多仓
-	OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error)
+	OpenLong(symbol string, quantity float64, leverage int) (*OrderResult, error)
 
 	// OpenShort 开空仓
-	OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error)
+	OpenShort(symbol string, quantity float64, leverage int) (*OrderResult, error)
 
 	// CloseLong 平多仓（quantity=0表示全部平仓）
-	CloseLong(symbol string, quantity float64) (map[string]interface{}, error)
+	CloseLong(symbol string, quantity float64) (*OrderResult, error)
 
 	// CloseShort 平空仓（quantity=0表示全部平仓）
-	CloseShort(symbol string, quantity float64) (map[string]interface{}, error)
+	CloseShort(symbol string, quantity float64) (*OrderResult, error)
 
 	// SetLeverage 设置杠杆
 	SetLeverage(symbol string, leverage int) error
@@ -39,3 +39,103 @@ type Trader interface {
 	// FormatQuantity 格式化数量到正确的精度
 	FormatQuantity(symbol string, quantity float64) (string, error)
 }
+
+// Balance 账户余额（USDT计价，币本位按当前价格折算为USD）
+type Balance struct {
+	TotalWalletBalance    float64            // 钱包余额（不含未实现盈亏）
+	AvailableBalance      float64            // 可用余额
+	TotalUnrealizedProfit float64            // 未实现盈亏
+	SettlementAssets      map[string]float64 // 🪙 币本位各结算币种的钱包余额（币，其他交易所为nil）
+
+	// 🪙 U本位单资产模式下各保证金资产（USDT/USDC）的余额，保证金互不通用（多资产模式和其他交易所为nil）
+	MarginAssets map[string]AssetBalance
+}
+
+// AssetBalance 单个保证金资产的余额
+type AssetBalance struct {
+	WalletBalance    float64
+	AvailableBalance float64
+	UnrealizedProfit float64
+}
+
+// AvailableFor 计价币种为quote的合约可用的保证金（未按资产区分时为账户可用余额）
+func (b *Balance) AvailableFor(quote string) float64 {
+	if b.MarginAssets == nil {
+		return b.AvailableBalance
+	}
+	return b.MarginAssets[quote].AvailableBalance
+}
+
+// Equity 账户净值 = 钱包余额 + 未实现盈亏
+func (b *Balance) Equity() float64 {
+	return b.TotalWalletBalance + b.TotalUnrealizedProfit
+}
+
+// Position 持仓（各交易所统一字段，数量始终为正，方向见Side）
+type Position struct {
+	Symbol           string
+	Side             string  // "long" 或 "short"
+	Quantity         float64 // 持仓数量（币）
+	EntryPrice       float64
+	MarkPrice        float64
+	UnrealizedPnL    float64
+	Leverage         int
+	LiquidationPrice float64
+}
+
+// Notional 按标记价格计算的名义价值
+func (p *Position) Notional() float64 {
+	return p.Quantity * p.MarkPrice
+}
+
+// Margin 持仓占用保证金（持仓价值 / 杠杆，杠杆未知时为0）
+func (p *Position) Margin() float64 {
+	if p.Leverage <= 0 || p.MarkPrice <= 0 {
+		return 0
+	}
+	return p.Notional() / float64(p.Leverage)
+}
+
+// FindPosition 按币种和方向查找持仓（找不到返回nil）
+func FindPosition(positions []Position, symbol, side string) *Position {
+	for i := range positions {
+		if positions[i].Symbol == symbol && positions[i].Side == side {
+			return &positions[i]
+		}
+	}
+	return nil
+}
+
+// PositionsByKey 按 "币种_方向" 索引持仓
+func PositionsByKey(positions []Position) map[string]*Position {
+	byKey := make(map[string]*Position, len(positions))
+	for i := range positions {
+		byKey[positions[i].Symbol+"_"+positions[i].Side] = &positions[i]
+	}
+	return byKey
+}
+
+// OrderResult 下单结果或订单查询结果
+type OrderResult struct {
+	OrderID       int64  // 交易所订单ID（Hyperliquid、Bybit等非数字ID的交易所为0）
+	ClientOrderID string // 客户端订单ID
+	Symbol        string
+	Status        string
+	Side          string  // BUY / SELL
+	Type          string  // MARKET / LIMIT / STOP_MARKET / TAKE_PROFIT_MARKET ...
+	Price         float64 // 委托价
+	StopPrice     float64 // 条件单触发价
+	OrigQty       float64 // 委托数量
+	ExecutedQty   float64 // 已成交数量（0=交易所未返回，按委托数量处理）
+	AvgPrice      float64 // 成交均价
+	UpdateTime    int64   // 毫秒
+
+	// 手续费与盈亏（开仓只有Commission；平仓时RealizedPnL为扣除手续费、计入资金费后的净盈亏）
+	Commission      float64
+	OpenCommission  float64
+	FundingFee      float64
+	GrossPnL        float64
+	RealizedPnL     float64
+	RealizedPnLCoin float64 // 🪙 币本位：以结算币计的盈亏
+	SettlementAsset string  // 🪙 币本位：结算币种
+}
@@ -0,0 +1,190 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/logger"
+)
+
+// 保证金模式
+const (
+	MarginIsolated = "isolated" // 逐仓（默认）
+	MarginCross    = "cross"    // 全仓
+)
+
+// SymbolPolicy 单个币种的保证金/杠杆策略
+type SymbolPolicy struct {
+	MarginMode  string // isolated/cross（空=继承默认）
+	MaxLeverage int    // 杠杆上限（0=不额外限制，仍受交易所档位约束）
+}
+
+// LeveragePolicy 保证金模式与杠杆策略（默认策略 + 按币种覆盖）
+type LeveragePolicy struct {
+	Default SymbolPolicy
+	Symbols map[string]SymbolPolicy
+}
+
+// For 币种的生效策略（币种覆盖未设置的字段继承默认值）
+func (p *LeveragePolicy) For(symbol string) SymbolPolicy {
+	policy := SymbolPolicy{MarginMode: MarginIsolated}
+	if p == nil {
+		return policy
+	}
+	if p.Default.MarginMode != "" {
+		policy.MarginMode = p.Default.MarginMode
+	}
+	policy.MaxLeverage = p.Default.MaxLeverage
+
+	if override, ok := p.Symbols[symbol]; ok {
+		if override.MarginMode != "" {
+			policy.MarginMode = override.MarginMode
+		}
+		if override.MaxLeverage > 0 {
+			policy.MaxLeverage = override.MaxLeverage
+		}
+	}
+	return policy
+}
+
+// MarginMode 币种的保证金模式
+func (p *LeveragePolicy) MarginMode(symbol string) string {
+	return p.For(symbol).MarginMode
+}
+
+// MarginModeSetter 支持按币种设置保证金模式的交易器
+type MarginModeSetter interface {
+	SetMarginModePolicy(marginMode func(symbol string) string)
+}
+
+// LeverageBracket 交易所杠杆档位（名义价值区间内允许的最大杠杆）
+type LeverageBracket struct {
+	InitialLeverage int
+	NotionalFloor   float64
+	NotionalCap     float64
+}
+
+// LeverageBracketProvider 支持查询杠杆档位的交易器
+type LeverageBracketProvider interface {
+	GetLeverageBrackets(symbol string) ([]LeverageBracket, error)
+}
+
+// maxNotionalForLeverage 该杠杆下允许的最大名义价值（所有允许该杠杆的档位中最高的上限）
+func maxNotionalForLeverage(brackets []LeverageBracket, leverage int) float64 {
+	maxNotional := 0.0
+	for _, b := range brackets {
+		if b.InitialLeverage >= leverage && b.NotionalCap > maxNotional {
+			maxNotional = b.NotionalCap
+		}
+	}
+	return maxNotional
+}
+
+// maxLeverageForNotional 名义价值所在档位允许的最大杠杆（超出所有档位返回0）
+func maxLeverageForNotional(brackets []LeverageBracket, notional float64) int {
+	for _, b := range brackets {
+		if notional >= b.NotionalFloor && notional < b.NotionalCap {
+			return b.InitialLeverage
+		}
+	}
+	return 0
+}
+
+// referencePrice 推导杠杆用的入场参考价：限价单用挂单价，否则用本周期预测快照价，没有快照时查询最新价
+func (at *AutoTrader) referencePrice(d *decision.Decision) float64 {
+	if d.LimitPrice > 0 {
+		return d.LimitPrice
+	}
+	s := &at.snapshot
+	s.mu.Lock()
+	price := s.prices[d.Symbol]
+	s.mu.Unlock()
+	if price > 0 {
+		return price
+	}
+	price, err := at.trader.GetMarketPrice(d.Symbol)
+	if err != nil {
+		return 0
+	}
+	return price
+}
+
+// applyStopDerivedLeverage 🎯 按止损距离推导杠杆：杠杆 = 目标亏损比例 / 止损距离%（向下取整，至少1x，不超过币种杠杆上限）
+// AI给出的保证金（仓位/杠杆）保持不变，仓位随杠杆缩放，止损触发时亏损 = 保证金 × 目标比例，与币种波动率无关
+func (at *AutoTrader) applyStopDerivedLeverage(d *decision.Decision, policy SymbolPolicy, actionRecord *logger.DecisionAction) {
+	lossPct := at.config.StopMarginLossPct
+	if lossPct <= 0 || d.StopLoss <= 0 || d.Leverage <= 0 || d.PositionSizeUSD <= 0 {
+		return
+	}
+	price := at.referencePrice(d)
+	if price <= 0 {
+		log.Printf("  ⚠️  %s 无法获取参考价，沿用AI杠杆%dx", d.Symbol, d.Leverage)
+		return
+	}
+	stopPct := math.Abs(price-d.StopLoss) / price * 100
+	if stopPct <= 0 {
+		return
+	}
+
+	leverage := int(lossPct / stopPct)
+	if leverage < 1 {
+		leverage = 1
+	}
+	if policy.MaxLeverage > 0 && leverage > policy.MaxLeverage {
+		leverage = policy.MaxLeverage
+	}
+	if leverage == d.Leverage {
+		return
+	}
+
+	margin := d.PositionSizeUSD / float64(d.Leverage)
+	size := margin * float64(leverage)
+	log.Printf("  🎯 %s 止损距离%.2f%%，止损亏损保证金%.0f%%: 杠杆%dx → %dx，仓位%.2f → %.2f USDT（保证金%.2f USDT，止损亏损%.1f%%）",
+		d.Symbol, stopPct, lossPct, d.Leverage, leverage, d.PositionSizeUSD, size, margin, stopPct*float64(leverage))
+	d.Leverage = leverage
+	d.PositionSizeUSD = size
+	actionRecord.Leverage = leverage
+}
+
+// applyLeveragePolicy 下单前按策略校验并调整AI请求的杠杆
+// 依次应用：按止损距离推导杠杆（启用时）→ 币种杠杆上限 → 交易所档位上限（按仓位名义价值）；调整后同步到决策记录
+func (at *AutoTrader) applyLeveragePolicy(d *decision.Decision, actionRecord *logger.DecisionAction) error {
+	policy := at.config.LeveragePolicy.For(d.Symbol)
+	at.applyStopDerivedLeverage(d, policy, actionRecord)
+
+	requested := d.Leverage
+	leverage := requested
+	if policy.MaxLeverage > 0 && leverage > policy.MaxLeverage {
+		leverage = policy.MaxLeverage
+	}
+
+	if provider, ok := at.trader.(LeverageBracketProvider); ok {
+		brackets, err := provider.GetLeverageBrackets(d.Symbol)
+		if err != nil {
+			log.Printf("  ⚠️  获取%s杠杆档位失败: %v（跳过档位校验）", d.Symbol, err)
+		} else if len(brackets) > 0 {
+			maxLev := maxLeverageForNotional(brackets, d.PositionSizeUSD)
+			if maxLev == 0 {
+				return fmt.Errorf("❌ %s 仓位名义价值%.2f USDT超出交易所最高档位（1x最多%.0f USDT）",
+					d.Symbol, d.PositionSizeUSD, maxNotionalForLeverage(brackets, 1))
+			}
+			if leverage > maxLev {
+				log.Printf("  🎚️  %s 名义价值%.2f USDT超出%dx档位上限%.0f USDT，降档到%dx",
+					d.Symbol, d.PositionSizeUSD, leverage, maxNotionalForLeverage(brackets, leverage), maxLev)
+				leverage = maxLev
+			}
+		}
+	}
+
+	if leverage < 1 {
+		leverage = 1
+	}
+	if leverage != requested {
+		log.Printf("  🎚️  %s 杠杆按策略调整: %dx → %dx（%s，仓位%.2f USDT）",
+			d.Symbol, requested, leverage, policy.MarginMode, d.PositionSizeUSD)
+		d.Leverage = leverage
+		actionRecord.Leverage = leverage
+	}
+	return nil
+}
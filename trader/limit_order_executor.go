@@ -10,6 +10,14 @@ import (
 	"time"
 )
 
+// LimitOrderTrader 支持限价单模式的交易器（币安、Aster）
+type LimitOrderTrader interface {
+	PlaceLimitOrder(symbol string, side OrderSide, price, quantity float64, leverage int, clientOrderID string) (*OrderResult, error)
+	CancelLimitOrder(symbol string, orderID int64) error
+	GetOrderStatus(symbol string, orderID int64) (*OrderResult, error)
+	GetOpenOrders(symbol string) ([]OrderResult, error)
+}
+
 // executeOpenLimitOrderWithRecord 执行限价单开仓（智能管理已有订单）
 func (at *AutoTrader) executeOpenLimitOrderWithRecord(d *decision.Decision, actionRecord *logger.DecisionAction) error {
 	log.Printf("  📝 限价单模式: %s @ %.4f (当前价 %.4f)",
@@ -20,17 +28,11 @@ func (at *AutoTrader) executeOpenLimitOrderWithRecord(d *decision.Decision, acti
 		binanceTrader.InvalidatePositionsCache()
 	}
 
-	// 🛡️ 硬约束检查（冷却期、日交易上限、小时上限、最大持仓数量）
 	positions, err := at.trader.GetPositions()
 	if err != nil {
 		return fmt.Errorf("获取持仓失败: %w", err)
 	}
 
-	if err := at.constraints.CanOpenPosition(d.Symbol, len(positions)); err != nil {
-		log.Printf("  ⚠️  硬约束拦截: %v", err)
-		return fmt.Errorf("硬约束拦截: %w", err)
-	}
-
 	// 确定目标方向
 	targetSide := ""
 	if d.Action == "open_long" {
@@ -39,85 +41,16 @@ func (at *AutoTrader) executeOpenLimitOrderWithRecord(d *decision.Decision, acti
 		targetSide = "short"
 	}
 
-	// 🆕 同方向单仓位限制：检查是否已有其他币种的同方向持仓
-	for _, pos := range positions {
-		if pos["symbol"] != d.Symbol && pos["side"] == targetSide {
-			existingSymbol := pos["symbol"].(string)
-			directionName := "多仓"
-			if targetSide == "short" {
-				directionName = "空仓"
-			}
-			return fmt.Errorf("❌ 同方向只能持有一个币种：已有%s%s，拒绝开%s%s。如需换仓，请先平掉%s",
-				existingSymbol, directionName, d.Symbol, directionName, existingSymbol)
-		}
-	}
-
-	// ⚠️ 检查是否已有同币种同方向持仓，如果有则拒绝（防止仓位叠加）
-	for _, pos := range positions {
-		if pos["symbol"] == d.Symbol && pos["side"] == targetSide {
-			return fmt.Errorf("❌ %s 已有%s仓，拒绝下限价单以防止仓位叠加", d.Symbol, targetSide)
-		}
+	// 🚪 开仓检查（与市价开仓相同，以限价作为入场价）
+	if _, err := at.checkOpenGates(d, targetSide, positions, nil, actionRecord); err != nil {
+		return err
 	}
 
-	// ✅ 检查保证金是否充足
-	balance, err := at.trader.GetBalance()
-	if err != nil {
-		return fmt.Errorf("获取账户余额失败: %w", err)
-	}
-	availableBalance := 0.0
-	totalEquity := 0.0
-	if avail, ok := balance["availableBalance"].(float64); ok {
-		availableBalance = avail
-	}
-	if equity, ok := balance["totalWalletBalance"].(float64); ok {
-		totalEquity = equity
-	}
-
-	// 计算当前总已用保证金
-	totalMarginUsed := 0.0
-	for _, pos := range positions {
-		positionAmt := 0.0
-		markPrice := 0.0
-		leverage := 1
-
-		if amt, ok := pos["positionAmt"].(float64); ok {
-			positionAmt = amt
-			if positionAmt < 0 {
-				positionAmt = -positionAmt
-			}
-		}
-		if price, ok := pos["markPrice"].(float64); ok {
-			markPrice = price
-		}
-		if lev, ok := pos["leverage"].(float64); ok {
-			leverage = int(lev)
-		}
-
-		if leverage > 0 && markPrice > 0 {
-			positionValue := positionAmt * markPrice
-			marginForThisPosition := positionValue / float64(leverage)
-			totalMarginUsed += marginForThisPosition
-		}
+	// 👁️ 观察模式：风控检查已通过，只记录假设的限价单（含止损止盈）
+	if at.config.ObserveMode {
+		return at.recordHypotheticalExecution(d, actionRecord, d.LimitPrice, d.PositionSizeUSD/d.LimitPrice)
 	}
 
-	requiredMargin := d.PositionSizeUSD / float64(d.Leverage)
-	newTotalMarginUsed := totalMarginUsed + requiredMargin
-	marginUtilizationRate := 0.0
-	if totalEquity > 0 {
-		marginUtilizationRate = (newTotalMarginUsed / totalEquity) * 100
-	}
-
-	if marginUtilizationRate > 90.0 {
-		return fmt.Errorf("❌ 总保证金使用率将超过90%%限制: 当前%.2f%% + 新仓位%.2f USDT = %.2f%%",
-			(totalMarginUsed/totalEquity)*100, requiredMargin, marginUtilizationRate)
-	}
-
-	if requiredMargin > availableBalance {
-		return fmt.Errorf("❌ 可用保证金不足: 需要%.2f USDT, 可用%.2f USDT", requiredMargin, availableBalance)
-	}
-	log.Printf("  💰 保证金检查通过: 需要%.2f USDT, 可用%.2f USDT, 总使用率%.1f%%",
-		requiredMargin, availableBalance, marginUtilizationRate)
-
 	// 1️⃣ 检查是否已有限价单
 	existingOrder, hasOrder := at.orderManager.GetOrder(d.Symbol)
 
@@ -146,13 +79,13 @@ func (at *AutoTrader) executeOpenLimitOrderWithRecord(d *decision.Decision, acti
 		// 需要更新：取消旧订单
 		log.Printf("  🔄 限价单需要更新: %s (原因: %s)", d.Symbol, reason)
 
-		binanceTrader, ok := at.trader.(*FuturesTrader)
+		limitTrader, ok := at.trader.(LimitOrderTrader)
 		if !ok {
-			return fmt.Errorf("限价单仅支持币安交易")
+			return fmt.Errorf("当前交易所不支持限价单")
 		}
 
 		orderID, _ := strconv.ParseInt(existingOrder.OrderID, 10, 64)
-		if err := binanceTrader.CancelLimitOrder(d.Symbol, orderID); err != nil {
+		if err := limitTrader.CancelLimitOrder(d.Symbol, orderID); err != nil {
 			log.Printf("  ⚠️  取消旧限价单失败: %v (将继续下新单)", err)
 		}
 
@@ -160,9 +93,9 @@ func (at *AutoTrader) executeOpenLimitOrderWithRecord(d *decision.Decision, acti
 	}
 
 	// 3️⃣ 下新的限价单
-	binanceTrader, ok := at.trader.(*FuturesTrader)
+	limitTrader, ok := at.trader.(LimitOrderTrader)
 	if !ok {
-		return fmt.Errorf("限价单仅支持币安交易")
+		return fmt.Errorf("当前交易所不支持限价单")
 	}
 
 	// 计算数量
@@ -177,12 +110,14 @@ func (at *AutoTrader) executeOpenLimitOrderWithRecord(d *decision.Decision, acti
 	}
 
 	// 下单
-	order, err := binanceTrader.PlaceLimitOrder(
+	actionRecord.ClientOrderID = at.clientOrderID(d.Symbol, d.Action, actionRecord.Strategy)
+	order, err := limitTrader.PlaceLimitOrder(
 		d.Symbol,
 		side,
 		d.LimitPrice,
 		quantity,
 		d.Leverage,
+		actionRecord.ClientOrderID,
 	)
 	if err != nil {
 		return fmt.Errorf("下限价单失败: %w", err)
@@ -190,7 +125,7 @@ func (at *AutoTrader) executeOpenLimitOrderWithRecord(d *decision.Decision, acti
 
 	// 4️⃣ 记录到订单管理器
 	limitOrder := &LimitOrder{
-		OrderID:     fmt.Sprintf("%v", order["orderId"]),
+		OrderID:     strconv.FormatInt(order.OrderID, 10),
 		Symbol:      d.Symbol,
 		Side:        side,
 		Price:       d.LimitPrice,
@@ -203,16 +138,19 @@ func (at *AutoTrader) executeOpenLimitOrderWithRecord(d *decision.Decision, acti
 		UpdateTime:  time.Now(),
 		AIDirection: aiDirection,
 		Reasoning:   d.Reasoning,
+
+		ClientOrderID: actionRecord.ClientOrderID,
+		Cycle:         at.callCount,
+		DecisionID:    actionRecord.DecisionID,
 	}
 
 	at.orderManager.AddOrder(limitOrder)
+	at.consumeCooldownOverride(d, actionRecord)
 
 	// 5️⃣ 记录到日志
 	actionRecord.Quantity = quantity
 	actionRecord.Price = d.LimitPrice
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
-	}
+	actionRecord.OrderID = order.OrderID
 
 	// 计算回调百分比（限价相对当前价的偏离）
 	pullbackPct := 0.0
@@ -238,9 +176,9 @@ func (at *AutoTrader) RecoverMissingStopLoss() error {
 		return fmt.Errorf("获取持仓失败: %w", err)
 	}
 
-	binanceTrader, ok := at.trader.(*FuturesTrader)
+	limitTrader, ok := at.trader.(LimitOrderTrader)
 	if !ok {
-		return fmt.Errorf("恢复功能仅支持币安交易")
+		return fmt.Errorf("当前交易所不支持限价单恢复")
 	}
 
 	// 获取所有活跃的限价单记录
@@ -252,12 +190,9 @@ func (at *AutoTrader) RecoverMissingStopLoss() error {
 
 	recoveryCount := 0
 	for _, pos := range positions {
-		symbol := pos["symbol"].(string)
-		side := pos["side"].(string)
-		quantity := pos["positionAmt"].(float64)
-		if quantity < 0 {
-			quantity = -quantity
-		}
+		symbol := pos.Symbol
+		side := pos.Side
+		quantity := pos.Quantity
 
 		// 检查该持仓是否有限价单记录
 		order, hasOrder := orderMap[symbol]
@@ -267,7 +202,7 @@ func (at *AutoTrader) RecoverMissingStopLoss() error {
 		}
 
 		// 检查该持仓是否已有止损止盈（查询交易所）
-		hasStopLoss, err := at.checkHasStopLoss(binanceTrader, symbol, side)
+		hasStopLoss, err := at.checkHasStopLoss(limitTrader, symbol, side)
 		if err != nil {
 			log.Printf("⚠️  [%s] 检查止损状态失败: %v", symbol, err)
 			continue
@@ -315,20 +250,16 @@ func (at *AutoTrader) RecoverMissingStopLoss() error {
 }
 
 // checkHasStopLoss 检查持仓是否已有止损止盈
-func (at *AutoTrader) checkHasStopLoss(binanceTrader *FuturesTrader, symbol string, side string) (bool, error) {
+func (at *AutoTrader) checkHasStopLoss(lister openOrderLister, symbol string, side string) (bool, error) {
 	// 查询该币种的所有挂单
-	orders, err := binanceTrader.GetOpenOrders(symbol)
+	orders, err := lister.GetOpenOrders(symbol)
 	if err != nil {
 		return false, err
 	}
 
 	// 检查是否有STOP_MARKET类型的订单
 	for _, order := range orders {
-		orderType, ok := order["type"].(string)
-		if !ok {
-			continue
-		}
-		if orderType == "STOP_MARKET" || orderType == "TAKE_PROFIT_MARKET" {
+		if order.Type == "STOP_MARKET" || order.Type == "TAKE_PROFIT_MARKET" {
 			return true, nil
 		}
 	}
@@ -344,9 +275,9 @@ func (at *AutoTrader) checkAndUpdateLimitOrders() error {
 		return nil
 	}
 
-	binanceTrader, ok := at.trader.(*FuturesTrader)
+	limitTrader, ok := at.trader.(LimitOrderTrader)
 	if !ok {
-		return fmt.Errorf("限价单仅支持币安交易")
+		return fmt.Errorf("当前交易所不支持限价单")
 	}
 
 	for _, order := range activeOrders {
@@ -357,27 +288,20 @@ func (at *AutoTrader) checkAndUpdateLimitOrders() error {
 			continue
 		}
 
-		orderInfo, err := binanceTrader.GetOrderStatus(order.Symbol, orderID)
+		orderInfo, err := limitTrader.GetOrderStatus(order.Symbol, orderID)
 		if err != nil {
 			log.Printf("⚠️  查询订单状态失败: %s %s - %v", order.Symbol, order.OrderID, err)
 			continue
 		}
 
-		// 提取状态字段
-		status, ok := orderInfo["status"].(string)
-		if !ok {
-			log.Printf("⚠️  订单状态格式错误: %s %s", order.Symbol, order.OrderID)
-			continue
-		}
-
 		// 根据状态处理
-		switch status {
+		switch orderInfo.Status {
 		case "FILLED":
 			// 订单已完全成交
 			log.Printf("✅ 限价单成交: %s %s @ %.4f (数量: %.4f)",
 				order.Symbol, order.Side, order.Price, order.Quantity)
 
-			// 🆕 同方向单仓位限制：检查是否已有其他币种的同方向持仓
+			// 🆕 同方向持仓上限：成交后再次检查其他币种的同方向持仓
 			positions, err := at.trader.GetPositions()
 			if err != nil {
 				log.Printf("  ⚠️  获取持仓失败，跳过同方向检查: %v", err)
@@ -387,43 +311,34 @@ func (at *AutoTrader) checkAndUpdateLimitOrders() error {
 					targetSide = "short"
 				}
 
-				// 检查是否违反同方向单仓位规则
-				for _, pos := range positions {
-					posSymbol := pos["symbol"].(string)
-					posSide := pos["side"].(string)
+				// 🛡️ 同方向持仓数量上限（排除刚成交的这个持仓本身）
+				if err := at.checkSideLimit(order.Symbol, targetSide, positions); err != nil {
+					log.Printf("  ⚠️  %s限价单成交违反同方向持仓上限，立即平仓: %v", order.Symbol, err)
 
-					// 排除刚成交的这个持仓本身（通过symbol判断）
-					if posSymbol != order.Symbol && posSide == targetSide {
-						directionName := "多仓"
-						if targetSide == "short" {
-							directionName = "空仓"
+					// 立即平掉刚成交的仓位
+					if order.Side == OrderSideBuy {
+						closed, err := at.trader.CloseLong(order.Symbol, 0)
+						if err != nil {
+							log.Printf("  ❌ 紧急平仓失败: %v", err)
+						} else {
+							at.constraints.RecordClosePosition(order.Symbol, "long", closed.RealizedPnL)
+							log.Printf("  ✅ 已紧急平掉违规仓位: %s", order.Symbol)
 						}
-						log.Printf("  ⚠️  同方向单仓位冲突：已有%s%s，%s限价单成交违反规则，立即平仓",
-							posSymbol, directionName, order.Symbol)
-
-						// 立即平掉刚成交的仓位
-						if order.Side == OrderSideBuy {
-							_, err := at.trader.CloseLong(order.Symbol, 0)
-							if err != nil {
-								log.Printf("  ❌ 紧急平仓失败: %v", err)
-							} else {
-								log.Printf("  ✅ 已紧急平掉违规仓位: %s", order.Symbol)
-							}
+					} else {
+						closed, err := at.trader.CloseShort(order.Symbol, 0)
+						if err != nil {
+							log.Printf("  ❌ 紧急平仓失败: %v", err)
 						} else {
-							_, err := at.trader.CloseShort(order.Symbol, 0)
-							if err != nil {
-								log.Printf("  ❌ 紧急平仓失败: %v", err)
-							} else {
-								log.Printf("  ✅ 已紧急平掉违规仓位: %s", order.Symbol)
-							}
+							at.constraints.RecordClosePosition(order.Symbol, "short", closed.RealizedPnL)
+							log.Printf("  ✅ 已紧急平掉违规仓位: %s", order.Symbol)
 						}
+					}
 
-						// 从订单管理器中移除
-						at.orderManager.RemoveOrder(order.Symbol)
+					// 从订单管理器中移除
+					at.orderManager.RemoveOrder(order.Symbol)
 
-						// 跳过后续的止损止盈设置
-						goto nextOrder
-					}
+					// 跳过后续的止损止盈设置
+					goto nextOrder
 				}
 			}
 
@@ -433,6 +348,8 @@ func (at *AutoTrader) checkAndUpdateLimitOrders() error {
 				side = "short"
 			}
 			at.constraints.RecordOpenPosition(order.Symbol, side)
+			at.publishLimitFilled(order, side)
+			at.recordLimitFill(order, side)
 
 			// 记录开仓时间
 			posKey := order.Symbol + "_" + side
@@ -466,11 +383,11 @@ func (at *AutoTrader) checkAndUpdateLimitOrders() error {
 				order.Symbol, order.Side, order.Price)
 
 			// 取消剩余订单
-			if err := binanceTrader.CancelLimitOrder(order.Symbol, orderID); err != nil {
+			if err := limitTrader.CancelLimitOrder(order.Symbol, orderID); err != nil {
 				log.Printf("  ⚠️  取消剩余订单失败: %v", err)
 			}
 
-			// 🆕 同方向单仓位限制：检查是否已有其他币种的同方向持仓
+			// 🆕 同方向持仓上限：成交后再次检查其他币种的同方向持仓
 			positions, err := at.trader.GetPositions()
 			if err != nil {
 				log.Printf("  ⚠️  获取持仓失败，跳过同方向检查: %v", err)
@@ -480,43 +397,34 @@ func (at *AutoTrader) checkAndUpdateLimitOrders() error {
 					targetSide = "short"
 				}
 
-				// 检查是否违反同方向单仓位规则
-				for _, pos := range positions {
-					posSymbol := pos["symbol"].(string)
-					posSide := pos["side"].(string)
+				// 🛡️ 同方向持仓数量上限（排除刚成交的这个持仓本身）
+				if err := at.checkSideLimit(order.Symbol, targetSide, positions); err != nil {
+					log.Printf("  ⚠️  %s部分成交违反同方向持仓上限，立即平仓: %v", order.Symbol, err)
 
-					// 排除刚成交的这个持仓本身（通过symbol判断）
-					if posSymbol != order.Symbol && posSide == targetSide {
-						directionName := "多仓"
-						if targetSide == "short" {
-							directionName = "空仓"
+					// 立即平掉部分成交的仓位
+					if order.Side == OrderSideBuy {
+						closed, err := at.trader.CloseLong(order.Symbol, 0)
+						if err != nil {
+							log.Printf("  ❌ 紧急平仓失败: %v", err)
+						} else {
+							at.constraints.RecordClosePosition(order.Symbol, "long", closed.RealizedPnL)
+							log.Printf("  ✅ 已紧急平掉违规仓位: %s", order.Symbol)
 						}
-						log.Printf("  ⚠️  同方向单仓位冲突：已有%s%s，%s部分成交违反规则，立即平仓",
-							posSymbol, directionName, order.Symbol)
-
-						// 立即平掉部分成交的仓位
-						if order.Side == OrderSideBuy {
-							_, err := at.trader.CloseLong(order.Symbol, 0)
-							if err != nil {
-								log.Printf("  ❌ 紧急平仓失败: %v", err)
-							} else {
-								log.Printf("  ✅ 已紧急平掉违规仓位: %s", order.Symbol)
-							}
+					} else {
+						closed, err := at.trader.CloseShort(order.Symbol, 0)
+						if err != nil {
+							log.Printf("  ❌ 紧急平仓失败: %v", err)
 						} else {
-							_, err := at.trader.CloseShort(order.Symbol, 0)
-							if err != nil {
-								log.Printf("  ❌ 紧急平仓失败: %v", err)
-							} else {
-								log.Printf("  ✅ 已紧急平掉违规仓位: %s", order.Symbol)
-							}
+							at.constraints.RecordClosePosition(order.Symbol, "short", closed.RealizedPnL)
+							log.Printf("  ✅ 已紧急平掉违规仓位: %s", order.Symbol)
 						}
+					}
 
-						// 从订单管理器中移除
-						at.orderManager.RemoveOrder(order.Symbol)
+					// 从订单管理器中移除
+					at.orderManager.RemoveOrder(order.Symbol)
 
-						// 跳过后续的止损止盈设置
-						goto nextOrder
-					}
+					// 跳过后续的止损止盈设置
+					goto nextOrder
 				}
 			}
 
@@ -526,6 +434,8 @@ func (at *AutoTrader) checkAndUpdateLimitOrders() error {
 				side = "short"
 			}
 			at.constraints.RecordOpenPosition(order.Symbol, side)
+			at.publishLimitFilled(order, side)
+			at.recordLimitFill(order, side)
 
 			// 记录开仓时间
 			posKey := order.Symbol + "_" + side
@@ -568,7 +478,7 @@ func (at *AutoTrader) checkAndUpdateLimitOrders() error {
 			at.orderManager.RemoveOrder(order.Symbol)
 
 		default:
-			log.Printf("⚠️  未知订单状态: %s %s - 状态: %s", order.Symbol, order.OrderID, status)
+			log.Printf("⚠️  未知订单状态: %s %s - 状态: %s", order.Symbol, order.OrderID, orderInfo.Status)
 		}
 	nextOrder:
 	}
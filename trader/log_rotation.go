@@ -0,0 +1,40 @@
+package trader
+
+import (
+	"log"
+	"time"
+)
+
+// runLogRotation 🗜️ 决策日志归档：启动时执行一次，之后每天执行
+func (at *AutoTrader) runLogRotation(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		at.rotateDecisionLogs()
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rotateDecisionLogs 归档旧决策记录并清理过期归档
+func (at *AutoTrader) rotateDecisionLogs() {
+	archived, err := at.decisionLogger.ArchiveOldRecords(at.config.DecisionLogArchiveDays)
+	if err != nil {
+		log.Printf("⚠️  [%s] 决策日志归档失败: %v", at.name, err)
+	} else if archived > 0 {
+		log.Printf("🗜️ [%s] 决策日志归档完成: %d条", at.name, archived)
+	}
+
+	if at.config.DecisionLogRetentionDays > 0 {
+		if _, err := at.decisionLogger.PurgeArchives(at.config.DecisionLogRetentionDays); err != nil {
+			log.Printf("⚠️  [%s] 清理过期归档失败: %v", at.name, err)
+		}
+		if _, err := at.decisionLogger.PurgeSnapshots(at.config.DecisionLogRetentionDays); err != nil {
+			log.Printf("⚠️  [%s] 清理过期市场快照失败: %v", at.name, err)
+		}
+	}
+}
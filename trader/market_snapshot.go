@@ -0,0 +1,52 @@
+package trader
+
+import (
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/market"
+	"time"
+)
+
+// saveMarketSnapshot 📸 保存本周期执行决策的币种（及BTC大盘）的完整市场数据，没有执行任何决策时跳过
+func (at *AutoTrader) saveMarketSnapshot(record *logger.DecisionRecord, ctx *decision.Context, extended map[string]*market.ExtendedData) {
+	snapshot := &logger.MarketSnapshot{
+		CycleNumber:  record.CycleNumber,
+		Timestamp:    time.Now(),
+		MarketData:   make(map[string]*market.Data),
+		ExtendedData: make(map[string]*market.ExtendedData),
+	}
+
+	for _, action := range record.Decisions {
+		if !action.Success {
+			continue
+		}
+		if _, seen := snapshot.MarketData[action.Symbol]; seen {
+			continue
+		}
+		data, ok := ctx.MarketDataMap[action.Symbol]
+		if !ok {
+			continue
+		}
+		snapshot.Symbols = append(snapshot.Symbols, action.Symbol)
+		snapshot.MarketData[action.Symbol] = data
+		if ext := extended[action.Symbol]; ext != nil {
+			snapshot.ExtendedData[action.Symbol] = ext
+		}
+	}
+	if len(snapshot.Symbols) == 0 {
+		return
+	}
+
+	// BTC是所有决策的大盘参考
+	if btc, ok := ctx.MarketDataMap["BTCUSDT"]; ok {
+		snapshot.MarketData["BTCUSDT"] = btc
+	}
+
+	filename, err := at.decisionLogger.SaveMarketSnapshot(snapshot)
+	if err != nil {
+		log.Printf("⚠️  [%s] 保存市场快照失败: %v", at.name, err)
+		return
+	}
+	record.SnapshotFile = filename
+}
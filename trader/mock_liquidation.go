@@ -0,0 +1,107 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+const mockMarginCallRatio = 0.8 // 维持保证金占仓位权益≥80%时发出追保警告
+
+// mockMaintenanceTier 维持保证金档位（按名义价值分档，参考币安USDT合约主流币种）
+type mockMaintenanceTier struct {
+	notionalCap float64 // 档位上限（名义价值，USDT）
+	rate        float64 // 维持保证金率
+	amount      float64 // 速算额（cum）
+}
+
+var mockMaintenanceTiers = []mockMaintenanceTier{
+	{notionalCap: 50_000, rate: 0.004, amount: 0},
+	{notionalCap: 250_000, rate: 0.005, amount: 50},
+	{notionalCap: 3_000_000, rate: 0.01, amount: 1_300},
+	{notionalCap: 15_000_000, rate: 0.025, amount: 46_300},
+	{notionalCap: 30_000_000, rate: 0.05, amount: 421_300},
+	{notionalCap: 80_000_000, rate: 0.1, amount: 1_921_300},
+	{notionalCap: 0, rate: 0.125, amount: 3_921_300}, // 0=无上限
+}
+
+// maintenanceTier 名义价值所在的维持保证金档位
+func maintenanceTier(notional float64) mockMaintenanceTier {
+	for _, tier := range mockMaintenanceTiers {
+		if tier.notionalCap == 0 || notional < tier.notionalCap {
+			return tier
+		}
+	}
+	return mockMaintenanceTiers[len(mockMaintenanceTiers)-1]
+}
+
+// maintenanceMargin 仓位维持保证金 = 名义价值 × 维持保证金率 - 速算额
+func maintenanceMargin(notional float64) float64 {
+	tier := maintenanceTier(notional)
+	return notional*tier.rate - tier.amount
+}
+
+// isolatedLiquidationPrice 逐仓强平价（单向持仓）：仓位保证金 + 未实现盈亏 = 维持保证金 时的价格
+// 多仓: LP = (Q×EP - 保证金 - 速算额) / (Q × (1 - MMR))
+// 空仓: LP = (Q×EP + 保证金 + 速算额) / (Q × (1 + MMR))
+func isolatedLiquidationPrice(side string, entryPrice, quantity, margin float64) float64 {
+	if quantity <= 0 || entryPrice <= 0 {
+		return 0
+	}
+	tier := maintenanceTier(entryPrice * quantity)
+	if side == "long" {
+		lp := (quantity*entryPrice - margin - tier.amount) / (quantity * (1 - tier.rate))
+		if lp < 0 {
+			return 0
+		}
+		return lp
+	}
+	return (quantity*entryPrice + margin + tier.amount) / (quantity * (1 + tier.rate))
+}
+
+// evaluateMockLiquidation 判断价格区间[low, high]是否触及强平价
+func evaluateMockLiquidation(pos *MockPosition, high, low float64) (mockTrigger, bool) {
+	if pos.LiquidationPrice <= 0 {
+		return mockTrigger{}, false
+	}
+	if (pos.Side == "long" && low <= pos.LiquidationPrice) || (pos.Side == "short" && high >= pos.LiquidationPrice) {
+		return mockTrigger{
+			kind:   mockTriggerLiquidation,
+			reason: fmt.Sprintf("强平触发(强平价%.4f，区间%.4f~%.4f)", pos.LiquidationPrice, low, high),
+			price:  pos.LiquidationPrice,
+		}, true
+	}
+	return mockTrigger{}, false
+}
+
+// evaluateMockExit 按价格路径决定先触发的是止损止盈还是强平
+// 止损在强平价之前且未跳空越过强平价时止损先成交；其余情况（止损越过强平价、同一K线内止盈与强平并存）保守地按强平处理
+func evaluateMockExit(pos *MockPosition, open, high, low float64) (mockTrigger, bool) {
+	trig, hit := evaluateMockTrigger(pos, open, high, low)
+	liq, liquidated := evaluateMockLiquidation(pos, high, low)
+	if !liquidated {
+		return trig, hit
+	}
+
+	gapped := (pos.Side == "long" && open <= pos.LiquidationPrice) || (pos.Side == "short" && open >= pos.LiquidationPrice)
+	stopFirst := (pos.Side == "long" && pos.StopLoss > pos.LiquidationPrice) || (pos.Side == "short" && pos.StopLoss < pos.LiquidationPrice)
+	if hit && trig.kind == mockTriggerStopLoss && stopFirst && !gapped {
+		return trig, true
+	}
+	return liq, true
+}
+
+// checkMarginCall 维持保证金接近仓位权益时发出追保警告（每个持仓只警告一次，恢复后重置）
+func (t *MockTrader) checkMarginCall(pos *MockPosition) {
+	equity := pos.MarginUsed + pos.UnrealizedProfit
+	maint := maintenanceMargin(pos.MarkPrice * pos.PositionAmt)
+	if equity <= 0 || maint/equity >= mockMarginCallRatio {
+		if !pos.MarginCallWarned {
+			pos.MarginCallWarned = true
+			log.Printf("🚨 [模拟追保] %s %s | 仓位权益%.2f USDT，维持保证金%.2f USDT | 标记价%.4f 距强平价%.4f仅%.2f%%",
+				pos.Symbol, strings.ToUpper(pos.Side), equity, maint, pos.MarkPrice, pos.LiquidationPrice, liquidationDistancePct(pos))
+		}
+		return
+	}
+	pos.MarginCallWarned = false
+}
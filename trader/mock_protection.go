@@ -0,0 +1,233 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/market"
+	"strings"
+	"time"
+)
+
+const (
+	mockProtectionInterval = 5 * time.Second // 实时模式下止损止盈检查间隔
+	mockStopSlippage       = 0.0005          // 触发后以市价成交的滑点（0.05%）
+)
+
+// 触发类型
+const (
+	mockTriggerStopLoss    = "stop_loss"
+	mockTriggerTakeProfit  = "take_profit"
+	mockTriggerLiquidation = "liquidation"
+)
+
+// mockTrigger 止损/止盈/强平触发结果
+type mockTrigger struct {
+	kind   string
+	reason string
+	price  float64 // 成交价（止损止盈已含滑点）
+}
+
+// RunProtection 实时模拟交易所侧的止损止盈单：按标记价格定期检查触发（直到stopCh关闭）
+// 真实交易所的条件单不依赖决策周期，这里同样独立于GetBalance/GetPositions调用运行
+func (t *MockTrader) RunProtection(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(mockProtectionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			t.mu.Lock()
+			t.checkProtectiveOrders()
+			t.mu.Unlock()
+		}
+	}
+}
+
+// ReplayKline 回测：用一根K线驱动该币种持仓的止损止盈（按最高/最低价判断盘中触发）
+// 同一根K线内止损和止盈都可能触发时保守地按止损处理；跳空越过触发价时按开盘价成交
+func (t *MockTrader) ReplayKline(symbol string, k market.Kline) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, pos := range t.positions {
+		if pos.Symbol != symbol {
+			continue
+		}
+		if t.fillLadderLevels(key, pos, k.Open, k.High, k.Low) {
+			continue
+		}
+		if trig, ok := evaluateMockExit(pos, k.Open, k.High, k.Low); ok {
+			t.closeTriggered(key, pos, trig)
+			continue
+		}
+		t.setMarkPrice(pos, k.Close)
+		t.checkMarginCall(pos)
+		t.applyTrailingStop(pos)
+	}
+}
+
+// checkProtectiveOrders 按最新标记价格更新移动止损并执行触发的止损止盈/强平（调用方持有写锁）
+func (t *MockTrader) checkProtectiveOrders() {
+	for key, pos := range t.positions {
+		t.updatePositionMarkPrice(pos)
+		t.applyTrailingStop(pos)
+		if t.fillLadderLevels(key, pos, pos.MarkPrice, pos.MarkPrice, pos.MarkPrice) {
+			continue
+		}
+		if trig, ok := evaluateMockExit(pos, pos.MarkPrice, pos.MarkPrice, pos.MarkPrice); ok {
+			t.closeTriggered(key, pos, trig)
+			continue
+		}
+		t.checkMarginCall(pos)
+	}
+}
+
+// evaluateMockTrigger 判断价格区间[low, high]是否触发止损/止盈（open用于跳空成交价）
+func evaluateMockTrigger(pos *MockPosition, open, high, low float64) (mockTrigger, bool) {
+	if pos.Side == "long" {
+		// 做多：价格跌破止损 或 涨过止盈
+		if pos.StopLoss > 0 && low <= pos.StopLoss {
+			fill := pos.StopLoss
+			if open < fill {
+				fill = open // 跳空低开，按开盘价成交
+			}
+			return mockTrigger{
+				kind:   mockTriggerStopLoss,
+				reason: fmt.Sprintf("止损触发(最低%.4f ≤ 止损%.4f)", low, pos.StopLoss),
+				price:  fill * (1 - mockStopSlippage),
+			}, true
+		}
+		if pos.TakeProfit > 0 && high >= pos.TakeProfit {
+			fill := pos.TakeProfit
+			if open > fill {
+				fill = open
+			}
+			return mockTrigger{
+				kind:   mockTriggerTakeProfit,
+				reason: fmt.Sprintf("止盈触发(最高%.4f ≥ 止盈%.4f)", high, pos.TakeProfit),
+				price:  fill * (1 - mockStopSlippage),
+			}, true
+		}
+		return mockTrigger{}, false
+	}
+
+	// 做空：价格涨破止损 或 跌过止盈
+	if pos.StopLoss > 0 && high >= pos.StopLoss {
+		fill := pos.StopLoss
+		if open > fill {
+			fill = open // 跳空高开，按开盘价成交
+		}
+		return mockTrigger{
+			kind:   mockTriggerStopLoss,
+			reason: fmt.Sprintf("止损触发(最高%.4f ≥ 止损%.4f)", high, pos.StopLoss),
+			price:  fill * (1 + mockStopSlippage),
+		}, true
+	}
+	if pos.TakeProfit > 0 && low <= pos.TakeProfit {
+		fill := pos.TakeProfit
+		if open < fill {
+			fill = open
+		}
+		return mockTrigger{
+			kind:   mockTriggerTakeProfit,
+			reason: fmt.Sprintf("止盈触发(最低%.4f ≤ 止盈%.4f)", low, pos.TakeProfit),
+			price:  fill * (1 + mockStopSlippage),
+		}, true
+	}
+	return mockTrigger{}, false
+}
+
+// setMarkPrice 更新标记价格和未实现盈亏
+func (t *MockTrader) setMarkPrice(pos *MockPosition, markPrice float64) {
+	pos.MarkPrice = markPrice
+	if pos.Side == "long" {
+		pos.UnrealizedProfit = (pos.MarkPrice - pos.EntryPrice) * pos.PositionAmt
+	} else {
+		pos.UnrealizedProfit = (pos.EntryPrice - pos.MarkPrice) * pos.PositionAmt
+	}
+}
+
+// applyTrailingStop 移动止损（盈利达到阶梯后把止损移动到上一阶梯，只朝有利方向移动）
+// 0-5%: 每2%移动一次 (2%→锁定0%, 4%→锁定2%)
+// 5-10%: 每1.5%移动一次 (5.5%→锁定4%, 7%→锁定5.5%, 8.5%→锁定7%)
+// 10%+: 每1%移动一次 (10%→锁定8.5%, 11%→锁定9.5%, 12%→锁定10.5%)
+func (t *MockTrader) applyTrailingStop(pos *MockPosition) {
+	if pos.StopLoss <= 0 || pos.MarginUsed <= 0 {
+		return
+	}
+	if t.skipTrailing != nil && t.skipTrailing(pos.Symbol, pos.Side) {
+		return // 🙋 人工管理的持仓不移动止损
+	}
+	profitPct := (pos.UnrealizedProfit / pos.MarginUsed) * 100
+	if profitPct < 2.0 { // 盈利2%才开始触发
+		return
+	}
+
+	var lockedProfitPct float64
+	if profitPct < 5.0 {
+		stageLevel := int(profitPct / 2.0)              // 2.x%→1, 4.x%→2
+		lockedProfitPct = float64((stageLevel - 1) * 2) // 锁定前一阶梯
+	} else if profitPct < 10.0 {
+		stageLevel := int((profitPct - 5.0) / 1.5)
+		lockedProfitPct = 4.0 + float64(stageLevel)*1.5
+	} else {
+		stageLevel := int(profitPct - 10.0)
+		lockedProfitPct = 8.5 + float64(stageLevel)*1.0
+	}
+
+	var newStopLoss float64
+	if pos.Side == "long" {
+		newStopLoss = pos.EntryPrice * (1.0 + lockedProfitPct*0.01)
+	} else {
+		newStopLoss = pos.EntryPrice * (1.0 - lockedProfitPct*0.01)
+	}
+
+	if (pos.Side == "long" && newStopLoss > pos.StopLoss) || (pos.Side == "short" && newStopLoss < pos.StopLoss) {
+		oldStopLoss := pos.StopLoss
+		pos.StopLoss = newStopLoss
+		log.Printf("📈 [移动止损] %s %s | 盈利%.1f%% | 止损 %.4f → %.4f | 锁定%.1f%%利润",
+			pos.Symbol, strings.ToUpper(pos.Side), profitPct, oldStopLoss, newStopLoss, lockedProfitPct)
+		if t.onStopMoved != nil {
+			t.onStopMoved(pos.Symbol, pos.Side, oldStopLoss, newStopLoss)
+		}
+	}
+}
+
+// closeTriggered 以触发成交价平仓（扣除平仓手续费），调用方持有写锁
+// 强平时逐仓保证金全部损失（超出部分由保险基金承担，不会穿仓）
+func (t *MockTrader) closeTriggered(key string, pos *MockPosition, trig mockTrigger) {
+	t.setMarkPrice(pos, trig.price)
+
+	closeCommission := trig.price * pos.PositionAmt * mockTakerFeeRate
+	realizedPnL := pos.UnrealizedProfit - closeCommission
+	if trig.kind == mockTriggerLiquidation {
+		closeCommission = 0
+		realizedPnL = -pos.MarginUsed
+	}
+
+	t.totalBalance += realizedPnL
+	t.availableBalance += pos.MarginUsed + realizedPnL
+	delete(t.positions, key)
+	t.orderIDCounter++
+
+	icon := "🎯"
+	if trig.kind == mockTriggerLiquidation {
+		icon = "💥"
+	}
+	log.Printf("%s [自动平仓] %s %s | %s | 入场%.4f → 成交%.4f | 净盈亏%+.2f USDT（平仓费%.4f）",
+		icon, pos.Symbol, strings.ToUpper(pos.Side), trig.reason,
+		pos.EntryPrice, trig.price, realizedPnL, closeCommission)
+}
+
+// liquidationDistancePct 标记价格到强平价的距离（%）
+func liquidationDistancePct(pos *MockPosition) float64 {
+	if pos.MarkPrice <= 0 || pos.LiquidationPrice <= 0 {
+		return 0
+	}
+	if pos.Side == "long" {
+		return (pos.MarkPrice - pos.LiquidationPrice) / pos.MarkPrice * 100
+	}
+	return (pos.LiquidationPrice - pos.MarkPrice) / pos.MarkPrice * 100
+}
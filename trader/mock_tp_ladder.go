@@ -0,0 +1,98 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// mockLadderLevel 模拟的只减仓止盈单
+type mockLadderLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+// SetPartialTakeProfit 🪜 设置只减仓指定数量的止盈单（模拟 - 按价格触发后部分平仓）
+func (t *MockTrader) SetPartialTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	side := "long"
+	if positionSide == "SHORT" {
+		side = "short"
+	}
+
+	pos, exists := t.positions[symbol+"_"+side]
+	if !exists {
+		return fmt.Errorf("持仓不存在: %s %s", symbol, side)
+	}
+	if quantity <= 0 {
+		return fmt.Errorf("分批止盈数量无效: %.6f", quantity)
+	}
+
+	pos.TakeProfitLadder = append(pos.TakeProfitLadder, mockLadderLevel{Price: takeProfitPrice, Quantity: quantity})
+	log.Printf("✓ [模拟] %s %s 设置分批止盈: %.4f 数量%.6f", symbol, positionSide, takeProfitPrice, quantity)
+	return nil
+}
+
+// fillLadderLevels 价格区间[low, high]触及的分批止盈按档位部分平仓（调用方持有写锁）
+// 同一区间也触及止损时不处理，交给止损（偏保守）；返回持仓是否已被全部平掉
+func (t *MockTrader) fillLadderLevels(key string, pos *MockPosition, open, high, low float64) bool {
+	if len(pos.TakeProfitLadder) == 0 {
+		return false
+	}
+	if pos.StopLoss > 0 && ((pos.Side == "long" && low <= pos.StopLoss) || (pos.Side == "short" && high >= pos.StopLoss)) {
+		return false
+	}
+
+	remaining := pos.TakeProfitLadder[:0]
+	for _, level := range pos.TakeProfitLadder {
+		hit := (pos.Side == "long" && high >= level.Price) || (pos.Side == "short" && low <= level.Price)
+		if !hit {
+			remaining = append(remaining, level)
+			continue
+		}
+
+		fill := level.Price
+		if (pos.Side == "long" && open > fill) || (pos.Side == "short" && open < fill) {
+			fill = open // 跳空越过触发价，按开盘价成交
+		}
+		if pos.Side == "long" {
+			fill *= 1 - mockStopSlippage
+		} else {
+			fill *= 1 + mockStopSlippage
+		}
+
+		reason := fmt.Sprintf("分批止盈触发(止盈%.4f)", level.Price)
+		if level.Quantity >= pos.PositionAmt*0.999 {
+			t.closeTriggered(key, pos, mockTrigger{kind: mockTriggerTakeProfit, reason: reason, price: fill})
+			return true
+		}
+		t.reducePosition(pos, level.Quantity, fill, reason)
+	}
+	pos.TakeProfitLadder = remaining
+	return false
+}
+
+// reducePosition 以成交价部分平仓：按比例释放保证金，结算已实现盈亏（扣除平仓手续费）
+func (t *MockTrader) reducePosition(pos *MockPosition, quantity, price float64, reason string) {
+	ratio := quantity / pos.PositionAmt
+	pnl := (price - pos.EntryPrice) * quantity
+	if pos.Side == "short" {
+		pnl = -pnl
+	}
+	commission := price * quantity * mockTakerFeeRate
+	realizedPnL := pnl - commission
+	margin := pos.MarginUsed * ratio
+
+	t.totalBalance += realizedPnL
+	t.availableBalance += margin + realizedPnL
+	pos.PositionAmt -= quantity
+	pos.MarginUsed -= margin
+	pos.OpenCommission *= 1 - ratio
+	t.setMarkPrice(pos, price)
+	t.orderIDCounter++
+
+	log.Printf("🪜 [自动减仓] %s %s | %s | 入场%.4f → 成交%.4f | 平仓%.6f 剩余%.6f | 净盈亏%+.2f USDT（平仓费%.4f）",
+		pos.Symbol, strings.ToUpper(pos.Side), reason, pos.EntryPrice, price, quantity, pos.PositionAmt, realizedPnL, commission)
+}
@@ -4,31 +4,38 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
+	"net/http"
+	"nofx/ratelimit"
 	"sync"
 	"time"
 
 	"github.com/adshao/go-binance/v2/futures"
 )
 
+// mockTakerFeeRate 模拟吃单手续费率（币安USDT合约默认0.04%）
+const mockTakerFeeRate = 0.0004
+
 // MockTrader 本地模拟交易器（使用真实市场数据）
 type MockTrader struct {
 	// 模拟账户状态
-	totalBalance       float64 // 总余额
-	availableBalance   float64 // 可用余额
-	unrealizedPnL      float64 // 未实现盈亏
-	positions          map[string]*MockPosition
-	orderIDCounter     int64
-	mu                 sync.RWMutex
+	totalBalance     float64 // 总余额
+	availableBalance float64 // 可用余额
+	unrealizedPnL    float64 // 未实现盈亏
+	positions        map[string]*MockPosition
+	orderIDCounter   int64
+	mu               sync.RWMutex
 
 	// Binance客户端（仅用于获取市场数据）
 	binanceClient *futures.Client
+
+	onStopMoved  StopMovedHandler               // 📣 移动止损更新回调
+	skipTrailing func(symbol, side string) bool // 🙋 返回true时跳过移动止损（人工管理的持仓）
 }
 
 // MockPosition 模拟持仓
 type MockPosition struct {
 	Symbol           string
-	Side             string  // "long" or "short"
+	Side             string // "long" or "short"
 	PositionAmt      float64
 	EntryPrice       float64
 	MarkPrice        float64
@@ -39,12 +46,17 @@ type MockPosition struct {
 	OpenTime         time.Time
 	StopLoss         float64 // 止损价格
 	TakeProfit       float64 // 止盈价格
+	OpenCommission   float64 // 🆕 开仓手续费
+	MarginCallWarned bool    // 🆕 已发出追保警告
+
+	TakeProfitLadder []mockLadderLevel // 🪜 分批止盈单（只减仓）
 }
 
 // NewMockTrader 创建模拟交易器
 func NewMockTrader(initialBalance float64) *MockTrader {
 	// 使用Binance客户端获取真实市场数据（无需API密钥）
 	client := futures.NewClient("", "")
+	client.HTTPClient = &http.Client{Transport: ratelimit.NewTransport(nil)}
 
 	return &MockTrader{
 		totalBalance:     initialBalance,
@@ -57,154 +69,25 @@ func NewMockTrader(initialBalance float64) *MockTrader {
 }
 
 // GetBalance 获取模拟账户余额
-func (t *MockTrader) GetBalance() (map[string]interface{}, error) {
+func (t *MockTrader) GetBalance() (*Balance, error) {
 	t.mu.Lock() // ✅ 修复: 使用写锁，因为updatePositionMarkPrice会修改position
 	defer t.mu.Unlock()
 
-	// ✅ 修复: 实时计算所有持仓的未实现盈亏
+	// 🛡️ 先按最新价格执行移动止损和止损止盈触发，再汇总剩余持仓的未实现盈亏
+	t.checkProtectiveOrders()
 	totalUnrealizedPnL := 0.0
-	log.Printf("🔍 [DEBUG] GetBalance: 持仓数量=%d", len(t.positions))
-
-	// 收集需要自动平仓的持仓（止损/止盈触发）
-	positionsToClose := []struct {
-		key    string
-		symbol string
-		side   string
-		reason string
-		price  float64
-	}{}
-
-	for key, pos := range t.positions {
-		log.Printf("🔍 [DEBUG] GetBalance: 处理持仓 %s, 入场价=%.2f", key, pos.EntryPrice)
-		// 先更新标记价格
-		t.updatePositionMarkPrice(pos)
-		log.Printf("🔍 [DEBUG] GetBalance: %s 标记价=%.2f, 未实现盈亏=%.2f", key, pos.MarkPrice, pos.UnrealizedProfit)
-
-		// 移动止损逻辑（盈利每达到1%，止损移动到上一阶梯）
-		if pos.StopLoss > 0 {
-			profitPct := (pos.UnrealizedProfit / pos.MarginUsed) * 100
-			if profitPct >= 2.0 { // 方案2：盈利2%才开始触发
-				// 分阶段移动止损：
-				// 0-5%: 每2%移动一次 (2%→锁定0%, 4%→锁定2%)
-				// 5-10%: 每1.5%移动一次 (5.5%→锁定4%, 7%→锁定5.5%, 8.5%→锁定7%)
-				// 10%+: 每1%移动一次 (10%→锁定8.5%, 11%→锁定9.5%, 12%→锁定10.5%)
-				var lockedProfitPct float64
-
-				if profitPct < 5.0 {
-					// 阶段1: 0-5%盈利，每2%移动一次
-					stageLevel := int(profitPct / 2.0)        // 2.x%→1, 4.x%→2
-					lockedProfitPct = float64((stageLevel - 1) * 2) // 锁定前一阶梯
-				} else if profitPct < 10.0 {
-					// 阶段2: 5-10%盈利，每1.5%移动一次
-					exceededPct := profitPct - 5.0
-					stageLevel := int(exceededPct / 1.5)
-					lockedProfitPct = 4.0 + float64(stageLevel)*1.5
-				} else {
-					// 阶段3: 10%+盈利，每1%移动一次
-					exceededPct := profitPct - 10.0
-					stageLevel := int(exceededPct / 1.0)
-					lockedProfitPct = 8.5 + float64(stageLevel)*1.0
-				}
-
-				// 计算新止损价格
-				var newStopLoss float64
-				if pos.Side == "long" {
-					newStopLoss = pos.EntryPrice * (1.0 + lockedProfitPct*0.01)
-				} else {
-					newStopLoss = pos.EntryPrice * (1.0 - lockedProfitPct*0.01)
-				}
-
-				// 只有当新止损比旧止损更有利时才更新
-				shouldUpdate := false
-				if pos.Side == "long" && newStopLoss > pos.StopLoss {
-					shouldUpdate = true
-				} else if pos.Side == "short" && newStopLoss < pos.StopLoss {
-					shouldUpdate = true
-				}
-
-				if shouldUpdate {
-					oldStopLoss := pos.StopLoss
-					pos.StopLoss = newStopLoss
-					log.Printf("📈 [移动止损] %s %s | 盈利%.1f%% | 止损 %.2f → %.2f | 锁定%.1f%%利润",
-						pos.Symbol, strings.ToUpper(pos.Side), profitPct, oldStopLoss, newStopLoss, lockedProfitPct)
-				}
-			}
-		}
-
-		// 检查止损止盈触发（如果已设置）
-		if pos.StopLoss > 0 || pos.TakeProfit > 0 {
-			triggered := false
-			reason := ""
-
-			if pos.Side == "long" {
-				// 做多：价格跌破止损 或 涨过止盈
-				if pos.StopLoss > 0 && pos.MarkPrice <= pos.StopLoss {
-					triggered = true
-					reason = fmt.Sprintf("止损触发(价格%.2f ≤ 止损%.2f)", pos.MarkPrice, pos.StopLoss)
-				} else if pos.TakeProfit > 0 && pos.MarkPrice >= pos.TakeProfit {
-					triggered = true
-					reason = fmt.Sprintf("止盈触发(价格%.2f ≥ 止盈%.2f)", pos.MarkPrice, pos.TakeProfit)
-				}
-			} else {
-				// 做空：价格涨破止损 或 跌过止盈
-				if pos.StopLoss > 0 && pos.MarkPrice >= pos.StopLoss {
-					triggered = true
-					reason = fmt.Sprintf("止损触发(价格%.2f ≥ 止损%.2f)", pos.MarkPrice, pos.StopLoss)
-				} else if pos.TakeProfit > 0 && pos.MarkPrice <= pos.TakeProfit {
-					triggered = true
-					reason = fmt.Sprintf("止盈触发(价格%.2f ≤ 止盈%.2f)", pos.MarkPrice, pos.TakeProfit)
-				}
-			}
-
-			if triggered {
-				positionsToClose = append(positionsToClose, struct {
-					key    string
-					symbol string
-					side   string
-					reason string
-					price  float64
-				}{
-					key:    key,
-					symbol: pos.Symbol,
-					side:   pos.Side,
-					reason: reason,
-					price:  pos.MarkPrice,
-				})
-			}
-		}
-
+	for _, pos := range t.positions {
 		totalUnrealizedPnL += pos.UnrealizedProfit
 	}
-	log.Printf("🔍 [DEBUG] GetBalance: 总未实现盈亏=%.2f", totalUnrealizedPnL)
-
-	// 执行自动平仓（止损/止盈）
-	for _, closeInfo := range positionsToClose {
-		pos := t.positions[closeInfo.key]
 
-		// 计算实现盈亏
-		realizedPnL := pos.UnrealizedProfit
-
-		// 更新余额
-		t.totalBalance += realizedPnL
-		t.availableBalance += pos.MarginUsed + realizedPnL
-
-		// 删除持仓
-		delete(t.positions, closeInfo.key)
-
-		log.Printf("🎯 [自动平仓] %s %s | %s | 入场%.2f → 平仓%.2f | 盈亏%+.2f USDT",
-			closeInfo.symbol, strings.ToUpper(closeInfo.side), closeInfo.reason,
-			pos.EntryPrice, closeInfo.price, realizedPnL)
+	// TotalWalletBalance = 钱包余额（不包含未实现盈亏）
+	// Total Equity = TotalWalletBalance + TotalUnrealizedProfit (在auto_trader中计算)
+	result := &Balance{
+		TotalWalletBalance:    t.totalBalance,
+		AvailableBalance:      t.availableBalance,
+		TotalUnrealizedProfit: totalUnrealizedPnL,
 	}
 
-	// ✅ 修复: 返回正确的币安API格式
-	// totalWalletBalance = 钱包余额（不包含未实现盈亏）
-	// totalUnrealizedProfit = 未实现盈亏
-	// Total Equity = totalWalletBalance + totalUnrealizedProfit (在auto_trader中计算)
-	result := make(map[string]interface{})
-	result["totalWalletBalance"] = t.totalBalance // 钱包余额（不包含未实现盈亏）
-	result["availableBalance"] = t.availableBalance
-	result["totalUnrealizedProfit"] = totalUnrealizedPnL
-
 	log.Printf("📊 [模拟账户] 钱包余额=%.2f, 可用=%.2f, 未实现盈亏=%.2f, 净值=%.2f",
 		t.totalBalance, t.availableBalance, totalUnrealizedPnL, t.totalBalance+totalUnrealizedPnL)
 
@@ -212,29 +95,26 @@ func (t *MockTrader) GetBalance() (map[string]interface{}, error) {
 }
 
 // GetPositions 获取模拟持仓
-func (t *MockTrader) GetPositions() ([]map[string]interface{}, error) {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+func (t *MockTrader) GetPositions() ([]Position, error) {
+	t.mu.Lock() // 更新标记价格、执行止损止盈会修改持仓
+	defer t.mu.Unlock()
+
+	var result []Position
 
-	var result []map[string]interface{}
+	// 🛡️ 更新标记价格并执行止损止盈（已触发的持仓不再返回）
+	t.checkProtectiveOrders()
 
 	for _, pos := range t.positions {
-		// 更新标记价格
-		t.updatePositionMarkPrice(pos)
-
-		// 使用Binance格式的字段名（驼峰命名）以匹配auto_trader期望格式
-		posMap := map[string]interface{}{
-			"symbol":            pos.Symbol,
-			"side":              pos.Side,
-			"positionAmt":       pos.PositionAmt,       // 改为驼峰
-			"entryPrice":        pos.EntryPrice,        // 改为驼峰
-			"markPrice":         pos.MarkPrice,         // 改为驼峰
-			"unRealizedProfit":  pos.UnrealizedProfit,  // 改为驼峰
-			"leverage":          float64(pos.Leverage), // 转为float64
-			"liquidationPrice":  pos.LiquidationPrice,  // 改为驼峰
-			"marginUsed":        pos.MarginUsed,        // 保持一致
-		}
-		result = append(result, posMap)
+		result = append(result, Position{
+			Symbol:           pos.Symbol,
+			Side:             pos.Side,
+			Quantity:         pos.PositionAmt,
+			EntryPrice:       pos.EntryPrice,
+			MarkPrice:        pos.MarkPrice,
+			UnrealizedPnL:    pos.UnrealizedProfit,
+			Leverage:         pos.Leverage,
+			LiquidationPrice: pos.LiquidationPrice,
+		})
 	}
 
 	if len(result) > 0 {
@@ -256,18 +136,11 @@ func (t *MockTrader) updatePositionMarkPrice(pos *MockPosition) {
 
 	markPrice := 0.0
 	fmt.Sscanf(ticker[0].LastPrice, "%f", &markPrice)
-	pos.MarkPrice = markPrice
-
-	// 计算未实现盈亏
-	if pos.Side == "long" {
-		pos.UnrealizedProfit = (pos.MarkPrice - pos.EntryPrice) * pos.PositionAmt
-	} else {
-		pos.UnrealizedProfit = (pos.EntryPrice - pos.MarkPrice) * pos.PositionAmt
-	}
+	t.setMarkPrice(pos, markPrice)
 }
 
 // OpenPosition 开仓（模拟）
-func (t *MockTrader) OpenPosition(symbol, side string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *MockTrader) OpenPosition(symbol, side string, quantity float64, leverage int) (*OrderResult, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -290,13 +163,16 @@ func (t *MockTrader) OpenPosition(symbol, side string, quantity float64, leverag
 	positionValue := quantity * entryPrice
 	marginUsed := positionValue / float64(leverage)
 
+	// 🆕 模拟开仓手续费（按名义价值计算）
+	commission := positionValue * mockTakerFeeRate
+
 	// 检查可用余额
-	if marginUsed > t.availableBalance {
-		return nil, fmt.Errorf("可用余额不足: 需要%.2f, 可用%.2f", marginUsed, t.availableBalance)
+	if marginUsed+commission > t.availableBalance {
+		return nil, fmt.Errorf("可用余额不足: 需要%.2f, 可用%.2f", marginUsed+commission, t.availableBalance)
 	}
 
 	// 计算强平价
-	liquidationPrice := t.calculateLiquidationPrice(entryPrice, side, leverage)
+	liquidationPrice := isolatedLiquidationPrice(side, entryPrice, quantity, marginUsed)
 
 	// 创建持仓
 	pos := &MockPosition{
@@ -310,28 +186,33 @@ func (t *MockTrader) OpenPosition(symbol, side string, quantity float64, leverag
 		LiquidationPrice: liquidationPrice,
 		MarginUsed:       marginUsed,
 		OpenTime:         time.Now(),
+		OpenCommission:   commission,
 	}
 
 	t.positions[key] = pos
-	t.availableBalance -= marginUsed
+	t.availableBalance -= marginUsed + commission
+	t.totalBalance -= commission
 
 	t.orderIDCounter++
 
 	log.Printf("✅ [模拟开仓] %s %s | 数量:%.4f | 价格:%.2f | 杠杆:%dx | 保证金:%.2f",
 		symbol, side, quantity, entryPrice, leverage, marginUsed)
 
-	return map[string]interface{}{
-		"orderId":  t.orderIDCounter, // 修复: 与binance_futures.go保持一致，使用驼峰式
-		"symbol":   symbol,
-		"side":     side,
-		"quantity": quantity,
-		"price":    entryPrice,
-		"leverage": leverage,
+	return &OrderResult{
+		OrderID:     t.orderIDCounter,
+		Symbol:      symbol,
+		Status:      "FILLED",
+		Side:        mockOrderSide(side, true),
+		Type:        "MARKET",
+		OrigQty:     quantity,
+		ExecutedQty: quantity,
+		AvgPrice:    entryPrice,
+		Commission:  commission,
 	}, nil
 }
 
 // ClosePosition 平仓（模拟）
-func (t *MockTrader) ClosePosition(symbol, side string) (map[string]interface{}, error) {
+func (t *MockTrader) ClosePosition(symbol, side string) (*OrderResult, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -344,8 +225,10 @@ func (t *MockTrader) ClosePosition(symbol, side string) (map[string]interface{},
 	// 更新最终标记价格
 	t.updatePositionMarkPrice(pos)
 
-	// 计算实现盈亏
-	realizedPnL := pos.UnrealizedProfit
+	// 计算实现盈亏（🆕 扣除平仓手续费，开仓手续费开仓时已从余额扣除）
+	grossPnL := pos.UnrealizedProfit
+	closeCommission := pos.MarkPrice * pos.PositionAmt * mockTakerFeeRate
+	realizedPnL := grossPnL - closeCommission
 
 	// 🔍 DEBUG: 平仓前的状态
 	log.Printf("🔍 [DEBUG ClosePosition] 平仓前: totalBalance=%.2f, availableBalance=%.2f, marginUsed=%.2f, realizedPnL=%.2f",
@@ -377,15 +260,30 @@ func (t *MockTrader) ClosePosition(symbol, side string) (map[string]interface{},
 	log.Printf("✅ [模拟平仓] %s %s | 入场:%.2f → 平仓:%.2f | 盈亏:%+.2f USDT",
 		symbol, side, pos.EntryPrice, closePrice, realizedPnL)
 
-	return map[string]interface{}{
-		"order_id":      t.orderIDCounter,
-		"symbol":        symbol,
-		"side":          side,
-		"close_price":   closePrice,
-		"realized_pnl":  realizedPnL,
+	return &OrderResult{
+		OrderID:        t.orderIDCounter,
+		Symbol:         symbol,
+		Status:         "FILLED",
+		Side:           mockOrderSide(side, false),
+		Type:           "MARKET",
+		OrigQty:        pos.PositionAmt,
+		ExecutedQty:    pos.PositionAmt,
+		AvgPrice:       closePrice,
+		GrossPnL:       grossPnL,
+		Commission:     closeCommission,
+		OpenCommission: pos.OpenCommission,
+		RealizedPnL:    realizedPnL - pos.OpenCommission, // 净盈亏（含开仓手续费）
 	}, nil
 }
 
+// mockOrderSide 持仓方向对应的订单方向（开多/平空为BUY）
+func mockOrderSide(side string, open bool) string {
+	if (side == "long") == open {
+		return "BUY"
+	}
+	return "SELL"
+}
+
 // SetLeverage 设置杠杆（模拟）
 func (t *MockTrader) SetLeverage(symbol string, leverage int) error {
 	log.Printf("✓ [模拟] 设置%s杠杆为%dx", symbol, leverage)
@@ -422,6 +320,11 @@ func (t *MockTrader) SetStopLoss(symbol string, positionSide string, quantity, s
 		return fmt.Errorf("持仓不存在: %s %s", symbol, side)
 	}
 
+	// ⚠️ 止损在强平价之外：真实交易所会先强平，止损形同虚设
+	if (side == "long" && stopPrice <= pos.LiquidationPrice) || (side == "short" && stopPrice >= pos.LiquidationPrice) {
+		log.Printf("⚠️  [模拟] %s %s 止损%.4f越过强平价%.4f，将先触发强平", symbol, positionSide, stopPrice, pos.LiquidationPrice)
+	}
+
 	pos.StopLoss = stopPrice
 	log.Printf("✓ [模拟] %s %s 设置止损: %.4f", symbol, positionSide, stopPrice)
 	return nil
@@ -462,32 +365,21 @@ func (t *MockTrader) FormatQuantity(symbol string, quantity float64) (string, er
 }
 
 // OpenLong 开多仓（接口方法）
-func (t *MockTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *MockTrader) OpenLong(symbol string, quantity float64, leverage int) (*OrderResult, error) {
 	return t.OpenPosition(symbol, "long", quantity, leverage)
 }
 
 // OpenShort 开空仓（接口方法）
-func (t *MockTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *MockTrader) OpenShort(symbol string, quantity float64, leverage int) (*OrderResult, error) {
 	return t.OpenPosition(symbol, "short", quantity, leverage)
 }
 
 // CloseLong 平多仓（接口方法，quantity=0表示全部平仓）
-func (t *MockTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *MockTrader) CloseLong(symbol string, quantity float64) (*OrderResult, error) {
 	return t.ClosePosition(symbol, "long")
 }
 
 // CloseShort 平空仓（接口方法，quantity=0表示全部平仓）
-func (t *MockTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *MockTrader) CloseShort(symbol string, quantity float64) (*OrderResult, error) {
 	return t.ClosePosition(symbol, "short")
 }
-
-// calculateLiquidationPrice 计算强平价
-func (t *MockTrader) calculateLiquidationPrice(entryPrice float64, side string, leverage int) float64 {
-	// 简化计算：强平价 = 入场价 ± (入场价 / 杠杆 * 0.9)
-	margin := entryPrice / float64(leverage) * 0.9
-
-	if side == "long" {
-		return entryPrice - margin
-	}
-	return entryPrice + margin
-}
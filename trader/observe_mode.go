@@ -0,0 +1,25 @@
+package trader
+
+import (
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+)
+
+// recordHypotheticalExecution 观察模式下记录假设执行（不下单）
+// 记录假设的成交价、数量以及本应设置的止损止盈，用于事后评估决策质量
+func (at *AutoTrader) recordHypotheticalExecution(d *decision.Decision, actionRecord *logger.DecisionAction, price, quantity float64) error {
+	actionRecord.Hypothetical = true
+	actionRecord.Price = price
+	actionRecord.Quantity = quantity
+	actionRecord.StopLoss = d.StopLoss
+	actionRecord.TakeProfit = d.TakeProfit
+
+	if d.Action == "open_long" || d.Action == "open_short" {
+		log.Printf("  👁️ [观察模式] 假设%s %s | 价格%.4f 数量%.4f 杠杆%dx | 止损%.4f 止盈%.4f（未下单）",
+			d.Action, d.Symbol, price, quantity, d.Leverage, d.StopLoss, d.TakeProfit)
+	} else {
+		log.Printf("  👁️ [观察模式] 假设%s %s | 价格%.4f（未下单）", d.Action, d.Symbol, price)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/market"
+)
+
+// replacedPosition 换仓预检时假设已平掉的旧仓
+type replacedPosition struct {
+	Symbol string
+	Freed  float64 // 平仓释放的资金（保证金+未实现盈亏）
+}
+
+// checkOpenGates 🚪 开仓前的全部检查，返回入场价（市价单为当前价，限价单为限价）
+// 市价开仓、限价开仓和换仓预检共用同一套检查，新增的开仓检查统一加在这里，换仓预检自动覆盖
+// positions 为开仓时的持仓（换仓预检时已去掉旧仓），replaced 非nil时可用保证金计入旧仓释放的资金
+// 止损距离、杠杆策略、单笔风险上限会调整d（换仓预检传入副本）
+func (at *AutoTrader) checkOpenGates(d *decision.Decision, side string, positions []Position, replaced *replacedPosition, actionRecord *logger.DecisionAction) (float64, error) {
+	// 🛡️ 硬约束检查（冷却期、日交易上限、小时上限、最大持仓数量）
+	if err := at.checkOpenConstraints(d, len(positions), actionRecord); err != nil {
+		log.Printf("  ⚠️  硬约束拦截: %v", err)
+		return 0, fmt.Errorf("硬约束拦截: %w", err)
+	}
+
+	// 🛡️ 同方向持仓数量上限（默认同方向只持有一个币种）
+	if err := at.checkSideLimit(d.Symbol, side, positions); err != nil {
+		return 0, err
+	}
+
+	// ⚠️ 关键：检查是否已有同币种同方向持仓，如果有则拒绝开仓（防止仓位叠加超限）
+	if FindPosition(positions, d.Symbol, side) != nil {
+		sideName := "多"
+		if side == "short" {
+			sideName = "空"
+		}
+		return 0, fmt.Errorf("❌ %s 已有%s仓，拒绝开仓以防止仓位叠加超限。如需换仓，请先给出 close_%s 决策", d.Symbol, sideName, side)
+	}
+
+	// 入场价：限价单按限价，市价单按当前价
+	entryPrice := d.LimitPrice
+	if !d.IsLimitOrder || entryPrice <= 0 {
+		marketData, err := market.GetFrom(at.marketSource, d.Symbol)
+		if err != nil {
+			return 0, err
+		}
+		entryPrice = marketData.CurrentPrice
+	}
+
+	// 📏 止损止盈按价格精度格式化后不能离入场价太近（否则下单即触发）
+	if err := at.enforceStopDistance(d, side, entryPrice); err != nil {
+		return 0, err
+	}
+
+	// 🎚️ 杠杆策略：按止损推导杠杆 + 币种杠杆上限 + 交易所档位（可能放大仓位，需在单笔风险上限之前）
+	if err := at.applyLeveragePolicy(d, actionRecord); err != nil {
+		return 0, err
+	}
+
+	// 🛡️ 单笔风险上限（按止损距离计算美元风险，超限缩仓后再计算保证金）
+	if err := at.enforceMaxRiskPerTrade(d, entryPrice); err != nil {
+		return 0, err
+	}
+
+	// ✅ 检查可用保证金是否充足 + 总保证金使用率
+	balance, err := at.trader.GetBalance()
+	if err != nil {
+		return 0, fmt.Errorf("获取账户余额失败: %w", err)
+	}
+	quote := market.QuoteAsset(d.Symbol)
+	availableBalance := balance.AvailableFor(quote) // 🪙 单资产模式下USDT/USDC保证金独立
+	freed := 0.0
+	if replaced != nil && (balance.MarginAssets == nil || market.QuoteAsset(replaced.Symbol) == quote) {
+		freed = replaced.Freed // 🪙 单资产模式下平仓释放的是另一种保证金时不计入
+	}
+	availableBalance += freed
+	totalEquity := balance.TotalWalletBalance
+
+	// 计算当前总已用保证金（所有持仓的保证金之和）
+	totalMarginUsed := 0.0
+	for _, pos := range positions {
+		totalMarginUsed += pos.Margin() // 保证金 = (持仓价值) / 杠杆
+	}
+
+	// 计算所需保证金 = 仓位价值 / 杠杆
+	requiredMargin := d.PositionSizeUSD / float64(d.Leverage)
+
+	// 🚨 关键检查：总保证金使用率不能超过90%（硬约束）
+	newTotalMarginUsed := totalMarginUsed + requiredMargin
+	marginUtilizationRate := 0.0
+	if totalEquity > 0 {
+		marginUtilizationRate = (newTotalMarginUsed / totalEquity) * 100
+	}
+
+	if marginUtilizationRate > 90.0 {
+		return 0, fmt.Errorf("❌ 总保证金使用率将超过90%%限制: 当前%.2f%% + 新仓位%.2f USDT = %.2f%% (账户净值:%.2f USDT)",
+			(totalMarginUsed/totalEquity)*100, requiredMargin, marginUtilizationRate, totalEquity)
+	}
+
+	// 检查可用保证金
+	if requiredMargin > availableBalance {
+		if freed > 0 {
+			return 0, fmt.Errorf("❌ 可用保证金不足: 需要%.2f %s, 可用%.2f %s（含换仓平仓释放%.2f）", requiredMargin, quote, availableBalance, quote, freed)
+		}
+		return 0, fmt.Errorf("❌ 可用保证金不足: 需要%.2f %s, 可用%.2f %s", requiredMargin, quote, availableBalance, quote)
+	}
+
+	// 🛡️ 总名义敞口上限
+	if err := at.checkNotionalLimit(positions, d.PositionSizeUSD, totalEquity); err != nil {
+		return 0, err
+	}
+	log.Printf("  💰 保证金检查通过: 需要%.2f USDT, 可用%.2f USDT, 总使用率%.1f%%", requiredMargin, availableBalance, marginUtilizationRate)
+
+	// 🧐 大仓位开仓前的AI自我审查
+	if err := at.selfCritique(d, critiqueRisk{Side: side, EntryPrice: entryPrice, Equity: totalEquity, MarginUtilization: marginUtilizationRate}, actionRecord); err != nil {
+		return 0, err
+	}
+
+	// ⏱️ 市价单下单前重新获取最新价，预测后价格变动过大则放弃（限价单按限价成交，不检查）
+	if !d.IsLimitOrder {
+		if err := at.checkStalePrice(d, actionRecord); err != nil {
+			return 0, err
+		}
+	}
+	return entryPrice, nil
+}
@@ -39,21 +39,27 @@ const (
 
 // LimitOrder 限价单信息
 type LimitOrder struct {
-	OrderID      string      `json:"order_id"`      // 交易所订单ID
-	Symbol       string      `json:"symbol"`        // 交易对
-	Side         OrderSide   `json:"side"`          // 方向（BUY/SELL）
-	Price        float64     `json:"price"`         // 限价
-	Quantity     float64     `json:"quantity"`      // 数量
-	Leverage     int         `json:"leverage"`      // 杠杆
-	StopLoss     float64     `json:"stop_loss"`     // 止损价
-	TakeProfit   float64     `json:"take_profit"`   // 止盈价
-	Status       OrderStatus `json:"status"`        // 订单状态
-	FilledQty    float64     `json:"filled_qty"`    // 已成交数量
-	AvgPrice     float64     `json:"avg_price"`     // 平均成交价
-	CreateTime   time.Time   `json:"create_time"`   // 创建时间
-	UpdateTime   time.Time   `json:"update_time"`   // 更新时间
-	AIDirection  string      `json:"ai_direction"`  // AI推荐方向（up/down）
-	Reasoning    string      `json:"reasoning"`     // 开仓理由
+	OrderID     string      `json:"order_id"`     // 交易所订单ID
+	Symbol      string      `json:"symbol"`       // 交易对
+	Side        OrderSide   `json:"side"`         // 方向（BUY/SELL）
+	Price       float64     `json:"price"`        // 限价
+	Quantity    float64     `json:"quantity"`     // 数量
+	Leverage    int         `json:"leverage"`     // 杠杆
+	StopLoss    float64     `json:"stop_loss"`    // 止损价
+	TakeProfit  float64     `json:"take_profit"`  // 止盈价
+	Status      OrderStatus `json:"status"`       // 订单状态
+	FilledQty   float64     `json:"filled_qty"`   // 已成交数量
+	AvgPrice    float64     `json:"avg_price"`    // 平均成交价
+	CreateTime  time.Time   `json:"create_time"`  // 创建时间
+	UpdateTime  time.Time   `json:"update_time"`  // 更新时间
+	AIDirection string      `json:"ai_direction"` // AI推荐方向（up/down）
+	Reasoning   string      `json:"reasoning"`    // 开仓理由
+
+	ClientOrderID string `json:"client_order_id,omitempty"` // 🏷️ 客户端订单ID（带策略标签）
+
+	// 📈 下单所在周期与决策ID（成交后写入台账，平仓盈亏归因到该周期）
+	Cycle      int    `json:"cycle,omitempty"`
+	DecisionID string `json:"decision_id,omitempty"`
 }
 
 // OrderManager 订单管理器（支持持久化）
@@ -61,6 +67,10 @@ type OrderManager struct {
 	activeOrders map[string]*LimitOrder // symbol -> order
 	mu           sync.RWMutex
 	filepath     string // 🆕 持久化文件路径
+
+	// 🆕 持仓保护信息（止损止盈/开仓时间/理由），重启后用于对账恢复
+	protections    map[string]*PositionProtection // symbol_side -> protection
+	protectionPath string
 }
 
 // NewOrderManager 创建订单管理器
@@ -92,6 +102,8 @@ func NewOrderManagerWithPath(dirPath string) *OrderManager {
 		log.Printf("📂 加载限价单成功：%d个活跃订单", len(om.activeOrders))
 	}
 
+	om.loadProtections()
+
 	return om
 }
 
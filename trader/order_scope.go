@@ -0,0 +1,95 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// botOrderPrefix 🏷️ 本系统下的订单都带该前缀的客户端订单ID（开平仓见 ClientOrderID，止损止盈等挂单见 botOrderID）
+// 撤单默认只撤带前缀的订单，用户在同一账户手动下的单不受影响
+const botOrderPrefix = "nofx_"
+
+var botOrderSeq uint64
+
+// botOrderID 挂单的客户端订单ID（nofx_<kind>_<时间戳><序号>，币安限制36个字符）
+func botOrderID(kind string) string {
+	seq := atomic.AddUint64(&botOrderSeq, 1) % 1296
+	return botOrderPrefix + kind + "_" + strconv.FormatInt(time.Now().UnixNano(), 36) + strconv.FormatUint(seq, 36)
+}
+
+// IsBotOrder 是否是本系统下的订单
+func IsBotOrder(clientOrderID string) bool {
+	return strings.HasPrefix(clientOrderID, botOrderPrefix)
+}
+
+// orderScopeSetter 支持配置撤单范围的交易器
+type orderScopeSetter interface {
+	SetCancelAllOrders(all bool)
+}
+
+// SetCancelAllOrders all=true时CancelAllOrders撤掉该币种的全部挂单（包括手动下的单）
+func (t *FuturesTrader) SetCancelAllOrders(all bool) { t.cancelAllOrders = all }
+
+// SetCancelAllOrders all=true时CancelAllOrders撤掉该币种的全部挂单（包括手动下的单）
+func (t *AsterTrader) SetCancelAllOrders(all bool) { t.cancelAllOrders = all }
+
+// cancelBotOrders 只撤本系统下的挂单
+func (t *FuturesTrader) cancelBotOrders(symbol string) error {
+	orders, err := t.client.NewListOpenOrdersService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return fmt.Errorf("获取挂单失败: %w", err)
+	}
+
+	cancelled, kept := 0, 0
+	for _, order := range orders {
+		if !IsBotOrder(order.ClientOrderID) {
+			kept++
+			continue
+		}
+		if _, err := t.client.NewCancelOrderService().Symbol(symbol).OrderID(order.OrderID).Do(context.Background()); err != nil {
+			return fmt.Errorf("取消挂单失败: %w", err)
+		}
+		cancelled++
+	}
+	logCancelled(symbol, cancelled, kept)
+	return nil
+}
+
+// cancelBotOrders 只撤本系统下的挂单
+func (t *AsterTrader) cancelBotOrders(symbol string) error {
+	orders, err := t.GetOpenOrders(symbol)
+	if err != nil {
+		return err
+	}
+
+	cancelled, kept := 0, 0
+	for _, order := range orders {
+		if !IsBotOrder(order.ClientOrderID) {
+			kept++
+			continue
+		}
+		params := map[string]interface{}{
+			"symbol":  symbol,
+			"orderId": order.OrderID,
+		}
+		if _, err := t.request("DELETE", "/fapi/v3/order", params); err != nil {
+			return fmt.Errorf("取消挂单失败: %w", err)
+		}
+		cancelled++
+	}
+	logCancelled(symbol, cancelled, kept)
+	return nil
+}
+
+func logCancelled(symbol string, cancelled, kept int) {
+	if kept > 0 {
+		log.Printf("  ✓ 已取消 %s 的%d个本系统挂单（保留%d个手动挂单）", symbol, cancelled, kept)
+		return
+	}
+	log.Printf("  ✓ 已取消 %s 的%d个本系统挂单", symbol, cancelled)
+}
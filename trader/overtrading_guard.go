@@ -0,0 +1,207 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/decision/agents"
+	"nofx/logger"
+	"sync"
+	"time"
+)
+
+const (
+	defaultOvertradingProbabilityStep = 0.05
+	defaultOvertradingMinConfidence   = 80
+	defaultOvertradingCooldownFactor  = 2.0
+	maxOvertradingProbability         = 0.95
+	maxOvertradingAdjustments         = 20 // 状态API保留的最近调整记录数
+)
+
+// OvertradingGuardConfig 🚦 过度交易保护：最近N笔已平仓交易期望收益为负时提高开仓门槛、延长冷却期，期望转正后恢复
+type OvertradingGuardConfig struct {
+	WindowTrades       int     // 统计最近N笔交易（0=不启用）
+	ProbabilityStep    float64 // 触发后开仓概率阈值提高量（0=默认0.05）
+	MinConfidence      int     // 触发后AI决策的最低信心度（0=默认80）
+	CooldownMultiplier float64 // 触发后同币种冷却期倍数（0=默认2）
+}
+
+// Enabled 是否启用过度交易保护
+func (c OvertradingGuardConfig) Enabled() bool {
+	return c.WindowTrades > 0
+}
+
+func (c OvertradingGuardConfig) probabilityStep() float64 {
+	if c.ProbabilityStep > 0 {
+		return c.ProbabilityStep
+	}
+	return defaultOvertradingProbabilityStep
+}
+
+func (c OvertradingGuardConfig) minConfidence() int {
+	if c.MinConfidence > 0 {
+		return c.MinConfidence
+	}
+	return defaultOvertradingMinConfidence
+}
+
+func (c OvertradingGuardConfig) cooldownMultiplier() float64 {
+	if c.CooldownMultiplier > 0 {
+		return c.CooldownMultiplier
+	}
+	return defaultOvertradingCooldownFactor
+}
+
+// overtradingAdjustment 一次收紧/放松记录
+type overtradingAdjustment struct {
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"` // tighten/relax
+	Expectancy float64   `json:"expectancy"`
+	Trades     int       `json:"trades"`
+}
+
+// overtradingGuard 过度交易保护状态（每个周期按绩效分析更新，状态API读取）
+type overtradingGuard struct {
+	mu sync.RWMutex
+
+	active      bool
+	since       time.Time
+	evaluated   bool
+	expectancy  float64 // 最近N笔的每笔期望收益（USDT）
+	winRate     float64
+	trades      int     // 参与统计的交易数
+	tradesPer24 float64 // 最近N笔的交易频率（笔/24小时）
+	last24h     int     // 最近24小时平仓笔数
+	adjustments []overtradingAdjustment
+}
+
+// updateOvertradingGuard 按最近N笔已平仓交易的期望收益收紧或放松开仓门槛（交易数不足N笔时保持当前状态）
+func (at *AutoTrader) updateOvertradingGuard(perf *logger.PerformanceAnalysis) {
+	cfg := at.config.OvertradingGuard
+	if !cfg.Enabled() || perf == nil {
+		return
+	}
+
+	g := &at.overtrading
+	now := time.Now()
+	trades := perf.RecentTrades
+
+	g.mu.Lock()
+	g.last24h = 0
+	for _, t := range trades {
+		if now.Sub(t.CloseTime) < 24*time.Hour {
+			g.last24h++
+		}
+	}
+	if len(trades) < cfg.WindowTrades {
+		g.mu.Unlock()
+		return
+	}
+
+	window := trades[len(trades)-cfg.WindowTrades:]
+	total, wins := 0.0, 0
+	for _, t := range window {
+		total += t.PnL
+		if t.PnL > 0 {
+			wins++
+		}
+	}
+	g.evaluated = true
+	g.trades = len(window)
+	g.expectancy = total / float64(len(window))
+	g.winRate = float64(wins) / float64(len(window)) * 100
+	g.tradesPer24 = 0
+	if span := window[len(window)-1].CloseTime.Sub(window[0].CloseTime); span > 0 {
+		g.tradesPer24 = float64(len(window)-1) / span.Hours() * 24
+	}
+
+	action := ""
+	switch {
+	case !g.active && g.expectancy < 0:
+		g.active, g.since, action = true, now, "tighten"
+	case g.active && g.expectancy > 0:
+		g.active, g.since, action = false, time.Time{}, "relax"
+	}
+	if action != "" {
+		g.adjustments = append(g.adjustments, overtradingAdjustment{Time: now, Action: action, Expectancy: g.expectancy, Trades: g.trades})
+		if len(g.adjustments) > maxOvertradingAdjustments {
+			g.adjustments = g.adjustments[len(g.adjustments)-maxOvertradingAdjustments:]
+		}
+	}
+	active, expectancy, winRate, perDay := g.active, g.expectancy, g.winRate, g.tradesPer24
+	g.mu.Unlock()
+
+	switch action {
+	case "tighten":
+		at.constraints.SetCooldownMultiplier(cfg.cooldownMultiplier())
+		log.Printf("🚦 过度交易保护启动: 最近%d笔期望%+.2f USDT/笔（胜率%.0f%%，%.1f笔/天）→ 概率阈值+%.0f%%、最低信心度%d、冷却期×%.1f",
+			cfg.WindowTrades, expectancy, winRate, perDay, cfg.probabilityStep()*100, cfg.minConfidence(), cfg.cooldownMultiplier())
+	case "relax":
+		at.constraints.SetCooldownMultiplier(1)
+		log.Printf("🚦 过度交易保护解除: 最近%d笔期望回升到%+.2f USDT/笔（胜率%.0f%%），恢复正常开仓门槛", cfg.WindowTrades, expectancy, winRate)
+	default:
+		if active {
+			log.Printf("🚦 过度交易保护生效中: 最近%d笔期望%+.2f USDT/笔", cfg.WindowTrades, expectancy)
+		}
+	}
+}
+
+// overtradingActive 过度交易保护是否生效
+func (at *AutoTrader) overtradingActive() bool {
+	at.overtrading.mu.RLock()
+	defer at.overtrading.mu.RUnlock()
+	return at.overtrading.active
+}
+
+// entryTuning 本周期的开仓参数：过度交易保护生效时提高概率阈值并只接受高置信度
+func (at *AutoTrader) entryTuning() agents.EntryTuning {
+	tuning := at.config.Tuning
+	if !at.config.OvertradingGuard.Enabled() || !at.overtradingActive() {
+		return tuning
+	}
+	base := tuning.MinProbability
+	if base <= 0 {
+		base = agents.DefaultMinProbability
+	}
+	tuning.MinProbability = math.Min(base+at.config.OvertradingGuard.probabilityStep(), maxOvertradingProbability)
+	tuning.HighConfidenceOnly = true
+	return tuning
+}
+
+// checkOvertradingGuard 过度交易保护生效时拒绝信心度不足的开仓
+func (at *AutoTrader) checkOvertradingGuard(d *decision.Decision) error {
+	if !at.config.OvertradingGuard.Enabled() || !at.overtradingActive() {
+		return nil
+	}
+	if minConf := at.config.OvertradingGuard.minConfidence(); d.Confidence < minConf {
+		return fmt.Errorf("过度交易保护：近期期望收益为负，开仓要求信心度≥%d（当前%d）", minConf, d.Confidence)
+	}
+	return nil
+}
+
+// getOvertradingStatus 过度交易保护状态（状态API）
+func (at *AutoTrader) getOvertradingStatus() map[string]interface{} {
+	cfg := at.config.OvertradingGuard
+	g := &at.overtrading
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	status := map[string]interface{}{
+		"active":         g.active,
+		"window_trades":  cfg.WindowTrades,
+		"evaluated":      g.evaluated,
+		"expectancy":     g.expectancy,
+		"win_rate":       g.winRate,
+		"trades_per_24h": g.tradesPer24,
+		"closed_24h":     g.last24h,
+		"adjustments":    append([]overtradingAdjustment(nil), g.adjustments...),
+	}
+	if g.active {
+		status["since"] = g.since.Format(time.RFC3339)
+		status["min_probability"] = at.entryTuning().MinProbability
+		status["min_confidence"] = cfg.minConfidence()
+		status["cooldown_multiplier"] = cfg.cooldownMultiplier()
+	}
+	return status
+}
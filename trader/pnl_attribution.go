@@ -0,0 +1,43 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/logger"
+)
+
+// 📈 盈亏归因：开仓时在台账记下周期、决策ID和按止损计算的风险，平仓盈亏据此归因到开仓周期并换算为R
+
+// decisionID 决策ID（周期:币种:动作）
+func decisionID(cycle int, symbol, action string) string {
+	return fmt.Sprintf("%d:%s:%s", cycle, symbol, action)
+}
+
+// openRisk 开仓价到止损的亏损金额（USDT，无止损时为0）
+func openRisk(price, stopLoss, quantity float64) float64 {
+	if price <= 0 || stopLoss <= 0 || quantity <= 0 {
+		return 0
+	}
+	return math.Abs(price-stopLoss) * quantity
+}
+
+// ledgerForcedClose 非决策平仓（人工请求、组合止损、恢复对账）写入台账
+// 盈亏优先用交易所返回的已实现盈亏，否则按平仓前的持仓估算；数量未知时视为全部平仓
+func (at *AutoTrader) ledgerForcedClose(symbol, side string, order *OrderResult, pos *Position, note string) {
+	entry := logger.LedgerEntry{
+		Strategy: at.positionStrategy(symbol, side),
+		Symbol:   symbol,
+		Side:     side,
+		Action:   "close",
+		Note:     note,
+	}
+	if pos != nil {
+		entry.Quantity = pos.Quantity
+		entry.Price = pos.MarkPrice
+		entry.PnL = pos.UnrealizedPnL
+	}
+	if order != nil && order.RealizedPnL != 0 {
+		entry.PnL = order.RealizedPnL
+	}
+	at.recordLedger(entry)
+}
@@ -0,0 +1,212 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/logger"
+	"nofx/memory"
+	"sync"
+	"time"
+)
+
+// ReconcileReport 一次对账的结果
+type ReconcileReport struct {
+	Time        time.Time `json:"time"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+
+	// 交易所流水汇总（窗口内）
+	ExchangeRealizedPnL float64 `json:"exchange_realized_pnl"`
+	ExchangeFundingFee  float64 `json:"exchange_funding_fee"`
+	ExchangeCommission  float64 `json:"exchange_commission"`
+	ExchangeNetPnL      float64 `json:"exchange_net_pnl"`
+
+	// 日内净盈亏（自日盈亏重置时间起，用于校正dailyPnL）
+	DailyExchangeNetPnL float64 `json:"daily_exchange_net_pnl"`
+
+	// 逐笔核对结果
+	TradesChecked int      `json:"trades_checked"`
+	TradesFlagged int      `json:"trades_flagged"`
+	TotalDrift    float64  `json:"total_drift"` // 交易所 - 内部账本
+	Discrepancies []string `json:"discrepancies"`
+}
+
+// PnLReconciler 盈亏对账器：定期拉取交易所收益流水，与内部账本（决策日志）逐笔核对
+// 偏差超过容忍度时标记并写入修正，让性能分析和AI记忆以交易所数据为准
+type PnLReconciler struct {
+	mu             sync.Mutex
+	provider       IncomeHistoryProvider
+	decisionLogger *logger.DecisionLogger
+	memoryManager  *memory.Manager
+
+	interval     time.Duration
+	absTolerance float64 // 绝对容忍度（USDT）
+	pctTolerance float64 // 相对容忍度（占交易所净盈亏的比例）
+
+	lastRun    time.Time
+	lastReport *ReconcileReport
+}
+
+// NewPnLReconciler 创建盈亏对账器
+func NewPnLReconciler(provider IncomeHistoryProvider, decisionLogger *logger.DecisionLogger, memoryManager *memory.Manager, interval time.Duration) *PnLReconciler {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &PnLReconciler{
+		provider:       provider,
+		decisionLogger: decisionLogger,
+		memoryManager:  memoryManager,
+		interval:       interval,
+		absTolerance:   0.5,  // 0.5 USDT以内视为一致
+		pctTolerance:   0.05, // 或偏差在5%以内
+	}
+}
+
+// Due 是否到了对账时间
+func (r *PnLReconciler) Due() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Since(r.lastRun) >= r.interval
+}
+
+// LastReport 获取最近一次对账结果（用于API）
+func (r *PnLReconciler) LastReport() *ReconcileReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastReport
+}
+
+// Reconcile 执行一次对账
+// dailySince 为日盈亏统计起点，返回报告中的 DailyExchangeNetPnL 可用于校正日内盈亏
+func (r *PnLReconciler) Reconcile(dailySince time.Time) (*ReconcileReport, error) {
+	r.mu.Lock()
+	r.lastRun = time.Now()
+	r.mu.Unlock()
+
+	performance, err := r.decisionLogger.AnalyzePerformance(100)
+	if err != nil {
+		return nil, fmt.Errorf("读取内部账本失败: %w", err)
+	}
+
+	// 窗口：覆盖最近交易的开仓时间和日盈亏起点（最多回溯7天，币安收益流水查询上限）
+	now := time.Now()
+	windowStart := dailySince
+	for _, trade := range performance.RecentTrades {
+		if !trade.OpenTime.IsZero() && trade.OpenTime.Before(windowStart) {
+			windowStart = trade.OpenTime
+		}
+	}
+	if earliest := now.Add(-7 * 24 * time.Hour); windowStart.Before(earliest) {
+		windowStart = earliest
+	}
+	windowStart = windowStart.Add(-time.Minute)
+
+	incomes, err := r.provider.GetIncomeHistory(windowStart, now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconcileReport{
+		Time:          now,
+		WindowStart:   windowStart,
+		WindowEnd:     now,
+		Discrepancies: []string{},
+	}
+	for _, income := range incomes {
+		switch income.IncomeType {
+		case "REALIZED_PNL":
+			report.ExchangeRealizedPnL += income.Income
+		case "FUNDING_FEE":
+			report.ExchangeFundingFee += income.Income
+		case "COMMISSION":
+			report.ExchangeCommission += income.Income
+		}
+		if !income.Time.Before(dailySince) {
+			report.DailyExchangeNetPnL += income.Income
+		}
+	}
+	report.ExchangeNetPnL = report.ExchangeRealizedPnL + report.ExchangeFundingFee + report.ExchangeCommission
+
+	// 逐笔核对（只核对尚未修正过的交易）
+	var corrections []logger.PnLCorrection
+	for _, trade := range performance.RecentTrades {
+		if trade.Reconciled || trade.OpenTime.IsZero() || trade.CloseTime.IsZero() {
+			continue
+		}
+		// 平仓后留2分钟让流水落库
+		if now.Sub(trade.CloseTime) < 2*time.Minute {
+			continue
+		}
+
+		exchangePnL, hasRealized := sumTradeIncome(incomes, trade)
+		if !hasRealized {
+			// 交易所没有对应的已实现盈亏流水，无法核对
+			continue
+		}
+		report.TradesChecked++
+
+		drift := exchangePnL - trade.PnL
+		tolerance := math.Max(r.absTolerance, math.Abs(exchangePnL)*r.pctTolerance)
+		if math.Abs(drift) > tolerance {
+			report.TradesFlagged++
+			report.TotalDrift += drift
+			msg := fmt.Sprintf("%s %s 平仓于%s: 内部%+.4f vs 交易所%+.4f (偏差%+.4f)",
+				trade.Symbol, trade.Side, trade.CloseTime.Format("01-02 15:04"), trade.PnL, exchangePnL, drift)
+			report.Discrepancies = append(report.Discrepancies, msg)
+			log.Printf("⚠️ [对账] %s", msg)
+		}
+
+		corrections = append(corrections, logger.PnLCorrection{
+			Symbol:      trade.Symbol,
+			Side:        trade.Side,
+			CloseTime:   trade.CloseTime,
+			RecordedPnL: trade.PnL,
+			ExchangePnL: exchangePnL,
+			CorrectedAt: now,
+		})
+
+		// 修正AI记忆中的收益率（与内部记录一致：相对保证金的百分比）
+		if r.memoryManager != nil && trade.MarginUsed > 0 {
+			returnPct := exchangePnL / trade.MarginUsed * 100
+			if _, err := r.memoryManager.CorrectTradeResult(trade.Symbol, trade.Side, trade.CloseTime, returnPct); err != nil {
+				log.Printf("⚠️ [对账] 修正记忆失败: %v", err)
+			}
+		}
+	}
+
+	if err := r.decisionLogger.SetPnLCorrections(corrections); err != nil {
+		log.Printf("⚠️ [对账] 保存盈亏修正失败: %v", err)
+	}
+
+	log.Printf("🧾 [对账] 窗口%s~%s | 交易所净盈亏%+.2f (已实现%+.2f 资金费%+.2f 手续费%+.2f) | 核对%d笔，偏差%d笔",
+		windowStart.Format("01-02 15:04"), now.Format("01-02 15:04"),
+		report.ExchangeNetPnL, report.ExchangeRealizedPnL, report.ExchangeFundingFee, report.ExchangeCommission,
+		report.TradesChecked, report.TradesFlagged)
+
+	r.mu.Lock()
+	r.lastReport = report
+	r.mu.Unlock()
+
+	return report, nil
+}
+
+// sumTradeIncome 汇总单笔交易持仓期间的交易所流水（同币种，开仓前1分钟到平仓后2分钟）
+// 注意：双向持仓时同币种多空重叠无法区分，会合并计算
+func sumTradeIncome(incomes []IncomeRecord, trade logger.TradeOutcome) (float64, bool) {
+	start := trade.OpenTime.Add(-time.Minute)
+	end := trade.CloseTime.Add(2 * time.Minute)
+
+	total := 0.0
+	hasRealized := false
+	for _, income := range incomes {
+		if income.Symbol != trade.Symbol || income.Time.Before(start) || income.Time.After(end) {
+			continue
+		}
+		total += income.Income
+		if income.IncomeType == "REALIZED_PNL" {
+			hasRealized = true
+		}
+	}
+	return total, hasRealized
+}
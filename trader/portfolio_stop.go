@@ -0,0 +1,225 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"nofx/events"
+	"nofx/logger"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const defaultPortfolioStopCooloff = 24 * time.Hour
+
+// PortfolioStopConfig 🧯 组合止损：净值从历史最高点回撤超过DrawdownPct时暂停交易（可选全部平仓），
+// 与MaxDrawdown（回撤暂停提示）分开配置；冷却期结束后以当时净值重新计算高水位
+type PortfolioStopConfig struct {
+	DrawdownPct float64       // 相对高水位的回撤阈值（%，0=不启用）
+	Flatten     bool          // 触发时平掉全部持仓（否则只暂停开新仓）
+	Cooloff     time.Duration // 触发后的冷却期（0=默认24小时）
+}
+
+// Enabled 是否启用组合止损
+func (c PortfolioStopConfig) Enabled() bool {
+	return c.DrawdownPct > 0
+}
+
+// portfolioStopState 组合止损状态（持久化，重启后高水位和冷却期不丢失）
+type portfolioStopState struct {
+	HighWaterMark float64   `json:"high_water_mark"`
+	HighWaterTime time.Time `json:"high_water_time"`
+	TriggeredAt   time.Time `json:"triggered_at,omitempty"`
+	Until         time.Time `json:"until,omitempty"` // 冷却期结束时间（零值=未触发）
+	TriggerEquity float64   `json:"trigger_equity,omitempty"`
+	Flattened     int       `json:"flattened,omitempty"` // 触发时平掉的持仓数
+}
+
+// portfolioStop 组合止损（高水位追踪）
+type portfolioStop struct {
+	mu    sync.Mutex
+	path  string
+	state portfolioStopState
+}
+
+// newPortfolioStop 从 <dir>/portfolio_stop.json 加载状态
+func newPortfolioStop(dir string) *portfolioStop {
+	p := &portfolioStop{path: filepath.Join(dir, "portfolio_stop.json")}
+	if data, err := os.ReadFile(p.path); err == nil {
+		if err := json.Unmarshal(data, &p.state); err != nil {
+			log.Printf("⚠️  加载组合止损状态失败: %v", err)
+		}
+	}
+	return p
+}
+
+func (p *portfolioStop) save() {
+	data, err := json.MarshalIndent(p.state, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(p.path, data, 0644); err != nil {
+		log.Printf("⚠️  保存组合止损状态失败: %v", err)
+	}
+}
+
+// observe 用最新净值更新高水位，返回是否刚刚触发（冷却期内不重复触发）
+func (p *portfolioStop) observe(equity float64, cfg PortfolioStopConfig, now time.Time) (triggered bool, drawdownPct float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if equity <= 0 {
+		return false, 0
+	}
+
+	// 冷却期内：不更新高水位，不重复触发
+	if !p.state.Until.IsZero() {
+		if now.Before(p.state.Until) {
+			return false, 0
+		}
+		log.Printf("🧯 组合止损冷却期结束，高水位重置为当前净值 %.2f USDT", equity)
+		p.state = portfolioStopState{HighWaterMark: equity, HighWaterTime: now}
+		p.save()
+		return false, 0
+	}
+
+	if equity > p.state.HighWaterMark {
+		p.state.HighWaterMark = equity
+		p.state.HighWaterTime = now
+		p.save()
+		return false, 0
+	}
+
+	drawdownPct = (p.state.HighWaterMark - equity) / p.state.HighWaterMark * 100
+	if drawdownPct < cfg.DrawdownPct {
+		return false, drawdownPct
+	}
+
+	cooloff := cfg.Cooloff
+	if cooloff <= 0 {
+		cooloff = defaultPortfolioStopCooloff
+	}
+	p.state.TriggeredAt = now
+	p.state.Until = now.Add(cooloff)
+	p.state.TriggerEquity = equity
+	p.save()
+	return true, drawdownPct
+}
+
+// pausedUntil 冷却期结束时间（未触发或已结束返回零值）
+func (p *portfolioStop) pausedUntil(now time.Time) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if now.Before(p.state.Until) {
+		return p.state.Until
+	}
+	return time.Time{}
+}
+
+func (p *portfolioStop) setFlattened(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state.Flattened = n
+	p.save()
+}
+
+func (p *portfolioStop) snapshot() portfolioStopState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state
+}
+
+// checkPortfolioStop 🧯 组合止损检查：触发后暂停交易到冷却期结束，按配置平掉全部持仓并发布风控事件
+// 返回true表示本周期应停止（已写入决策记录）
+func (at *AutoTrader) checkPortfolioStop(equity float64, record *logger.DecisionRecord) bool {
+	cfg := at.config.PortfolioStop
+	if !cfg.Enabled() || at.portfolioStop == nil {
+		return false
+	}
+
+	now := time.Now()
+	triggered, drawdownPct := at.portfolioStop.observe(equity, cfg, now)
+	if !triggered {
+		return false
+	}
+
+	state := at.portfolioStop.snapshot()
+	if state.Until.After(at.stopUntil) {
+		at.stopUntil = state.Until
+	}
+	log.Printf("🧯 [%s] 组合止损触发: 净值%.2f 较高水位%.2f 回撤%.2f%% ≥ %.2f%%，暂停交易至 %s",
+		at.name, equity, state.HighWaterMark, drawdownPct, cfg.DrawdownPct, state.Until.Format("01-02 15:04"))
+
+	reason := fmt.Sprintf("组合止损: 净值较高水位回撤%.2f%%（阈值%.2f%%）", drawdownPct, cfg.DrawdownPct)
+	if cfg.Flatten {
+		closed, failed := at.flattenAllPositions(record, "🧯 组合止损")
+		at.portfolioStop.setFlattened(closed)
+		reason += fmt.Sprintf("，已平仓%d个", closed)
+		if failed > 0 {
+			reason += fmt.Sprintf("，%d个平仓失败", failed)
+		}
+	}
+
+	record.Success = false
+	record.ErrorMessage = reason + "，暂停交易"
+	at.events.Publish(events.RiskPausedEvent{TraderID: at.id, Reason: reason, Until: state.Until, Time: now})
+	at.decisionLogger.LogDecision(record)
+	return true
+}
+
+// flattenAllPositions 平掉全部持仓（忽略的持仓除外；观察模式只记录不下单），label 为日志中的平仓原因
+func (at *AutoTrader) flattenAllPositions(record *logger.DecisionRecord, label string) (closed, failed int) {
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		log.Printf("❌ [%s] %s获取持仓失败: %v（请手动平仓）", at.name, label, err)
+		record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s获取持仓失败: %v", label, err))
+		return 0, 0
+	}
+
+	for _, pos := range positions {
+		symbol, side := pos.Symbol, pos.Side
+		if at.ignoredPositions[symbol+"_"+side] {
+			continue
+		}
+		if at.config.ObserveMode {
+			log.Printf("👀 [%s] 观察模式：%s本应平仓 %s %s", at.name, label, symbol, side)
+			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("👀 %s（观察模式未执行）: 平仓 %s %s", label, symbol, side))
+			continue
+		}
+
+		log.Printf("%s [%s] 平仓 %s %s", label, at.name, symbol, side)
+		if err := at.closeUnknownPosition(symbol, side); err != nil {
+			failed++
+			log.Printf("  ❌ 平仓失败: %v", err)
+			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s平仓 %s %s 失败: %v", label, symbol, side, err))
+			continue
+		}
+		closed++
+		at.orderManager.RemoveProtection(symbol, side)
+		record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("%s平仓 %s %s", label, symbol, side))
+	}
+	return closed, failed
+}
+
+// getPortfolioStopStatus 组合止损状态（状态API）
+func (at *AutoTrader) getPortfolioStopStatus() map[string]interface{} {
+	if !at.config.PortfolioStop.Enabled() || at.portfolioStop == nil {
+		return nil
+	}
+	state := at.portfolioStop.snapshot()
+	status := map[string]interface{}{
+		"drawdown_pct":    at.config.PortfolioStop.DrawdownPct,
+		"flatten":         at.config.PortfolioStop.Flatten,
+		"high_water_mark": state.HighWaterMark,
+		"high_water_time": state.HighWaterTime,
+	}
+	if until := at.portfolioStop.pausedUntil(time.Now()); !until.IsZero() {
+		status["triggered_at"] = state.TriggeredAt
+		status["until"] = until
+		status["trigger_equity"] = state.TriggerEquity
+		status["flattened"] = state.Flattened
+	}
+	return status
+}
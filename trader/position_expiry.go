@@ -0,0 +1,113 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/market"
+	"time"
+)
+
+// 持仓到期（按市场体制区分最长持仓时间）
+// 由Go代码每个周期确定性执行，不依赖AI预测是否成功
+const (
+	defaultMaxHoldTrend = 24 * time.Hour // 趋势行情默认最长持仓
+	defaultMaxHoldRange = 8 * time.Hour  // 震荡行情默认最长持仓
+	expiryTrendADX      = 25.0           // ADX≥25视为趋势行情
+	expiryMinProfitPct  = 5.0            // 盈利≥5%的持仓不因到期平仓（交给止盈/AI管理）
+)
+
+// positionRegime 判断持仓币种当前的市场体制（"trend" / "range"）
+// 获取行情失败时按趋势处理（使用较长的持仓上限，避免误平仓）
+func positionRegime(symbol string) string {
+	data, err := market.Get(symbol)
+	if err != nil || data == nil {
+		return "trend"
+	}
+	if data.CurrentADX >= expiryTrendADX {
+		return "trend"
+	}
+	return "range"
+}
+
+// maxHoldDuration 该体制下的最长持仓时间（<=0表示不限制）
+func (at *AutoTrader) maxHoldDuration(regime string) time.Duration {
+	if regime == "range" {
+		if at.config.MaxHoldRange == 0 {
+			return defaultMaxHoldRange
+		}
+		return at.config.MaxHoldRange
+	}
+	if at.config.MaxHoldTrend == 0 {
+		return defaultMaxHoldTrend
+	}
+	return at.config.MaxHoldTrend
+}
+
+// enforcePositionExpiry 平掉超过最长持仓时间且未明显盈利的持仓
+// 在AI决策之前执行：已平仓的持仓从上下文中移除，平仓动作写入本周期决策记录
+func (at *AutoTrader) enforcePositionExpiry(ctx *decision.Context, record *logger.DecisionRecord) {
+	remaining := ctx.Positions[:0]
+	for _, pos := range ctx.Positions {
+		if pos.OpenTime.IsZero() || pos.UnrealizedPnLPct >= expiryMinProfitPct {
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		regime := positionRegime(pos.Symbol)
+		maxHold := at.maxHoldDuration(regime)
+		holdDuration := time.Since(pos.OpenTime)
+		if maxHold <= 0 || holdDuration <= maxHold {
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		reason := fmt.Sprintf("持仓到期: %s行情持仓%.1f小时 > %.0f小时且盈利%.2f%% < %.0f%%",
+			regime, holdDuration.Hours(), maxHold.Hours(), pos.UnrealizedPnLPct, expiryMinProfitPct)
+		log.Printf("⌛ [%s %s] %s，强制平仓", pos.Symbol, pos.Side, reason)
+
+		d := decision.Decision{
+			Symbol:    pos.Symbol,
+			Action:    "close_" + pos.Side,
+			Reasoning: reason,
+		}
+		actionRecord := logger.DecisionAction{
+			Action:    d.Action,
+			Symbol:    d.Symbol,
+			Timestamp: time.Now(),
+			Reasoning: reason,
+		}
+
+		if err := at.executeDecisionWithRecord(&d, &actionRecord); err != nil {
+			log.Printf("❌ 到期平仓失败 (%s %s): %v", pos.Symbol, pos.Side, err)
+			actionRecord.Error = err.Error()
+			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s %s 到期平仓失败: %v", d.Symbol, d.Action, err))
+			record.Decisions = append(record.Decisions, actionRecord)
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		actionRecord.Success = true
+		record.Decisions = append(record.Decisions, actionRecord)
+		if actionRecord.Hypothetical {
+			// 👁️ 观察模式：持仓仍在，保留给AI
+			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("👁️ %s %s 到期平仓假设执行（观察模式）", d.Symbol, d.Action))
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("⌛ %s %s 到期平仓成功", d.Symbol, d.Action))
+		tradeEntry := at.buildTradeEntry(&d, &actionRecord, ctx)
+		if err := at.memoryManager.AddTrade(tradeEntry); err != nil {
+			log.Printf("⚠️  记录交易到记忆失败: %v", err)
+		}
+
+		ctx.Account.MarginUsed -= pos.MarginUsed
+		if ctx.Account.TotalEquity > 0 {
+			ctx.Account.MarginUsedPct = ctx.Account.MarginUsed / ctx.Account.TotalEquity * 100
+		}
+	}
+	ctx.Positions = remaining
+	ctx.Account.PositionCount = len(remaining)
+}
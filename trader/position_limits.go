@@ -0,0 +1,21 @@
+package trader
+
+// checkSideLimit 同方向持仓数量上限（positions 为当前持仓，同币种持仓不计入）
+func (at *AutoTrader) checkSideLimit(symbol, side string, positions []Position) error {
+	var existing []string
+	for _, pos := range positions {
+		if pos.Symbol != symbol && pos.Side == side {
+			existing = append(existing, pos.Symbol)
+		}
+	}
+	return at.config.PositionLimits.CheckSide(symbol, side, existing)
+}
+
+// checkNotionalLimit 开仓后总名义敞口上限（净值倍数）
+func (at *AutoTrader) checkNotionalLimit(positions []Position, newNotional, equity float64) error {
+	currentNotional := 0.0
+	for _, pos := range positions {
+		currentNotional += pos.Notional()
+	}
+	return at.config.PositionLimits.CheckNotional(currentNotional, newNotional, equity)
+}
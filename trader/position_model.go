@@ -0,0 +1,53 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/mcp"
+	"time"
+)
+
+// PositionModelConfig 持仓评估专用模型（持有/平仓判断不需要与开仓预测相同的大模型）
+type PositionModelConfig struct {
+	Provider  string // "deepseek", "qwen", "custom"；为空时沿用主模型的provider和密钥，只替换模型名
+	APIKey    string
+	APIURL    string // custom时必填
+	Model     string
+	Timeout   time.Duration // 单次请求超时（0=沿用主模型）
+	MaxTokens int           // 单次回复最大token数（0=沿用主模型）
+}
+
+// newPositionClient 按配置创建持仓评估模型的客户端（cfg为nil时返回nil，持仓评估使用主模型）
+func newPositionClient(main *mcp.Client, cfg *PositionModelConfig) (*mcp.Client, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	client := *main
+	switch cfg.Provider {
+	case "":
+	case "deepseek":
+		client = *mcp.New()
+		client.SetDeepSeekAPIKey(cfg.APIKey)
+		client.Timeout = main.Timeout
+	case "qwen":
+		client = *mcp.New()
+		client.SetQwenAPIKey(cfg.APIKey, "")
+		client.Timeout = main.Timeout
+	case "custom":
+		client = *mcp.New()
+		client.SetCustomAPI(cfg.APIURL, cfg.APIKey, cfg.Model)
+		client.Timeout = main.Timeout
+	default:
+		return nil, fmt.Errorf("position_model: 不支持的provider '%s'", cfg.Provider)
+	}
+	if cfg.Model != "" {
+		client.Model = cfg.Model
+	}
+	if cfg.Timeout > 0 {
+		client.Timeout = cfg.Timeout
+	}
+	if cfg.MaxTokens > 0 {
+		client.MaxTokens = cfg.MaxTokens
+	}
+	return &client, nil
+}
@@ -0,0 +1,393 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/events"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 持仓人工指令类型
+const (
+	positionCmdAnnotate = "annotate" // 备注 / 人工管理标记
+	positionCmdAdjust   = "adjust"   // 调整止损止盈
+	positionCmdClose    = "close"    // 平仓
+)
+
+const maxPositionCommandResults = 20
+
+// positionCommand 🙋 外部对单个持仓的人工指令（gRPC提交，下一个决策周期开始时执行，与AI决策不并发）
+type positionCommand struct {
+	Kind       string
+	Symbol     string
+	Side       string
+	Manual     *bool   // annotate: nil=不修改标记
+	Note       *string // annotate: nil=不修改备注
+	StopLoss   float64 // adjust: <=0表示不修改
+	TakeProfit float64 // adjust: <=0表示不修改
+	Submitted  time.Time
+}
+
+// positionCommandResult 已执行的人工指令（状态API展示最近几条）
+type positionCommandResult struct {
+	Kind     string    `json:"kind"`
+	Position string    `json:"position"`
+	Success  bool      `json:"success"`
+	Error    string    `json:"error,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// trailingSkipper 交易器内部会移动止损时，支持跳过人工管理的持仓
+type trailingSkipper interface {
+	SetTrailingSkip(skip func(symbol, side string) bool)
+}
+
+// SetTrailingSkip 注册移动止损跳过判断（在交易器开始运行前调用）
+func (t *FuturesTrader) SetTrailingSkip(skip func(symbol, side string) bool) { t.skipTrailing = skip }
+
+// SetTrailingSkip 注册移动止损跳过判断（在交易器开始运行前调用）
+func (t *MockTrader) SetTrailingSkip(skip func(symbol, side string) bool) { t.skipTrailing = skip }
+
+// normalizePosition 规范化币种（同关注列表）和方向
+func normalizePosition(symbol, side string) (string, string, error) {
+	symbols, err := NormalizeWatchlist([]string{symbol})
+	if err != nil {
+		return "", "", err
+	}
+	if len(symbols) == 0 {
+		return "", "", fmt.Errorf("币种不能为空")
+	}
+	side = strings.ToLower(strings.TrimSpace(side))
+	if side != "long" && side != "short" {
+		return "", "", fmt.Errorf("方向必须是long或short: %q", side)
+	}
+	return symbols[0], side, nil
+}
+
+// AnnotatePosition 设置持仓备注和"人工管理"标记（nil表示不修改），下一个决策周期开始时生效
+// 人工管理的持仓不交给AI，系统也不会平仓、移动止损或补设止损；manual=false交回系统管理
+func (at *AutoTrader) AnnotatePosition(symbol, side string, manual *bool, note *string) error {
+	symbol, side, err := normalizePosition(symbol, side)
+	if err != nil {
+		return err
+	}
+	if manual == nil && note == nil {
+		return fmt.Errorf("manual和note至少设置一项")
+	}
+	at.queuePositionCommand(positionCommand{Kind: positionCmdAnnotate, Symbol: symbol, Side: side, Manual: manual, Note: note})
+	return nil
+}
+
+// AdjustPosition 通过系统调整持仓止损/止盈（<=0表示不修改），下一个决策周期开始时执行
+// 撤掉本系统的保护单后按新价格重下，并同步更新持仓保护记录
+func (at *AutoTrader) AdjustPosition(symbol, side string, stopLoss, takeProfit float64) error {
+	symbol, side, err := normalizePosition(symbol, side)
+	if err != nil {
+		return err
+	}
+	if stopLoss <= 0 && takeProfit <= 0 {
+		return fmt.Errorf("stop_loss和take_profit至少设置一项")
+	}
+	if at.config.ObserveMode {
+		return fmt.Errorf("观察模式不下单，无法调整止损止盈")
+	}
+	at.queuePositionCommand(positionCommand{Kind: positionCmdAdjust, Symbol: symbol, Side: side, StopLoss: stopLoss, TakeProfit: takeProfit})
+	return nil
+}
+
+// RequestClosePosition 请求在下一个决策周期开始时平仓（人工管理的持仓同样平掉）
+func (at *AutoTrader) RequestClosePosition(symbol, side string) error {
+	symbol, side, err := normalizePosition(symbol, side)
+	if err != nil {
+		return err
+	}
+	if at.config.ObserveMode {
+		return fmt.Errorf("观察模式不下单，无法平仓")
+	}
+	at.queuePositionCommand(positionCommand{Kind: positionCmdClose, Symbol: symbol, Side: side})
+	return nil
+}
+
+func (at *AutoTrader) queuePositionCommand(cmd positionCommand) {
+	cmd.Submitted = time.Now()
+	at.control.mu.Lock()
+	at.control.pendingPositions = append(at.control.pendingPositions, cmd)
+	at.control.mu.Unlock()
+	log.Printf("🙋 [%s] 已接收持仓指令 %s %s_%s，下一个决策周期执行", at.name, cmd.Kind, cmd.Symbol, cmd.Side)
+}
+
+// isManualPosition 持仓是否标记为人工管理
+func (at *AutoTrader) isManualPosition(symbol, side string) bool {
+	p, ok := at.orderManager.GetProtection(symbol, side)
+	return ok && p.Manual
+}
+
+// checkManualPosition 执行层拦截：人工管理的持仓，系统不开/平该方向的仓位
+func (at *AutoTrader) checkManualPosition(symbol, action string) error {
+	var side string
+	switch action {
+	case "open_long", "close_long":
+		side = "long"
+	case "open_short", "close_short":
+		side = "short"
+	default:
+		return nil
+	}
+	if at.isManualPosition(symbol, side) {
+		return fmt.Errorf("%s %s 已标记为人工管理，系统不操作该持仓", symbol, side)
+	}
+	return nil
+}
+
+// forgetManualPosition 人工管理的持仓平仓后清理保护记录（这类持仓不进入持仓快照，不会走消失检测）
+func (at *AutoTrader) forgetManualPosition(key string) {
+	i := strings.LastIndex(key, "_")
+	if i < 0 {
+		return
+	}
+	symbol, side := key[:i], key[i+1:]
+	if at.isManualPosition(symbol, side) {
+		at.orderManager.RemoveProtection(symbol, side)
+		log.Printf("🙋 [%s] 人工管理的持仓 %s 已平仓，清除记录", at.name, key)
+	}
+}
+
+// applyPendingPositionCommands 执行暂存的持仓人工指令（在决策周期开始时调用）
+func (at *AutoTrader) applyPendingPositionCommands() {
+	at.control.mu.Lock()
+	cmds := at.control.pendingPositions
+	at.control.pendingPositions = nil
+	at.control.mu.Unlock()
+
+	if len(cmds) == 0 {
+		return
+	}
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		log.Printf("⚠️  [%s] 持仓人工指令: 获取持仓失败，下个周期重试: %v", at.name, err)
+		at.control.mu.Lock()
+		at.control.pendingPositions = append(cmds, at.control.pendingPositions...)
+		at.control.mu.Unlock()
+		return
+	}
+	open := PositionsByKey(positions)
+
+	for _, cmd := range cmds {
+		key := cmd.Symbol + "_" + cmd.Side
+		var err error
+		if _, ok := open[key]; !ok {
+			err = fmt.Errorf("当前没有该持仓")
+		} else {
+			switch cmd.Kind {
+			case positionCmdAnnotate:
+				at.annotatePosition(cmd)
+			case positionCmdAdjust:
+				err = at.adjustPosition(cmd, open)
+			case positionCmdClose:
+				err = at.closePositionOnRequest(cmd, open[key])
+				delete(open, key)
+			}
+		}
+
+		if err != nil {
+			log.Printf("❌ [%s] 持仓指令 %s %s 执行失败: %v", at.name, cmd.Kind, key, err)
+		}
+		at.recordPositionCommand(cmd, err)
+	}
+}
+
+// annotatePosition 写入备注/人工管理标记（没有保护记录的持仓新建一条，例如启动时忽略的未知持仓）
+func (at *AutoTrader) annotatePosition(cmd positionCommand) {
+	key := cmd.Symbol + "_" + cmd.Side
+	updated := PositionProtection{Symbol: cmd.Symbol, Side: cmd.Side, OpenTime: time.Now(), Source: "override"}
+	if p, ok := at.orderManager.GetProtection(cmd.Symbol, cmd.Side); ok {
+		updated = *p
+	}
+	if cmd.Note != nil {
+		updated.Note = *cmd.Note
+	}
+	if cmd.Manual != nil {
+		updated.Manual = *cmd.Manual
+	}
+	at.orderManager.SetProtection(&updated)
+
+	switch {
+	case updated.Manual:
+		at.ignoredPositions[key] = true
+		log.Printf("🙋 [%s] %s 已标记为人工管理，系统不再操作该持仓（备注: %s）", at.name, key, updated.Note)
+	case cmd.Manual != nil:
+		delete(at.ignoredPositions, key)
+		log.Printf("🤖 [%s] %s 已交回系统管理", at.name, key)
+	default:
+		log.Printf("📝 [%s] %s 备注: %s", at.name, key, updated.Note)
+	}
+}
+
+// adjustPosition 按新价格重下止损止盈并更新保护记录
+// 撤单按币种进行，同币种另一方向持仓的保护单会一并撤掉，按其记录重下
+func (at *AutoTrader) adjustPosition(cmd positionCommand, open map[string]*Position) error {
+	if at.orderManager.HasOrder(cmd.Symbol) {
+		return fmt.Errorf("%s有未成交的限价单，撤单会一并撤掉，请稍后再调整", cmd.Symbol)
+	}
+
+	pos := open[cmd.Symbol+"_"+cmd.Side]
+	updated := PositionProtection{Symbol: cmd.Symbol, Side: cmd.Side, OpenTime: time.Now(), Source: "override"}
+	if p, ok := at.orderManager.GetProtection(cmd.Symbol, cmd.Side); ok {
+		updated = *p
+	}
+	if cmd.StopLoss > 0 {
+		updated.StopLoss = cmd.StopLoss
+	}
+	if cmd.TakeProfit > 0 {
+		updated.TakeProfit = cmd.TakeProfit
+		updated.TakeProfitLadder = nil // 人工指定的止盈替换分批止盈
+		updated.LadderQuantity = 0
+	}
+	if err := validateOverridePrices(cmd.Side, pos.MarkPrice, updated.StopLoss, updated.TakeProfit); err != nil {
+		return err
+	}
+
+	if err := at.trader.CancelAllOrders(cmd.Symbol); err != nil {
+		return fmt.Errorf("撤销原保护单失败: %w", err)
+	}
+	at.orderManager.SetProtection(&updated)
+
+	quantity := pos.Quantity
+	positionSide := strings.ToUpper(cmd.Side)
+	log.Printf("🙋 [%s] %s_%s 调整保护单: 止损=%.4f 止盈=%.4f", at.name, cmd.Symbol, cmd.Side, updated.StopLoss, updated.TakeProfit)
+	var err error
+	if updated.StopLoss > 0 {
+		if err = at.trader.SetStopLoss(cmd.Symbol, positionSide, quantity, updated.StopLoss); err != nil {
+			err = fmt.Errorf("设置止损失败（止损巡检会按记录补设）: %w", err)
+		}
+	}
+	if updated.TakeProfit > 0 {
+		at.restoreTakeProfits(cmd.Symbol, cmd.Side, quantity, &updated)
+	}
+
+	otherSide := "short"
+	if cmd.Side == "short" {
+		otherSide = "long"
+	}
+	if other, ok := open[cmd.Symbol+"_"+otherSide]; ok {
+		if p, ok := at.orderManager.GetProtection(cmd.Symbol, otherSide); ok && p.StopLoss > 0 {
+			at.restoreProtectiveOrders(cmd.Symbol, otherSide, other.Quantity, p)
+		}
+	}
+	return err
+}
+
+// validateOverridePrices 止损止盈必须在当前价格的正确一侧（否则会立即触发）
+func validateOverridePrices(side string, markPrice, stopLoss, takeProfit float64) error {
+	if markPrice <= 0 {
+		return nil
+	}
+	if side == "long" {
+		if stopLoss > 0 && stopLoss >= markPrice {
+			return fmt.Errorf("多单止损%.4f必须低于当前价%.4f", stopLoss, markPrice)
+		}
+		if takeProfit > 0 && takeProfit <= markPrice {
+			return fmt.Errorf("多单止盈%.4f必须高于当前价%.4f", takeProfit, markPrice)
+		}
+		return nil
+	}
+	if stopLoss > 0 && stopLoss <= markPrice {
+		return fmt.Errorf("空单止损%.4f必须高于当前价%.4f", stopLoss, markPrice)
+	}
+	if takeProfit > 0 && takeProfit >= markPrice {
+		return fmt.Errorf("空单止盈%.4f必须低于当前价%.4f", takeProfit, markPrice)
+	}
+	return nil
+}
+
+// closePositionOnRequest 按人工请求平仓（与AI平仓一样记录冷却期、盈亏和事件）
+func (at *AutoTrader) closePositionOnRequest(cmd positionCommand, pos *Position) error {
+	var order *OrderResult
+	var err error
+	if cmd.Side == "long" {
+		order, err = at.trader.CloseLong(cmd.Symbol, 0)
+	} else {
+		order, err = at.trader.CloseShort(cmd.Symbol, 0)
+	}
+	if err != nil {
+		return err
+	}
+
+	key := cmd.Symbol + "_" + cmd.Side
+	at.manualCloseTracker[key] = time.Now()
+	delete(at.ignoredPositions, key)
+	at.constraints.RecordClosePosition(cmd.Symbol, cmd.Side, order.RealizedPnL)
+	at.ledgerForcedClose(cmd.Symbol, cmd.Side, order, pos, "🙋 人工请求平仓")
+	at.orderManager.RemoveProtection(cmd.Symbol, cmd.Side)
+
+	at.dailyPnL += order.RealizedPnL
+	at.events.Publish(events.PositionClosedEvent{
+		TraderID:    at.id,
+		Symbol:      cmd.Symbol,
+		Side:        cmd.Side,
+		Price:       pos.MarkPrice,
+		RealizedPnL: order.RealizedPnL,
+		Reason:      "🙋 人工请求平仓",
+		Time:        time.Now(),
+	})
+	log.Printf("🙋 [%s] %s 已按人工请求平仓（盈亏 %+.2f USDT）", at.name, key, order.RealizedPnL)
+	return nil
+}
+
+func (at *AutoTrader) recordPositionCommand(cmd positionCommand, err error) {
+	result := positionCommandResult{
+		Kind:     cmd.Kind,
+		Position: cmd.Symbol + "_" + cmd.Side,
+		Success:  err == nil,
+		Time:     time.Now(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+	at.control.positionResults = append(at.control.positionResults, result)
+	if n := len(at.control.positionResults); n > maxPositionCommandResults {
+		at.control.positionResults = at.control.positionResults[n-maxPositionCommandResults:]
+	}
+}
+
+// getPositionOverrideStatus 人工管理的持仓、待执行和最近执行的持仓指令（状态API）
+func (at *AutoTrader) getPositionOverrideStatus() map[string]interface{} {
+	protections := at.orderManager.AllProtections()
+	keys := make([]string, 0, len(protections))
+	for key, p := range protections {
+		if p.Manual {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	manual := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		manual = append(manual, map[string]interface{}{
+			"position": key,
+			"note":     protections[key].Note,
+			"updated":  protections[key].UpdatedAt,
+		})
+	}
+
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+	pending := make([]map[string]interface{}, 0, len(at.control.pendingPositions))
+	for _, cmd := range at.control.pendingPositions {
+		pending = append(pending, map[string]interface{}{
+			"kind":      cmd.Kind,
+			"position":  cmd.Symbol + "_" + cmd.Side,
+			"submitted": cmd.Submitted,
+		})
+	}
+	return map[string]interface{}{
+		"manual":  manual,
+		"pending": pending,
+		"recent":  append([]positionCommandResult(nil), at.control.positionResults...),
+	}
+}
@@ -0,0 +1,42 @@
+package trader
+
+import "strings"
+
+// protectionLevels 持仓的止损止盈价格
+type protectionLevels struct {
+	StopLoss   float64
+	TakeProfit float64
+	Manual     bool   // 🙋 人工管理
+	Note       string // 📝 人工备注
+}
+
+// latestProtectionLevels 查找每个持仓（symbol_side）的止损止盈
+// 优先使用订单管理器中的保护信息，其次是决策日志中最后一次开仓时设置的值
+// 止损止盈挂在交易所条件单上，这里只用于展示，找不到时为0
+func (at *AutoTrader) latestProtectionLevels() map[string]protectionLevels {
+	levels := make(map[string]protectionLevels)
+
+	if records, err := at.decisionLogger.GetLatestRecords(500); err == nil {
+		// 从旧到新遍历，后面的开仓覆盖前面的
+		for _, record := range records {
+			for _, action := range record.Decisions {
+				if !action.Success || action.Hypothetical || action.StopLoss <= 0 {
+					continue
+				}
+				if !strings.HasPrefix(action.Action, "open_") {
+					continue
+				}
+				side := strings.TrimPrefix(action.Action, "open_")
+				levels[action.Symbol+"_"+side] = protectionLevels{
+					StopLoss:   action.StopLoss,
+					TakeProfit: action.TakeProfit,
+				}
+			}
+		}
+	}
+
+	for key, p := range at.orderManager.AllProtections() {
+		levels[key] = protectionLevels{StopLoss: p.StopLoss, TakeProfit: p.TakeProfit, Manual: p.Manual, Note: p.Note}
+	}
+	return levels
+}
@@ -0,0 +1,112 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PositionProtection 持仓保护信息（与限价单一起持久化，重启后用于恢复止损止盈和开仓时间）
+type PositionProtection struct {
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"` // long/short
+	StopLoss   float64   `json:"stop_loss"`
+	TakeProfit float64   `json:"take_profit"`
+	OpenTime   time.Time `json:"open_time"`
+	Reasoning  string    `json:"reasoning,omitempty"` // 开仓理由
+	Source     string    `json:"source"`              // open/decision_log/exchange/adopted/override
+	Strategy   string    `json:"strategy,omitempty"`  // 🏷️ 开仓策略来源（ai/limit/basis/manual）
+
+	TakeProfitLadder []LadderLevel `json:"take_profit_ladder,omitempty"` // 🪜 分批止盈档位（含成交状态）
+	LadderQuantity   float64       `json:"ladder_quantity,omitempty"`    // 设置分批止盈时的持仓数量
+
+	Manual    bool      `json:"manual,omitempty"` // 🙋 人工管理：不交给AI，系统不平仓、不移动/补设止损
+	Note      string    `json:"note,omitempty"`   // 📝 人工备注
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// loadProtections 从文件加载持仓保护信息（与限价单文件同目录）
+func (om *OrderManager) loadProtections() {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	om.protections = make(map[string]*PositionProtection)
+	om.protectionPath = filepath.Join(filepath.Dir(om.filepath), "position_protection.json")
+
+	data, err := os.ReadFile(om.protectionPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️  加载持仓保护信息失败: %v", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &om.protections); err != nil {
+		log.Printf("⚠️  解析持仓保护信息失败: %v", err)
+		om.protections = make(map[string]*PositionProtection)
+	}
+}
+
+// saveProtections 持久化持仓保护信息（调用方持有锁）
+func (om *OrderManager) saveProtections() error {
+	data, err := json.MarshalIndent(om.protections, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON序列化失败: %w", err)
+	}
+
+	tmpFile := om.protectionPath + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	return os.Rename(tmpFile, om.protectionPath)
+}
+
+// SetProtection 记录/更新持仓保护信息
+func (om *OrderManager) SetProtection(p *PositionProtection) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	p.UpdatedAt = time.Now()
+	om.protections[p.Symbol+"_"+p.Side] = p
+	if err := om.saveProtections(); err != nil {
+		log.Printf("⚠️  保存持仓保护信息失败: %v", err)
+	}
+}
+
+// GetProtection 获取持仓保护信息
+func (om *OrderManager) GetProtection(symbol, side string) (*PositionProtection, bool) {
+	om.mu.RLock()
+	defer om.mu.RUnlock()
+
+	p, ok := om.protections[symbol+"_"+side]
+	return p, ok
+}
+
+// RemoveProtection 持仓平仓后移除保护信息
+func (om *OrderManager) RemoveProtection(symbol, side string) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	key := symbol + "_" + side
+	if _, ok := om.protections[key]; !ok {
+		return
+	}
+	delete(om.protections, key)
+	if err := om.saveProtections(); err != nil {
+		log.Printf("⚠️  保存持仓保护信息失败: %v", err)
+	}
+}
+
+// AllProtections 获取所有持仓保护信息（副本）
+func (om *OrderManager) AllProtections() map[string]PositionProtection {
+	om.mu.RLock()
+	defer om.mu.RUnlock()
+
+	result := make(map[string]PositionProtection, len(om.protections))
+	for key, p := range om.protections {
+		result[key] = *p
+	}
+	return result
+}
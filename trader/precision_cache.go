@@ -0,0 +1,174 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	precisionRefreshInterval = 24 * time.Hour  // 精度缓存每天刷新（交易所偶尔调整tickSize/stepSize）
+	precisionMissCooldown    = time.Minute     // 缓存未命中（新上线币种）时重新下载exchangeInfo的最短间隔
+	precisionRetryInterval   = 5 * time.Minute // 预取失败后的重试间隔
+)
+
+// precisionPrefetcher 支持批量预取全部交易对精度的交易器（启动时预热，避免首笔订单临时下载exchangeInfo）
+type precisionPrefetcher interface {
+	PrefetchPrecisions() (int, error)
+}
+
+// symbolPrecisionCache 交易对数量/价格精度缓存（一次exchangeInfo覆盖全部交易对）
+type symbolPrecisionCache struct {
+	mu          sync.RWMutex
+	quantity    map[string]int // LOT_SIZE stepSize 的小数位
+	price       map[string]int // PRICE_FILTER tickSize 的小数位
+	lastMissRun time.Time
+}
+
+// lookup 缓存中的精度（price=true 查询价格精度）
+func (c *symbolPrecisionCache) lookup(symbol string, price bool) (int, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m := c.quantity
+	if price {
+		m = c.price
+	}
+	p, ok := m[symbol]
+	return p, ok
+}
+
+// missRefreshDue 缓存未命中时是否应重新下载（冷却期内不重复下载，避免不存在的币种每次都触发）
+func (c *symbolPrecisionCache) missRefreshDue() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.lastMissRun) < precisionMissCooldown {
+		return false
+	}
+	c.lastMissRun = time.Now()
+	return true
+}
+
+// PrefetchPrecisions 下载一次exchangeInfo，缓存全部交易对的数量/价格精度（顺带刷新交易对状态缓存）
+func (t *FuturesTrader) PrefetchPrecisions() (int, error) {
+	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("获取交易规则失败: %w", classifyBinanceError(err))
+	}
+
+	quantity := make(map[string]int, len(exchangeInfo.Symbols))
+	price := make(map[string]int, len(exchangeInfo.Symbols))
+	statuses := make(map[string]string, len(exchangeInfo.Symbols))
+	for _, s := range exchangeInfo.Symbols {
+		statuses[s.Symbol] = s.Status
+		for _, filter := range s.Filters {
+			switch filter["filterType"] {
+			case "LOT_SIZE":
+				if stepSize, ok := filter["stepSize"].(string); ok {
+					quantity[s.Symbol] = calculatePrecision(stepSize)
+				}
+			case "PRICE_FILTER":
+				if tickSize, ok := filter["tickSize"].(string); ok {
+					price[s.Symbol] = calculatePrecision(tickSize)
+				}
+			}
+		}
+	}
+
+	c := &t.precisions
+	c.mu.Lock()
+	c.quantity = quantity
+	c.price = price
+	c.mu.Unlock()
+	t.statuses.set(statuses)
+	return len(quantity), nil
+}
+
+// cachedPrecision 从缓存取精度，未命中时（未预取或新上线币种）重新下载一次exchangeInfo
+func (t *FuturesTrader) cachedPrecision(symbol string, price bool) (int, bool, error) {
+	if p, ok := t.precisions.lookup(symbol, price); ok {
+		return p, true, nil
+	}
+	if !t.precisions.missRefreshDue() {
+		return 0, false, nil
+	}
+	if _, err := t.PrefetchPrecisions(); err != nil {
+		return 0, false, err
+	}
+	p, ok := t.precisions.lookup(symbol, price)
+	return p, ok, nil
+}
+
+// precisionPrefetchState 精度预取状态（状态API）
+type precisionPrefetchState struct {
+	mu        sync.Mutex
+	symbols   int
+	fetchedAt time.Time
+	lastError string
+}
+
+// prefetchPrecisions 预取全部交易对精度（不支持的交易所返回false）
+func (at *AutoTrader) prefetchPrecisions() bool {
+	prefetcher, ok := unwrapTrader(at.trader).(precisionPrefetcher)
+	if !ok {
+		return false
+	}
+
+	start := time.Now()
+	count, err := prefetcher.PrefetchPrecisions()
+
+	s := &at.precisionState
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.lastError = err.Error()
+		log.Printf("⚠️  [%s] 预取交易对精度失败: %v（下单时按需获取）", at.name, err)
+		return true
+	}
+	s.symbols = count
+	s.fetchedAt = time.Now()
+	s.lastError = ""
+	log.Printf("📐 [%s] 已预取%d个交易对的数量/价格精度（耗时%v）", at.name, count, time.Since(start).Round(time.Millisecond))
+	return true
+}
+
+// runPrecisionRefresh 每天刷新精度缓存，失败时缩短间隔重试
+func (at *AutoTrader) runPrecisionRefresh(stopCh <-chan struct{}) {
+	for {
+		interval := precisionRefreshInterval
+		at.precisionState.mu.Lock()
+		if at.precisionState.lastError != "" {
+			interval = precisionRetryInterval
+		}
+		at.precisionState.mu.Unlock()
+
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(interval):
+			at.prefetchPrecisions()
+		}
+	}
+}
+
+// getPrecisionStatus 精度缓存状态（状态API）
+func (at *AutoTrader) getPrecisionStatus() map[string]interface{} {
+	if _, ok := unwrapTrader(at.trader).(precisionPrefetcher); !ok {
+		return map[string]interface{}{"enabled": false}
+	}
+	s := &at.precisionState
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := map[string]interface{}{
+		"enabled": true,
+		"symbols": s.symbols,
+	}
+	if !s.fetchedAt.IsZero() {
+		status["fetched_at"] = s.fetchedAt
+	}
+	if s.lastError != "" {
+		status["last_error"] = s.lastError
+	}
+	return status
+}
@@ -0,0 +1,224 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/errs"
+	"nofx/events"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultProtectiveMonitorInterval = time.Minute
+	defaultProtectiveAlertAfter      = 3 // 连续补设失败3次后告警
+)
+
+// missingStop 交易所上缺少止损单的持仓
+type missingStop struct {
+	Since     time.Time
+	StopLoss  float64
+	Failures  int // 连续补设失败次数
+	LastError string
+	Escalated bool // 已告警
+}
+
+// protectiveMonitor 🛡️ 止损单巡检：定期确认每个持仓在交易所上都有止损单
+// 移动止损是"先撤旧单再下新单"，新单失败时持仓无保护；手动误撤、交易所丢单同理
+type protectiveMonitor struct {
+	mu       sync.Mutex
+	lastScan time.Time
+	restored int                     // 累计补设成功次数
+	missing  map[string]*missingStop // symbol_side → 缺失止损的持仓
+}
+
+func newProtectiveMonitor() *protectiveMonitor {
+	return &protectiveMonitor{missing: make(map[string]*missingStop)}
+}
+
+// protectiveMonitorInterval 巡检间隔（<=0表示禁用）
+func (at *AutoTrader) protectiveMonitorInterval() time.Duration {
+	if at.config.ProtectiveMonitorInterval == 0 {
+		return defaultProtectiveMonitorInterval
+	}
+	return at.config.ProtectiveMonitorInterval
+}
+
+func (at *AutoTrader) protectiveAlertAfter() int {
+	if at.config.ProtectiveMonitorAlertAfter <= 0 {
+		return defaultProtectiveAlertAfter
+	}
+	return at.config.ProtectiveMonitorAlertAfter
+}
+
+// isMissing 上次巡检时该持仓在交易所上是否缺少止损单
+func (m *protectiveMonitor) isMissing(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.missing[key]
+	return ok
+}
+
+// runProtectiveMonitor 独立于决策周期运行
+func (at *AutoTrader) runProtectiveMonitor(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(at.protectiveMonitorInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if at.exchangeOutage() {
+				continue // 失联期间由恢复对账处理
+			}
+			at.checkProtectiveOrders()
+		}
+	}
+}
+
+// recordStopMove 移动止损成功后更新持久化的止损价（补设时使用最新止损，而不是开仓时的止损）
+func (at *AutoTrader) recordStopMove(symbol, side string, newStop float64) {
+	p, ok := at.orderManager.GetProtection(symbol, side)
+	if !ok || newStop <= 0 {
+		return
+	}
+	updated := *p
+	updated.StopLoss = newStop
+	at.orderManager.SetProtection(&updated)
+}
+
+// checkProtectiveOrders 单次巡检：只检查有止损记录的持仓（外部/忽略的持仓没有保护信息，人工管理的持仓跳过）
+// 连续两次巡检都缺失才补设，避免与移动止损的撤单-重下窗口冲突造成重复止损单
+func (at *AutoTrader) checkProtectiveOrders() {
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		log.Printf("⚠️  [%s] 止损巡检: 获取持仓失败: %v", at.name, err)
+		return
+	}
+
+	m := at.stopGuard
+	seen := make(map[string]bool, len(positions))
+	for _, pos := range positions {
+		symbol, side := pos.Symbol, pos.Side
+		key := symbol + "_" + side
+		protection, ok := at.orderManager.GetProtection(symbol, side)
+		if !ok || protection.StopLoss <= 0 || protection.Manual {
+			continue // 🙋 人工管理的持仓不补设止损
+		}
+		seen[key] = true
+
+		if sl, _ := at.protectiveOrderPrices(symbol, side); sl > 0 {
+			m.mu.Lock()
+			if prev, ok := m.missing[key]; ok && prev.Failures > 0 {
+				log.Printf("✅ [%s] %s 止损单已恢复", at.name, key)
+			}
+			delete(m.missing, key)
+			m.mu.Unlock()
+			continue
+		}
+
+		m.mu.Lock()
+		state, pending := m.missing[key]
+		if !pending {
+			m.missing[key] = &missingStop{Since: time.Now(), StopLoss: protection.StopLoss}
+			m.mu.Unlock()
+			log.Printf("⚠️  [%s] %s 交易所上没有止损单（记录止损%.4f），下次巡检仍缺失时补设", at.name, key, protection.StopLoss)
+			continue
+		}
+		state.StopLoss = protection.StopLoss
+		m.mu.Unlock()
+
+		at.recreateStopLoss(symbol, side, pos.Quantity, protection.StopLoss, state)
+	}
+
+	m.mu.Lock()
+	for key := range m.missing {
+		if !seen[key] {
+			delete(m.missing, key) // 持仓已平
+		}
+	}
+	m.lastScan = time.Now()
+	m.mu.Unlock()
+}
+
+// recreateStopLoss 按记录补设止损；连续失败达到阈值或错误不可重试时告警（每次缺失只告警一次）
+func (at *AutoTrader) recreateStopLoss(symbol, side string, quantity, stopLoss float64, state *missingStop) {
+	key := symbol + "_" + side
+	log.Printf("🚨 [%s] %s 持仓无止损保护，按记录补设止损 %.4f", at.name, key, stopLoss)
+
+	err := at.trader.SetStopLoss(symbol, strings.ToUpper(side), quantity, stopLoss)
+
+	m := at.stopGuard
+	m.mu.Lock()
+	if err == nil {
+		m.restored++
+		delete(m.missing, key)
+		m.mu.Unlock()
+		log.Printf("  ✅ %s 止损已补设", key)
+		return
+	}
+	state.Failures++
+	state.LastError = err.Error()
+	failures := state.Failures
+	// 交易对无效/精度错误重试不会成功，立即告警
+	escalate := (failures >= at.protectiveAlertAfter() || errs.Is(err, errs.InvalidSymbol, errs.PrecisionError)) && !state.Escalated
+	if escalate {
+		state.Escalated = true
+	}
+	m.mu.Unlock()
+
+	log.Printf("  ❌ %s 补设止损失败（第%d次）: %v", key, failures, err)
+	if !escalate {
+		return
+	}
+
+	detail := fmt.Sprintf("止损单缺失已%.0f分钟，连续%d次补设止损%.4f失败: %v，请立即手动处理",
+		time.Since(state.Since).Minutes(), failures, stopLoss, err)
+	log.Printf("🚨🚨🚨 [%s] %s %s", at.name, key, detail)
+	at.events.Publish(events.AccountAnomalyEvent{
+		TraderID: at.id, Kind: AnomalyMissingStop, Symbol: symbol, Side: side, Detail: detail, Time: time.Now(),
+	})
+}
+
+// getProtectiveMonitorStatus 止损巡检状态（状态API）
+func (at *AutoTrader) getProtectiveMonitorStatus() map[string]interface{} {
+	interval := at.protectiveMonitorInterval()
+	if _, ok := at.trader.(openOrderLister); !ok || interval <= 0 || at.config.ObserveMode {
+		return map[string]interface{}{"enabled": false}
+	}
+	m := at.stopGuard
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]string, 0, len(m.missing))
+	for key := range m.missing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	unprotected := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		s := m.missing[key]
+		unprotected = append(unprotected, map[string]interface{}{
+			"position":   key,
+			"since":      s.Since,
+			"stop_loss":  s.StopLoss,
+			"failures":   s.Failures,
+			"last_error": s.LastError,
+			"escalated":  s.Escalated,
+		})
+	}
+
+	status := map[string]interface{}{
+		"enabled":          true,
+		"interval_seconds": interval.Seconds(),
+		"restored":         m.restored,
+		"unprotected":      unprotected,
+	}
+	if !m.lastScan.IsZero() {
+		status["last_scan"] = m.lastScan
+	}
+	return status
+}
@@ -0,0 +1,50 @@
+package trader
+
+import (
+	"log"
+	"nofx/decision"
+	"nofx/market"
+)
+
+// applyQuoteAssets 🪙 按计价币种偏好选择交易对：币种池给出的是USDT交易对，
+// 按quote_assets顺序换成第一个处于交易状态的合约（如BTCUSDT -> BTCUSDC），都没有时保持原样
+func (at *AutoTrader) applyQuoteAssets(candidates []decision.CandidateCoin) []decision.CandidateCoin {
+	quotes := at.config.QuoteAssets
+	if len(quotes) == 0 || (len(quotes) == 1 && quotes[0] == "USDT") {
+		return candidates
+	}
+	provider, ok := at.trader.(symbolStatusProvider)
+	if !ok {
+		return candidates
+	}
+	statuses, err := provider.GetSymbolStatuses(false)
+	if err != nil {
+		log.Printf("⚠️  [%s] 获取交易对状态失败，候选币种保持USDT交易对: %v", at.name, err)
+		return candidates
+	}
+
+	seen := make(map[string]bool, len(candidates))
+	result := make([]decision.CandidateCoin, 0, len(candidates))
+	switched := 0
+	for _, c := range candidates {
+		base := market.BaseAsset(c.Symbol)
+		for _, q := range quotes {
+			if statuses[base+q] == SymbolStatusTrading {
+				if base+q != c.Symbol {
+					switched++
+				}
+				c.Symbol = base + q
+				break
+			}
+		}
+		if seen[c.Symbol] {
+			continue
+		}
+		seen[c.Symbol] = true
+		result = append(result, c)
+	}
+	if switched > 0 {
+		log.Printf("🪙 [%s] 按计价币种偏好%v切换了%d个候选交易对", at.name, quotes, switched)
+	}
+	return result
+}
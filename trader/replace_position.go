@@ -0,0 +1,113 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"time"
+)
+
+// replaceStep 换仓拆分后的单步执行结果（平仓/开仓各一条决策记录）
+type replaceStep struct {
+	decision     *decision.Decision
+	actionRecord *logger.DecisionAction
+	err          error
+}
+
+// newActionRecord 决策执行记录
+func newActionRecord(d *decision.Decision) *logger.DecisionAction {
+	return &logger.DecisionAction{
+		Action:    d.Action,
+		Symbol:    d.Symbol,
+		Leverage:  d.Leverage,
+		Timestamp: time.Now(),
+		Reasoning: d.Reasoning,
+
+		Confidence: d.Confidence,
+	}
+}
+
+// executeReplacePosition 原子换仓：先确认新仓位能通过全部开仓检查，再平旧仓、开新仓
+// 预检不通过时不平仓（保持原持仓），避免"平仓成功、开仓失败"导致意外空仓
+func (at *AutoTrader) executeReplacePosition(d *decision.Decision) []replaceStep {
+	closeDecision := &decision.Decision{
+		Symbol:    d.CloseSymbol,
+		Action:    "close_" + d.CloseSide,
+		Reasoning: fmt.Sprintf("🔄 换仓至%s %s: %s", d.Symbol, d.Side, d.Reasoning),
+	}
+	openDecision := *d
+	openDecision.Action = "open_" + d.Side
+	openDecision.CloseSymbol, openDecision.CloseSide, openDecision.Side = "", "", ""
+
+	log.Printf("  🔄 换仓: %s %s → %s %s", d.CloseSymbol, d.CloseSide, d.Symbol, d.Side)
+
+	preflight, err := at.preflightReplace(closeDecision, &openDecision)
+	if err != nil {
+		record := newActionRecord(d)
+		return []replaceStep{{decision: d, actionRecord: record, err: fmt.Errorf("换仓预检未通过，保留原持仓: %w", err)}}
+	}
+	log.Printf("  ✓ 换仓预检通过，执行平仓→开仓")
+
+	closeRecord := newActionRecord(closeDecision)
+	if err := at.executeDecisionWithRecord(closeDecision, closeRecord); err != nil {
+		return []replaceStep{{decision: closeDecision, actionRecord: closeRecord, err: err}}
+	}
+	steps := []replaceStep{{decision: closeDecision, actionRecord: closeRecord}}
+
+	openRecord := newActionRecord(&openDecision)
+	openRecord.Critique = preflight.Critique // 🧐 预检已审查过的决策不再重复审查
+	err = at.executeDecisionWithRecord(&openDecision, openRecord)
+	if err != nil {
+		// 预检后状态变化（行情、余额）导致开仓失败：此时已空仓，需要人工关注
+		log.Printf("🚨 换仓平仓成功但开仓失败（当前未持有%s）: %v", d.Symbol, err)
+	}
+	return append(steps, replaceStep{decision: &openDecision, actionRecord: openRecord, err: err})
+}
+
+// preflightReplace 假设旧仓已平，按开仓流程执行全部开仓检查（不下单、不修改原决策）
+// 返回预检的执行记录，开仓时沿用其中的自我审查结果（避免同一决策重复调用审查模型）
+func (at *AutoTrader) preflightReplace(closeDecision, openDecision *decision.Decision) (*logger.DecisionAction, error) {
+	// 🙋 人工管理的持仓不允许换仓平仓/开仓
+	if err := at.checkManualPosition(closeDecision.Symbol, closeDecision.Action); err != nil {
+		return nil, err
+	}
+	if err := at.checkManualPosition(openDecision.Symbol, openDecision.Action); err != nil {
+		return nil, err
+	}
+
+	if binanceTrader, ok := at.trader.(*FuturesTrader); ok {
+		binanceTrader.InvalidatePositionsCache()
+	}
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	closeSide := closeDecision.Action[len("close_"):]
+	targetSide := openDecision.Action[len("open_"):]
+
+	// 平仓释放的资金 = 保证金 + 未实现盈亏
+	var remaining []Position
+	replaced := &replacedPosition{Symbol: closeDecision.Symbol}
+	found := false
+	for _, pos := range positions {
+		if pos.Symbol == closeDecision.Symbol && pos.Side == closeSide {
+			found = true
+			replaced.Freed = pos.Margin() + pos.UnrealizedPnL
+			continue
+		}
+		remaining = append(remaining, pos)
+	}
+	if !found {
+		return nil, fmt.Errorf("要替换的持仓 %s %s 不存在", closeDecision.Symbol, closeSide)
+	}
+
+	// 在副本上执行开仓检查（实际开仓时会重新执行）
+	probe := *openDecision
+	probeRecord := newActionRecord(&probe)
+	if _, err := at.checkOpenGates(&probe, targetSide, remaining, replaced, probeRecord); err != nil {
+		return nil, err
+	}
+	return probeRecord, nil
+}
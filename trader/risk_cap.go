@@ -0,0 +1,47 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+)
+
+// minOrderNotionalUSD 交易所最小下单名义价值（币安开仓时不足100 USDT会被向上调整，调整后会突破风险上限）
+const minOrderNotionalUSD = 100.0
+
+// enforceMaxRiskPerTrade 单笔风险上限：风险 = 仓位数量 × 入场价到止损的距离
+// 超过上限（MaxRiskPerTradeUSD与账户级别按净值比例的上限取较小者）时缩小仓位；缩小后低于最小下单金额则拒绝开仓
+func (at *AutoTrader) enforceMaxRiskPerTrade(d *decision.Decision, entryPrice float64) error {
+	maxRisk := at.maxRiskPerTradeUSD()
+	if maxRisk <= 0 || entryPrice <= 0 {
+		return nil
+	}
+	if d.StopLoss <= 0 {
+		return fmt.Errorf("❌ 未设置止损，无法计算单笔风险（上限%.2f USDT）", maxRisk)
+	}
+
+	stopDistance := math.Abs(entryPrice - d.StopLoss)
+	if stopDistance == 0 {
+		return fmt.Errorf("❌ 止损价等于入场价，无法计算单笔风险")
+	}
+
+	quantity := d.PositionSizeUSD / entryPrice
+	risk := quantity * stopDistance
+	if risk <= maxRisk {
+		log.Printf("  🛡️ 单笔风险%.2f USDT（上限%.2f USDT，止损距离%.2f%%）",
+			risk, maxRisk, stopDistance/entryPrice*100)
+		return nil
+	}
+
+	adjustedSize := maxRisk / stopDistance * entryPrice
+	if adjustedSize < minOrderNotionalUSD {
+		return fmt.Errorf("❌ 单笔风险%.2f USDT超过上限%.2f USDT，缩仓后仓位%.2f USDT低于最小下单金额%.0f USDT，拒绝开仓",
+			risk, maxRisk, adjustedSize, minOrderNotionalUSD)
+	}
+
+	log.Printf("  🛡️ 单笔风险%.2f USDT超过上限%.2f USDT（止损距离%.2f%%），仓位缩小: %.2f → %.2f USDT",
+		risk, maxRisk, stopDistance/entryPrice*100, d.PositionSizeUSD, adjustedSize)
+	d.PositionSizeUSD = adjustedSize
+	return nil
+}
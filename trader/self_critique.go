@@ -0,0 +1,154 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/decision/prompts"
+	"nofx/logger"
+	"strings"
+	"time"
+)
+
+// SelfCritiqueConfig 🧐 大仓位自我审查：名义价值或杠杆达到阈值的开仓，先把决策和风险计算交给AI再审一遍，提出阻断性问题时不执行
+type SelfCritiqueConfig struct {
+	MinNotionalUSD float64 // 名义价值≥该值时审查（0=不按名义价值触发）
+	MinLeverage    int     // 杠杆≥该值时审查（0=不按杠杆触发）
+	BlockOnError   bool    // 审查调用或解析失败时拒绝开仓（默认放行）
+}
+
+// Enabled 是否启用自我审查
+func (c SelfCritiqueConfig) Enabled() bool {
+	return c.MinNotionalUSD > 0 || c.MinLeverage > 0
+}
+
+// critiqueRisk 提交审查的账户侧风险数据（决策本身的止损/仓位由决策提供）
+type critiqueRisk struct {
+	Side              string
+	EntryPrice        float64
+	Equity            float64
+	MarginUtilization float64 // 开仓后总保证金使用率（%）
+}
+
+// critiqueResult 审查模型的JSON输出
+type critiqueResult struct {
+	Verdict    string   `json:"verdict"` // approve/block
+	Objections []string `json:"objections"`
+	Summary    string   `json:"summary"`
+}
+
+// selfCritique 风控检查全部通过后、下单前的第二次AI调用：审查提出阻断性问题（verdict=block）时拒绝开仓
+// 审查过程记录在 actionRecord.Critique 中，与第一次决策一起写入决策日志
+func (at *AutoTrader) selfCritique(d *decision.Decision, risk critiqueRisk, actionRecord *logger.DecisionAction) error {
+	cfg := at.config.SelfCritique
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	var triggers []string
+	if cfg.MinNotionalUSD > 0 && d.PositionSizeUSD >= cfg.MinNotionalUSD {
+		triggers = append(triggers, fmt.Sprintf("名义价值%.0f≥%.0f USDT", d.PositionSizeUSD, cfg.MinNotionalUSD))
+	}
+	if cfg.MinLeverage > 0 && d.Leverage >= cfg.MinLeverage {
+		triggers = append(triggers, fmt.Sprintf("杠杆%dx≥%dx", d.Leverage, cfg.MinLeverage))
+	}
+	if len(triggers) == 0 {
+		return nil
+	}
+	if actionRecord.Critique != nil && !actionRecord.Critique.Blocked {
+		return nil // 换仓预检已审查通过
+	}
+
+	critique := &logger.SelfCritique{Trigger: strings.Join(triggers, "，"), Proposal: critiqueProposal(d, risk)}
+	actionRecord.Critique = critique
+	log.Printf("  🧐 %s 大仓位自我审查（%s）", d.Symbol, critique.Trigger)
+
+	start := time.Now()
+	response, err := at.mcpClient.CallWithMessages(prompts.Render(prompts.SelfCritiqueSystem, nil), critique.Proposal)
+	critique.DurationMs = time.Since(start).Milliseconds()
+	critique.Response = response
+
+	var result critiqueResult
+	if err == nil {
+		err = parseCritique(response, &result)
+	}
+	if err != nil {
+		critique.Verdict = "error"
+		if cfg.BlockOnError {
+			critique.Blocked = true
+			return fmt.Errorf("自我审查失败，拒绝开仓: %w", err)
+		}
+		log.Printf("  ⚠️  自我审查失败，按配置放行: %v", err)
+		return nil
+	}
+
+	critique.Verdict = result.Verdict
+	critique.Objections = result.Objections
+	critique.Summary = result.Summary
+	for _, o := range result.Objections {
+		log.Printf("    • %s", o)
+	}
+	if result.Verdict == "block" {
+		critique.Blocked = true
+		return fmt.Errorf("自我审查提出阻断性问题，拒绝开仓: %s", result.Summary)
+	}
+	log.Printf("  🧐 自我审查通过: %s", result.Summary)
+	return nil
+}
+
+// critiqueProposal 提交审查的决策与风险计算
+func critiqueProposal(d *decision.Decision, risk critiqueRisk) string {
+	var sb strings.Builder
+	price := risk.EntryPrice
+	fmt.Fprintf(&sb, "## 拟执行决策\n")
+	fmt.Fprintf(&sb, "币种: %s | 方向: %s | 入场价: %.6g | 信心度: %d\n", d.Symbol, risk.Side, price, d.Confidence)
+	fmt.Fprintf(&sb, "仓位: %.2f USDT | 杠杆: %dx | 保证金: %.2f USDT\n", d.PositionSizeUSD, d.Leverage, d.PositionSizeUSD/float64(max(d.Leverage, 1)))
+	fmt.Fprintf(&sb, "止损: %.6g | 止盈: %.6g\n", d.StopLoss, d.TakeProfit)
+
+	fmt.Fprintf(&sb, "\n## 风险计算\n")
+	if price > 0 && d.StopLoss > 0 {
+		stopPct := math.Abs(price-d.StopLoss) / price * 100
+		riskUSD := d.PositionSizeUSD * stopPct / 100
+		fmt.Fprintf(&sb, "止损距离: %.2f%% | 止损亏损: %.2f USDT", stopPct, riskUSD)
+		if risk.Equity > 0 {
+			fmt.Fprintf(&sb, "（账户净值的%.2f%%）", riskUSD/risk.Equity*100)
+		}
+		sb.WriteString("\n")
+		if d.TakeProfit > 0 && stopPct > 0 {
+			fmt.Fprintf(&sb, "盈亏比: %.2f\n", math.Abs(d.TakeProfit-price)/price*100/stopPct)
+		}
+		wrongSide := (risk.Side == "long" && d.StopLoss >= price) || (risk.Side == "short" && d.StopLoss <= price)
+		if wrongSide {
+			sb.WriteString("⚠️ 止损位于入场价的错误一侧\n")
+		}
+	}
+	if d.Leverage > 0 {
+		fmt.Fprintf(&sb, "约%.1f%%反向波动触及强平（未计维持保证金）\n", 100/float64(d.Leverage))
+	}
+	if risk.Equity > 0 {
+		fmt.Fprintf(&sb, "账户净值: %.2f USDT | 名义价值/净值: %.2f倍 | 开仓后保证金使用率: %.1f%%\n",
+			risk.Equity, d.PositionSizeUSD/risk.Equity, risk.MarginUtilization)
+	}
+
+	fmt.Fprintf(&sb, "\n## 决策理由\n%s\n", d.Reasoning)
+	return sb.String()
+}
+
+// parseCritique 解析审查回复（兼容markdown代码块包裹）
+func parseCritique(response string, result *critiqueResult) error {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start < 0 || end <= start {
+		return fmt.Errorf("审查回复中没有JSON")
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), result); err != nil {
+		return fmt.Errorf("解析审查回复失败: %w", err)
+	}
+	result.Verdict = strings.ToLower(strings.TrimSpace(result.Verdict))
+	if result.Verdict != "approve" && result.Verdict != "block" {
+		return fmt.Errorf("审查结论无效: %q", result.Verdict)
+	}
+	return nil
+}
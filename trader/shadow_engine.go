@@ -0,0 +1,355 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"nofx/decision"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// EngineScore 单个决策引擎在影子对比中的模拟成绩
+type EngineScore struct {
+	Engine        string  `json:"engine"`
+	Decisions     int     `json:"decisions"` // 非hold/wait决策数
+	Opens         int     `json:"opens"`
+	Closes        int     `json:"closes"`
+	Wins          int     `json:"wins"`
+	Losses        int     `json:"losses"`
+	WinRate       float64 `json:"win_rate"`
+	TotalPnL      float64 `json:"total_pnl"`      // 模拟已实现盈亏（USDT，不含手续费）
+	AvgReturnPct  float64 `json:"avg_return_pct"` // 每笔平均价格收益率（未乘杠杆）
+	OpenPositions int     `json:"open_positions"`
+}
+
+// ShadowReport 🪞 主引擎与影子引擎的对比报告
+type ShadowReport struct {
+	Primary      EngineScore `json:"primary"`
+	Shadow       EngineScore `json:"shadow"`
+	Cycles       int         `json:"cycles"`
+	Errors       int         `json:"errors"`        // 影子引擎决策失败的周期数
+	AgreementPct float64     `json:"agreement_pct"` // 两个引擎对同一币种给出相同动作的比例
+	Since        time.Time   `json:"since"`
+}
+
+// shadowPosition 模拟持仓
+type shadowPosition struct {
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	Entry      float64   `json:"entry"`
+	StopLoss   float64   `json:"stop_loss"`
+	TakeProfit float64   `json:"take_profit"`
+	SizeUSD    float64   `json:"size_usd"` // 名义价值
+	OpenTime   time.Time `json:"open_time"`
+}
+
+// shadowBook 单个引擎的模拟账本
+type shadowBook struct {
+	Engine       string                     `json:"engine"`
+	Positions    map[string]*shadowPosition `json:"positions"` // symbol -> 持仓（每个币种最多一个方向）
+	Decisions    int                        `json:"decisions"`
+	Opens        int                        `json:"opens"`
+	Closes       int                        `json:"closes"`
+	Wins         int                        `json:"wins"`
+	Losses       int                        `json:"losses"`
+	TotalPnL     float64                    `json:"total_pnl"`
+	SumReturnPct float64                    `json:"sum_return_pct"`
+}
+
+// shadowState 影子对比持久化状态
+type shadowState struct {
+	Primary    *shadowBook `json:"primary"`
+	Shadow     *shadowBook `json:"shadow"`
+	Cycles     int         `json:"cycles"`
+	Errors     int         `json:"errors"`
+	Compared   int         `json:"compared"`
+	Agreements int         `json:"agreements"`
+	Since      time.Time   `json:"since"`
+}
+
+// shadowCycleRecord 每周期影子决策记录（JSONL）
+type shadowCycleRecord struct {
+	Time      time.Time           `json:"time"`
+	Cycle     int                 `json:"cycle"`
+	Engine    string              `json:"engine"`
+	Decisions []decision.Decision `json:"decisions,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	Agreement float64             `json:"agreement_pct"`
+}
+
+// ShadowEvaluator 🪞 影子引擎评估：每周期用两个引擎对同一上下文决策，只执行主引擎，
+// 两边的决策都按同一规则模拟成交（开仓按当时价格，之后按止损/止盈或平仓决策出场），得到可比的成绩
+type ShadowEvaluator struct {
+	mu        sync.Mutex
+	statePath string
+	logPath   string
+	state     shadowState
+}
+
+// NewShadowEvaluator 创建影子评估器（状态保存在 <dir>/shadow_eval.json，引擎组合变化时重新计数）
+func NewShadowEvaluator(dir, primary, shadow string) *ShadowEvaluator {
+	e := &ShadowEvaluator{
+		statePath: filepath.Join(dir, "shadow_eval.json"),
+		logPath:   filepath.Join(dir, "shadow_decisions.jsonl"),
+	}
+	if data, err := os.ReadFile(e.statePath); err == nil {
+		if err := json.Unmarshal(data, &e.state); err != nil {
+			log.Printf("⚠️  加载影子评估状态失败: %v", err)
+		}
+	}
+	if e.state.Primary == nil || e.state.Shadow == nil || e.state.Primary.Engine != primary || e.state.Shadow.Engine != shadow {
+		e.state = shadowState{
+			Primary: &shadowBook{Engine: primary, Positions: make(map[string]*shadowPosition)},
+			Shadow:  &shadowBook{Engine: shadow, Positions: make(map[string]*shadowPosition)},
+			Since:   time.Now(),
+		}
+	}
+	return e
+}
+
+// Observe 记录一个周期：先按当前价格检查模拟持仓的止损/止盈，再应用两个引擎的决策
+func (e *ShadowEvaluator) Observe(cycle int, prices map[string]float64, primary, shadow []decision.Decision, shadowErr error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.state.Cycles++
+	e.state.Primary.mark(prices)
+	e.state.Primary.apply(primary, prices)
+
+	record := shadowCycleRecord{Time: time.Now(), Cycle: cycle, Engine: e.state.Shadow.Engine}
+	if shadowErr != nil {
+		e.state.Errors++
+		record.Error = shadowErr.Error()
+	} else {
+		e.state.Shadow.mark(prices)
+		e.state.Shadow.apply(shadow, prices)
+		record.Decisions = shadow
+
+		compared, agreed := compareDecisions(primary, shadow)
+		e.state.Compared += compared
+		e.state.Agreements += agreed
+		if compared > 0 {
+			record.Agreement = float64(agreed) / float64(compared) * 100
+		} else {
+			record.Agreement = 100
+		}
+	}
+
+	e.save()
+	e.appendLog(record)
+}
+
+// Report 当前对比报告
+func (e *ShadowEvaluator) Report() ShadowReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	report := ShadowReport{
+		Primary: e.state.Primary.score(),
+		Shadow:  e.state.Shadow.score(),
+		Cycles:  e.state.Cycles,
+		Errors:  e.state.Errors,
+		Since:   e.state.Since,
+	}
+	if e.state.Compared > 0 {
+		report.AgreementPct = float64(e.state.Agreements) / float64(e.state.Compared) * 100
+	}
+	return report
+}
+
+func (e *ShadowEvaluator) save() {
+	data, err := json.MarshalIndent(e.state, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(e.statePath, data, 0644); err != nil {
+		log.Printf("⚠️  保存影子评估状态失败: %v", err)
+	}
+}
+
+func (e *ShadowEvaluator) appendLog(record shadowCycleRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(e.logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("⚠️  写入影子决策记录失败: %v", err)
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// mark 按当前价格检查止损/止盈是否触发（只在周期时点检查，按止损/止盈价成交）
+func (b *shadowBook) mark(prices map[string]float64) {
+	for symbol, pos := range b.Positions {
+		price, ok := prices[symbol]
+		if !ok || price <= 0 {
+			continue
+		}
+		long := pos.Side == "long"
+		switch {
+		case pos.StopLoss > 0 && ((long && price <= pos.StopLoss) || (!long && price >= pos.StopLoss)):
+			b.close(symbol, pos.StopLoss)
+		case pos.TakeProfit > 0 && ((long && price >= pos.TakeProfit) || (!long && price <= pos.TakeProfit)):
+			b.close(symbol, pos.TakeProfit)
+		}
+	}
+}
+
+// apply 应用一个周期的决策（先平后开，币种已有模拟持仓时忽略开仓）
+func (b *shadowBook) apply(decisions []decision.Decision, prices map[string]float64) {
+	for _, d := range sortDecisionsByPriority(decisions) {
+		if d.Action == "hold" || d.Action == "wait" {
+			continue
+		}
+		b.Decisions++
+		switch d.Action {
+		case "close_long", "close_short":
+			if pos, ok := b.Positions[d.Symbol]; ok && "close_"+pos.Side == d.Action {
+				if price := prices[d.Symbol]; price > 0 {
+					b.close(d.Symbol, price)
+				}
+			}
+		case "open_long", "open_short":
+			b.open(d.Symbol, d.Action[len("open_"):], d, prices[d.Symbol])
+		case "replace_position":
+			if pos, ok := b.Positions[d.CloseSymbol]; ok && pos.Side == d.CloseSide {
+				if price := prices[d.CloseSymbol]; price > 0 {
+					b.close(d.CloseSymbol, price)
+				}
+			}
+			b.open(d.Symbol, d.Side, d, prices[d.Symbol])
+		}
+	}
+}
+
+func (b *shadowBook) open(symbol, side string, d decision.Decision, price float64) {
+	if _, exists := b.Positions[symbol]; exists || price <= 0 || d.PositionSizeUSD <= 0 {
+		return
+	}
+	entry := price
+	if d.IsLimitOrder && d.LimitPrice > 0 {
+		entry = d.LimitPrice // 限价单按限价假设成交
+	}
+	b.Positions[symbol] = &shadowPosition{
+		Symbol:     symbol,
+		Side:       side,
+		Entry:      entry,
+		StopLoss:   d.StopLoss,
+		TakeProfit: d.TakeProfit,
+		SizeUSD:    d.PositionSizeUSD,
+		OpenTime:   time.Now(),
+	}
+	b.Opens++
+}
+
+func (b *shadowBook) close(symbol string, price float64) {
+	pos := b.Positions[symbol]
+	delete(b.Positions, symbol)
+
+	ret := (price - pos.Entry) / pos.Entry
+	if pos.Side == "short" {
+		ret = -ret
+	}
+	pnl := pos.SizeUSD * ret
+	b.Closes++
+	b.TotalPnL += pnl
+	b.SumReturnPct += ret * 100
+	if pnl > 0 {
+		b.Wins++
+	} else if pnl < 0 {
+		b.Losses++
+	}
+}
+
+func (b *shadowBook) score() EngineScore {
+	s := EngineScore{
+		Engine:        b.Engine,
+		Decisions:     b.Decisions,
+		Opens:         b.Opens,
+		Closes:        b.Closes,
+		Wins:          b.Wins,
+		Losses:        b.Losses,
+		TotalPnL:      b.TotalPnL,
+		OpenPositions: len(b.Positions),
+	}
+	if b.Closes > 0 {
+		s.WinRate = float64(b.Wins) / float64(b.Closes) * 100
+		s.AvgReturnPct = b.SumReturnPct / float64(b.Closes)
+	}
+	return s
+}
+
+// compareDecisions 比较两个引擎对同一批币种的动作（任一方非hold/wait的币种计入比较，缺省视为hold）
+func compareDecisions(primary, shadow []decision.Decision) (compared, agreed int) {
+	actions := func(ds []decision.Decision) map[string]string {
+		m := make(map[string]string)
+		for _, d := range ds {
+			if d.Action != "hold" && d.Action != "wait" {
+				m[d.Symbol] = d.Action
+			}
+		}
+		return m
+	}
+	p, s := actions(primary), actions(shadow)
+	for symbol, action := range p {
+		compared++
+		if s[symbol] == action {
+			agreed++
+		}
+	}
+	for symbol := range s {
+		if _, ok := p[symbol]; !ok {
+			compared++
+		}
+	}
+	return compared, agreed
+}
+
+// runShadowEngine 🪞 主引擎决策执行完成后，用影子引擎对同一上下文（复用已获取的市场数据）决策并记录模拟结果
+func (at *AutoTrader) runShadowEngine(ctx *decision.Context, primary *decision.FullDecision) string {
+	if at.shadow == nil {
+		return ""
+	}
+
+	shadowCtx := *ctx
+	shadowCtx.Deadline = time.Time{} // 主引擎已执行完毕，影子引擎不受周期预算限制
+	start := time.Now()
+	result, err := decision.DecideWithEngine(at.config.ShadowEngine, &shadowCtx, at.mcpClient)
+
+	prices := make(map[string]float64, len(ctx.MarketDataMap))
+	for symbol, md := range ctx.MarketDataMap {
+		if md != nil {
+			prices[symbol] = md.CurrentPrice
+		}
+	}
+	var shadowDecisions []decision.Decision
+	if result != nil {
+		shadowDecisions = result.Decisions
+	}
+	at.shadow.Observe(at.callCount, prices, primary.Decisions, shadowDecisions, err)
+
+	if err != nil {
+		log.Printf("⚠️  [%s] 影子引擎%s决策失败: %v", at.name, at.config.ShadowEngine, err)
+		return fmt.Sprintf("🪞 影子引擎%s决策失败: %v", at.config.ShadowEngine, err)
+	}
+	report := at.shadow.Report()
+	log.Printf("🪞 [%s] 影子引擎%s: %d个决策（耗时%v），累计一致率%.1f%% | 模拟盈亏 主%+.2f / 影子%+.2f USDT",
+		at.name, at.config.ShadowEngine, len(shadowDecisions), time.Since(start).Round(time.Second),
+		report.AgreementPct, report.Primary.TotalPnL, report.Shadow.TotalPnL)
+	return fmt.Sprintf("🪞 影子引擎%s: %d个决策（未执行）", at.config.ShadowEngine, len(shadowDecisions))
+}
+
+// GetShadowReport 影子引擎对比报告（未启用时返回nil）
+func (at *AutoTrader) GetShadowReport() *ShadowReport {
+	if at.shadow == nil {
+		return nil
+	}
+	report := at.shadow.Report()
+	return &report
+}
@@ -0,0 +1,130 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"nofx/logger"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultStalePriceStopFraction = 0.5 // 价格变动超过止损距离的该比例时放弃开仓
+	maxLatencySamples             = 200 // 状态API统计的最近执行延迟样本数
+)
+
+// priceSnapshot 本周期构建交易上下文时（AI预测所依据）的价格
+type priceSnapshot struct {
+	mu     sync.Mutex
+	time   time.Time
+	prices map[string]float64
+
+	latencies []time.Duration // 最近的决策→下单延迟
+	skipped   int             // 因价格过期放弃的开仓次数
+}
+
+// setPriceSnapshot 记录本周期交易上下文中的价格（周期开始时调用）
+func (at *AutoTrader) setPriceSnapshot(ctx *decision.Context) {
+	prices := make(map[string]float64, len(ctx.MarketDataMap))
+	for symbol, md := range ctx.MarketDataMap {
+		if md != nil && md.CurrentPrice > 0 {
+			prices[symbol] = md.CurrentPrice
+		}
+	}
+	s := &at.snapshot
+	s.mu.Lock()
+	s.time = time.Now()
+	s.prices = prices
+	s.mu.Unlock()
+}
+
+// stalePriceFraction 价格变动阈值（止损距离的比例，<=0表示不检查）
+func (at *AutoTrader) stalePriceFraction() float64 {
+	if at.config.StalePriceStopFraction == 0 {
+		return defaultStalePriceStopFraction
+	}
+	return at.config.StalePriceStopFraction
+}
+
+// checkStalePrice ⏱️ 下单前重新获取最新价：记录从预测快照到下单的延迟，价格变动超过止损距离的一定比例时放弃开仓（预测依据的形态已失效）
+func (at *AutoTrader) checkStalePrice(d *decision.Decision, actionRecord *logger.DecisionAction) error {
+	s := &at.snapshot
+	s.mu.Lock()
+	snapshotTime, snapshotPrice := s.time, s.prices[d.Symbol]
+	s.mu.Unlock()
+	if snapshotTime.IsZero() || snapshotPrice <= 0 {
+		return nil
+	}
+
+	latency := time.Since(snapshotTime)
+	actionRecord.DecisionLatencyMs = latency.Milliseconds()
+	actionRecord.SnapshotPrice = snapshotPrice
+	at.recordLatency(latency)
+
+	fraction := at.stalePriceFraction()
+	if fraction <= 0 || d.StopLoss <= 0 {
+		return nil
+	}
+	price, err := at.trader.GetMarketPrice(d.Symbol)
+	if err != nil || price <= 0 {
+		log.Printf("  ⚠️  下单前获取%s最新价失败: %v（跳过价格过期检查）", d.Symbol, err)
+		return nil
+	}
+	stopDistance := math.Abs(snapshotPrice - d.StopLoss)
+	if stopDistance <= 0 {
+		return nil
+	}
+
+	moveR := math.Abs(price-snapshotPrice) / stopDistance
+	actionRecord.PriceMoveR = moveR
+	if moveR <= fraction {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.skipped++
+	s.mu.Unlock()
+	return fmt.Errorf("⏱️ 价格已过期：预测后%.1f秒内%s从%.6g变动到%.6g（止损距离的%.0f%% > %.0f%%），放弃开仓",
+		latency.Seconds(), d.Symbol, snapshotPrice, price, moveR*100, fraction*100)
+}
+
+func (at *AutoTrader) recordLatency(latency time.Duration) {
+	s := &at.snapshot
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latencies = append(s.latencies, latency)
+	if len(s.latencies) > maxLatencySamples {
+		s.latencies = s.latencies[len(s.latencies)-maxLatencySamples:]
+	}
+}
+
+// getExecutionLatencyStatus 最近开仓的决策→下单延迟统计（状态API）
+func (at *AutoTrader) getExecutionLatencyStatus() map[string]interface{} {
+	s := &at.snapshot
+	s.mu.Lock()
+	samples := append([]time.Duration(nil), s.latencies...)
+	skipped := s.skipped
+	s.mu.Unlock()
+
+	status := map[string]interface{}{
+		"samples":           len(samples),
+		"stale_skips":       skipped,
+		"max_stop_fraction": at.stalePriceFraction(),
+	}
+	if len(samples) == 0 {
+		return status
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	total := time.Duration(0)
+	for _, l := range samples {
+		total += l
+	}
+	status["avg_ms"] = (total / time.Duration(len(samples))).Milliseconds()
+	status["p50_ms"] = samples[len(samples)/2].Milliseconds()
+	status["p95_ms"] = samples[len(samples)*95/100].Milliseconds()
+	status["max_ms"] = samples[len(samples)-1].Milliseconds()
+	return status
+}
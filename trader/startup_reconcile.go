@@ -0,0 +1,253 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// 未知持仓处理策略
+const (
+	UnknownPositionAdopt  = "adopt"  // 接管：交给AI管理（默认，与原有行为一致）
+	UnknownPositionClose  = "close"  // 平仓
+	UnknownPositionIgnore = "ignore" // 忽略：不交给AI管理，也不平仓
+)
+
+// openOrderLister 支持查询挂单的交易器（用于读取交易所上的止损止盈单）
+type openOrderLister interface {
+	GetOpenOrders(symbol string) ([]OrderResult, error)
+}
+
+// loggedOpen 决策日志中仍未平仓的开仓记录
+type loggedOpen struct {
+	Time       time.Time
+	StopLoss   float64
+	TakeProfit float64
+	Reasoning  string
+}
+
+// reconcileStartupPositions 启动对账：列出交易所持仓和止损止盈单，与保护信息/决策日志匹配
+// 已知持仓恢复开仓时间并导入止损止盈（交易所缺少止损时补设），未知持仓按配置接管、平仓或忽略
+func (at *AutoTrader) reconcileStartupPositions() error {
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	// 🧾 先核对崩溃前未完成的执行（补记已成交开仓的保护信息）
+	at.reconcileJournal(positions)
+
+	// 清理已不存在的持仓的保护信息（停机期间被止损/手动平仓）
+	currentKeys := make(map[string]bool)
+	for _, pos := range positions {
+		currentKeys[pos.Symbol+"_"+pos.Side] = true
+	}
+	for key, p := range at.orderManager.AllProtections() {
+		if !currentKeys[key] {
+			at.orderManager.RemoveProtection(p.Symbol, p.Side)
+		}
+	}
+
+	if len(positions) == 0 {
+		return nil
+	}
+
+	log.Printf("🔎 启动持仓对账：交易所当前有%d个持仓", len(positions))
+	openByLog := at.openPositionsFromLog()
+
+	policy := strings.ToLower(at.config.UnknownPositionPolicy)
+	if policy == "" {
+		policy = UnknownPositionAdopt
+	}
+
+	for _, pos := range positions {
+		symbol, side := pos.Symbol, pos.Side
+		posKey := symbol + "_" + side
+		quantity := pos.Quantity
+
+		exchangeSL, exchangeTP := at.protectiveOrderPrices(symbol, side)
+
+		// 1. 已持久化的保护信息 → 2. 决策日志中未平仓的开仓记录
+		var protection *PositionProtection
+		stored, known := at.orderManager.GetProtection(symbol, side)
+		if known {
+			copied := *stored
+			protection = &copied
+		} else {
+			if open, ok := openByLog[posKey]; ok {
+				protection = &PositionProtection{
+					Symbol:     symbol,
+					Side:       side,
+					StopLoss:   open.StopLoss,
+					TakeProfit: open.TakeProfit,
+					OpenTime:   open.Time,
+					Reasoning:  open.Reasoning,
+					Source:     "decision_log",
+				}
+				known = true
+			}
+		}
+
+		if known {
+			// 交易所上的实际挂单优先（可能已被移动止损更新过）
+			if exchangeSL > 0 {
+				protection.StopLoss = exchangeSL
+			}
+			if exchangeTP > 0 {
+				protection.TakeProfit = exchangeTP
+			}
+			at.adoptPosition(protection)
+			log.Printf("  ✓ [%s %s] 已匹配开仓记录（%s，开仓于%s）止损=%.4f 止盈=%.4f",
+				symbol, side, protection.Source, protection.OpenTime.Format("01-02 15:04"),
+				protection.StopLoss, protection.TakeProfit)
+
+			// 🙋 人工管理的持仓：不交给AI，也不补设止损
+			if protection.Manual {
+				at.ignoredPositions[posKey] = true
+				log.Printf("  🙋 [%s %s] 人工管理中（备注: %s），系统不操作", symbol, side, protection.Note)
+				continue
+			}
+
+			// 交易所缺少止损但记录中有 → 补设
+			if exchangeSL == 0 && protection.StopLoss > 0 && !at.config.ObserveMode {
+				at.restoreProtectiveOrders(symbol, side, quantity, protection)
+			}
+			continue
+		}
+
+		// 未知持仓
+		log.Printf("  ⚠️  [%s %s] 未找到开仓记录（非本系统开仓或记录已丢失），处理策略: %s", symbol, side, policy)
+		switch policy {
+		case UnknownPositionClose:
+			if at.config.ObserveMode {
+				log.Printf("  👁️ 观察模式不平仓，改为忽略")
+				at.ignoredPositions[posKey] = true
+				continue
+			}
+			if err := at.closeUnknownPosition(symbol, side); err != nil {
+				log.Printf("  ❌ 平仓失败: %v（改为忽略）", err)
+				at.ignoredPositions[posKey] = true
+			}
+		case UnknownPositionIgnore:
+			at.ignoredPositions[posKey] = true
+			log.Printf("  ⏭️  已忽略，AI不会管理该持仓")
+		default:
+			// 接管：开仓时间未知，沿用保守估计（60分钟前），止损止盈以交易所挂单为准
+			at.adoptPosition(&PositionProtection{
+				Symbol:     symbol,
+				Side:       side,
+				StopLoss:   exchangeSL,
+				TakeProfit: exchangeTP,
+				OpenTime:   time.Now().Add(-60 * time.Minute),
+				Source:     "adopted",
+			})
+			log.Printf("  ✓ 已接管，交给AI管理（止损=%.4f 止盈=%.4f）", exchangeSL, exchangeTP)
+		}
+	}
+
+	return nil
+}
+
+// adoptPosition 导入持仓保护信息并恢复开仓时间
+func (at *AutoTrader) adoptPosition(p *PositionProtection) {
+	posKey := p.Symbol + "_" + p.Side
+	at.orderManager.SetProtection(p)
+	at.positionFirstSeenTime[posKey] = p.OpenTime.UnixMilli()
+	at.constraints.RestorePositionOpenTime(p.Symbol, p.Side, p.OpenTime)
+}
+
+// restoreProtectiveOrders 补设缺失的止损止盈单
+func (at *AutoTrader) restoreProtectiveOrders(symbol, side string, quantity float64, p *PositionProtection) {
+	positionSide := strings.ToUpper(side)
+	log.Printf("  🚨 [%s %s] 交易所缺少止损单，按记录补设: 止损=%.4f 止盈=%.4f", symbol, side, p.StopLoss, p.TakeProfit)
+	if err := at.trader.SetStopLoss(symbol, positionSide, quantity, p.StopLoss); err != nil {
+		log.Printf("  ❌ 补设止损失败: %v", err)
+		return
+	}
+	at.restoreTakeProfits(symbol, side, quantity, p)
+}
+
+// closeUnknownPosition 平掉未知持仓（记录平仓冷却期）
+func (at *AutoTrader) closeUnknownPosition(symbol, side string) error {
+	var order *OrderResult
+	var err error
+	if side == "long" {
+		order, err = at.trader.CloseLong(symbol, 0)
+	} else {
+		order, err = at.trader.CloseShort(symbol, 0)
+	}
+	if err != nil {
+		return err
+	}
+	at.ledgerForcedClose(symbol, side, order, nil, "🚨 系统强制平仓")
+	at.constraints.RecordClosePosition(symbol, side, order.RealizedPnL)
+	at.manualCloseTracker[symbol+"_"+side] = time.Now()
+	log.Printf("  ✓ 已平仓未知持仓 %s %s", symbol, side)
+	return nil
+}
+
+// protectiveOrderPrices 读取交易所上该持仓的止损/止盈触发价（不支持查询挂单时返回0）
+func (at *AutoTrader) protectiveOrderPrices(symbol, side string) (stopLoss, takeProfit float64) {
+	lister, ok := at.trader.(openOrderLister)
+	if !ok {
+		return 0, 0
+	}
+	orders, err := lister.GetOpenOrders(symbol)
+	if err != nil {
+		log.Printf("  ⚠️  [%s] 查询挂单失败: %v", symbol, err)
+		return 0, 0
+	}
+
+	// 多仓的保护单是卖单，空仓的保护单是买单
+	closeSide := "SELL"
+	if side == "short" {
+		closeSide = "BUY"
+	}
+	for _, order := range orders {
+		if order.Side != closeSide {
+			continue
+		}
+		switch order.Type {
+		case "STOP_MARKET", "STOP":
+			stopLoss = order.StopPrice
+		case "TAKE_PROFIT_MARKET", "TAKE_PROFIT":
+			takeProfit = order.StopPrice
+		}
+	}
+	return stopLoss, takeProfit
+}
+
+// openPositionsFromLog 从决策日志找出仍未平仓的开仓记录（symbol_side → 最后一次开仓）
+func (at *AutoTrader) openPositionsFromLog() map[string]loggedOpen {
+	opens := make(map[string]loggedOpen)
+
+	// 每3分钟一个周期：2000条 ≈ 4天
+	records, err := at.decisionLogger.GetLatestRecords(2000)
+	if err != nil {
+		log.Printf("  ⚠️  读取决策日志失败: %v", err)
+		return opens
+	}
+
+	for _, record := range records {
+		for _, action := range record.Decisions {
+			if !action.Success || action.Hypothetical {
+				continue
+			}
+			switch action.Action {
+			case "open_long", "open_short":
+				side := strings.TrimPrefix(action.Action, "open_")
+				opens[action.Symbol+"_"+side] = loggedOpen{
+					Time:       action.Timestamp,
+					StopLoss:   action.StopLoss,
+					TakeProfit: action.TakeProfit,
+					Reasoning:  action.Reasoning,
+				}
+			case "close_long", "close_short":
+				side := strings.TrimPrefix(action.Action, "close_")
+				delete(opens, action.Symbol+"_"+side)
+			}
+		}
+	}
+	return opens
+}
@@ -0,0 +1,113 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"nofx/decision"
+	"strconv"
+	"strings"
+)
+
+const defaultMinStopTicks = 2 // 止损止盈距离当前价的最少价格步进数
+
+// 止损距离不足时的处理
+const (
+	StopDistanceAdjust = "adjust" // 推远到最小距离（默认）
+	StopDistanceReject = "reject" // 拒绝开仓
+)
+
+// priceFormatter 支持按交易所价格精度格式化的交易器
+type priceFormatter interface {
+	FormatPrice(symbol string, price float64) (string, error)
+}
+
+// priceTick 按交易所精度格式化后的价格和价格步进（按格式化结果的小数位推算）
+func priceTick(formatter priceFormatter, symbol string, price float64) (float64, float64, error) {
+	s, err := formatter.FormatPrice(symbol, price)
+	if err != nil {
+		return 0, 0, err
+	}
+	formatted, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("解析格式化价格失败: %w", err)
+	}
+	tick := 1.0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		tick = math.Pow(10, -float64(len(s)-i-1))
+	}
+	return formatted, tick, nil
+}
+
+// enforceStopDistance 📏 校验止损止盈按交易所精度格式化后与当前价的距离
+// 低价币按ATR算出的止损四舍五入后可能等于当前价（下单即触发）：距离至少min_stop_ticks个价格步进且不小于min_stop_distance_pct%，
+// 不足时按stop_distance_action推远到最小距离或拒绝开仓
+func (at *AutoTrader) enforceStopDistance(d *decision.Decision, side string, price float64) error {
+	if price <= 0 {
+		return nil
+	}
+
+	minDist := price * at.config.MinStopDistancePct / 100
+	tick := 0.0
+	formatter, ok := at.trader.(priceFormatter)
+	if ok {
+		var err error
+		if price, tick, err = priceTick(formatter, d.Symbol, price); err != nil {
+			log.Printf("  ⚠️  获取%s价格精度失败: %v（跳过止损距离校验）", d.Symbol, err)
+			return nil
+		}
+		minTicks := at.config.MinStopTicks
+		if minTicks == 0 {
+			minTicks = defaultMinStopTicks
+		}
+		minDist = math.Max(minDist, float64(minTicks)*tick)
+	}
+	if minDist <= 0 {
+		return nil
+	}
+
+	// direction: 止损/止盈相对当前价的有利方向（做多止损在下方=-1）
+	check := func(name string, target *float64, direction float64) error {
+		if *target <= 0 {
+			return nil
+		}
+		formatted := *target
+		if ok {
+			if f, _, err := priceTick(formatter, d.Symbol, *target); err == nil {
+				formatted = f
+			}
+		}
+		dist := (formatted - price) * direction
+		if dist >= minDist-1e-12 {
+			return nil
+		}
+		if at.config.StopDistanceAction == StopDistanceReject {
+			return fmt.Errorf("❌ %s %s%.8g距当前价%.8g过近（%.8g < 最小%.8g），拒绝开仓", d.Symbol, name, formatted, price, dist, minDist)
+		}
+
+		adjusted := price + direction*minDist
+		if tick > 0 {
+			// 按价格步进向远离当前价的方向取整，格式化后距离不会再变小
+			if direction > 0 {
+				adjusted = math.Ceil(adjusted/tick-1e-9) * tick
+			} else {
+				adjusted = math.Floor(adjusted/tick+1e-9) * tick
+			}
+		}
+		if adjusted <= 0 {
+			return fmt.Errorf("❌ %s %s无法调整到最小距离%.8g（当前价%.8g）", d.Symbol, name, minDist, price)
+		}
+		log.Printf("  📏 %s %s距当前价过近: %.8g → %.8g（最小距离%.8g）", d.Symbol, name, *target, adjusted, minDist)
+		*target = adjusted
+		return nil
+	}
+
+	slDir, tpDir := -1.0, 1.0
+	if side == "short" {
+		slDir, tpDir = 1.0, -1.0
+	}
+	if err := check("止损", &d.StopLoss, slDir); err != nil {
+		return err
+	}
+	return check("止盈", &d.TakeProfit, tpDir)
+}
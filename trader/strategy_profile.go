@@ -0,0 +1,151 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision/agents"
+	"nofx/market"
+	"sort"
+	"time"
+)
+
+// StrategyProfile 策略档案：打包扫描周期、K线周期、开仓阈值、止损倍数、仓位分配和持仓时长
+type StrategyProfile struct {
+	Name                    string
+	Description             string
+	ScanInterval            time.Duration
+	KlineInterval           string
+	Tuning                  agents.EntryTuning
+	PositionAllocation      string
+	MaxNewPositionsPerCycle int
+	MaxHoldTrend            time.Duration
+	MaxHoldRange            time.Duration
+}
+
+// strategyProfiles 内置策略档案
+var strategyProfiles = map[string]StrategyProfile{
+	"scalper": {
+		Name:                    "scalper",
+		Description:             "短线：高频扫描、小周期K线、较紧止损、短持仓",
+		ScanInterval:            2 * time.Minute,
+		KlineInterval:           "3m",
+		Tuning:                  agents.EntryTuning{MinProbability: 0.62, StopATRMultiple: 3.0},
+		PositionAllocation:      agents.AllocationEqualWeight,
+		MaxNewPositionsPerCycle: 2,
+		MaxHoldTrend:            4 * time.Hour,
+		MaxHoldRange:            2 * time.Hour,
+	},
+	"swing": {
+		Name:                    "swing",
+		Description:             "波段：低频扫描、大周期K线、宽止损、长持仓",
+		ScanInterval:            15 * time.Minute,
+		KlineInterval:           "30m",
+		Tuning:                  agents.EntryTuning{MinProbability: 0.68, StopATRMultiple: 6.0},
+		PositionAllocation:      agents.AllocationRiskParity,
+		MaxNewPositionsPerCycle: 1,
+		MaxHoldTrend:            72 * time.Hour,
+		MaxHoldRange:            24 * time.Hour,
+	},
+	"conservative": {
+		Name:                    "conservative",
+		Description:             "稳健：只做高信心机会，风险平价分配，默认持仓时长",
+		ScanInterval:            5 * time.Minute,
+		KlineInterval:           "15m",
+		Tuning:                  agents.EntryTuning{MinProbability: 0.75, HighConfidenceOnly: true, StopATRMultiple: 5.0},
+		PositionAllocation:      agents.AllocationRiskParity,
+		MaxNewPositionsPerCycle: 1,
+		MaxHoldTrend:            defaultMaxHoldTrend,
+		MaxHoldRange:            defaultMaxHoldRange,
+	},
+}
+
+// GetStrategyProfile 按名称获取内置策略档案
+func GetStrategyProfile(name string) (StrategyProfile, bool) {
+	p, ok := strategyProfiles[name]
+	return p, ok
+}
+
+// StrategyProfileNames 内置策略档案名称（按字母排序）
+func StrategyProfileNames() []string {
+	names := make([]string, 0, len(strategyProfiles))
+	for name := range strategyProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyStrategyProfile 用策略档案补全未显式配置的参数（启动时调用，显式配置优先）
+func applyStrategyProfile(config *AutoTraderConfig) error {
+	if config.StrategyProfile == "" {
+		return nil
+	}
+	p, ok := strategyProfiles[config.StrategyProfile]
+	if !ok {
+		return fmt.Errorf("未知的策略档案: %s", config.StrategyProfile)
+	}
+
+	if config.ScanInterval == 0 {
+		config.ScanInterval = p.ScanInterval
+	}
+	if config.KlineInterval == "" {
+		config.KlineInterval = p.KlineInterval
+	}
+	if config.Tuning == (agents.EntryTuning{}) {
+		config.Tuning = p.Tuning
+	}
+	if config.PositionAllocation == "" {
+		config.PositionAllocation = p.PositionAllocation
+	}
+	if config.MaxNewPositionsPerCycle == 0 {
+		config.MaxNewPositionsPerCycle = p.MaxNewPositionsPerCycle
+	}
+	if config.MaxHoldTrend == 0 {
+		config.MaxHoldTrend = p.MaxHoldTrend
+	}
+	if config.MaxHoldRange == 0 {
+		config.MaxHoldRange = p.MaxHoldRange
+	}
+	return nil
+}
+
+// SwitchStrategyProfile 运行时切换策略档案（下一个决策周期开始时生效，档案参数整体替换当前配置）
+func (at *AutoTrader) SwitchStrategyProfile(name string) error {
+	if _, ok := strategyProfiles[name]; !ok {
+		return fmt.Errorf("未知的策略档案: %s（可选: %v）", name, StrategyProfileNames())
+	}
+
+	at.control.mu.Lock()
+	defer at.control.mu.Unlock()
+	at.control.pendingProfile = name
+	return nil
+}
+
+// applyPendingProfile 应用待切换的策略档案（在决策周期开始时调用）
+func (at *AutoTrader) applyPendingProfile() {
+	at.control.mu.Lock()
+	name := at.control.pendingProfile
+	at.control.pendingProfile = ""
+	at.control.mu.Unlock()
+
+	p, ok := strategyProfiles[name]
+	if !ok {
+		return
+	}
+
+	log.Printf("🎛️  [%s] 切换策略档案: %s → %s（%s）", at.name, at.config.StrategyProfile, p.Name, p.Description)
+	at.config.StrategyProfile = p.Name
+	at.config.ScanInterval = p.ScanInterval
+	at.adaptive.mu.Lock()
+	at.adaptive.interval = 0 // ⏱️ 自适应间隔按新档案重新计算
+	at.adaptive.mu.Unlock()
+	at.config.Tuning = p.Tuning
+	at.config.PositionAllocation = p.PositionAllocation
+	at.config.MaxNewPositionsPerCycle = p.MaxNewPositionsPerCycle
+	at.config.MaxHoldTrend = p.MaxHoldTrend
+	at.config.MaxHoldRange = p.MaxHoldRange
+	if at.config.KlineInterval != p.KlineInterval {
+		at.config.KlineInterval = p.KlineInterval
+		market.SetDefaultInterval(p.KlineInterval)
+	}
+}
@@ -0,0 +1,172 @@
+package trader
+
+import (
+	"log"
+	"nofx/decision"
+	"nofx/logger"
+	"strings"
+	"time"
+)
+
+// 🏷️ 策略来源标签：从决策经客户端订单ID传到成交台账，报表按策略拆分绩效
+const (
+	StrategyAI     = "ai"     // AI市价开平仓
+	StrategyLimit  = "limit"  // AI限价单
+	StrategyBasis  = "basis"  // 基差策略
+	StrategyManual = "manual" // 手动开仓后被接管的持仓
+)
+
+// StrategyFromClientOrderID 从客户端订单ID（nofx_<strategy>_<hash>）解析策略标签，无法识别时返回空
+func StrategyFromClientOrderID(id string) string {
+	parts := strings.Split(id, "_")
+	if len(parts) != 3 || parts[0] != "nofx" {
+		return ""
+	}
+	switch parts[1] {
+	case StrategyAI, StrategyLimit, StrategyBasis, StrategyManual:
+		return parts[1]
+	}
+	return ""
+}
+
+// positionStrategy 持仓的策略来源（按开仓时记录的持仓保护信息，接管的持仓视为手动）
+func (at *AutoTrader) positionStrategy(symbol, side string) string {
+	p, ok := at.orderManager.GetProtection(symbol, side)
+	switch {
+	case !ok:
+		return StrategyAI
+	case p.Strategy != "":
+		return p.Strategy
+	case p.Source == "adopted":
+		return StrategyManual
+	}
+	return StrategyAI
+}
+
+// tagStrategy 为执行记录打上策略标签：开仓按决策类型，平仓沿用持仓开仓时的策略
+func (at *AutoTrader) tagStrategy(d *decision.Decision, actionRecord *logger.DecisionAction) {
+	if actionRecord.Strategy != "" {
+		return
+	}
+	switch d.Action {
+	case "open_long", "open_short":
+		actionRecord.Strategy = StrategyAI
+		if d.IsLimitOrder {
+			actionRecord.Strategy = StrategyLimit
+		}
+	case "close_long":
+		actionRecord.Strategy = at.positionStrategy(d.Symbol, "long")
+	case "close_short":
+		actionRecord.Strategy = at.positionStrategy(d.Symbol, "short")
+	}
+}
+
+// recordLedger 写入成交台账（失败只打印警告）
+func (at *AutoTrader) recordLedger(entry logger.LedgerEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if err := at.decisionLogger.LogLedgerEntry(entry); err != nil {
+		log.Printf("⚠️  [%s] 写入成交台账失败: %v", at.name, err)
+	}
+}
+
+// ledgerFromAction 把成功执行的开平仓决策写入台账（限价单在成交时写入）
+func (at *AutoTrader) ledgerFromAction(actionRecord *logger.DecisionAction) {
+	if actionRecord.Hypothetical || (actionRecord.Strategy == StrategyLimit && strings.HasPrefix(actionRecord.Action, "open_")) {
+		return
+	}
+	var action, side string
+	switch actionRecord.Action {
+	case "open_long", "open_short":
+		action, side = "open", strings.TrimPrefix(actionRecord.Action, "open_")
+	case "close_long", "close_short":
+		action, side = "close", strings.TrimPrefix(actionRecord.Action, "close_")
+	default:
+		return
+	}
+	entry := logger.LedgerEntry{
+		Time:          actionRecord.Timestamp,
+		Strategy:      actionRecord.Strategy,
+		Symbol:        actionRecord.Symbol,
+		Side:          side,
+		Action:        action,
+		Quantity:      actionRecord.Quantity,
+		Price:         actionRecord.Price,
+		PnL:           actionRecord.RealizedPnL,
+		Fees:          actionRecord.Commission,
+		ClientOrderID: actionRecord.ClientOrderID,
+		DecisionID:    actionRecord.DecisionID,
+	}
+	if action == "open" {
+		entry.Cycle = at.callCount
+		entry.Risk = openRisk(actionRecord.Price, actionRecord.StopLoss, actionRecord.Quantity)
+	}
+	at.recordLedger(entry)
+}
+
+// GetStrategyReport 按策略来源拆分的绩效（since之后）
+func (at *AutoTrader) GetStrategyReport(since time.Time) ([]*logger.StrategyPerformance, error) {
+	return at.decisionLogger.GetStrategyReport(since)
+}
+
+// recordLimitFill 限价单成交后写入台账，并在持仓保护信息中记下策略来源（平仓时沿用）
+func (at *AutoTrader) recordLimitFill(order *LimitOrder, side string) {
+	quantity := order.Quantity
+	if order.FilledQty > 0 {
+		quantity = order.FilledQty
+	}
+	price := order.Price
+	if order.AvgPrice > 0 {
+		price = order.AvgPrice
+	}
+	at.recordLedger(logger.LedgerEntry{
+		Strategy:      StrategyLimit,
+		Symbol:        order.Symbol,
+		Side:          side,
+		Action:        "open",
+		Quantity:      quantity,
+		Price:         price,
+		ClientOrderID: order.ClientOrderID,
+		Cycle:         order.Cycle,
+		DecisionID:    order.DecisionID,
+		Risk:          openRisk(price, order.StopLoss, quantity),
+	})
+	at.orderManager.SetProtection(&PositionProtection{
+		Symbol:     order.Symbol,
+		Side:       side,
+		StopLoss:   order.StopLoss,
+		TakeProfit: order.TakeProfit,
+		OpenTime:   time.Now(),
+		Reasoning:  order.Reasoning,
+		Source:     "open",
+		Strategy:   StrategyLimit,
+	})
+}
+
+// ledgerVanished 交易所侧平仓（止损/止盈/强平）写入台账，盈亏按成交价估算（无成交价时用上次标记价）
+func (at *AutoTrader) ledgerVanished(last decision.PositionInfo, strategy string, audit *logger.CloseFillRecord) {
+	price := audit.FillPrice
+	if price <= 0 {
+		price = last.MarkPrice
+	}
+	quantity := audit.Quantity
+	if quantity <= 0 {
+		quantity = last.Quantity
+	}
+	pnl := (price - last.EntryPrice) * quantity
+	if last.Side == "short" {
+		pnl = -pnl
+	}
+	at.recordLedger(logger.LedgerEntry{
+		Time:     audit.Time,
+		Strategy: strategy,
+		Symbol:   last.Symbol,
+		Side:     last.Side,
+		Action:   "close",
+		Quantity: quantity,
+		Price:    price,
+		PnL:      pnl,
+		Note:     audit.Reason,
+	})
+}
@@ -0,0 +1,128 @@
+package trader
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 币种类别（constraint_overrides 的键，除币种名外）
+const (
+	SymbolClassMajors = "majors" // 主流币
+	SymbolClassAlts   = "alts"   // 其余币种
+)
+
+// defaultMajorSymbols 未配置 major_symbols 时的主流币
+var defaultMajorSymbols = []string{"BTCUSDT", "ETHUSDT"}
+
+// SymbolConstraints 按币种/类别覆盖的硬约束（0=继承上一级）
+type SymbolConstraints struct {
+	CooldownMinutes   int `json:"cooldown_minutes"`    // 同币种冷却期（分钟）
+	MinHoldingMinutes int `json:"min_holding_minutes"` // 最短持仓时间（分钟）
+	MaxHourlyTrades   int `json:"max_hourly_trades"`   // 该币种每小时最多开仓次数（0=只受全局上限约束）
+	MaxDailyTrades    int `json:"max_daily_trades"`    // 该币种每24小时最多开仓次数（0=只受全局上限约束）
+}
+
+// merge 用o中非零字段覆盖c
+func (c SymbolConstraints) merge(o SymbolConstraints) SymbolConstraints {
+	if o.CooldownMinutes > 0 {
+		c.CooldownMinutes = o.CooldownMinutes
+	}
+	if o.MinHoldingMinutes > 0 {
+		c.MinHoldingMinutes = o.MinHoldingMinutes
+	}
+	if o.MaxHourlyTrades > 0 {
+		c.MaxHourlyTrades = o.MaxHourlyTrades
+	}
+	if o.MaxDailyTrades > 0 {
+		c.MaxDailyTrades = o.MaxDailyTrades
+	}
+	return c
+}
+
+// SetSymbolOverrides 设置按类别（majors/alts）和币种的约束覆盖，生效顺序：全局默认 < 类别 < 币种
+// majors为空时主流币为BTCUSDT、ETHUSDT
+func (tc *TradingConstraints) SetSymbolOverrides(majors []string, overrides map[string]SymbolConstraints) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if len(majors) == 0 {
+		majors = defaultMajorSymbols
+	}
+	tc.majorSymbols = make(map[string]bool, len(majors))
+	for _, s := range majors {
+		tc.majorSymbols[strings.ToUpper(s)] = true
+	}
+	tc.overrides = make(map[string]SymbolConstraints, len(overrides))
+	for key, o := range overrides {
+		if key != SymbolClassMajors && key != SymbolClassAlts {
+			key = strings.ToUpper(key)
+		}
+		tc.overrides[key] = o
+	}
+}
+
+// symbolClass 币种类别（调用方持有锁）
+func (tc *TradingConstraints) symbolClass(symbol string) string {
+	if tc.majorSymbols[symbol] {
+		return SymbolClassMajors
+	}
+	return SymbolClassAlts
+}
+
+// limitsFor 币种的生效约束（调用方持有锁）
+func (tc *TradingConstraints) limitsFor(symbol string) SymbolConstraints {
+	limits := SymbolConstraints{CooldownMinutes: tc.cooldownMinutes, MinHoldingMinutes: tc.minHoldingMinutes}
+	if len(tc.overrides) == 0 {
+		return limits
+	}
+	limits = limits.merge(tc.overrides[tc.symbolClass(symbol)])
+	return limits.merge(tc.overrides[symbol])
+}
+
+// configuredCooldown 币种或其类别显式配置的冷却期（分钟，调用方持有锁）
+func (tc *TradingConstraints) configuredCooldown(symbol string) (int, bool) {
+	if o := tc.overrides[symbol]; o.CooldownMinutes > 0 {
+		return o.CooldownMinutes, true
+	}
+	if o := tc.overrides[tc.symbolClass(symbol)]; o.CooldownMinutes > 0 {
+		return o.CooldownMinutes, true
+	}
+	return 0, false
+}
+
+// checkSymbolTradeCaps 该币种的每小时/每日开仓次数上限（调用方持有锁）
+func (tc *TradingConstraints) checkSymbolTradeCaps(symbol string, limits SymbolConstraints, now time.Time) error {
+	if limits.MaxHourlyTrades <= 0 && limits.MaxDailyTrades <= 0 {
+		return nil
+	}
+	hourly, daily := 0, 0
+	for _, t := range tc.symbolOpens[symbol] {
+		age := now.Sub(t)
+		if age < 24*time.Hour {
+			daily++
+		}
+		if age < time.Hour {
+			hourly++
+		}
+	}
+	if limits.MaxDailyTrades > 0 && daily >= limits.MaxDailyTrades {
+		return fmt.Errorf("%s 日交易上限：过去24小时已开仓 %d 次（该币种最多 %d 次/天）", symbol, daily, limits.MaxDailyTrades)
+	}
+	if limits.MaxHourlyTrades > 0 && hourly >= limits.MaxHourlyTrades {
+		return fmt.Errorf("%s 小时交易上限：过去1小时已开仓 %d 次（该币种最多 %d 次/小时）", symbol, hourly, limits.MaxHourlyTrades)
+	}
+	return nil
+}
+
+// recordSymbolOpen 记录币种开仓时间（只保留24小时内，调用方持有锁）
+func (tc *TradingConstraints) recordSymbolOpen(symbol string, now time.Time) {
+	opens := tc.symbolOpens[symbol]
+	kept := opens[:0]
+	for _, t := range opens {
+		if now.Sub(t) < 24*time.Hour {
+			kept = append(kept, t)
+		}
+	}
+	tc.symbolOpens[symbol] = append(kept, now)
+}
@@ -0,0 +1,268 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/errs"
+	"nofx/events"
+	"nofx/market"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SymbolStatusTrading = "TRADING" // 正常交易（其余如 SETTLING/BREAK/PENDING_TRADING/CLOSE 均不可下单）
+
+	symbolStatusTTL     = 5 * time.Minute
+	maintenanceMinShare = 0.9 // 超过该比例的永续合约不在TRADING状态时视为交易所整体维护
+
+	invalidSymbolSkip  = 6 * time.Hour // 交易所报交易对无效后跳过该币种的时间
+	precisionErrorSkip = time.Hour     // 精度/最小下单量被拒后跳过该币种的时间（同时刷新精度缓存）
+)
+
+// symbolStatusProvider 支持查询交易对状态的交易器（exchangeInfo，refresh=true时忽略缓存）
+type symbolStatusProvider interface {
+	GetSymbolStatuses(refresh bool) (map[string]string, error)
+}
+
+// symbolStatusCache 交易对状态缓存（exchangeInfo较重，按TTL刷新）
+type symbolStatusCache struct {
+	mu        sync.RWMutex
+	statuses  map[string]string
+	fetchedAt time.Time
+}
+
+// get 未过期的缓存（force=true时视为过期）
+func (c *symbolStatusCache) get(force bool) (map[string]string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.statuses == nil || force || time.Since(c.fetchedAt) >= symbolStatusTTL {
+		return c.statuses, false
+	}
+	return c.statuses, true
+}
+
+func (c *symbolStatusCache) set(statuses map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statuses = statuses
+	c.fetchedAt = time.Now()
+}
+
+// isMaintenanceError 交易所返回的错误是否表示系统维护
+func isMaintenanceError(err error) bool {
+	return errs.Is(err, errs.Maintenance)
+}
+
+// exchangeStatus 交易所维护状态与非交易中的币种（主循环更新，状态API读取）
+type exchangeStatus struct {
+	mu               sync.Mutex
+	maintenance      bool
+	since            time.Time
+	reason           string
+	statuses         map[string]string // 最近一次获取的全部交易对状态
+	skippedLastCycle []string          // 上一周期因非TRADING状态移出候选池的币种
+	rejected         map[string]rejectedSymbol
+}
+
+// rejectedSymbol 下单被交易所按交易对无效/精度错误拒绝的币种（到期前移出候选池）
+type rejectedSymbol struct {
+	Kind  string    `json:"kind"`
+	Until time.Time `json:"until"`
+}
+
+// refreshSymbolStatus 刷新交易对状态并判断交易所是否在维护（不支持的交易所返回false）
+// 维护中每个周期都重新获取，恢复后按TTL缓存
+func (at *AutoTrader) refreshSymbolStatus() bool {
+	provider, ok := at.trader.(symbolStatusProvider)
+	if !ok {
+		return false
+	}
+
+	s := &at.exchangeState
+	s.mu.Lock()
+	wasMaintenance := s.maintenance
+	s.mu.Unlock()
+
+	statuses, err := provider.GetSymbolStatuses(wasMaintenance)
+	maintenance, reason := false, ""
+	switch {
+	case err != nil && isMaintenanceError(err):
+		maintenance, reason = true, err.Error()
+	case err != nil:
+		log.Printf("⚠️  [%s] 获取交易对状态失败: %v（沿用上次结果）", at.name, err)
+		return wasMaintenance
+	default:
+		total, halted := 0, 0
+		for symbol, status := range statuses {
+			if !strings.HasSuffix(symbol, "USDT") {
+				continue
+			}
+			total++
+			if status != SymbolStatusTrading {
+				halted++
+			}
+		}
+		if total > 0 && float64(halted)/float64(total) >= maintenanceMinShare {
+			maintenance = true
+			reason = fmt.Sprintf("%d/%d个合约不在交易状态", halted, total)
+		}
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	if statuses != nil {
+		s.statuses = statuses
+	}
+	since := s.since
+	if maintenance && !wasMaintenance {
+		s.since = now
+	}
+	s.maintenance, s.reason = maintenance, reason
+	s.mu.Unlock()
+
+	switch {
+	case maintenance && !wasMaintenance:
+		log.Printf("🚧 [%s] 交易所维护中（%s），暂停决策周期，恢复后自动继续", at.name, reason)
+		at.events.Publish(events.RiskPausedEvent{TraderID: at.id, Reason: "交易所维护: " + reason, Time: now})
+	case !maintenance && wasMaintenance:
+		log.Printf("✅ [%s] 交易所维护结束（持续%.0f分钟），恢复决策周期", at.name, now.Sub(since).Minutes())
+		if binanceTrader, ok := at.trader.(*FuturesTrader); ok {
+			binanceTrader.InvalidatePositionsCache()
+		}
+	}
+	return maintenance
+}
+
+// exchangeMaintenance 交易所是否处于维护状态（维护中的周期开始时重新检查）
+func (at *AutoTrader) exchangeMaintenance() bool {
+	at.exchangeState.mu.Lock()
+	maintenance := at.exchangeState.maintenance
+	at.exchangeState.mu.Unlock()
+	if !maintenance {
+		return false
+	}
+	return at.refreshSymbolStatus()
+}
+
+// symbolStatus 币种的交易状态（未知返回空）
+func (at *AutoTrader) symbolStatus(symbol string) string {
+	at.exchangeState.mu.Lock()
+	defer at.exchangeState.mu.Unlock()
+	return at.exchangeState.statuses[symbol]
+}
+
+// filterTradableCandidates 🚧 移除不在TRADING状态的候选币种（SETTLING/BREAK等状态下单会失败）
+func (at *AutoTrader) filterTradableCandidates(coins []decision.CandidateCoin) []decision.CandidateCoin {
+	at.refreshSymbolStatus()
+
+	var skipped []string
+	kept := coins[:0]
+	for _, coin := range coins {
+		if status := at.symbolStatus(coin.Symbol); status != "" && status != SymbolStatusTrading {
+			skipped = append(skipped, coin.Symbol+"("+status+")")
+			continue
+		}
+		if r, ok := at.symbolRejected(coin.Symbol); ok {
+			skipped = append(skipped, coin.Symbol+"("+r.Kind+")")
+			continue
+		}
+		kept = append(kept, coin)
+	}
+	if len(skipped) > 0 {
+		log.Printf("🚧 跳过非交易状态的候选币种: %s", strings.Join(skipped, ", "))
+	}
+
+	at.exchangeState.mu.Lock()
+	at.exchangeState.skippedLastCycle = skipped
+	at.exchangeState.mu.Unlock()
+	return kept
+}
+
+// checkSymbolTradable 开仓前确认币种处于TRADING状态
+func (at *AutoTrader) checkSymbolTradable(symbol string) error {
+	if status := at.symbolStatus(symbol); status != "" && status != SymbolStatusTrading {
+		return fmt.Errorf("%s 当前状态为%s（非交易中），暂不可开仓", symbol, status)
+	}
+	if r, ok := at.symbolRejected(symbol); ok {
+		return fmt.Errorf("%s 下单曾被交易所拒绝（%s），%s前暂不开仓", symbol, r.Kind, r.Until.Format("15:04"))
+	}
+	return nil
+}
+
+// skipRejectedSymbol 🏷️ 下单因交易对无效或精度错误被拒时暂时跳过该币种（重试不会成功，避免AI每个周期重复选中）
+func (at *AutoTrader) skipRejectedSymbol(symbol string, err error) {
+	var skip time.Duration
+	switch {
+	case errs.Is(err, errs.InvalidSymbol):
+		skip = invalidSymbolSkip
+	case errs.Is(err, errs.PrecisionError):
+		skip = precisionErrorSkip
+		go at.prefetchPrecisions() // 交易所可能调整了tickSize/stepSize
+	default:
+		return
+	}
+
+	r := rejectedSymbol{Kind: errs.KindOf(err).String(), Until: time.Now().Add(skip)}
+	s := &at.exchangeState
+	s.mu.Lock()
+	if s.rejected == nil {
+		s.rejected = make(map[string]rejectedSymbol)
+	}
+	s.rejected[symbol] = r
+	s.mu.Unlock()
+	log.Printf("🏷️  [%s] %s 下单被拒（%s），%v内不再作为候选", at.name, symbol, r.Kind, skip)
+}
+
+// symbolRejected 币种是否在下单被拒后的跳过期内
+func (at *AutoTrader) symbolRejected(symbol string) (rejectedSymbol, bool) {
+	s := &at.exchangeState
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.rejected[symbol]
+	if ok && time.Now().After(r.Until) {
+		delete(s.rejected, symbol)
+		return r, false
+	}
+	return r, ok
+}
+
+// getSymbolStatusReport 交易所维护与非交易币种状态（状态API）
+func (at *AutoTrader) getSymbolStatusReport() map[string]interface{} {
+	if _, ok := at.trader.(symbolStatusProvider); !ok {
+		return nil
+	}
+	s := &at.exchangeState
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var halted []string
+	for symbol, status := range s.statuses {
+		if status != SymbolStatusTrading && market.HasQuoteAsset(symbol) {
+			halted = append(halted, symbol+":"+status)
+		}
+	}
+	sort.Strings(halted)
+	report := map[string]interface{}{
+		"maintenance":        s.maintenance,
+		"non_trading":        halted,
+		"skipped_candidates": s.skippedLastCycle,
+	}
+	rejected := make(map[string]rejectedSymbol, len(s.rejected))
+	for symbol, r := range s.rejected {
+		if time.Now().Before(r.Until) {
+			rejected[symbol] = r
+		}
+	}
+	if len(rejected) > 0 {
+		report["rejected_symbols"] = rejected
+	}
+	if s.maintenance {
+		report["since"] = s.since
+		report["reason"] = s.reason
+	}
+	return report
+}
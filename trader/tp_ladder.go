@@ -0,0 +1,151 @@
+package trader
+
+import (
+	"log"
+	"math"
+	"nofx/decision"
+	"strings"
+	"time"
+)
+
+// TakeProfitRung 确定性分批止盈策略的一档：价格距离为止损距离的R倍，平掉开仓数量的Pct%
+type TakeProfitRung struct {
+	R   float64
+	Pct float64
+}
+
+// LadderLevel 已下单的分批止盈档位（随持仓保护信息持久化，重启后补设未成交的档位）
+type LadderLevel struct {
+	Price    float64   `json:"price"`
+	Pct      float64   `json:"pct"`
+	Quantity float64   `json:"quantity"`
+	Filled   bool      `json:"filled,omitempty"`
+	FilledAt time.Time `json:"filled_at,omitempty"`
+}
+
+// partialTakeProfitSetter 支持只减仓指定数量的止盈单（SetTakeProfit在部分交易所是closePosition，会平掉整个仓位）
+type partialTakeProfitSetter interface {
+	SetPartialTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error
+}
+
+// takeProfitLadder 🪜 本次开仓的分批止盈：决策自带的优先，否则按配置的R倍数策略生成（不越过最终止盈）
+func (at *AutoTrader) takeProfitLadder(d *decision.Decision, side string, entry, quantity float64) []LadderLevel {
+	ladder := d.TakeProfitLadder
+	if len(ladder) == 0 && len(at.config.TakeProfitLadder) > 0 && d.StopLoss > 0 && entry > 0 {
+		risk := math.Abs(entry - d.StopLoss)
+		for _, rung := range at.config.TakeProfitLadder {
+			price := entry + rung.R*risk
+			if side == "short" {
+				price = entry - rung.R*risk
+			}
+			if price <= 0 || (d.TakeProfit > 0 && ((side == "long" && price >= d.TakeProfit) || (side == "short" && price <= d.TakeProfit))) {
+				continue
+			}
+			ladder = append(ladder, decision.TakeProfitLevel{Price: price, Pct: rung.Pct})
+		}
+	}
+
+	levels := make([]LadderLevel, 0, len(ladder))
+	for _, level := range ladder {
+		levels = append(levels, LadderLevel{Price: level.Price, Pct: level.Pct, Quantity: quantity * level.Pct / 100})
+	}
+	return levels
+}
+
+// setTakeProfits 设置止盈：分批止盈逐档下只减仓止盈单，剩余仓位仍以takeProfit为最终目标（各档已覆盖全部数量时不再设置）
+// 交易所不支持分批止盈时回退为单一止盈；返回实际下单成功的档位
+func (at *AutoTrader) setTakeProfits(symbol, side string, quantity, takeProfit float64, levels []LadderLevel) []LadderLevel {
+	positionSide := strings.ToUpper(side)
+	setter, ok := at.trader.(partialTakeProfitSetter)
+	if len(levels) > 0 && !ok {
+		log.Printf("  ⚠️ 当前交易所不支持分批止盈，使用单一止盈")
+		levels = nil
+	}
+
+	var placed []LadderLevel
+	placedQty := 0.0
+	for i, level := range levels {
+		if err := setter.SetPartialTakeProfit(symbol, positionSide, level.Quantity, level.Price); err != nil {
+			log.Printf("  ⚠ 设置第%d档止盈失败（该部分交给最终止盈）: %v", i+1, err)
+			continue
+		}
+		log.Printf("  🪜 第%d档止盈: %.4f 平仓%.0f%% (数量%.6f)", i+1, level.Price, level.Pct, level.Quantity)
+		placed = append(placed, level)
+		placedQty += level.Quantity
+	}
+
+	if takeProfit > 0 && placedQty < quantity*0.999 {
+		if err := at.trader.SetTakeProfit(symbol, positionSide, quantity, takeProfit); err != nil {
+			log.Printf("  ⚠ 设置止盈失败: %v", err)
+		}
+	}
+	return placed
+}
+
+// recordTakeProfitLadder 把已下单的分批止盈记录到持仓保护信息
+func (at *AutoTrader) recordTakeProfitLadder(symbol, side string, quantity float64, levels []LadderLevel) {
+	if len(levels) == 0 {
+		return
+	}
+	p, ok := at.orderManager.GetProtection(symbol, side)
+	if !ok {
+		return
+	}
+	updated := *p
+	updated.TakeProfitLadder = levels
+	updated.LadderQuantity = quantity
+	at.orderManager.SetProtection(&updated)
+}
+
+// trackTakeProfitLadders 🪜 按持仓数量的减少标记已成交的止盈档位（各交易所统一按持仓变化判断，不依赖订单查询）
+func (at *AutoTrader) trackTakeProfitLadders() {
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return
+	}
+	for _, pos := range positions {
+		symbol, side := pos.Symbol, pos.Side
+		p, ok := at.orderManager.GetProtection(symbol, side)
+		if !ok || len(p.TakeProfitLadder) == 0 || p.LadderQuantity <= 0 {
+			continue
+		}
+		quantity := pos.Quantity
+
+		updated := *p
+		updated.TakeProfitLadder = append([]LadderLevel(nil), p.TakeProfitLadder...)
+		remaining := p.LadderQuantity
+		changed := false
+		for i := range updated.TakeProfitLadder {
+			level := &updated.TakeProfitLadder[i]
+			remaining -= level.Quantity
+			if level.Filled {
+				continue
+			}
+			if quantity > remaining+p.LadderQuantity*0.001 {
+				break // 持仓还没减到该档成交后的数量
+			}
+			level.Filled, level.FilledAt = true, time.Now()
+			changed = true
+			log.Printf("🪜 [%s %s] 第%d档止盈已成交: %.4f 平仓%.0f%%（剩余持仓%.6f）",
+				symbol, side, i+1, level.Price, level.Pct, quantity)
+		}
+		if changed {
+			at.orderManager.SetProtection(&updated)
+		}
+	}
+}
+
+// restoreTakeProfits 补设止盈：未成交的分批止盈档位按记录数量补设（合计不超过当前持仓），其余同开仓
+func (at *AutoTrader) restoreTakeProfits(symbol, side string, quantity float64, p *PositionProtection) {
+	var levels []LadderLevel
+	budget := quantity
+	for _, level := range p.TakeProfitLadder {
+		if level.Filled || budget <= 0 {
+			continue
+		}
+		level.Quantity = math.Min(level.Quantity, budget)
+		budget -= level.Quantity
+		levels = append(levels, level)
+	}
+	at.setTakeProfits(symbol, side, quantity, p.TakeProfit, levels)
+}
@@ -0,0 +1,216 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tradeIdeaSource        = "webhook"        // 候选来源标签
+	tradeIdeaTTL           = 30 * time.Minute // 未被决策周期取走的想法过期时间
+	maxTradeIdeaCandidates = 5                // 每周期最多注入的想法数
+	maxTradeIdeaNoteLen    = 200              // 备注最大长度（外部文本，截断后注入提示词）
+	maxRecentTradeIdeas    = 20               // 状态API保留的最近想法数
+)
+
+// TradeIdea 📥 外部系统（TradingView警报、自定义扫描器）推送的交易想法
+// 只作为下一周期的候选信号交给AI确认或否决，不会直接下单
+type TradeIdea struct {
+	Symbol     string    `json:"symbol"`
+	Direction  string    `json:"direction"`             // "long" 或 "short"（也接受 buy/sell）
+	Confidence int       `json:"confidence,omitempty"`  // 发送方置信度 0-100（0=未提供）
+	StopLoss   float64   `json:"stop_loss,omitempty"`   // 建议止损（可选）
+	TakeProfit float64   `json:"take_profit,omitempty"` // 建议止盈（可选）
+	Source     string    `json:"source,omitempty"`      // 发送方标识，如 tradingview
+	Note       string    `json:"note,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+	Status     string    `json:"status,omitempty"` // pending / injected / expired / replaced
+}
+
+// normalize 校验并规范化想法（币种补全USDT、方向统一为long/short）
+func (idea *TradeIdea) normalize() error {
+	symbols, err := NormalizeWatchlist([]string{idea.Symbol})
+	if err != nil {
+		return err
+	}
+	if len(symbols) != 1 {
+		return fmt.Errorf("缺少symbol")
+	}
+	idea.Symbol = symbols[0]
+
+	switch strings.ToLower(strings.TrimSpace(idea.Direction)) {
+	case "long", "buy":
+		idea.Direction = "long"
+	case "short", "sell":
+		idea.Direction = "short"
+	default:
+		return fmt.Errorf("direction必须是 long/short（或 buy/sell），收到 %q", idea.Direction)
+	}
+
+	if idea.Confidence < 0 || idea.Confidence > 100 {
+		return fmt.Errorf("confidence必须在0-100之间")
+	}
+	if idea.StopLoss < 0 || idea.TakeProfit < 0 {
+		return fmt.Errorf("stop_loss/take_profit不能为负数")
+	}
+	if idea.StopLoss > 0 && idea.TakeProfit > 0 {
+		if idea.Direction == "long" && idea.StopLoss >= idea.TakeProfit {
+			return fmt.Errorf("多单止损(%.4f)必须低于止盈(%.4f)", idea.StopLoss, idea.TakeProfit)
+		}
+		if idea.Direction == "short" && idea.StopLoss <= idea.TakeProfit {
+			return fmt.Errorf("空单止损(%.4f)必须高于止盈(%.4f)", idea.StopLoss, idea.TakeProfit)
+		}
+	}
+
+	idea.Source = strings.TrimSpace(idea.Source)
+	if idea.Source == "" {
+		idea.Source = "external"
+	}
+	idea.Note = strings.TrimSpace(idea.Note)
+	if runes := []rune(idea.Note); len(runes) > maxTradeIdeaNoteLen {
+		idea.Note = string(runes[:maxTradeIdeaNoteLen]) + "…"
+	}
+	return nil
+}
+
+// summary 注入提示词的想法摘要
+func (idea *TradeIdea) summary() string {
+	parts := []string{fmt.Sprintf("来源%s 方向%s", idea.Source, idea.Direction)}
+	if idea.Confidence > 0 {
+		parts = append(parts, fmt.Sprintf("发送方置信度%d%%", idea.Confidence))
+	}
+	if idea.StopLoss > 0 || idea.TakeProfit > 0 {
+		parts = append(parts, fmt.Sprintf("建议止损%.4f 止盈%.4f", idea.StopLoss, idea.TakeProfit))
+	}
+	parts = append(parts, fmt.Sprintf("%.0f分钟前收到", time.Since(idea.ReceivedAt).Minutes()))
+	if idea.Note != "" {
+		parts = append(parts, "备注: "+idea.Note)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// tradeIdeaInbox webhook与决策周期之间的想法缓冲（每个币种保留最新一条，注入后移除）
+type tradeIdeaInbox struct {
+	mu       sync.Mutex
+	pending  map[string]*TradeIdea
+	recent   []*TradeIdea // 最近收到的想法（含处理结果，状态API）
+	received int
+	injected int
+	expired  int
+}
+
+func newTradeIdeaInbox() *tradeIdeaInbox {
+	return &tradeIdeaInbox{pending: make(map[string]*TradeIdea)}
+}
+
+// SubmitTradeIdea 接收外部交易想法，下一个决策周期作为候选信号注入（AI确认或否决）
+func (at *AutoTrader) SubmitTradeIdea(idea TradeIdea) (*TradeIdea, error) {
+	if err := idea.normalize(); err != nil {
+		return nil, err
+	}
+	if at.basis != nil && at.basis.Manages(idea.Symbol) {
+		return nil, fmt.Errorf("%s 由基差策略管理，不接受交易想法", idea.Symbol)
+	}
+	idea.ReceivedAt = time.Now()
+	idea.Status = "pending"
+
+	b := at.tradeIdeas
+	b.mu.Lock()
+	if old, ok := b.pending[idea.Symbol]; ok {
+		old.Status = "replaced"
+	}
+	stored := idea
+	b.pending[idea.Symbol] = &stored
+	b.recent = append(b.recent, &stored)
+	if len(b.recent) > maxRecentTradeIdeas {
+		b.recent = b.recent[len(b.recent)-maxRecentTradeIdeas:]
+	}
+	b.received++
+	b.mu.Unlock()
+
+	log.Printf("📥 [%s] 收到交易想法: %s %s（来源%s，置信度%d）", at.name, idea.Symbol, idea.Direction, idea.Source, idea.Confidence)
+	result := stored
+	return &result, nil
+}
+
+// drain 取出有效期内的想法（最新的在前，最多maxTradeIdeaCandidates个，其余留到下个周期）
+func (b *tradeIdeaInbox) drain() []*TradeIdea {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var result []*TradeIdea
+	for symbol, idea := range b.pending {
+		if time.Since(idea.ReceivedAt) > tradeIdeaTTL {
+			idea.Status = "expired"
+			b.expired++
+			delete(b.pending, symbol)
+			continue
+		}
+		result = append(result, idea)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ReceivedAt.After(result[j].ReceivedAt) })
+	if len(result) > maxTradeIdeaCandidates {
+		result = result[:maxTradeIdeaCandidates]
+	}
+	for _, idea := range result {
+		idea.Status = "injected"
+		delete(b.pending, idea.Symbol)
+	}
+	b.injected += len(result)
+	return result
+}
+
+// injectTradeIdeas 把外部交易想法作为额外候选来源注入（已在候选池中的币种追加来源标签，新币种放在最前面）
+func (at *AutoTrader) injectTradeIdeas(candidates []decision.CandidateCoin) []decision.CandidateCoin {
+	ideas := at.tradeIdeas.drain()
+	if len(ideas) == 0 {
+		return candidates
+	}
+
+	index := make(map[string]int, len(candidates))
+	for i, c := range candidates {
+		index[c.Symbol] = i
+	}
+
+	var injected []decision.CandidateCoin
+	for _, idea := range ideas {
+		if i, ok := index[idea.Symbol]; ok {
+			candidates[i].Sources = append(candidates[i].Sources, tradeIdeaSource)
+			candidates[i].Idea = idea.summary()
+			continue
+		}
+		injected = append(injected, decision.CandidateCoin{
+			Symbol:  idea.Symbol,
+			Sources: []string{tradeIdeaSource},
+			Idea:    idea.summary(),
+		})
+	}
+
+	log.Printf("📥 注入%d个外部交易想法（新增候选%d个）", len(ideas), len(injected))
+	return append(injected, candidates...)
+}
+
+// getTradeIdeaStatus 交易想法收件箱状态（状态API）
+func (at *AutoTrader) getTradeIdeaStatus() map[string]interface{} {
+	b := at.tradeIdeas
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recent := make([]TradeIdea, 0, len(b.recent))
+	for i := len(b.recent) - 1; i >= 0; i-- {
+		recent = append(recent, *b.recent[i])
+	}
+	return map[string]interface{}{
+		"pending":  len(b.pending),
+		"received": b.received,
+		"injected": b.injected,
+		"expired":  b.expired,
+		"recent":   recent,
+	}
+}
@@ -0,0 +1,164 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision/types"
+	"nofx/events"
+	"nofx/logger"
+	"nofx/memory"
+	"time"
+)
+
+// StopMovedHandler 移动止损更新回调（oldStop=0表示首次设置）
+type StopMovedHandler func(symbol, side string, oldStop, newStop float64)
+
+// stopMoveReporter 交易器内部会移动止损时，支持注册回调以发布事件
+type stopMoveReporter interface {
+	SetStopMovedHandler(handler StopMovedHandler)
+}
+
+// SetStopMovedHandler 注册移动止损回调（在交易器开始运行前调用）
+func (t *FuturesTrader) SetStopMovedHandler(handler StopMovedHandler) { t.onStopMoved = handler }
+
+// SetStopMovedHandler 注册移动止损回调（在交易器开始运行前调用）
+func (t *MockTrader) SetStopMovedHandler(handler StopMovedHandler) { t.onStopMoved = handler }
+
+// subscribeStopMoves 📣 交易器内部的移动止损发布为事件
+func (at *AutoTrader) subscribeStopMoves() {
+	reporter, ok := at.trader.(stopMoveReporter)
+	if !ok {
+		return
+	}
+	reporter.SetStopMovedHandler(func(symbol, side string, oldStop, newStop float64) {
+		at.recordStopMove(symbol, side, newStop)
+		at.events.Publish(events.StopMovedEvent{
+			TraderID: at.id, Symbol: symbol, Side: side, OldStop: oldStop, NewStop: newStop, Time: time.Now(),
+		})
+	})
+}
+
+// publishPredictions 📣 本周期每个币种的预测结论发布为事件（来自因子归因）
+func (at *AutoTrader) publishPredictions(attributions []types.FactorAttribution) {
+	now := time.Now()
+	for _, attr := range attributions {
+		at.events.Publish(events.PredictionDoneEvent{
+			TraderID:    at.id,
+			Cycle:       at.callCount,
+			Symbol:      attr.Symbol,
+			Stage:       attr.Stage,
+			Verdict:     attr.Verdict,
+			Direction:   attr.Direction,
+			Probability: attr.Probability,
+			Confidence:  attr.Confidence,
+			Reason:      attr.Reason,
+			Time:        now,
+		})
+	}
+}
+
+// publishExecuted 📣 决策执行成功
+func (at *AutoTrader) publishExecuted(actionRecord *logger.DecisionAction) {
+	at.events.Publish(events.DecisionExecutedEvent{
+		TraderID:     at.id,
+		Cycle:        at.callCount,
+		Symbol:       actionRecord.Symbol,
+		Action:       actionRecord.Action,
+		Quantity:     actionRecord.Quantity,
+		Price:        actionRecord.Price,
+		OrderID:      actionRecord.OrderID,
+		Hypothetical: actionRecord.Hypothetical,
+		Time:         time.Now(),
+	})
+}
+
+// Events 交易器事件总线（通知、指标等模块可订阅，无需依赖交易器内部实现）
+func (at *AutoTrader) Events() *events.Bus {
+	return at.events
+}
+
+// subscribeMemory 🧠 止损/止盈自动触发写入AI记忆
+func (at *AutoTrader) subscribeMemory() {
+	at.events.Subscribe("memory", func(e events.Event) {
+		if ev, ok := e.(events.StopTriggeredEvent); ok {
+			at.recordStopToMemory(ev)
+		}
+	}, events.StopTriggered)
+}
+
+// recordStopToMemory 把交易所侧触发的平仓记入AI记忆
+func (at *AutoTrader) recordStopToMemory(ev events.StopTriggeredEvent) {
+	result := "break_even"
+	if band := at.memoryManager.BreakEvenPct(); ev.ReturnPct > band {
+		result = "win"
+	} else if ev.ReturnPct < -band {
+		result = "loss"
+	}
+
+	triggerType := "止损"
+	switch ev.Trigger {
+	case CloseReasonTakeProfit:
+		triggerType = "止盈"
+	case CloseReasonLiquidation:
+		triggerType = "强平"
+	case CloseReasonManual:
+		triggerType = "手动平仓"
+	}
+
+	exitPrice := ev.LastPrice
+	reasoning := fmt.Sprintf("%s自动触发（持仓消失，未经主动平仓决策）", triggerType)
+	if ev.FillPrice > 0 {
+		exitPrice = ev.FillPrice
+		reasoning = fmt.Sprintf("%s（交易所订单确认，成交价%.4f", triggerType, ev.FillPrice)
+		if ev.TriggerPrice > 0 {
+			reasoning += fmt.Sprintf("，触发价%.4f，滑点%.3f%%", ev.TriggerPrice, ev.SlippagePct)
+		}
+		reasoning += "）"
+	}
+
+	tradeEntry := memory.TradeEntry{
+		Cycle:       ev.Cycle,
+		Timestamp:   ev.Time,
+		Action:      "close",
+		Symbol:      ev.Symbol,
+		Side:        ev.Side,
+		Signals:     []string{triggerType + "自动触发"},
+		Reasoning:   reasoning,
+		EntryPrice:  ev.EntryPrice,
+		ExitPrice:   exitPrice,
+		PositionPct: ev.PositionPct,
+		Leverage:    ev.Leverage,
+		HoldMinutes: ev.HoldMinutes,
+		ReturnPct:   ev.ReturnPct,
+		Result:      result,
+	}
+
+	if err := at.memoryManager.AddTrade(tradeEntry); err != nil {
+		log.Printf("⚠️  记录止损/止盈到记忆失败: %v", err)
+		return
+	}
+	log.Printf("✅ 已记录%s到交易记忆：%s %s, 收益%.2f%%", triggerType, ev.Symbol, ev.Side, ev.ReturnPct)
+}
+
+// publishLimitFilled 限价单成交（含部分成交）后发布开仓事件
+func (at *AutoTrader) publishLimitFilled(order *LimitOrder, side string) {
+	quantity := order.Quantity
+	if order.FilledQty > 0 {
+		quantity = order.FilledQty
+	}
+	price := order.Price
+	if order.AvgPrice > 0 {
+		price = order.AvgPrice
+	}
+	at.events.Publish(events.PositionOpenedEvent{
+		TraderID:   at.id,
+		Symbol:     order.Symbol,
+		Side:       side,
+		Quantity:   quantity,
+		Price:      price,
+		Leverage:   order.Leverage,
+		StopLoss:   order.StopLoss,
+		TakeProfit: order.TakeProfit,
+		Time:       time.Now(),
+	})
+}
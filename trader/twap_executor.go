@@ -0,0 +1,205 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/market"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// 决策执行方式（Decision.ExecutionStyle，空=按配置自动选择）
+const (
+	ExecutionStyleMarket = "market" // 单笔市价单
+	ExecutionStyleTWAP   = "twap"   // 时间加权拆单
+)
+
+const (
+	defaultTWAPSlices   = 5
+	defaultTWAPDuration = 60 * time.Second
+	twapMinSliceUSD     = 100.0 // 每个分片的最小名义价值（与开仓最小名义价值一致）
+	twapBookBandPct     = 0.5   // 盘口深度统计范围（中间价±0.5%）
+)
+
+// TWAPConfig ⏳ 大额开仓的TWAP拆单配置
+type TWAPConfig struct {
+	MinNotional float64       // 名义价值≥此值时拆单（0=不按金额触发）
+	MaxBookPct  float64       // 订单占盘口±0.5%深度的百分比超过此值时拆单（0=不按深度触发）
+	Slices      int           // 分片数（0=默认5）
+	Duration    time.Duration // 执行总时长（0=默认60秒）
+}
+
+func (c TWAPConfig) slices() int {
+	if c.Slices > 1 {
+		return c.Slices
+	}
+	return defaultTWAPSlices
+}
+
+func (c TWAPConfig) duration() time.Duration {
+	if c.Duration > 0 {
+		return c.Duration
+	}
+	return defaultTWAPDuration
+}
+
+// EntrySlicer 支持TWAP后续分片的交易器（只下市价单，不再重复设置杠杆、撤销委托和冷却检查）
+type EntrySlicer interface {
+	PlaceEntrySlice(symbol, side string, quantity float64, clientOrderID string) (*OrderResult, error)
+}
+
+// PlaceEntrySlice TWAP后续分片：在已有持仓上追加市价单
+func (t *FuturesTrader) PlaceEntrySlice(symbol, side string, quantity float64, clientOrderID string) (*OrderResult, error) {
+	quantityStr, err := t.FormatQuantity(symbol, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	orderSide := futures.SideTypeBuy
+	if side == "short" {
+		orderSide = futures.SideTypeSell
+	}
+	orderService := t.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(orderSide).
+		PositionSide(t.positionSideFor(side)).
+		Type(futures.OrderTypeMarket).
+		Quantity(quantityStr)
+	if clientOrderID != "" {
+		orderService = orderService.NewClientOrderID(clientOrderID)
+	}
+	order, err := orderService.Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("TWAP分片下单失败: %w", err)
+	}
+	t.invalidateCache()
+
+	executed, _ := strconv.ParseFloat(quantityStr, 64)
+	return &OrderResult{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        string(order.Status),
+		Side:          string(order.Side),
+		Type:          string(order.Type),
+		OrigQty:       executed,
+		ExecutedQty:   executed,
+		Commission:    t.recordOpenCommission(symbol, side, order.OrderID),
+	}, nil
+}
+
+// twapSlices 判断开仓是否需要TWAP拆单，返回分片数（<2表示单笔市价单）
+func (at *AutoTrader) twapSlices(d *decision.Decision, side string, notional float64) int {
+	if _, ok := at.trader.(EntrySlicer); !ok || d.ExecutionStyle == ExecutionStyleMarket {
+		return 0
+	}
+
+	cfg := at.config.TWAP
+	reason := ""
+	switch {
+	case d.ExecutionStyle == ExecutionStyleTWAP:
+		reason = "决策指定TWAP"
+	case cfg.MinNotional > 0 && notional >= cfg.MinNotional:
+		reason = fmt.Sprintf("名义价值%.0f≥%.0f USDT", notional, cfg.MinNotional)
+	case cfg.MaxBookPct > 0:
+		bookSide := "buy"
+		if side == "short" {
+			bookSide = "sell"
+		}
+		depth, err := market.BookDepthUSD(d.Symbol, bookSide, twapBookBandPct)
+		if err != nil {
+			log.Printf("  ⚠️ 获取盘口深度失败，按单笔市价单执行: %v", err)
+			return 0
+		}
+		if depth > 0 && notional/depth*100 > cfg.MaxBookPct {
+			reason = fmt.Sprintf("占盘口±%.1f%%深度%.1f%%>%.1f%%", twapBookBandPct, notional/depth*100, cfg.MaxBookPct)
+		}
+	}
+	if reason == "" {
+		return 0
+	}
+
+	n := cfg.slices()
+	if maxSlices := int(notional / twapMinSliceUSD); n > maxSlices {
+		n = maxSlices // 每个分片不低于最小名义价值
+	}
+	if n >= 2 {
+		log.Printf("  ⏳ TWAP拆单: %s，%d个分片，%v内完成", reason, n, cfg.duration())
+	}
+	return n
+}
+
+// twapSliceSizes 把数量随机拆成n份（每份在均值±25%内浮动，总和不变）
+func twapSliceSizes(quantity float64, n int) []float64 {
+	weights := make([]float64, n)
+	total := 0.0
+	for i := range weights {
+		weights[i] = 0.75 + rand.Float64()*0.5
+		total += weights[i]
+	}
+	sizes := make([]float64, n)
+	for i, w := range weights {
+		sizes[i] = quantity * w / total
+	}
+	return sizes
+}
+
+// placeEntryOrder 开仓下单：满足TWAP条件时拆单执行，返回首个订单结果（手续费为所有分片之和）和实际下单数量
+func (at *AutoTrader) placeEntryOrder(d *decision.Decision, side string, quantity, price float64, actionRecord *logger.DecisionAction) (*OrderResult, float64, error) {
+	n := at.twapSlices(d, side, quantity*price)
+	if n < 2 {
+		order, err := at.placeOrder(d.Action, d.Symbol, quantity, d.Leverage, actionRecord.ClientOrderID)
+		if err != nil {
+			return nil, 0, err
+		}
+		// 部分成交：止损止盈按实际成交数量设置
+		if executed := order.ExecutedQty; executed > 0 && executed < quantity {
+			log.Printf("  ⚠️ 实际成交数量%.6f少于下单数量%.6f", executed, quantity)
+			return order, executed, nil
+		}
+		return order, quantity, nil
+	}
+
+	sizes := twapSliceSizes(quantity, n)
+	interval := at.config.TWAP.duration() / time.Duration(n-1)
+
+	// 第一个分片走完整开仓流程（设置杠杆/保证金模式、撤销旧委托）
+	first, err := at.placeOrder(d.Action, d.Symbol, sizes[0], d.Leverage, actionRecord.ClientOrderID)
+	if err != nil {
+		return nil, 0, err
+	}
+	filled := sizes[0]
+	commission := first.Commission
+
+	slicer := at.trader.(EntrySlicer)
+	for i := 1; i < n; i++ {
+		// 间隔随机浮动±20%，避免固定节奏被识别
+		time.Sleep(time.Duration(float64(interval) * (0.8 + rand.Float64()*0.4)))
+		if !at.IsRunning() {
+			log.Printf("  ⏹ 交易器已停止，TWAP在第%d/%d个分片中止", i+1, n)
+			break
+		}
+
+		order, err := slicer.PlaceEntrySlice(d.Symbol, side, sizes[i], fmt.Sprintf("%s_t%d", actionRecord.ClientOrderID, i))
+		if err != nil {
+			log.Printf("  ⚠️ TWAP第%d/%d个分片失败，保留已成交部分: %v", i+1, n, err)
+			break
+		}
+		if order.ExecutedQty > 0 {
+			filled += order.ExecutedQty
+		} else {
+			filled += sizes[i]
+		}
+		commission += order.Commission
+	}
+
+	first.Commission = commission
+	log.Printf("  ✓ TWAP执行完成: %s 计划数量%.4f，实际%.4f", d.Symbol, quantity, filled)
+	return first, filled, nil
+}
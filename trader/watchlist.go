@@ -0,0 +1,97 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"nofx/decision"
+	"nofx/decision/agents"
+	"nofx/market"
+	"strings"
+	"sync"
+)
+
+// watchlist ⭐ 用户置顶关注的币种：不论AI500/OI排名，每个周期都加入候选池
+type watchlist struct {
+	mu      sync.RWMutex
+	symbols []string
+}
+
+// NormalizeWatchlist 规范化币种列表（大写、无计价币种时补全USDT后缀、去重）
+func NormalizeWatchlist(symbols []string) ([]string, error) {
+	seen := make(map[string]bool, len(symbols))
+	result := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s == "" {
+			continue
+		}
+		if market.IsQuoteAsset(s) || strings.ContainsAny(s, " /_-") {
+			return nil, fmt.Errorf("无效的币种: %s", s)
+		}
+		s = market.Normalize(s)
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// SetWatchlist 设置关注列表（下一个决策周期生效，空列表=清空）
+func (at *AutoTrader) SetWatchlist(symbols []string) error {
+	normalized, err := NormalizeWatchlist(symbols)
+	if err != nil {
+		return err
+	}
+	at.watchlist.mu.Lock()
+	at.watchlist.symbols = normalized
+	at.watchlist.mu.Unlock()
+	log.Printf("⭐ [%s] 关注列表已更新: %v", at.name, normalized)
+	return nil
+}
+
+// GetWatchlist 当前关注列表
+func (at *AutoTrader) GetWatchlist() []string {
+	at.watchlist.mu.RLock()
+	defer at.watchlist.mu.RUnlock()
+	return append([]string(nil), at.watchlist.symbols...)
+}
+
+// injectWatchlist 把关注币种加入候选池（已在池中的追加user来源），关注币种排在最前
+func (at *AutoTrader) injectWatchlist(candidates []decision.CandidateCoin) []decision.CandidateCoin {
+	symbols := at.GetWatchlist()
+	if len(symbols) == 0 {
+		return candidates
+	}
+
+	index := make(map[string]int, len(candidates))
+	for i, c := range candidates {
+		index[c.Symbol] = i
+	}
+
+	pinned := make([]decision.CandidateCoin, 0, len(symbols))
+	for _, symbol := range symbols {
+		if at.basis != nil && at.basis.Manages(symbol) {
+			continue // 📐 基差策略币种不交给AI
+		}
+		if i, ok := index[symbol]; ok {
+			coin := candidates[i]
+			coin.Sources = append(coin.Sources, agents.SourceUser)
+			pinned = append(pinned, coin)
+			index[symbol] = -1
+			continue
+		}
+		pinned = append(pinned, decision.CandidateCoin{
+			Symbol:  symbol,
+			Sources: []string{agents.SourceUser},
+		})
+	}
+
+	for _, c := range candidates {
+		if index[c.Symbol] >= 0 {
+			pinned = append(pinned, c)
+		}
+	}
+	return pinned
+}
@@ -0,0 +1,169 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"nofx/events"
+	"nofx/logger"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const defaultWithdrawalLockPct = 5.0 // 可用余额无法解释的下降阈值（%）
+
+// withdrawalLockState 提现/划转锁状态（持久化，重启后仍需确认才能开仓）
+type withdrawalLockState struct {
+	Locked       bool      `json:"locked"`
+	Since        time.Time `json:"since,omitempty"`
+	Before       float64   `json:"available_before,omitempty"` // 上一周期可用余额（已扣除未实现盈亏变化）
+	After        float64   `json:"available_after,omitempty"`
+	DropPct      float64   `json:"drop_pct,omitempty"`
+	Acknowledged time.Time `json:"acknowledged,omitempty"` // 最近一次确认解锁时间
+	AckSource    string    `json:"ack_source,omitempty"`   // api/config
+}
+
+// withdrawalLock 🔒 提现/划转安全锁：会话中保证金被外部转出时仓位计算的前提失效，暂停开新仓直到人工确认
+type withdrawalLock struct {
+	mu    sync.Mutex
+	path  string
+	state withdrawalLockState
+}
+
+// newWithdrawalLock 从 <dir>/withdrawal_lock.json 加载状态
+func newWithdrawalLock(dir string) *withdrawalLock {
+	l := &withdrawalLock{path: filepath.Join(dir, "withdrawal_lock.json")}
+	if data, err := os.ReadFile(l.path); err == nil {
+		if err := json.Unmarshal(data, &l.state); err != nil {
+			log.Printf("⚠️  加载提现锁状态失败: %v", err)
+		}
+	}
+	return l
+}
+
+func (l *withdrawalLock) save() {
+	data, err := json.MarshalIndent(l.state, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(l.path, data, 0644); err != nil {
+		log.Printf("⚠️  保存提现锁状态失败: %v", err)
+	}
+}
+
+func (l *withdrawalLock) snapshot() withdrawalLockState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// acknowledge 解除锁定，返回之前是否处于锁定状态
+func (l *withdrawalLock) acknowledge(source string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.state.Locked {
+		return false
+	}
+	l.state.Locked = false
+	l.state.Acknowledged = time.Now()
+	l.state.AckSource = source
+	l.save()
+	return true
+}
+
+// withdrawalLockPct 锁定阈值（<=0表示不启用）
+func (at *AutoTrader) withdrawalLockPct() float64 {
+	if at.config.WithdrawalLockPct == 0 {
+		return defaultWithdrawalLockPct
+	}
+	return at.config.WithdrawalLockPct
+}
+
+// detectWithdrawal 🔒 相邻周期之间没有开仓、平仓、减仓时，可用余额（扣除未实现盈亏变化）下降超过阈值视为外部提现/划转，锁定开新仓
+// 有交易时保证金占用和已实现盈亏会改变可用余额，本周期不判断（由钱包余额异常检测兜底）
+func (at *AutoTrader) detectWithdrawal(last, current *accountSnapshot, record *logger.DecisionRecord) {
+	threshold := at.withdrawalLockPct()
+	if threshold <= 0 || at.withdrawal == nil || last.AvailableBalance <= 0 {
+		return
+	}
+	if len(last.Positions) != len(current.Positions) {
+		return
+	}
+	for key, prev := range last.Positions {
+		if cur, ok := current.Positions[key]; !ok || cur.Quantity != prev.Quantity {
+			return
+		}
+	}
+	for _, p := range current.Positions {
+		if at.openedSince(p.Symbol, last.Time) {
+			return
+		}
+	}
+
+	before := last.AvailableBalance
+	after := current.AvailableBalance - (current.UnrealizedPnL - last.UnrealizedPnL)
+	dropPct := (before - after) / before * 100
+	if dropPct <= threshold {
+		return
+	}
+
+	l := at.withdrawal
+	l.mu.Lock()
+	if l.state.Locked {
+		l.mu.Unlock()
+		return
+	}
+	l.state = withdrawalLockState{Locked: true, Since: current.Time, Before: before, After: after, DropPct: dropPct}
+	l.save()
+	l.mu.Unlock()
+
+	reason := fmt.Sprintf("提现/划转锁: 可用余额无法解释地下降 %.2f → %.2f USDT（-%.2f%%），已暂停开新仓，确认后恢复", before, after, dropPct)
+	log.Printf("🔒 [%s] %s（gRPC Resume 或配置 withdrawal_lock_ack: true 后重启）", at.name, reason)
+	record.ExecutionLog = append(record.ExecutionLog, "🔒 "+reason)
+	at.events.Publish(events.RiskPausedEvent{TraderID: at.id, Reason: reason, Time: current.Time})
+}
+
+// checkWithdrawalLock 提现锁生效时拒绝开仓（平仓和止损管理不受影响）
+func (at *AutoTrader) checkWithdrawalLock() error {
+	if at.withdrawal == nil {
+		return nil
+	}
+	if state := at.withdrawal.snapshot(); state.Locked {
+		return fmt.Errorf("提现/划转锁生效中（%s 可用余额下降%.2f%%），需要确认后才能开仓", state.Since.Format("01-02 15:04"), state.DropPct)
+	}
+	return nil
+}
+
+// AcknowledgeWithdrawalLock 确认外部提现/划转并恢复开仓（返回之前是否处于锁定状态）
+func (at *AutoTrader) AcknowledgeWithdrawalLock() bool {
+	if at.withdrawal == nil || !at.withdrawal.acknowledge("api") {
+		return false
+	}
+	log.Printf("🔓 [%s] 提现/划转锁已确认解除，恢复开仓", at.name)
+	return true
+}
+
+// getWithdrawalLockStatus 提现锁状态（状态API）
+func (at *AutoTrader) getWithdrawalLockStatus() map[string]interface{} {
+	if at.withdrawal == nil {
+		return nil
+	}
+	state := at.withdrawal.snapshot()
+	status := map[string]interface{}{
+		"locked":       state.Locked,
+		"drop_pct_max": at.withdrawalLockPct(),
+	}
+	if state.Locked {
+		status["since"] = state.Since
+		status["available_before"] = state.Before
+		status["available_after"] = state.After
+		status["drop_pct"] = state.DropPct
+	}
+	if !state.Acknowledged.IsZero() {
+		status["acknowledged"] = state.Acknowledged
+		status["ack_source"] = state.AckSource
+	}
+	return status
+}